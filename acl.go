@@ -0,0 +1,41 @@
+package oss
+
+// ACLManager 对象级访问控制接口
+// s3、aliyun、tencent、huawei和googlecloud实现了该接口，调用方通过类型断言判断是否支持
+type ACLManager interface {
+	// SetACL 设置对象的访问控制
+	// 参数:
+	//   - path: 对象路径
+	//   - acl: 访问控制类型，后端不支持该类型时返回 ErrNotSupported
+	// 返回:
+	//   - error: 错误信息
+	SetACL(path string, acl ACL) error
+
+	// GetACL 获取对象的访问控制
+	// 参数:
+	//   - path: 对象路径
+	// 返回:
+	//   - ACL: 访问控制类型，对象继承存储桶ACL时返回空字符串
+	//   - error: 错误信息
+	GetACL(path string) (ACL, error)
+}
+
+// ACLFromGrants 根据匿名用户和认证用户的授权还原访问控制类型
+// 用于以授权列表返回ACL的后端，只识别预定义ACL产生的授权组合
+// 参数:
+//   - publicRead: 所有用户可读
+//   - publicWrite: 所有用户可写
+//   - authenticatedRead: 认证用户可读
+// 返回:
+//   - ACL: 访问控制类型
+func ACLFromGrants(publicRead, publicWrite, authenticatedRead bool) ACL {
+	switch {
+	case publicRead && publicWrite:
+		return ACLPublicReadWrite
+	case publicRead:
+		return ACLPublicRead
+	case authenticatedRead:
+		return ACLAuthenticatedRead
+	}
+	return ACLPrivate
+}
@@ -0,0 +1,38 @@
+package aliyun
+
+import (
+	"fmt"
+
+	aliyun "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/smart-unicom/oss"
+)
+
+// SetACL 设置对象的访问控制
+// 参数:
+//   - path: 对象路径
+//   - acl: 访问控制类型，阿里云OSS不支持 oss.ACLAuthenticatedRead
+// 返回:
+//   - error: 错误信息
+func (client Client) SetACL(path string, acl oss.ACL) error {
+	if acl == oss.ACLAuthenticatedRead {
+		return fmt.Errorf("%w: aliyun does not support %s ACL", oss.ErrNotSupported, acl)
+	}
+	return wrapError(client.Bucket.SetObjectACL(client.ToRelativePath(path), aliyun.ACLType(acl)))
+}
+
+// GetACL 获取对象的访问控制
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - oss.ACL: 访问控制类型，对象继承存储桶ACL时返回空字符串
+//   - error: 错误信息
+func (client Client) GetACL(path string) (oss.ACL, error) {
+	result, err := client.Bucket.GetObjectACL(client.ToRelativePath(path))
+	if err != nil {
+		return "", wrapError(err)
+	}
+	if result.ACL == string(aliyun.ACLDefault) {
+		return "", nil
+	}
+	return oss.ACL(result.ACL), nil
+}
@@ -3,12 +3,15 @@
 package aliyun
 
 import (
+	"errors"
+	"fmt"
 	"io"
-	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -44,6 +47,11 @@ type Config struct {
 	ClientOptions []aliyun.ClientOption
 	// UseCname 是否使用自定义域名
 	UseCname bool
+	// HTTPClient 发送请求使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用SDK默认客户端
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，为nil时不重试，SDK本身不会重试失败的请求
+	// 设置后在HTTP客户端的Transport外层重试，只重试幂等且内容可以重新读取的请求
+	Retry *oss.RetryConfig
 }
 
 // New 初始化阿里云OSS存储客户端
@@ -51,11 +59,9 @@ type Config struct {
 //   - config: 阿里云OSS配置信息
 // 返回:
 //   - *Client: 阿里云OSS存储客户端实例
-func New(config *Config) *Client {
-	var (
-		err    error
-		client = &Client{Config: config}
-	)
+//   - error: 错误信息
+func New(config *Config) (*Client, error) {
+	client := &Client{Config: config}
 
 	// 设置默认端点
 	if config.Endpoint == "" {
@@ -72,18 +78,43 @@ func New(config *Config) *Client {
 		config.ClientOptions = append(config.ClientOptions, aliyun.UseCname(config.UseCname))
 	}
 
+	// 使用自定义HTTP客户端，配置了重试时在客户端外层重试
+	if httpClient := config.HTTPClient; httpClient != nil || config.Retry != nil {
+		if config.Retry != nil {
+			if httpClient == nil {
+				httpClient = oss.DefaultHTTPClient
+			}
+			httpClient = config.Retry.Client(httpClient)
+		}
+		config.ClientOptions = append(config.ClientOptions, aliyun.HTTPClient(httpClient))
+	}
+
 	// 创建阿里云OSS客户端
 	Aliyun, err := aliyun.New(config.Endpoint, config.AccessId, config.AccessKey, config.ClientOptions...)
+	if err != nil {
+		return nil, err
+	}
 
-	if err == nil {
-		// 获取存储桶实例
-		client.Bucket, err = Aliyun.Bucket(config.Bucket)
+	// 获取存储桶实例
+	if client.Bucket, err = Aliyun.Bucket(config.Bucket); err != nil {
+		return nil, err
 	}
 
+	return client, nil
+}
+
+// MustNew 初始化阿里云OSS存储客户端，失败时panic
+// 参数:
+//   - config: 阿里云OSS配置信息
+// 返回:
+//   - *Client: 阿里云OSS存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
 	if err != nil {
 		panic(err)
 	}
-
 	return client
 }
 
@@ -99,17 +130,8 @@ func (client Client) Get(path string) (file *os.File, err error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建临时文件并复制内容
-	if file, err = ioutil.TempFile("/tmp", "ali"); err == nil {
-		defer readCloser.Close()
-		// 将流内容复制到临时文件
-		_, err = io.Copy(file, readCloser)
-		// 重置文件指针到开始位置
-		file.Seek(0, 0)
-	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "ali")
 }
 
 // GetStream 获取指定路径文件的流
@@ -120,7 +142,68 @@ func (client Client) Get(path string) (file *os.File, err error) {
 //   - error: 错误信息
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	// 从OSS获取对象流
-	return client.Bucket.GetObject(client.ToRelativePath(path))
+	readCloser, err := client.Bucket.GetObject(client.ToRelativePath(path))
+	return readCloser, wrapError(err)
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用HTTP Range请求只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	// NormalizedRange 使用 "start-end" 格式，end为空时读取到文件末尾
+	rangeValue := strings.TrimPrefix(oss.HTTPRange(offset, length), "bytes=")
+	readCloser, err := client.Bucket.GetObject(client.ToRelativePath(path), aliyun.NormalizedRange(rangeValue))
+	return readCloser, wrapError(err)
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 使用HEAD请求获取对象的详细元数据
+	header, err := client.Bucket.GetObjectDetailedMeta(client.ToRelativePath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	object := &oss.Object{
+		Path:             path,
+		Name:             filepath.Base(path),
+		ContentType:      header.Get("Content-Type"),
+		ETag:             header.Get("ETag"),
+		Metadata:         oss.MetadataFromHeader(header, "X-Oss-Meta-"),
+		StorageInterface: client,
+	}
+	object.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if lastModified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		object.LastModified = oss.NormalizeTime(lastModified)
+	}
+
+	return object, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	exists, err := client.Bucket.IsObjectExist(client.ToRelativePath(path))
+	return exists, wrapError(err)
 }
 
 // Put 上传文件到指定路径
@@ -131,21 +214,123 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传后的对象信息
 //   - error: 错误信息
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
 	// 如果是可寻址的读取器，重置到开始位置
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
+	// 按冲突策略确定上传路径，CollisionError使用条件上传
+	if opts.Collision != oss.CollisionError {
+		resolved, err := opts.ResolvePath(client, urlPath)
+		if err != nil {
+			return nil, err
+		}
+		urlPath = resolved
+	}
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(urlPath), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	// 构建上传选项，对象级ACL优先于客户端配置
+	options := []aliyun.Option{aliyun.ACL(client.Config.ACL)}
+	if opts.ACL != "" {
+		options = append(options, aliyun.ObjectACL(aliyun.ACLType(opts.ACL)))
+	}
+	// 禁止覆盖时由服务端拒绝已存在的对象
+	if opts.Collision == oss.CollisionError {
+		options = append(options, aliyun.ForbidOverWrite(true))
+	}
+	if opts.ContentType != "" {
+		options = append(options, aliyun.ContentType(opts.ContentType))
+	}
+	if opts.ContentDisposition != "" {
+		options = append(options, aliyun.ContentDisposition(opts.ContentDisposition))
+	}
+	if opts.CacheControl != "" {
+		options = append(options, aliyun.CacheControl(opts.CacheControl))
+	}
+	for key, value := range opts.Metadata {
+		options = append(options, aliyun.Meta(key, value))
+	}
+	if len(opts.Tags) > 0 {
+		options = append(options, aliyun.SetTagging(aliyunTagging(opts.Tags)))
+	}
+	// 发送Content-MD5由服务端校验内容
+	if opts.Checksum != "" {
+		if opts.Checksum != oss.ChecksumMD5 {
+			return nil, fmt.Errorf("%w: aliyun does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+		}
+		body, checksum, err := opts.ReadChecksum(reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = body
+		options = append(options, aliyun.ContentMD5(checksum))
+	}
+
+	// 回调上传进度，包装后SDK无法识别内容长度，需要显式设置
+	if opts.Progress != nil {
+		size := oss.ReaderSize(reader)
+		if size >= 0 {
+			options = append(options, aliyun.ContentLength(size))
+		}
+		reader = opts.WrapReader(reader, size)
+	}
+
 	// 上传对象到阿里云OSS
-	err := client.Bucket.PutObject(client.ToRelativePath(urlPath), reader, aliyun.ACL(client.Config.ACL))
-	now := time.Now()
+	err = wrapError(client.Bucket.PutObject(client.ToRelativePath(urlPath), reader, options...))
 
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, err
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if err == nil {
+		if stat, err := client.Stat(urlPath); err == nil {
+			object.LastModified = stat.LastModified
+			object.Size = stat.Size
+		}
+	}
+
+	return object, err
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 阿里云SDK不需要预先知道内容长度，数据通过管道流式上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
 }
 
 // Delete 删除指定路径的文件
@@ -154,7 +339,112 @@ func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error)
 // 返回:
 //   - error: 错误信息
 func (client Client) Delete(path string) error {
-	return client.Bucket.DeleteObject(client.ToRelativePath(path))
+	return wrapError(client.Bucket.DeleteObject(client.ToRelativePath(path)))
+}
+
+// DeleteObjects 批量删除多个文件
+// 每次请求最多删除 oss.MaxDeleteObjects 个文件，多批请求并发执行
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsInChunks(paths, oss.DefaultDeleteChunkConcurrency, func(chunk []string) map[string]error {
+		failures := map[string]error{}
+		// 记录对象键对应的原始路径
+		var objectKeys []string
+		keys := map[string]string{}
+		for _, path := range chunk {
+			key := client.ToRelativePath(path)
+			keys[key] = path
+			objectKeys = append(objectKeys, key)
+		}
+
+		result, err := client.Bucket.DeleteObjects(objectKeys)
+		if err != nil {
+			for _, path := range chunk {
+				failures[path] = wrapError(err)
+			}
+			return failures
+		}
+
+		// 阿里云只返回已删除的对象，未出现在结果中的对象视为删除失败
+		for _, key := range result.DeletedObjects {
+			delete(keys, key)
+		}
+		for key, path := range keys {
+			failures[path] = fmt.Errorf("object %s was not deleted", key)
+		}
+		return failures
+	})
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分页列举目录下的对象，每页使用批量删除接口删除
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(client.ToRelativePath(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	marker := ""
+	for {
+		results, err := client.Bucket.ListObjects(aliyun.Prefix(prefix), aliyun.Marker(marker), aliyun.MaxKeys(oss.MaxDeleteObjects))
+		if err != nil {
+			return wrapError(err)
+		}
+
+		var paths []string
+		for _, obj := range results.Objects {
+			paths = append(paths, obj.Key)
+		}
+		if len(paths) > 0 {
+			if err := client.DeleteObjects(paths); err != nil {
+				return err
+			}
+		}
+
+		if !results.IsTruncated {
+			return nil
+		}
+		marker = results.NextMarker
+	}
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := oss.ValidateKeyLength(client.ToRelativePath(dstPath), maxKeyBytes, 0); err != nil {
+		return err
+	}
+
+	// 使用服务端复制
+	_, err = client.Bucket.CopyObject(client.ToRelativePath(srcPath), client.ToRelativePath(dstPath), aliyun.ObjectACL(client.Config.ACL))
+	return wrapError(err)
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 对象存储不支持重命名，通过服务端复制后删除源文件实现
+	return oss.MoveByCopy(client, srcPath, dstPath)
 }
 
 // List 列出指定路径下的所有对象
@@ -175,14 +465,23 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 			objects = append(objects, &oss.Object{
 				Path:             "/" + client.ToRelativePath(obj.Key),
 				Name:             filepath.Base(obj.Key),
-				LastModified:     &obj.LastModified,
+				LastModified:     oss.NormalizeTime(obj.LastModified),
 				Size:             obj.Size,
+				ETag:             obj.ETag,
 				StorageInterface: client,
 			})
 		}
 	}
 
-	return objects, err
+	return objects, wrapError(err)
+}
+
+// Underlying 返回阿里云OSS SDK的存储桶实例，用于统一接口没有覆盖的功能
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - interface{}: *oss.Bucket
+func (client Client) Underlying() interface{} {
+	return client.Bucket
 }
 
 // GetEndpoint 获取存储服务的端点地址
@@ -207,6 +506,9 @@ func (client Client) GetEndpoint() string {
 	return client.Config.Bucket + "." + endpoint
 }
 
+// maxKeyBytes 阿里云OSS对象键的最大字节数
+const maxKeyBytes = 1023
+
 // urlRegexp URL正则表达式，用于匹配HTTP/HTTPS URL
 var urlRegexp = regexp.MustCompile(`(https?:)?//((\w+).)+(\w+)/`)
 
@@ -234,10 +536,118 @@ func (client Client) ToRelativePath(urlPath string) string {
 //   - string: 访问URL
 //   - error: 错误信息
 func (client Client) GetURL(path string) (url string, err error) {
-	// 如果是私有访问，生成签名URL（1小时有效期）
+	// 如果是私有访问，生成默认有效期的签名URL
 	if client.Config.ACL == aliyun.ACLPrivate {
-		return client.Bucket.SignURL(client.ToRelativePath(path), aliyun.HTTPGet, 60*60)
+		return client.GetSignedURL(path, oss.SignedURLOptions{})
 	}
 	// 公共访问直接返回路径
 	return path, nil
 }
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	method := aliyun.HTTPGet
+	if opts.Method == http.MethodPut {
+		method = aliyun.HTTPPut
+	}
+
+	// 设置响应头覆盖
+	var options []aliyun.Option
+	if opts.ResponseContentType != "" {
+		options = append(options, aliyun.ResponseContentType(opts.ResponseContentType))
+	}
+	if opts.ResponseContentDisposition != "" {
+		options = append(options, aliyun.ResponseContentDisposition(opts.ResponseContentDisposition))
+	}
+	if opts.ResponseCacheControl != "" {
+		options = append(options, aliyun.ResponseCacheControl(opts.ResponseCacheControl))
+	}
+
+	return client.Bucket.SignURL(client.ToRelativePath(path), method, int64(opts.Expiry/time.Second), options...)
+}
+
+// GetUploadURL 生成预签名PUT直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+
+	// 签名中包含Content-Type时客户端必须携带相同的请求头
+	var options []aliyun.Option
+	if opts.ContentType != "" {
+		options = append(options, aliyun.ContentType(opts.ContentType))
+	}
+
+	signedURL, err := client.Bucket.SignURL(client.ToRelativePath(path), aliyun.HTTPPut, int64(opts.Expiry/time.Second), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oss.UploadURL{
+		URL:       signedURL,
+		Method:    http.MethodPut,
+		Headers:   opts.Headers(),
+		ExpiresAt: oss.Now().Add(opts.Expiry),
+	}, nil
+}
+
+func init() {
+	oss.RegisterHTTPStatusMapper(httpStatus)
+}
+
+// httpStatus 将阿里云OSS的错误转换为HTTP状态码
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码
+//   - bool: 是否识别该错误
+func httpStatus(err error) (int, bool) {
+	var serviceError aliyun.ServiceError
+	if errors.As(err, &serviceError) {
+		return serviceError.StatusCode, true
+	}
+	return 0, false
+}
+
+// wrapError 将阿里云OSS的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var serviceError aliyun.ServiceError
+	if !errors.As(err, &serviceError) {
+		return err
+	}
+
+	switch {
+	case serviceError.Code == "NoSuchKey":
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case serviceError.Code == "NoSuchBucket":
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case serviceError.Code == "AccessDenied" || serviceError.StatusCode == http.StatusForbidden:
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	case serviceError.Code == "FileAlreadyExists":
+		return oss.WrapError(oss.ErrConflict, err)
+	case serviceError.Code == "InvalidDigest" || serviceError.Code == "BadDigest":
+		return oss.WrapError(oss.ErrChecksumMismatch, err)
+	case serviceError.StatusCode == http.StatusNotFound:
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	}
+	return err
+}
@@ -34,20 +34,26 @@ func init() {
 		panic("No aliyun configuration")
 	}
 
-	client = aliyun.New(&aliyun.Config{
+	client, err = aliyun.New(&aliyun.Config{
 		AccessId:  config.Public.AccessId,
 		AccessKey: config.Public.AccessKey,
 		Bucket:    config.Public.Bucket,
 		Endpoint:  config.Public.Endpoint,
 	})
+	if err != nil {
+		panic(err)
+	}
 
-	privateClient = aliyun.New(&aliyun.Config{
+	privateClient, err = aliyun.New(&aliyun.Config{
 		AccessId:  config.Private.AccessId,
 		AccessKey: config.Private.AccessKey,
 		Bucket:    config.Private.Bucket,
 		ACL:       aliyunoss.ACLPrivate,
 		Endpoint:  config.Private.Endpoint,
 	})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestAll(t *testing.T) {
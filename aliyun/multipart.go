@@ -0,0 +1,118 @@
+package aliyun
+
+import (
+	"io"
+	"path/filepath"
+
+	aliyun "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/smart-unicom/oss"
+)
+
+// InitiateMultipart 初始化分片上传
+// 参数:
+//   - urlPath: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(urlPath string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(urlPath), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	// 构建上传选项，对象级ACL优先于客户端配置
+	options := []aliyun.Option{aliyun.ACL(client.Config.ACL)}
+	if opts.ACL != "" {
+		options = append(options, aliyun.ObjectACL(aliyun.ACLType(opts.ACL)))
+	}
+	if opts.ContentType != "" {
+		options = append(options, aliyun.ContentType(opts.ContentType))
+	}
+	if opts.ContentDisposition != "" {
+		options = append(options, aliyun.ContentDisposition(opts.ContentDisposition))
+	}
+	if opts.CacheControl != "" {
+		options = append(options, aliyun.CacheControl(opts.CacheControl))
+	}
+	for key, value := range opts.Metadata {
+		options = append(options, aliyun.Meta(key, value))
+	}
+
+	result, err := client.Bucket.InitiateMultipartUpload(client.ToRelativePath(urlPath), options...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.MultipartUpload{Path: urlPath, UploadID: result.UploadID, Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+// 返回:
+//   - *oss.Part: 已上传的分片
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	part, err := client.Bucket.UploadPart(client.initiateResult(upload), reader, size, number)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: part.PartNumber, ETag: part.ETag, Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号合并分片，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	oss.SortParts(parts)
+	uploadParts := make([]aliyun.UploadPart, 0, len(parts))
+	for _, part := range parts {
+		uploadParts = append(uploadParts, aliyun.UploadPart{PartNumber: part.Number, ETag: part.ETag})
+	}
+
+	if _, err := client.Bucket.CompleteMultipartUpload(client.initiateResult(upload), uploadParts); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(upload.Path); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: upload.Path, Name: filepath.Base(upload.Path), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传并释放已上传的分片
+// 参数:
+//   - upload: 分片上传会话
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	return wrapError(client.Bucket.AbortMultipartUpload(client.initiateResult(upload)))
+}
+
+// initiateResult 根据分片上传会话构建SDK的初始化结果
+func (client Client) initiateResult(upload *oss.MultipartUpload) aliyun.InitiateMultipartUploadResult {
+	return aliyun.InitiateMultipartUploadResult{
+		Bucket:   client.Bucket.BucketName,
+		Key:      client.ToRelativePath(upload.Path),
+		UploadID: upload.UploadID,
+	}
+}
@@ -0,0 +1,15 @@
+package aliyun
+
+import "strings"
+
+// BucketRegion 查询存储桶所在的区域
+// 返回:
+//   - string: 区域名，例如 cn-hangzhou，去掉了OSS返回的 oss- 前缀
+//   - error: 错误信息
+func (client Client) BucketRegion() (string, error) {
+	location, err := client.Bucket.Client.GetBucketLocation(client.Bucket.BucketName)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return strings.ToLower(strings.TrimPrefix(location, "oss-")), nil
+}
@@ -5,6 +5,8 @@ package azureblob
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 
 	"io"
@@ -19,7 +21,9 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Azure/azure-pipeline-go/pipeline"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/smart-unicom/oss"
 )
@@ -29,16 +33,21 @@ import (
 type Client struct {
 	Config       *Config                // 配置信息
 	containerURL *azblob.ContainerURL   // 容器URL对象
+	serviceURL   azblob.ServiceURL      // 服务URL对象，用于获取用户委托密钥
 }
 
 // Config Azure Blob存储配置
 // 包含连接Azure Blob存储所需的所有配置信息
 type Config struct {
 	AccessId  string // 账户名称
-	AccessKey string // 访问密钥
+	AccessKey string // 访问密钥，与 Credential 都为空时使用 NewDefaultCredential 获取Azure AD令牌
 	Region    string // 区域
 	Bucket    string // 容器名称
 	Endpoint  string // 端点URL
+
+	HTTPClient *http.Client     // 发送请求使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用SDK默认客户端
+	Retry      *oss.RetryConfig // 重试配置，转换为SDK的指数退避重试，为nil时使用SDK默认重试策略
+	Credential TokenCredential  // Azure AD令牌凭据，例如托管标识或工作负载标识，设置后代替 AccessKey 认证
 }
 
 // urlRegexp URL正则表达式，用于匹配HTTP/HTTPS URL格式
@@ -61,6 +70,22 @@ func (client Client) ToRelativePath(urlPath string) string {
 	return strings.TrimPrefix(urlPath, "/")
 }
 
+// maxBlobNameLength Azure Blob名称的最大字符数
+const maxBlobNameLength = 1024
+
+// validateBlobName 校验Blob名称长度
+// 参数:
+//   - blobName: Blob名称
+// 返回:
+//   - error: 超出限制时返回 *oss.KeyLengthError
+func validateBlobName(blobName string) error {
+	// Azure按字符而不是字节限制名称长度
+	if length := utf8.RuneCountInString(blobName); length > maxBlobNameLength {
+		return &oss.KeyLengthError{Key: blobName, Length: length, Limit: maxBlobNameLength}
+	}
+	return nil
+}
+
 // blobFormatString Azure Blob存储的URL格式模板
 const blobFormatString = `https://%s.blob.core.windows.net`
 
@@ -74,13 +99,33 @@ var (
 //   - config: Azure Blob存储配置
 // 返回:
 //   - *Client: Azure Blob存储客户端实例
-func New(config *Config) *Client {
+//   - error: 凭据无效时返回错误
+func New(config *Config) (*Client, error) {
 	// 创建客户端实例
 	var client = &Client{Config: config}
 
 	// 获取服务URL并初始化容器URL
-	serviceURL, _ := GetBlobService(config)
+	serviceURL, err := GetBlobService(config)
+	if err != nil {
+		return nil, err
+	}
+	client.serviceURL = serviceURL
 	client.containerURL = containerUrl(serviceURL, config)
+	return client, nil
+}
+
+// MustNew 创建新的Azure Blob存储客户端，失败时panic
+// 参数:
+//   - config: Azure Blob存储配置
+// 返回:
+//   - *Client: Azure Blob存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		panic(err)
+	}
 	return client
 }
 
@@ -91,24 +136,56 @@ func New(config *Config) *Client {
 //   - azblob.ServiceURL: 服务URL对象
 //   - error: 错误信息
 func GetBlobService(config *Config) (azblob.ServiceURL, error) {
-	// 使用存储账户名称和密钥创建凭据对象
-	credential, err := azblob.NewSharedKeyCredential(config.AccessId, config.AccessKey)
+	// 使用存储账户密钥或Azure AD令牌创建凭据对象
+	credential, err := newPipelineCredential(config)
 	if err != nil {
 		return azblob.ServiceURL{}, err
 	}
 
 	// 创建请求管道，用于处理HTTP(S)请求和响应
 	// 在更高级的场景中，可以配置遥测、重试策略、日志记录等选项
-	p := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+	var options azblob.PipelineOptions
+	if config.HTTPClient != nil {
+		options.HTTPSender = httpSender(config.HTTPClient)
+	}
+	if config.Retry != nil {
+		options.Retry = azblob.RetryOptions{
+			Policy:        azblob.RetryPolicyExponential,
+			MaxTries:      int32(config.Retry.Attempts()),
+			RetryDelay:    config.Retry.InitialDelay(),
+			MaxRetryDelay: config.Retry.MaxDelay(),
+		}
+	}
+	p := azblob.NewPipeline(credential, options)
 
 	// 从Azure门户获取存储账户的Blob服务URL端点
 	// URL通常格式为: https://accountname.blob.core.windows.net
-	u, _ := url.Parse(fmt.Sprintf(blobFormatString, config.AccessId))
+	u, err := url.Parse(fmt.Sprintf(blobFormatString, config.AccessId))
+	if err != nil {
+		return azblob.ServiceURL{}, err
+	}
 
 	// 创建包装服务URL和请求管道的ServiceURL对象
 	return azblob.NewServiceURL(*u, p), nil
 }
 
+// httpSender 使用自定义HTTP客户端发送请求的管道终点
+// 参数:
+//   - httpClient: HTTP客户端
+// 返回:
+//   - pipeline.Factory: 发送请求的管道工厂
+func httpSender(httpClient *http.Client) pipeline.Factory {
+	return pipeline.FactoryFunc(func(next pipeline.Policy, po *pipeline.PolicyOptions) pipeline.PolicyFunc {
+		return func(ctx context.Context, request pipeline.Request) (pipeline.Response, error) {
+			response, err := httpClient.Do(request.WithContext(ctx))
+			if err != nil {
+				err = pipeline.NewError(err, "HTTP request failed")
+			}
+			return pipeline.NewHTTPResponse(response), err
+		}
+	})
+}
+
 // containerUrl 获取容器URL对象
 // 参数:
 //   - serviceURL: 服务URL对象
@@ -130,12 +207,25 @@ func containerUrl(serviceURL azblob.ServiceURL, config *Config) *azblob.Containe
 //   - azblob.BlockBlobURL: 块Blob URL对象
 //   - error: 错误信息
 func (client Client) UploadBlob(blobName *string, blobType *string, data io.ReadSeeker) (azblob.BlockBlobURL, error) {
+	return client.uploadBlob(*blobName, azblob.BlobHTTPHeaders{ContentType: *blobType}, azblob.Metadata{}, data)
+}
+
+// uploadBlob 使用指定的HTTP头和元数据上传Blob
+// 参数:
+//   - blobName: Blob名称
+//   - headers: Blob的HTTP头
+//   - metadata: 用户自定义元数据
+//   - data: 要上传的数据流
+// 返回:
+//   - azblob.BlockBlobURL: 块Blob URL对象
+//   - error: 错误信息
+func (client Client) uploadBlob(blobName string, headers azblob.BlobHTTPHeaders, metadata azblob.Metadata, data io.ReadSeeker) (azblob.BlockBlobURL, error) {
 	// 创建引用Azure存储账户容器中Blob的URL
 	// 返回包装Blob URL和请求管道的BlockBlobURL对象
-	blobURL := client.containerURL.NewBlockBlobURL(*blobName) // Blob名称可以是混合大小写
+	blobURL := client.containerURL.NewBlockBlobURL(blobName) // Blob名称可以是混合大小写
 
 	// 上传Blob数据
-	_, err := blobURL.Upload(ctx, data, azblob.BlobHTTPHeaders{ContentType: *blobType}, azblob.Metadata{}, azblob.BlobAccessConditions{}, azblob.DefaultAccessTier, nil, azblob.ClientProvidedKeyOptions{}, azblob.ImmutabilityPolicyOptions{})
+	_, err := blobURL.Upload(ctx, data, headers, metadata, azblob.BlobAccessConditions{}, azblob.DefaultAccessTier, nil, azblob.ClientProvidedKeyOptions{}, azblob.ImmutabilityPolicyOptions{})
 	if err != nil {
 		return azblob.BlockBlobURL{}, err
 	}
@@ -212,30 +302,13 @@ func (client Client) GetListBlob() ([][]azblob.BlobItemInternal, error) {
 //   - *os.File: 文件对象
 //   - error: 错误信息
 func (client Client) Get(path string) (file *os.File, err error) {
-	// 转换为相对路径
-	path = client.ToRelativePath(path)
 	// 获取文件流
-	readCloser, err := client.GetStream(path)
-
-	if err == nil {
-		// 创建临时文件
-		if file, err = ioutil.TempFile("/tmp", "ali"); err == nil {
-			defer func(readCloser io.ReadCloser) {
-				err := readCloser.Close()
-				if err != nil {
-
-				}
-			}(readCloser)
-			// 将流内容复制到临时文件
-			_, err = io.Copy(file, readCloser)
-			// 重置文件指针到开始位置
-			_, err := file.Seek(0, 0)
-			if err != nil {
-				return nil, err
-			}
-		}
+	readCloser, err := client.GetStream(client.ToRelativePath(path))
+	if err != nil {
+		return nil, err
 	}
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "azureblob")
 }
 
 // GetStream 获取指定路径文件的流
@@ -249,11 +322,80 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	// 下载Blob并返回响应体
 	blob, err := client.DownloadBlob(&name)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return blob.Response().Body, err
 }
 
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用Blob范围下载只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	// Download 使用0表示读取到末尾
+	if length < 0 {
+		length = 0
+	}
+	blobURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(path))
+	blob, err := blobURL.Download(ctx, offset, length, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return blob.Response().Body, nil
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 转换为相对路径
+	path = client.ToRelativePath(path)
+	blobURL := client.containerURL.NewBlockBlobURL(path)
+
+	// 获取Blob属性
+	properties, err := blobURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Object{
+		Path:             path,
+		Name:             filepath.Base(path),
+		LastModified:     oss.NormalizeTime(properties.LastModified()),
+		Size:             properties.ContentLength(),
+		ContentType:      properties.ContentType(),
+		ETag:             string(properties.ETag()),
+		Metadata:         oss.NormalizeMetadata(properties.NewMetadata()),
+		StorageInterface: client,
+	}, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	_, err := client.Stat(path)
+	if errors.Is(err, oss.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // Put 上传文件到指定路径
 // 参数:
 //   - urlPath: 文件路径
@@ -262,6 +404,46 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传成功后的对象信息
 //   - error: 错误信息
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 文件路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传成功后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// Azure Blob不支持对象级ACL，访问级别只能在容器上设置
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: azure blob does not support per-object ACL", oss.ErrNotSupported)
+	}
+	// Azure Blob不支持对象标签
+	if len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("%w: azure blob does not support object tags", oss.ErrNotSupported)
+	}
+
+	// 按冲突策略确定上传路径
+	resolved, err := opts.ResolvePath(client, urlPath)
+	if err != nil {
+		return nil, err
+	}
+	urlPath = resolved
+
 	// 如果reader支持Seek，重置到开始位置
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		_, err := seeker.Seek(0, 0)
@@ -271,33 +453,85 @@ func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error)
 	}
 	// 转换为相对路径
 	urlPath = client.ToRelativePath(urlPath)
+	// 在发送请求前校验Blob名称长度
+	if err := validateBlobName(urlPath); err != nil {
+		return nil, err
+	}
 	// 读取所有数据到缓冲区
 	buffer, err := ioutil.ReadAll(reader)
 
 	// 检测文件类型
-	fileType := mime.TypeByExtension(path.Ext(urlPath))
+	fileType := opts.ContentType
 	if fileType == "" {
-		fileType = http.DetectContentType(buffer)
+		fileType = mime.TypeByExtension(path.Ext(urlPath))
 	}
-
 	if fileType == "" {
 		fileType = http.DetectContentType(buffer)
 	}
 
 	// 上传Blob到Azure存储
-	_, err = client.UploadBlob(&urlPath, &fileType, bytes.NewReader(buffer))
+	headers := azblob.BlobHTTPHeaders{
+		ContentType:        fileType,
+		ContentDisposition: opts.ContentDisposition,
+		CacheControl:       opts.CacheControl,
+	}
+	// 设置内容MD5由服务端校验
+	if opts.Checksum != "" {
+		if opts.Checksum != oss.ChecksumMD5 {
+			return nil, fmt.Errorf("%w: azure blob does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+		}
+		checksum, err := opts.ContentChecksum(buffer)
+		if err != nil {
+			return nil, err
+		}
+		headers.ContentMD5, _ = base64.StdEncoding.DecodeString(checksum)
+	}
+	// 回调上传进度，bytes.Reader可寻址，包装后仍然满足 io.ReadSeeker
+	body := opts.WrapReader(bytes.NewReader(buffer), int64(len(buffer))).(io.ReadSeeker)
+	_, err = client.uploadBlob(urlPath, headers, azblob.Metadata(opts.Metadata), body)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
-	now := time.Now()
 
 	// 创建返回对象
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, err
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if stat, err := client.Stat(urlPath); err == nil {
+		object.LastModified = stat.LastModified
+		object.Size = stat.Size
+	}
+
+	return object, err
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 使用块Blob的流式上传，按块分段上传
+// 参数:
+//   - urlPath: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(urlPath string) (io.WriteCloser, error) {
+	// 与 PutWithOptions 相同，规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	urlPath = client.ToRelativePath(urlPath)
+	if err := validateBlobName(urlPath); err != nil {
+		return nil, err
+	}
+
+	return oss.NewPipeWriter(func(reader io.Reader) error {
+		_, err := azblob.UploadStreamToBlockBlob(ctx, reader, client.containerURL.NewBlockBlobURL(urlPath), azblob.UploadStreamToBlockBlobOptions{
+			BlobHTTPHeaders: azblob.BlobHTTPHeaders{ContentType: mime.TypeByExtension(path.Ext(urlPath))},
+		})
+		return wrapError(err)
+	}), nil
 }
 
 // Delete 删除指定路径的文件
@@ -308,17 +542,155 @@ func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error)
 func (client Client) Delete(path string) error {
 	// 转换为相对路径
 	path = client.ToRelativePath(path)
-	return client.DeleteBlob(&path)
+	return wrapError(client.DeleteBlob(&path))
+}
+
+// DeleteObjects 批量删除多个文件
+// Azure Blob 存储没有批量删除接口，并发逐个删除
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsConcurrently(client, paths, oss.DefaultDeleteConcurrency)
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分段列举目录下的Blob，每段并发逐个删除
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(client.ToRelativePath(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		listBlob, err := client.containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{Prefix: prefix})
+		if err != nil {
+			return wrapError(err)
+		}
+		marker = listBlob.NextMarker
+
+		var paths []string
+		for _, blobInfo := range listBlob.Segment.BlobItems {
+			paths = append(paths, blobInfo.Name)
+		}
+		if len(paths) > 0 {
+			if err := client.DeleteObjects(paths); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := validateBlobName(client.ToRelativePath(dstPath)); err != nil {
+		return err
+	}
+
+	srcURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(srcPath)).URL()
+	dstURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(dstPath))
+
+	status, err := copyFromURL(srcURL, dstURL)
+	if err != nil {
+		return err
+	}
+	if status != azblob.CopyStatusSuccess {
+		return fmt.Errorf("copy %s to %s failed, status: %s", srcPath, dstPath, status)
+	}
+	return nil
+}
+
+// copyFromURL 启动服务端复制（Copy Blob）并等待复制结束
+// 参数:
+//   - srcURL: 源Blob地址
+//   - dstURL: 目标Blob
+// 返回:
+//   - azblob.CopyStatusType: 复制结束时的状态
+//   - error: 错误信息
+func copyFromURL(srcURL url.URL, dstURL azblob.BlockBlobURL) (azblob.CopyStatusType, error) {
+	response, err := dstURL.StartCopyFromURL(ctx, srcURL, azblob.Metadata{}, azblob.ModifiedAccessConditions{}, azblob.BlobAccessConditions{}, azblob.DefaultAccessTier, nil)
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	// 复制是异步完成的，轮询直到复制结束
+	status := response.CopyStatus()
+	for status == azblob.CopyStatusPending {
+		time.Sleep(time.Second)
+		properties, err := dstURL.GetProperties(ctx, azblob.BlobAccessConditions{}, azblob.ClientProvidedKeyOptions{})
+		if err != nil {
+			return "", wrapError(err)
+		}
+		status = properties.CopyStatus()
+	}
+	return status, nil
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 对象存储不支持重命名，通过服务端复制后删除源文件实现
+	return oss.MoveByCopy(client, srcPath, dstPath)
 }
 
 // List 列出指定路径下的所有对象
+// 分段列举指定前缀的Blob，按名称字典序返回
 // 参数:
 //   - path: 路径前缀
 // 返回:
 //   - []*oss.Object: 对象列表
 //   - error: 错误信息
 func (client Client) List(path string) ([]*oss.Object, error) {
-	panic("implement me")
+	var objects []*oss.Object
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		listBlob, err := client.containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{
+			Prefix:  client.ToRelativePath(path),
+			Details: azblob.BlobListingDetails{Metadata: true},
+		})
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		marker = listBlob.NextMarker
+
+		for _, blobInfo := range listBlob.Segment.BlobItems {
+			object := &oss.Object{
+				Path:             "/" + blobInfo.Name,
+				Name:             filepath.Base(blobInfo.Name),
+				LastModified:     oss.NormalizeTime(blobInfo.Properties.LastModified),
+				ETag:             string(blobInfo.Properties.Etag),
+				Metadata:         oss.NormalizeMetadata(blobInfo.Metadata),
+				StorageInterface: client,
+			}
+			if blobInfo.Properties.ContentLength != nil {
+				object.Size = *blobInfo.Properties.ContentLength
+			}
+			if blobInfo.Properties.ContentType != nil {
+				object.ContentType = *blobInfo.Properties.ContentType
+			}
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
 }
 
 // GetURL 获取文件的访问URL
@@ -331,6 +703,94 @@ func (client Client) GetURL(path string) (string, error) {
 	return path, nil
 }
 
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// 使用存储账户密钥或用户委托密钥签名
+	expiresAt := oss.Now().UTC().Add(opts.Expiry)
+	credential, err := client.sasCredential(expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	permissions := azblob.BlobSASPermissions{Read: true}
+	if opts.Method == http.MethodPut {
+		permissions = azblob.BlobSASPermissions{Create: true, Write: true}
+	}
+
+	// 生成Blob级别的SAS令牌
+	blobName := client.ToRelativePath(path)
+	sasQuery, err := azblob.BlobSASSignatureValues{
+		Protocol:           azblob.SASProtocolHTTPS,
+		ExpiryTime:         expiresAt,
+		ContainerName:      client.Config.Bucket,
+		BlobName:           blobName,
+		Permissions:        permissions.String(),
+		ContentType:        opts.ResponseContentType,
+		ContentDisposition: opts.ResponseContentDisposition,
+		CacheControl:       opts.ResponseCacheControl,
+	}.NewSASQueryParameters(credential)
+	if err != nil {
+		return "", err
+	}
+
+	blobURL := client.containerURL.NewBlockBlobURL(blobName).URL()
+	blobURL.RawQuery = sasQuery.Encode()
+	return blobURL.String(), nil
+}
+
+// GetUploadURL 生成带SAS令牌的PUT直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+
+	signedURL, err := client.GetSignedURL(path, oss.SignedURLOptions{Expiry: opts.Expiry, Method: http.MethodPut})
+	if err != nil {
+		return nil, err
+	}
+
+	// Put Blob请求必须指定Blob类型
+	headers := opts.Headers()
+	headers["x-ms-blob-type"] = string(azblob.BlobBlockBlob)
+
+	return &oss.UploadURL{
+		URL:       signedURL,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: oss.Now().Add(opts.Expiry),
+	}, nil
+}
+
+// ContainerURL 返回Azure Blob SDK的容器URL对象，用于统一接口没有覆盖的功能
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - *azblob.ContainerURL: 容器URL对象
+func (client Client) ContainerURL() *azblob.ContainerURL {
+	return client.containerURL
+}
+
+// Underlying 返回Azure Blob SDK的容器URL对象，与 ContainerURL 相同
+// 返回:
+//   - interface{}: *azblob.ContainerURL
+func (client Client) Underlying() interface{} {
+	return client.containerURL
+}
+
 // GetEndpoint 获取存储端点
 // 返回:
 //   - string: 存储端点URL
@@ -342,3 +802,53 @@ func (client Client) GetEndpoint() string {
 	// 否则使用默认的Azure Blob存储端点格式
 	return fmt.Sprintf(blobFormatString, client.Config.AccessId)
 }
+
+func init() {
+	oss.RegisterHTTPStatusMapper(httpStatus)
+}
+
+// httpStatus 将Azure Blob存储的错误转换为HTTP状态码
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码
+//   - bool: 是否识别该错误
+func httpStatus(err error) (int, bool) {
+	var storageError azblob.StorageError
+	if errors.As(err, &storageError) && storageError.Response() != nil {
+		return storageError.Response().StatusCode, true
+	}
+	return 0, false
+}
+
+// wrapError 将Azure Blob存储的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var storageError azblob.StorageError
+	if !errors.As(err, &storageError) {
+		return err
+	}
+
+	switch storageError.ServiceCode() {
+	case azblob.ServiceCodeBlobNotFound:
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case azblob.ServiceCodeContainerNotFound:
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case azblob.ServiceCodeAuthenticationFailed, azblob.ServiceCodeInsufficientAccountPermissions:
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	}
+
+	// HEAD请求的错误响应没有错误码，只能根据状态码判断
+	if storageError.Response() != nil {
+		switch storageError.Response().StatusCode {
+		case http.StatusNotFound:
+			return oss.WrapError(oss.ErrObjectNotFound, err)
+		case http.StatusForbidden:
+			return oss.WrapError(oss.ErrAccessDenied, err)
+		}
+	}
+	return err
+}
@@ -12,13 +12,17 @@ import (
 var client *Client
 
 func init() {
-	client = New(&Config{
+	var err error
+	client, err = New(&Config{
 		AccessId:  "",
 		AccessKey: "",
 		Bucket:    "",
 		Region:    "",
 		Endpoint:  "localhost:8080",
 	})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestClientPut(t *testing.T) {
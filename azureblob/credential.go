@@ -0,0 +1,344 @@
+package azureblob
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/smart-unicom/oss"
+)
+
+const (
+	// storageResource 托管标识换取存储服务令牌使用的资源
+	storageResource = "https://storage.azure.com/"
+	// storageScope Azure AD换取存储服务令牌使用的范围
+	storageScope = storageResource + ".default"
+	// defaultAuthorityHost 默认的Azure AD登录地址
+	defaultAuthorityHost = "https://login.microsoftonline.com/"
+	// imdsEndpoint 虚拟机和AKS节点上的实例元数据服务令牌地址
+	imdsEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// ErrNoCredential 没有可用的Azure AD凭据
+var ErrNoCredential = errors.New("azureblob: no credential available")
+
+// AccessToken Azure AD访问令牌
+type AccessToken struct {
+	// Token 令牌值
+	Token string
+	// ExpiresOn 过期时间
+	ExpiresOn time.Time
+}
+
+// TokenCredential 获取访问存储服务的Azure AD令牌
+// 配置到 Config.Credential 后使用令牌认证代替存储账户密钥，令牌在过期前自动刷新
+type TokenCredential interface {
+	// Token 获取访问存储服务的令牌
+	// 参数:
+	//   - ctx: 上下文
+	// 返回:
+	//   - AccessToken: 访问令牌
+	//   - error: 错误信息
+	Token(ctx context.Context) (AccessToken, error)
+}
+
+// ClientSecretCredential 使用应用注册的客户端密码获取令牌
+type ClientSecretCredential struct {
+	// TenantID 租户ID
+	TenantID string
+	// ClientID 应用（客户端）ID
+	ClientID string
+	// ClientSecret 客户端密码
+	ClientSecret string
+	// AuthorityHost Azure AD登录地址，为空时使用 AZURE_AUTHORITY_HOST 环境变量或公有云地址
+	AuthorityHost string
+	// HTTPClient 发送令牌请求使用的HTTP客户端，为nil时使用 http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Token 通过 client_credentials 授权获取令牌
+func (credential *ClientSecretCredential) Token(ctx context.Context) (AccessToken, error) {
+	return requestToken(ctx, credential.HTTPClient, credential.AuthorityHost, credential.TenantID, url.Values{
+		"client_id":     {credential.ClientID},
+		"client_secret": {credential.ClientSecret},
+	})
+}
+
+// WorkloadIdentityCredential 使用联合令牌文件获取令牌
+// 用于AKS工作负载标识，Pod中的服务账户令牌由Kubernetes写入 TokenFile 并定期轮换，每次获取令牌时重新读取
+type WorkloadIdentityCredential struct {
+	// TenantID 租户ID，为空时使用 AZURE_TENANT_ID 环境变量
+	TenantID string
+	// ClientID 托管标识或应用的客户端ID，为空时使用 AZURE_CLIENT_ID 环境变量
+	ClientID string
+	// TokenFile 联合令牌文件路径，为空时使用 AZURE_FEDERATED_TOKEN_FILE 环境变量
+	TokenFile string
+	// AuthorityHost Azure AD登录地址，为空时使用 AZURE_AUTHORITY_HOST 环境变量或公有云地址
+	AuthorityHost string
+	// HTTPClient 发送令牌请求使用的HTTP客户端，为nil时使用 http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Token 使用联合令牌作为客户端断言获取令牌
+func (credential *WorkloadIdentityCredential) Token(ctx context.Context) (AccessToken, error) {
+	tokenFile := firstNonEmpty(credential.TokenFile, os.Getenv("AZURE_FEDERATED_TOKEN_FILE"))
+	if tokenFile == "" {
+		return AccessToken{}, fmt.Errorf("%w: federated token file is not configured", ErrNoCredential)
+	}
+	assertion, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	return requestToken(ctx, credential.HTTPClient, credential.AuthorityHost, firstNonEmpty(credential.TenantID, os.Getenv("AZURE_TENANT_ID")), url.Values{
+		"client_id":             {firstNonEmpty(credential.ClientID, os.Getenv("AZURE_CLIENT_ID"))},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+	})
+}
+
+// ManagedIdentityCredential 使用托管标识获取令牌
+// 设置了 IDENTITY_ENDPOINT 和 IDENTITY_HEADER 环境变量时（App Service、Functions、Container Apps）使用其中的地址，否则使用实例元数据服务
+type ManagedIdentityCredential struct {
+	// ClientID 用户分配的托管标识的客户端ID，为空时使用系统分配的托管标识
+	ClientID string
+	// HTTPClient 发送令牌请求使用的HTTP客户端，为nil时使用超时为5秒的客户端
+	HTTPClient *http.Client
+}
+
+// Token 从托管标识服务获取令牌
+func (credential *ManagedIdentityCredential) Token(ctx context.Context) (AccessToken, error) {
+	query := url.Values{"resource": {storageResource}}
+	if credential.ClientID != "" {
+		query.Set("client_id", credential.ClientID)
+	}
+
+	var request *http.Request
+	var err error
+	if endpoint, header := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER"); endpoint != "" && header != "" {
+		query.Set("api-version", "2019-08-01")
+		if request, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil); err == nil {
+			request.Header.Set("X-IDENTITY-HEADER", header)
+		}
+	} else {
+		query.Set("api-version", "2018-02-01")
+		if request, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsEndpoint+"?"+query.Encode(), nil); err == nil {
+			request.Header.Set("Metadata", "true")
+		}
+	}
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	httpClient := credential.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return doTokenRequest(httpClient, request)
+}
+
+// ChainedCredential 依次尝试多个凭据，使用第一个成功获取令牌的凭据
+// 成功后固定使用该凭据，之后的失败不再尝试其他凭据
+type ChainedCredential struct {
+	// Credentials 依次尝试的凭据
+	Credentials []TokenCredential
+
+	mutex    sync.Mutex
+	selected TokenCredential
+}
+
+// Token 使用第一个可用的凭据获取令牌
+// 返回:
+//   - AccessToken: 访问令牌
+//   - error: 全部凭据都失败时返回包含每个凭据错误的 ErrNoCredential
+func (credential *ChainedCredential) Token(ctx context.Context) (AccessToken, error) {
+	credential.mutex.Lock()
+	selected := credential.selected
+	credential.mutex.Unlock()
+	if selected != nil {
+		return selected.Token(ctx)
+	}
+
+	errs := []error{ErrNoCredential}
+	for _, candidate := range credential.Credentials {
+		token, err := candidate.Token(ctx)
+		if err == nil {
+			credential.mutex.Lock()
+			credential.selected = candidate
+			credential.mutex.Unlock()
+			return token, nil
+		}
+		errs = append(errs, fmt.Errorf("%T: %w", candidate, err))
+	}
+	return AccessToken{}, errors.Join(errs...)
+}
+
+// NewDefaultCredential 创建与 DefaultAzureCredential 类似的凭据链
+// 依次尝试：设置了 AZURE_TENANT_ID、AZURE_CLIENT_ID 和 AZURE_CLIENT_SECRET 环境变量时的客户端密码，
+// 设置了 AZURE_FEDERATED_TOKEN_FILE 环境变量时的工作负载标识（AKS Pod），以及托管标识（AZURE_CLIENT_ID 为用户分配的托管标识）
+// 参数:
+//   - httpClient: 发送令牌请求使用的HTTP客户端，为nil时使用默认客户端
+// 返回:
+//   - *ChainedCredential: 凭据链
+func NewDefaultCredential(httpClient *http.Client) *ChainedCredential {
+	var credentials []TokenCredential
+	tenantID, clientID := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID")
+	if secret := os.Getenv("AZURE_CLIENT_SECRET"); tenantID != "" && clientID != "" && secret != "" {
+		credentials = append(credentials, &ClientSecretCredential{TenantID: tenantID, ClientID: clientID, ClientSecret: secret, HTTPClient: httpClient})
+	}
+	if os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" {
+		credentials = append(credentials, &WorkloadIdentityCredential{HTTPClient: httpClient})
+	}
+	credentials = append(credentials, &ManagedIdentityCredential{ClientID: clientID, HTTPClient: httpClient})
+	return &ChainedCredential{Credentials: credentials}
+}
+
+// requestToken 向Azure AD的令牌地址发送 client_credentials 请求
+func requestToken(ctx context.Context, httpClient *http.Client, authorityHost, tenantID string, form url.Values) (AccessToken, error) {
+	if tenantID == "" || form.Get("client_id") == "" {
+		return AccessToken{}, fmt.Errorf("%w: tenant id and client id are required", ErrNoCredential)
+	}
+	authorityHost = firstNonEmpty(authorityHost, os.Getenv("AZURE_AUTHORITY_HOST"), defaultAuthorityHost)
+	endpoint := strings.TrimSuffix(authorityHost, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", storageScope)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return AccessToken{}, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return doTokenRequest(httpClient, request)
+}
+
+// doTokenRequest 发送令牌请求并解析响应
+// Azure AD返回数字的 expires_in，托管标识服务返回字符串的 expires_in 和 expires_on
+func doTokenRequest(httpClient *http.Client, request *http.Request) (AccessToken, error) {
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return AccessToken{}, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return AccessToken{}, fmt.Errorf("azureblob: token request to %s failed with status %d: %s", request.URL.Host, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		AccessToken string  `json:"access_token"`
+		ExpiresIn   seconds `json:"expires_in"`
+		ExpiresOn   seconds `json:"expires_on"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.AccessToken == "" {
+		return AccessToken{}, fmt.Errorf("azureblob: invalid token response from %s", request.URL.Host)
+	}
+
+	token := AccessToken{Token: result.AccessToken, ExpiresOn: oss.Now().Add(time.Duration(result.ExpiresIn) * time.Second)}
+	if result.ExpiresOn > 0 {
+		token.ExpiresOn = time.Unix(int64(result.ExpiresOn), 0)
+	} else if result.ExpiresIn <= 0 {
+		token.ExpiresOn = oss.Now().Add(time.Hour)
+	}
+	return token, nil
+}
+
+// seconds 秒数，兼容JSON数字和字符串形式的数字
+type seconds int64
+
+// UnmarshalJSON 解析JSON数字或字符串形式的数字
+func (value *seconds) UnmarshalJSON(data []byte) error {
+	n, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	*value = seconds(n)
+	return err
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+const (
+	// tokenRefreshMargin 令牌过期前提前刷新的时间
+	tokenRefreshMargin = 5 * time.Minute
+	// tokenRetryDelay 刷新令牌失败后重试的间隔，重试前继续使用旧令牌
+	tokenRetryDelay = 30 * time.Second
+)
+
+// usesSharedKey 是否使用存储账户密钥认证
+func usesSharedKey(config *Config) bool {
+	return config.Credential == nil && config.AccessKey != ""
+}
+
+// newPipelineCredential 创建请求管道使用的凭据
+// 配置了 AccessKey 且没有配置 Credential 时使用共享密钥，否则获取Azure AD令牌并在过期前自动刷新
+// 参数:
+//   - config: Azure Blob存储配置
+// 返回:
+//   - azblob.Credential: 请求管道凭据
+//   - error: 首次获取令牌失败时返回错误
+func newPipelineCredential(config *Config) (azblob.Credential, error) {
+	if usesSharedKey(config) {
+		return azblob.NewSharedKeyCredential(config.AccessId, config.AccessKey)
+	}
+	source := config.Credential
+	if source == nil {
+		source = NewDefaultCredential(config.HTTPClient)
+	}
+	current, err := source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// SDK创建凭据时立即调用一次刷新函数，令牌未临近过期时只返回等待时间
+	return azblob.NewTokenCredential(current.Token, func(credential azblob.TokenCredential) time.Duration {
+		if wait := current.ExpiresOn.Sub(oss.Now()) - tokenRefreshMargin; wait > 0 {
+			return wait
+		}
+		refreshed, err := source.Token(ctx)
+		if err != nil {
+			return tokenRetryDelay
+		}
+		current = refreshed
+		credential.SetToken(refreshed.Token)
+		return max(refreshed.ExpiresOn.Sub(oss.Now())-tokenRefreshMargin, tokenRetryDelay)
+	}), nil
+}
+
+// sasCredential 返回签名SAS使用的凭据
+// 使用Azure AD令牌认证时没有账户密钥，改为获取用户委托密钥生成用户委托SAS，主体需要具有 Storage Blob Delegator 角色
+// 参数:
+//   - expiresAt: SAS的过期时间，用户委托密钥最长有效7天
+// 返回:
+//   - azblob.StorageAccountCredential: 签名凭据
+//   - error: 错误信息
+func (client Client) sasCredential(expiresAt time.Time) (azblob.StorageAccountCredential, error) {
+	if usesSharedKey(client.Config) {
+		return azblob.NewSharedKeyCredential(client.Config.AccessId, client.Config.AccessKey)
+	}
+	// 开始时间提前一分钟，避免与服务端的时钟偏差导致签名尚未生效
+	credential, err := client.serviceURL.GetUserDelegationCredential(ctx, azblob.NewKeyInfo(oss.Now().Add(-time.Minute), expiresAt), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
@@ -0,0 +1,129 @@
+package azureblob
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"path/filepath"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/smart-unicom/oss"
+)
+
+// InitiateMultipart 初始化分片上传
+// 块Blob没有服务端的上传会话，上传ID只用于生成块ID，未提交的块由服务端在7天后自动清理
+// 参数:
+//   - urlPath: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值，内容类型等选项在完成上传时应用
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(urlPath string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// Azure Blob不支持对象级ACL，访问级别只能在容器上设置
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: azure blob does not support per-object ACL", oss.ErrNotSupported)
+	}
+
+	// 在发送请求前校验Blob名称长度
+	urlPath = client.ToRelativePath(urlPath)
+	if err := validateBlobName(urlPath); err != nil {
+		return nil, err
+	}
+
+	id, err := oss.RandomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	return &oss.MultipartUpload{Path: urlPath, UploadID: id, Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片，分片作为未提交的块暂存
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+// 返回:
+//   - *oss.Part: 已上传的分片，ETag为块ID
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	// SDK暂存块时需要重读分片内容
+	body, err := oss.SeekablePart(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	blockID := blockID(upload.UploadID, number)
+	blobURL := client.containerURL.NewBlockBlobURL(upload.Path)
+	if _, err := blobURL.StageBlock(ctx, blockID, body, azblob.LeaseAccessConditions{}, nil, azblob.ClientProvidedKeyOptions{}); err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: number, ETag: blockID, Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号提交块列表，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	oss.SortParts(parts)
+	blockIDs := make([]string, 0, len(parts))
+	for _, part := range parts {
+		blockIDs = append(blockIDs, part.ETag)
+	}
+
+	fileType := upload.Options.ContentType
+	if fileType == "" {
+		fileType = mime.TypeByExtension(path.Ext(upload.Path))
+	}
+	headers := azblob.BlobHTTPHeaders{
+		ContentType:        fileType,
+		ContentDisposition: upload.Options.ContentDisposition,
+		CacheControl:       upload.Options.CacheControl,
+	}
+
+	blobURL := client.containerURL.NewBlockBlobURL(upload.Path)
+	_, err := blobURL.CommitBlockList(ctx, blockIDs, headers, azblob.Metadata(upload.Options.Metadata), azblob.BlobAccessConditions{},
+		azblob.DefaultAccessTier, nil, azblob.ClientProvidedKeyOptions{}, azblob.ImmutabilityPolicyOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(upload.Path); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: upload.Path, Name: filepath.Base(upload.Path), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传
+// 块Blob无法删除未提交的块，由服务端在7天后自动清理，这里不发送请求
+// 参数:
+//   - upload: 分片上传会话
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	return nil
+}
+
+// blockID 生成分片对应的块ID
+// 同一个Blob的块ID编码前长度必须相同，因此分片编号使用固定宽度
+func blockID(uploadID string, number int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s-%05d", uploadID, number)))
+}
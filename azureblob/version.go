@@ -0,0 +1,109 @@
+package azureblob
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/smart-unicom/oss"
+)
+
+// ListVersions 列出Blob的全部版本
+// 需要在存储账户上开启Blob版本控制，删除当前版本后只保留历史版本，没有删除标记
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - []*oss.ObjectVersion: 版本列表，按修改时间从新到旧排列
+//   - error: 错误信息
+func (client Client) ListVersions(path string) ([]*oss.ObjectVersion, error) {
+	name := client.ToRelativePath(path)
+
+	var versions []*oss.ObjectVersion
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		listBlob, err := client.containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{
+			Prefix:  name,
+			Details: azblob.BlobListingDetails{Versions: true},
+		})
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		marker = listBlob.NextMarker
+
+		// 前缀匹配会返回同名前缀的其他Blob，只保留名称完全相同的版本
+		for _, blobInfo := range listBlob.Segment.BlobItems {
+			if blobInfo.Name != name || blobInfo.VersionID == nil {
+				continue
+			}
+
+			version := &oss.ObjectVersion{
+				Object: oss.Object{
+					Path:             name,
+					Name:             filepath.Base(name),
+					LastModified:     oss.NormalizeTime(blobInfo.Properties.LastModified),
+					ETag:             string(blobInfo.Properties.Etag),
+					StorageInterface: client,
+				},
+				VersionID: *blobInfo.VersionID,
+				IsLatest:  blobInfo.IsCurrentVersion != nil && *blobInfo.IsCurrentVersion,
+			}
+			if blobInfo.Properties.ContentLength != nil {
+				version.Size = *blobInfo.Properties.ContentLength
+			}
+			if blobInfo.Properties.ContentType != nil {
+				version.ContentType = *blobInfo.Properties.ContentType
+			}
+			versions = append(versions, version)
+		}
+	}
+
+	oss.SortVersions(versions)
+	return versions, nil
+}
+
+// GetVersion 获取Blob指定版本的内容
+// 参数:
+//   - path: 对象路径
+//   - versionID: 版本ID
+// 返回:
+//   - io.ReadCloser: 版本内容
+//   - error: 错误信息
+func (client Client) GetVersion(path string, versionID string) (io.ReadCloser, error) {
+	blobURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(path)).WithVersionID(versionID)
+	blob, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false, azblob.ClientProvidedKeyOptions{})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return blob.Response().Body, nil
+}
+
+// DeleteVersion 永久删除Blob的指定版本
+// 参数:
+//   - path: 对象路径
+//   - versionID: 版本ID
+// 返回:
+//   - error: 错误信息
+func (client Client) DeleteVersion(path string, versionID string) error {
+	blobURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(path)).WithVersionID(versionID)
+	_, err := blobURL.Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{})
+	return wrapError(err)
+}
+
+// RestoreVersion 通过服务端复制将指定版本恢复为当前版本
+// 参数:
+//   - path: 对象路径
+//   - versionID: 版本ID
+// 返回:
+//   - error: 错误信息
+func (client Client) RestoreVersion(path string, versionID string) error {
+	blobURL := client.containerURL.NewBlockBlobURL(client.ToRelativePath(path))
+
+	status, err := copyFromURL(blobURL.WithVersionID(versionID).URL(), blobURL)
+	if err != nil {
+		return err
+	}
+	if status != azblob.CopyStatusSuccess {
+		return fmt.Errorf("restore %s to version %s failed, status: %s", path, versionID, status)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency PutAll、DeleteAll、Migrate 和 StatMany 默认的并发数
+const DefaultBatchConcurrency = 8
+
+// BatchResult 批量操作中单个对象的结果
+type BatchResult struct {
+	// Path 对象路径
+	Path string
+	// Err 错误信息，为nil时表示成功
+	Err error
+	// Bytes 上传后的对象大小，失败或删除操作为0
+	Bytes int64
+	// Duration 处理该对象所用的时间
+	Duration time.Duration
+}
+
+// BatchFailed 返回批量操作中失败的结果，用于只重试失败的对象
+// 参数:
+//   - results: 批量操作的结果
+// 返回:
+//   - []BatchResult: 失败的结果，保持原有顺序
+func BatchFailed(results []BatchResult) []BatchResult {
+	var failed []BatchResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// BatchError 将批量操作的结果合并为一个错误
+// 参数:
+//   - results: 批量操作的结果
+// 返回:
+//   - error: 全部成功时返回nil，否则返回包含每个失败对象的错误，可以使用 errors.Is 匹配统一错误类型
+func BatchError(results []BatchResult) error {
+	var errs []error
+	for _, result := range BatchFailed(results) {
+		errs = append(errs, fmt.Errorf("%s: %w", result.Path, result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// PutAllOrRollback 上传一组相关的对象，任意一个失败时删除已上传的对象
+// 按路径字典序依次上传，回滚为尽力而为：上传前已存在并被覆盖的对象无法恢复
+// 参数:
+//   - storage: 存储接口
+//   - readers: 目标路径到文件内容读取器的映射
+// 返回:
+//   - []*Object: 全部成功时返回上传后的对象信息
+//   - error: 错误信息，包含上传失败和回滚失败的原因
+func PutAllOrRollback(storage StorageInterface, readers map[string]io.Reader) ([]*Object, error) {
+	paths := make([]string, 0, len(readers))
+	for path := range readers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	objects := make([]*Object, 0, len(paths))
+	for _, path := range paths {
+		object, err := storage.Put(path, readers[path])
+		if err == nil {
+			objects = append(objects, object)
+			continue
+		}
+
+		// 删除已经上传成功的对象
+		var rollbackErrs []error
+		for _, uploaded := range objects {
+			if deleteErr := storage.Delete(uploaded.Path); deleteErr != nil {
+				rollbackErrs = append(rollbackErrs, fmt.Errorf("%s: %w", uploaded.Path, deleteErr))
+			}
+		}
+
+		if len(rollbackErrs) > 0 {
+			return nil, fmt.Errorf("put %s failed: %w, rollback failed: %v", path, err, rollbackErrs)
+		}
+		return nil, fmt.Errorf("put %s failed: %w", path, err)
+	}
+
+	return objects, nil
+}
+
+// PutAll 并发上传一组对象，返回每个对象的结果
+// 与 PutAllOrRollback 不同，失败的对象不影响其他对象，已上传的对象不会被删除
+// 参数:
+//   - storage: 存储接口
+//   - readers: 目标路径到文件内容读取器的映射
+//   - concurrency: 并发数，小于等于0时使用 DefaultBatchConcurrency
+// 返回:
+//   - []BatchResult: 每个对象的结果，按路径字典序排列
+func PutAll(storage StorageInterface, readers map[string]io.Reader, concurrency int) []BatchResult {
+	paths := make([]string, 0, len(readers))
+	for path := range readers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return runBatch(paths, concurrency, func(path string) (int64, error) {
+		object, err := storage.Put(path, readers[path])
+		return objectSize(object), err
+	})
+}
+
+// DeleteAll 并发逐个删除一组对象，返回每个对象的结果
+// 与 DeleteObjects 一致，不存在的对象视为删除成功
+// 参数:
+//   - storage: 存储接口
+//   - paths: 文件路径列表
+//   - concurrency: 并发数，小于等于0时使用 DefaultBatchConcurrency
+// 返回:
+//   - []BatchResult: 每个对象的结果，与paths顺序一致
+func DeleteAll(storage StorageInterface, paths []string, concurrency int) []BatchResult {
+	return runBatch(paths, concurrency, deleteObject(storage))
+}
+
+// deleteObject 返回删除单个对象的操作，不存在的对象视为删除成功
+func deleteObject(storage StorageInterface) func(path string) (int64, error) {
+	return func(path string) (int64, error) {
+		if err := storage.Delete(path); err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		return 0, nil
+	}
+}
+
+// Migrate 并发将一组对象从源存储复制到目标存储，返回每个对象的结果
+// 对象以相同路径写入目标存储，源存储中的对象不会被删除
+// 参数:
+//   - source: 源存储
+//   - destination: 目标存储
+//   - paths: 文件路径列表
+//   - concurrency: 并发数，小于等于0时使用 DefaultBatchConcurrency
+// 返回:
+//   - []BatchResult: 每个对象的结果，与paths顺序一致
+func Migrate(source, destination StorageInterface, paths []string, concurrency int) []BatchResult {
+	return runBatch(paths, concurrency, migrateObject(source, destination))
+}
+
+// migrateObject 返回将单个对象从源存储复制到目标存储的操作
+func migrateObject(source, destination StorageInterface) func(path string) (int64, error) {
+	return func(path string) (int64, error) {
+		stream, err := source.GetStream(path)
+		if err != nil {
+			return 0, err
+		}
+		defer stream.Close()
+
+		object, err := destination.Put(path, stream)
+		return objectSize(object), err
+	}
+}
+
+// BatchStater 原生支持批量获取对象信息的存储后端，例如七牛的批量操作接口
+type BatchStater interface {
+	// StatMany 批量获取对象信息
+	// 参数:
+	//   - paths: 文件路径列表
+	// 返回:
+	//   - map[string]*Object: 以请求的路径为键的对象信息，不存在的对象不包含在内
+	//   - error: 错误信息，不包括对象不存在
+	StatMany(paths []string) (map[string]*Object, error)
+}
+
+// StatMany 批量获取对象信息
+// 存储后端实现了 BatchStater 时使用批量接口，否则并发调用 Stat，
+// 例如渲染包含大小的文件列表时不需要逐个顺序请求
+// 参数:
+//   - storage: 存储接口
+//   - paths: 文件路径列表
+//   - concurrency: 并发调用 Stat 的并发数，小于等于0时使用 DefaultBatchConcurrency
+// 返回:
+//   - map[string]*Object: 以请求的路径为键的对象信息，不存在的对象不包含在内
+//   - error: 部分对象失败时返回包含每个失败对象的错误，成功的对象仍在返回的映射中
+func StatMany(storage StorageInterface, paths []string, concurrency int) (map[string]*Object, error) {
+	if stater, ok := storage.(BatchStater); ok {
+		return stater.StatMany(paths)
+	}
+
+	var mu sync.Mutex
+	objects := make(map[string]*Object, len(paths))
+	results := runBatch(paths, concurrency, func(path string) (int64, error) {
+		object, err := storage.Stat(path)
+		if errors.Is(err, ErrNotFound) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		mu.Lock()
+		objects[path] = object
+		mu.Unlock()
+		return object.Size, nil
+	})
+	return objects, BatchError(results)
+}
+
+// runBatch 使用固定数量的goroutine处理每个路径，结果按下标写入，无需加锁
+func runBatch(paths []string, concurrency int, fn func(path string) (int64, error)) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	var (
+		wg      sync.WaitGroup
+		results = make([]BatchResult, len(paths))
+		queue   = make(chan int)
+	)
+
+	for i := 0; i < concurrency && i < len(paths); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				start := time.Now()
+				n, err := fn(paths[index])
+				results[index] = BatchResult{Path: paths[index], Err: err, Bytes: n, Duration: time.Since(start)}
+			}
+		}()
+	}
+
+	for index := range paths {
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// objectSize 返回上传后的对象大小，上传失败时为0
+func objectSize(object *Object) int64 {
+	if object == nil {
+		return 0
+	}
+	return object.Size
+}
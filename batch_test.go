@@ -0,0 +1,77 @@
+package oss_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestBatch(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+
+	results := oss.PutAll(fileSystem, map[string]io.Reader{
+		"/b.txt": strings.NewReader("sample"),
+		"/a.txt": strings.NewReader("sample"),
+		"/c.txt": &failingReader{},
+	}, 2)
+	if len(results) != 3 || results[0].Path != "/a.txt" || results[0].Err != nil || results[0].Bytes != 6 {
+		t.Fatalf("PutAll should return sorted per-item results, but got %+v", results)
+	}
+	failed := oss.BatchFailed(results)
+	if len(failed) != 1 || failed[0].Path != "/c.txt" {
+		t.Errorf("Only /c.txt should fail, but got %+v", failed)
+	}
+	if err := oss.BatchError(results); err == nil || !strings.Contains(err.Error(), "/c.txt") {
+		t.Errorf("BatchError should report the failed path, but got %v", err)
+	}
+	if exists, _ := fileSystem.Exists("/b.txt"); !exists {
+		t.Errorf("Other objects should be kept when one upload failed")
+	}
+
+	destination := filesystem.New(t.TempDir())
+	results = oss.Migrate(fileSystem, destination, []string{"/a.txt", "/missing.txt", "/b.txt"}, 0)
+	if results[0].Err != nil || results[2].Err != nil || !errors.Is(results[1].Err, oss.ErrNotFound) {
+		t.Errorf("Migrate should report missing source per item, but got %+v", results)
+	}
+	if exists, _ := destination.Exists("/b.txt"); !exists {
+		t.Errorf("Migrate should copy objects to destination")
+	}
+
+	results = oss.DeleteAll(fileSystem, []string{"/a.txt", "/missing.txt"}, 0)
+	if err := oss.BatchError(results); err != nil {
+		t.Errorf("DeleteAll should treat missing objects as deleted, but got %v", err)
+	}
+}
+
+func TestStatMany(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	var paths []string
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("/files/%02d.txt", i)
+		fileSystem.Put(path, strings.NewReader(strings.Repeat("a", i)))
+		paths = append(paths, path)
+	}
+	objects, err := oss.StatMany(fileSystem, append(paths, "/files/missing.txt"), 4)
+	if err != nil || len(objects) != 20 || objects["/files/07.txt"].Size != 7 {
+		t.Errorf("StatMany should return every existing object without error, but got %d objects and %v", len(objects), err)
+	}
+
+	mock := ossmock.New()
+	mock.Put("/a.txt", strings.NewReader("sample"))
+	mock.StatFunc = func(path string) (*oss.Object, error) {
+		if path == "/b.txt" {
+			return nil, oss.ErrUnavailable
+		}
+		return &oss.Object{Path: path, Size: 6}, nil
+	}
+	objects, err = oss.StatMany(mock, []string{"/a.txt", "/b.txt"}, 0)
+	if !errors.Is(err, oss.ErrUnavailable) || len(objects) != 1 || objects["/a.txt"] == nil {
+		t.Errorf("Failed objects should be reported while others are returned, but got %v %v", objects, err)
+	}
+}
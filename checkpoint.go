@@ -0,0 +1,212 @@
+package oss
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// CheckpointVersion 下载检查点格式的版本号
+const CheckpointVersion = 1
+
+// DownloadCheckpoint 可续传下载的检查点，以JSON格式保存在本地文件中
+// 记录对象的大小、ETag和修改时间，以及按 PartSize 划分的各分段是否已经写入本地文件，
+// 续传时对象已被修改的检查点会被丢弃，重新下载全部分段
+type DownloadCheckpoint struct {
+	// Version 检查点格式的版本号
+	Version int `json:"version"`
+	// Path 对象路径
+	Path string `json:"path"`
+	// Size 对象大小
+	Size int64 `json:"size"`
+	// ETag 对象的实体标签
+	ETag string `json:"etag,omitempty"`
+	// LastModified 对象的最后修改时间
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	// PartSize 分段大小
+	PartSize int64 `json:"part_size"`
+	// Parts 各分段是否已经写入本地文件
+	Parts []bool `json:"parts"`
+	// Offset 从开头连续写入完成的字节数，顺序读取本地文件的程序可以读取到该位置
+	Offset int64 `json:"offset"`
+}
+
+// NewDownloadCheckpoint 为对象创建空的下载检查点
+// 参数:
+//   - object: 对象信息
+//   - partSize: 分段大小，小于等于0时使用 DefaultDownloadPartSize
+// 返回:
+//   - *DownloadCheckpoint: 下载检查点
+func NewDownloadCheckpoint(object *Object, partSize int64) *DownloadCheckpoint {
+	if partSize <= 0 {
+		partSize = DefaultDownloadPartSize
+	}
+	return &DownloadCheckpoint{
+		Version:      CheckpointVersion,
+		Path:         object.Path,
+		Size:         object.Size,
+		ETag:         object.ETag,
+		LastModified: object.LastModified,
+		PartSize:     partSize,
+		Parts:        make([]bool, (object.Size+partSize-1)/partSize),
+	}
+}
+
+// LoadDownloadCheckpoint 读取下载检查点文件
+// 参数:
+//   - file: 检查点文件路径
+// 返回:
+//   - *DownloadCheckpoint: 下载检查点
+//   - error: 文件不存在时 errors.Is(err, os.ErrNotExist) 成立，格式错误时返回错误信息
+func LoadDownloadCheckpoint(file string) (*DownloadCheckpoint, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint DownloadCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("oss: invalid checkpoint %s: %w", file, err)
+	}
+	if checkpoint.Version != CheckpointVersion || checkpoint.PartSize <= 0 || int64(len(checkpoint.Parts)) != (checkpoint.Size+checkpoint.PartSize-1)/checkpoint.PartSize {
+		return nil, fmt.Errorf("oss: invalid checkpoint %s", file)
+	}
+	return &checkpoint, nil
+}
+
+// Save 将检查点写入文件，先写入临时文件再重命名，中断时不会留下不完整的检查点
+// 参数:
+//   - file: 检查点文件路径
+// 返回:
+//   - error: 错误信息
+func (checkpoint *DownloadCheckpoint) Save(file string) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// Matches 判断检查点是否记录的是对象的当前版本
+// 参数:
+//   - object: 对象信息
+//   - partSize: 分段大小
+// 返回:
+//   - bool: 路径、大小、ETag、修改时间和分段大小都相同时返回true
+func (checkpoint *DownloadCheckpoint) Matches(object *Object, partSize int64) bool {
+	sameTime := checkpoint.LastModified == nil && object.LastModified == nil ||
+		checkpoint.LastModified != nil && object.LastModified != nil && checkpoint.LastModified.Equal(*object.LastModified)
+	return checkpoint.Path == object.Path && checkpoint.Size == object.Size && checkpoint.ETag == object.ETag &&
+		sameTime && checkpoint.PartSize == partSize
+}
+
+// Complete 标记分段已经写入本地文件，并更新 Offset
+// 参数:
+//   - index: 分段序号
+func (checkpoint *DownloadCheckpoint) Complete(index int) {
+	checkpoint.Parts[index] = true
+	for checkpoint.Offset < checkpoint.Size && checkpoint.Parts[checkpoint.Offset/checkpoint.PartSize] {
+		checkpoint.Offset = min(checkpoint.Offset+checkpoint.PartSize, checkpoint.Size)
+	}
+}
+
+// Remaining 返回尚未下载的字节数
+// 返回:
+//   - int64: 尚未下载的字节数
+func (checkpoint *DownloadCheckpoint) Remaining() int64 {
+	var remaining int64
+	for index, done := range checkpoint.Parts {
+		if !done {
+			offset := int64(index) * checkpoint.PartSize
+			remaining += min(checkpoint.PartSize, checkpoint.Size-offset)
+		}
+	}
+	return remaining
+}
+
+// ParallelGetOptions 并发下载到本地文件的选项
+type ParallelGetOptions struct {
+	// PartSize 分段大小，小于等于0时使用 DefaultDownloadPartSize
+	PartSize int64
+	// Concurrency 并发数，小于等于0时使用 DefaultDownloadConcurrency
+	Concurrency int
+	// Checkpoint 检查点文件路径，为空时不记录检查点，中断后需要重新下载
+	// 每个分段写入完成后更新检查点，下载成功后删除检查点
+	Checkpoint string
+}
+
+// ParallelGet 按分段并发下载对象到本地文件
+// 设置了检查点文件且检查点与对象的当前版本一致时，只下载检查点中未完成的分段，
+// 用于中断后继续下载大文件；对象已被修改时丢弃检查点重新下载
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+//   - localPath: 本地文件路径
+//   - opts: 下载选项
+// 返回:
+//   - error: 错误信息，失败时已下载的分段保留在本地文件和检查点中
+func ParallelGet(storage StorageInterface, path, localPath string, opts ParallelGetOptions) error {
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultDownloadPartSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultDownloadConcurrency
+	}
+	object, err := storage.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	checkpoint := NewDownloadCheckpoint(object, opts.PartSize)
+	flags := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if opts.Checkpoint != "" {
+		saved, err := LoadDownloadCheckpoint(opts.Checkpoint)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		// 本地文件被删除或截断时已完成的分段不再可信
+		info, statErr := os.Stat(localPath)
+		if saved != nil && saved.Matches(object, opts.PartSize) && statErr == nil && info.Size() == object.Size {
+			checkpoint, flags = saved, os.O_RDWR|os.O_CREATE
+		}
+	}
+
+	file, err := os.OpenFile(localPath, flags, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := file.Truncate(object.Size); err != nil {
+		return err
+	}
+
+	var mutex sync.Mutex
+	var done func(index int) error
+	if opts.Checkpoint != "" {
+		if err := checkpoint.Save(opts.Checkpoint); err != nil {
+			return err
+		}
+		done = func(index int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			checkpoint.Complete(index)
+			return checkpoint.Save(opts.Checkpoint)
+		}
+	}
+	skip := func(index int) bool { return checkpoint.Parts[index] }
+	if err := downloadParts(storage, path, file, object.Size, opts.PartSize, opts.Concurrency, skip, done); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if opts.Checkpoint != "" {
+		return os.Remove(opts.Checkpoint)
+	}
+	return nil
+}
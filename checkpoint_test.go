@@ -0,0 +1,73 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+// flakyStorage 第 fail 次范围读取失败的存储
+type flakyStorage struct {
+	oss.StorageInterface
+	fail   int
+	ranges []int64
+}
+
+func (storage *flakyStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	storage.ranges = append(storage.ranges, offset)
+	if len(storage.ranges) == storage.fail {
+		return nil, errors.New("connection reset")
+	}
+	return storage.StorageInterface.GetStreamRange(path, offset, length)
+}
+
+func TestParallelGet(t *testing.T) {
+	mock := ossmock.New()
+	mock.Put("/big.bin", strings.NewReader("0123456789abcdefghij"))
+	dir := t.TempDir()
+	local, checkpointFile := filepath.Join(dir, "big.bin"), filepath.Join(dir, "big.bin.checkpoint")
+	opts := oss.ParallelGetOptions{PartSize: 4, Concurrency: 1, Checkpoint: checkpointFile}
+
+	storage := &flakyStorage{StorageInterface: mock, fail: 3}
+	if err := oss.ParallelGet(storage, "/big.bin", local, opts); err == nil {
+		t.Fatalf("ParallelGet should fail when a part fails")
+	}
+	checkpoint, err := oss.LoadDownloadCheckpoint(checkpointFile)
+	if err != nil {
+		t.Fatalf("Checkpoint should be saved after failure, but got %v", err)
+	}
+	if checkpoint.Offset != 8 || checkpoint.Remaining() != 12 || len(checkpoint.Parts) != 5 || !checkpoint.Parts[1] || checkpoint.Parts[2] {
+		t.Errorf("Checkpoint should record completed parts, but got %+v", checkpoint)
+	}
+
+	storage = &flakyStorage{StorageInterface: mock}
+	if err := oss.ParallelGet(storage, "/big.bin", local, opts); err != nil {
+		t.Fatalf("Resumed ParallelGet failed: %v", err)
+	}
+	if content, _ := os.ReadFile(local); string(content) != "0123456789abcdefghij" {
+		t.Errorf("Resumed download should produce the whole object, but got %q", content)
+	}
+	if len(storage.ranges) != 3 || storage.ranges[0] != 8 {
+		t.Errorf("Resume should only download remaining parts, but got %v", storage.ranges)
+	}
+	if _, err := os.Stat(checkpointFile); !os.IsNotExist(err) {
+		t.Errorf("Checkpoint should be removed after success, but got %v", err)
+	}
+
+	// 对象已被修改时丢弃检查点
+	checkpoint.Save(checkpointFile)
+	mock.Put("/big.bin", strings.NewReader("changed content here"))
+	storage = &flakyStorage{StorageInterface: mock}
+	if err := oss.ParallelGet(storage, "/big.bin", local, opts); err != nil {
+		t.Fatalf("ParallelGet failed: %v", err)
+	}
+	if content, _ := os.ReadFile(local); string(content) != "changed content here" || len(storage.ranges) != 5 {
+		t.Errorf("Changed object should be downloaded again, but got %q with %v ranges", content, len(storage.ranges))
+	}
+}
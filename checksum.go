@@ -0,0 +1,170 @@
+package oss
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// ChecksumAlgorithm 内容校验和算法
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumMD5 MD5，以Content-MD5请求头发送，各云存储均支持服务端校验
+	ChecksumMD5 ChecksumAlgorithm = "md5"
+	// ChecksumSHA256 SHA256，S3以x-amz-checksum-sha256请求头发送
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+)
+
+// NewChecksumHash 创建校验和算法对应的哈希
+// 参数:
+//   - algorithm: 校验和算法
+// 返回:
+//   - hash.Hash: 哈希实例
+//   - error: 算法不支持时返回 ErrNotSupported
+func NewChecksumHash(algorithm ChecksumAlgorithm) (hash.Hash, error) {
+	switch algorithm {
+	case ChecksumMD5:
+		return md5.New(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("%w: checksum algorithm %s", ErrNotSupported, algorithm)
+}
+
+// ComputeChecksum 计算内容的校验和
+// 参数:
+//   - algorithm: 校验和算法
+//   - data: 文件内容
+// 返回:
+//   - string: Base64编码的校验和
+//   - error: 算法不支持时返回 ErrNotSupported
+func ComputeChecksum(algorithm ChecksumAlgorithm, data []byte) (string, error) {
+	h, err := NewChecksumHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// ContentChecksum 计算上传内容的校验和并与调用方提供的值比较
+// 参数:
+//   - data: 文件内容
+// 返回:
+//   - string: Base64编码的校验和，未设置校验和算法时返回空字符串
+//   - error: 内容与 ChecksumValue 不一致时返回 ErrChecksumMismatch
+func (opts PutOptions) ContentChecksum(data []byte) (string, error) {
+	if opts.Checksum == "" {
+		return "", nil
+	}
+	checksum, err := ComputeChecksum(opts.Checksum, data)
+	if err != nil {
+		return "", err
+	}
+	if opts.ChecksumValue != "" && opts.ChecksumValue != checksum {
+		return "", fmt.Errorf("%w: %s %s, expected %s", ErrChecksumMismatch, opts.Checksum, checksum, opts.ChecksumValue)
+	}
+	return checksum, nil
+}
+
+// ReadChecksum 将上传内容读入内存并计算校验和
+// 用于流式上传的后端，计算校验和后需要使用返回的读取器上传
+// 参数:
+//   - reader: 文件内容读取器
+// 返回:
+//   - io.Reader: 可以重新读取的文件内容
+//   - string: Base64编码的校验和
+//   - error: 错误信息，内容与 ChecksumValue 不一致时返回 ErrChecksumMismatch
+func (opts PutOptions) ReadChecksum(reader io.Reader) (io.Reader, string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	checksum, err := opts.ContentChecksum(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(data), checksum, nil
+}
+
+// VerifyStream 读取结束时校验流内容的校验和
+// 内容不一致时最后一次读取返回 ErrChecksumMismatch 而不是 io.EOF
+// 参数:
+//   - stream: 文件流
+//   - algorithm: 校验和算法
+//   - expected: Base64编码的期望校验和
+// 返回:
+//   - io.ReadCloser: 校验内容的文件流
+//   - error: 算法不支持时返回 ErrNotSupported
+func VerifyStream(stream io.ReadCloser, algorithm ChecksumAlgorithm, expected string) (io.ReadCloser, error) {
+	h, err := NewChecksumHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &verifyingReader{ReadCloser: stream, hash: h, algorithm: algorithm, expected: expected}, nil
+}
+
+// GetStreamVerified 获取文件流并在读取结束时校验内容
+// expected为空且使用MD5时，以对象的ETag作为期望值，分片上传等ETag不是MD5的对象返回 ErrNotSupported
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+//   - algorithm: 校验和算法
+//   - expected: Base64编码的期望校验和，可以为空
+// 返回:
+//   - io.ReadCloser: 校验内容的文件流
+//   - error: 错误信息
+func GetStreamVerified(storage StorageInterface, path string, algorithm ChecksumAlgorithm, expected string) (io.ReadCloser, error) {
+	if expected == "" {
+		if algorithm != ChecksumMD5 {
+			return nil, fmt.Errorf("%w: no expected %s checksum for %s", ErrNotSupported, algorithm, path)
+		}
+		object, err := storage.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		sum, err := hex.DecodeString(strings.Trim(object.ETag, `"`))
+		if err != nil || len(sum) != md5.Size {
+			return nil, fmt.Errorf("%w: ETag %s of %s is not an MD5 checksum", ErrNotSupported, object.ETag, path)
+		}
+		expected = base64.StdEncoding.EncodeToString(sum)
+	}
+
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := VerifyStream(stream, algorithm, expected)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	return verified, nil
+}
+
+// verifyingReader 读取时计算校验和的文件流
+type verifyingReader struct {
+	io.ReadCloser
+	hash      hash.Hash
+	algorithm ChecksumAlgorithm
+	expected  string
+}
+
+// Read 读取内容并更新校验和，读取结束时比较校验和
+func (reader *verifyingReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	reader.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := base64.StdEncoding.EncodeToString(reader.hash.Sum(nil)); actual != reader.expected {
+			return n, fmt.Errorf("%w: %s %s, expected %s", ErrChecksumMismatch, reader.algorithm, actual, reader.expected)
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,366 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCircuitFailureThreshold 默认打开熔断器的连续失败次数
+	DefaultCircuitFailureThreshold = 5
+	// DefaultCircuitOpenTimeout 熔断器打开后默认等待多久进入半开状态
+	DefaultCircuitOpenTimeout = 30 * time.Second
+)
+
+// ErrCircuitOpen 熔断器已打开，调用直接失败而不访问存储后端，errors.Is(err, ErrUnavailable) 同样成立
+var ErrCircuitOpen error = &kindError{message: "oss: circuit open", parent: ErrUnavailable}
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	// CircuitClosed 正常调用存储后端
+	CircuitClosed CircuitState = iota
+	// CircuitOpen 调用直接返回 ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen 只允许一个探测调用，成功后关闭，失败后重新打开
+	CircuitHalfOpen
+)
+
+// String 返回状态名称
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreakerOptions 熔断器配置
+type CircuitBreakerOptions struct {
+	// FailureThreshold 打开熔断器的连续失败次数，小于等于0时使用 DefaultCircuitFailureThreshold
+	FailureThreshold int
+	// OpenTimeout 熔断器打开后等待多久进入半开状态，小于等于0时使用 DefaultCircuitOpenTimeout
+	OpenTimeout time.Duration
+	// IsFailure 判断错误是否计为后端故障，为nil时使用 IsCircuitFailure
+	IsFailure func(err error) bool
+	// OnStateChange 状态变化时的回调，可以为nil，在持有锁时调用，不应再调用存储
+	OnStateChange func(from, to CircuitState)
+}
+
+// IsCircuitFailure 默认的故障判断
+// 对象不存在、无权访问、冲突、不支持以及路径和大小等调用方的错误说明后端仍然正常，不计为故障
+// 参数:
+//   - err: 调用返回的错误
+// 返回:
+//   - bool: 是否计为后端故障
+func IsCircuitFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, kind := range []error{ErrNotFound, ErrPermissionDenied, ErrConflict, ErrNotSupported, ErrInvalidPath, ErrInvalidRange, ErrKeyTooLong, ErrTooLarge, ErrChecksumMismatch} {
+		if errors.Is(err, kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// CircuitBreakerStorage 连续失败后快速失败的存储包装器
+// 后端不可用时每次调用都要等到超时，调用方的线程会堆积；熔断器在连续失败 FailureThreshold 次后打开，
+// 之后的调用直接返回 ErrCircuitOpen，经过 OpenTimeout 后放行一个探测调用，成功后恢复正常。
+// GetStream 等流式方法只统计打开流的结果；GetURL 等只在本地计算的方法不受熔断器影响
+type CircuitBreakerStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Options 熔断器配置
+	Options CircuitBreakerOptions
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probing    bool
+}
+
+// WithCircuitBreaker 创建连续失败后快速失败的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 熔断器配置
+// 返回:
+//   - *CircuitBreakerStorage: 存储包装器实例
+func WithCircuitBreaker(storage StorageInterface, opts CircuitBreakerOptions) *CircuitBreakerStorage {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = IsCircuitFailure
+	}
+	return &CircuitBreakerStorage{StorageInterface: storage, Options: opts}
+}
+
+// State 返回熔断器当前的状态，打开时间超过 OpenTimeout 时返回 CircuitHalfOpen
+// 返回:
+//   - CircuitState: 熔断器状态
+func (storage *CircuitBreakerStorage) State() CircuitState {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.state == CircuitOpen && Now().Sub(storage.openedAt) >= storage.Options.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return storage.state
+}
+
+// allow 判断是否允许调用，半开状态只允许一个探测调用
+// 返回调用开始时的状态代数，状态变化后结束的调用结果不会被记录
+func (storage *CircuitBreakerStorage) allow(op, path string) (uint64, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.state == CircuitOpen && Now().Sub(storage.openedAt) >= storage.Options.OpenTimeout {
+		storage.transition(CircuitHalfOpen)
+	}
+	switch {
+	case storage.state == CircuitOpen, storage.state == CircuitHalfOpen && storage.probing:
+		return 0, fmt.Errorf("%w: %s %s", ErrCircuitOpen, op, path)
+	case storage.state == CircuitHalfOpen:
+		storage.probing = true
+	}
+	return storage.generation, nil
+}
+
+// record 记录调用结果并更新状态
+// 打开前开始的慢调用可能在半开状态下才结束，它的结果不能代替探测调用，因此忽略开始后状态已经变化的调用
+func (storage *CircuitBreakerStorage) record(generation uint64, err error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if generation != storage.generation {
+		return
+	}
+	halfOpen := storage.state == CircuitHalfOpen
+	storage.probing = false
+	if !storage.Options.IsFailure(err) {
+		storage.failures = 0
+		if halfOpen {
+			storage.transition(CircuitClosed)
+		}
+		return
+	}
+
+	storage.failures++
+	if halfOpen || storage.state == CircuitClosed && storage.failures >= storage.Options.FailureThreshold {
+		storage.openedAt = Now()
+		storage.transition(CircuitOpen)
+	}
+}
+
+// transition 切换状态并调用回调
+func (storage *CircuitBreakerStorage) transition(to CircuitState) {
+	from := storage.state
+	storage.state = to
+	if from != to {
+		storage.generation++
+	}
+	if from != to && storage.Options.OnStateChange != nil {
+		storage.Options.OnStateChange(from, to)
+	}
+}
+
+// guarded 熔断器允许时执行操作并记录结果
+func guarded[T any](storage *CircuitBreakerStorage, op, path string, fn func() (T, error)) (T, error) {
+	generation, err := storage.allow(op, path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	value, err := fn()
+	storage.record(generation, err)
+	return value, err
+}
+
+// guardedErr 熔断器允许时执行只返回错误的操作并记录结果
+func guardedErr(storage *CircuitBreakerStorage, op, path string, fn func() error) error {
+	_, err := guarded(storage, op, path, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Get 获取指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Get(path string) (*os.File, error) {
+	return guarded(storage, "get", path, func() (*os.File, error) {
+		return storage.StorageInterface.Get(path)
+	})
+}
+
+// GetStream 获取指定路径文件的流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) GetStream(path string) (io.ReadCloser, error) {
+	return guarded(storage, "get stream", path, func() (io.ReadCloser, error) {
+		return storage.StorageInterface.GetStream(path)
+	})
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return guarded(storage, "get stream range", path, func() (io.ReadCloser, error) {
+		return storage.StorageInterface.GetStreamRange(path, offset, length)
+	})
+}
+
+// Stat 获取对象信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Stat(path string) (*Object, error) {
+	return guarded(storage, "stat", path, func() (*Object, error) {
+		return storage.StorageInterface.Stat(path)
+	})
+}
+
+// Exists 检查对象是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Exists(path string) (bool, error) {
+	return guarded(storage, "exists", path, func() (bool, error) {
+		return storage.StorageInterface.Exists(path)
+	})
+}
+
+// Put 上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return guarded(storage, "put", path, func() (*Object, error) {
+		return storage.StorageInterface.Put(path, reader)
+	})
+}
+
+// PutWithOptions 使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	return guarded(storage, "put", path, func() (*Object, error) {
+		return storage.StorageInterface.PutWithOptions(path, reader, opts)
+	})
+}
+
+// NewWriter 创建流式写入器
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) NewWriter(path string) (io.WriteCloser, error) {
+	return guarded(storage, "new writer", path, func() (io.WriteCloser, error) {
+		return storage.StorageInterface.NewWriter(path)
+	})
+}
+
+// Delete 删除文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Delete(path string) error {
+	return guardedErr(storage, "delete", path, func() error {
+		return storage.StorageInterface.Delete(path)
+	})
+}
+
+// DeleteObjects 批量删除对象
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) DeleteObjects(paths []string) error {
+	return guardedErr(storage, "delete objects", fmt.Sprintf("(%d paths)", len(paths)), func() error {
+		return storage.StorageInterface.DeleteObjects(paths)
+	})
+}
+
+// DeleteDir 删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) DeleteDir(dir string) error {
+	return guardedErr(storage, "delete dir", dir, func() error {
+		return storage.StorageInterface.DeleteDir(dir)
+	})
+}
+
+// Copy 复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Copy(srcPath, dstPath string) error {
+	return guardedErr(storage, "copy", srcPath, func() error {
+		return storage.StorageInterface.Copy(srcPath, dstPath)
+	})
+}
+
+// Move 移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) Move(srcPath, dstPath string) error {
+	return guardedErr(storage, "move", srcPath, func() error {
+		return storage.StorageInterface.Move(srcPath, dstPath)
+	})
+}
+
+// List 列出对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息，熔断器打开时返回 ErrCircuitOpen
+func (storage *CircuitBreakerStorage) List(path string) ([]*Object, error) {
+	return guarded(storage, "list", path, func() ([]*Object, error) {
+		return storage.StorageInterface.List(path)
+	})
+}
@@ -0,0 +1,122 @@
+package oss_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+
+	mock := ossmock.New()
+	var transitions []string
+	storage := oss.WithCircuitBreaker(mock, oss.CircuitBreakerOptions{FailureThreshold: 3, OpenTimeout: time.Minute,
+		OnStateChange: func(from, to oss.CircuitState) { transitions = append(transitions, from.String()+"->"+to.String()) }})
+
+	mock.FailWith("Stat", oss.ErrNotFound)
+	for i := 0; i < 5; i++ {
+		storage.Stat("/a.txt")
+	}
+	if storage.State() != oss.CircuitClosed {
+		t.Errorf("Not found should not open the circuit, but got %v", storage.State())
+	}
+
+	mock.FailWith("Stat", oss.ErrUnavailable)
+	for i := 0; i < 3; i++ {
+		storage.Stat("/a.txt")
+	}
+	calls := len(mock.CallsTo("Stat"))
+	if _, err := storage.Stat("/a.txt"); !errors.Is(err, oss.ErrCircuitOpen) || !errors.Is(err, oss.ErrUnavailable) || len(mock.CallsTo("Stat")) != calls {
+		t.Errorf("Open circuit should fail fast without calling the backend, but got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if storage.State() != oss.CircuitHalfOpen {
+		t.Errorf("Circuit should be half-open after the open timeout, but got %v", storage.State())
+	}
+	storage.Stat("/a.txt")
+	if storage.State() != oss.CircuitOpen || len(mock.CallsTo("Stat")) != calls+1 {
+		t.Errorf("Failed probe should reopen the circuit, but got %v", storage.State())
+	}
+
+	now = now.Add(time.Minute)
+	mock.FailWith("Stat", nil)
+	mock.Put("/a.txt", strings.NewReader("sample"))
+	if _, err := storage.Stat("/a.txt"); err != nil || storage.State() != oss.CircuitClosed {
+		t.Errorf("Successful probe should close the circuit, but got %v %v", storage.State(), err)
+	}
+	expected := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if strings.Join(transitions, ",") != strings.Join(expected, ",") {
+		t.Errorf("State changes should be reported, but got %v", transitions)
+	}
+}
+
+func TestCircuitBreakerStaleResult(t *testing.T) {
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.ClockFunc(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+
+	started := map[string]chan struct{}{"/slow.txt": make(chan struct{}), "/probe.txt": make(chan struct{})}
+	release := map[string]chan struct{}{"/slow.txt": make(chan struct{}), "/probe.txt": make(chan struct{})}
+	mock := ossmock.New()
+	mock.StatFunc = func(path string) (*oss.Object, error) {
+		if started[path] == nil {
+			return nil, oss.ErrUnavailable
+		}
+		close(started[path])
+		<-release[path]
+		if path == "/probe.txt" {
+			return nil, oss.ErrUnavailable
+		}
+		return &oss.Object{Path: path}, nil
+	}
+	storage := oss.WithCircuitBreaker(mock, oss.CircuitBreakerOptions{FailureThreshold: 2, OpenTimeout: time.Minute})
+	stat := func(path string) chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			storage.Stat(path)
+			close(done)
+		}()
+		<-started[path]
+		return done
+	}
+
+	slow := stat("/slow.txt")
+	storage.Stat("/a.txt")
+	storage.Stat("/a.txt")
+	mu.Lock()
+	now = now.Add(time.Minute)
+	mu.Unlock()
+	probe := stat("/probe.txt")
+
+	// 打开前开始的调用在探测期间成功，不能代替探测调用关闭熔断器
+	close(release["/slow.txt"])
+	<-slow
+	if state := storage.State(); state != oss.CircuitHalfOpen {
+		t.Errorf("Stale success should not close the circuit, but got %v", state)
+	}
+	if _, err := storage.Stat("/a.txt"); !errors.Is(err, oss.ErrCircuitOpen) {
+		t.Errorf("Only one probe should be allowed while half-open, but got %v", err)
+	}
+
+	close(release["/probe.txt"])
+	<-probe
+	if state := storage.State(); state != oss.CircuitOpen {
+		t.Errorf("Failed probe should reopen the circuit, but got %v", state)
+	}
+}
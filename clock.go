@@ -0,0 +1,87 @@
+package oss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	mathrand "math/rand"
+	"sync"
+	"time"
+)
+
+// Clock 时间来源
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// ClockFunc 函数形式的时间来源
+type ClockFunc func() time.Time
+
+// Now 调用函数返回当前时间
+func (fn ClockFunc) Now() time.Time {
+	return fn()
+}
+
+// FixedClock 创建总是返回同一时间的时间来源，用于断言精确的输出
+// 参数:
+//   - t: 返回的时间
+// 返回:
+//   - Clock: 时间来源
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// DefaultClock 签名URL的过期时间、发布时间、模拟存储的修改时间等使用的时间来源，默认为系统时间
+// 需要在程序或测试开始时设置，测试结束后应恢复为原来的值
+var DefaultClock Clock = ClockFunc(time.Now)
+
+// DefaultRandom 分片上传ID、临时文件名、审计记录ID等不涉及安全的随机数来源，默认为 crypto/rand.Reader
+// 只应在测试中替换，与 DefaultClock 一样需要在开始时设置，可以使用 NewSeededRandom 得到可重复的输出；
+// 密钥、随机数（nonce）和令牌等安全相关的内容总是直接取自 crypto/rand，不受该变量影响
+var DefaultRandom io.Reader = rand.Reader
+
+// Now 返回 DefaultClock 的当前时间
+// 返回:
+//   - time.Time: 当前时间
+func Now() time.Time {
+	return DefaultClock.Now()
+}
+
+// NewSeededRandom 创建使用固定种子的随机数来源，相同的种子总是产生相同的序列
+// 只用于测试，生成的内容不能用于安全相关的场景，可以并发读取
+// 参数:
+//   - seed: 随机数种子
+// 返回:
+//   - io.Reader: 随机数来源
+func NewSeededRandom(seed int64) io.Reader {
+	return &seededRandom{rand: mathrand.New(mathrand.NewSource(seed))}
+}
+
+// seededRandom 加锁的固定种子随机数来源，math/rand.Rand 不能并发使用
+type seededRandom struct {
+	mu   sync.Mutex
+	rand *mathrand.Rand
+}
+
+// Read 读取随机字节
+func (random *seededRandom) Read(p []byte) (int, error) {
+	random.mu.Lock()
+	defer random.mu.Unlock()
+	return random.rand.Read(p)
+}
+
+// RandomHex 从 DefaultRandom 读取n个字节并编码为十六进制字符串
+// 结果可以被替换的 DefaultRandom 预测，不能用作密钥或访问令牌
+// 参数:
+//   - n: 随机字节数
+// 返回:
+//   - string: 长度为2n的十六进制字符串
+//   - error: 读取随机数失败时的错误信息
+func RandomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(DefaultRandom, b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
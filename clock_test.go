@@ -0,0 +1,41 @@
+package oss_test
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestClockAndRandom(t *testing.T) {
+	clock, random := oss.DefaultClock, oss.DefaultRandom
+	defer func() { oss.DefaultClock, oss.DefaultRandom = clock, random }()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.FixedClock(now)
+	publisher := oss.NewPublisher(filesystem.New(t.TempDir()), "/dataset")
+	publisher.Stage("v1", map[string]io.Reader{"a.txt": strings.NewReader("v1")})
+	if manifest, err := publisher.Promote("v1"); err != nil || !manifest.PublishedAt.Equal(now) {
+		t.Errorf("Manifest should be published at the fixed time, but got %+v, %v", manifest, err)
+	}
+
+	oss.TempDir = t.TempDir()
+	defer func() { oss.TempDir = "" }()
+	var names []string
+	for i := 0; i < 2; i++ {
+		oss.DefaultRandom = oss.NewSeededRandom(1)
+		file, err := oss.CreateTempFile("oss-*.tmp")
+		if err != nil {
+			t.Fatalf("No error should happen when create temp file, but got %v", err)
+		}
+		names = append(names, filepath.Base(file.Name()))
+		oss.RemoveTempFile(file)
+	}
+	if names[0] != names[1] || !strings.HasPrefix(names[0], "oss-") || !strings.HasSuffix(names[0], ".tmp") {
+		t.Errorf("Temp file names should repeat with the same seed, but got %v", names)
+	}
+}
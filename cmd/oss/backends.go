@@ -0,0 +1,14 @@
+package main
+
+// 导入全部存储后端，使 oss.Open 可以识别它们的连接字符串协议
+import (
+	_ "github.com/smart-unicom/oss/aliyun"
+	_ "github.com/smart-unicom/oss/azureblob"
+	_ "github.com/smart-unicom/oss/filesystem"
+	_ "github.com/smart-unicom/oss/googlecloud"
+	_ "github.com/smart-unicom/oss/huawei"
+	_ "github.com/smart-unicom/oss/qiniu"
+	_ "github.com/smart-unicom/oss/s3"
+	_ "github.com/smart-unicom/oss/synology"
+	_ "github.com/smart-unicom/oss/tencent"
+)
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// localPrefix 表示本地文件的参数前缀，例如 local:./a.png
+const localPrefix = "local:"
+
+// localPath 返回参数对应的本地文件路径，参数不以 local: 开头时返回false
+func localPath(arg string) (string, bool) {
+	if strings.HasPrefix(arg, localPrefix) {
+		return strings.TrimPrefix(arg, localPrefix), true
+	}
+	return "", false
+}
+
+// runList 列出目录下的对象和子目录
+func runList(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("ls")
+	recursive := flags.Bool("r", false, "递归列出全部对象")
+	long := flags.Bool("l", false, "输出大小和修改时间")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	dir := "/"
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+
+	result, err := oss.ListWithOptions(storage, dir, oss.ListOptions{Recursive: *recursive})
+	if err != nil {
+		return err
+	}
+	for _, prefix := range result.Prefixes {
+		if *long {
+			fmt.Fprintf(stdout, "%12s  %-24s  %s\n", "DIR", "", prefix)
+		} else {
+			fmt.Fprintln(stdout, prefix)
+		}
+	}
+	for _, object := range result.Objects {
+		if !*long {
+			fmt.Fprintln(stdout, object.Path)
+			continue
+		}
+		modified := ""
+		if object.LastModified != nil {
+			modified = object.LastModified.Format(time.RFC3339)
+		}
+		fmt.Fprintf(stdout, "%12d  %-24s  %s\n", object.Size, modified, object.Path)
+	}
+	return nil
+}
+
+// runCopy 复制对象，local: 开头的参数表示本地文件，用于上传和下载
+func runCopy(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("cp")
+	contentType := flags.String("type", "", "上传的内容类型，为空时自动检测")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return &usageError{message: "cp requires a source and a destination"}
+	}
+	src, dst := flags.Arg(0), flags.Arg(1)
+	srcLocal, fromLocal := localPath(src)
+	dstLocal, toLocal := localPath(dst)
+
+	switch {
+	case fromLocal && toLocal:
+		return &usageError{message: "cp requires at least one object path"}
+	case fromLocal:
+		file, err := os.Open(srcLocal)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		object, err := storage.PutWithOptions(dst, file, &oss.PutOptions{ContentType: *contentType})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "uploaded %s (%d bytes)\n", objectPath(object, dst), object.Size)
+		return nil
+	case toLocal:
+		file, err := os.Create(dstLocal)
+		if err != nil {
+			return err
+		}
+		err = oss.Download(storage, src, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "downloaded %s\n", dstLocal)
+		return nil
+	default:
+		return storage.Copy(src, dst)
+	}
+}
+
+// runMove 移动对象
+func runMove(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("mv")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return &usageError{message: "mv requires a source and a destination"}
+	}
+	return storage.Move(flags.Arg(0), flags.Arg(1))
+}
+
+// runRemove 删除对象，-r 时删除目录
+func runRemove(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("rm")
+	recursive := flags.Bool("r", false, "删除目录及其中的全部对象")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return &usageError{message: "rm requires at least one path"}
+	}
+	if !*recursive {
+		return storage.DeleteObjects(flags.Args())
+	}
+	for _, dir := range flags.Args() {
+		if err := storage.DeleteDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCat 将对象内容输出到标准输出
+func runCat(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("cat")
+	offset := flags.Int64("offset", 0, "起始偏移量")
+	length := flags.Int64("length", -1, "读取长度，小于0时读取到末尾")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return &usageError{message: "cat requires a path"}
+	}
+
+	var stream io.ReadCloser
+	if *offset == 0 && *length < 0 {
+		stream, err = storage.GetStream(flags.Arg(0))
+	} else {
+		stream, err = storage.GetStreamRange(flags.Arg(0), *offset, *length)
+	}
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = io.Copy(stdout, stream)
+	return err
+}
+
+// runPresign 生成签名URL
+func runPresign(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("presign")
+	expiry := flags.Duration("expiry", oss.DefaultSignedURLExpiry, "有效期")
+	method := flags.String("method", http.MethodGet, "HTTP方法，GET 或 PUT")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return &usageError{message: "presign requires a path"}
+	}
+	signed, err := storage.GetSignedURL(flags.Arg(0), oss.SignedURLOptions{Expiry: *expiry, Method: strings.ToUpper(*method)})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, signed)
+	return nil
+}
+
+// runGet 并发分段下载对象到本地文件，-resume 时记录检查点，中断后再次执行只下载未完成的分段
+func runGet(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("get")
+	resume := flags.Bool("resume", false, "记录检查点，中断后继续下载")
+	partSize := flags.Int64("part-size", oss.DefaultDownloadPartSize, "分段大小")
+	concurrency := flags.Int("concurrency", oss.DefaultDownloadConcurrency, "并发数")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return &usageError{message: "get requires a path and a local file"}
+	}
+	path, local := flags.Arg(0), strings.TrimPrefix(flags.Arg(1), localPrefix)
+
+	opts := oss.ParallelGetOptions{PartSize: *partSize, Concurrency: *concurrency}
+	if *resume {
+		opts.Checkpoint = local + ".oss-checkpoint"
+		if checkpoint, err := oss.LoadDownloadCheckpoint(opts.Checkpoint); err == nil {
+			fmt.Fprintf(stdout, "resuming %s, %d bytes remaining\n", path, checkpoint.Remaining())
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := oss.ParallelGet(storage, path, local, opts); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "downloaded %s\n", local)
+	return nil
+}
+
+// objectPath 优先使用后端返回的对象路径
+func objectPath(object *oss.Object, path string) string {
+	if object != nil && object.Path != "" {
+		return object.Path
+	}
+	return path
+}
+
+// stringList 可以重复指定的字符串参数
+type stringList []string
+
+// String 返回以逗号分隔的参数值
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+// Set 追加一个参数值
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+var _ flag.Value = (*stringList)(nil)
@@ -0,0 +1,106 @@
+// oss 通过连接字符串操作任意存储后端的命令行工具
+//
+// 连接字符串的格式与 oss.Open 相同，可以通过 -dsn 参数或 OSS_DSN 环境变量传入:
+//
+//	oss ls -l -dsn 's3://AccessId:AccessKey@bucket?region=us-east-1' /uploads
+//	OSS_DSN=file:///data oss cp local:./a.png /avatars/a.png
+//	OSS_DSN=qiniu://... oss sync -to 's3://...' -delete /uploads
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/smart-unicom/oss"
+)
+
+// command 一个子命令
+type command struct {
+	// usage 参数说明
+	usage string
+	// summary 一行说明
+	summary string
+	// run 执行子命令，args 为子命令之后的参数
+	run func(args []string, stdout io.Writer) error
+}
+
+// commands 全部子命令
+var commands = map[string]command{
+	"ls":       {usage: "ls [-r] [-l] [dir]", summary: "列出目录下的对象和子目录", run: runList},
+	"cp":       {usage: "cp [-type content-type] src dst", summary: "复制对象，local: 开头的参数表示本地文件", run: runCopy},
+	"mv":       {usage: "mv src dst", summary: "移动对象", run: runMove},
+	"rm":       {usage: "rm [-r] path...", summary: "删除对象，-r 时删除目录", run: runRemove},
+	"cat":      {usage: "cat [-offset n] [-length n] path", summary: "输出对象内容", run: runCat},
+	"get":      {usage: "get [-resume] [-part-size n] [-concurrency n] path file", summary: "并发分段下载到本地文件，-resume 时可以中断后继续", run: runGet},
+	"sync":     {usage: "sync -to dsn [-delete] [-dry-run] [-include glob] [-exclude glob] [dir]", summary: "同步到另一个存储", run: runSync},
+	"presign":  {usage: "presign [-expiry 15m] [-method GET] path", summary: "生成签名URL", run: runPresign},
+	"selftest": {usage: "selftest [-dir dir] [-size bytes] [-fetch]", summary: "写入、读取、范围读取、列出、签名和删除自检对象，报告功能和延迟", run: runSelfTest},
+}
+
+// usageError 参数错误，输出子命令的用法并以状态码2退出
+type usageError struct {
+	message string
+}
+
+// Error 返回错误描述
+func (err *usageError) Error() string {
+	return err.message
+}
+
+// newFlagSet 创建子命令的参数集，所有子命令都支持 -dsn 参数
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dsn := flags.String("dsn", os.Getenv("OSS_DSN"), "存储的连接字符串，默认读取 OSS_DSN 环境变量")
+	return flags, dsn
+}
+
+// parse 解析子命令参数并打开存储
+func parse(flags *flag.FlagSet, dsn *string, args []string) (oss.StorageInterface, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, &usageError{message: err.Error()}
+	}
+	if *dsn == "" {
+		return nil, &usageError{message: "missing -dsn or OSS_DSN"}
+	}
+	return oss.Open(*dsn)
+}
+
+// usage 输出全部子命令的用法
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: oss <command> [-dsn dsn] [arguments]")
+	fmt.Fprintln(w)
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-52s %s\n", commands[name].usage, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nregistered schemes: %v\n", oss.Schemes())
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "oss: unknown command %q\n\n", os.Args[1])
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "oss %s: %v\n", os.Args[1], err)
+		if _, ok := err.(*usageError); ok {
+			fmt.Fprintf(os.Stderr, "usage: oss %s\n", cmd.usage)
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	root := t.TempDir()
+	dsn := "-dsn=file://" + filepath.ToSlash(filepath.Join(root, "bucket"))
+	local := filepath.Join(root, "local.txt")
+	os.WriteFile(local, []byte("hello world"), 0o644)
+	run := func(name string, args ...string) string {
+		t.Helper()
+		var stdout bytes.Buffer
+		if err := commands[name].run(append([]string{dsn}, args...), &stdout); err != nil {
+			t.Fatalf("oss %s %v failed, got %v", name, args, err)
+		}
+		return stdout.String()
+	}
+
+	run("cp", "local:"+local, "/docs/a.txt")
+	run("cp", "/docs/a.txt", "/docs/b.txt")
+	run("mv", "/docs/b.txt", "/archive/b.txt")
+	if listed := run("ls", "-r"); listed != "/archive/b.txt\n/docs/a.txt\n" {
+		t.Errorf("ls -r should list all objects, but got %q", listed)
+	}
+	if listed := run("ls"); listed != "/archive/\n/docs/\n" {
+		t.Errorf("ls should list directories, but got %q", listed)
+	}
+	if content := run("cat", "-offset", "6", "-length", "5", "/docs/a.txt"); content != "world" {
+		t.Errorf("cat should read the range, but got %q", content)
+	}
+
+	downloaded := filepath.Join(root, "downloaded.txt")
+	run("get", "-resume", "-part-size", "4", "/docs/a.txt", downloaded)
+	if content, _ := os.ReadFile(downloaded); string(content) != "hello world" {
+		t.Errorf("get should download the object, but got %q", content)
+	}
+	if _, err := os.Stat(downloaded + ".oss-checkpoint"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Checkpoint should be removed after the download, got %v", err)
+	}
+
+	mirror := "file://" + filepath.ToSlash(filepath.Join(root, "mirror"))
+	if summary := run("sync", "-to", mirror, "-include", "*.txt"); !strings.Contains(summary, "copied 2") {
+		t.Errorf("sync should copy both objects, but got %q", summary)
+	}
+
+	run("rm", "/docs/a.txt")
+	run("rm", "-r", "/archive")
+	if listed := run("ls", "-r"); listed != "" {
+		t.Errorf("rm should delete the objects, but got %q", listed)
+	}
+}
+
+func TestUsageError(t *testing.T) {
+	var stdout bytes.Buffer
+	var usage *usageError
+	if err := runCopy([]string{"-dsn=file:///tmp", "/only-one"}, &stdout); !errors.As(err, &usage) {
+		t.Errorf("Missing arguments should be a usage error, but got %v", err)
+	}
+	if err := runList(nil, &stdout); !errors.As(err, &usage) && os.Getenv("OSS_DSN") == "" {
+		t.Errorf("Missing dsn should be a usage error, but got %v", err)
+	}
+}
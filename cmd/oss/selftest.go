@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/smart-unicom/oss"
+)
+
+// runSelfTest 对存储执行自检并打印报告，有失败的步骤时返回错误
+func runSelfTest(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("selftest")
+	dir := flags.String("dir", oss.DefaultSelfTestDir, "自检对象的目录")
+	size := flags.Int("size", oss.DefaultSelfTestSize, "自检对象的字节数")
+	fetch := flags.Bool("fetch", false, "通过HTTP下载签名URL并校验内容")
+	storage, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+
+	report := oss.SelfTest(storage, oss.SelfTestOptions{Dir: *dir, Size: *size, FetchSignedURL: *fetch})
+	fmt.Fprintf(stdout, "canary %s\n%s", report.Path, report)
+	if !report.OK() {
+		return errors.New("self test failed")
+	}
+	return nil
+}
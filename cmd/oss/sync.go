@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/osssync"
+)
+
+// runSync 将 -dsn 指定的存储同步到 -to 指定的存储
+func runSync(args []string, stdout io.Writer) error {
+	flags, dsn := newFlagSet("sync")
+	to := flags.String("to", "", "目标存储的连接字符串")
+	concurrency := flags.Int("concurrency", oss.DefaultBatchConcurrency, "复制的并发数")
+	deleteExtraneous := flags.Bool("delete", false, "删除目标存储中源存储不存在的对象")
+	dryRun := flags.Bool("dry-run", false, "只输出需要执行的操作")
+	sizeOnly := flags.Bool("size-only", false, "只比较大小，不比较修改时间")
+	verbose := flags.Bool("v", false, "输出跳过的对象")
+	var include, exclude stringList
+	flags.Var(&include, "include", "只同步匹配的对象，可以重复指定")
+	flags.Var(&exclude, "exclude", "跳过匹配的对象，可以重复指定")
+	src, err := parse(flags, dsn, args)
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		return &usageError{message: "sync requires -to"}
+	}
+	dst, err := oss.Open(*to)
+	if err != nil {
+		return err
+	}
+	prefix := "/"
+	if flags.NArg() > 0 {
+		prefix = flags.Arg(0)
+	}
+
+	opts := &osssync.Options{
+		Prefix:      prefix,
+		Concurrency: *concurrency,
+		Include:     include,
+		Exclude:     exclude,
+		Delete:      *deleteExtraneous,
+		DryRun:      *dryRun,
+		OnResult: func(result osssync.Result) {
+			switch {
+			case result.Err != nil:
+				fmt.Fprintf(stdout, "failed %s %s: %v\n", result.Action, result.Path, result.Err)
+			case result.Action != osssync.ActionSkip || *verbose:
+				fmt.Fprintf(stdout, "%s %s\n", result.Action, result.Path)
+			}
+		},
+	}
+	if *sizeOnly {
+		opts.Strategy = osssync.CompareSize
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := osssync.Sync(ctx, src, dst, opts)
+	fmt.Fprintf(stdout, "copied %d (%d bytes), skipped %d, deleted %d, failed %d\n",
+		report.Copied, report.Bytes, report.Skipped, report.Deleted, len(report.Errors))
+	return err
+}
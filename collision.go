@@ -0,0 +1,78 @@
+package oss
+
+import (
+	"fmt"
+	pathpkg "path"
+	"strconv"
+	"strings"
+)
+
+// CollisionPolicy 上传路径已存在对象时的处理策略
+type CollisionPolicy string
+
+const (
+	// CollisionOverwrite 覆盖已有对象，为默认策略
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionError 已有对象时返回 ErrConflict
+	CollisionError CollisionPolicy = "error"
+	// CollisionRename 已有对象时在文件名后追加序号，例如 a-1.txt
+	CollisionRename CollisionPolicy = "rename"
+)
+
+// MaxRenameAttempts 自动重命名时尝试的最大序号
+const MaxRenameAttempts = 1000
+
+// RenamedPath 在文件名和扩展名之间追加序号
+// 参数:
+//   - path: 原始路径
+//   - n: 序号
+// 返回:
+//   - string: 重命名后的路径，例如 /docs/a-1.txt
+func RenamedPath(path string, n int) string {
+	dir, name := pathpkg.Split(path)
+	ext := pathpkg.Ext(name)
+	// 以点开头的文件名没有扩展名，例如 .env
+	if ext == name {
+		ext = ""
+	}
+	return dir + strings.TrimSuffix(name, ext) + "-" + strconv.Itoa(n) + ext
+}
+
+// ResolvePath 按冲突策略确定实际上传的路径
+// 通过 Exists 检查已有对象，检查和上传之间的并发写入无法发现，
+// 支持条件上传的后端对 CollisionError 使用条件上传代替该检查
+// 参数:
+//   - storage: 存储接口
+//   - path: 上传路径
+// 返回:
+//   - string: 实际上传的路径，重命名时为追加序号的路径
+//   - error: 策略为 CollisionError 且对象已存在时返回 ErrConflict
+func (opts PutOptions) ResolvePath(storage StorageInterface, path string) (string, error) {
+	switch opts.Collision {
+	case "", CollisionOverwrite:
+		return path, nil
+	case CollisionError, CollisionRename:
+	default:
+		return "", fmt.Errorf("%w: collision policy %s", ErrNotSupported, opts.Collision)
+	}
+
+	exists, err := storage.Exists(path)
+	if err != nil || !exists {
+		return path, err
+	}
+	if opts.Collision == CollisionError {
+		return "", fmt.Errorf("%w: %s already exists", ErrConflict, path)
+	}
+
+	for n := 1; n <= MaxRenameAttempts; n++ {
+		candidate := RenamedPath(path, n)
+		exists, err := storage.Exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no free name for %s after %d attempts", ErrConflict, path, MaxRenameAttempts)
+}
@@ -0,0 +1,49 @@
+// Package composite 将多个存储后端组合为一个存储接口
+// 例如将每次写入复制到其他区域和本地NAS的镜像存储
+package composite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReplicationError 写入主存储成功但部分副本写入失败的错误
+type ReplicationError struct {
+	// Op 操作名称，例如 Put、Delete
+	Op string
+	// Path 对象路径
+	Path string
+	// Errors 每个副本的错误，下标与副本的顺序一致，成功的副本为nil
+	Errors []error
+}
+
+// Error 返回错误描述
+func (err *ReplicationError) Error() string {
+	var messages []string
+	for index, replicaErr := range err.Errors {
+		if replicaErr != nil {
+			messages = append(messages, fmt.Sprintf("replica %d: %v", index, replicaErr))
+		}
+	}
+	return fmt.Sprintf("composite: %s %s: %s", err.Op, err.Path, strings.Join(messages, "; "))
+}
+
+// Unwrap 返回失败副本的错误，使 errors.Is 可以匹配统一错误类型
+func (err *ReplicationError) Unwrap() []error {
+	var errs []error
+	for _, replicaErr := range err.Errors {
+		if replicaErr != nil {
+			errs = append(errs, replicaErr)
+		}
+	}
+	return errs
+}
+
+// newReplicationError 根据每个副本的错误创建复制错误，全部成功时返回nil
+func newReplicationError(op, path string, errs []error) error {
+	if errors.Join(errs...) == nil {
+		return nil
+	}
+	return &ReplicationError{Op: op, Path: path, Errors: errs}
+}
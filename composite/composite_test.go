@@ -0,0 +1,245 @@
+package composite
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func content(t *testing.T, storage oss.StorageInterface, path string) string {
+	t.Helper()
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return ""
+	}
+	defer stream.Close()
+	data, _ := io.ReadAll(stream)
+	return string(data)
+}
+
+func TestMirror(t *testing.T) {
+	primary, region, nas := filesystem.New(t.TempDir()), filesystem.New(t.TempDir()), ossmock.New()
+	mirror := NewMirror(primary, region, nas)
+
+	// 没有Seek能力的内容也要完整写入每个副本
+	if _, err := mirror.Put("/uploads/a.txt", io.MultiReader(strings.NewReader("sam"), strings.NewReader("ple"))); err != nil {
+		t.Fatalf("No error should happen when put, but got %v", err)
+	}
+	for index, storage := range []oss.StorageInterface{primary, region, nas} {
+		if got := content(t, storage, "/uploads/a.txt"); got != "sample" {
+			t.Errorf("Backend %d should contain the upload, but got %q", index, got)
+		}
+	}
+
+	writer, _ := mirror.NewWriter("/uploads/b.txt")
+	io.WriteString(writer, "stream")
+	if err := writer.Close(); err != nil || content(t, nas, "/uploads/b.txt") != "stream" {
+		t.Errorf("Writer should be replicated on close, but got %v", err)
+	}
+
+	if err := mirror.Move("/uploads/b.txt", "/uploads/c.txt"); err != nil || content(t, region, "/uploads/c.txt") != "stream" {
+		t.Errorf("Move should be replicated, but got %v", err)
+	}
+
+	nas.FailWith("Delete", oss.ErrUnavailable)
+	err := mirror.Delete("/uploads/a.txt")
+	var replicationErr *ReplicationError
+	if !errors.As(err, &replicationErr) || replicationErr.Errors[0] != nil || !errors.Is(err, oss.ErrUnavailable) {
+		t.Errorf("Should report the failed replica, but got %v", err)
+	}
+	if exists, _ := region.Exists("/uploads/a.txt"); exists {
+		t.Errorf("Healthy replica should be deleted even if another replica failed")
+	}
+}
+
+func TestAsyncMirror(t *testing.T) {
+	primary, replica := ossmock.New(), ossmock.New()
+	var (
+		mutex  sync.Mutex
+		failed []string
+	)
+	mirror := NewMirrorWithOptions(MirrorOptions{Async: true, OnError: func(index int, op, path string, err error) {
+		mutex.Lock()
+		failed = append(failed, op+" "+path)
+		mutex.Unlock()
+	}}, primary, replica)
+
+	mirror.Put("/a.txt", strings.NewReader("v1"))
+	mirror.Put("/a.txt", strings.NewReader("v2"))
+	mirror.Put("/b.txt", strings.NewReader("b"))
+	mirror.Delete("/b.txt")
+	replica.FailWith("Copy", oss.ErrUnavailable)
+	mirror.Copy("/a.txt", "/c.txt")
+	mirror.Close()
+
+	if got := content(t, replica, "/a.txt"); got != "v2" {
+		t.Errorf("Replica should end with the latest version, but got %q", got)
+	}
+	if exists, _ := replica.Exists("/b.txt"); exists {
+		t.Errorf("Delete should be replicated in order")
+	}
+	if got := content(t, replica, "/c.txt"); got != "v2" {
+		t.Errorf("Failed copy should fall back to reading the primary, but got %q", got)
+	}
+	if mirror.Pending() != 0 {
+		t.Errorf("Queue should be drained after close, but got %v", mirror.Pending())
+	}
+
+	mirror.Put("/d.txt", strings.NewReader("d"))
+	if len(failed) != 1 || failed[0] != "Put /d.txt" {
+		t.Errorf("Writes after close should be reported, but got %v", failed)
+	}
+}
+
+func TestMirrorCollision(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		primary, replica := ossmock.New(), ossmock.New()
+		mirror := NewMirrorWithOptions(MirrorOptions{Async: async}, primary, replica)
+		mirror.Put("/a.txt", strings.NewReader("old"))
+		object, err := mirror.PutWithOptions("/a.txt", strings.NewReader("new"), &oss.PutOptions{Collision: oss.CollisionRename})
+		mirror.Close()
+		if err != nil || object.Path != "/a-1.txt" {
+			t.Fatalf("Renamed upload should succeed on the primary, but got %v, %v", object, err)
+		}
+		for _, path := range []string{"/a.txt", "/a-1.txt"} {
+			if got, expected := content(t, replica, path), content(t, primary, path); got != expected {
+				t.Errorf("Replica should match the primary at %v in async mode %v, expected %q but got %q", path, async, expected, got)
+			}
+		}
+		if objects, _ := replica.List("/"); len(objects) != 2 {
+			t.Errorf("Replica should not rename the replicated upload again in async mode %v, but got %v objects", async, len(objects))
+		}
+	}
+}
+
+func TestFailover(t *testing.T) {
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+
+	primary, fallback := ossmock.New(), ossmock.New()
+	primary.Put("/a.txt", strings.NewReader("primary"))
+	fallback.Put("/a.txt", strings.NewReader("fallback"))
+	fallback.Put("/b.txt", strings.NewReader("fallback"))
+	var failovers []string
+	failover := NewFailoverWithOptions(FailoverOptions{
+		Writes:     true,
+		Circuit:    oss.CircuitBreakerOptions{FailureThreshold: 2, OpenTimeout: time.Minute},
+		OnFailover: func(op, path string, err error) { failovers = append(failovers, op+" "+path) },
+	}, primary, fallback)
+
+	if got := content(t, failover, "/a.txt"); got != "primary" {
+		t.Errorf("Healthy primary should serve reads, but got %q", got)
+	}
+	if got := content(t, failover, "/b.txt"); got != "fallback" || !failover.Healthy() {
+		t.Errorf("Object missing in primary should be read from fallback without marking primary unhealthy, but got %q", got)
+	}
+
+	primary.FailWith("GetStream", oss.ErrUnavailable)
+	primary.FailWith("Put", oss.ErrUnavailable)
+	if got := content(t, failover, "/a.txt"); got != "fallback" {
+		t.Errorf("Failed read should be retried on fallback, but got %q", got)
+	}
+	if _, err := failover.Put("/c.txt", strings.NewReader("c")); err != nil || content(t, fallback, "/c.txt") != "c" {
+		t.Errorf("Failed write should be retried on fallback, but got %v", err)
+	}
+	if failover.Healthy() {
+		t.Errorf("Primary should be unhealthy after consecutive failures")
+	}
+	calls := len(primary.CallsTo("GetStream"))
+	content(t, failover, "/a.txt")
+	if len(primary.CallsTo("GetStream")) != calls {
+		t.Errorf("Unhealthy primary should be skipped")
+	}
+
+	primary.FailWith("GetStream", nil)
+	now = now.Add(time.Minute)
+	if got := content(t, failover, "/a.txt"); got != "primary" || !failover.Healthy() {
+		t.Errorf("Successful probe should switch back to primary, but got %q", got)
+	}
+	if _, err := failover.Put("/d.txt", io.MultiReader(strings.NewReader("d"))); !errors.Is(err, oss.ErrUnavailable) {
+		t.Errorf("Consumed content that cannot seek should not be retried, but got %v", err)
+	}
+	if strings.Join(failovers, ",") != "GetStream /b.txt,GetStream /a.txt,Put /c.txt,GetStream /a.txt" {
+		t.Errorf("Failovers should be reported, but got %v", failovers)
+	}
+}
+
+func TestSharded(t *testing.T) {
+	hot, cold, archive := ossmock.New(), ossmock.New(), ossmock.New()
+	hashRouter := NewHashRouter([]string{"hot", "cold"}, 0)
+	router := NewPrefixRouter([]PrefixRule{{Prefix: "/archive/", Shard: "archive"}, {Prefix: "archive/2024/", Shard: "cold"}}, hashRouter)
+	sharded := NewSharded(router, map[string]oss.StorageInterface{"hot": hot, "cold": cold, "archive": archive})
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[hashRouter.Route(fmt.Sprintf("objects/%d", i))]++
+	}
+	if counts["hot"] < 300 || counts["cold"] < 300 {
+		t.Errorf("Consistent hashing should spread keys across shards, but got %v", counts)
+	}
+	if got := router.Route("archive/2024/a.txt"); got != "cold" {
+		t.Errorf("Longest prefix should win, but got %v", got)
+	}
+
+	var paths []string
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("/objects/%02d.txt", i)
+		paths = append(paths, path)
+		sharded.Put(path, strings.NewReader(path))
+	}
+	sharded.Put("/archive/a.txt", strings.NewReader("archived"))
+	if got := content(t, archive, "/archive/a.txt"); got != "archived" {
+		t.Errorf("Prefix rule should route to the archive shard, but got %q", got)
+	}
+	for _, path := range paths {
+		name, storage, _ := sharded.Locate(path)
+		if content(t, storage, path) != path || content(t, sharded, path) != path || name != hashRouter.Route(strings.TrimPrefix(path, "/")) {
+			t.Errorf("%v should be stored in shard %v", path, name)
+		}
+	}
+
+	objects, err := sharded.List("/objects")
+	if err != nil || len(objects) != 20 || objects[0].Path != "/objects/00.txt" || objects[19].Path != "/objects/19.txt" {
+		t.Errorf("List should merge all shards in order, but got %v, %v", len(objects), err)
+	}
+
+	if err := sharded.Move("/archive/a.txt", "/objects/moved.txt"); err != nil || content(t, sharded, "/objects/moved.txt") != "archived" {
+		t.Errorf("Move across shards should copy the content, but got %v", err)
+	}
+	if exists, _ := archive.Exists("/archive/a.txt"); exists {
+		t.Errorf("Move across shards should delete the source")
+	}
+
+	cold.FailWith("DeleteObjects", oss.ErrUnavailable)
+	err = sharded.DeleteObjects(paths)
+	var deleteErr *oss.DeleteObjectsError
+	if !errors.As(err, &deleteErr) || len(deleteErr.Errors) != shardCount(hashRouter, paths, "cold") {
+		t.Errorf("Only paths in the failed shard should be reported, but got %v", err)
+	}
+	for _, path := range paths {
+		exists, _ := hot.Exists(path)
+		if exists {
+			t.Errorf("%v should be deleted from the healthy shard", path)
+		}
+	}
+}
+
+func shardCount(router Router, paths []string, shard string) int {
+	count := 0
+	for _, path := range paths {
+		if router.Route(strings.TrimPrefix(path, "/")) == shard {
+			count++
+		}
+	}
+	return count
+}
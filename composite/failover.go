@@ -0,0 +1,306 @@
+package composite
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/smart-unicom/oss"
+)
+
+// FailoverOptions 故障转移存储的选项
+type FailoverOptions struct {
+	// Writes 主存储故障时是否将写入转到备用存储，为false时写入只访问主存储
+	Writes bool
+	// Circuit 判断主存储健康状态的熔断器配置，连续失败 FailureThreshold 次后直接使用备用存储，
+	// 经过 OpenTimeout 后用一次调用探测主存储，成功后切换回主存储
+	Circuit oss.CircuitBreakerOptions
+	// OnFailover 调用转到备用存储时的回调，err 为主存储返回的错误，可以为nil
+	// 用于记录转到备用存储的写入，在主存储恢复后补齐
+	OnFailover func(op, path string, err error)
+}
+
+// Failover 主存储故障时转到备用存储的存储接口
+// 读取在主存储返回任何错误时重试备用存储，例如主存储中不存在的对象也会从备用存储读取；
+// 主存储的健康状态由熔断器判断，不健康时读取直接访问备用存储，不再等待主存储超时。
+// 转到备用存储的写入不会同步回主存储，主存储恢复后需要根据 OnFailover 的记录使用 oss.Migrate 补齐
+type Failover struct {
+	// StorageInterface 主存储，GetURL 等只在本地计算的方法直接调用主存储
+	oss.StorageInterface
+	// Fallback 备用存储
+	Fallback oss.StorageInterface
+	// Options 故障转移选项
+	Options FailoverOptions
+
+	breaker *oss.CircuitBreakerStorage
+}
+
+// NewFailover 创建主存储故障时将读取转到备用存储的存储接口
+// 参数:
+//   - primary: 主存储
+//   - fallback: 备用存储
+// 返回:
+//   - *Failover: 故障转移存储实例
+func NewFailover(primary, fallback oss.StorageInterface) *Failover {
+	return NewFailoverWithOptions(FailoverOptions{}, primary, fallback)
+}
+
+// NewFailoverWithOptions 使用指定选项创建故障转移存储
+// 参数:
+//   - opts: 故障转移选项
+//   - primary: 主存储
+//   - fallback: 备用存储
+// 返回:
+//   - *Failover: 故障转移存储实例
+func NewFailoverWithOptions(opts FailoverOptions, primary, fallback oss.StorageInterface) *Failover {
+	breaker := oss.WithCircuitBreaker(primary, opts.Circuit)
+	opts.Circuit = breaker.Options
+	return &Failover{StorageInterface: primary, Fallback: fallback, Options: opts, breaker: breaker}
+}
+
+// Healthy 返回主存储是否健康，不健康时调用直接访问备用存储，半开状态视为不健康
+// 返回:
+//   - bool: 主存储是否健康
+func (failover *Failover) Healthy() bool {
+	return failover.breaker.State() == oss.CircuitClosed
+}
+
+// notify 调用 OnFailover 回调
+func (failover *Failover) notify(op, path string, err error) {
+	if failover.Options.OnFailover != nil {
+		failover.Options.OnFailover(op, path, err)
+	}
+}
+
+// read 从主存储读取，失败时从备用存储读取，两者都失败时返回两个错误
+func read[T any](failover *Failover, op, path string, fn func(storage oss.StorageInterface) (T, error)) (T, error) {
+	value, err := fn(failover.breaker)
+	if err == nil {
+		return value, nil
+	}
+	failover.notify(op, path, err)
+	fallbackValue, fallbackErr := fn(failover.Fallback)
+	if fallbackErr != nil {
+		return fallbackValue, errors.Join(err, fallbackErr)
+	}
+	return fallbackValue, nil
+}
+
+// write 写入主存储，启用写入转移且主存储故障时写入备用存储
+// retryable 判断主存储失败后是否还能重试，例如上传内容已被读取且不能Seek时不能重试
+func write[T any](failover *Failover, op, path string, retryable func() bool, fn func(storage oss.StorageInterface) (T, error)) (T, error) {
+	if !failover.Options.Writes {
+		return fn(failover.StorageInterface)
+	}
+	value, err := fn(failover.breaker)
+	if err == nil || !(errors.Is(err, oss.ErrCircuitOpen) || failover.Options.Circuit.IsFailure(err)) {
+		return value, err
+	}
+	// 熔断器打开时主存储没有被调用，上传内容没有被读取
+	if !errors.Is(err, oss.ErrCircuitOpen) && retryable != nil && !retryable() {
+		return value, err
+	}
+	failover.notify(op, path, err)
+	return fn(failover.Fallback)
+}
+
+// rewind 记录上传内容的位置，返回主存储失败后回到该位置的函数
+func rewind(reader io.Reader) func() bool {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return func() bool { return false }
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return func() bool { return false }
+	}
+	return func() bool {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err == nil
+	}
+}
+
+// Get 获取文件，主存储失败时从备用存储获取
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (failover *Failover) Get(path string) (*os.File, error) {
+	return read(failover, "Get", path, func(storage oss.StorageInterface) (*os.File, error) {
+		return storage.Get(path)
+	})
+}
+
+// GetStream 获取文件流，打开主存储的流失败时从备用存储获取，读取过程中的错误不会转移
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (failover *Failover) GetStream(path string) (io.ReadCloser, error) {
+	return read(failover, "GetStream", path, func(storage oss.StorageInterface) (io.ReadCloser, error) {
+		return storage.GetStream(path)
+	})
+}
+
+// GetStreamRange 范围读取文件流，打开主存储的流失败时从备用存储获取
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (failover *Failover) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return read(failover, "GetStreamRange", path, func(storage oss.StorageInterface) (io.ReadCloser, error) {
+		return storage.GetStreamRange(path, offset, length)
+	})
+}
+
+// Stat 获取对象信息，主存储失败时从备用存储获取
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (failover *Failover) Stat(path string) (*oss.Object, error) {
+	return read(failover, "Stat", path, func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.Stat(path)
+	})
+}
+
+// Exists 检查对象是否存在，主存储中不存在或失败时检查备用存储
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息，只在两个存储都失败时返回
+func (failover *Failover) Exists(path string) (bool, error) {
+	exists, err := failover.breaker.Exists(path)
+	if err == nil && exists {
+		return true, nil
+	}
+	failover.notify("Exists", path, err)
+	fallbackExists, fallbackErr := failover.Fallback.Exists(path)
+	switch {
+	case fallbackErr == nil:
+		return fallbackExists, nil
+	case err == nil:
+		return false, nil
+	}
+	return false, errors.Join(err, fallbackErr)
+}
+
+// List 列出对象，主存储失败时列出备用存储中的对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (failover *Failover) List(path string) ([]*oss.Object, error) {
+	return read(failover, "List", path, func(storage oss.StorageInterface) ([]*oss.Object, error) {
+		return storage.List(path)
+	})
+}
+
+// Put 上传文件，启用写入转移且主存储故障时上传到备用存储，内容不能Seek时只在主存储不健康时转移
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (failover *Failover) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return write(failover, "Put", path, rewind(reader), func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.Put(path, reader)
+	})
+}
+
+// PutWithOptions 使用指定选项上传文件，启用写入转移且主存储故障时上传到备用存储
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (failover *Failover) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	return write(failover, "PutWithOptions", path, rewind(reader), func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.PutWithOptions(path, reader, opts)
+	})
+}
+
+// NewWriter 创建流式写入器，启用写入转移且创建主存储的写入器失败时写入备用存储
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (failover *Failover) NewWriter(path string) (io.WriteCloser, error) {
+	return write(failover, "NewWriter", path, nil, func(storage oss.StorageInterface) (io.WriteCloser, error) {
+		return storage.NewWriter(path)
+	})
+}
+
+// Delete 删除文件，启用写入转移且主存储故障时从备用存储删除
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (failover *Failover) Delete(path string) error {
+	_, err := write(failover, "Delete", path, nil, func(storage oss.StorageInterface) (struct{}, error) {
+		return struct{}{}, storage.Delete(path)
+	})
+	return err
+}
+
+// DeleteObjects 批量删除对象，启用写入转移且主存储故障时从备用存储删除
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (failover *Failover) DeleteObjects(paths []string) error {
+	_, err := write(failover, "DeleteObjects", "", nil, func(storage oss.StorageInterface) (struct{}, error) {
+		return struct{}{}, storage.DeleteObjects(paths)
+	})
+	return err
+}
+
+// DeleteDir 删除目录，启用写入转移且主存储故障时从备用存储删除
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (failover *Failover) DeleteDir(dir string) error {
+	_, err := write(failover, "DeleteDir", dir, nil, func(storage oss.StorageInterface) (struct{}, error) {
+		return struct{}{}, storage.DeleteDir(dir)
+	})
+	return err
+}
+
+// Copy 复制文件，启用写入转移且主存储故障时在备用存储中复制
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (failover *Failover) Copy(srcPath, dstPath string) error {
+	_, err := write(failover, "Copy", dstPath, nil, func(storage oss.StorageInterface) (struct{}, error) {
+		return struct{}{}, storage.Copy(srcPath, dstPath)
+	})
+	return err
+}
+
+// Move 移动文件，启用写入转移且主存储故障时在备用存储中移动
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (failover *Failover) Move(srcPath, dstPath string) error {
+	_, err := write(failover, "Move", dstPath, nil, func(storage oss.StorageInterface) (struct{}, error) {
+		return struct{}{}, storage.Move(srcPath, dstPath)
+	})
+	return err
+}
@@ -0,0 +1,401 @@
+package composite
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultMirrorBuffer 异步复制时每个副本的队列长度
+const DefaultMirrorBuffer = 1024
+
+// ErrMirrorClosed 镜像存储已关闭，之后的写入只写入主存储，不再复制到副本
+var ErrMirrorClosed = errors.New("composite: mirror closed")
+
+// MirrorOptions 镜像存储的选项
+type MirrorOptions struct {
+	// Async 是否异步复制到副本，为false时全部副本写入完成后才返回
+	Async bool
+	// Buffer 异步复制时每个副本的队列长度，队列已满时写入等待，小于等于0时使用 DefaultMirrorBuffer
+	Buffer int
+	// OnError 异步复制失败时的回调，replica 为副本的下标，为nil时忽略错误
+	OnError func(replica int, op, path string, err error)
+}
+
+// Mirror 将写入复制到多个副本的镜像存储
+// 读取和列举只访问主存储；写入先写入主存储，成功后再写入每个副本。
+// 同步模式下副本写入失败时返回 *ReplicationError，主存储中的写入不会回滚；
+// 异步模式下每个副本有独立的队列，按写入顺序复制，慢的副本不会拖慢其他副本，失败交给 OnError 处理
+type Mirror struct {
+	// StorageInterface 主存储
+	oss.StorageInterface
+	// Replicas 副本存储
+	Replicas []oss.StorageInterface
+	// Options 镜像选项
+	Options MirrorOptions
+
+	mu     sync.RWMutex
+	closed bool
+	queues []chan mirrorJob
+	wg     sync.WaitGroup
+}
+
+// mirrorJob 需要复制到副本的一次写入
+type mirrorJob struct {
+	op     string
+	path   string
+	source string
+	paths  []string
+	opts   *oss.PutOptions
+	// content 同步上传时已缓存的内容，为nil时从主存储读取
+	content io.ReaderAt
+	size    int64
+}
+
+// NewMirror 创建同步复制的镜像存储
+// 参数:
+//   - primary: 主存储
+//   - replicas: 副本存储
+// 返回:
+//   - *Mirror: 镜像存储实例
+func NewMirror(primary oss.StorageInterface, replicas ...oss.StorageInterface) *Mirror {
+	return NewMirrorWithOptions(MirrorOptions{}, primary, replicas...)
+}
+
+// NewMirrorWithOptions 使用指定选项创建镜像存储
+// 参数:
+//   - opts: 镜像选项
+//   - primary: 主存储
+//   - replicas: 副本存储
+// 返回:
+//   - *Mirror: 镜像存储实例，异步模式下不再使用时调用 Close 等待队列中的写入复制完毕
+func NewMirrorWithOptions(opts MirrorOptions, primary oss.StorageInterface, replicas ...oss.StorageInterface) *Mirror {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultMirrorBuffer
+	}
+	mirror := &Mirror{StorageInterface: primary, Replicas: replicas, Options: opts}
+	if opts.Async {
+		for index := range replicas {
+			queue := make(chan mirrorJob, opts.Buffer)
+			mirror.queues = append(mirror.queues, queue)
+			mirror.wg.Add(1)
+			go mirror.run(index, queue)
+		}
+	}
+	return mirror
+}
+
+// Pending 返回异步复制队列中等待复制的写入数量，包括全部副本
+// 返回:
+//   - int: 等待复制的写入数量
+func (mirror *Mirror) Pending() int {
+	var pending int
+	for _, queue := range mirror.queues {
+		pending += len(queue)
+	}
+	return pending
+}
+
+// Close 停止接收新的复制，等待队列中的写入复制完毕
+// 返回:
+//   - error: 错误信息
+func (mirror *Mirror) Close() error {
+	mirror.mu.Lock()
+	if !mirror.closed {
+		mirror.closed = true
+		for _, queue := range mirror.queues {
+			close(queue)
+		}
+	}
+	mirror.mu.Unlock()
+	mirror.wg.Wait()
+	return nil
+}
+
+// run 按顺序将队列中的写入复制到一个副本
+func (mirror *Mirror) run(index int, queue chan mirrorJob) {
+	defer mirror.wg.Done()
+	for job := range queue {
+		if err := mirror.apply(mirror.Replicas[index], job); err != nil {
+			mirror.report(index, job, err)
+		}
+	}
+}
+
+// report 将异步复制的失败交给 OnError 处理
+func (mirror *Mirror) report(index int, job mirrorJob, err error) {
+	if mirror.Options.OnError != nil {
+		mirror.Options.OnError(index, job.op, job.path, err)
+	}
+}
+
+// replicate 将写入复制到全部副本，同步模式下返回每个副本的错误
+func (mirror *Mirror) replicate(job mirrorJob) error {
+	if !mirror.Options.Async {
+		errs := make([]error, len(mirror.Replicas))
+		var wg sync.WaitGroup
+		for index, replica := range mirror.Replicas {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[index] = mirror.apply(replica, job)
+			}()
+		}
+		wg.Wait()
+		return newReplicationError(job.op, job.path, errs)
+	}
+
+	// 异步复制时不缓存内容，由后台从主存储读取
+	job.content = nil
+	mirror.mu.RLock()
+	defer mirror.mu.RUnlock()
+	for index, queue := range mirror.queues {
+		if mirror.closed {
+			mirror.report(index, job, ErrMirrorClosed)
+			continue
+		}
+		queue <- job
+	}
+	return nil
+}
+
+// apply 在一个副本上执行写入
+func (mirror *Mirror) apply(replica oss.StorageInterface, job mirrorJob) error {
+	switch job.op {
+	case "Put", "PutWithOptions", "NewWriter":
+		return mirror.copyObject(replica, job)
+	case "Delete":
+		if err := replica.Delete(job.path); err != nil && !errors.Is(err, oss.ErrNotFound) {
+			return err
+		}
+		return nil
+	case "DeleteObjects":
+		return replica.DeleteObjects(job.paths)
+	case "DeleteDir":
+		return replica.DeleteDir(job.path)
+	case "Copy":
+		// 副本中缺少源对象时从主存储复制目标对象
+		if err := replica.Copy(job.source, job.path); err != nil {
+			return mirror.copyObject(replica, job)
+		}
+		return nil
+	case "Move":
+		if err := replica.Move(job.source, job.path); err != nil {
+			if err := mirror.copyObject(replica, job); err != nil {
+				return err
+			}
+			if err := replica.Delete(job.source); err != nil && !errors.Is(err, oss.ErrNotFound) {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// copyObject 将对象写入副本，没有缓存的内容时从主存储读取；主存储中对象已被删除时删除副本中的对象
+func (mirror *Mirror) copyObject(replica oss.StorageInterface, job mirrorJob) error {
+	var reader io.Reader
+	if job.content != nil {
+		reader = io.NewSectionReader(job.content, 0, job.size)
+	} else {
+		stream, err := mirror.StorageInterface.GetStream(job.path)
+		if errors.Is(err, oss.ErrNotFound) {
+			if err := replica.Delete(job.path); err != nil && !errors.Is(err, oss.ErrNotFound) {
+				return err
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		reader = stream
+	}
+
+	var err error
+	if job.opts != nil {
+		_, err = replica.PutWithOptions(job.path, reader, job.opts)
+	} else {
+		_, err = replica.Put(job.path, reader)
+	}
+	return err
+}
+
+// buffer 同步复制时缓存上传内容，使主存储和每个副本都可以读取完整的内容
+// 返回:
+//   - io.ReaderAt: 缓存的内容，异步模式或没有副本时为nil
+//   - int64: 内容大小
+//   - func(): 释放缓存
+func (mirror *Mirror) buffer(reader io.Reader) (io.ReaderAt, int64, func(), error) {
+	if mirror.Options.Async || len(mirror.Replicas) == 0 {
+		return nil, 0, func() {}, nil
+	}
+	file, err := oss.CreateTempFile("oss-mirror-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		oss.RemoveTempFile(file)
+		return nil, 0, nil, err
+	}
+	return file, size, func() { oss.RemoveTempFile(file) }, nil
+}
+
+// Put 上传文件到主存储并复制到副本
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 主存储中的对象信息
+//   - error: 主存储写入失败时的错误，同步模式下副本写入失败时返回 *ReplicationError
+func (mirror *Mirror) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return mirror.put("Put", path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到主存储，并使用相同的选项复制到副本
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 主存储中的对象信息
+//   - error: 主存储写入失败时的错误，同步模式下副本写入失败时返回 *ReplicationError
+func (mirror *Mirror) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	return mirror.put("PutWithOptions", path, reader, opts)
+}
+
+// put 缓存内容后依次写入主存储和副本
+func (mirror *Mirror) put(op, path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	content, size, release, err := mirror.buffer(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if content != nil {
+		reader = io.NewSectionReader(content, 0, size)
+	}
+
+	var object *oss.Object
+	if opts != nil {
+		object, err = mirror.StorageInterface.PutWithOptions(path, reader, opts)
+	} else {
+		object, err = mirror.StorageInterface.Put(path, reader)
+	}
+	if err != nil {
+		return object, err
+	}
+
+	// 副本写入主存储中实际写入的路径，例如按 CollisionRename 重命名后的路径，并且不再按冲突策略处理，
+	// 使副本与主存储的路径和内容一致
+	job := mirrorJob{op: op, path: path, opts: opts, content: content, size: size}
+	if object != nil && object.Path != "" {
+		job.path = object.Path
+	}
+	if opts != nil && opts.Collision != "" {
+		replicated := *opts
+		replicated.Collision = ""
+		job.opts = &replicated
+	}
+	return object, mirror.replicate(job)
+}
+
+// NewWriter 创建写入主存储的流式写入器，关闭后从主存储读取内容复制到副本
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，同步模式下副本写入失败时 Close 返回 *ReplicationError
+//   - error: 错误信息
+func (mirror *Mirror) NewWriter(path string) (io.WriteCloser, error) {
+	writer, err := mirror.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &mirrorWriter{WriteCloser: writer, mirror: mirror, path: path}, nil
+}
+
+// Delete 从主存储和副本中删除文件，副本中不存在的对象视为删除成功
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 主存储删除失败时的错误，同步模式下副本删除失败时返回 *ReplicationError
+func (mirror *Mirror) Delete(path string) error {
+	if err := mirror.StorageInterface.Delete(path); err != nil {
+		return err
+	}
+	return mirror.replicate(mirrorJob{op: "Delete", path: path})
+}
+
+// DeleteObjects 从主存储和副本中批量删除对象
+// 主存储部分删除失败时仍然从副本中删除全部对象
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (mirror *Mirror) DeleteObjects(paths []string) error {
+	err := mirror.StorageInterface.DeleteObjects(paths)
+	var deleteErr *oss.DeleteObjectsError
+	if err != nil && !errors.As(err, &deleteErr) {
+		return err
+	}
+	return errors.Join(err, mirror.replicate(mirrorJob{op: "DeleteObjects", paths: paths}))
+}
+
+// DeleteDir 从主存储和副本中删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (mirror *Mirror) DeleteDir(dir string) error {
+	if err := mirror.StorageInterface.DeleteDir(dir); err != nil {
+		return err
+	}
+	return mirror.replicate(mirrorJob{op: "DeleteDir", path: dir})
+}
+
+// Copy 在主存储和副本中复制文件，副本中缺少源对象时从主存储读取目标对象
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (mirror *Mirror) Copy(srcPath, dstPath string) error {
+	if err := mirror.StorageInterface.Copy(srcPath, dstPath); err != nil {
+		return err
+	}
+	return mirror.replicate(mirrorJob{op: "Copy", path: dstPath, source: srcPath})
+}
+
+// Move 在主存储和副本中移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (mirror *Mirror) Move(srcPath, dstPath string) error {
+	if err := mirror.StorageInterface.Move(srcPath, dstPath); err != nil {
+		return err
+	}
+	return mirror.replicate(mirrorJob{op: "Move", path: dstPath, source: srcPath})
+}
+
+// mirrorWriter 关闭后复制到副本的写入器
+type mirrorWriter struct {
+	io.WriteCloser
+	mirror *Mirror
+	path   string
+}
+
+// Close 关闭主存储的写入器并复制到副本
+func (writer *mirrorWriter) Close() error {
+	if err := writer.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return writer.mirror.replicate(mirrorJob{op: "NewWriter", path: writer.path})
+}
@@ -0,0 +1,514 @@
+package composite
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultVirtualNodes 一致性哈希中每个分片的默认虚拟节点数
+const DefaultVirtualNodes = 128
+
+// Router 决定对象存放在哪个分片
+type Router interface {
+	// Route 返回对象所在分片的名称
+	// 参数:
+	//   - key: 对象键，不带前导斜杠
+	// 返回:
+	//   - string: 分片名称，没有匹配的分片时为空
+	Route(key string) string
+}
+
+// RouterFunc 函数形式的分片路由
+type RouterFunc func(key string) string
+
+// Route 调用函数返回分片名称
+func (fn RouterFunc) Route(key string) string {
+	return fn(key)
+}
+
+// HashRouter 一致性哈希分片路由
+// 增加或删除分片时只有约 1/N 的对象需要移动到其他分片
+type HashRouter struct {
+	ring  []uint64
+	names map[uint64]string
+}
+
+// NewHashRouter 创建一致性哈希分片路由
+// 参数:
+//   - names: 分片名称
+//   - virtualNodes: 每个分片的虚拟节点数，越多分布越均匀，小于等于0时使用 DefaultVirtualNodes
+// 返回:
+//   - *HashRouter: 分片路由实例
+func NewHashRouter(names []string, virtualNodes int) *HashRouter {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	router := &HashRouter{names: map[uint64]string{}}
+	for _, name := range names {
+		for i := 0; i < virtualNodes; i++ {
+			hash := hashKey(name + "#" + strconv.Itoa(i))
+			if _, exists := router.names[hash]; exists {
+				continue
+			}
+			router.names[hash] = name
+			router.ring = append(router.ring, hash)
+		}
+	}
+	sort.Slice(router.ring, func(i, j int) bool { return router.ring[i] < router.ring[j] })
+	return router
+}
+
+// Route 返回哈希环上对象键之后的第一个分片
+func (router *HashRouter) Route(key string) string {
+	if len(router.ring) == 0 {
+		return ""
+	}
+	hash := hashKey(key)
+	index := sort.Search(len(router.ring), func(i int) bool { return router.ring[i] >= hash })
+	if index == len(router.ring) {
+		index = 0
+	}
+	return router.names[router.ring[index]]
+}
+
+// hashKey 计算对象键的64位哈希值
+func hashKey(key string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return hash.Sum64()
+}
+
+// PrefixRule 前缀分片规则
+type PrefixRule struct {
+	// Prefix 对象键前缀，例如 avatars/
+	Prefix string
+	// Shard 分片名称
+	Shard string
+}
+
+// PrefixRouter 按对象键前缀选择分片的路由，最长的前缀优先
+type PrefixRouter struct {
+	rules    []PrefixRule
+	fallback Router
+}
+
+// NewPrefixRouter 创建按对象键前缀选择分片的路由
+// 参数:
+//   - rules: 前缀规则，前缀的前导斜杠会被忽略
+//   - fallback: 没有匹配的规则时使用的路由，例如 HashRouter，为nil时没有匹配的对象无法存放
+// 返回:
+//   - *PrefixRouter: 分片路由实例
+func NewPrefixRouter(rules []PrefixRule, fallback Router) *PrefixRouter {
+	sorted := make([]PrefixRule, len(rules))
+	for i, rule := range rules {
+		sorted[i] = PrefixRule{Prefix: strings.TrimPrefix(rule.Prefix, "/"), Shard: rule.Shard}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Prefix) > len(sorted[j].Prefix) })
+	return &PrefixRouter{rules: sorted, fallback: fallback}
+}
+
+// Route 返回最长匹配前缀的分片，没有匹配时使用备用路由
+func (router *PrefixRouter) Route(key string) string {
+	for _, rule := range router.rules {
+		if strings.HasPrefix(key, rule.Prefix) {
+			return rule.Shard
+		}
+	}
+	if router.fallback != nil {
+		return router.fallback.Route(key)
+	}
+	return ""
+}
+
+// Sharded 将对象分散存放到多个存储后端的存储接口
+// 单个对象的操作只访问路由选择的分片；List 和 DeleteDir 并发访问全部分片并合并结果，
+// DeleteObjects 按分片分组后并发删除；跨分片的 Copy 和 Move 通过下载再上传完成。
+// 路由的结果必须稳定，修改路由规则或增减分片后，位置变化的对象需要先使用 oss.Migrate 移动到新的分片
+type Sharded struct {
+	// Router 分片路由
+	Router Router
+	// Shards 分片名称到存储接口的映射
+	Shards map[string]oss.StorageInterface
+
+	names []string
+}
+
+// NewSharded 创建分片存储
+// 参数:
+//   - router: 分片路由，例如 NewHashRouter 或 NewPrefixRouter
+//   - shards: 分片名称到存储接口的映射
+// 返回:
+//   - *Sharded: 分片存储实例
+func NewSharded(router Router, shards map[string]oss.StorageInterface) *Sharded {
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return &Sharded{Router: router, Shards: shards, names: names}
+}
+
+// Locate 返回对象所在分片的名称和存储接口
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 分片名称
+//   - oss.StorageInterface: 分片的存储接口
+//   - error: 路由没有返回已配置的分片时返回 oss.ErrInvalidPath
+func (sharded *Sharded) Locate(path string) (string, oss.StorageInterface, error) {
+	name := sharded.Router.Route(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+	storage, ok := sharded.Shards[name]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: no shard for %s", oss.ErrInvalidPath, path)
+	}
+	return name, storage, nil
+}
+
+// shard 返回对象所在分片的存储接口
+func (sharded *Sharded) shard(path string) (oss.StorageInterface, error) {
+	_, storage, err := sharded.Locate(path)
+	return storage, err
+}
+
+// each 并发对每个分片执行操作，返回按分片名称排序的错误
+func (sharded *Sharded) each(fn func(storage oss.StorageInterface) error) []error {
+	errs := make([]error, len(sharded.names))
+	var wg sync.WaitGroup
+	for index, name := range sharded.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[index] = fn(sharded.Shards[name])
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// routed 在对象所在的分片上执行操作
+func routed[T any](sharded *Sharded, path string, fn func(storage oss.StorageInterface) (T, error)) (T, error) {
+	storage, err := sharded.shard(path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return fn(storage)
+}
+
+// Get 从对象所在的分片获取文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (sharded *Sharded) Get(path string) (*os.File, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (*os.File, error) {
+		return storage.Get(path)
+	})
+}
+
+// GetStream 从对象所在的分片获取文件流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (sharded *Sharded) GetStream(path string) (io.ReadCloser, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (io.ReadCloser, error) {
+		return storage.GetStream(path)
+	})
+}
+
+// GetStreamRange 从对象所在的分片范围读取文件流
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (sharded *Sharded) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (io.ReadCloser, error) {
+		return storage.GetStreamRange(path, offset, length)
+	})
+}
+
+// Stat 从对象所在的分片获取对象信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (sharded *Sharded) Stat(path string) (*oss.Object, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.Stat(path)
+	})
+}
+
+// Exists 检查对象所在的分片中对象是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (sharded *Sharded) Exists(path string) (bool, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (bool, error) {
+		return storage.Exists(path)
+	})
+}
+
+// Put 上传文件到路由选择的分片
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (sharded *Sharded) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.Put(path, reader)
+	})
+}
+
+// PutWithOptions 使用指定选项上传文件到路由选择的分片
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (sharded *Sharded) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (*oss.Object, error) {
+		return storage.PutWithOptions(path, reader, opts)
+	})
+}
+
+// NewWriter 创建写入路由选择的分片的流式写入器
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (sharded *Sharded) NewWriter(path string) (io.WriteCloser, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (io.WriteCloser, error) {
+		return storage.NewWriter(path)
+	})
+}
+
+// Delete 从对象所在的分片删除文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (sharded *Sharded) Delete(path string) error {
+	storage, err := sharded.shard(path)
+	if err != nil {
+		return err
+	}
+	return storage.Delete(path)
+}
+
+// DeleteObjects 按分片分组后并发批量删除对象
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分对象删除失败时返回 *oss.DeleteObjectsError
+func (sharded *Sharded) DeleteObjects(paths []string) error {
+	failures := map[string]error{}
+	groups := map[string][]string{}
+	for _, path := range paths {
+		name, _, err := sharded.Locate(path)
+		if err != nil {
+			failures[path] = err
+			continue
+		}
+		groups[name] = append(groups[name], path)
+	}
+
+	var (
+		mutex sync.Mutex
+		wg    sync.WaitGroup
+	)
+	for name, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sharded.Shards[name].DeleteObjects(group)
+			if err == nil {
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			var deleteErr *oss.DeleteObjectsError
+			if errors.As(err, &deleteErr) {
+				for path, pathErr := range deleteErr.Errors {
+					failures[path] = pathErr
+				}
+				return
+			}
+			for _, path := range group {
+				failures[path] = err
+			}
+		}()
+	}
+	wg.Wait()
+	return oss.NewDeleteObjectsError(failures)
+}
+
+// DeleteDir 从全部分片中删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (sharded *Sharded) DeleteDir(dir string) error {
+	return errors.Join(sharded.each(func(storage oss.StorageInterface) error {
+		return storage.DeleteDir(dir)
+	})...)
+}
+
+// Copy 复制文件，源对象和目标对象不在同一个分片时通过下载再上传完成
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (sharded *Sharded) Copy(srcPath, dstPath string) error {
+	srcName, src, err := sharded.Locate(srcPath)
+	if err != nil {
+		return err
+	}
+	dstName, dst, err := sharded.Locate(dstPath)
+	if err != nil {
+		return err
+	}
+	if srcName == dstName {
+		return src.Copy(srcPath, dstPath)
+	}
+
+	stream, err := src.GetStream(srcPath)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = dst.Put(dstPath, stream)
+	return err
+}
+
+// Move 移动文件，源对象和目标对象不在同一个分片时复制后删除源对象
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (sharded *Sharded) Move(srcPath, dstPath string) error {
+	srcName, src, err := sharded.Locate(srcPath)
+	if err != nil {
+		return err
+	}
+	dstName, _, err := sharded.Locate(dstPath)
+	if err != nil {
+		return err
+	}
+	if srcName == dstName {
+		return src.Move(srcPath, dstPath)
+	}
+	if err := sharded.Copy(srcPath, dstPath); err != nil {
+		return err
+	}
+	return src.Delete(srcPath)
+}
+
+// List 并发列出全部分片中的对象，合并后按路径排序
+// 目录只在部分分片中存在时不返回错误，全部分片中都不存在时返回 oss.ErrNotFound
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (sharded *Sharded) List(path string) ([]*oss.Object, error) {
+	var (
+		mutex   sync.Mutex
+		objects []*oss.Object
+	)
+	errs := sharded.each(func(storage oss.StorageInterface) error {
+		shardObjects, err := storage.List(path)
+		mutex.Lock()
+		objects = append(objects, shardObjects...)
+		mutex.Unlock()
+		return err
+	})
+
+	found := false
+	var notFound error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			found = true
+		case errors.Is(err, oss.ErrNotFound):
+			notFound = err
+		default:
+			return nil, err
+		}
+	}
+	if !found && notFound != nil {
+		return nil, notFound
+	}
+	oss.SortObjects(objects)
+	return objects, nil
+}
+
+// GetURL 返回对象所在分片的访问URL
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 访问URL
+//   - error: 错误信息
+func (sharded *Sharded) GetURL(path string) (string, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (string, error) {
+		return storage.GetURL(path)
+	})
+}
+
+// GetSignedURL 返回对象所在分片的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (sharded *Sharded) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (string, error) {
+		return storage.GetSignedURL(path, opts)
+	})
+}
+
+// GetUploadURL 返回路由选择的分片的直传地址
+// 参数:
+//   - path: 目标路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址
+//   - error: 错误信息
+func (sharded *Sharded) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	return routed(sharded, path, func(storage oss.StorageInterface) (*oss.UploadURL, error) {
+		return storage.GetUploadURL(path, opts)
+	})
+}
+
+// GetEndpoint 获取服务端点，分片存储由多个后端组成，没有统一的服务端点
+// 返回:
+//   - string: 空字符串，需要分片的端点时通过 Locate 获取分片后调用
+func (sharded *Sharded) GetEndpoint() string {
+	return ""
+}
@@ -0,0 +1,374 @@
+// Package configfile 从YAML或JSON配置文件创建多个命名存储
+// 配置文件描述每个存储的后端、凭据引用和按顺序叠加的包装器，应用通过配置而不是代码组装存储
+package configfile
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/osscache"
+	"github.com/smart-unicom/oss/osslog"
+	"github.com/smart-unicom/oss/ossretry"
+	"github.com/smart-unicom/oss/tracing"
+)
+
+// Config 配置文件，JSON是YAML的子集，两种格式使用同一个解析器
+type Config struct {
+	// Stores 按名称配置的存储
+	Stores map[string]Store `yaml:"stores"`
+}
+
+// Store 单个存储的配置
+// Provider、DSN 和 From 必须且只能设置一个
+type Store struct {
+	// Provider 存储后端的连接字符串协议，例如 s3、file、synology，需要导入对应的包
+	Provider string `yaml:"provider"`
+	// Location 连接字符串中协议之后的部分，例如存储桶名称 assets、本地目录 /data 或 nas.example.com:5001/share
+	Location string `yaml:"location"`
+	// Params 连接字符串的查询参数，例如 region、endpoint
+	Params map[string]string `yaml:"params"`
+	// DSN 完整的连接字符串，不需要分开填写 Provider、Location 和 Params 时使用
+	DSN string `yaml:"dsn"`
+	// From 在另一个命名存储（包括其包装器）的基础上继续叠加包装器
+	From string `yaml:"from"`
+	// Credentials 访问凭据，可以引用环境变量或文件，避免在配置文件中保存密钥
+	Credentials Credentials `yaml:"credentials"`
+	// Decorators 按顺序叠加的包装器，每一项只有一个键，例如 prefix: /site，先列出的在内层
+	Decorators []map[string]string `yaml:"decorators"`
+}
+
+// Credentials 访问凭据
+// 值为 env:NAME 时读取环境变量，为 file:/path 时读取文件内容并去掉首尾空白，其它值按原样使用
+type Credentials struct {
+	// AccessID 访问密钥ID，作为连接字符串的用户名
+	AccessID string `yaml:"access_id"`
+	// AccessKey 访问密钥，作为连接字符串的密码
+	AccessKey string `yaml:"access_key"`
+}
+
+// Decorator 根据配置值包装存储
+// 参数:
+//   - storage: 被包装的存储接口
+//   - value: 配置中的值
+// 返回:
+//   - oss.StorageInterface: 包装后的存储接口
+//   - error: 错误信息
+type Decorator func(storage oss.StorageInterface, value string) (oss.StorageInterface, error)
+
+var (
+	decoratorsMu sync.RWMutex
+	decorators   = map[string]Decorator{
+		"prefix":       decoratePrefix,
+		"timeout":      decorateTimeout,
+		"retry":        decorateRetry,
+		"cache":        decorateCache,
+		"residency":    decorateResidency,
+		"content_type": decorateContentType,
+		"log":          decorateLog,
+		"tracing":      decorateTracing,
+	}
+)
+
+// RegisterDecorator 注册配置文件中可以使用的包装器，例如应用自己的包装器
+// 参数:
+//   - name: 包装器名称
+//   - decorator: 包装器
+func RegisterDecorator(name string, decorator Decorator) {
+	if name == "" || decorator == nil {
+		panic("configfile: RegisterDecorator name and decorator must not be empty")
+	}
+	decoratorsMu.Lock()
+	defer decoratorsMu.Unlock()
+	if _, exists := decorators[name]; exists {
+		panic("configfile: RegisterDecorator called twice for " + name)
+	}
+	decorators[name] = decorator
+}
+
+// Decorators 返回已注册的包装器名称
+// 返回:
+//   - []string: 按字典序排序的包装器名称
+func Decorators() []string {
+	decoratorsMu.RLock()
+	defer decoratorsMu.RUnlock()
+	names := make([]string, 0, len(decorators))
+	for name := range decorators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse 解析YAML或JSON格式的配置，存在未识别的字段时返回错误，以便发现拼写错误
+// 参数:
+//   - data: 配置内容
+// 返回:
+//   - *Config: 配置
+//   - error: 错误信息
+func Parse(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	config := &Config{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("configfile: %w", err)
+	}
+	return config, nil
+}
+
+// Load 读取配置文件并创建其中的全部存储
+// 参数:
+//   - path: 配置文件路径
+// 返回:
+//   - map[string]oss.StorageInterface: 按名称索引的存储
+//   - error: 错误信息
+func Load(path string) (map[string]oss.StorageInterface, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("configfile: %w", err)
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return config.Build()
+}
+
+// Build 创建配置中的全部存储，任意一个创建失败时返回错误
+// 返回:
+//   - map[string]oss.StorageInterface: 按名称索引的存储
+//   - error: 错误信息，包含出错的存储名称
+func (config *Config) Build() (map[string]oss.StorageInterface, error) {
+	builder := &builder{config: config, built: map[string]oss.StorageInterface{}, building: map[string]bool{}}
+	names := make([]string, 0, len(config.Stores))
+	for name := range config.Stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := builder.build(name); err != nil {
+			return nil, err
+		}
+	}
+	return builder.built, nil
+}
+
+// builder 按依赖顺序创建存储，检测 From 的循环引用
+type builder struct {
+	config   *Config
+	built    map[string]oss.StorageInterface
+	building map[string]bool
+}
+
+// build 创建指定名称的存储
+func (builder *builder) build(name string) (oss.StorageInterface, error) {
+	if storage, ok := builder.built[name]; ok {
+		return storage, nil
+	}
+	store, ok := builder.config.Stores[name]
+	if !ok {
+		return nil, fmt.Errorf("configfile: store %s is not defined", name)
+	}
+	if builder.building[name] {
+		return nil, fmt.Errorf("configfile: store %s references itself through from", name)
+	}
+	builder.building[name] = true
+	defer delete(builder.building, name)
+
+	var storage oss.StorageInterface
+	var err error
+	if store.From != "" {
+		if store.Provider != "" || store.DSN != "" {
+			return nil, fmt.Errorf("configfile: store %s: from cannot be combined with provider or dsn", name)
+		}
+		storage, err = builder.build(store.From)
+	} else {
+		storage, err = store.open()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configfile: store %s: %w", name, err)
+	}
+
+	for _, decorator := range store.Decorators {
+		if storage, err = decorate(storage, decorator); err != nil {
+			return nil, fmt.Errorf("configfile: store %s: %w", name, err)
+		}
+	}
+	builder.built[name] = storage
+	return storage, nil
+}
+
+// open 拼接连接字符串并通过 oss.Open 创建存储客户端
+func (store Store) open() (oss.StorageInterface, error) {
+	dsn := store.DSN
+	switch {
+	case store.Provider != "" && dsn != "":
+		return nil, fmt.Errorf("provider and dsn cannot both be set")
+	case store.Provider != "":
+		dsn = store.Provider + "://" + store.Location
+	case dsn == "":
+		return nil, fmt.Errorf("one of provider, dsn or from is required")
+	}
+
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	accessID, err := resolve(store.Credentials.AccessID)
+	if err != nil {
+		return nil, err
+	}
+	accessKey, err := resolve(store.Credentials.AccessKey)
+	if err != nil {
+		return nil, err
+	}
+	if accessID != "" || accessKey != "" {
+		parsed.User = url.UserPassword(accessID, accessKey)
+	}
+	if len(store.Params) > 0 {
+		query := parsed.Query()
+		for key, value := range store.Params {
+			query.Set(key, value)
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	return oss.Open(parsed.String())
+}
+
+// resolve 解析凭据引用
+func resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("credential environment variable %s is not set", name)
+		}
+		return resolved, nil
+	case strings.HasPrefix(value, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(value, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("read credential: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return value, nil
+}
+
+// decorate 按配置叠加一个包装器
+func decorate(storage oss.StorageInterface, config map[string]string) (oss.StorageInterface, error) {
+	if len(config) != 1 {
+		return nil, fmt.Errorf("each decorator must have exactly one key, got %d", len(config))
+	}
+	for name, value := range config {
+		decoratorsMu.RLock()
+		decorator, ok := decorators[name]
+		decoratorsMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("%w: decorator %s, available decorators are %s", oss.ErrNotSupported, name, strings.Join(Decorators(), ", "))
+		}
+		wrapped, err := decorator(storage, value)
+		if err != nil {
+			return nil, fmt.Errorf("decorator %s: %w", name, err)
+		}
+		storage = wrapped
+	}
+	return storage, nil
+}
+
+// decoratePrefix 以配置的目录为根目录
+func decoratePrefix(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	if value == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+	return oss.NewPrefixedStorage(storage, value), nil
+}
+
+// decorateTimeout 限制单次调用的时间，值为 time.ParseDuration 格式，例如 30s
+func decorateTimeout(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q", value)
+	}
+	return oss.WithTimeout(storage, duration), nil
+}
+
+// decorateRetry 重试临时错误，值为最大尝试次数，为空时使用默认值
+func decorateRetry(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	var config ossretry.Config
+	if value != "" {
+		attempts, err := strconv.Atoi(value)
+		if err != nil || attempts <= 0 {
+			return nil, fmt.Errorf("invalid max attempts %q", value)
+		}
+		config.MaxAttempts = attempts
+	}
+	return ossretry.Wrap(storage, config), nil
+}
+
+// decorateCache 将读取过的对象缓存在本地磁盘上，值为缓存目录和最大字节数，例如 /var/cache/oss,1073741824
+func decorateCache(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	items := splitList(value)
+	if len(items) != 2 {
+		return nil, fmt.Errorf("cache directory and max bytes are required, got %q", value)
+	}
+	maxBytes, err := strconv.ParseInt(items[1], 10, 64)
+	if err != nil || maxBytes <= 0 {
+		return nil, fmt.Errorf("invalid max bytes %q", items[1])
+	}
+	cached, err := osscache.New(storage, items[0], maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return cached, nil
+}
+
+// decorateResidency 限制数据存放区域，值为逗号分隔的区域
+func decorateResidency(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	return oss.WithResidency(storage, splitList(value)...)
+}
+
+// decorateContentType 只允许上传指定的内容类型，值为逗号分隔的类型，例如 image/*,application/pdf
+func decorateContentType(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	allow := splitList(value)
+	if len(allow) == 0 {
+		return nil, fmt.Errorf("at least one content type is required")
+	}
+	return oss.WithContentTypePolicy(storage, oss.ContentTypePolicy{Allow: allow}), nil
+}
+
+// decorateLog 通过 slog.Default() 记录操作日志，值为成功调用的日志级别，例如 debug，为空时为 info
+func decorateLog(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	logged := osslog.Wrap(storage, nil)
+	if value != "" {
+		if err := logged.Level.UnmarshalText([]byte(value)); err != nil {
+			return nil, err
+		}
+	}
+	return logged, nil
+}
+
+// decorateTracing 创建OpenTelemetry span，值为记录在span中的存储桶名称，可以为空
+func decorateTracing(storage oss.StorageInterface, value string) (oss.StorageInterface, error) {
+	return tracing.Wrap(storage, tracing.Options{Bucket: value}), nil
+}
+
+// splitList 拆分逗号分隔的列表，忽略空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
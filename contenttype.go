@@ -0,0 +1,281 @@
+package oss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// SniffLength 检测内容类型时读取的最大字节数，与 http.DetectContentType 一致
+const SniffLength = 512
+
+// ErrContentTypeRejected 上传内容的类型不被策略允许，errors.Is(err, ErrPermissionDenied) 同样成立
+var ErrContentTypeRejected error = &kindError{message: "oss: content type rejected", parent: ErrPermissionDenied}
+
+// ContentTypeError 上传内容的类型被 ContentTypePolicy 拒绝的错误
+// errors.Is(err, ErrContentTypeRejected) 成立，errors.As 可以取出声明和检测到的类型
+type ContentTypeError struct {
+	// Path 上传的目标路径
+	Path string
+	// Declared 声明的内容类型，取自 PutOptions.ContentType 或扩展名，可能为空
+	Declared string
+	// Detected 根据内容检测到的类型
+	Detected string
+	// Reason 拒绝的原因
+	Reason string
+}
+
+// Error 返回错误描述
+func (err *ContentTypeError) Error() string {
+	return fmt.Sprintf("%v: %s: %s (declared %q, detected %q)", ErrContentTypeRejected, err.Path, err.Reason, err.Declared, err.Detected)
+}
+
+// Unwrap 返回统一错误类型 ErrContentTypeRejected
+func (err *ContentTypeError) Unwrap() error {
+	return ErrContentTypeRejected
+}
+
+// ContentTypePolicy 上传内容类型的允许和拒绝策略
+// 类型模式不区分大小写，忽略参数，支持 image/* 形式的通配
+type ContentTypePolicy struct {
+	// Allow 允许的类型，为空时允许除 Deny 之外的全部类型；声明的类型和检测到的类型都必须被允许
+	Allow []string
+	// Deny 拒绝的类型，声明的类型或检测到的类型匹配时拒绝，优先于 Allow
+	Deny []string
+	// Strict 为true时检测到的类型即使是无法判断的 text/plain 或 application/octet-stream 也必须被 Allow 允许
+	Strict bool
+}
+
+// Check 按策略检查上传内容的类型
+// 参数:
+//   - path: 上传的目标路径，未声明类型时根据扩展名确定
+//   - declared: 声明的内容类型，可以为空
+//   - head: 内容开头最多 SniffLength 字节
+// 返回:
+//   - error: 被拒绝时返回 *ContentTypeError
+func (policy ContentTypePolicy) Check(path, declared string, head []byte) error {
+	if declared == "" {
+		declared = mime.TypeByExtension(filepath.Ext(path))
+	}
+	declaredType, detectedType := mediaType(declared), mediaType(DetectContentType(head))
+	reject := func(reason string) error {
+		return &ContentTypeError{Path: path, Declared: declaredType, Detected: detectedType, Reason: reason}
+	}
+
+	for _, candidate := range []string{declaredType, detectedType} {
+		if candidate != "" && matchContentType(policy.Deny, candidate) {
+			return reject(candidate + " is denied")
+		}
+	}
+	if len(policy.Allow) == 0 {
+		return nil
+	}
+	if declaredType != "" && !matchContentType(policy.Allow, declaredType) {
+		return reject(declaredType + " is not allowed")
+	}
+	generic := detectedType == "text/plain" || detectedType == "application/octet-stream"
+	if (policy.Strict || !generic) && !matchContentType(policy.Allow, detectedType) {
+		return reject("content is " + detectedType)
+	}
+	return nil
+}
+
+// DetectContentType 根据内容开头检测内容类型
+// 在 http.DetectContentType 的基础上识别Windows、Linux和macOS可执行文件以及脚本
+// 参数:
+//   - head: 内容开头最多 SniffLength 字节
+// 返回:
+//   - string: 内容类型，无法判断时为 application/octet-stream
+func DetectContentType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("MZ")):
+		return "application/vnd.microsoft.portable-executable"
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "application/x-executable"
+	case bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xce}), bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(head, []byte{0xce, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(head, []byte{0xcf, 0xfa, 0xed, 0xfe}):
+		return "application/x-mach-binary"
+	case bytes.HasPrefix(head, []byte("#!")):
+		return "text/x-shellscript"
+	}
+	return http.DetectContentType(head)
+}
+
+// mediaType 返回去掉参数并转为小写的媒体类型
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	if mediatype, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediatype
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
+
+// matchContentType 判断媒体类型是否匹配任意一个模式
+func matchContentType(patterns []string, contentType string) bool {
+	for _, pattern := range patterns {
+		pattern = mediaType(pattern)
+		if pattern == "*/*" || pattern == contentType ||
+			strings.HasSuffix(pattern, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentTypeStorage 上传前按策略检查内容类型的存储包装器
+// 检查读取内容开头的字节，不只依据扩展名；复制和移动不改变内容，不会被检查
+type ContentTypeStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Policy 内容类型策略
+	Policy ContentTypePolicy
+}
+
+// WithContentTypePolicy 创建上传前按策略检查内容类型的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - policy: 内容类型策略
+// 返回:
+//   - *ContentTypeStorage: 存储包装器实例
+func WithContentTypePolicy(storage StorageInterface, policy ContentTypePolicy) *ContentTypeStorage {
+	return &ContentTypeStorage{StorageInterface: storage, Policy: policy}
+}
+
+// Put 检查内容类型后上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，类型被拒绝时返回 *ContentTypeError
+func (storage *ContentTypeStorage) Put(path string, reader io.Reader) (*Object, error) {
+	reader, err := storage.check(path, "", reader)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.Put(path, reader)
+}
+
+// PutWithOptions 检查内容类型后使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，ContentType 作为声明的类型
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，类型被拒绝时返回 *ContentTypeError
+func (storage *ContentTypeStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	var declared string
+	if opts != nil {
+		declared = opts.ContentType
+	}
+	reader, err := storage.check(path, declared, reader)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.PutWithOptions(path, reader, opts)
+}
+
+// NewWriter 创建检查内容类型的流式写入器
+// 写入的前 SniffLength 字节先缓存在内存中，检查通过后才创建被包装存储的写入器，被拒绝时不会留下部分对象
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，类型被拒绝时 Write 或 Close 返回 *ContentTypeError
+//   - error: 错误信息
+func (storage *ContentTypeStorage) NewWriter(path string) (io.WriteCloser, error) {
+	return &contentTypeWriter{storage: storage, path: path}, nil
+}
+
+// check 读取内容开头检查类型，返回从头读取完整内容的读取器
+// 可以Seek的读取器检查后回到原来的位置，保持上传时可以重新读取
+func (storage *ContentTypeStorage) check(path, declared string, reader io.Reader) (io.Reader, error) {
+	head := make([]byte, SniffLength)
+	seeker, seekable := reader.(io.ReadSeeker)
+	var offset int64
+	if seekable {
+		var err error
+		if offset, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seekable = false
+		}
+	}
+
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	head = head[:n]
+	if err := storage.Policy.Check(path, declared, head); err != nil {
+		return nil, err
+	}
+
+	if seekable {
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	return io.MultiReader(bytes.NewReader(head), reader), nil
+}
+
+// contentTypeWriter 检查内容类型后才创建被包装存储写入器的写入器
+type contentTypeWriter struct {
+	storage *ContentTypeStorage
+	path    string
+	head    []byte
+	writer  io.WriteCloser
+	err     error
+}
+
+// Write 缓存内容开头，达到 SniffLength 后检查类型并写入被包装的写入器
+func (writer *contentTypeWriter) Write(p []byte) (int, error) {
+	if writer.err != nil {
+		return 0, writer.err
+	}
+	if writer.writer != nil {
+		return writer.writer.Write(p)
+	}
+
+	writer.head = append(writer.head, p...)
+	if len(writer.head) < SniffLength {
+		return len(p), nil
+	}
+	if err := writer.open(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close 检查尚未检查的内容并关闭被包装的写入器
+func (writer *contentTypeWriter) Close() error {
+	if writer.err != nil {
+		return writer.err
+	}
+	if writer.writer == nil {
+		if err := writer.open(); err != nil {
+			return err
+		}
+	}
+	return writer.writer.Close()
+}
+
+// open 检查缓存的内容开头，通过后创建被包装的写入器并写入缓存的内容
+func (writer *contentTypeWriter) open() error {
+	if writer.err = writer.storage.Policy.Check(writer.path, "", writer.head[:min(len(writer.head), SniffLength)]); writer.err != nil {
+		return writer.err
+	}
+	if writer.writer, writer.err = writer.storage.StorageInterface.NewWriter(writer.path); writer.err != nil {
+		return writer.err
+	}
+	if _, writer.err = writer.writer.Write(writer.head); writer.err != nil {
+		return writer.err
+	}
+	writer.head = nil
+	return nil
+}
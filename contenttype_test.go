@@ -0,0 +1,46 @@
+package oss_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestContentTypePolicy(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	storage := oss.WithContentTypePolicy(fileSystem, oss.ContentTypePolicy{Allow: []string{"image/*"}})
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 16)
+
+	if _, err := storage.Put("/a.png", strings.NewReader(png)); err != nil {
+		t.Errorf("PNG image should be allowed, but got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(fileSystem.Base, "a.png")); string(data) != png {
+		t.Errorf("Sniffed content should be uploaded in full, but got %q", data)
+	}
+
+	_, err := storage.Put("/b.png", strings.NewReader("<html><script>alert(1)</script></html>"))
+	var typeErr *oss.ContentTypeError
+	if !errors.As(err, &typeErr) || typeErr.Detected != "text/html" || !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("HTML masquerading as an image should be rejected, but got %v", err)
+	}
+	if _, err := storage.PutWithOptions("/c", strings.NewReader("MZ\x90\x00"), &oss.PutOptions{ContentType: "image/png"}); !errors.Is(err, oss.ErrContentTypeRejected) {
+		t.Errorf("Executable should be rejected, but got %v", err)
+	}
+	if exists, _ := fileSystem.Exists("/b.png"); exists {
+		t.Errorf("Rejected content should not be uploaded")
+	}
+
+	writer, _ := storage.NewWriter("/d.png")
+	writer.Write([]byte("\x7fELF"))
+	if err := writer.Close(); !errors.Is(err, oss.ErrContentTypeRejected) {
+		t.Errorf("Writer should reject executables on close, but got %v", err)
+	}
+	if exists, _ := fileSystem.Exists("/d.png"); exists {
+		t.Errorf("Rejected writer should not leave a partial object")
+	}
+}
@@ -0,0 +1,45 @@
+package oss
+
+// CopyByStream 通过下载再上传的方式复制对象
+// 用于不支持服务端复制的存储后端
+// 参数:
+//   - storage: 存储接口
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func CopyByStream(storage StorageInterface, srcPath, dstPath string) error {
+	// 源和目标相同时无需复制
+	if srcPath == dstPath {
+		return nil
+	}
+
+	readCloser, err := storage.GetStream(srcPath)
+	if err != nil {
+		return err
+	}
+	defer readCloser.Close()
+
+	_, err = storage.Put(dstPath, readCloser)
+	return err
+}
+
+// MoveByCopy 通过复制后删除源文件的方式移动对象
+// 用于不支持服务端重命名的存储后端
+// 参数:
+//   - storage: 存储接口
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func MoveByCopy(storage StorageInterface, srcPath, dstPath string) error {
+	// 源和目标相同时无需移动
+	if srcPath == dstPath {
+		return nil
+	}
+
+	if err := storage.Copy(srcPath, dstPath); err != nil {
+		return err
+	}
+	return storage.Delete(srcPath)
+}
@@ -0,0 +1,202 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxDeleteObjects 单次批量删除请求的最大对象数，各云厂商批量删除接口均限制为1000
+const MaxDeleteObjects = 1000
+
+// DefaultDeleteConcurrency 逐个删除时的默认并发数
+const DefaultDeleteConcurrency = 8
+
+// DefaultDeleteChunkConcurrency 分批删除时同时进行的批量删除请求数
+const DefaultDeleteChunkConcurrency = 4
+
+// ErrDeleteRoot 拒绝删除根目录，避免误删存储桶内的全部对象
+var ErrDeleteRoot = errors.New("oss: refusing to delete root directory")
+
+// DeleteObjectsError 批量删除部分失败的错误
+// 记录每个未能删除的对象路径及原因，未出现在其中的对象均已删除
+type DeleteObjectsError struct {
+	// Errors 删除失败的对象路径到错误原因的映射
+	Errors map[string]error
+}
+
+// NewDeleteObjectsError 根据删除失败的对象创建批量删除错误
+// 参数:
+//   - failures: 删除失败的对象路径到错误原因的映射
+// 返回:
+//   - error: 没有失败的对象时返回nil
+func NewDeleteObjectsError(failures map[string]error) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &DeleteObjectsError{Errors: failures}
+}
+
+// Paths 返回删除失败的对象路径，按字典序排列
+func (err *DeleteObjectsError) Paths() []string {
+	paths := make([]string, 0, len(err.Errors))
+	for path := range err.Errors {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Error 返回错误描述
+func (err *DeleteObjectsError) Error() string {
+	paths := err.Paths()
+	messages := make([]string, 0, len(paths))
+	for _, path := range paths {
+		messages = append(messages, fmt.Sprintf("%s: %v", path, err.Errors[path]))
+	}
+	return fmt.Sprintf("oss: failed to delete %d objects: %s", len(paths), strings.Join(messages, "; "))
+}
+
+// Unwrap 返回每个对象的错误原因，使 errors.Is 可以匹配统一错误类型
+func (err *DeleteObjectsError) Unwrap() []error {
+	errs := make([]error, 0, len(err.Errors))
+	for _, path := range err.Paths() {
+		errs = append(errs, err.Errors[path])
+	}
+	return errs
+}
+
+// DeleteObjectsConcurrently 并发逐个删除对象
+// 用于不支持批量删除的存储后端，与批量删除接口一致，不存在的对象视为删除成功
+// 参数:
+//   - storage: 存储接口
+//   - paths: 文件路径列表
+//   - concurrency: 并发数，小于等于0时使用 DefaultDeleteConcurrency
+// 返回:
+//   - error: 错误信息，部分对象删除失败时返回 *DeleteObjectsError
+func DeleteObjectsConcurrently(storage StorageInterface, paths []string, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = DefaultDeleteConcurrency
+	}
+
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		failures = map[string]error{}
+		queue    = make(chan string)
+	)
+
+	for i := 0; i < concurrency && i < len(paths); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				if err := storage.Delete(path); err != nil && !errors.Is(err, ErrNotFound) {
+					mutex.Lock()
+					failures[path] = err
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		queue <- path
+	}
+	close(queue)
+	wg.Wait()
+
+	return NewDeleteObjectsError(failures)
+}
+
+// DeleteObjectsInChunks 将对象按 MaxDeleteObjects 分批，并发执行批量删除并合并每个对象的错误
+// 用于支持批量删除接口的存储后端，超过1000个对象的删除不需要调用方分批
+// 参数:
+//   - paths: 文件路径列表
+//   - concurrency: 同时进行的批量删除请求数，小于等于0时使用 DefaultDeleteChunkConcurrency
+//   - deleteChunk: 删除一批对象，返回删除失败的对象路径到错误原因的映射
+// 返回:
+//   - error: 错误信息，部分对象删除失败时返回 *DeleteObjectsError
+func DeleteObjectsInChunks(paths []string, concurrency int, deleteChunk func(chunk []string) map[string]error) error {
+	if concurrency <= 0 {
+		concurrency = DefaultDeleteChunkConcurrency
+	}
+
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		failures = map[string]error{}
+		slots    = make(chan struct{}, concurrency)
+	)
+
+	for start := 0; start < len(paths); start += MaxDeleteObjects {
+		chunk := paths[start:min(start+MaxDeleteObjects, len(paths))]
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			chunkFailures := deleteChunk(chunk)
+			mutex.Lock()
+			for path, err := range chunkFailures {
+				failures[path] = err
+			}
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return NewDeleteObjectsError(failures)
+}
+
+// DirPrefix 将目录路径转换为对象键前缀
+// 去掉前导斜杠并补齐结尾斜杠，避免 /users/a 匹配到 /users/ab 下的对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - string: 对象键前缀，根目录返回空字符串
+func DirPrefix(path string) string {
+	prefix := strings.Trim(path, "/")
+	if prefix == "" || prefix == "." {
+		return ""
+	}
+	return prefix + "/"
+}
+
+// DeleteDirByList 通过List和DeleteObjects删除目录下的全部对象
+// 用于没有分页列举接口的存储后端
+// 参数:
+//   - storage: 存储接口
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 ErrDeleteRoot
+func DeleteDirByList(storage StorageInterface, dir string) error {
+	prefix := DirPrefix(dir)
+	if prefix == "" {
+		return ErrDeleteRoot
+	}
+
+	objects, err := storage.List(dir)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// 部分后端按字符串前缀列举，需要排除同级的同名前缀对象
+	var paths []string
+	for _, object := range objects {
+		if strings.HasPrefix(strings.TrimPrefix(object.Path, "/"), prefix) {
+			paths = append(paths, object.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return storage.DeleteObjects(paths)
+}
@@ -0,0 +1,53 @@
+package oss_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+func TestDeleteObjectsInChunks(t *testing.T) {
+	paths := make([]string, 2500)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("/gc/%04d.txt", i)
+	}
+
+	var (
+		mutex               sync.Mutex
+		chunks              []int
+		running, maxRunning int
+	)
+	err := oss.DeleteObjectsInChunks(paths, 2, func(chunk []string) map[string]error {
+		mutex.Lock()
+		chunks = append(chunks, len(chunk))
+		running++
+		maxRunning = max(maxRunning, running)
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+
+		if chunk[0] == "/gc/2000.txt" {
+			return map[string]error{chunk[1]: oss.ErrPermissionDenied}
+		}
+		return nil
+	})
+
+	sort.Ints(chunks)
+	if len(chunks) != 3 || chunks[0] != 500 || chunks[2] != oss.MaxDeleteObjects {
+		t.Errorf("Should split into chunks of at most %v objects, but got %v", oss.MaxDeleteObjects, chunks)
+	}
+	if maxRunning > 2 {
+		t.Errorf("Should run at most 2 chunks concurrently, but got %v", maxRunning)
+	}
+	var deleteErr *oss.DeleteObjectsError
+	if !errors.As(err, &deleteErr) || len(deleteErr.Errors) != 1 || !errors.Is(deleteErr.Errors["/gc/2001.txt"], oss.ErrPermissionDenied) {
+		t.Errorf("Should report per-object failures, but got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package oss
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	// DefaultDownloadPartSize DownloadAt 默认的分段大小
+	DefaultDownloadPartSize = 8 << 20
+	// DefaultDownloadConcurrency DownloadAt 默认的并发数
+	DefaultDownloadConcurrency = 4
+)
+
+// Download 将文件内容直接写入w，不创建临时文件
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+//   - w: 写入目标
+// 返回:
+//   - error: 错误信息
+func Download(storage StorageInterface, path string, w io.Writer) error {
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = io.Copy(w, stream)
+	return err
+}
+
+// DownloadAt 按分段并发范围读取文件，并写入w的对应偏移
+// 文件大小取自 Stat，任意分段失败时不再下载剩余分段，已写入的内容不会被清理
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+//   - w: 写入目标，例如 *os.File
+//   - partSize: 分段大小，小于等于0时使用 DefaultDownloadPartSize
+//   - concurrency: 并发数，小于等于0时使用 DefaultDownloadConcurrency
+// 返回:
+//   - error: 错误信息
+func DownloadAt(storage StorageInterface, path string, w io.WriterAt, partSize int64, concurrency int) error {
+	if partSize <= 0 {
+		partSize = DefaultDownloadPartSize
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultDownloadConcurrency
+	}
+
+	object, err := storage.Stat(path)
+	if err != nil {
+		return err
+	}
+	return downloadParts(storage, path, w, object.Size, partSize, concurrency, nil, nil)
+}
+
+// downloadParts 使用 concurrency 个协程并发下载分段，跳过 skip 返回true的分段
+// 每个分段写入完成后调用 done，done 返回错误时停止下载剩余分段；skip 和 done 可以为nil
+func downloadParts(storage StorageInterface, path string, w io.WriterAt, size, partSize int64, concurrency int, skip func(index int) bool, done func(index int) error) error {
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		queue    = make(chan int)
+	)
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return firstErr != nil
+	}
+	fail := func(err error) {
+		mutex.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mutex.Unlock()
+	}
+
+	for i := int64(0); i < int64(concurrency) && i*partSize < size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range queue {
+				if failed() {
+					continue
+				}
+				offset := int64(index) * partSize
+				if err := downloadPart(storage, path, w, offset, min(partSize, size-offset)); err != nil {
+					fail(err)
+				} else if done != nil {
+					if err := done(index); err != nil {
+						fail(err)
+					}
+				}
+			}
+		}()
+	}
+
+	for index := 0; int64(index)*partSize < size && !failed(); index++ {
+		if skip != nil && skip(index) {
+			continue
+		}
+		queue <- index
+	}
+	close(queue)
+	wg.Wait()
+
+	return firstErr
+}
+
+// downloadPart 范围读取一个分段并写入w的对应偏移
+func downloadPart(storage StorageInterface, path string, w io.WriterAt, offset, length int64) error {
+	stream, err := storage.GetStreamRange(path, offset, length)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	n, err := io.Copy(io.NewOffsetWriter(w, offset), stream)
+	if err != nil {
+		return err
+	}
+	if n != length {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
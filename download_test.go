@@ -0,0 +1,39 @@
+package oss_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestDownload(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	content := strings.Repeat("0123456789", 1000)
+	fileSystem.Put("/a.txt", strings.NewReader(content))
+
+	var buffer strings.Builder
+	if err := oss.Download(fileSystem, "/a.txt", &buffer); err != nil || buffer.String() != content {
+		t.Errorf("Download should write whole content, but got %v bytes, %v", buffer.Len(), err)
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := oss.DownloadAt(fileSystem, "/a.txt", file, 999, 3); err != nil {
+		t.Fatalf("No error should happen when download in parts, but got %v", err)
+	}
+	if data, _ := os.ReadFile(file.Name()); string(data) != content {
+		t.Errorf("DownloadAt should write every part at its offset, but got %v bytes", len(data))
+	}
+
+	if err := oss.DownloadAt(fileSystem, "/missing.txt", file, 0, 0); !errors.Is(err, oss.ErrNotFound) {
+		t.Errorf("DownloadAt of missing file should return ErrNotFound, but got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotSupported 存储后端不支持该操作或选项
+var ErrNotSupported = errors.New("oss: not supported")
+
+// ErrKeyTooLong 对象键超过存储后端的长度限制
+var ErrKeyTooLong = errors.New("oss: key too long")
+
+// ErrInvalidPath 路径包含存储后端无法保存的内容，例如Windows保留设备名
+var ErrInvalidPath = errors.New("oss: invalid path")
+
+// ErrInvalidRange 读取范围无效
+var ErrInvalidRange = errors.New("oss: invalid range")
+
+// ErrChecksumMismatch 内容的校验和与期望值不一致
+var ErrChecksumMismatch = errors.New("oss: checksum mismatch")
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("oss: not found")
+	// ErrPermissionDenied 没有访问权限
+	ErrPermissionDenied = errors.New("oss: permission denied")
+	// ErrConflict 对象已存在或与当前状态冲突
+	ErrConflict = errors.New("oss: conflict")
+	// ErrTooLarge 对象超过存储后端的大小限制
+	ErrTooLarge = errors.New("oss: too large")
+	// ErrRateLimited 请求过于频繁被存储后端限流
+	ErrRateLimited = errors.New("oss: rate limited")
+	// ErrUnavailable 存储后端暂时不可用
+	ErrUnavailable = errors.New("oss: unavailable")
+	// ErrTimeout 操作超过了设置的超时时间
+	ErrTimeout = errors.New("oss: timeout")
+)
+
+var (
+	// ErrObjectNotFound 对象不存在，errors.Is(err, ErrNotFound) 同样成立
+	ErrObjectNotFound error = &kindError{message: "oss: object not found", parent: ErrNotFound}
+	// ErrBucketNotFound 存储桶或容器不存在，errors.Is(err, ErrNotFound) 同样成立
+	ErrBucketNotFound error = &kindError{message: "oss: bucket not found", parent: ErrNotFound}
+	// ErrRegionNotAllowed 存储桶所在区域不在允许的列表中，errors.Is(err, ErrPermissionDenied) 同样成立
+	ErrRegionNotAllowed error = &kindError{message: "oss: region not allowed", parent: ErrPermissionDenied}
+	// ErrAccessDenied 访问被拒绝，与 ErrPermissionDenied 为同一个错误
+	ErrAccessDenied = ErrPermissionDenied
+)
+
+// kindError 带有上级分类的统一错误
+type kindError struct {
+	message string
+	parent  error
+}
+
+// Error 返回错误描述
+func (err *kindError) Error() string {
+	return err.message
+}
+
+// Unwrap 返回上级分类
+func (err *kindError) Unwrap() error {
+	return err.parent
+}
+
+// Error 包装存储后端原始错误的统一错误
+// errors.Is 可以匹配统一错误类型，errors.As 仍然可以取出SDK的原始错误
+type Error struct {
+	// Kind 统一错误类型，例如 ErrObjectNotFound
+	Kind error
+	// Err 存储后端的原始错误
+	Err error
+}
+
+// Error 返回错误描述
+func (err *Error) Error() string {
+	return err.Kind.Error() + ": " + err.Err.Error()
+}
+
+// Unwrap 同时返回统一错误类型和原始错误
+func (err *Error) Unwrap() []error {
+	return []error{err.Kind, err.Err}
+}
+
+// WrapError 使用统一错误类型包装存储后端的原始错误
+// 参数:
+//   - kind: 统一错误类型
+//   - err: 原始错误
+// 返回:
+//   - error: err为nil或已经属于该类型时原样返回
+func WrapError(kind, err error) error {
+	if err == nil || kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// KeyLengthError 对象键长度错误
+// 在请求发送前由各存储后端根据自身限制返回
+type KeyLengthError struct {
+	// Key 对象键
+	Key string
+	// Length 超出限制的部分的长度
+	Length int
+	// Limit 允许的最大长度
+	Limit int
+	// Segment 是否为单个路径段超出限制
+	Segment bool
+}
+
+// Error 返回错误描述
+func (err *KeyLengthError) Error() string {
+	if err.Segment {
+		return fmt.Sprintf("oss: path segment length %d of key %q exceeds limit %d", err.Length, err.Key, err.Limit)
+	}
+	return fmt.Sprintf("oss: key length %d of %q exceeds limit %d", err.Length, err.Key, err.Limit)
+}
+
+// Is 使 errors.Is(err, ErrKeyTooLong) 成立
+func (err *KeyLengthError) Is(target error) bool {
+	return target == ErrKeyTooLong
+}
+
+// ValidateKeyLength 校验对象键的字节长度
+// 参数:
+//   - key: 对象键
+//   - maxKeyBytes: 整个键允许的最大字节数，小于等于0时不限制
+//   - maxSegmentBytes: 单个路径段允许的最大字节数，小于等于0时不限制
+// 返回:
+//   - error: 超出限制时返回 *KeyLengthError
+func ValidateKeyLength(key string, maxKeyBytes, maxSegmentBytes int) error {
+	if maxKeyBytes > 0 && len(key) > maxKeyBytes {
+		return &KeyLengthError{Key: key, Length: len(key), Limit: maxKeyBytes}
+	}
+
+	if maxSegmentBytes > 0 {
+		for _, segment := range strings.Split(key, "/") {
+			if len(segment) > maxSegmentBytes {
+				return &KeyLengthError{Key: key, Length: len(segment), Limit: maxSegmentBytes, Segment: true}
+			}
+		}
+	}
+
+	return nil
+}
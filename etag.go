@@ -0,0 +1,447 @@
+package oss
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	pathpkg "path"
+	"strings"
+	"time"
+)
+
+// ETagSidecarSuffix ETag记录文件的后缀
+// 记录文件与对象位于同一目录，文件名为 "." + 对象文件名 + ETagSidecarSuffix
+const ETagSidecarSuffix = ".etag"
+
+// ETagRecord 保存的模拟ETag
+// 同时记录计算时对象的大小和修改时间，对象被其他程序修改后记录视为过期
+type ETagRecord struct {
+	// ETag 带引号的十六进制MD5，与云存储普通上传的ETag格式相同
+	ETag string `json:"etag"`
+	// Size 计算时对象的大小（字节）
+	Size int64 `json:"size"`
+	// LastModified 计算时对象的最后修改时间
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+// Fresh 判断记录是否与对象当前的大小和修改时间一致
+// 参数:
+//   - object: 对象信息
+// 返回:
+//   - bool: 记录未过期时返回true
+func (record *ETagRecord) Fresh(object *Object) bool {
+	if record == nil || record.ETag == "" || record.Size != object.Size {
+		return false
+	}
+	if record.LastModified == nil || object.LastModified == nil {
+		return record.LastModified == object.LastModified
+	}
+	return record.LastModified.Equal(*object.LastModified)
+}
+
+// ETagStore 保存模拟ETag的存储
+// 用于本地文件系统、群晖等不返回ETag的后端，可以替换为扩展属性、数据库等实现
+type ETagStore interface {
+	// LoadETag 读取对象的ETag记录
+	// 参数:
+	//   - path: 对象路径
+	// 返回:
+	//   - *ETagRecord: ETag记录，没有记录时返回nil
+	//   - error: 错误信息
+	LoadETag(path string) (*ETagRecord, error)
+	// SaveETag 保存对象的ETag记录
+	// 参数:
+	//   - path: 对象路径
+	//   - record: ETag记录
+	// 返回:
+	//   - error: 错误信息
+	SaveETag(path string, record *ETagRecord) error
+	// DeleteETag 删除对象的ETag记录，没有记录时不返回错误
+	// 参数:
+	//   - path: 对象路径
+	// 返回:
+	//   - error: 错误信息
+	DeleteETag(path string) error
+}
+
+// FormatETag 将MD5摘要格式化为ETag
+// 参数:
+//   - sum: MD5摘要
+// 返回:
+//   - string: 带引号的十六进制ETag
+func FormatETag(sum []byte) string {
+	return `"` + hex.EncodeToString(sum) + `"`
+}
+
+// ComputeETag 读取内容并计算ETag
+// 参数:
+//   - reader: 文件内容读取器
+// 返回:
+//   - string: 带引号的十六进制ETag
+//   - error: 读取失败时返回错误
+func ComputeETag(reader io.Reader) (string, error) {
+	h := md5.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return FormatETag(h.Sum(nil)), nil
+}
+
+// ETagSidecarPath 返回对象的ETag记录文件路径
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - string: 记录文件路径
+func ETagSidecarPath(path string) string {
+	dir, name := pathpkg.Split(path)
+	return dir + "." + name + ETagSidecarSuffix
+}
+
+// IsETagSidecar 判断路径是否为ETag记录文件
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - bool: 是记录文件时返回true
+func IsETagSidecar(path string) bool {
+	name := pathpkg.Base(strings.ReplaceAll(path, `\`, "/"))
+	return len(name) > len(ETagSidecarSuffix)+1 && strings.HasPrefix(name, ".") && strings.HasSuffix(name, ETagSidecarSuffix)
+}
+
+// SidecarETagStore 将ETag记录以JSON文件保存在对象旁边的存储
+// 本地文件系统中为同目录下的隐藏文件，群晖中同样通过FileStation上传为元数据文件
+type SidecarETagStore struct {
+	// Storage 保存记录文件的存储，通常与对象所在的存储相同
+	Storage StorageInterface
+}
+
+// LoadETag 读取对象旁边的记录文件
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - *ETagRecord: ETag记录，记录文件不存在时返回nil
+//   - error: 错误信息
+func (store *SidecarETagStore) LoadETag(path string) (*ETagRecord, error) {
+	stream, err := store.Storage.GetStream(ETagSidecarPath(path))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var record ETagRecord
+	if err := json.NewDecoder(stream).Decode(&record); err != nil {
+		return nil, fmt.Errorf("decode ETag record of %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// SaveETag 上传对象旁边的记录文件
+// 参数:
+//   - path: 对象路径
+//   - record: ETag记录
+// 返回:
+//   - error: 错误信息
+func (store *SidecarETagStore) SaveETag(path string, record *ETagRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = store.Storage.Put(ETagSidecarPath(path), bytes.NewReader(data))
+	return err
+}
+
+// DeleteETag 删除对象旁边的记录文件
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - error: 错误信息，记录文件不存在时返回nil
+func (store *SidecarETagStore) DeleteETag(path string) error {
+	if err := store.Storage.Delete(ETagSidecarPath(path)); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ETagStorage 为不返回ETag的后端模拟ETag的存储包装器
+// 写入时计算内容的MD5并保存到 Store，Stat 和 List 返回保存的ETag，使同步、迁移和一致性检查可以统一比较内容；
+// 被包装的存储已经返回ETag的对象不受影响。Stat 遇到没有记录或记录过期的对象时读取内容重新计算，List 只返回未过期的记录
+type ETagStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Store ETag记录的存储
+	Store ETagStore
+}
+
+// WithETags 创建模拟ETag的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - store: ETag记录的存储，为nil时使用保存在对象旁边的 SidecarETagStore
+// 返回:
+//   - *ETagStorage: 存储包装器实例
+func WithETags(storage StorageInterface, store ETagStore) *ETagStorage {
+	if store == nil {
+		store = &SidecarETagStore{Storage: storage}
+	}
+	return &ETagStorage{StorageInterface: storage, Store: store}
+}
+
+// Stat 获取对象信息，没有ETag时读取记录或重新计算
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ETagStorage) Stat(path string) (*Object, error) {
+	object, err := storage.StorageInterface.Stat(path)
+	if err != nil || object.ETag != "" {
+		return object, err
+	}
+
+	record, err := storage.Store.LoadETag(path)
+	if err != nil {
+		return nil, err
+	}
+	if !record.Fresh(object) {
+		// 没有记录或对象已被其他程序修改，重新计算并保存
+		stream, err := storage.StorageInterface.GetStream(path)
+		if err != nil {
+			return nil, err
+		}
+		etag, err := ComputeETag(stream)
+		stream.Close()
+		if err != nil {
+			return nil, err
+		}
+		record = &ETagRecord{ETag: etag, Size: object.Size, LastModified: object.LastModified}
+		if err := storage.Store.SaveETag(path, record); err != nil {
+			return nil, err
+		}
+	}
+	object.ETag = record.ETag
+	return object, nil
+}
+
+// List 列出对象并返回未过期的ETag记录，结果中不包含记录文件
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息
+func (storage *ETagStorage) List(path string) ([]*Object, error) {
+	objects, err := storage.StorageInterface.List(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := objects[:0]
+	for _, object := range objects {
+		if IsETagSidecar(object.Path) {
+			continue
+		}
+		if object.ETag == "" {
+			if record, err := storage.Store.LoadETag(object.Path); err == nil && record.Fresh(object) {
+				object.ETag = record.ETag
+			}
+		}
+		result = append(result, object)
+	}
+	return result, nil
+}
+
+// Put 上传文件并保存ETag
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ETagStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return storage.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件并保存ETag
+// 可寻址的读取器先读取一遍计算MD5再回到原位置上传，其它读取器在上传时计算
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ETagStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	if IsETagSidecar(path) {
+		return nil, fmt.Errorf("%w: %s is reserved for ETag records", ErrInvalidPath, path)
+	}
+
+	var (
+		etag string
+		h    hash.Hash
+	)
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if etag, err = ComputeETag(seeker); err != nil {
+			return nil, err
+		}
+		if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	} else {
+		h = md5.New()
+		reader = io.TeeReader(reader, h)
+	}
+
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	if err != nil {
+		return nil, err
+	}
+	if h != nil {
+		etag = FormatETag(h.Sum(nil))
+	}
+	return object, storage.save(objectPath(object, path), object, etag)
+}
+
+// NewWriter 创建流式写入器，关闭成功后保存ETag
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *ETagStorage) NewWriter(path string) (io.WriteCloser, error) {
+	if IsETagSidecar(path) {
+		return nil, fmt.Errorf("%w: %s is reserved for ETag records", ErrInvalidPath, path)
+	}
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &etagWriter{WriteCloser: writer, storage: storage, path: path, hash: md5.New()}, nil
+}
+
+// Copy 复制文件并为目标保存源对象的ETag
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ETagStorage) Copy(srcPath, dstPath string) error {
+	if IsETagSidecar(dstPath) {
+		return fmt.Errorf("%w: %s is reserved for ETag records", ErrInvalidPath, dstPath)
+	}
+	if err := storage.StorageInterface.Copy(srcPath, dstPath); err != nil {
+		return err
+	}
+	return storage.transfer(srcPath, dstPath)
+}
+
+// Move 移动文件并将ETag记录转移到目标
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ETagStorage) Move(srcPath, dstPath string) error {
+	if IsETagSidecar(dstPath) {
+		return fmt.Errorf("%w: %s is reserved for ETag records", ErrInvalidPath, dstPath)
+	}
+	if err := storage.StorageInterface.Move(srcPath, dstPath); err != nil {
+		return err
+	}
+	if err := storage.transfer(srcPath, dstPath); err != nil {
+		return err
+	}
+	return storage.Store.DeleteETag(srcPath)
+}
+
+// Delete 删除文件及其ETag记录
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ETagStorage) Delete(path string) error {
+	if err := storage.StorageInterface.Delete(path); err != nil {
+		return err
+	}
+	return storage.Store.DeleteETag(path)
+}
+
+// DeleteObjects 批量删除文件及其ETag记录
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *ETagStorage) DeleteObjects(paths []string) error {
+	if err := storage.StorageInterface.DeleteObjects(paths); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := storage.Store.DeleteETag(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save 按写入后对象的大小和修改时间保存ETag记录
+func (storage *ETagStorage) save(path string, object *Object, etag string) error {
+	// 写入结果缺少修改时间时重新获取，保证与之后 Stat 的结果一致
+	if object == nil || object.LastModified == nil {
+		stat, err := storage.StorageInterface.Stat(path)
+		if err != nil {
+			return err
+		}
+		object = stat
+	}
+	return storage.Store.SaveETag(path, &ETagRecord{ETag: etag, Size: object.Size, LastModified: object.LastModified})
+}
+
+// transfer 复制或移动后为目标保存源对象的ETag，源对象没有未过期的记录时删除目标的旧记录
+func (storage *ETagStorage) transfer(srcPath, dstPath string) error {
+	record, err := storage.Store.LoadETag(srcPath)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.ETag == "" {
+		return storage.Store.DeleteETag(dstPath)
+	}
+	object, err := storage.StorageInterface.Stat(dstPath)
+	if err != nil {
+		return err
+	}
+	if object.Size != record.Size {
+		return storage.Store.DeleteETag(dstPath)
+	}
+	return storage.Store.SaveETag(dstPath, &ETagRecord{ETag: record.ETag, Size: object.Size, LastModified: object.LastModified})
+}
+
+// etagWriter 写入时计算MD5，关闭成功后保存ETag记录的写入器
+type etagWriter struct {
+	io.WriteCloser
+	storage *ETagStorage
+	path    string
+	hash    hash.Hash
+}
+
+// Write 写入内容并更新MD5
+func (writer *etagWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.hash.Write(p[:n])
+	return n, err
+}
+
+// Close 关闭写入器，成功时保存ETag记录
+func (writer *etagWriter) Close() error {
+	if err := writer.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return writer.storage.save(writer.path, nil, FormatETag(writer.hash.Sum(nil)))
+}
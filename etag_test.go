@@ -0,0 +1,58 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestETags(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	storage := oss.WithETags(fileSystem, nil)
+	const etag = `"5e8ff9bf55ba3508199d22e984129be6"`
+
+	if _, err := storage.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("No error should happen when put, but got %v", err)
+	}
+	if object, err := storage.Stat("/a.txt"); err != nil || object.ETag != etag {
+		t.Errorf("Stat should return MD5 ETag of content, but got %+v, %v", object, err)
+	}
+	if _, err := fileSystem.Stat("/.a.txt.etag"); err != nil {
+		t.Errorf("ETag record should be saved next to the file, but got %v", err)
+	}
+
+	writer, _ := storage.NewWriter("/b.txt")
+	io.Copy(writer, io.MultiReader(strings.NewReader("sam"), strings.NewReader("ple")))
+	writer.Close()
+	storage.Move("/b.txt", "/dir/c.txt")
+	objects, err := storage.List("/")
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("List should hide ETag records, but got %v, %v", objects, err)
+	}
+	for _, object := range objects {
+		if object.ETag != etag {
+			t.Errorf("List should return saved ETag of %v, but got %q", object.Path, object.ETag)
+		}
+	}
+
+	// 绕过包装器修改文件后记录过期，Stat 重新计算
+	time.Sleep(10 * time.Millisecond)
+	fileSystem.Put("/a.txt", strings.NewReader("SAMPLE"))
+	if object, _ := storage.Stat("/a.txt"); object == nil || object.ETag == etag || object.ETag == "" {
+		t.Errorf("Stat should recompute ETag of modified file, but got %+v", object)
+	}
+
+	if _, err := storage.Put("/.a.txt.etag", strings.NewReader("{}")); !errors.Is(err, oss.ErrInvalidPath) {
+		t.Errorf("Writing ETag record paths should return ErrInvalidPath, but got %v", err)
+	}
+	storage.Delete("/a.txt")
+	if exists, _ := fileSystem.Exists("/.a.txt.etag"); exists {
+		t.Errorf("ETag record should be deleted with the file")
+	}
+
+}
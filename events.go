@@ -0,0 +1,391 @@
+package oss
+
+import (
+	"errors"
+	"io"
+	pathpkg "path"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// EventOp 存储事件的操作类型
+type EventOp string
+
+const (
+	// EventPut 上传成功，包括 Put、PutWithOptions 和 NewWriter 的 Close
+	EventPut EventOp = "put"
+	// EventDelete 删除成功，包括 Delete 和 DeleteObjects 中删除成功的每个对象
+	EventDelete EventOp = "delete"
+	// EventDeleteDir 删除目录成功，Path 为目录路径
+	EventDeleteDir EventOp = "delete-dir"
+	// EventCopy 复制成功
+	EventCopy EventOp = "copy"
+	// EventMove 移动成功
+	EventMove EventOp = "move"
+)
+
+// Event 写入操作成功后发布的事件
+type Event struct {
+	// Op 操作类型
+	Op EventOp
+	// Path 对象路径，删除目录时为目录路径，复制和移动时为目标路径
+	Path string
+	// Source 复制和移动的源路径
+	Source string
+	// Size 上传的字节数，其他操作为0
+	Size int64
+	// Provider 最内层存储后端的包名，例如 s3、filesystem
+	Provider string
+	// Duration 操作耗时
+	Duration time.Duration
+	// Object 上传后的对象信息，可能为nil
+	Object *Object
+}
+
+// EventListener 事件监听器，在写入操作返回前同步调用
+// 耗时的处理（例如刷新CDN）应在监听器中另起goroutine，避免拖慢写入
+type EventListener func(event Event)
+
+// EventBus 按操作类型分发存储事件的事件总线
+type EventBus struct {
+	mu        sync.RWMutex
+	listeners []eventSubscription
+	next      int
+}
+
+// eventSubscription 一个监听器注册
+type eventSubscription struct {
+	id       int
+	ops      []EventOp
+	listener EventListener
+}
+
+// NewEventBus 创建事件总线
+// 返回:
+//   - *EventBus: 事件总线实例
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe 注册监听器
+// 参数:
+//   - listener: 事件监听器
+//   - ops: 监听的操作类型，为空时监听全部事件
+// 返回:
+//   - func(): 取消注册的函数
+func (bus *EventBus) Subscribe(listener EventListener, ops ...EventOp) func() {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.next++
+	id := bus.next
+	bus.listeners = append(bus.listeners, eventSubscription{id: id, ops: ops, listener: listener})
+	return func() {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+		for i, subscription := range bus.listeners {
+			if subscription.id == id {
+				bus.listeners = append(bus.listeners[:i:i], bus.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnPut 注册上传事件的监听器
+// 参数:
+//   - listener: 事件监听器
+// 返回:
+//   - func(): 取消注册的函数
+func (bus *EventBus) OnPut(listener EventListener) func() {
+	return bus.Subscribe(listener, EventPut)
+}
+
+// OnDelete 注册删除对象和删除目录事件的监听器
+// 参数:
+//   - listener: 事件监听器
+// 返回:
+//   - func(): 取消注册的函数
+func (bus *EventBus) OnDelete(listener EventListener) func() {
+	return bus.Subscribe(listener, EventDelete, EventDeleteDir)
+}
+
+// OnCopy 注册复制和移动事件的监听器
+// 参数:
+//   - listener: 事件监听器
+// 返回:
+//   - func(): 取消注册的函数
+func (bus *EventBus) OnCopy(listener EventListener) func() {
+	return bus.Subscribe(listener, EventCopy, EventMove)
+}
+
+// Publish 按注册顺序将事件发送给监听该操作的监听器
+// 参数:
+//   - event: 存储事件
+func (bus *EventBus) Publish(event Event) {
+	bus.mu.RLock()
+	listeners := make([]EventListener, 0, len(bus.listeners))
+	for _, subscription := range bus.listeners {
+		if subscription.matches(event.Op) {
+			listeners = append(listeners, subscription.listener)
+		}
+	}
+	bus.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// matches 判断注册是否监听该操作
+func (subscription eventSubscription) matches(op EventOp) bool {
+	if len(subscription.ops) == 0 {
+		return true
+	}
+	for _, candidate := range subscription.ops {
+		if candidate == op {
+			return true
+		}
+	}
+	return false
+}
+
+// EventStorage 写入操作成功后向事件总线发布事件的存储包装器
+// 缓存失效、CDN刷新和搜索索引等逻辑注册到总线上，不需要包装每一个调用点
+type EventStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Bus 事件总线
+	Bus *EventBus
+
+	provider string
+}
+
+// WithEvents 创建发布存储事件的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - bus: 事件总线，为nil时创建新的事件总线
+// 返回:
+//   - *EventStorage: 存储包装器实例
+func WithEvents(storage StorageInterface, bus *EventBus) *EventStorage {
+	if bus == nil {
+		bus = NewEventBus()
+	}
+	return &EventStorage{StorageInterface: storage, Bus: bus, provider: providerName(storage)}
+}
+
+// providerName 解开包装器，返回最内层存储后端所在的包名
+func providerName(storage StorageInterface) string {
+	for wrapped := wrappedStorage(storage); wrapped != nil; wrapped = wrappedStorage(storage) {
+		storage = wrapped
+	}
+	t := reflect.TypeOf(storage)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.PkgPath() == "" {
+		return "unknown"
+	}
+	return pathpkg.Base(t.PkgPath())
+}
+
+// publish 补全后端名称和耗时后发布事件
+func (storage *EventStorage) publish(event Event, start time.Time) {
+	event.Provider = storage.provider
+	event.Duration = Now().Sub(start)
+	storage.Bus.Publish(event)
+}
+
+// Put 上传文件，成功后发布上传事件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *EventStorage) Put(path string, reader io.Reader) (*Object, error) {
+	start, counter := Now(), newEventCounter(reader)
+	object, err := storage.StorageInterface.Put(path, counter.reader)
+	if err == nil {
+		storage.publish(Event{Op: EventPut, Path: objectPath(object, path), Size: counter.size(object), Object: object}, start)
+	}
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件，成功后发布上传事件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *EventStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	start, counter := Now(), newEventCounter(reader)
+	object, err := storage.StorageInterface.PutWithOptions(path, counter.reader, opts)
+	if err == nil {
+		storage.publish(Event{Op: EventPut, Path: objectPath(object, path), Size: counter.size(object), Object: object}, start)
+	}
+	return object, err
+}
+
+// NewWriter 创建流式写入器，关闭成功后发布上传事件，耗时从创建写入器开始计算
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *EventStorage) NewWriter(path string) (io.WriteCloser, error) {
+	start := Now()
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &eventWriter{WriteCloser: writer, storage: storage, path: path, start: start}, nil
+}
+
+// Delete 删除文件，成功后发布删除事件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *EventStorage) Delete(path string) error {
+	start := Now()
+	err := storage.StorageInterface.Delete(path)
+	if err == nil {
+		storage.publish(Event{Op: EventDelete, Path: path}, start)
+	}
+	return err
+}
+
+// DeleteObjects 批量删除对象，为每个删除成功的对象发布删除事件
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *EventStorage) DeleteObjects(paths []string) error {
+	start := Now()
+	err := storage.StorageInterface.DeleteObjects(paths)
+	var deleteErr *DeleteObjectsError
+	if err != nil && !errors.As(err, &deleteErr) {
+		return err
+	}
+	for _, path := range paths {
+		if deleteErr != nil && deleteErr.Errors[path] != nil {
+			continue
+		}
+		storage.publish(Event{Op: EventDelete, Path: path}, start)
+	}
+	return err
+}
+
+// DeleteDir 删除目录，成功后发布删除目录事件
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *EventStorage) DeleteDir(dir string) error {
+	start := Now()
+	err := storage.StorageInterface.DeleteDir(dir)
+	if err == nil {
+		storage.publish(Event{Op: EventDeleteDir, Path: dir}, start)
+	}
+	return err
+}
+
+// Copy 复制文件，成功后发布复制事件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *EventStorage) Copy(srcPath, dstPath string) error {
+	start := Now()
+	err := storage.StorageInterface.Copy(srcPath, dstPath)
+	if err == nil {
+		storage.publish(Event{Op: EventCopy, Path: dstPath, Source: srcPath}, start)
+	}
+	return err
+}
+
+// Move 移动文件，成功后发布移动事件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *EventStorage) Move(srcPath, dstPath string) error {
+	start := Now()
+	err := storage.StorageInterface.Move(srcPath, dstPath)
+	if err == nil {
+		storage.publish(Event{Op: EventMove, Path: dstPath, Source: srcPath}, start)
+	}
+	return err
+}
+
+// eventCounter 获取上传的字节数
+// 读取器的大小已知时直接使用，不包装读取器，保留后端依赖的 io.Seeker 等接口；否则统计读取的字节数
+type eventCounter struct {
+	reader io.Reader
+	known  int64
+	n      int64
+}
+
+// newEventCounter 创建上传字节数的计数器
+func newEventCounter(reader io.Reader) *eventCounter {
+	counter := &eventCounter{reader: reader, known: ReaderSize(reader)}
+	if counter.known < 0 {
+		counter.reader = &countingReader{Reader: reader, n: &counter.n}
+	}
+	return counter
+}
+
+// size 优先使用后端返回的对象大小
+func (counter *eventCounter) size(object *Object) int64 {
+	switch {
+	case object != nil && object.Size > 0:
+		return object.Size
+	case counter.known >= 0:
+		return counter.known
+	default:
+		return counter.n
+	}
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	io.Reader
+	n *int64
+}
+
+// Read 读取内容并计数
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	*reader.n += int64(n)
+	return n, err
+}
+
+// eventWriter 统计写入的字节数，关闭成功后发布上传事件
+type eventWriter struct {
+	io.WriteCloser
+	storage *EventStorage
+	path    string
+	start   time.Time
+	n       int64
+}
+
+// Write 写入内容并计数
+func (writer *eventWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.n += int64(n)
+	return n, err
+}
+
+// Close 关闭写入器，成功时发布上传事件
+func (writer *eventWriter) Close() error {
+	if err := writer.WriteCloser.Close(); err != nil {
+		return err
+	}
+	writer.storage.publish(Event{Op: EventPut, Path: writer.path, Size: writer.n}, writer.start)
+	return nil
+}
@@ -0,0 +1,58 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestEvents(t *testing.T) {
+	mock := ossmock.New()
+	bus := oss.NewEventBus()
+	storage := oss.WithEvents(oss.WithTimeout(mock, time.Minute), bus)
+
+	var puts, deletes, copies, all []oss.Event
+	bus.OnPut(func(event oss.Event) { puts = append(puts, event) })
+	bus.OnDelete(func(event oss.Event) { deletes = append(deletes, event) })
+	bus.OnCopy(func(event oss.Event) { copies = append(copies, event) })
+	unsubscribe := bus.Subscribe(func(event oss.Event) { all = append(all, event) })
+
+	storage.Put("/a.txt", strings.NewReader("hello"))
+	storage.PutWithOptions("/b.txt", io.MultiReader(strings.NewReader("streamed")), &oss.PutOptions{})
+	writer, _ := storage.NewWriter("/c.txt")
+	io.WriteString(writer, "abc")
+	writer.Close()
+	if len(puts) != 3 || puts[0].Path != "/a.txt" || puts[0].Size != 5 || puts[1].Size != 8 || puts[2].Size != 3 {
+		t.Errorf("Put events should carry path and size, but got %+v", puts)
+	}
+	if puts[0].Provider != "ossmock" || puts[0].Duration < 0 {
+		t.Errorf("Provider should be the innermost backend, but got %v", puts[0].Provider)
+	}
+
+	storage.Copy("/a.txt", "/d.txt")
+	storage.Move("/d.txt", "/e.txt")
+	if len(copies) != 2 || copies[0].Op != oss.EventCopy || copies[1].Op != oss.EventMove || copies[1].Source != "/d.txt" {
+		t.Errorf("Copy events should carry source and destination, but got %+v", copies)
+	}
+
+	unsubscribe()
+	storage.Delete("/a.txt")
+	mock.FailWith("DeleteObjects", oss.NewDeleteObjectsError(map[string]error{"/c.txt": errors.New("denied")}))
+	storage.DeleteObjects([]string{"/b.txt", "/c.txt"})
+	mock.FailWith("DeleteObjects", nil)
+	storage.DeleteDir("/dir")
+	if len(deletes) != 3 || deletes[1].Path != "/b.txt" || deletes[2].Op != oss.EventDeleteDir {
+		t.Errorf("Delete events should skip failed objects, but got %+v", deletes)
+	}
+	if err := storage.Delete("/missing.txt"); err == nil || len(deletes) != 3 {
+		t.Errorf("Failed operations should not publish events")
+	}
+	if len(all) != 5 {
+		t.Errorf("Unsubscribed listener should not receive events, but got %v", len(all))
+	}
+}
@@ -0,0 +1,69 @@
+// mirror-nas 将存储中的一个目录镜像到NAS
+//
+// 默认从临时目录下的本地文件系统镜像到另一个本地目录，
+// 将 destination 替换为 synology.New 或 synology.GatewayClient 即可镜像到群晖NAS:
+//
+//	go run ./examples/mirror-nas -src /tmp/oss-examples -dst /tmp/oss-nas -prefix /uploads
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// mirror 复制目标中不存在或大小、修改时间不一致的对象
+// 参数:
+//   - source: 源存储
+//   - destination: 目标存储
+//   - prefix: 需要镜像的目录
+// 返回:
+//   - []oss.BatchResult: 每个需要复制的对象的结果
+//   - error: 错误信息
+func mirror(source, destination oss.StorageInterface, prefix string) ([]oss.BatchResult, error) {
+	objects, err := source.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, object := range objects {
+		if target, err := destination.Stat(object.Path); err == nil && target.Size == object.Size &&
+			target.LastModified != nil && object.LastModified != nil && !target.LastModified.Before(*object.LastModified) {
+			continue
+		}
+		paths = append(paths, object.Path)
+	}
+	return oss.Migrate(source, destination, paths, 0), nil
+}
+
+func main() {
+	var (
+		src    = flag.String("src", filepath.Join(os.TempDir(), "oss-examples"), "源文件系统存储的根目录")
+		dst    = flag.String("dst", filepath.Join(os.TempDir(), "oss-nas"), "目标文件系统存储的根目录")
+		prefix = flag.String("prefix", "/", "需要镜像的目录")
+	)
+	flag.Parse()
+
+	results, err := mirror(filesystem.New(*src), filesystem.New(*dst), *prefix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Printf("failed %s: %v\n", result.Path, result.Err)
+			continue
+		}
+		fmt.Printf("copied %s (%d bytes in %v)\n", result.Path, result.Bytes, result.Duration)
+	}
+	failed := len(oss.BatchFailed(results))
+	fmt.Printf("mirrored %d objects from %s to %s, %d failed\n", len(results)-failed, *src, *dst, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,116 @@
+// presigned-upload 浏览器直传示例
+//
+// 服务端只负责通过 GetUploadURL 签发上传地址，浏览器直接将文件上传到存储，
+// 本地文件系统等不支持直传的后端退化为由本服务代理上传:
+//
+//	go run ./examples/presigned-upload -addr :8080
+//	open http://localhost:8080/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// page 上传页面，按签发的方法使用PUT或表单POST上传
+const page = `<!DOCTYPE html>
+<html>
+<body>
+<input type="file" id="file">
+<button onclick="upload()">Upload</button>
+<pre id="result"></pre>
+<script>
+async function upload() {
+  const file = document.getElementById("file").files[0];
+  const response = await fetch("/upload-url?path=" + encodeURIComponent("/uploads/" + file.name) + "&content_type=" + encodeURIComponent(file.type));
+  const upload = await response.json();
+
+  let result;
+  if (upload.method === "POST") {
+    const form = new FormData();
+    for (const [key, value] of Object.entries(upload.form_fields || {})) form.append(key, value);
+    form.append("file", file);
+    result = await fetch(upload.url, {method: "POST", body: form});
+  } else {
+    result = await fetch(upload.url, {method: upload.method, headers: upload.headers || {}, body: file});
+  }
+  document.getElementById("result").textContent = result.status + " " + upload.url;
+}
+</script>
+</body>
+</html>`
+
+// server 签发上传地址的服务
+type server struct {
+	storage oss.StorageInterface
+}
+
+// uploadURL 返回浏览器使用的上传地址
+func (server server) uploadURL(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	upload, err := server.storage.GetUploadURL(path, oss.UploadURLOptions{
+		Expiry:      10 * time.Minute,
+		ContentType: r.URL.Query().Get("content_type"),
+	})
+
+	// 后端不支持直传时由本服务代理上传
+	if errors.Is(err, oss.ErrNotSupported) {
+		upload, err = &oss.UploadURL{URL: "/upload?path=" + url.QueryEscape(path), Method: http.MethodPut}, nil
+	}
+	if err != nil {
+		http.Error(w, err.Error(), oss.HTTPStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"url":         upload.URL,
+		"method":      upload.Method,
+		"headers":     upload.Headers,
+		"form_fields": upload.FormFields,
+	})
+}
+
+// upload 代理上传，只用于不支持直传的后端
+func (server server) upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	object, err := server.storage.Put(r.URL.Query().Get("path"), r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), oss.HTTPStatus(err))
+		return
+	}
+	log.Printf("uploaded %s", object.Path)
+}
+
+func main() {
+	var (
+		root = flag.String("root", filepath.Join(os.TempDir(), "oss-examples"), "本地文件系统存储的根目录")
+		addr = flag.String("addr", ":8080", "HTTP监听地址")
+	)
+	flag.Parse()
+
+	server := server{storage: filesystem.New(*root)}
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+	http.HandleFunc("/upload-url", server.uploadURL)
+	http.HandleFunc("/upload", server.upload)
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
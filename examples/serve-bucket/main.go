@@ -0,0 +1,33 @@
+// serve-bucket 通过HTTP提供存储中的文件下载和目录索引
+//
+// 默认读取临时目录下的本地文件系统存储:
+//
+//	go run ./examples/serve-bucket -addr :8080
+//	curl http://localhost:8080/uploads/sample.txt
+//	curl http://localhost:8080/uploads/?format=json
+//
+// 在浏览器中打开 http://localhost:8080/uploads/ 浏览目录
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/httpserver"
+)
+
+func main() {
+	var (
+		root = flag.String("root", filepath.Join(os.TempDir(), "oss-examples"), "本地文件系统存储的根目录")
+		addr = flag.String("addr", ":8080", "HTTP监听地址")
+	)
+	flag.Parse()
+
+	log.Printf("serving %s on %s", *root, *addr)
+	handler := httpserver.New(filesystem.New(*root), httpserver.Options{Index: true})
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
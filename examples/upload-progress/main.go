@@ -0,0 +1,76 @@
+// upload-progress 上传本地文件并打印上传进度
+//
+// 默认上传到临时目录下的本地文件系统存储，替换 storage 即可上传到任意云存储:
+//
+//	go run ./examples/upload-progress -file ./tests/sample.txt -path /uploads/sample.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// progressReader 统计已读取的字节数并打印进度
+type progressReader struct {
+	reader io.Reader
+	total  int64
+	read   int64
+}
+
+// Read 读取数据并打印进度
+func (progress *progressReader) Read(p []byte) (int, error) {
+	n, err := progress.reader.Read(p)
+	if n > 0 {
+		progress.read += int64(n)
+		if progress.total > 0 {
+			fmt.Printf("\ruploaded %d/%d bytes (%.0f%%)", progress.read, progress.total, float64(progress.read)*100/float64(progress.total))
+		} else {
+			fmt.Printf("\ruploaded %d bytes", progress.read)
+		}
+	}
+	if err == io.EOF {
+		fmt.Println()
+	}
+	return n, err
+}
+
+func main() {
+	var (
+		root = flag.String("root", filepath.Join(os.TempDir(), "oss-examples"), "本地文件系统存储的根目录")
+		file = flag.String("file", "", "需要上传的本地文件")
+		path = flag.String("path", "", "上传到存储的路径，默认为 /uploads/<文件名>")
+	)
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	if *path == "" {
+		*path = "/uploads/" + filepath.Base(*file)
+	}
+
+	storage := filesystem.New(*root)
+
+	source, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	object, err := storage.Put(*path, &progressReader{reader: source, total: info.Size()})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("saved %s to %s\n", object.Path, filepath.Join(*root, object.Path))
+}
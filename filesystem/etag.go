@@ -0,0 +1,69 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/smart-unicom/oss"
+)
+
+// etagXattr 保存ETag记录的扩展属性名
+const etagXattr = "user.oss.etag"
+
+// XattrETagStore 将ETag记录保存在文件扩展属性中的存储
+// 记录随文件重命名，不会出现在目录中；只支持Linux，且文件系统需要启用用户扩展属性，其它平台返回 oss.ErrNotSupported
+type XattrETagStore struct {
+	// FileSystem 文件所在的文件系统存储
+	FileSystem *FileSystem
+}
+
+// LoadETag 读取文件扩展属性中的ETag记录
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.ETagRecord: ETag记录，没有记录时返回nil
+//   - error: 错误信息
+func (store *XattrETagStore) LoadETag(path string) (*oss.ETagRecord, error) {
+	data, err := getXattr(store.FileSystem.GetFullPath(path), etagXattr)
+	if errors.Is(err, errNoXattr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var record oss.ETagRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("decode ETag record of %s: %w", path, err)
+	}
+	return &record, nil
+}
+
+// SaveETag 将ETag记录写入文件扩展属性
+// 参数:
+//   - path: 文件路径
+//   - record: ETag记录
+// 返回:
+//   - error: 错误信息
+func (store *XattrETagStore) SaveETag(path string, record *oss.ETagRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return wrapError(setXattr(store.FileSystem.GetFullPath(path), etagXattr, data))
+}
+
+// DeleteETag 删除文件扩展属性中的ETag记录
+// 文件删除后扩展属性随之删除，文件或记录不存在时不返回错误
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (store *XattrETagStore) DeleteETag(path string) error {
+	err := wrapError(removeXattr(store.FileSystem.GetFullPath(path), etagXattr))
+	if err == nil || errors.Is(err, errNoXattr) || errors.Is(err, oss.ErrObjectNotFound) {
+		return nil
+	}
+	return err
+}
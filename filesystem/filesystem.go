@@ -3,8 +3,13 @@
 package filesystem
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/fs"
+	"mime"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,8 +22,26 @@ import (
 type FileSystem struct {
 	// Base 基础目录路径
 	Base string
+	// SymlinkPolicy List时符号链接的处理策略，默认将链接本身作为对象返回
+	SymlinkPolicy SymlinkPolicy
+	// ReportLinkTarget 是否在List结果中返回符号链接的目标路径
+	ReportLinkTarget bool
+	// SkipDuplicateHardlinks 是否在List结果中只保留同一文件的第一个硬链接
+	SkipDuplicateHardlinks bool
+	// UnsortedList 是否关闭List结果的排序，关闭后按目录遍历顺序返回
+	UnsortedList bool
+	// OnListError List遇到无法访问的文件、失效链接或循环链接时的回调
+	// 返回非nil错误将终止List，未设置时忽略这些条目
+	OnListError func(path string, err error) error
 }
 
+const (
+	// maxPathBytes 完整路径的最大字节数
+	maxPathBytes = 4096
+	// maxNameBytes 文件名的最大字节数
+	maxNameBytes = 255
+)
+
 // New 初始化文件系统存储客户端
 // 参数:
 //   - base: 基础目录路径
@@ -54,7 +77,11 @@ func (fileSystem FileSystem) GetFullPath(path string) string {
 //   - *os.File: 文件对象
 //   - error: 错误信息
 func (fileSystem FileSystem) Get(path string) (*os.File, error) {
-	return os.Open(fileSystem.GetFullPath(path))
+	file, err := os.Open(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return file, nil
 }
 
 // GetStream 获取指定路径文件的流
@@ -64,7 +91,84 @@ func (fileSystem FileSystem) Get(path string) (*os.File, error) {
 //   - io.ReadCloser: 可读流
 //   - error: 错误信息
 func (fileSystem FileSystem) GetStream(path string) (io.ReadCloser, error) {
-	return os.Open(fileSystem.GetFullPath(path))
+	file, err := os.Open(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return file, nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 定位到偏移量后读取，不读取范围外的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (fileSystem FileSystem) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if length <= 0 {
+		return file, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (fileSystem FileSystem) Stat(path string) (*oss.Object, error) {
+	info, err := os.Stat(fileSystem.GetFullPath(path))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	// 目录不是对象
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	return &oss.Object{
+		Path:             path,
+		Name:             info.Name(),
+		LastModified:     oss.NormalizeTime(info.ModTime()),
+		Size:             info.Size(),
+		ContentType:      mime.TypeByExtension(filepath.Ext(path)),
+		StorageInterface: fileSystem,
+	}, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (fileSystem FileSystem) Exists(path string) (bool, error) {
+	_, err := fileSystem.Stat(path)
+	if errors.Is(err, oss.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // Put 上传文件到指定路径
@@ -75,29 +179,169 @@ func (fileSystem FileSystem) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传后的对象信息
 //   - error: 错误信息
 func (fileSystem FileSystem) Put(path string, reader io.Reader) (*oss.Object, error) {
-	var (
-		fullpath = fileSystem.GetFullPath(path)
-		// 创建目录结构
-		err = os.MkdirAll(filepath.Dir(fullpath), os.ModePerm)
-	)
+	return fileSystem.PutWithOptions(path, reader, nil)
+}
 
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (fileSystem FileSystem) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	path, err := oss.NormalizePath(path)
 	if err != nil {
 		return nil, err
 	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+
+	// 本地文件系统不支持对象级ACL
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: filesystem does not support per-object ACL", oss.ErrNotSupported)
+	}
+	// 本地文件系统不支持对象标签
+	if len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("%w: filesystem does not support object tags", oss.ErrNotSupported)
+	}
+
+	// 按冲突策略确定上传路径，CollisionError在创建文件时使用O_EXCL
+	if opts.Collision != oss.CollisionError {
+		resolved, err := opts.ResolvePath(fileSystem, path)
+		if err != nil {
+			return nil, err
+		}
+		path = resolved
+	}
+
+	fullpath := fileSystem.GetFullPath(path)
+	// 在写入前校验路径长度
+	if err = oss.ValidateKeyLength(filepath.ToSlash(fullpath), maxPathBytes, maxNameBytes); err != nil {
+		return nil, err
+	}
+
+	// 写入时计算校验和，与调用方提供的值比较
+	var checksum hash.Hash
+	if opts.Checksum != "" {
+		if checksum, err = oss.NewChecksumHash(opts.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	// 创建目录结构
+	if err = os.MkdirAll(filepath.Dir(fullpath), os.ModePerm); err != nil {
+		return nil, err
+	}
 
 	// 创建目标文件
-	dst, err := os.Create(fullpath)
+	flag := os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	if opts.Collision == oss.CollisionError {
+		flag = os.O_RDWR | os.O_CREATE | os.O_EXCL
+	}
+	dst, err := os.OpenFile(fullpath, flag, 0666)
+	if errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("%w: %s already exists", oss.ErrConflict, path)
+	}
 
 	if err == nil {
 		// 如果是可寻址的读取器，重置到开始位置
 		if seeker, ok := reader.(io.ReadSeeker); ok {
 			seeker.Seek(0, 0)
 		}
+		reader = opts.WrapReader(reader, oss.ReaderSize(reader))
+		if checksum != nil {
+			reader = io.TeeReader(reader, checksum)
+		}
 		// 复制内容到目标文件
 		_, err = io.Copy(dst, reader)
+		dst.Close()
+	}
+
+	if err == nil && checksum != nil && opts.ChecksumValue != "" {
+		// 内容损坏时删除已写入的文件
+		if actual := base64.StdEncoding.EncodeToString(checksum.Sum(nil)); actual != opts.ChecksumValue {
+			os.Remove(fullpath)
+			return nil, fmt.Errorf("%w: %s %s, expected %s", oss.ErrChecksumMismatch, opts.Checksum, actual, opts.ChecksumValue)
+		}
+	}
+
+	object := &oss.Object{Path: path, Name: filepath.Base(path), StorageInterface: fileSystem}
+	if err == nil {
+		if info, err := os.Stat(fullpath); err == nil {
+			object.LastModified = oss.NormalizeTime(info.ModTime())
+			object.Size = info.Size()
+		}
+	}
+
+	return object, err
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 先写入同目录下的临时文件，Close 时重命名为目标文件
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回写入结果
+//   - error: 错误信息
+func (fileSystem FileSystem) NewWriter(path string) (io.WriteCloser, error) {
+	// 与 PutWithOptions 相同，规范化来自Windows的路径
+	path, err := oss.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	fullpath := fileSystem.GetFullPath(path)
+	if err := oss.ValidateKeyLength(filepath.ToSlash(fullpath), maxPathBytes, maxNameBytes); err != nil {
+		return nil, err
 	}
 
-	return &oss.Object{Path: path, Name: filepath.Base(path), StorageInterface: fileSystem}, err
+	if err := os.MkdirAll(filepath.Dir(fullpath), os.ModePerm); err != nil {
+		return nil, wrapError(err)
+	}
+
+	file, err := createTemp(fullpath)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &fileWriter{File: file, fullpath: fullpath}, nil
+}
+
+// createTemp 在目标文件所在目录创建临时文件
+// os.CreateTemp 创建的文件权限为0600，这里与 Put 一样使用受 umask 限制的0666，重命名后的文件可以被其他用户读取
+func createTemp(fullpath string) (*os.File, error) {
+	for try := 0; ; try++ {
+		random, err := oss.RandomHex(5)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Join(filepath.Dir(fullpath), "."+filepath.Base(fullpath)+"."+random+".tmp")
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// fileWriter 写入临时文件，关闭时重命名为目标文件
+type fileWriter struct {
+	*os.File
+	fullpath string
+}
+
+// Close 关闭临时文件并重命名为目标文件，失败时删除临时文件
+func (writer *fileWriter) Close() error {
+	err := writer.File.Close()
+	if err == nil {
+		err = os.Rename(writer.File.Name(), writer.fullpath)
+	}
+	if err != nil {
+		os.Remove(writer.File.Name())
+	}
+	return wrapError(err)
 }
 
 // Delete 删除指定路径的文件
@@ -106,7 +350,82 @@ func (fileSystem FileSystem) Put(path string, reader io.Reader) (*oss.Object, er
 // 返回:
 //   - error: 错误信息
 func (fileSystem FileSystem) Delete(path string) error {
-	return os.Remove(fileSystem.GetFullPath(path))
+	return wrapError(os.Remove(fileSystem.GetFullPath(path)))
+}
+
+// DeleteObjects 批量删除多个文件
+// 并发逐个删除本地文件
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (fileSystem FileSystem) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsConcurrently(fileSystem, paths, oss.DefaultDeleteConcurrency)
+}
+
+// DeleteDir 删除目录及其下的全部文件
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (fileSystem FileSystem) DeleteDir(dir string) error {
+	if oss.DirPrefix(dir) == "" {
+		return oss.ErrDeleteRoot
+	}
+	return wrapError(os.RemoveAll(fileSystem.GetFullPath(dir)))
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (fileSystem FileSystem) Copy(srcPath, dstPath string) error {
+	// 源和目标为同一文件时无需复制
+	if fileSystem.GetFullPath(srcPath) == fileSystem.GetFullPath(dstPath) {
+		return nil
+	}
+
+	// 本地文件直接通过流复制
+	return oss.CopyByStream(fileSystem, srcPath, dstPath)
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (fileSystem FileSystem) Move(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	var (
+		src = fileSystem.GetFullPath(srcPath)
+		dst = fileSystem.GetFullPath(dstPath)
+	)
+
+	// 在写入前校验路径长度
+	if err := oss.ValidateKeyLength(filepath.ToSlash(dst), maxPathBytes, maxNameBytes); err != nil {
+		return err
+	}
+
+	// 创建目标目录结构
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	// 跨设备等无法直接重命名的情况，退回到复制后删除
+	if err := os.Rename(src, dst); err != nil {
+		if _, statErr := os.Stat(src); statErr != nil {
+			return wrapError(err)
+		}
+		return oss.MoveByCopy(fileSystem, srcPath, dstPath)
+	}
+	return nil
 }
 
 // List 列出指定路径下的所有对象
@@ -117,31 +436,36 @@ func (fileSystem FileSystem) Delete(path string) error {
 //   - error: 错误信息
 func (fileSystem FileSystem) List(path string) ([]*oss.Object, error) {
 	var (
-		objects  []*oss.Object
 		fullpath = fileSystem.GetFullPath(path)
+		walker   = &listWalker{fileSystem: fileSystem}
 	)
 
-	// 遍历目录下的所有文件
-	filepath.Walk(fullpath, func(path string, info os.FileInfo, err error) error {
-		// 跳过根目录本身
-		if path == fullpath {
-			return nil
-		}
+	// 只有目录才需要遍历
+	info, err := os.Stat(fullpath)
+	if err != nil {
+		return nil, walker.handle(fullpath, err)
+	}
+	if !info.IsDir() {
+		return nil, nil
+	}
 
-		// 只处理文件，不处理目录
-		if err == nil && !info.IsDir() {
-			modTime := info.ModTime()
-			objects = append(objects, &oss.Object{
-				Path:             strings.TrimPrefix(path, fileSystem.Base),
-				Name:             info.Name(),
-				LastModified:     &modTime,
-				StorageInterface: fileSystem,
-			})
+	var ancestors []string
+	if fileSystem.SymlinkPolicy == SymlinkFollow {
+		realpath, err := filepath.EvalSymlinks(fullpath)
+		if err != nil {
+			return nil, walker.handle(fullpath, err)
 		}
-		return nil
-	})
+		ancestors = append(ancestors, realpath)
+	}
 
-	return objects, nil
+	// 遍历目录下的所有文件
+	err = walker.walk(fullpath, ancestors)
+
+	// 深度优先遍历的顺序与路径字典序不同，需要重新排序
+	if !fileSystem.UnsortedList {
+		oss.SortObjects(walker.objects)
+	}
+	return walker.objects, err
 }
 
 // GetEndpoint 获取存储服务的端点地址，文件系统的端点是 /
@@ -160,3 +484,47 @@ func (fileSystem FileSystem) GetEndpoint() string {
 func (fileSystem FileSystem) GetURL(path string) (url string, err error) {
 	return path, nil
 }
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (fileSystem FileSystem) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	return "", fmt.Errorf("%w: file system does not support signed URL", oss.ErrNotSupported)
+}
+
+// GetUploadURL 生成客户端直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (fileSystem FileSystem) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	return nil, fmt.Errorf("%w: file system does not support upload URL", oss.ErrNotSupported)
+}
+
+// URLStrategies 返回生成访问URL时依次尝试的方式，本地文件只能通过代理访问
+// 返回:
+//   - []oss.URLStrategy: 生成访问URL的方式
+func (fileSystem FileSystem) URLStrategies() []oss.URLStrategy {
+	return []oss.URLStrategy{oss.URLProxied}
+}
+
+// wrapError 将文件系统的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+// 返回:
+//   - error: 包装后的错误，errors.Is(err, os.ErrNotExist) 仍然成立
+func wrapError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case errors.Is(err, fs.ErrPermission):
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	}
+	return err
+}
@@ -1,12 +1,323 @@
-package filesystem
-
-import (
-	"testing"
-
-	"github.com/smart-unicom/oss/tests"
-)
-
-func TestAll(t *testing.T) {
-	fileSystem := New("/tmp")
-	tests.TestAll(fileSystem, t)
-}
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	fileSystem := New("/tmp")
+	tests.TestAll(fileSystem, t)
+}
+
+func TestListSymlinks(t *testing.T) {
+	base := t.TempDir()
+	os.MkdirAll(filepath.Join(base, "data", "sub"), os.ModePerm)
+	os.WriteFile(filepath.Join(base, "data", "sub", "sample.txt"), []byte("sample"), os.ModePerm)
+	os.Link(filepath.Join(base, "data", "sub", "sample.txt"), filepath.Join(base, "data", "hardlink.txt"))
+	os.Symlink(filepath.Join(base, "data", "sub"), filepath.Join(base, "data", "link"))
+	os.Symlink(filepath.Join(base, "data"), filepath.Join(base, "data", "sub", "loop"))
+	os.Symlink(filepath.Join(base, "missing.txt"), filepath.Join(base, "data", "broken.txt"))
+
+	paths := func(objects []*oss.Object) map[string]string {
+		result := map[string]string{}
+		for _, object := range objects {
+			result[object.Path] = object.LinkTarget
+		}
+		return result
+	}
+
+	fileSystem := New(base)
+	fileSystem.SymlinkPolicy = SymlinkSkip
+	if objects, _ := fileSystem.List("data"); len(objects) != 2 {
+		t.Errorf("Should skip symlinks, but got %v", paths(objects))
+	}
+
+	fileSystem.SymlinkPolicy = SymlinkAsObject
+	fileSystem.ReportLinkTarget = true
+	if objects, _ := fileSystem.List("data"); len(objects) != 5 {
+		t.Errorf("Should report symlinks as objects, but got %v", paths(objects))
+	} else if target := paths(objects)["/data/link"]; target != filepath.Join(base, "data", "sub") {
+		t.Errorf("Should report link target, but got %v", target)
+	}
+
+	var errs []error
+	fileSystem.SymlinkPolicy = SymlinkFollow
+	fileSystem.OnListError = func(path string, err error) error {
+		errs = append(errs, err)
+		return nil
+	}
+	if objects, err := fileSystem.List("data"); err != nil {
+		t.Errorf("No error should happen when list with symlinks followed, but got %v", err)
+	} else if _, ok := paths(objects)["/data/link/sample.txt"]; !ok || len(objects) != 3 {
+		t.Errorf("Should follow symlinks, but got %v", paths(objects))
+	}
+
+	var cycles int
+	for _, err := range errs {
+		if errors.Is(err, ErrSymlinkCycle) {
+			cycles++
+		}
+	}
+	if len(errs) != 3 || cycles != 2 {
+		t.Errorf("Should report broken link and cycles, but got %v", errs)
+	}
+
+	fileSystem.SkipDuplicateHardlinks = true
+	if objects, _ := fileSystem.List("data"); len(objects) != 1 {
+		t.Errorf("Should skip duplicate hardlinks, but got %v", paths(objects))
+	}
+}
+
+func TestListOrder(t *testing.T) {
+	base := t.TempDir()
+	for _, name := range []string{"a/b.txt", "a-c.txt", "B.txt", "a/a.txt"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(base, name)), os.ModePerm)
+		os.WriteFile(filepath.Join(base, name), []byte("sample"), os.ModePerm)
+	}
+
+	fileSystem := New(base)
+	objects, err := fileSystem.List("/")
+	if err != nil {
+		t.Fatalf("No error should happen when list objects, but got %v", err)
+	}
+
+	var paths []string
+	for _, object := range objects {
+		paths = append(paths, object.Path)
+	}
+	if expected := "/B.txt,/a-c.txt,/a/a.txt,/a/b.txt"; strings.Join(paths, ",") != expected {
+		t.Errorf("List should return objects in lexicographic order %v, but got %v", expected, paths)
+	}
+
+	fileSystem.UnsortedList = true
+	if objects, _ := fileSystem.List("/"); len(objects) != 4 {
+		t.Errorf("Should found 4 objects when list without sorting, but got %v", len(objects))
+	}
+}
+
+func TestPutWithOptions(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	if _, err := fileSystem.PutWithOptions("/a.txt", strings.NewReader("sample"), &oss.PutOptions{ACL: oss.ACLPublicRead}); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Should return ErrNotSupported when save file with ACL, but got %v", err)
+	}
+
+	if exists, _ := fileSystem.Exists("/a.txt"); exists {
+		t.Errorf("File should not be created when options are not supported")
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	if _, err := fileSystem.Get("/missing.txt"); oss.HTTPStatus(err) != http.StatusNotFound {
+		t.Errorf("Should map missing file to 404, but got %v for %v", oss.HTTPStatus(err), err)
+	}
+
+	if _, err := fileSystem.Put("/"+strings.Repeat("a", 300), strings.NewReader("sample")); oss.HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("Should map too long key to 400, but got %v for %v", oss.HTTPStatus(err), err)
+	}
+
+	if _, err := fileSystem.GetSignedURL("/a.txt", oss.SignedURLOptions{}); oss.HTTPStatus(err) != http.StatusNotImplemented {
+		t.Errorf("Should map unsupported operation to 501, but got %v for %v", oss.HTTPStatus(err), err)
+	}
+
+	if status := oss.HTTPStatus(fmt.Errorf("upload failed: %w", oss.ErrRateLimited)); status != http.StatusTooManyRequests {
+		t.Errorf("Should map wrapped rate limit error to 429, but got %v", status)
+	}
+
+	if status := oss.HTTPStatus(errors.New("unknown")); status != http.StatusInternalServerError {
+		t.Errorf("Should map unknown error to 500, but got %v", status)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	_, err := fileSystem.Get("/missing.txt")
+	if !errors.Is(err, oss.ErrObjectNotFound) || !errors.Is(err, oss.ErrNotFound) || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Missing file should match ErrObjectNotFound, ErrNotFound and os.ErrNotExist, but got %v", err)
+	}
+
+	if errors.Is(err, oss.ErrBucketNotFound) {
+		t.Errorf("Missing file should not match ErrBucketNotFound")
+	}
+
+	if err := fileSystem.Delete("/missing.txt"); !errors.Is(err, oss.ErrObjectNotFound) {
+		t.Errorf("Delete missing file should return ErrObjectNotFound, but got %v", err)
+	}
+
+	if !errors.Is(oss.ErrAccessDenied, oss.ErrPermissionDenied) {
+		t.Errorf("ErrAccessDenied should match ErrPermissionDenied")
+	}
+
+	if err := oss.WrapError(oss.ErrAccessDenied, nil); err != nil {
+		t.Errorf("WrapError should keep nil error, but got %v", err)
+	}
+}
+
+func TestDeleteObjects(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	for _, path := range []string{"/a.txt", "/dir/b.txt"} {
+		if _, err := fileSystem.Put(path, strings.NewReader("sample")); err != nil {
+			t.Fatalf("No error should happen when save file, but got %v", err)
+		}
+	}
+
+	// 非空目录无法删除，其余文件应当删除成功
+	err := fileSystem.DeleteObjects([]string{"/a.txt", "/dir", "/missing.txt"})
+
+	var deleteErr *oss.DeleteObjectsError
+	if !errors.As(err, &deleteErr) {
+		t.Fatalf("Should return DeleteObjectsError, but got %v", err)
+	}
+
+	if paths := deleteErr.Paths(); len(paths) != 1 || paths[0] != "/dir" {
+		t.Errorf("Should report failed path /dir, but got %v", paths)
+	}
+
+	if exists, _ := fileSystem.Exists("/a.txt"); exists {
+		t.Errorf("File should be deleted even if other objects failed")
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	fileSystem := New(t.TempDir())
+
+	writer, err := fileSystem.NewWriter("/reports/a.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when create writer, but got %v", err)
+	}
+	io.WriteString(writer, "sample")
+
+	if exists, _ := fileSystem.Exists("/reports/a.txt"); exists {
+		t.Errorf("Written file should not be visible before close")
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("No error should happen when close writer, but got %v", err)
+	}
+
+	if objects, _ := fileSystem.List("/reports"); len(objects) != 1 {
+		t.Errorf("Should only leave the written file, but got %v objects", len(objects))
+	}
+
+	// 与 Put 写入的文件权限相同
+	fileSystem.Put("/reports/b.txt", strings.NewReader("sample"))
+	written, _ := os.Stat(fileSystem.GetFullPath("/reports/a.txt"))
+	put, _ := os.Stat(fileSystem.GetFullPath("/reports/b.txt"))
+	if written.Mode() != put.Mode() {
+		t.Errorf("Written file should have the same mode as put, expected %v but got %v", put.Mode(), written.Mode())
+	}
+}
+
+func TestAllSubStorage(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	fileSystem.Put("/projects/a/readme.txt", strings.NewReader("readme"))
+	tests.TestAll(oss.NewPrefixedStorage(fileSystem, "/projects/a"), t)
+}
+
+func TestAllWithTimeout(t *testing.T) {
+	tests.TestAll(oss.WithTimeout(New(t.TempDir()), time.Minute), t)
+}
+
+func TestChecksum(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	checksum, _ := oss.ComputeChecksum(oss.ChecksumMD5, []byte("sample"))
+
+	if _, err := fileSystem.PutWithOptions("/a.txt", strings.NewReader("sample"), &oss.PutOptions{Checksum: oss.ChecksumMD5, ChecksumValue: checksum}); err != nil {
+		t.Errorf("Put with matched checksum should succeed, but got %v", err)
+	}
+
+	_, err := fileSystem.PutWithOptions("/b.txt", strings.NewReader("sample2"), &oss.PutOptions{Checksum: oss.ChecksumMD5, ChecksumValue: checksum})
+	if !errors.Is(err, oss.ErrChecksumMismatch) || oss.HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("Put with mismatched checksum should return ErrChecksumMismatch, but got %v", err)
+	}
+	if exists, _ := fileSystem.Exists("/b.txt"); exists {
+		t.Errorf("File with mismatched checksum should be removed")
+	}
+
+	stream, err := oss.GetStreamVerified(fileSystem, "/a.txt", oss.ChecksumMD5, checksum)
+	if err != nil {
+		t.Fatalf("No error should happen when get verified stream, but got %v", err)
+	}
+	if content, err := io.ReadAll(stream); err != nil || string(content) != "sample" {
+		t.Errorf("Verified stream should read content, but got %v, %v", string(content), err)
+	}
+	stream.Close()
+
+	sha256Checksum, _ := oss.ComputeChecksum(oss.ChecksumSHA256, []byte("sample"))
+	stream, _ = oss.GetStreamVerified(fileSystem, "/a.txt", oss.ChecksumSHA256, sha256Checksum+"x")
+	if _, err := io.ReadAll(stream); !errors.Is(err, oss.ErrChecksumMismatch) {
+		t.Errorf("Verified stream with wrong checksum should return ErrChecksumMismatch, but got %v", err)
+	}
+	stream.Close()
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	storage, err := oss.Open("file://" + filepath.ToSlash(dir))
+	if err != nil {
+		t.Fatalf("No error should happen when open file dsn, but got %v", err)
+	}
+	if _, err := storage.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("No error should happen when put through opened storage, but got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); err != nil {
+		t.Errorf("File should be saved under dsn path, but got %v", err)
+	}
+
+	if _, err := oss.Open("file://" + filepath.ToSlash(dir) + "?acl=private"); err == nil {
+		t.Errorf("Unknown dsn parameter should be rejected")
+	}
+	if _, err := oss.Open("unknown://bucket"); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Unregistered scheme should return ErrNotSupported, but got %v", err)
+	}
+	if _, err := oss.Open("/data"); err == nil {
+		t.Errorf("Dsn without scheme should be rejected")
+	}
+}
+
+func TestPutWindowsPath(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	object, err := fileSystem.Put(`C:\docs\a.txt`, strings.NewReader("sample"))
+	if err != nil {
+		t.Fatalf("No error should happen when put windows path, but got %v", err)
+	}
+	if object.Path != "/docs/a.txt" {
+		t.Errorf("Windows path should be saved as /docs/a.txt, but got %v", object.Path)
+	}
+	if _, err := fileSystem.Put("/docs/NUL", strings.NewReader("sample")); !errors.Is(err, oss.ErrInvalidPath) {
+		t.Errorf("Reserved device name should be rejected before writing, but got %v", err)
+	}
+}
+
+func TestXattrETagStore(t *testing.T) {
+	fileSystem := New(t.TempDir())
+	const etag = `"5e8ff9bf55ba3508199d22e984129be6"`
+
+	xattrs := oss.WithETags(fileSystem, &XattrETagStore{FileSystem: fileSystem})
+	if _, err := xattrs.Put("/x.txt", strings.NewReader("sample")); errors.Is(err, oss.ErrNotSupported) {
+		t.Skipf("Extended attributes are not supported: %v", err)
+	} else if err != nil {
+		t.Fatalf("No error should happen when put with xattr store, but got %v", err)
+	}
+	if object, err := xattrs.Stat("/x.txt"); err != nil || object.ETag != etag {
+		t.Errorf("Stat should return ETag saved in xattr, but got %+v, %v", object, err)
+	}
+	if exists, _ := fileSystem.Exists("/.x.txt.etag"); exists {
+		t.Errorf("Xattr store should not create sidecar files")
+	}
+}
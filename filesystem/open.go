@@ -0,0 +1,32 @@
+package filesystem
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/smart-unicom/oss"
+)
+
+func init() {
+	oss.Register("file", open)
+}
+
+// open 根据连接字符串创建本地文件系统存储
+// 格式为 file:///data，路径为存储的根目录
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - oss.StorageInterface: 文件系统存储
+//   - error: 错误信息
+func open(dsn url.URL) (oss.StorageInterface, error) {
+	if dsn.Host != "" && dsn.Host != "localhost" {
+		return nil, fmt.Errorf("filesystem: dsn %s must use an absolute path such as file:///data", dsn.Redacted())
+	}
+	if dsn.Path == "" {
+		return nil, fmt.Errorf("filesystem: dsn %s has no path", dsn.Redacted())
+	}
+	if err := oss.NewDSNParams(dsn).Err(); err != nil {
+		return nil, err
+	}
+	return New(dsn.Path), nil
+}
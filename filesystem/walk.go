@@ -0,0 +1,174 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/smart-unicom/oss"
+)
+
+// SymlinkPolicy 符号链接处理策略
+type SymlinkPolicy int
+
+const (
+	// SymlinkAsObject 将符号链接本身作为对象返回，不跟随链接
+	SymlinkAsObject SymlinkPolicy = iota
+	// SymlinkSkip 跳过所有符号链接
+	SymlinkSkip
+	// SymlinkFollow 跟随符号链接，列出链接目标的内容，并检测循环链接
+	SymlinkFollow
+)
+
+// ErrSymlinkCycle 跟随符号链接时检测到循环
+var ErrSymlinkCycle = errors.New("symlink cycle detected")
+
+// listWalker 目录遍历器
+// 按照文件系统的符号链接策略收集对象
+type listWalker struct {
+	fileSystem FileSystem
+	objects    []*oss.Object
+	// files 按大小分组记录已收集的文件，用于识别硬链接
+	files map[int64][]os.FileInfo
+}
+
+// walk 遍历目录
+// 参数:
+//   - dir: 目录完整路径
+//   - ancestors: 跟随链接时祖先目录的真实路径，用于检测循环
+// 返回:
+//   - error: 错误信息
+func (walker *listWalker) walk(dir string, ancestors []string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return walker.handle(dir, err)
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			if err = walker.handle(path, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			err = walker.visitSymlink(path, info, ancestors)
+		case info.IsDir():
+			err = walker.visitDir(path, ancestors)
+		default:
+			walker.add(path, info, "")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visitDir 进入子目录
+// 参数:
+//   - path: 子目录完整路径
+//   - ancestors: 祖先目录的真实路径
+// 返回:
+//   - error: 错误信息
+func (walker *listWalker) visitDir(path string, ancestors []string) error {
+	if walker.fileSystem.SymlinkPolicy != SymlinkFollow {
+		return walker.walk(path, nil)
+	}
+
+	// 跟随链接时，目录的真实路径可能出现在祖先中
+	realpath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return walker.handle(path, err)
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == realpath {
+			return walker.handle(path, ErrSymlinkCycle)
+		}
+	}
+
+	return walker.walk(path, append(ancestors, realpath))
+}
+
+// visitSymlink 按照策略处理符号链接
+// 参数:
+//   - path: 链接完整路径
+//   - info: 链接本身的文件信息
+//   - ancestors: 祖先目录的真实路径
+// 返回:
+//   - error: 错误信息
+func (walker *listWalker) visitSymlink(path string, info os.FileInfo, ancestors []string) error {
+	var linkTarget string
+	if walker.fileSystem.ReportLinkTarget {
+		linkTarget, _ = os.Readlink(path)
+	}
+
+	switch walker.fileSystem.SymlinkPolicy {
+	case SymlinkSkip:
+		return nil
+	case SymlinkFollow:
+		// 获取链接目标的信息，失效链接会返回错误
+		target, err := os.Stat(path)
+		if err != nil {
+			return walker.handle(path, err)
+		}
+
+		if target.IsDir() {
+			return walker.visitDir(path, ancestors)
+		}
+		walker.add(path, target, linkTarget)
+	default:
+		walker.add(path, info, linkTarget)
+	}
+
+	return nil
+}
+
+// add 添加对象到结果中
+// 参数:
+//   - path: 文件完整路径
+//   - info: 文件信息
+//   - linkTarget: 符号链接的目标路径
+func (walker *listWalker) add(path string, info os.FileInfo, linkTarget string) {
+	// 同一文件的多个硬链接只保留第一个
+	if walker.fileSystem.SkipDuplicateHardlinks {
+		if walker.files == nil {
+			walker.files = map[int64][]os.FileInfo{}
+		}
+		for _, seen := range walker.files[info.Size()] {
+			if os.SameFile(seen, info) {
+				return
+			}
+		}
+		walker.files[info.Size()] = append(walker.files[info.Size()], info)
+	}
+
+	walker.objects = append(walker.objects, &oss.Object{
+		Path:             strings.TrimPrefix(path, walker.fileSystem.Base),
+		Name:             info.Name(),
+		LastModified:     oss.NormalizeTime(info.ModTime()),
+		Size:             info.Size(),
+		LinkTarget:       linkTarget,
+		StorageInterface: walker.fileSystem,
+	})
+}
+
+// handle 处理遍历过程中的错误
+// 参数:
+//   - path: 出错的完整路径
+//   - err: 错误信息
+// 返回:
+//   - error: 回调返回的错误，未设置回调时返回nil
+func (walker *listWalker) handle(path string, err error) error {
+	if walker.fileSystem.OnListError != nil {
+		return walker.fileSystem.OnListError(strings.TrimPrefix(path, walker.fileSystem.Base), err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/smart-unicom/oss"
+)
+
+// errNoXattr 文件没有该扩展属性
+var errNoXattr error = syscall.ENODATA
+
+// getXattr 读取文件的扩展属性
+func getXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err != nil {
+		return nil, xattrError("getxattr", path, err)
+	}
+	data := make([]byte, size)
+	size, err = syscall.Getxattr(path, name, data)
+	if err != nil {
+		return nil, xattrError("getxattr", path, err)
+	}
+	return data[:size], nil
+}
+
+// setXattr 设置文件的扩展属性
+func setXattr(path, name string, data []byte) error {
+	if err := syscall.Setxattr(path, name, data, 0); err != nil {
+		return xattrError("setxattr", path, err)
+	}
+	return nil
+}
+
+// removeXattr 删除文件的扩展属性
+func removeXattr(path, name string) error {
+	if err := syscall.Removexattr(path, name); err != nil {
+		return xattrError("removexattr", path, err)
+	}
+	return nil
+}
+
+// xattrError 包装扩展属性操作的错误，文件系统不支持用户扩展属性时返回 oss.ErrNotSupported
+func xattrError(op, path string, err error) error {
+	if err == syscall.ENOTSUP {
+		return fmt.Errorf("%w: %s does not support user extended attributes", oss.ErrNotSupported, path)
+	}
+	return &os.PathError{Op: op, Path: path, Err: err}
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/smart-unicom/oss"
+)
+
+// errNoXattr 文件没有该扩展属性
+var errNoXattr = errors.New("no such extended attribute")
+
+// getXattr 当前平台不支持扩展属性
+func getXattr(path, name string) ([]byte, error) {
+	return nil, fmt.Errorf("%w: extended attributes are only supported on linux", oss.ErrNotSupported)
+}
+
+// setXattr 当前平台不支持扩展属性
+func setXattr(path, name string, data []byte) error {
+	return fmt.Errorf("%w: extended attributes are only supported on linux", oss.ErrNotSupported)
+}
+
+// removeXattr 当前平台不支持扩展属性
+func removeXattr(path, name string) error {
+	return fmt.Errorf("%w: extended attributes are only supported on linux", oss.ErrNotSupported)
+}
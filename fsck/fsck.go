@@ -0,0 +1,471 @@
+// Package fsck 多存储一致性检查
+// 以一个存储或清单为基准，比较其他存储中缺失、多余和内容不一致的对象，用于校验镜像和迁移的结果
+package fsck
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultConcurrency 默认的并发数
+const DefaultConcurrency = 4
+
+// Strategy 判断对象是否一致的比较策略
+type Strategy int
+
+const (
+	// CompareSize 只比较对象大小
+	CompareSize Strategy = iota
+	// CompareModTime 比较对象大小，并且副本的修改时间不能早于基准
+	CompareModTime
+	// CompareChecksum 比较对象大小和内容的SHA256校验和，需要读取对象内容
+	CompareChecksum
+	// CompareETag 比较对象大小和List返回的MD5 ETag，任一方的ETag不是MD5时退回 CompareChecksum
+	// 本地文件系统和群晖需要使用 oss.WithETags 包装后才会返回ETag
+	CompareETag
+)
+
+// IssueKind 不一致的类型
+type IssueKind string
+
+const (
+	// Missing 基准中存在而副本中不存在
+	Missing IssueKind = "missing"
+	// Extra 副本中存在而基准中不存在
+	Extra IssueKind = "extra"
+	// Divergent 两边都存在但比较结果不一致
+	Divergent IssueKind = "divergent"
+	// Unreadable 计算校验和时读取对象失败
+	Unreadable IssueKind = "unreadable"
+)
+
+// Options 检查选项
+type Options struct {
+	// Prefix 需要检查的目录，为空时检查全部对象
+	Prefix string
+	// Strategy 比较策略
+	Strategy Strategy
+	// Concurrency 列举存储和计算校验和的并发数，小于等于0时使用 DefaultConcurrency
+	Concurrency int
+	// ModTimeTolerance CompareModTime 允许的修改时间误差，用于精度不同的存储
+	ModTimeTolerance time.Duration
+}
+
+// Backend 参与比较的存储
+type Backend struct {
+	// Name 存储名称，用于报告
+	Name string
+	// Storage 存储接口
+	Storage oss.StorageInterface
+}
+
+// Entry 清单中的一个对象
+type Entry struct {
+	// Path 对象路径，以 / 开头
+	Path string `json:"path"`
+	// Size 对象大小（字节）
+	Size int64 `json:"size"`
+	// LastModified 最后修改时间
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	// Checksum 内容的SHA256校验和（十六进制）
+	Checksum string `json:"checksum,omitempty"`
+	// ETag 存储返回的实体标签
+	ETag string `json:"etag,omitempty"`
+}
+
+// Issue 一个不一致的对象
+type Issue struct {
+	// Path 对象路径
+	Path string
+	// Backend 出现问题的副本名称
+	Backend string
+	// Kind 不一致的类型
+	Kind IssueKind
+	// Reason 不一致的原因
+	Reason string
+}
+
+// String 返回便于日志输出的描述
+func (issue *Issue) String() string {
+	if issue.Reason == "" {
+		return fmt.Sprintf("%s %s: %s", issue.Backend, issue.Kind, issue.Path)
+	}
+	return fmt.Sprintf("%s %s: %s (%s)", issue.Backend, issue.Kind, issue.Path, issue.Reason)
+}
+
+// Report 检查结果
+type Report struct {
+	// Reference 基准名称
+	Reference string
+	// Checked 基准中检查的对象数量
+	Checked int
+	// Issues 不一致的对象，按路径和副本名称排序
+	Issues []*Issue
+}
+
+// OK 是否全部一致
+// 返回:
+//   - bool: 没有发现不一致时返回true
+func (report *Report) OK() bool {
+	return len(report.Issues) == 0
+}
+
+// Check 以第一个存储为基准比较其他存储
+// 所有存储并发列举，校验和按 Concurrency 并发计算
+// 参数:
+//   - reference: 基准存储
+//   - replicas: 需要检查的副本存储
+//   - opts: 检查选项，为nil时使用默认值
+// 返回:
+//   - *Report: 检查结果
+//   - error: 列举存储失败时返回错误
+func Check(reference Backend, replicas []Backend, opts *Options) (*Report, error) {
+	opts = normalize(opts)
+
+	snapshots, err := listAll(append([]Backend{reference}, replicas...), opts)
+	if err != nil {
+		return nil, err
+	}
+	return compare(snapshots[0], snapshots[1:], opts), nil
+}
+
+// CheckManifest 以清单为基准比较存储
+// 使用 CompareChecksum 时清单中缺少校验和的对象会被报告为不一致
+// 参数:
+//   - manifest: 基准清单，通常由 BuildManifest 生成
+//   - replicas: 需要检查的存储
+//   - opts: 检查选项，为nil时使用默认值
+// 返回:
+//   - *Report: 检查结果
+//   - error: 列举存储失败时返回错误
+func CheckManifest(manifest []*Entry, replicas []Backend, opts *Options) (*Report, error) {
+	opts = normalize(opts)
+
+	reference := &snapshot{name: "manifest", entries: map[string]*Entry{}}
+	prefix := "/" + oss.DirPrefix(opts.Prefix)
+	for _, entry := range manifest {
+		entry := *entry
+		entry.Path = cleanPath(entry.Path)
+		if strings.HasPrefix(entry.Path, prefix) {
+			reference.entries[entry.Path] = &entry
+		}
+	}
+
+	snapshots, err := listAll(replicas, opts)
+	if err != nil {
+		return nil, err
+	}
+	return compare(reference, snapshots, opts), nil
+}
+
+// BuildManifest 列举存储生成清单
+// 使用 CompareChecksum 时读取每个对象计算校验和，使用 CompareETag 时只为ETag不是MD5的对象计算校验和
+// 参数:
+//   - storage: 存储接口
+//   - opts: 检查选项，为nil时使用默认值
+// 返回:
+//   - []*Entry: 按路径排序的清单
+//   - error: 错误信息
+func BuildManifest(storage oss.StorageInterface, opts *Options) ([]*Entry, error) {
+	opts = normalize(opts)
+
+	snapshots, err := listAll([]Backend{{Name: "storage", Storage: storage}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	source := snapshots[0]
+
+	if opts.Strategy == CompareChecksum || opts.Strategy == CompareETag {
+		var targets []checksumTarget
+		for _, path := range source.paths() {
+			if opts.Strategy == CompareETag && md5ETag(source.entries[path].ETag) {
+				continue
+			}
+			targets = append(targets, checksumTarget{source, path})
+		}
+		if failures := computeChecksums(targets, opts.Concurrency); len(failures) > 0 {
+			return nil, fmt.Errorf("checksum %s failed: %w", failures[0].path, failures[0].err)
+		}
+	}
+
+	manifest := make([]*Entry, 0, len(source.entries))
+	for _, path := range source.paths() {
+		manifest = append(manifest, source.entries[path])
+	}
+	return manifest, nil
+}
+
+// WriteManifest 将清单以JSON格式写入
+// 参数:
+//   - writer: 写入目标
+//   - manifest: 清单
+// 返回:
+//   - error: 错误信息
+func WriteManifest(writer io.Writer, manifest []*Entry) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(manifest)
+}
+
+// ReadManifest 读取 WriteManifest 写入的清单
+// 参数:
+//   - reader: 清单内容
+// 返回:
+//   - []*Entry: 清单
+//   - error: 错误信息
+func ReadManifest(reader io.Reader) ([]*Entry, error) {
+	var manifest []*Entry
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// snapshot 一个存储在检查时的对象列表
+type snapshot struct {
+	name    string
+	storage oss.StorageInterface
+	entries map[string]*Entry
+}
+
+// paths 按字典序返回全部对象路径
+func (snapshot *snapshot) paths() []string {
+	paths := make([]string, 0, len(snapshot.entries))
+	for path := range snapshot.entries {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// checksumTarget 需要计算校验和的对象
+type checksumTarget struct {
+	snapshot *snapshot
+	path     string
+}
+
+// checksumPair 需要比较校验和的基准和副本对象
+type checksumPair struct {
+	path    string
+	replica *snapshot
+}
+
+// checksumFailure 计算校验和失败的对象
+type checksumFailure struct {
+	checksumTarget
+	err error
+}
+
+// normalize 填充选项的默认值
+func normalize(opts *Options) *Options {
+	normalized := Options{}
+	if opts != nil {
+		normalized = *opts
+	}
+	if normalized.Concurrency <= 0 {
+		normalized.Concurrency = DefaultConcurrency
+	}
+	return &normalized
+}
+
+// cleanPath 统一对象路径的格式，不同存储返回的路径不一定以 / 开头
+func cleanPath(path string) string {
+	return "/" + strings.TrimPrefix(path, "/")
+}
+
+// listAll 并发列举全部存储
+func listAll(backends []Backend, opts *Options) ([]*snapshot, error) {
+	var (
+		wg        sync.WaitGroup
+		snapshots = make([]*snapshot, len(backends))
+		errs      = make([]error, len(backends))
+		semaphore = make(chan struct{}, opts.Concurrency)
+	)
+
+	for i, backend := range backends {
+		wg.Add(1)
+		go func(i int, backend Backend) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			objects, err := backend.Storage.List(opts.Prefix)
+			if err != nil {
+				errs[i] = fmt.Errorf("list %s failed: %w", backend.Name, err)
+				return
+			}
+
+			entries := make(map[string]*Entry, len(objects))
+			for _, object := range objects {
+				path := cleanPath(object.Path)
+				entries[path] = &Entry{Path: path, Size: object.Size, LastModified: object.LastModified, ETag: object.ETag}
+			}
+			snapshots[i] = &snapshot{name: backend.Name, storage: backend.Storage, entries: entries}
+		}(i, backend)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return snapshots, nil
+}
+
+// computeChecksums 并发读取对象计算校验和，已有校验和的对象不会重复读取
+func computeChecksums(targets []checksumTarget, concurrency int) []checksumFailure {
+	var (
+		mutex    sync.Mutex
+		wg       sync.WaitGroup
+		failures []checksumFailure
+		queue    = make(chan checksumTarget)
+	)
+
+	for i := 0; i < concurrency && i < len(targets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				checksum, err := checksum(target.snapshot.storage, target.path)
+				mutex.Lock()
+				if err != nil {
+					failures = append(failures, checksumFailure{target, err})
+				} else {
+					target.snapshot.entries[target.path].Checksum = checksum
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	seen := map[checksumTarget]bool{}
+	for _, target := range targets {
+		if seen[target] || target.snapshot.storage == nil || target.snapshot.entries[target.path].Checksum != "" {
+			continue
+		}
+		seen[target] = true
+		queue <- target
+	}
+	close(queue)
+	wg.Wait()
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].path < failures[j].path
+	})
+	return failures
+}
+
+// checksum 计算对象内容的SHA256校验和
+func checksum(storage oss.StorageInterface, path string) (string, error) {
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, stream); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// compare 比较基准和每个副本，需要时计算校验和
+func compare(reference *snapshot, replicas []*snapshot, opts *Options) *Report {
+	report := &Report{Reference: reference.name, Checked: len(reference.entries)}
+	addIssue := func(path string, replica *snapshot, kind IssueKind, reason string) {
+		report.Issues = append(report.Issues, &Issue{Path: path, Backend: replica.name, Kind: kind, Reason: reason})
+	}
+
+	// 大小一致的对象在全部副本比较完成后统一计算校验和
+	var (
+		pairs   []checksumPair
+		targets []checksumTarget
+	)
+
+	for _, replica := range replicas {
+		for _, path := range reference.paths() {
+			expected := reference.entries[path]
+			actual, ok := replica.entries[path]
+			switch {
+			case !ok:
+				addIssue(path, replica, Missing, "")
+			case actual.Size != expected.Size:
+				addIssue(path, replica, Divergent, fmt.Sprintf("size %d, expected %d", actual.Size, expected.Size))
+			case opts.Strategy == CompareModTime && stale(expected, actual, opts.ModTimeTolerance):
+				addIssue(path, replica, Divergent, fmt.Sprintf("modified %v, expected not before %v", actual.LastModified, expected.LastModified))
+			case opts.Strategy == CompareETag && md5ETag(expected.ETag) && md5ETag(actual.ETag):
+				if !strings.EqualFold(expected.ETag, actual.ETag) {
+					addIssue(path, replica, Divergent, fmt.Sprintf("etag %s, expected %s", actual.ETag, expected.ETag))
+				}
+			case opts.Strategy == CompareChecksum || opts.Strategy == CompareETag:
+				pairs = append(pairs, checksumPair{path, replica})
+				targets = append(targets, checksumTarget{reference, path}, checksumTarget{replica, path})
+			}
+		}
+
+		for _, path := range replica.paths() {
+			if _, ok := reference.entries[path]; !ok {
+				addIssue(path, replica, Extra, "")
+			}
+		}
+	}
+
+	if len(pairs) > 0 {
+		failed := map[checksumTarget]error{}
+		for _, failure := range computeChecksums(targets, opts.Concurrency) {
+			failed[failure.checksumTarget] = failure.err
+		}
+
+		for _, pair := range pairs {
+			if err, ok := failed[checksumTarget{reference, pair.path}]; ok {
+				addIssue(pair.path, pair.replica, Unreadable, fmt.Sprintf("read %s: %v", reference.name, err))
+				continue
+			}
+			if err, ok := failed[checksumTarget{pair.replica, pair.path}]; ok {
+				addIssue(pair.path, pair.replica, Unreadable, err.Error())
+				continue
+			}
+
+			expected, actual := reference.entries[pair.path].Checksum, pair.replica.entries[pair.path].Checksum
+			if expected == "" {
+				addIssue(pair.path, pair.replica, Divergent, "no checksum in "+reference.name)
+			} else if actual != expected {
+				addIssue(pair.path, pair.replica, Divergent, fmt.Sprintf("checksum %s, expected %s", actual, expected))
+			}
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].Path != report.Issues[j].Path {
+			return report.Issues[i].Path < report.Issues[j].Path
+		}
+		return report.Issues[i].Backend < report.Issues[j].Backend
+	})
+	return report
+}
+
+// md5ETag ETag是否为内容的MD5，分片上传等生成的ETag无法跨存储比较
+func md5ETag(etag string) bool {
+	sum, err := hex.DecodeString(strings.Trim(etag, `"`))
+	return err == nil && len(sum) == md5.Size
+}
+
+// stale 副本的修改时间是否早于基准，任一方缺少修改时间时不比较
+func stale(expected, actual *Entry, tolerance time.Duration) bool {
+	if expected.LastModified == nil || actual.LastModified == nil {
+		return false
+	}
+	return actual.LastModified.Add(tolerance).Before(*expected.LastModified)
+}
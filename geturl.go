@@ -0,0 +1,105 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// URLStrategy 生成访问URL的方式
+type URLStrategy string
+
+const (
+	// URLSigned 生成预签名URL
+	URLSigned URLStrategy = "signed"
+	// URLPublic 使用 GetURL 返回的公共URL，只接受http或https的完整地址，不接受各后端直接返回的对象路径
+	URLPublic URLStrategy = "public"
+	// URLProxied 使用 URLOptions.ProxyBase 指定的网关或下载代理地址，例如 redirect.Handler 或 oss.OnceHandler 的挂载地址
+	URLProxied URLStrategy = "proxied"
+)
+
+// DefaultURLStrategies 存储后端未声明时依次尝试的方式
+var DefaultURLStrategies = []URLStrategy{URLSigned, URLPublic, URLProxied}
+
+// URLCapabilities 声明生成访问URL时依次尝试的方式的存储后端
+// 例如本地文件系统无法签名也没有公共地址，只能通过代理访问
+type URLCapabilities interface {
+	// URLStrategies 返回依次尝试的方式
+	// 返回:
+	//   - []URLStrategy: 生成访问URL的方式
+	URLStrategies() []URLStrategy
+}
+
+// URLOptions 生成访问URL的选项
+type URLOptions struct {
+	// Strategies 依次尝试的方式，为空时使用存储后端声明的方式或 DefaultURLStrategies
+	Strategies []URLStrategy
+	// Expiry 预签名URL的有效期，为0时使用各存储后端的默认值
+	Expiry time.Duration
+	// ProxyBase 代理地址，例如 https://example.com/files，对象路径转义后追加在其后，为空时跳过 URLProxied
+	ProxyBase string
+}
+
+// ResolvedURL 生成的访问URL
+type ResolvedURL struct {
+	// URL 访问URL
+	URL string
+	// Strategy 生成URL的方式
+	Strategy URLStrategy
+}
+
+// ResolveURL 按顺序尝试预签名、公共地址和代理地址，返回第一个可用的访问URL
+// 不支持的方式（返回 ErrNotSupported 或只返回对象路径）被跳过，调用方总是得到可以直接访问的URL或错误，
+// 而不是部分后端 GetURL 返回的对象路径
+// 参数:
+//   - storage: 存储接口
+//   - path: 对象路径
+//   - opts: 生成选项
+// 返回:
+//   - *ResolvedURL: 访问URL及其生成方式
+//   - error: 全部方式都不可用时返回 ErrNotSupported，包含各方式失败的原因
+func ResolveURL(storage StorageInterface, path string, opts URLOptions) (*ResolvedURL, error) {
+	strategies := opts.Strategies
+	if len(strategies) == 0 {
+		if capabilities, ok := storage.(URLCapabilities); ok {
+			strategies = capabilities.URLStrategies()
+		} else {
+			strategies = DefaultURLStrategies
+		}
+	}
+
+	var errs []error
+	for _, strategy := range strategies {
+		rawURL, err := resolveURL(storage, path, strategy, opts)
+		if err == nil {
+			return &ResolvedURL{URL: rawURL, Strategy: strategy}, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", strategy, err))
+	}
+	return nil, fmt.Errorf("%w: no usable URL for %s: %w", ErrNotSupported, path, errors.Join(errs...))
+}
+
+// resolveURL 使用一种方式生成访问URL
+func resolveURL(storage StorageInterface, path string, strategy URLStrategy, opts URLOptions) (string, error) {
+	switch strategy {
+	case URLSigned:
+		return storage.GetSignedURL(path, SignedURLOptions{Expiry: opts.Expiry})
+	case URLPublic:
+		rawURL, err := storage.GetURL(path)
+		if err != nil {
+			return "", err
+		}
+		if parsed, err := url.Parse(rawURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "", fmt.Errorf("%w: %T has no public URL", ErrNotSupported, storage)
+		}
+		return rawURL, nil
+	case URLProxied:
+		if opts.ProxyBase == "" {
+			return "", fmt.Errorf("%w: proxy base is not set", ErrNotSupported)
+		}
+		return strings.TrimSuffix(opts.ProxyBase, "/") + (&url.URL{Path: "/" + strings.TrimPrefix(path, "/")}).EscapedPath(), nil
+	}
+	return "", fmt.Errorf("%w: unknown URL strategy %q", ErrNotSupported, strategy)
+}
@@ -0,0 +1,39 @@
+package oss_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestResolveURL(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	if _, err := oss.ResolveURL(fileSystem, "/a b.txt", oss.URLOptions{}); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("File system without proxy should have no usable URL, but got %v", err)
+	}
+	resolved, err := oss.ResolveURL(fileSystem, "/a b.txt", oss.URLOptions{ProxyBase: "https://example.com/files/"})
+	if err != nil || resolved.URL != "https://example.com/files/a%20b.txt" || resolved.Strategy != oss.URLProxied {
+		t.Errorf("File system should fall back to proxied URL, but got %v %v", resolved, err)
+	}
+
+	storage := ossmock.New()
+	storage.GetURLFunc = func(path string) (string, error) { return "https://cdn.example.com" + path, nil }
+	resolved, err = oss.ResolveURL(storage, "/a.txt", oss.URLOptions{})
+	if err != nil || resolved.URL != "https://cdn.example.com/a.txt" || resolved.Strategy != oss.URLPublic {
+		t.Errorf("Unsupported signed URL should fall back to public URL, but got %v %v", resolved, err)
+	}
+	storage.GetSignedURLFunc = func(path string, opts oss.SignedURLOptions) (string, error) {
+		return "https://bucket.example.com" + path + "?signature", nil
+	}
+	resolved, err = oss.ResolveURL(storage, "/a.txt", oss.URLOptions{})
+	if err != nil || resolved.Strategy != oss.URLSigned {
+		t.Errorf("Signed URL should be preferred, but got %v %v", resolved, err)
+	}
+	resolved, err = oss.ResolveURL(storage, "/a.txt", oss.URLOptions{Strategies: []oss.URLStrategy{oss.URLPublic}})
+	if err != nil || resolved.Strategy != oss.URLPublic {
+		t.Errorf("Strategies option should override the default order, but got %v %v", resolved, err)
+	}
+}
@@ -0,0 +1,51 @@
+package googlecloud
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/smart-unicom/oss"
+)
+
+// SetACL 使用预定义ACL设置对象的访问控制
+// 存储桶开启统一存储桶级访问权限时只能通过IAM授权，服务端会拒绝该请求
+// 参数:
+//   - path: 对象路径
+//   - acl: 访问控制类型
+// 返回:
+//   - error: 错误信息
+func (client Client) SetACL(path string, acl oss.ACL) error {
+	predefinedACL, ok := predefinedACLs[acl]
+	if !ok {
+		return fmt.Errorf("%w: unknown ACL %q", oss.ErrNotSupported, acl)
+	}
+
+	_, err := client.BucketHandle.Object(path).Update(context.Background(), storage.ObjectAttrsToUpdate{PredefinedACL: predefinedACL})
+	return wrapError(err)
+}
+
+// GetACL 根据对象的ACL规则获取访问控制
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - oss.ACL: 访问控制类型
+//   - error: 错误信息
+func (client Client) GetACL(path string) (oss.ACL, error) {
+	attrs, err := client.BucketHandle.Object(path).Attrs(context.Background())
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	var publicRead, publicWrite, authenticatedRead bool
+	for _, rule := range attrs.ACL {
+		switch rule.Entity {
+		case storage.AllUsers:
+			publicRead = true
+			publicWrite = publicWrite || rule.Role == storage.RoleWriter || rule.Role == storage.RoleOwner
+		case storage.AllAuthenticatedUsers:
+			authenticatedRead = true
+		}
+	}
+	return oss.ACLFromGrants(publicRead, publicWrite, authenticatedRead), nil
+}
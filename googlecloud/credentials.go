@@ -0,0 +1,54 @@
+package googlecloud
+
+import (
+	"context"
+
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// cloudPlatformScope 访问Cloud Storage使用的OAuth2范围
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// newCredentials 根据配置创建访问Cloud Storage使用的凭据
+// 配置了服务账户JSON密钥时使用密钥，否则使用应用默认凭据（GOOGLE_APPLICATION_CREDENTIALS、gcloud登录的用户、GCE/GKE元数据服务）；
+// 配置了 ImpersonateServiceAccount 时以上述凭据为基础凭据，通过IAM Credentials API的 generateAccessToken 换取目标服务账户的访问令牌
+// 参数:
+//   - ctx: 上下文
+//   - config: Google Cloud配置信息
+// 返回:
+//   - *google.Credentials: 凭据
+//   - error: 错误信息
+func newCredentials(ctx context.Context, config *Config) (*google.Credentials, error) {
+	var (
+		credentials *google.Credentials
+		err         error
+	)
+	if config.ServiceAccountJson != "" {
+		credentials, err = google.CredentialsFromJSON(ctx, []byte(config.ServiceAccountJson), cloudPlatformScope)
+	} else {
+		credentials, err = google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	}
+	if err != nil || config.ImpersonateServiceAccount == "" {
+		return credentials, err
+	}
+
+	tokenSource, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: config.ImpersonateServiceAccount,
+		Scopes:          []string{cloudPlatformScope},
+		Delegates:       config.ImpersonateDelegates,
+		Lifetime:        config.ImpersonateLifetime,
+	}, option.WithCredentials(credentials))
+	if err != nil {
+		return nil, err
+	}
+	// 不保留基础凭据的JSON，签名URL时按目标服务账户签名
+	return &google.Credentials{ProjectID: credentials.ProjectID, TokenSource: tokenSource}, nil
+}
+
+// signedURLAccessID 返回签名URL使用的服务账户
+// 模拟服务账户时凭据中没有私钥，SDK需要知道目标服务账户才能通过IAM的 signBlob 签名，其他情况由SDK从凭据中检测
+func (client Client) signedURLAccessID() string {
+	return client.Config.ImpersonateServiceAccount
+}
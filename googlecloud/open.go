@@ -0,0 +1,50 @@
+package googlecloud
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/smart-unicom/oss"
+)
+
+func init() {
+	oss.Register("gs", open)
+}
+
+// open 根据连接字符串创建Google Cloud存储客户端
+// 格式为 gs://bucket?credentials=/etc/gcs/service-account.json，credentials为服务账号JSON文件的路径，省略时使用应用默认凭据；
+// impersonate 为模拟的目标服务账户邮箱，delegates 为逗号分隔的委托链
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - oss.StorageInterface: Google Cloud存储客户端
+//   - error: 错误信息
+func open(dsn url.URL) (oss.StorageInterface, error) {
+	if dsn.Host == "" {
+		return nil, fmt.Errorf("googlecloud: dsn %s has no bucket", dsn.Redacted())
+	}
+	params := oss.NewDSNParams(dsn)
+	credentials := params.Get("credentials")
+	config := &Config{
+		Bucket:                    dsn.Host,
+		Endpoint:                  params.Get("endpoint"),
+		ImpersonateServiceAccount: params.Get("impersonate"),
+	}
+	if delegates := params.Get("delegates"); delegates != "" {
+		config.ImpersonateDelegates = strings.Split(delegates, ",")
+	}
+	if err := params.Err(); err != nil {
+		return nil, err
+	}
+	if credentials == "" {
+		return New(config)
+	}
+	serviceAccountJson, err := os.ReadFile(credentials)
+	if err != nil {
+		return nil, err
+	}
+	config.ServiceAccountJson = string(serviceAccountJson)
+	return New(config)
+}
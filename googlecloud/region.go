@@ -0,0 +1,18 @@
+package googlecloud
+
+import (
+	"context"
+	"strings"
+)
+
+// BucketRegion 查询存储桶所在的区域
+// 返回:
+//   - string: 区域名，例如 europe-west1，多区域存储桶为 eu、us 等
+//   - error: 错误信息
+func (client Client) BucketRegion() (string, error) {
+	attrs, err := client.BucketHandle.Attrs(context.Background())
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return strings.ToLower(attrs.Location), nil
+}
@@ -0,0 +1,369 @@
+package oss
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultHotspotWindow 热点统计的默认时间窗口
+	DefaultHotspotWindow = 10 * time.Minute
+	// DefaultHotspotPrefixDepth 热点统计默认按前两级目录汇总
+	DefaultHotspotPrefixDepth = 2
+	// DefaultHotspotTopN 报告中默认返回的前缀和对象数量
+	DefaultHotspotTopN = 20
+
+	// hotspotSlots 时间窗口分成的槽数，过期的槽整体丢弃
+	hotspotSlots = 10
+)
+
+// HotspotOptions 热点统计配置
+type HotspotOptions struct {
+	// SampleEvery 每多少次调用统计一次，小于等于1时统计每次调用，报告中的次数和字节数按比例放大
+	SampleEvery int
+	// Window 统计的时间窗口，只报告最近这段时间内的调用，小于等于0时使用 DefaultHotspotWindow
+	Window time.Duration
+	// PrefixDepth 按多少级目录汇总前缀，例如为2时 /a/b/c/d.txt 计入 /a/b/，小于等于0时使用 DefaultHotspotPrefixDepth
+	PrefixDepth int
+	// TopN 报告中返回的前缀和对象数量，小于等于0时使用 DefaultHotspotTopN
+	TopN int
+}
+
+// PrefixHotspot 前缀在时间窗口内的调用统计
+type PrefixHotspot struct {
+	// Prefix 目录前缀，以 / 结尾
+	Prefix string
+	// Puts 上传次数的估计值
+	Puts int64
+	// Lists 列出次数的估计值
+	Lists int64
+	// Bytes 上传字节数的估计值
+	Bytes int64
+}
+
+// HotspotReport 热点统计报告
+type HotspotReport struct {
+	// Since 统计开始时间
+	Since time.Time
+	// Until 统计结束时间
+	Until time.Time
+	// Prefixes 上传和列出次数最多的前缀，按次数从多到少排列
+	Prefixes []PrefixHotspot
+	// Largest 采样到的最大的对象，按大小从大到小排列
+	Largest []*Object
+}
+
+// hotspotSlot 时间窗口中的一个槽
+type hotspotSlot struct {
+	id       int64
+	prefixes map[string]*PrefixHotspot
+	largest  map[string]*Object
+}
+
+// HotspotAnalyzer 采样上传和列出调用，统计时间窗口内最热的前缀和最大的对象
+// 用于根据实际流量决定分片和缓存策略，例如哪些前缀需要分散到多个存储桶、哪些前缀值得缓存
+type HotspotAnalyzer struct {
+	// Options 热点统计配置
+	Options HotspotOptions
+
+	calls atomic.Int64
+	mu    sync.Mutex
+	slots [hotspotSlots]hotspotSlot
+}
+
+// NewHotspotAnalyzer 创建热点统计
+// 参数:
+//   - opts: 热点统计配置
+// 返回:
+//   - *HotspotAnalyzer: 热点统计实例，通过 WithHotspots 包装存储接口后开始采样
+func NewHotspotAnalyzer(opts HotspotOptions) *HotspotAnalyzer {
+	if opts.SampleEvery <= 1 {
+		opts.SampleEvery = 1
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultHotspotWindow
+	}
+	if opts.PrefixDepth <= 0 {
+		opts.PrefixDepth = DefaultHotspotPrefixDepth
+	}
+	if opts.TopN <= 0 {
+		opts.TopN = DefaultHotspotTopN
+	}
+	return &HotspotAnalyzer{Options: opts}
+}
+
+// sample 判断本次调用是否需要统计，按调用顺序每 SampleEvery 次统计一次
+func (analyzer *HotspotAnalyzer) sample() bool {
+	return analyzer.calls.Add(1)%int64(analyzer.Options.SampleEvery) == 0
+}
+
+// slotDuration 返回每个槽覆盖的时间
+func (analyzer *HotspotAnalyzer) slotDuration() time.Duration {
+	return analyzer.Options.Window / hotspotSlots
+}
+
+// slot 返回当前时间所在的槽，槽已过期时清空，调用方需要持有锁
+func (analyzer *HotspotAnalyzer) slot() *hotspotSlot {
+	id := Now().UnixNano() / int64(analyzer.slotDuration())
+	slot := &analyzer.slots[id%hotspotSlots]
+	if slot.id != id || slot.prefixes == nil {
+		*slot = hotspotSlot{id: id, prefixes: map[string]*PrefixHotspot{}, largest: map[string]*Object{}}
+	}
+	return slot
+}
+
+// prefix 返回路径按 PrefixDepth 汇总的目录前缀
+func (analyzer *HotspotAnalyzer) prefix(path string, dir bool) string {
+	segments := strings.Split(strings.Trim(strings.ReplaceAll(path, `\`, "/"), "/"), "/")
+	if !dir {
+		segments = segments[:len(segments)-1]
+	}
+	if len(segments) > analyzer.Options.PrefixDepth {
+		segments = segments[:analyzer.Options.PrefixDepth]
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		return "/"
+	}
+	return "/" + strings.Join(segments, "/") + "/"
+}
+
+// RecordPut 记录一次上传，未被采样时忽略
+// 参数:
+//   - path: 上传的路径
+//   - object: 上传后的对象信息，可以为nil，用于统计字节数和最大的对象
+func (analyzer *HotspotAnalyzer) RecordPut(path string, object *Object) {
+	if !analyzer.sample() {
+		return
+	}
+	every := int64(analyzer.Options.SampleEvery)
+
+	analyzer.mu.Lock()
+	defer analyzer.mu.Unlock()
+	slot := analyzer.slot()
+	stats := slot.hotspot(analyzer.prefix(path, false))
+	stats.Puts += every
+	if object != nil {
+		stats.Bytes += object.Size * every
+		slot.track(object, analyzer.Options.TopN)
+	}
+}
+
+// RecordList 记录一次列出，未被采样时忽略
+// 参数:
+//   - path: 列出的目录
+//   - objects: 列出的对象，用于统计最大的对象
+func (analyzer *HotspotAnalyzer) RecordList(path string, objects []*Object) {
+	if !analyzer.sample() {
+		return
+	}
+
+	analyzer.mu.Lock()
+	defer analyzer.mu.Unlock()
+	slot := analyzer.slot()
+	slot.hotspot(analyzer.prefix(path, true)).Lists += int64(analyzer.Options.SampleEvery)
+	for _, object := range objects {
+		slot.track(object, analyzer.Options.TopN)
+	}
+}
+
+// hotspot 返回前缀的统计，不存在时创建
+func (slot *hotspotSlot) hotspot(prefix string) *PrefixHotspot {
+	stats, ok := slot.prefixes[prefix]
+	if !ok {
+		stats = &PrefixHotspot{Prefix: prefix}
+		slot.prefixes[prefix] = stats
+	}
+	return stats
+}
+
+// track 记录对象大小，只保留最大的一部分对象用于报告
+func (slot *hotspotSlot) track(object *Object, topN int) {
+	if object == nil || object.Path == "" {
+		return
+	}
+	slot.largest[object.Path] = &Object{Path: object.Path, Name: object.Name, Size: object.Size, LastModified: object.LastModified}
+	if len(slot.largest) > 2*topN {
+		for _, small := range largestObjects(slot.largest, len(slot.largest))[topN:] {
+			delete(slot.largest, small.Path)
+		}
+	}
+}
+
+// largestObjects 按大小从大到小返回前n个对象，大小相同时按路径排序
+func largestObjects(objects map[string]*Object, n int) []*Object {
+	sorted := make([]*Object, 0, len(objects))
+	for _, object := range objects {
+		sorted = append(sorted, object)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// Report 返回时间窗口内最热的前缀和最大的对象
+// 返回:
+//   - *HotspotReport: 热点统计报告
+func (analyzer *HotspotAnalyzer) Report() *HotspotReport {
+	analyzer.mu.Lock()
+	defer analyzer.mu.Unlock()
+
+	slotDuration := analyzer.slotDuration()
+	now := Now()
+	current := now.UnixNano() / int64(slotDuration)
+	prefixes := map[string]*PrefixHotspot{}
+	largest := map[string]*Object{}
+	for i := range analyzer.slots {
+		slot := &analyzer.slots[i]
+		if slot.prefixes == nil || slot.id <= current-hotspotSlots {
+			continue
+		}
+		for prefix, stats := range slot.prefixes {
+			merged, ok := prefixes[prefix]
+			if !ok {
+				merged = &PrefixHotspot{Prefix: prefix}
+				prefixes[prefix] = merged
+			}
+			merged.Puts += stats.Puts
+			merged.Lists += stats.Lists
+			merged.Bytes += stats.Bytes
+		}
+		for path, object := range slot.largest {
+			if existing, ok := largest[path]; !ok || object.Size > existing.Size {
+				largest[path] = object
+			}
+		}
+	}
+
+	report := &HotspotReport{
+		Since:   time.Unix(0, (current-hotspotSlots+1)*int64(slotDuration)),
+		Until:   now,
+		Largest: largestObjects(largest, analyzer.Options.TopN),
+	}
+	for _, stats := range prefixes {
+		report.Prefixes = append(report.Prefixes, *stats)
+	}
+	sort.Slice(report.Prefixes, func(i, j int) bool {
+		a, b := report.Prefixes[i], report.Prefixes[j]
+		if a.Puts+a.Lists != b.Puts+b.Lists {
+			return a.Puts+a.Lists > b.Puts+b.Lists
+		}
+		return a.Prefix < b.Prefix
+	})
+	if len(report.Prefixes) > analyzer.Options.TopN {
+		report.Prefixes = report.Prefixes[:analyzer.Options.TopN]
+	}
+	return report
+}
+
+// HotspotStorage 将上传和列出调用交给热点统计采样的存储包装器
+type HotspotStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Analyzer 热点统计
+	Analyzer *HotspotAnalyzer
+}
+
+// WithHotspots 创建将上传和列出调用交给热点统计采样的存储包装器
+// 多个存储接口可以共用一个热点统计
+// 参数:
+//   - storage: 被包装的存储接口
+//   - analyzer: 热点统计
+// 返回:
+//   - *HotspotStorage: 存储包装器实例
+func WithHotspots(storage StorageInterface, analyzer *HotspotAnalyzer) *HotspotStorage {
+	return &HotspotStorage{StorageInterface: storage, Analyzer: analyzer}
+}
+
+// Put 上传文件并记录上传
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *HotspotStorage) Put(path string, reader io.Reader) (*Object, error) {
+	object, err := storage.StorageInterface.Put(path, reader)
+	if err == nil {
+		storage.Analyzer.RecordPut(objectPath(object, path), object)
+	}
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件并记录上传
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *HotspotStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	if err == nil {
+		storage.Analyzer.RecordPut(objectPath(object, path), object)
+	}
+	return object, err
+}
+
+// NewWriter 创建流式写入器，关闭成功后按写入的字节数记录上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *HotspotStorage) NewWriter(path string) (io.WriteCloser, error) {
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &hotspotWriter{WriteCloser: writer, analyzer: storage.Analyzer, path: path}, nil
+}
+
+// List 列出对象并记录列出
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息
+func (storage *HotspotStorage) List(path string) ([]*Object, error) {
+	objects, err := storage.StorageInterface.List(path)
+	if err == nil {
+		storage.Analyzer.RecordList(path, objects)
+	}
+	return objects, err
+}
+
+// hotspotWriter 统计写入字节数的写入器，关闭成功后记录上传
+type hotspotWriter struct {
+	io.WriteCloser
+	analyzer *HotspotAnalyzer
+	path     string
+	written  int64
+}
+
+// Write 写入数据并累计字节数
+func (writer *hotspotWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.written += int64(n)
+	return n, err
+}
+
+// Close 关闭写入器，成功后记录上传
+func (writer *hotspotWriter) Close() error {
+	if err := writer.WriteCloser.Close(); err != nil {
+		return err
+	}
+	writer.analyzer.RecordPut(writer.path, &Object{Path: writer.path, Size: writer.written})
+	return nil
+}
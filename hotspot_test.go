@@ -0,0 +1,55 @@
+package oss_test
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestHotspots(t *testing.T) {
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+
+	analyzer := oss.NewHotspotAnalyzer(oss.HotspotOptions{PrefixDepth: 1, TopN: 2})
+	storage := oss.WithHotspots(ossmock.New(), analyzer)
+	for i := 0; i < 3; i++ {
+		storage.Put(fmt.Sprintf("/logs/2024/%d.txt", i), strings.NewReader(strings.Repeat("l", 100)))
+	}
+	storage.Put("/img/x.png", strings.NewReader(strings.Repeat("x", 1000)))
+	now = now.Add(5 * time.Minute)
+	storage.List("/logs/2024")
+	writer, _ := storage.NewWriter("/img/y.png")
+	io.WriteString(writer, strings.Repeat("y", 5000))
+	writer.Close()
+
+	report := analyzer.Report()
+	expected := []oss.PrefixHotspot{{Prefix: "/logs/", Puts: 3, Lists: 1, Bytes: 300}, {Prefix: "/img/", Puts: 2, Bytes: 6000}}
+	if fmt.Sprint(report.Prefixes) != fmt.Sprint(expected) {
+		t.Errorf("Hottest prefixes should be reported, but got %v", report.Prefixes)
+	}
+	if len(report.Largest) != 2 || report.Largest[0].Path != "/img/y.png" || report.Largest[1].Size != 1000 {
+		t.Errorf("Largest objects should be reported, but got %v", report.Largest)
+	}
+
+	now = now.Add(6 * time.Minute)
+	report = analyzer.Report()
+	expected = []oss.PrefixHotspot{{Prefix: "/img/", Puts: 1, Bytes: 5000}, {Prefix: "/logs/", Lists: 1}}
+	if fmt.Sprint(report.Prefixes) != fmt.Sprint(expected) {
+		t.Errorf("Calls outside the window should expire, but got %v", report.Prefixes)
+	}
+
+	sampled := oss.NewHotspotAnalyzer(oss.HotspotOptions{SampleEvery: 2})
+	for i := 0; i < 5; i++ {
+		sampled.RecordPut("/a/b/c/d.txt", &oss.Object{Path: "/a/b/c/d.txt", Size: 10})
+	}
+	if report := sampled.Report(); len(report.Prefixes) != 1 || report.Prefixes[0] != (oss.PrefixHotspot{Prefix: "/a/b/", Puts: 4, Bytes: 40}) {
+		t.Errorf("Sampled calls should be scaled up, but got %v", report.Prefixes)
+	}
+}
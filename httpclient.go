@@ -0,0 +1,90 @@
+package oss
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultDialTimeout 建立连接的默认超时时间
+	DefaultDialTimeout = 30 * time.Second
+	// DefaultTLSHandshakeTimeout TLS握手的默认超时时间
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	// DefaultResponseHeaderTimeout 等待响应头的默认超时时间，不限制读取响应内容的时间
+	DefaultResponseHeaderTimeout = 60 * time.Second
+	// DefaultIdleConnTimeout 空闲连接的默认保留时间
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// HTTPOptions 创建HTTP客户端的传输选项
+// 各存储后端的 Config.HTTPClient 可以使用 NewHTTPClient 创建，也可以直接传入自定义的 *http.Client
+type HTTPOptions struct {
+	// Proxy 代理选择函数，为nil时使用环境变量 HTTP_PROXY、HTTPS_PROXY 和 NO_PROXY
+	Proxy func(*http.Request) (*url.URL, error)
+	// TLSConfig TLS配置，例如自定义根证书或客户端证书
+	TLSConfig *tls.Config
+	// MaxIdleConns 所有主机的最大空闲连接数，为0时不限制
+	MaxIdleConns int
+	// MaxIdleConnsPerHost 每个主机的最大空闲连接数，为0时使用 http.DefaultMaxIdleConnsPerHost
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个主机的最大连接数，为0时不限制
+	MaxConnsPerHost int
+	// DialTimeout 建立连接的超时时间，为0时使用 DefaultDialTimeout
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout TLS握手的超时时间，为0时使用 DefaultTLSHandshakeTimeout
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout 发送请求后等待响应头的超时时间，为0时使用 DefaultResponseHeaderTimeout
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout 空闲连接的保留时间，为0时使用 DefaultIdleConnTimeout
+	IdleConnTimeout time.Duration
+	// Timeout 整个请求的超时时间，包括读取响应内容，为0时不限制；下载大文件时应使用 WithTimeout 或请求上下文控制
+	Timeout time.Duration
+}
+
+// DefaultHTTPClient 没有设置 Config.HTTPClient 时七牛和群晖使用的HTTP客户端
+// 与 http.DefaultClient 不同，建立连接、TLS握手和等待响应头都有超时，服务端无响应时不会一直阻塞
+var DefaultHTTPClient = NewHTTPClient(HTTPOptions{})
+
+// NewHTTPClient 按传输选项创建HTTP客户端
+// 参数:
+//   - opts: 传输选项，未设置的超时使用默认值
+// 返回:
+//   - *http.Client: HTTP客户端
+func NewHTTPClient(opts HTTPOptions) *http.Client {
+	if opts.Proxy == nil {
+		opts.Proxy = http.ProxyFromEnvironment
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.TLSHandshakeTimeout <= 0 {
+		opts.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if opts.ResponseHeaderTimeout <= 0 {
+		opts.ResponseHeaderTimeout = DefaultResponseHeaderTimeout
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: opts.DialTimeout, KeepAlive: 30 * time.Second}
+	return &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			Proxy:                 opts.Proxy,
+			DialContext:           dialer.DialContext,
+			TLSClientConfig:       opts.TLSConfig,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          opts.MaxIdleConns,
+			MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+			MaxConnsPerHost:       opts.MaxConnsPerHost,
+			TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+			ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+			IdleConnTimeout:       opts.IdleConnTimeout,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxRedirects 下载时默认最多跟随的跳转次数
+const DefaultMaxRedirects = 10
+
+// ErrTooManyRedirects 下载时跳转次数超过 RedirectPolicy.MaxRedirects
+var ErrTooManyRedirects = errors.New("oss: too many redirects")
+
+// sensitiveHeaders 跳转到其他主机时删除的认证请求头
+var sensitiveHeaders = []string{"Authorization", "Cookie", "X-Syno-Token"}
+
+// RedirectPolicy 通过HTTP下载时跟随跳转的策略
+// 七牛和群晖的下载地址可能302跳转到CDN或其他节点
+type RedirectPolicy struct {
+	// MaxRedirects 最多跟随的跳转次数，为0时使用 DefaultMaxRedirects，小于0时不跟随跳转
+	MaxRedirects int
+}
+
+// CheckRedirect 作为 http.Client.CheckRedirect 使用的跳转检查
+// 跳转次数超过上限时返回 ErrTooManyRedirects，不跟随跳转时返回 http.ErrUseLastResponse；
+// 跳转到与第一个请求不同的主机时删除 Authorization、Cookie 等认证请求头，只在同一主机内保留
+// 参数:
+//   - req: 即将发送的跳转请求
+//   - via: 之前发送的请求，第一个为原始请求
+// 返回:
+//   - error: 停止跳转的原因
+func (policy RedirectPolicy) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if policy.MaxRedirects < 0 {
+		return http.ErrUseLastResponse
+	}
+	max := policy.MaxRedirects
+	if max == 0 {
+		max = DefaultMaxRedirects
+	}
+	if len(via) > max {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, max)
+	}
+	if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		for _, header := range sensitiveHeaders {
+			req.Header.Del(header)
+		}
+	}
+	return nil
+}
+
+// Client 返回使用该跳转策略的HTTP客户端
+// 参数:
+//   - base: 基础HTTP客户端，为nil时使用 http.DefaultClient，不会被修改
+// 返回:
+//   - *http.Client: HTTP客户端副本
+func (policy RedirectPolicy) Client(base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	client := *base
+	client.CheckRedirect = policy.CheckRedirect
+	return &client
+}
+
+// DownloadStream 通过HTTP下载的文件流
+// 记录跟随跳转后实际返回内容的地址，用于排查CDN或节点问题
+type DownloadStream struct {
+	io.ReadCloser
+	// URL 最终响应的请求地址
+	URL string
+	// Redirects 跟随的跳转次数
+	Redirects int
+}
+
+// NewDownloadStream 由HTTP响应创建文件流
+// 参数:
+//   - resp: 最终的HTTP响应
+// 返回:
+//   - *DownloadStream: 文件流，读取响应内容
+func NewDownloadStream(resp *http.Response) *DownloadStream {
+	stream := &DownloadStream{ReadCloser: resp.Body}
+	if resp.Request != nil {
+		stream.URL = resp.Request.URL.String()
+		for req := resp.Request; req.Response != nil && req.Response.Request != nil; req = req.Response.Request {
+			stream.Redirects++
+		}
+	}
+	return stream
+}
+
+// StreamURL 返回文件流最终的下载地址
+// 参数:
+//   - stream: GetStream 返回的文件流
+// 返回:
+//   - string: 最终地址，文件流不是通过HTTP下载时返回空字符串
+func StreamURL(stream io.Reader) string {
+	if download, ok := stream.(*DownloadStream); ok {
+		return download.URL
+	}
+	return ""
+}
@@ -0,0 +1,390 @@
+// Package httpserver 通过HTTP只读访问存储的处理器
+// 提供对象下载，并可以为目录生成HTML或JSON索引页，团队不需要开发界面即可在浏览器中浏览任意存储后端的前缀
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultPageSize 索引页每页默认的条目数
+const DefaultPageSize = 100
+
+// 索引页的查询参数
+const (
+	// SortParam 排序字段：name、size 或 modified
+	SortParam = "sort"
+	// OrderParam 排序方向：asc 或 desc
+	OrderParam = "order"
+	// PageParam 页码，从1开始
+	PageParam = "page"
+	// FormatParam 为 json 时返回JSON索引
+	FormatParam = "format"
+)
+
+// Options 处理器配置
+type Options struct {
+	// Index 是否为以斜杠结尾的目录路径生成索引页，为false时目录路径返回404
+	Index bool
+	// PageSize 索引页每页的条目数，小于等于0时使用 DefaultPageSize
+	PageSize int
+}
+
+// Handler 通过HTTP只读访问存储的处理器
+// 只处理GET和HEAD请求，对象路径为请求路径，挂载在子路径下时配合 http.StripPrefix 使用
+type Handler struct {
+	// Storage 存储接口
+	Storage oss.StorageInterface
+	// Options 处理器配置
+	Options Options
+}
+
+// New 创建通过HTTP只读访问存储的处理器
+// 参数:
+//   - storage: 存储接口
+//   - opts: 处理器配置
+// 返回:
+//   - *Handler: 处理器实例
+func New(storage oss.StorageInterface, opts Options) *Handler {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	return &Handler{Storage: storage, Options: opts}
+}
+
+// Entry 索引页中的一个条目
+type Entry struct {
+	// Name 对象或子目录名称，子目录以斜杠结尾
+	Name string `json:"name"`
+	// Path 对象或子目录的完整路径
+	Path string `json:"path"`
+	// Dir 是否为子目录
+	Dir bool `json:"dir"`
+	// Size 对象大小，子目录为0
+	Size int64 `json:"size"`
+	// LastModified 最后修改时间，子目录和未知时为nil
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+// Index 目录索引
+type Index struct {
+	// Path 目录路径，以斜杠结尾
+	Path string `json:"path"`
+	// Entries 当前页的条目，子目录在前
+	Entries []Entry `json:"entries"`
+	// Sort 排序字段
+	Sort string `json:"sort"`
+	// Order 排序方向
+	Order string `json:"order"`
+	// Page 当前页码
+	Page int `json:"page"`
+	// Pages 总页数
+	Pages int `json:"pages"`
+	// Total 条目总数
+	Total int `json:"total"`
+}
+
+// ServeHTTP 以斜杠结尾的路径返回目录索引，其他路径返回对象内容
+// 启用索引时，不存在的对象路径如果是目录会跳转到以斜杠结尾的路径
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := cleanPath(r.URL.Path)
+	if strings.HasSuffix(path, "/") {
+		if !handler.Options.Index {
+			http.NotFound(w, r)
+			return
+		}
+		handler.index(w, r, path)
+		return
+	}
+
+	object, err := handler.Storage.Stat(path)
+	if errors.Is(err, oss.ErrNotFound) && handler.Options.Index && handler.isDir(path) {
+		// 使用相对地址，挂载在子路径下时同样有效
+		w.Header().Set("Location", pathpkg.Base(path)+"/")
+		w.WriteHeader(http.StatusMovedPermanently)
+		return
+	}
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+	handler.serveObject(w, r, path, object)
+}
+
+// httpError 按 oss.HTTPStatus 返回错误，响应中只有状态码的说明，不包含存储桶名称、访问地址等后端的错误信息
+func httpError(w http.ResponseWriter, err error) {
+	status := oss.HTTPStatus(err)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// cleanPath 清理请求路径，去掉 .. 等路径段并保留结尾的斜杠
+func cleanPath(path string) string {
+	cleaned := pathpkg.Clean("/" + path)
+	if strings.HasSuffix(path, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// isDir 判断路径下是否有对象或子目录
+func (handler *Handler) isDir(path string) bool {
+	result, err := oss.ListWithOptions(handler.Storage, path, oss.ListOptions{MaxResults: 1})
+	return err == nil && len(result.Objects)+len(result.Prefixes) > 0
+}
+
+// serveObject 通过 http.ServeContent 返回对象内容，支持范围请求和条件请求
+// 浏览器不会猜测内容类型，存储中的 ContentType 为空时按扩展名和内容检测
+func (handler *Handler) serveObject(w http.ResponseWriter, r *http.Request, path string, object *oss.Object) {
+	header := w.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	if object.ContentType != "" {
+		header.Set("Content-Type", object.ContentType)
+	}
+	if object.ETag != "" {
+		header.Set("ETag", strconv.Quote(strings.Trim(object.ETag, `"`)))
+	}
+	var modified time.Time
+	if object.LastModified != nil {
+		modified = *object.LastModified
+	}
+
+	content := &rangeReader{storage: handler.Storage, path: path, size: object.Size}
+	defer content.Close()
+	// 提前打开完整的流，对象无法读取时仍然可以返回错误状态码
+	if r.Method == http.MethodGet && r.Header.Get("Range") == "" {
+		stream, err := handler.Storage.GetStream(path)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		content.stream = stream
+	}
+	http.ServeContent(w, r, pathpkg.Base(path), modified, content)
+}
+
+// rangeReader 按需通过 GetStreamRange 读取对象的 io.ReadSeeker
+// Seek 只记录位置，读取的位置与已打开的流不同时才重新打开流
+type rangeReader struct {
+	storage oss.StorageInterface
+	path    string
+	size    int64
+	offset  int64
+	// stream 已打开的流，streamOffset 为它的读取位置
+	stream       io.ReadCloser
+	streamOffset int64
+}
+
+// Read 从当前位置读取对象内容
+func (reader *rangeReader) Read(p []byte) (int, error) {
+	if reader.offset >= reader.size {
+		return 0, io.EOF
+	}
+	if reader.stream != nil && reader.streamOffset != reader.offset {
+		reader.Close()
+	}
+	if reader.stream == nil {
+		stream, err := reader.storage.GetStreamRange(reader.path, reader.offset, 0)
+		if err != nil {
+			return 0, err
+		}
+		reader.stream, reader.streamOffset = stream, reader.offset
+	}
+	n, err := reader.stream.Read(p)
+	reader.offset += int64(n)
+	reader.streamOffset += int64(n)
+	return n, err
+}
+
+// Seek 设置下一次读取的位置
+func (reader *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += reader.offset
+	case io.SeekEnd:
+		offset += reader.size
+	}
+	if offset < 0 {
+		return 0, errors.New("httpserver: negative position")
+	}
+	reader.offset = offset
+	return offset, nil
+}
+
+// Close 关闭已打开的流
+func (reader *rangeReader) Close() error {
+	if reader.stream == nil {
+		return nil
+	}
+	err := reader.stream.Close()
+	reader.stream = nil
+	return err
+}
+
+// index 返回目录索引，请求 format=json 或 Accept 为 application/json 时返回JSON
+func (handler *Handler) index(w http.ResponseWriter, r *http.Request, path string) {
+	result, err := oss.ListWithOptions(handler.Storage, path, oss.ListOptions{})
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	index := &Index{Path: path, Sort: query.Get(SortParam), Order: query.Get(OrderParam)}
+	if index.Sort != "size" && index.Sort != "modified" {
+		index.Sort = "name"
+	}
+	if index.Order != "desc" {
+		index.Order = "asc"
+	}
+
+	var dirs, files []Entry
+	for _, prefix := range result.Prefixes {
+		name := pathpkg.Base(prefix) + "/"
+		dirs = append(dirs, Entry{Name: name, Path: path + name, Dir: true})
+	}
+	for _, object := range result.Objects {
+		files = append(files, Entry{Name: pathpkg.Base(object.Path), Path: object.Path, Size: object.Size, LastModified: object.LastModified})
+	}
+	sortEntries(dirs, "name", index.Order)
+	sortEntries(files, index.Sort, index.Order)
+	entries := append(dirs, files...)
+
+	pageSize := handler.Options.PageSize
+	index.Total = len(entries)
+	index.Pages = (len(entries) + pageSize - 1) / pageSize
+	if index.Pages == 0 {
+		index.Pages = 1
+	}
+	index.Page, _ = strconv.Atoi(query.Get(PageParam))
+	if index.Page < 1 {
+		index.Page = 1
+	}
+	if index.Page > index.Pages {
+		index.Page = index.Pages
+	}
+	start := (index.Page - 1) * pageSize
+	index.Entries = entries[start:min(start+pageSize, len(entries))]
+	if index.Entries == nil {
+		index.Entries = []Entry{}
+	}
+
+	if query.Get(FormatParam) == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(index)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if r.Method == http.MethodGet {
+		indexTemplate.Execute(w, index)
+	}
+}
+
+// sortEntries 按字段排序条目，字段相同时按名称排序
+func sortEntries(entries []Entry, field, order string) {
+	less := func(a, b Entry) bool {
+		switch field {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "modified":
+			at, bt := modifiedTime(a), modifiedTime(b)
+			if !at.Equal(bt) {
+				return at.Before(bt)
+			}
+		}
+		return a.Name < b.Name
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if order == "desc" {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+}
+
+// modifiedTime 返回条目的修改时间，未知时为零值
+func modifiedTime(entry Entry) time.Time {
+	if entry.LastModified == nil {
+		return time.Time{}
+	}
+	return *entry.LastModified
+}
+
+// SortURL 返回按字段排序的索引页地址，当前已按该字段升序时改为降序
+// 参数:
+//   - field: 排序字段
+// 返回:
+//   - string: 查询字符串
+func (index *Index) SortURL(field string) string {
+	order := "asc"
+	if index.Sort == field && index.Order == "asc" {
+		order = "desc"
+	}
+	return "?" + url.Values{SortParam: {field}, OrderParam: {order}}.Encode()
+}
+
+// PageURL 返回保持当前排序的指定页地址
+// 参数:
+//   - page: 页码
+// 返回:
+//   - string: 查询字符串
+func (index *Index) PageURL(page int) string {
+	return "?" + url.Values{SortParam: {index.Sort}, OrderParam: {index.Order}, PageParam: {strconv.Itoa(page)}}.Encode()
+}
+
+// indexTemplate 目录索引的HTML模板
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+	// 以 ./ 开头避免名称中的冒号被当作协议
+	"escape": func(name string) string {
+		return "./" + (&url.URL{Path: name}).EscapedPath()
+	},
+	"time": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Path}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.2em 1em; text-align: left; }
+td.size { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<tr><th><a href="{{.SortURL "name"}}">Name</a></th><th><a href="{{.SortURL "size"}}">Size</a></th><th><a href="{{.SortURL "modified"}}">Last modified</a></th></tr>
+{{if ne .Path "/"}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{escape .Name}}">{{.Name}}</a></td><td class="size">{{if not .Dir}}{{.Size}}{{end}}</td><td>{{time .LastModified}}</td></tr>
+{{end}}</table>
+<p>{{if gt .Page 1}}<a href="{{.PageURL (add .Page -1)}}">Previous</a> {{end}}Page {{.Page}} of {{.Pages}}, {{.Total}} entries{{if lt .Page .Pages}} <a href="{{.PageURL (add .Page 1)}}">Next</a>{{end}}</p>
+</body>
+</html>
+`))
@@ -0,0 +1,85 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestHandler(t *testing.T) {
+	storage := ossmock.New()
+	storage.Put("/docs/a.txt", strings.NewReader("aaa"))
+	storage.Put("/docs/b.txt", strings.NewReader("b"))
+	storage.Put("/docs/c <d>.txt", strings.NewReader("cc"))
+	storage.Put("/docs/sub/e.txt", strings.NewReader("e"))
+
+	handler := New(storage, Options{Index: true, PageSize: 2})
+	serve := func(method, target string, headers ...string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(method, target, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	if recorder := serve(http.MethodGet, "/docs/a.txt"); recorder.Code != http.StatusOK || recorder.Body.String() != "aaa" || recorder.Header().Get("Content-Length") != "3" {
+		t.Errorf("Object should be served, but got %v %q", recorder.Code, recorder.Body.String())
+	}
+	if recorder := serve(http.MethodGet, "/docs/missing.txt"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Missing object should return 404, but got %v", recorder.Code)
+	}
+	if recorder := serve(http.MethodGet, "/docs/a.txt"); recorder.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Object should be served with nosniff, but got %v", recorder.Header())
+	}
+	if recorder := serve(http.MethodGet, "/docs/a.txt", "Range", "bytes=1-"); recorder.Code != http.StatusPartialContent || recorder.Body.String() != "aa" {
+		t.Errorf("Range request should return partial content, but got %v %q", recorder.Code, recorder.Body.String())
+	}
+	etag := serve(http.MethodHead, "/docs/a.txt").Header().Get("ETag")
+	if recorder := serve(http.MethodGet, "/docs/a.txt", "If-None-Match", etag); etag == "" || recorder.Code != http.StatusNotModified {
+		t.Errorf("Conditional request with ETag %v should return 304, but got %v", etag, recorder.Code)
+	}
+	storage.FailWith("Stat", errors.Join(oss.ErrPermissionDenied, errors.New("bucket secret-bucket at internal.example.com")))
+	if recorder := serve(http.MethodGet, "/docs/a.txt"); recorder.Code != http.StatusForbidden || strings.Contains(recorder.Body.String(), "secret-bucket") {
+		t.Errorf("Backend errors should not be exposed, but got %v %q", recorder.Code, recorder.Body.String())
+	}
+	storage.FailWith("Stat", nil)
+	if recorder := serve(http.MethodPut, "/docs/a.txt"); recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Handler should be read-only, but got %v", recorder.Code)
+	}
+	if recorder := serve(http.MethodGet, "/docs/sub"); recorder.Code != http.StatusMovedPermanently || recorder.Header().Get("Location") != "sub/" {
+		t.Errorf("Directory without trailing slash should redirect, but got %v %v", recorder.Code, recorder.Header().Get("Location"))
+	}
+
+	var index Index
+	recorder := serve(http.MethodGet, "/docs/?format=json&sort=size&order=desc&page=2")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &index); err != nil {
+		t.Fatalf("No error should happen when decode index, but got %v", err)
+	}
+	// 子目录在前，文件按大小降序：sub/、a.txt | c <d>.txt、b.txt
+	if index.Total != 4 || index.Pages != 2 || len(index.Entries) != 2 || index.Entries[0].Name != "c <d>.txt" || index.Entries[1].Path != "/docs/b.txt" {
+		t.Errorf("JSON index should be sorted and paginated, but got %+v", index)
+	}
+
+	html := serve(http.MethodGet, "/docs/").Body.String()
+	for _, expected := range []string{`<a href="./sub/">sub/</a>`, `<a href="./a.txt">a.txt</a>`, `<a href="../">`, "Page 1 of 2", `sort=size`} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("HTML index should contain %v, but got %v", expected, html)
+		}
+	}
+	if html := serve(http.MethodGet, "/docs/?page=2").Body.String(); !strings.Contains(html, `<a href="./c%20%3Cd%3E.txt">c &lt;d&gt;.txt</a>`) {
+		t.Errorf("Names should be escaped, but got %v", html)
+	}
+
+	handler.Options.Index = false
+	if recorder := serve(http.MethodGet, "/docs/"); recorder.Code != http.StatusNotFound {
+		t.Errorf("Index should be disabled, but got %v", recorder.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package oss
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"sync"
+)
+
+// HTTPStatusMapper 将存储后端特有的错误转换为HTTP状态码
+// 无法识别该错误时返回false
+type HTTPStatusMapper func(err error) (int, bool)
+
+var (
+	httpStatusMappersMu sync.RWMutex
+	httpStatusMappers   []HTTPStatusMapper
+)
+
+// RegisterHTTPStatusMapper 注册存储后端错误到HTTP状态码的转换函数
+// 各存储后端在包初始化时注册自身SDK的错误类型，后注册的转换函数优先
+// 参数:
+//   - mapper: 转换函数
+func RegisterHTTPStatusMapper(mapper HTTPStatusMapper) {
+	httpStatusMappersMu.Lock()
+	defer httpStatusMappersMu.Unlock()
+	httpStatusMappers = append(httpStatusMappers, mapper)
+}
+
+// HTTPStatus 获取错误对应的HTTP状态码
+// 依次检查统一错误、实现了 HTTPStatus() int 或 StatusCode() int 的错误以及已注册的转换函数，
+// 都无法识别时返回500
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码，err为nil时返回200
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound), errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, fs.ErrPermission):
+		return http.StatusForbidden
+	case errors.Is(err, ErrConflict), errors.Is(err, fs.ErrExist):
+		return http.StatusConflict
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrKeyTooLong), errors.Is(err, ErrInvalidPath), errors.Is(err, ErrDeleteRoot), errors.Is(err, ErrChecksumMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidRange):
+		return http.StatusRequestedRangeNotSatisfiable
+	case errors.Is(err, ErrNotSupported):
+		return http.StatusNotImplemented
+	}
+
+	// 错误自身携带状态码
+	var statusError interface{ HTTPStatus() int }
+	if errors.As(err, &statusError) {
+		return normalizeHTTPStatus(statusError.HTTPStatus())
+	}
+	var codeError interface{ StatusCode() int }
+	if errors.As(err, &codeError) {
+		return normalizeHTTPStatus(codeError.StatusCode())
+	}
+
+	// 由存储后端注册的转换函数识别
+	httpStatusMappersMu.RLock()
+	defer httpStatusMappersMu.RUnlock()
+	for i := len(httpStatusMappers) - 1; i >= 0; i-- {
+		if status, ok := httpStatusMappers[i](err); ok {
+			return normalizeHTTPStatus(status)
+		}
+	}
+
+	return http.StatusInternalServerError
+}
+
+// normalizeHTTPStatus 将非错误状态码和非标准状态码统一为500
+func normalizeHTTPStatus(status int) int {
+	if status < 400 || status > 599 {
+		return http.StatusInternalServerError
+	}
+	return status
+}
@@ -0,0 +1,55 @@
+package huawei
+
+import (
+	"github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
+	"github.com/smart-unicom/oss"
+)
+
+// SetACL 使用预定义ACL设置对象的访问控制
+// 参数:
+//   - path: 对象路径
+//   - acl: 访问控制类型
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) SetACL(path string, acl oss.ACL) error {
+	input := &obs.SetObjectAclInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(path)
+	input.ACL = obs.AclType(acl)
+
+	_, err := client.OBS.SetObjectAcl(input)
+	return wrapError(err)
+}
+
+// GetACL 根据对象的授权列表获取访问控制
+// 参数:
+//   - path: 对象路径
+//
+// 返回:
+//   - oss.ACL: 访问控制类型
+//   - error: 错误信息
+func (client Client) GetACL(path string) (oss.ACL, error) {
+	input := &obs.GetObjectAclInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(path)
+
+	output, err := client.OBS.GetObjectAcl(input)
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	var publicRead, publicWrite, authenticatedRead bool
+	for _, grant := range output.Grants {
+		readable := grant.Permission == obs.PermissionRead || grant.Permission == obs.PermissionFullControl
+		writable := grant.Permission == obs.PermissionWrite || grant.Permission == obs.PermissionFullControl
+		switch grant.Grantee.URI {
+		case obs.GroupAllUsers:
+			publicRead = publicRead || readable
+			publicWrite = publicWrite || writable
+		case obs.GroupAuthenticatedUsers:
+			authenticatedRead = authenticatedRead || readable
+		}
+	}
+	return oss.ACLFromGrants(publicRead, publicWrite, authenticatedRead), nil
+}
@@ -3,7 +3,10 @@
 package huawei
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,6 +19,9 @@ import (
 // 确保Client实现了StorageInterface接口
 var _ oss.StorageInterface = (*Client)(nil)
 
+// maxKeyBytes 华为云OBS对象键的最大字节数
+const maxKeyBytes = 1024
+
 // Client 华为云OBS存储客户端
 // 封装华为云OBS的操作接口
 type Client struct {
@@ -40,6 +46,10 @@ type Config struct {
 	Bucket string
 	// SecurityToken 安全令牌（可选，用于临时访问凭证）
 	SecurityToken string
+	// HTTPClient 发送请求使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用SDK默认客户端
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，转换为SDK的重试设置，为nil时使用SDK默认重试策略
+	Retry *oss.RetryConfig
 }
 
 // New 初始化华为云OBS存储客户端
@@ -48,17 +58,40 @@ type Config struct {
 //
 // 返回:
 //   - *Client: 华为云OBS存储客户端实例
-func New(config *Config) *Client {
-	// 创建OBS客户端
-	obsClient, err := obs.New(config.SecretID, config.SecretKey, config.Endpoint)
+//   - error: 错误信息
+func New(config *Config) (*Client, error) {
+	// 未配置重试时，-1表示使用SDK默认的重试次数
+	maxRetryCount := -1
+	if config.Retry != nil {
+		maxRetryCount = config.Retry.Attempts() - 1
+	}
+
+	// 创建OBS客户端，HTTPClient为nil时由SDK创建默认客户端
+	obsClient, err := obs.New(config.SecretID, config.SecretKey, config.Endpoint, obs.WithHttpClient(config.HTTPClient), obs.WithMaxRetryCount(maxRetryCount))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	return &Client{
 		Config: config,
 		OBS:    obsClient,
+	}, nil
+}
+
+// MustNew 初始化华为云OBS存储客户端，失败时panic
+// 参数:
+//   - config: 华为云OBS配置信息
+//
+// 返回:
+//   - *Client: 华为云OBS存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		panic(err)
 	}
+	return client
 }
 
 // Get 获取指定路径的文件
@@ -74,17 +107,8 @@ func (client Client) Get(path string) (file *os.File, err error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建临时文件并复制内容
-	if file, err = os.CreateTemp("/tmp", "huaweicloud"); err == nil {
-		defer readCloser.Close()
-		// 将流内容复制到临时文件
-		_, err = io.Copy(file, readCloser)
-		// 重置文件指针到开始位置
-		file.Seek(0, 0)
-	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "huaweicloud")
 }
 
 // GetStream 获取指定路径文件的流
@@ -103,12 +127,85 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	// 使用OBS客户端获取对象
 	output, err := client.OBS.GetObject(input)
 	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return output.Body, nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用HTTP Range请求只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+//
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
 		return nil, err
 	}
 
+	input := &obs.GetObjectInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(path)
+
+	// RangeStart/RangeEnd 无法表示读取到末尾和单字节范围，直接设置Range头
+	output, err := client.OBS.GetObject(input, obs.WithCustomHeader("Range", oss.HTTPRange(offset, length)))
+	if err != nil {
+		return nil, wrapError(err)
+	}
 	return output.Body, nil
 }
 
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 构建获取对象元数据请求
+	input := &obs.GetObjectMetadataInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(path)
+
+	// 使用OBS客户端获取对象元数据
+	output, err := client.OBS.GetObjectMetadata(input)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Object{
+		Path:             path,
+		Name:             filepath.Base(path),
+		LastModified:     oss.NormalizeTime(output.LastModified),
+		Size:             output.ContentLength,
+		ContentType:      output.ContentType,
+		ETag:             output.ETag,
+		Metadata:         oss.NormalizeMetadata(output.Metadata),
+		StorageInterface: client,
+	}, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	_, err := client.Stat(path)
+	if errors.Is(err, oss.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // Put 上传文件到指定路径
 // 参数:
 //   - urlPath: 目标路径
@@ -118,30 +215,116 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传后的对象信息
 //   - error: 错误信息
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+//
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
 	// 如果是可寻址的读取器，重置到开始位置
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
+	// 按冲突策略确定上传路径
+	resolved, err := opts.ResolvePath(client, urlPath)
+	if err != nil {
+		return nil, err
+	}
+	urlPath = resolved
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(urlPath), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
 	// 构建上传对象请求
 	input := &obs.PutObjectInput{}
 	input.Bucket = client.Config.Bucket
 	input.Key = client.ToRelativePath(urlPath)
 	input.Body = reader
+	input.ContentType = opts.ContentType
+	input.ContentDisposition = opts.ContentDisposition
+	input.CacheControl = opts.CacheControl
+	input.Metadata = opts.Metadata
+	if opts.ACL != "" {
+		input.ACL = obs.AclType(opts.ACL)
+	}
+	// 发送Content-MD5由服务端校验内容
+	if opts.Checksum != "" {
+		if opts.Checksum != oss.ChecksumMD5 {
+			return nil, fmt.Errorf("%w: huawei does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+		}
+		body, checksum, err := opts.ReadChecksum(reader)
+		if err != nil {
+			return nil, err
+		}
+		input.Body = body
+		input.ContentMD5 = checksum
+	}
+
+	// 回调上传进度，包装后SDK无法识别内容长度，需要显式设置
+	if opts.Progress != nil {
+		size := oss.ReaderSize(input.Body)
+		if size >= 0 {
+			input.ContentLength = size
+		}
+		input.Body = opts.WrapReader(input.Body, size)
+	}
 
-	// 使用OBS客户端上传对象
-	_, err := client.OBS.PutObject(input)
+	// 使用OBS客户端上传对象，对象标签通过请求头设置
+	if len(opts.Tags) > 0 {
+		_, err = client.OBS.PutObject(input, obs.WithCustomHeader("x-obs-tagging", oss.EncodeTags(opts.Tags)))
+	} else {
+		_, err = client.OBS.PutObject(input)
+	}
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
-	now := time.Now()
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, nil
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if stat, err := client.Stat(urlPath); err == nil {
+		object.LastModified = stat.LastModified
+		object.Size = stat.Size
+	}
+
+	return object, nil
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 数据通过管道交给 Put 上传
+// 参数:
+//   - path: 目标路径
+//
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
 }
 
 // Delete 删除指定路径的文件
@@ -158,7 +341,125 @@ func (client Client) Delete(path string) error {
 
 	// 使用OBS客户端删除对象
 	_, err := client.OBS.DeleteObject(input)
-	return err
+	return wrapError(err)
+}
+
+// DeleteObjects 批量删除多个文件
+// 每次请求最多删除 oss.MaxDeleteObjects 个文件，多批请求并发执行
+// 参数:
+//   - paths: 文件路径列表
+//
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsInChunks(paths, oss.DefaultDeleteChunkConcurrency, func(chunk []string) map[string]error {
+		failures := map[string]error{}
+		// 构建批量删除请求，记录对象键对应的原始路径
+		input := &obs.DeleteObjectsInput{}
+		input.Bucket = client.Config.Bucket
+		input.Quiet = true
+		keys := map[string]string{}
+		for _, path := range chunk {
+			key := client.ToRelativePath(path)
+			keys[key] = path
+			input.Objects = append(input.Objects, obs.ObjectToDelete{Key: key})
+		}
+
+		// 静默模式下只返回删除失败的对象
+		output, err := client.OBS.DeleteObjects(input)
+		if err != nil {
+			for _, path := range chunk {
+				failures[path] = wrapError(err)
+			}
+			return failures
+		}
+
+		for _, deleteError := range output.Errors {
+			failures[keys[deleteError.Key]] = wrapError(obs.ObsError{Code: deleteError.Code, Message: deleteError.Message})
+		}
+		return failures
+	})
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分页列举目录下的对象，每页使用批量删除接口删除
+// 参数:
+//   - dir: 目录路径
+//
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(client.ToRelativePath(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	input := &obs.ListObjectsInput{}
+	input.Bucket = client.Config.Bucket
+	input.Prefix = prefix
+	input.MaxKeys = oss.MaxDeleteObjects
+	for {
+		output, err := client.OBS.ListObjects(input)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		var paths []string
+		for _, obj := range output.Contents {
+			paths = append(paths, obj.Key)
+		}
+		if len(paths) > 0 {
+			if err := client.DeleteObjects(paths); err != nil {
+				return err
+			}
+		}
+
+		if !output.IsTruncated {
+			return nil
+		}
+		input.Marker = output.NextMarker
+	}
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := oss.ValidateKeyLength(client.ToRelativePath(dstPath), maxKeyBytes, 0); err != nil {
+		return err
+	}
+
+	// 构建复制对象请求
+	input := &obs.CopyObjectInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(dstPath)
+	input.CopySourceBucket = client.Config.Bucket
+	input.CopySourceKey = client.ToRelativePath(srcPath)
+
+	// 使用服务端复制
+	_, err = client.OBS.CopyObject(input)
+	return wrapError(err)
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 对象存储不支持重命名，通过服务端复制后删除源文件实现
+	return oss.MoveByCopy(client, srcPath, dstPath)
 }
 
 // List 列出指定路径下的所有对象
@@ -179,7 +480,7 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 	// 使用OBS客户端列出对象
 	output, err := client.OBS.ListObjects(input)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	// 遍历对象列表并转换为统一格式
@@ -187,8 +488,9 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 		objects = append(objects, &oss.Object{
 			Path:             "/" + obj.Key,
 			Name:             filepath.Base(obj.Key),
-			LastModified:     &obj.LastModified,
+			LastModified:     oss.NormalizeTime(obj.LastModified),
 			Size:             obj.Size,
+			ETag:             obj.ETag,
 			StorageInterface: client,
 		})
 	}
@@ -196,6 +498,14 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 	return objects, nil
 }
 
+// Underlying 返回华为云OBS SDK的客户端实例，用于统一接口没有覆盖的功能
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - interface{}: *obs.ObsClient
+func (client Client) Underlying() interface{} {
+	return client.OBS
+}
+
 // GetEndpoint 获取存储服务的端点地址
 // 返回:
 //   - string: 端点地址
@@ -223,12 +533,44 @@ func (client Client) ToRelativePath(urlPath string) string {
 //   - string: 访问URL
 //   - error: 错误信息
 func (client Client) GetURL(path string) (string, error) {
+	return client.GetSignedURL(path, oss.SignedURLOptions{})
+}
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+//
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
 	// 构建生成预签名URL请求
 	input := &obs.CreateSignedUrlInput{}
 	input.Method = obs.HttpMethodGet
+	if opts.Method == http.MethodPut {
+		input.Method = obs.HttpMethodPut
+	}
 	input.Bucket = client.Config.Bucket
 	input.Key = client.ToRelativePath(path)
-	input.Expires = 3600 // 1小时有效期
+	input.Expires = int(opts.Expiry / time.Second)
+
+	// 设置响应头覆盖
+	input.QueryParams = map[string]string{}
+	if opts.ResponseContentType != "" {
+		input.QueryParams["response-content-type"] = opts.ResponseContentType
+	}
+	if opts.ResponseContentDisposition != "" {
+		input.QueryParams["response-content-disposition"] = opts.ResponseContentDisposition
+	}
+	if opts.ResponseCacheControl != "" {
+		input.QueryParams["response-cache-control"] = opts.ResponseCacheControl
+	}
 
 	// 生成预签名URL
 	output, err := client.OBS.CreateSignedUrl(input)
@@ -238,3 +580,81 @@ func (client Client) GetURL(path string) (string, error) {
 
 	return output.SignedUrl, nil
 }
+
+// GetUploadURL 生成预签名PUT直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+//
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+
+	// 构建生成预签名URL请求
+	input := &obs.CreateSignedUrlInput{}
+	input.Method = obs.HttpMethodPut
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(path)
+	input.Expires = int(opts.Expiry / time.Second)
+	input.Headers = opts.Headers()
+
+	// 生成预签名URL
+	output, err := client.OBS.CreateSignedUrl(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oss.UploadURL{
+		URL:       output.SignedUrl,
+		Method:    http.MethodPut,
+		Headers:   opts.Headers(),
+		ExpiresAt: oss.Now().Add(opts.Expiry),
+	}, nil
+}
+
+func init() {
+	oss.RegisterHTTPStatusMapper(httpStatus)
+}
+
+// httpStatus 将华为云OBS的错误转换为HTTP状态码
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码
+//   - bool: 是否识别该错误
+func httpStatus(err error) (int, bool) {
+	var obsError obs.ObsError
+	if errors.As(err, &obsError) {
+		return obsError.StatusCode, true
+	}
+	return 0, false
+}
+
+// wrapError 将华为云OBS的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+//
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var obsError obs.ObsError
+	if !errors.As(err, &obsError) {
+		return err
+	}
+
+	switch {
+	case obsError.Code == "NoSuchKey":
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case obsError.Code == "NoSuchBucket":
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case obsError.Code == "AccessDenied" || obsError.StatusCode == http.StatusForbidden:
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	case obsError.Code == "InvalidDigest" || obsError.Code == "BadDigest":
+		return oss.WrapError(oss.ErrChecksumMismatch, err)
+	case obsError.StatusCode == http.StatusNotFound:
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	}
+	return err
+}
@@ -26,7 +26,10 @@ func TestHuawei(t *testing.T) {
 	}
 
 	// 创建华为云OBS客户端
-	client := huawei.New(config)
+	client, err := huawei.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// 运行通用测试
 	tests.TestAll(client, t)
@@ -0,0 +1,123 @@
+package huawei
+
+import (
+	"io"
+	"path/filepath"
+
+	obs "github.com/huaweicloud/huaweicloud-sdk-go-obs/obs"
+	"github.com/smart-unicom/oss"
+)
+
+// InitiateMultipart 初始化分片上传
+// 参数:
+//   - urlPath: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值
+//
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(urlPath string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(urlPath), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	// 构建初始化分片上传请求
+	input := &obs.InitiateMultipartUploadInput{}
+	input.Bucket = client.Config.Bucket
+	input.Key = client.ToRelativePath(urlPath)
+	input.ContentType = opts.ContentType
+	input.ContentDisposition = opts.ContentDisposition
+	input.CacheControl = opts.CacheControl
+	input.Metadata = opts.Metadata
+	if opts.ACL != "" {
+		input.ACL = obs.AclType(opts.ACL)
+	}
+
+	output, err := client.OBS.InitiateMultipartUpload(input)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.MultipartUpload{Path: urlPath, UploadID: output.UploadId, Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+//
+// 返回:
+//   - *oss.Part: 已上传的分片
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	output, err := client.OBS.UploadPart(&obs.UploadPartInput{
+		Bucket:     client.Config.Bucket,
+		Key:        client.ToRelativePath(upload.Path),
+		UploadId:   upload.UploadID,
+		PartNumber: number,
+		Body:       reader,
+		PartSize:   size,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: number, ETag: output.ETag, Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号合并分片，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+//
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	oss.SortParts(parts)
+	input := &obs.CompleteMultipartUploadInput{
+		Bucket:   client.Config.Bucket,
+		Key:      client.ToRelativePath(upload.Path),
+		UploadId: upload.UploadID,
+	}
+	for _, part := range parts {
+		input.Parts = append(input.Parts, obs.Part{PartNumber: part.Number, ETag: part.ETag})
+	}
+
+	if _, err := client.OBS.CompleteMultipartUpload(input); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(upload.Path); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: upload.Path, Name: filepath.Base(upload.Path), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传并释放已上传的分片
+// 参数:
+//   - upload: 分片上传会话
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	_, err := client.OBS.AbortMultipartUpload(&obs.AbortMultipartUploadInput{
+		Bucket:   client.Config.Bucket,
+		Key:      client.ToRelativePath(upload.Path),
+		UploadId: upload.UploadID,
+	})
+	return wrapError(err)
+}
@@ -0,0 +1,15 @@
+package huawei
+
+import "strings"
+
+// BucketRegion 查询存储桶所在的区域
+// 返回:
+//   - string: 区域名，例如 cn-north-4、eu-west-101
+//   - error: 错误信息
+func (client Client) BucketRegion() (string, error) {
+	output, err := client.OBS.GetBucketLocation(client.Config.Bucket)
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return strings.ToLower(output.Location), nil
+}
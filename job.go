@@ -0,0 +1,426 @@
+package oss
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	pathpkg "path"
+	"sync"
+	"time"
+)
+
+// DefaultJobCheckpointEvery 任务默认每完成多少个对象保存一次进度
+const DefaultJobCheckpointEvery = 100
+
+// ErrJobCanceled 任务被取消
+var ErrJobCanceled = errors.New("oss: job canceled")
+
+// JobState 任务状态
+type JobState string
+
+const (
+	// JobPending 任务尚未开始
+	JobPending JobState = "pending"
+	// JobRunning 任务正在执行
+	JobRunning JobState = "running"
+	// JobPaused 任务已暂停，正在处理的对象完成后不再开始新的对象
+	JobPaused JobState = "paused"
+	// JobCanceled 任务已取消
+	JobCanceled JobState = "canceled"
+	// JobCompleted 任务已处理完全部对象
+	JobCompleted JobState = "completed"
+)
+
+// JobProgress 任务进度
+type JobProgress struct {
+	// State 任务状态
+	State JobState
+	// Total 对象总数
+	Total int
+	// Completed 成功处理的对象数，包括之前的运行中已完成的对象
+	Completed int
+	// Failed 本次运行中失败的对象数
+	Failed int
+	// Active 正在处理的对象数，暂停或取消后降为0时表示任务已停下
+	Active int
+	// Bytes 已传输的字节数，包括之前的运行中传输的字节数
+	Bytes int64
+}
+
+// JobCheckpoint 保存的任务进度，进程重启后用于跳过已完成的对象
+type JobCheckpoint struct {
+	// ID 任务标识
+	ID string `json:"id"`
+	// State 保存时的任务状态
+	State JobState `json:"state"`
+	// Completed 已成功处理的对象路径
+	Completed []string `json:"completed"`
+	// Failed 失败的对象路径和错误信息，再次运行时会重试
+	Failed map[string]string `json:"failed,omitempty"`
+	// Bytes 已传输的字节数
+	Bytes int64 `json:"bytes"`
+	// UpdatedAt 保存时间
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobStore 保存任务进度的存储
+type JobStore interface {
+	// LoadJob 读取任务进度
+	// 参数:
+	//   - id: 任务标识
+	// 返回:
+	//   - *JobCheckpoint: 任务进度，没有保存过时返回nil
+	//   - error: 错误信息
+	LoadJob(id string) (*JobCheckpoint, error)
+	// SaveJob 保存任务进度
+	// 参数:
+	//   - checkpoint: 任务进度
+	// 返回:
+	//   - error: 错误信息
+	SaveJob(checkpoint *JobCheckpoint) error
+}
+
+// StorageJobStore 将任务进度以JSON文件保存在存储中的任务进度存储，
+// 例如保存在本地文件系统或另一个存储桶中，迁移源和目标之外的位置
+type StorageJobStore struct {
+	// Storage 保存进度文件的存储
+	Storage StorageInterface
+	// Prefix 进度文件所在的目录
+	Prefix string
+}
+
+// jobPath 返回任务进度文件的路径
+func (store *StorageJobStore) jobPath(id string) string {
+	return pathpkg.Join("/", store.Prefix, id+".json")
+}
+
+// LoadJob 读取任务进度文件
+// 参数:
+//   - id: 任务标识
+// 返回:
+//   - *JobCheckpoint: 任务进度，进度文件不存在时返回nil
+//   - error: 错误信息
+func (store *StorageJobStore) LoadJob(id string) (*JobCheckpoint, error) {
+	stream, err := store.Storage.GetStream(store.jobPath(id))
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var checkpoint JobCheckpoint
+	if err := json.NewDecoder(stream).Decode(&checkpoint); err != nil {
+		return nil, fmt.Errorf("decode checkpoint of job %s: %w", id, err)
+	}
+	return &checkpoint, nil
+}
+
+// SaveJob 上传任务进度文件
+// 参数:
+//   - checkpoint: 任务进度
+// 返回:
+//   - error: 错误信息
+func (store *StorageJobStore) SaveJob(checkpoint *JobCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	_, err = store.Storage.Put(store.jobPath(checkpoint.ID), bytes.NewReader(data))
+	return err
+}
+
+// JobOptions 任务配置
+type JobOptions struct {
+	// ID 任务标识，设置了 Store 时用于读取和保存进度
+	ID string
+	// Concurrency 并发数，小于等于0时使用 DefaultBatchConcurrency
+	Concurrency int
+	// Store 保存进度的存储，为nil时不保存进度
+	Store JobStore
+	// CheckpointEvery 每完成多少个对象保存一次进度，小于等于0时使用 DefaultJobCheckpointEvery，
+	// 暂停、取消和结束时总是保存
+	CheckpointEvery int
+}
+
+// Job 可以暂停、继续和取消的批量任务，例如持续数天的迁移和清理
+// 设置了 Store 时定期保存已完成的对象，进程重启后使用相同的 ID 创建任务，
+// Run 会跳过已完成的对象，失败的对象会重试
+type Job struct {
+	// Options 任务配置
+	Options JobOptions
+
+	paths []string
+	fn    func(path string) (int64, error)
+
+	mu        sync.Mutex
+	resumed   *sync.Cond
+	state     JobState
+	started   bool
+	completed []string
+	failed    map[string]error
+	bytes     int64
+	active    int
+	cursor    int
+	remaining []int
+	unsaved   int
+	saveMu    sync.Mutex
+	saveErr   error
+}
+
+// NewJob 创建对每个路径执行操作的批量任务
+// 参数:
+//   - paths: 文件路径列表
+//   - opts: 任务配置
+//   - fn: 处理单个对象的操作，返回传输的字节数
+// 返回:
+//   - *Job: 任务实例，调用 Run 开始执行
+func NewJob(paths []string, opts JobOptions, fn func(path string) (int64, error)) *Job {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBatchConcurrency
+	}
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = DefaultJobCheckpointEvery
+	}
+	job := &Job{Options: opts, paths: paths, fn: fn, state: JobPending, failed: map[string]error{}}
+	job.resumed = sync.NewCond(&job.mu)
+	return job
+}
+
+// MigrateJob 创建将一组对象从源存储复制到目标存储的任务，与 Migrate 的处理相同
+// 参数:
+//   - source: 源存储
+//   - destination: 目标存储
+//   - paths: 文件路径列表
+//   - opts: 任务配置
+// 返回:
+//   - *Job: 任务实例
+func MigrateJob(source, destination StorageInterface, paths []string, opts JobOptions) *Job {
+	return NewJob(paths, opts, migrateObject(source, destination))
+}
+
+// DeleteJob 创建逐个删除一组对象的任务，例如清理不再引用的对象，与 DeleteAll 的处理相同
+// 参数:
+//   - storage: 存储接口
+//   - paths: 文件路径列表
+//   - opts: 任务配置
+// 返回:
+//   - *Job: 任务实例
+func DeleteJob(storage StorageInterface, paths []string, opts JobOptions) *Job {
+	return NewJob(paths, opts, deleteObject(storage))
+}
+
+// Run 执行任务，直到处理完全部对象或任务被取消
+// 参数: 无
+// 返回:
+//   - []BatchResult: 本次运行处理的对象的结果，按paths顺序排列，不包括之前已完成的对象
+//   - error: 任务被取消时返回 ErrJobCanceled，保存进度失败时返回保存的错误，单个对象的失败只记录在结果中
+func (job *Job) Run() ([]BatchResult, error) {
+	if err := job.restore(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(job.paths))
+	var wg sync.WaitGroup
+	for i := 0; i < job.Options.Concurrency && i < len(job.remaining); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				index, ok := job.next()
+				if !ok {
+					return
+				}
+				start := time.Now()
+				n, err := job.fn(job.paths[index])
+				results[index] = BatchResult{Path: job.paths[index], Err: err, Bytes: n, Duration: time.Since(start)}
+				job.finish(index, n, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	job.mu.Lock()
+	canceled := job.state == JobCanceled
+	if !canceled {
+		job.state = JobCompleted
+	}
+	job.mu.Unlock()
+	job.save()
+
+	processed := make([]BatchResult, 0, len(job.remaining))
+	for _, index := range job.remaining {
+		if results[index].Path != "" {
+			processed = append(processed, results[index])
+		}
+	}
+
+	job.saveMu.Lock()
+	err := job.saveErr
+	job.saveMu.Unlock()
+	if canceled {
+		err = errors.Join(ErrJobCanceled, err)
+	}
+	return processed, err
+}
+
+// restore 读取保存的进度，确定本次运行需要处理的对象
+func (job *Job) restore() error {
+	var checkpoint *JobCheckpoint
+	if job.Options.Store != nil && job.Options.ID != "" {
+		var err error
+		if checkpoint, err = job.Options.Store.LoadJob(job.Options.ID); err != nil {
+			return fmt.Errorf("load checkpoint of job %s: %w", job.Options.ID, err)
+		}
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.started {
+		return fmt.Errorf("job %s has already been run", job.Options.ID)
+	}
+	job.started = true
+	switch job.state {
+	case JobPending:
+		job.state = JobRunning
+	case JobCanceled:
+		return ErrJobCanceled
+	}
+
+	done := map[string]bool{}
+	if checkpoint != nil {
+		for _, path := range checkpoint.Completed {
+			done[path] = true
+		}
+		job.completed = append(job.completed, checkpoint.Completed...)
+		job.bytes = checkpoint.Bytes
+	}
+	for index, path := range job.paths {
+		if !done[path] {
+			job.remaining = append(job.remaining, index)
+		}
+	}
+	return nil
+}
+
+// next 返回下一个要处理的对象，暂停时等待继续，取消或没有剩余对象时返回false
+func (job *Job) next() (int, bool) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	for job.state == JobPaused {
+		job.resumed.Wait()
+	}
+	if job.state == JobCanceled || job.cursor >= len(job.remaining) {
+		return 0, false
+	}
+	index := job.remaining[job.cursor]
+	job.cursor++
+	job.active++
+	return index, true
+}
+
+// finish 记录对象的处理结果，达到间隔时保存进度
+func (job *Job) finish(index int, n int64, err error) {
+	path := job.paths[index]
+	job.mu.Lock()
+	job.active--
+	if err != nil {
+		job.failed[path] = err
+	} else {
+		delete(job.failed, path)
+		job.completed = append(job.completed, path)
+		job.bytes += n
+	}
+	job.unsaved++
+	// 暂停后最后一个正在处理的对象完成时保存，使保存的进度包括暂停前开始的对象
+	checkpoint := job.unsaved >= job.Options.CheckpointEvery || (job.state == JobPaused && job.active == 0)
+	job.mu.Unlock()
+
+	if checkpoint {
+		job.save()
+	}
+}
+
+// save 保存当前进度，未设置 Store 时不做任何事
+func (job *Job) save() {
+	if job.Options.Store == nil || job.Options.ID == "" {
+		return
+	}
+	// 保存按顺序进行，后保存的进度总是更新
+	job.saveMu.Lock()
+	defer job.saveMu.Unlock()
+
+	job.mu.Lock()
+	checkpoint := &JobCheckpoint{
+		ID:        job.Options.ID,
+		State:     job.state,
+		Completed: append([]string(nil), job.completed...),
+		Bytes:     job.bytes,
+		UpdatedAt: Now(),
+	}
+	if len(job.failed) > 0 {
+		checkpoint.Failed = make(map[string]string, len(job.failed))
+		for path, err := range job.failed {
+			checkpoint.Failed[path] = err.Error()
+		}
+	}
+	job.unsaved = 0
+	job.mu.Unlock()
+
+	if err := job.Options.Store.SaveJob(checkpoint); err != nil {
+		job.saveErr = fmt.Errorf("save checkpoint of job %s: %w", job.Options.ID, err)
+	}
+}
+
+// Pause 暂停任务，正在处理的对象完成后不再开始新的对象，并在这些对象完成后保存进度
+// 任务开始前调用时 Run 从暂停状态开始，已结束的任务不受影响
+func (job *Job) Pause() {
+	job.mu.Lock()
+	if job.state != JobPending && job.state != JobRunning {
+		job.mu.Unlock()
+		return
+	}
+	job.state = JobPaused
+	job.mu.Unlock()
+	job.save()
+}
+
+// Resume 继续已暂停的任务
+func (job *Job) Resume() {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.state == JobPaused {
+		job.state = JobRunning
+		job.resumed.Broadcast()
+	}
+}
+
+// Cancel 取消任务，正在处理的对象完成后 Run 返回 ErrJobCanceled
+// 已保存的进度不会删除，之后使用相同的 ID 运行仍会跳过已完成的对象
+func (job *Job) Cancel() {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.state == JobCompleted || job.state == JobCanceled {
+		return
+	}
+	job.state = JobCanceled
+	job.resumed.Broadcast()
+}
+
+// Progress 返回任务进度
+// 返回:
+//   - JobProgress: 任务进度
+func (job *Job) Progress() JobProgress {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return JobProgress{
+		State:     job.state,
+		Total:     len(job.paths),
+		Completed: len(job.completed),
+		Failed:    len(job.failed),
+		Active:    job.active,
+		Bytes:     job.bytes,
+	}
+}
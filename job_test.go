@@ -0,0 +1,82 @@
+package oss_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestJob(t *testing.T) {
+	source, destination := ossmock.New(), ossmock.New()
+	store := &oss.StorageJobStore{Storage: ossmock.New(), Prefix: "jobs"}
+	var paths []string
+	for i := 1; i <= 6; i++ {
+		path := fmt.Sprintf("/objects/%d.txt", i)
+		paths = append(paths, path)
+		source.Put(path, strings.NewReader(path))
+	}
+
+	var (
+		job       *oss.Job
+		processed []string
+	)
+	opts := oss.JobOptions{ID: "migrate-1", Concurrency: 1, Store: store}
+	job = oss.NewJob(paths, opts, func(path string) (int64, error) {
+		processed = append(processed, path)
+		switch len(processed) {
+		case 2:
+			job.Pause()
+		case 4:
+			job.Cancel()
+		}
+		return 1, nil
+	})
+
+	done := make(chan error)
+	go func() {
+		results, err := job.Run()
+		if len(results) != 4 {
+			t.Errorf("Canceled job should return the results of processed objects, but got %v", len(results))
+		}
+		done <- err
+	}()
+
+	for progress := job.Progress(); progress.State != oss.JobPaused || progress.Active != 0 || progress.Completed != 2; progress = job.Progress() {
+		time.Sleep(time.Millisecond)
+	}
+	if checkpoint, err := store.LoadJob("migrate-1"); err != nil || checkpoint.State != oss.JobPaused || len(checkpoint.Completed) != 2 {
+		t.Errorf("Pause should save the checkpoint, but got %+v, %v", checkpoint, err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if len(processed) != 2 {
+		t.Errorf("Paused job should not start new objects, but processed %v", processed)
+	}
+	job.Resume()
+	if err := <-done; !errors.Is(err, oss.ErrJobCanceled) {
+		t.Errorf("Canceled job should return ErrJobCanceled, but got %v", err)
+	}
+
+	// 进程重启后使用相同的ID继续，跳过已完成的对象
+	job = oss.MigrateJob(source, destination, paths, opts)
+	results, err := job.Run()
+	if err != nil || len(results) != 2 || results[0].Path != "/objects/5.txt" || results[1].Bytes != int64(len("/objects/6.txt")) {
+		t.Errorf("Restarted job should only process remaining objects, but got %+v, %v", results, err)
+	}
+	if exists, _ := destination.Exists("/objects/4.txt"); exists {
+		t.Errorf("Completed objects should be skipped")
+	}
+	if progress := job.Progress(); progress.State != oss.JobCompleted || progress.Completed != 6 {
+		t.Errorf("Job should be completed, but got %+v", progress)
+	}
+	if _, err := job.Run(); err == nil {
+		t.Errorf("Job should not run twice")
+	}
+	if checkpoint, _ := store.LoadJob("migrate-1"); checkpoint.State != oss.JobCompleted || len(checkpoint.Completed) != 6 {
+		t.Errorf("Completed job should be saved, but got %+v", checkpoint)
+	}
+}
@@ -0,0 +1,136 @@
+package oss
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// 临时对象的标签，配合生命周期规则自动过期
+const (
+	// TempTagKey 临时对象标签的键
+	TempTagKey = "temp"
+	// TempTagValue 临时对象标签的值
+	TempTagValue = "true"
+	// TempLifecycleRuleID 临时对象过期规则的ID
+	TempLifecycleRuleID = "oss-temp-expiration"
+)
+
+// LifecycleRule 按前缀和对象标签自动删除对象的生命周期规则
+type LifecycleRule struct {
+	// ID 规则ID，同一存储桶内唯一
+	ID string
+	// Prefix 对象路径前缀，为空时匹配全部对象
+	Prefix string
+	// Tags 对象需要同时匹配的标签，为空时只按前缀匹配
+	Tags map[string]string
+	// ExpirationDays 对象最后修改后经过的天数，到期后被服务端删除
+	ExpirationDays int
+}
+
+// LifecycleManager 存储桶生命周期规则管理接口
+// s3、aliyun、tencent和huawei实现了该接口，调用方通过类型断言判断是否支持
+type LifecycleManager interface {
+	// PutLifecycleRule 添加生命周期规则，已有相同ID的规则时替换，其它规则保持不变
+	// 参数:
+	//   - rule: 生命周期规则
+	// 返回:
+	//   - error: 错误信息，后端不支持规则中的条件时返回 ErrNotSupported
+	PutLifecycleRule(rule LifecycleRule) error
+
+	// DeleteLifecycleRule 删除指定ID的生命周期规则，规则不存在时不返回错误
+	// 参数:
+	//   - id: 规则ID
+	// 返回:
+	//   - error: 错误信息
+	DeleteLifecycleRule(id string) error
+}
+
+// Temporary 返回带有临时对象标签的上传选项副本
+// 参数:
+//   - opts: 上传选项，可以为nil
+// 返回:
+//   - *PutOptions: 添加了 TempTagKey 标签的上传选项
+func Temporary(opts *PutOptions) *PutOptions {
+	var temporary PutOptions
+	if opts != nil {
+		temporary = *opts
+	}
+	tags := make(map[string]string, len(temporary.Tags)+1)
+	for key, value := range temporary.Tags {
+		tags[key] = value
+	}
+	tags[TempTagKey] = TempTagValue
+	temporary.Tags = tags
+	return &temporary
+}
+
+// TempLifecycleRule 创建删除临时对象的生命周期规则
+// 参数:
+//   - prefix: 对象路径前缀，为空时匹配全部对象
+//   - days: 对象保留天数
+// 返回:
+//   - LifecycleRule: 匹配 TempTagKey 标签的生命周期规则
+func TempLifecycleRule(prefix string, days int) LifecycleRule {
+	return LifecycleRule{
+		ID:             TempLifecycleRuleID,
+		Prefix:         prefix,
+		Tags:           map[string]string{TempTagKey: TempTagValue},
+		ExpirationDays: days,
+	}
+}
+
+// ExpireTemporary 在存储桶上配置临时对象的过期规则
+// 参数:
+//   - storage: 存储接口
+//   - prefix: 对象路径前缀，为空时匹配全部对象
+//   - days: 对象保留天数
+// 返回:
+//   - error: 错误信息，存储不支持生命周期规则时返回 ErrNotSupported
+func ExpireTemporary(storage StorageInterface, prefix string, days int) error {
+	manager, ok := storage.(LifecycleManager)
+	if !ok {
+		return fmt.Errorf("%w: %T does not support lifecycle rules", ErrNotSupported, storage)
+	}
+	return manager.PutLifecycleRule(TempLifecycleRule(prefix, days))
+}
+
+// Validate 检查生命周期规则是否完整
+// 返回:
+//   - error: 缺少ID或过期天数时返回错误
+func (rule LifecycleRule) Validate() error {
+	if rule.ID == "" {
+		return fmt.Errorf("lifecycle rule id is required")
+	}
+	if rule.ExpirationDays <= 0 {
+		return fmt.Errorf("lifecycle rule %s: expiration days must be positive, got %d", rule.ID, rule.ExpirationDays)
+	}
+	return nil
+}
+
+// EncodeTags 将对象标签编码为 x-amz-tagging 等请求头使用的URL查询字符串
+// 参数:
+//   - tags: 对象标签
+// 返回:
+//   - string: 按键排序的查询字符串，标签为空时返回空字符串
+func EncodeTags(tags map[string]string) string {
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
+
+// SortedTagKeys 返回按字典序排序的标签键，用于生成稳定的请求
+// 参数:
+//   - tags: 对象标签
+// 返回:
+//   - []string: 排序后的标签键
+func SortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,35 @@
+package oss_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestTemporary(t *testing.T) {
+	opts := &oss.PutOptions{ContentType: "text/plain", Tags: map[string]string{"owner": "a b"}}
+	temporary := oss.Temporary(opts)
+	if temporary.ContentType != "text/plain" || temporary.Tags[oss.TempTagKey] != oss.TempTagValue || temporary.Tags["owner"] != "a b" {
+		t.Errorf("Temporary should keep options and add temp tag, but got %+v", temporary)
+	}
+	if _, ok := opts.Tags[oss.TempTagKey]; ok {
+		t.Errorf("Temporary should not modify the original options")
+	}
+	if encoded := oss.EncodeTags(temporary.Tags); encoded != "owner=a+b&temp=true" {
+		t.Errorf("Tags should be encoded as sorted query string, but got %v", encoded)
+	}
+
+	fileSystem := filesystem.New(t.TempDir())
+	if _, err := fileSystem.PutWithOptions("/a.txt", strings.NewReader("sample"), temporary); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Filesystem should not support object tags, but got %v", err)
+	}
+	if err := oss.ExpireTemporary(fileSystem, "/uploads", 1); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Filesystem should not support lifecycle rules, but got %v", err)
+	}
+	if err := oss.TempLifecycleRule("/uploads", 0).Validate(); err == nil {
+		t.Errorf("Lifecycle rule without expiration days should be invalid")
+	}
+}
@@ -0,0 +1,139 @@
+package oss
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ListOptions 列出对象的选项
+type ListOptions struct {
+	// Recursive 为true时列出目录下的全部对象，为false时只列出直接子对象，子目录作为 ListResult.Prefixes 返回
+	Recursive bool
+	// Glob 对象名称需要匹配的模式，使用 path.Match 语法，例如 *.jpg，只匹配名称不含目录
+	Glob string
+	// Pattern 对象相对于列出目录的路径需要匹配的正则表达式，例如 ^2024/.*\.log$
+	Pattern *regexp.Regexp
+	// MaxResults 最多返回的对象和子目录数量，小于等于0时不限制
+	MaxResults int
+}
+
+// ListResult 按选项列出对象的结果
+type ListResult struct {
+	// Objects 对象列表，按路径字典序排列
+	Objects []*Object
+	// Prefixes 非递归列出时的直接子目录，以斜杠开头和结尾，例如 /users/a/，按字典序排列
+	Prefixes []string
+	// Truncated 是否因为 MaxResults 省略了部分结果
+	Truncated bool
+}
+
+// OptionsLister 原生支持按选项列出对象的存储后端
+// 例如使用分隔符列出的对象存储，或只能逐级列出目录的NAS
+type OptionsLister interface {
+	// ListWithOptions 按选项列出指定路径下的对象
+	// 参数:
+	//   - path: 目录路径
+	//   - opts: 列出选项
+	// 返回:
+	//   - *ListResult: 列出结果
+	//   - error: 错误信息
+	ListWithOptions(path string, opts ListOptions) (*ListResult, error)
+}
+
+// ListWithOptions 按选项列出指定路径下的对象
+// 存储后端实现了 OptionsLister 时使用原生实现，否则将 List 的结果按选项分组和过滤，
+// 各后端的 List 有的递归、有的只列出一级，通过此函数可以得到一致的结果
+// 参数:
+//   - storage: 存储接口
+//   - path: 目录路径
+//   - opts: 列出选项
+// 返回:
+//   - *ListResult: 列出结果
+//   - error: 错误信息
+func ListWithOptions(storage StorageInterface, path string, opts ListOptions) (*ListResult, error) {
+	if lister, ok := storage.(OptionsLister); ok {
+		return lister.ListWithOptions(path, opts)
+	}
+	objects, err := storage.List(path)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Result(path, objects, nil), nil
+}
+
+// Match 判断对象是否匹配名称模式和路径正则表达式
+// 参数:
+//   - relative: 对象相对于列出目录的路径，不以斜杠开头
+// 返回:
+//   - bool: 是否匹配
+func (opts ListOptions) Match(relative string) bool {
+	if opts.Glob != "" {
+		if matched, err := path.Match(opts.Glob, path.Base(relative)); err != nil || !matched {
+			return false
+		}
+	}
+	return opts.Pattern == nil || opts.Pattern.MatchString(relative)
+}
+
+// Result 将后端返回的对象和子目录按选项整理为列出结果
+// 非递归时更深层级的对象归并为直接子目录，不在目录下的对象（例如 /users/ab 之于 /users/a）被排除，
+// 用于实现 OptionsLister 的后端和 ListWithOptions 的通用实现
+// 参数:
+//   - dir: 列出的目录路径
+//   - objects: 后端返回的对象
+//   - prefixes: 后端返回的子目录，例如使用分隔符列出时的公共前缀，可以为nil
+// 返回:
+//   - *ListResult: 列出结果
+func (opts ListOptions) Result(dir string, objects []*Object, prefixes []string) *ListResult {
+	base := DirPrefix(dir)
+	result := &ListResult{}
+	seen := map[string]bool{}
+	addPrefix := func(prefix string) {
+		prefix = "/" + strings.Trim(prefix, "/") + "/"
+		if !seen[prefix] {
+			seen[prefix] = true
+			result.Prefixes = append(result.Prefixes, prefix)
+		}
+	}
+
+	for _, prefix := range prefixes {
+		addPrefix(prefix)
+	}
+	for _, object := range objects {
+		key := strings.TrimPrefix(object.Path, "/")
+		if !strings.HasPrefix(key, base) || key == base {
+			continue
+		}
+		relative := key[len(base):]
+		if i := strings.Index(relative, "/"); i >= 0 && !opts.Recursive {
+			addPrefix(base + relative[:i])
+			continue
+		}
+		if opts.Match(relative) {
+			result.Objects = append(result.Objects, object)
+		}
+	}
+
+	SortObjects(result.Objects)
+	sort.Strings(result.Prefixes)
+	if opts.MaxResults > 0 && len(result.Objects)+len(result.Prefixes) > opts.MaxResults {
+		result.Truncated = true
+		result.Objects, result.Prefixes = truncateListing(result.Objects, result.Prefixes, opts.MaxResults)
+	}
+	return result
+}
+
+// truncateListing 按路径字典序合并对象和子目录，保留前n个
+func truncateListing(objects []*Object, prefixes []string, n int) ([]*Object, []string) {
+	i, j := 0, 0
+	for i+j < n {
+		if j >= len(prefixes) || i < len(objects) && strings.TrimPrefix(objects[i].Path, "/") < strings.TrimPrefix(prefixes[j], "/") {
+			i++
+		} else {
+			j++
+		}
+	}
+	return objects[:i], prefixes[:j]
+}
@@ -0,0 +1,36 @@
+package oss_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestListWithOptions(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	for _, path := range []string{"/users/a.jpg", "/users/b.txt", "/users/x/c.jpg", "/users/y/z/d.jpg", "/usersx/e.jpg"} {
+		fileSystem.Put(path, strings.NewReader("sample"))
+	}
+
+	result, err := oss.ListWithOptions(fileSystem, "/users", oss.ListOptions{})
+	if err != nil {
+		t.Fatalf("No error should happen when list, but got %v", err)
+	}
+	if len(result.Objects) != 2 || result.Objects[0].Path != "/users/a.jpg" ||
+		len(result.Prefixes) != 2 || result.Prefixes[0] != "/users/x/" || result.Prefixes[1] != "/users/y/" {
+		t.Errorf("Non-recursive list should return direct children and sub directories, but got %+v", result)
+	}
+
+	result, _ = oss.ListWithOptions(fileSystem, "/users", oss.ListOptions{Recursive: true, Glob: "*.jpg"})
+	if len(result.Objects) != 3 || len(result.Prefixes) != 0 || result.Objects[2].Path != "/users/y/z/d.jpg" {
+		t.Errorf("Recursive list should filter names by glob, but got %+v", result.Objects)
+	}
+
+	result, _ = oss.ListWithOptions(fileSystem, "/users", oss.ListOptions{Recursive: true, Pattern: regexp.MustCompile(`^[xy]/`), MaxResults: 1})
+	if len(result.Objects) != 1 || result.Objects[0].Path != "/users/x/c.jpg" || !result.Truncated {
+		t.Errorf("List should apply regex filter and MaxResults, but got %+v", result)
+	}
+}
@@ -0,0 +1,181 @@
+// Package metrics 存储用量指标
+// 定时统计配置的路径前缀下的对象数量和总大小，并以仪表盘指标的形式发布
+package metrics
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultInterval 默认的统计间隔
+const DefaultInterval = 10 * time.Minute
+
+// 发布的指标名称
+const (
+	// GaugeObjects 前缀下的对象数量
+	GaugeObjects = "oss_prefix_objects"
+	// GaugeBytes 前缀下的对象总大小，单位字节
+	GaugeBytes = "oss_prefix_bytes"
+	// GaugeLastModified 前缀下最近一次修改时间的Unix时间戳，单位秒
+	GaugeLastModified = "oss_prefix_last_modified_seconds"
+)
+
+// Sink 指标发布目标，可以适配Prometheus、StatsD等监控系统
+type Sink interface {
+	// SetGauge 设置仪表盘指标的值
+	// 参数:
+	//   - name: 指标名称
+	//   - prefix: 统计的路径前缀
+	//   - value: 指标值
+	SetGauge(name, prefix string, value float64)
+}
+
+// SinkFunc 函数形式的指标发布目标
+type SinkFunc func(name, prefix string, value float64)
+
+// SetGauge 调用函数设置仪表盘指标的值
+func (fn SinkFunc) SetGauge(name, prefix string, value float64) {
+	fn(name, prefix, value)
+}
+
+// ExpvarSink 将指标发布到标准库expvar，可以通过 /debug/vars 查看
+type ExpvarSink struct {
+	mutex  sync.Mutex
+	gauges map[string]*expvar.Map
+}
+
+// NewExpvarSink 创建expvar指标发布目标
+// 返回:
+//   - *ExpvarSink: 指标发布目标实例
+func NewExpvarSink() *ExpvarSink {
+	return &ExpvarSink{gauges: map[string]*expvar.Map{}}
+}
+
+// SetGauge 设置仪表盘指标的值，每个指标名称对应一个以前缀为键的expvar.Map
+// expvar不允许重复注册，同名指标在进程内只注册一次
+func (sink *ExpvarSink) SetGauge(name, prefix string, value float64) {
+	sink.mutex.Lock()
+	gauge, ok := sink.gauges[name]
+	if !ok {
+		if existing, isMap := expvar.Get(name).(*expvar.Map); isMap {
+			gauge = existing
+		} else {
+			gauge = expvar.NewMap(name)
+		}
+		sink.gauges[name] = gauge
+	}
+	sink.mutex.Unlock()
+
+	gaugeValue := new(expvar.Float)
+	gaugeValue.Set(value)
+	gauge.Set(prefix, gaugeValue)
+}
+
+// Scheduler 用量指标调度器
+// 按固定间隔对每个前缀执行 oss.Stats 并发布指标，不需要额外的定时任务和脚本
+type Scheduler struct {
+	// Storage 存储接口
+	Storage oss.StorageInterface
+	// Prefixes 需要统计的路径前缀
+	Prefixes []string
+	// Interval 统计间隔，为0时使用 DefaultInterval
+	Interval time.Duration
+	// Sink 指标发布目标
+	Sink Sink
+	// OnError 统计失败时的回调，为nil时忽略错误，下一个周期会重新统计
+	OnError func(prefix string, err error)
+
+	mutex sync.Mutex
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewScheduler 创建用量指标调度器
+// 参数:
+//   - storage: 存储接口
+//   - sink: 指标发布目标
+//   - interval: 统计间隔
+//   - prefixes: 需要统计的路径前缀
+// 返回:
+//   - *Scheduler: 调度器实例
+func NewScheduler(storage oss.StorageInterface, sink Sink, interval time.Duration, prefixes ...string) *Scheduler {
+	return &Scheduler{Storage: storage, Sink: sink, Interval: interval, Prefixes: prefixes}
+}
+
+// RunOnce 立即统计全部前缀并发布指标
+// 单个前缀统计失败不影响其他前缀
+// 返回:
+//   - error: 错误信息，包含全部统计失败的前缀
+func (scheduler *Scheduler) RunOnce() error {
+	var errs []error
+	for _, prefix := range scheduler.Prefixes {
+		stats, err := oss.Stats(scheduler.Storage, prefix)
+		if err != nil {
+			err = fmt.Errorf("stats %s failed: %w", prefix, err)
+			if scheduler.OnError != nil {
+				scheduler.OnError(prefix, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		scheduler.Sink.SetGauge(GaugeObjects, prefix, float64(stats.Objects))
+		scheduler.Sink.SetGauge(GaugeBytes, prefix, float64(stats.Bytes))
+		if stats.LastModified != nil {
+			scheduler.Sink.SetGauge(GaugeLastModified, prefix, float64(stats.LastModified.Unix()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start 在后台启动调度器，立即统计一次，之后按间隔统计
+// 调度器已经启动时不做任何操作
+func (scheduler *Scheduler) Start() {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if scheduler.stop != nil {
+		return
+	}
+
+	interval := scheduler.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	scheduler.stop, scheduler.done = stop, done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			scheduler.RunOnce()
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止调度器，等待正在进行的统计完成
+func (scheduler *Scheduler) Stop() {
+	scheduler.mutex.Lock()
+	stop, done := scheduler.stop, scheduler.done
+	scheduler.stop, scheduler.done = nil, nil
+	scheduler.mutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
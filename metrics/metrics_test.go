@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// recordSink 记录最近一次发布的指标
+type recordSink struct {
+	mutex  sync.Mutex
+	gauges map[string]float64
+}
+
+func (sink *recordSink) SetGauge(name, prefix string, value float64) {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	sink.gauges[name+" "+prefix] = value
+}
+
+func (sink *recordSink) get(name, prefix string) (float64, bool) {
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	value, ok := sink.gauges[name+" "+prefix]
+	return value, ok
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	storage := filesystem.New(t.TempDir())
+	storage.Put("/users/a.txt", strings.NewReader("sample"))
+	storage.Put("/users/b/c.txt", strings.NewReader("sample2"))
+	storage.Put("/logs/d.txt", strings.NewReader("log"))
+
+	sink := &recordSink{gauges: map[string]float64{}}
+	scheduler := NewScheduler(storage, sink, time.Minute, "/users", "/logs")
+	if err := scheduler.RunOnce(); err != nil {
+		t.Fatalf("No error should happen when run scheduler, but got %v", err)
+	}
+
+	if value, _ := sink.get(GaugeObjects, "/users"); value != 2 {
+		t.Errorf("Should publish 2 objects for /users, but got %v", value)
+	}
+
+	if value, _ := sink.get(GaugeBytes, "/users"); value != 13 {
+		t.Errorf("Should publish 13 bytes for /users, but got %v", value)
+	}
+
+	if value, _ := sink.get(GaugeObjects, "/logs"); value != 1 {
+		t.Errorf("Should publish 1 object for /logs, but got %v", value)
+	}
+
+	if _, ok := sink.get(GaugeLastModified, "/logs"); !ok {
+		t.Errorf("Should publish last modified time for /logs")
+	}
+}
+
+func TestSchedulerStartStop(t *testing.T) {
+	storage := filesystem.New(t.TempDir())
+	storage.Put("/users/a.txt", strings.NewReader("sample"))
+
+	sink := &recordSink{gauges: map[string]float64{}}
+	scheduler := NewScheduler(storage, sink, 10*time.Millisecond, "/users")
+	scheduler.Start()
+	time.Sleep(50 * time.Millisecond)
+
+	storage.Put("/users/b.txt", strings.NewReader("sample"))
+	time.Sleep(50 * time.Millisecond)
+	scheduler.Stop()
+
+	if value, _ := sink.get(GaugeObjects, "/users"); value != 2 {
+		t.Errorf("Scheduler should refresh gauges on schedule, but got %v objects", value)
+	}
+}
+
+func TestExpvarSink(t *testing.T) {
+	sink := NewExpvarSink()
+	sink.SetGauge("oss_test_bytes", "/users", 42)
+	NewExpvarSink().SetGauge("oss_test_bytes", "/logs", 7)
+
+	gauge, ok := expvar.Get("oss_test_bytes").(*expvar.Map)
+	if !ok {
+		t.Fatalf("Should register gauge in expvar")
+	}
+
+	if value := gauge.Get("/users"); value == nil || value.String() != "42" {
+		t.Errorf("Should publish gauge value 42, but got %v", value)
+	}
+
+	if value := gauge.Get("/logs"); value == nil || value.String() != "7" {
+		t.Errorf("Should share registered gauge between sinks, but got %v", value)
+	}
+}
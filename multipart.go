@@ -0,0 +1,160 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// MinPartSize 除最后一个分片外每个分片的最小大小，S3兼容服务的下限为5MB
+	MinPartSize = 5 << 20
+	// DefaultPartSize UploadMultipart 默认的分片大小
+	DefaultPartSize = 16 << 20
+	// MaxParts 单次分片上传允许的最大分片数量
+	MaxParts = 10000
+)
+
+// MultipartUpload 分片上传会话
+type MultipartUpload struct {
+	// Path 目标对象路径
+	Path string
+	// UploadID 服务端返回的上传ID
+	UploadID string
+	// Options 初始化时指定的上传选项，部分后端在完成上传时才应用
+	Options PutOptions
+}
+
+// Part 已上传的分片
+type Part struct {
+	// Number 分片编号，从1开始
+	Number int
+	// ETag 服务端返回的分片标签，完成上传时需要
+	ETag string
+	// Size 分片大小（字节）
+	Size int64
+}
+
+// MultipartUploader 分片上传接口
+// 超大文件按分片逐个上传，每个分片可以单独重试，不需要在内存中缓冲整个文件
+// s3、aliyun、tencent、huawei、azureblob和qiniu实现了该接口，调用方通过类型断言判断是否支持
+type MultipartUploader interface {
+	// InitiateMultipart 初始化分片上传
+	// 参数:
+	//   - path: 目标对象路径
+	//   - opts: 上传选项，为nil时使用默认值
+	// 返回:
+	//   - *MultipartUpload: 分片上传会话
+	//   - error: 错误信息
+	InitiateMultipart(path string, opts *PutOptions) (*MultipartUpload, error)
+
+	// UploadPart 上传一个分片
+	// 参数:
+	//   - upload: 分片上传会话
+	//   - number: 分片编号，从1开始
+	//   - reader: 分片内容
+	//   - size: 分片大小（字节）
+	// 返回:
+	//   - *Part: 已上传的分片
+	//   - error: 错误信息
+	UploadPart(upload *MultipartUpload, number int, reader io.Reader, size int64) (*Part, error)
+
+	// CompleteMultipart 按分片编号合并分片，完成上传
+	// 参数:
+	//   - upload: 分片上传会话
+	//   - parts: 已上传的分片
+	// 返回:
+	//   - *Object: 上传后的对象信息
+	//   - error: 错误信息
+	CompleteMultipart(upload *MultipartUpload, parts []*Part) (*Object, error)
+
+	// AbortMultipart 中止分片上传并释放已上传的分片
+	// 参数:
+	//   - upload: 分片上传会话
+	// 返回:
+	//   - error: 错误信息
+	AbortMultipart(upload *MultipartUpload) error
+}
+
+// UploadMultipart 按分片上传reader的全部内容
+// 同一时间只在内存中缓冲一个分片，任意分片上传失败时中止整个上传
+// 参数:
+//   - uploader: 分片上传接口
+//   - path: 目标对象路径
+//   - reader: 文件内容
+//   - partSize: 分片大小，小于 MinPartSize 时使用 DefaultPartSize
+//   - opts: 上传选项，为nil时使用默认值
+// 返回:
+//   - *Object: 上传后的对象信息
+//   - error: 错误信息
+func UploadMultipart(uploader MultipartUploader, path string, reader io.Reader, partSize int64, opts *PutOptions) (*Object, error) {
+	if partSize < MinPartSize {
+		partSize = DefaultPartSize
+	}
+
+	upload, err := uploader.InitiateMultipart(path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		parts  []*Part
+		buffer = make([]byte, partSize)
+	)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, abortMultipart(uploader, upload, err)
+		}
+		// 空文件也需要上传一个空分片
+		if n == 0 && len(parts) > 0 {
+			break
+		}
+		if number > MaxParts {
+			return nil, abortMultipart(uploader, upload, fmt.Errorf("%w: more than %d parts of %d bytes", ErrTooLarge, MaxParts, partSize))
+		}
+
+		part, partErr := uploader.UploadPart(upload, number, bytes.NewReader(buffer[:n]), int64(n))
+		if partErr != nil {
+			return nil, abortMultipart(uploader, upload, partErr)
+		}
+		parts = append(parts, part)
+
+		if err != nil {
+			break
+		}
+	}
+
+	object, err := uploader.CompleteMultipart(upload, parts)
+	if err != nil {
+		return nil, abortMultipart(uploader, upload, err)
+	}
+	return object, nil
+}
+
+// abortMultipart 中止分片上传，返回原始错误和中止时的错误
+func abortMultipart(uploader MultipartUploader, upload *MultipartUpload, err error) error {
+	if abortErr := uploader.AbortMultipart(upload); abortErr != nil {
+		return errors.Join(err, abortErr)
+	}
+	return err
+}
+
+// SeekablePart 将分片内容转换为可寻址的读取器
+// 用于要求分片内容可重读的SDK，已经可寻址的读取器原样返回
+// 参数:
+//   - reader: 分片内容
+// 返回:
+//   - io.ReadSeeker: 可寻址的分片内容
+//   - error: 错误信息
+func SeekablePart(reader io.Reader) (io.ReadSeeker, error) {
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		return seeker, nil
+	}
+	buffer, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(buffer), nil
+}
@@ -0,0 +1,83 @@
+package oss_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// partUploader 在内存中暂存分片的分片上传实现，完成时写入文件系统
+type partUploader struct {
+	*filesystem.FileSystem
+	parts    map[int][]byte
+	failPart int
+	aborted  bool
+}
+
+func (uploader *partUploader) InitiateMultipart(path string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	uploader.parts = map[int][]byte{}
+	return &oss.MultipartUpload{Path: path, UploadID: "test"}, nil
+}
+
+func (uploader *partUploader) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	if number == uploader.failPart {
+		return nil, oss.ErrUnavailable
+	}
+	buffer, err := io.ReadAll(reader)
+	if err != nil || int64(len(buffer)) != size {
+		return nil, fmt.Errorf("part %d: read %d of %d bytes: %v", number, len(buffer), size, err)
+	}
+	uploader.parts[number] = buffer
+	return &oss.Part{Number: number, ETag: fmt.Sprint(number), Size: size}, nil
+}
+
+func (uploader *partUploader) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	var content []byte
+	for _, part := range parts {
+		content = append(content, uploader.parts[part.Number]...)
+	}
+	return uploader.FileSystem.Put(upload.Path, strings.NewReader(string(content)))
+}
+
+func (uploader *partUploader) AbortMultipart(upload *oss.MultipartUpload) error {
+	uploader.aborted = true
+	return nil
+}
+
+func TestUploadMultipart(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	content := strings.Repeat("0123456789", oss.MinPartSize/10*2+1)
+
+	uploader := &partUploader{FileSystem: fileSystem}
+	if _, err := oss.UploadMultipart(uploader, "/multipart.bin", strings.NewReader(content), oss.MinPartSize, nil); err != nil {
+		t.Fatalf("No error should happen when upload in parts, but got %v", err)
+	}
+	if len(uploader.parts) != 3 {
+		t.Errorf("Should upload 3 parts, but got %v", len(uploader.parts))
+	}
+	if stream, err := fileSystem.GetStream("/multipart.bin"); err != nil {
+		t.Errorf("No error should happen when get file uploaded in parts, but got %v", err)
+	} else {
+		if buffer, _ := io.ReadAll(stream); string(buffer) != content {
+			t.Errorf("File uploaded in parts should contain correct content, but got %v bytes", len(buffer))
+		}
+		stream.Close()
+	}
+
+	if _, err := oss.UploadMultipart(uploader, "/empty.bin", strings.NewReader(""), 0, nil); err != nil || len(uploader.parts) != 1 {
+		t.Errorf("Empty file should be uploaded as a single part, but got %v parts, %v", len(uploader.parts), err)
+	}
+
+	failing := &partUploader{FileSystem: fileSystem, failPart: 2}
+	if _, err := oss.UploadMultipart(failing, "/failed.bin", strings.NewReader(content), oss.MinPartSize, nil); !errors.Is(err, oss.ErrUnavailable) {
+		t.Errorf("Failed part should return its error, but got %v", err)
+	}
+	if !failing.aborted {
+		t.Errorf("Failed upload should be aborted")
+	}
+}
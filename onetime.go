@@ -0,0 +1,158 @@
+package oss
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// OnceParam 一次性令牌的查询参数
+const OnceParam = "once"
+
+// ErrTokenRedeemed 一次性令牌无效、已被使用或已过期，errors.Is(err, ErrPermissionDenied) 同样成立
+var ErrTokenRedeemed error = &kindError{message: "oss: token already redeemed or invalid", parent: ErrPermissionDenied}
+
+// TokenRegistry 一次性令牌的登记表
+// 默认实现 MemoryTokenRegistry 只在单个进程内有效，多实例部署时可以基于Redis或数据库实现该接口
+type TokenRegistry interface {
+	// Issue 为对象路径签发一次性令牌
+	// 参数:
+	//   - path: 对象路径
+	//   - ttl: 令牌有效期
+	// 返回:
+	//   - string: 令牌
+	//   - error: 错误信息
+	Issue(path string, ttl time.Duration) (string, error)
+	// Redeem 使用令牌，令牌只能成功使用一次
+	// 参数:
+	//   - token: 令牌
+	//   - path: 请求的对象路径，必须与签发时一致
+	// 返回:
+	//   - error: 令牌无效、已被使用、已过期或路径不一致时返回 ErrTokenRedeemed
+	Redeem(token, path string) error
+	// Expire 提前作废令牌，令牌不存在时不返回错误
+	// 参数:
+	//   - token: 令牌
+	// 返回:
+	//   - error: 错误信息
+	Expire(token string) error
+}
+
+// MemoryTokenRegistry 保存在内存中的一次性令牌登记表，可以并发使用
+type MemoryTokenRegistry struct {
+	mu     sync.Mutex
+	tokens map[string]onceToken
+}
+
+// onceToken 已签发的一次性令牌
+type onceToken struct {
+	path    string
+	expires time.Time
+}
+
+// NewMemoryTokenRegistry 创建保存在内存中的一次性令牌登记表
+// 返回:
+//   - *MemoryTokenRegistry: 令牌登记表
+func NewMemoryTokenRegistry() *MemoryTokenRegistry {
+	return &MemoryTokenRegistry{tokens: map[string]onceToken{}}
+}
+
+// Issue 为对象路径签发一次性令牌，令牌为 crypto/rand 生成的32位十六进制字符串
+// 令牌是访问凭证，不使用可以被替换的 DefaultRandom，避免令牌可以被预测
+// 签发时顺便清理已过期的令牌
+// 参数:
+//   - path: 对象路径
+//   - ttl: 令牌有效期
+// 返回:
+//   - string: 令牌
+//   - error: 读取随机数失败时的错误信息
+func (registry *MemoryTokenRegistry) Issue(path string, ttl time.Duration) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(random)
+	now := Now()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for key, issued := range registry.tokens {
+		if now.After(issued.expires) {
+			delete(registry.tokens, key)
+		}
+	}
+	registry.tokens[token] = onceToken{path: path, expires: now.Add(ttl)}
+	return token, nil
+}
+
+// Redeem 使用令牌，令牌无论是否成功使用都会被删除，路径不一致的尝试同样会作废令牌
+// 参数:
+//   - token: 令牌
+//   - path: 请求的对象路径
+// 返回:
+//   - error: 令牌无效、已被使用、已过期或路径不一致时返回 ErrTokenRedeemed
+func (registry *MemoryTokenRegistry) Redeem(token, path string) error {
+	registry.mu.Lock()
+	issued, ok := registry.tokens[token]
+	delete(registry.tokens, token)
+	registry.mu.Unlock()
+
+	switch {
+	case !ok:
+		return ErrTokenRedeemed
+	case Now().After(issued.expires):
+		return fmt.Errorf("%w: token expired", ErrTokenRedeemed)
+	case issued.path != path:
+		return fmt.Errorf("%w: token does not match %s", ErrTokenRedeemed, path)
+	}
+	return nil
+}
+
+// Expire 提前作废令牌
+// 参数:
+//   - token: 令牌
+// 返回:
+//   - error: 总是返回nil
+func (registry *MemoryTokenRegistry) Expire(token string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.tokens, token)
+	return nil
+}
+
+// Len 返回尚未使用且未被清理的令牌数量
+// 返回:
+//   - int: 令牌数量
+func (registry *MemoryTokenRegistry) Len() int {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return len(registry.tokens)
+}
+
+// OnceHandler 要求请求携带一次性令牌的HTTP处理器
+// 用于自建的下载服务，例如通过 http.FileServer 直接提供本地文件系统或NAS挂载目录中的对象，
+// 对象路径为请求路径，令牌通过 OnceParam 查询参数传递，使用后链接不能再次访问或转发给他人
+type OnceHandler struct {
+	// Registry 一次性令牌登记表
+	Registry TokenRegistry
+	// Handler 令牌校验通过后处理请求的处理器
+	Handler http.Handler
+	// Audit 每次请求校验令牌后的回调，err为nil表示令牌使用成功，可以为nil
+	Audit func(r *http.Request, path string, err error)
+}
+
+// ServeHTTP 使用请求携带的一次性令牌，成功后交给 Handler 处理
+func (handler *OnceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := handler.Registry.Redeem(r.URL.Query().Get(OnceParam), r.URL.Path)
+	if handler.Audit != nil {
+		handler.Audit(r, r.URL.Path, err)
+	}
+	if err != nil {
+		status := HTTPStatus(err)
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	handler.Handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,65 @@
+package oss_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestOnceHandler(t *testing.T) {
+	base := t.TempDir()
+	filesystem.New(base).Put("/docs/a.txt", strings.NewReader("sample"))
+	registry := oss.NewMemoryTokenRegistry()
+	server := httptest.NewServer(&oss.OnceHandler{Registry: registry, Handler: http.FileServer(http.Dir(base))})
+	defer server.Close()
+
+	token, _ := registry.Issue("/docs/a.txt", time.Minute)
+	for i, expected := range []int{http.StatusOK, http.StatusForbidden} {
+		response, err := http.Get(server.URL + "/docs/a.txt?" + oss.OnceParam + "=" + token)
+		if err != nil {
+			t.Fatalf("No error should happen when download with one-time token, but got %v", err)
+		}
+		response.Body.Close()
+		if response.StatusCode != expected {
+			t.Errorf("Request %d with one-time token should return %v, but got %v", i+1, expected, response.StatusCode)
+		}
+	}
+
+	other, _ := registry.Issue("/docs/a.txt", time.Minute)
+	if err := registry.Redeem(other, "/docs/b.txt"); !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("Token should be bound to its path, but got %v", err)
+	}
+	expired, _ := registry.Issue("/docs/a.txt", -time.Second)
+	if err := registry.Redeem(expired, "/docs/a.txt"); !errors.Is(err, oss.ErrTokenRedeemed) {
+		t.Errorf("Expired token should be rejected, but got %v", err)
+	}
+	revoked, _ := registry.Issue("/docs/a.txt", time.Minute)
+	registry.Expire(revoked)
+	if err := registry.Redeem(revoked, "/docs/a.txt"); err == nil || registry.Len() != 0 {
+		t.Errorf("Expired token should be removed, but got %v with %d tokens", err, registry.Len())
+	}
+}
+
+func TestIssueSeededRandom(t *testing.T) {
+	random := oss.DefaultRandom
+	defer func() { oss.DefaultRandom = random }()
+
+	var tokens [2]string
+	for i := range tokens {
+		oss.DefaultRandom = oss.NewSeededRandom(1)
+		token, err := oss.NewMemoryTokenRegistry().Issue("/docs/a.txt", time.Minute)
+		if err != nil {
+			t.Fatalf("No error should happen when issue token, but got %v", err)
+		}
+		tokens[i] = token
+	}
+	if tokens[0] == tokens[1] {
+		t.Errorf("Tokens should not depend on the seeded oss.DefaultRandom, but both were %v", tokens[0])
+	}
+}
@@ -0,0 +1,193 @@
+package oss
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory 根据连接字符串创建存储客户端
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - StorageInterface: 存储客户端
+//   - error: 错误信息
+type Factory func(dsn url.URL) (StorageInterface, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register 注册连接字符串协议对应的存储客户端工厂
+// 各存储后端在包初始化时注册，使用 Open 前需要导入对应的包；
+// 第三方存储后端以同样的方式注册后即可通过 Open 创建，并使用 prefix、timeout 等通用参数
+// 参数:
+//   - scheme: 协议名，例如 s3，不区分大小写
+//   - factory: 存储客户端工厂
+func Register(scheme string, factory Factory) {
+	scheme = strings.ToLower(scheme)
+	if scheme == "" || factory == nil {
+		panic("oss: Register scheme and factory must not be empty")
+	}
+
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, exists := factories[scheme]; exists {
+		panic("oss: Register called twice for scheme " + scheme)
+	}
+	factories[scheme] = factory
+}
+
+// Schemes 返回已注册的连接字符串协议
+// 返回:
+//   - []string: 按字典序排序的协议名
+func Schemes() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	schemes := make([]string, 0, len(factories))
+	for scheme := range factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open 根据连接字符串创建存储客户端
+// 例如 s3://bucket?region=us-east-1&acl=private、file:///data 和 azblob://container，
+// 访问密钥以URL的用户名和密码传入，例如 s3://AccessId:AccessKey@bucket。
+// 所有协议都支持以下通用参数，由 Open 处理后再将其它参数交给存储客户端工厂:
+//   - prefix: 以该目录为根目录，返回 *PrefixedStorage
+//   - timeout: 单次调用的超时时间，例如 30s，返回 *TimeoutStorage
+// 参数:
+//   - dsn: 连接字符串
+// 返回:
+//   - StorageInterface: 存储客户端
+//   - error: 错误信息，协议未注册时返回 ErrNotSupported
+func Open(dsn string) (StorageInterface, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("oss: invalid dsn: %w", err)
+	}
+	if parsed.Scheme == "" {
+		return nil, fmt.Errorf("oss: dsn %s has no scheme", parsed.Redacted())
+	}
+
+	factoriesMu.RLock()
+	factory, ok := factories[strings.ToLower(parsed.Scheme)]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: dsn scheme %s, import the backend package to register it", ErrNotSupported, parsed.Scheme)
+	}
+
+	// 取出通用参数，其它参数交给存储客户端工厂
+	query := parsed.Query()
+	prefix, timeout := query.Get("prefix"), query.Get("timeout")
+	query.Del("prefix")
+	query.Del("timeout")
+	parsed.RawQuery = query.Encode()
+
+	var duration time.Duration
+	if timeout != "" {
+		if duration, err = time.ParseDuration(timeout); err != nil || duration <= 0 {
+			return nil, fmt.Errorf("oss: dsn parameter timeout: invalid duration %q", timeout)
+		}
+	}
+
+	storage, err := factory(*parsed)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" {
+		storage = NewPrefixedStorage(storage, prefix)
+	}
+	if duration > 0 {
+		storage = WithTimeout(storage, duration)
+	}
+	return storage, nil
+}
+
+// DSNParams 连接字符串的查询参数
+// 记录读取过的参数，Err 对未读取的参数返回错误，以便发现拼写错误
+type DSNParams struct {
+	values url.Values
+	used   map[string]bool
+	err    error
+}
+
+// NewDSNParams 创建连接字符串的查询参数
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - *DSNParams: 查询参数
+func NewDSNParams(dsn url.URL) *DSNParams {
+	return &DSNParams{values: dsn.Query(), used: map[string]bool{}}
+}
+
+// Get 读取字符串参数
+// 参数:
+//   - key: 参数名
+// 返回:
+//   - string: 参数值，未设置时返回空字符串
+func (params *DSNParams) Get(key string) string {
+	params.used[key] = true
+	return params.values.Get(key)
+}
+
+// Bool 读取布尔参数，无法解析的值由 Err 返回
+// 参数:
+//   - key: 参数名
+// 返回:
+//   - bool: 参数值，未设置时返回false
+func (params *DSNParams) Bool(key string) bool {
+	value := params.Get(key)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil && params.err == nil {
+		params.err = fmt.Errorf("oss: dsn parameter %s: invalid bool %q", key, value)
+	}
+	return b
+}
+
+// Int 读取整数参数，无法解析的值由 Err 返回
+// 参数:
+//   - key: 参数名
+// 返回:
+//   - int: 参数值，未设置时返回0
+func (params *DSNParams) Int(key string) int {
+	value := params.Get(key)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil && params.err == nil {
+		params.err = fmt.Errorf("oss: dsn parameter %s: invalid number %q", key, value)
+	}
+	return n
+}
+
+// Err 返回读取参数时的错误
+// 返回:
+//   - error: 参数值无法解析或存在未识别的参数时返回错误
+func (params *DSNParams) Err() error {
+	if params.err != nil {
+		return params.err
+	}
+	var unknown []string
+	for key := range params.values {
+		if !params.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("oss: unknown dsn parameters %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package oss_test
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestRegister(t *testing.T) {
+	var opened url.URL
+	oss.Register("custom-test", func(dsn url.URL) (oss.StorageInterface, error) {
+		opened = dsn
+		return filesystem.New(t.TempDir()), nil
+	})
+
+	storage, err := oss.Open("custom-test://bucket/root?region=eu&prefix=/uploads&timeout=5s")
+	if err != nil {
+		t.Fatalf("No error should happen when open registered scheme, but got %v", err)
+	}
+	if opened.Host != "bucket" || opened.Path != "/root" || opened.RawQuery != "region=eu" {
+		t.Errorf("Factory should receive dsn without common parameters, but got %v", opened.String())
+	}
+	timeout, ok := storage.(*oss.TimeoutStorage)
+	if !ok {
+		t.Fatalf("Open with timeout should return *oss.TimeoutStorage, but got %T", storage)
+	}
+	if _, ok := timeout.StorageInterface.(*oss.PrefixedStorage); !ok {
+		t.Errorf("Open with prefix should wrap storage with *oss.PrefixedStorage, but got %T", timeout.StorageInterface)
+	}
+
+	schemes := oss.Schemes()
+	if !sort.StringsAreSorted(schemes) || !strings.Contains(strings.Join(schemes, ","), "custom-test") || !strings.Contains(strings.Join(schemes, ","), "file") {
+		t.Errorf("Schemes should list registered schemes in order, but got %v", schemes)
+	}
+	if _, err := oss.Open("custom-test://bucket?timeout=soon"); err == nil {
+		t.Errorf("Invalid timeout should be rejected")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registering a scheme twice should panic")
+		}
+	}()
+	oss.Register("CUSTOM-TEST", func(url.URL) (oss.StorageInterface, error) { return nil, nil })
+}
@@ -0,0 +1,159 @@
+package oss
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	pathpkg "path"
+	"strings"
+	"time"
+)
+
+// ACL 与存储后端无关的访问控制类型
+type ACL string
+
+const (
+	// ACLPrivate 私有读写
+	ACLPrivate ACL = "private"
+	// ACLPublicRead 公共读
+	ACLPublicRead ACL = "public-read"
+	// ACLPublicReadWrite 公共读写
+	ACLPublicReadWrite ACL = "public-read-write"
+	// ACLAuthenticatedRead 认证用户可读
+	ACLAuthenticatedRead ACL = "authenticated-read"
+)
+
+// PutOptions 上传选项
+// 未设置的字段使用后端的默认行为，后端不支持的HTTP头会被忽略，
+// 后端不支持对象级ACL时设置ACL将返回 ErrNotSupported
+type PutOptions struct {
+	// ContentType 内容类型，为空时根据扩展名或内容自动检测
+	ContentType string
+	// ContentDisposition 内容处置方式，例如 attachment; filename="a.txt"
+	ContentDisposition string
+	// CacheControl 缓存控制
+	CacheControl string
+	// Metadata 用户自定义元数据
+	Metadata map[string]string
+	// ACL 对象级访问控制，为空时使用客户端配置的默认ACL
+	ACL ACL
+	// Charset 文本对象的字符集，例如 utf-8、gbk，设置后写入Content-Type的charset参数
+	Charset string
+	// Checksum 上传时发送校验和的算法，由服务端校验内容，后端不支持该算法时返回 ErrNotSupported
+	// 设置后流式上传的后端需要先将内容读入内存
+	Checksum ChecksumAlgorithm
+	// ChecksumValue 调用方预先计算的Base64编码校验和，为空时根据上传内容计算
+	// 与上传内容不一致时在发送前返回 ErrChecksumMismatch，用于发现多次转发过程中的损坏
+	ChecksumValue string
+	// Tags 对象标签，用于匹配生命周期规则，后端不支持对象标签时返回 ErrNotSupported
+	Tags map[string]string
+	// Collision 上传路径已存在对象时的处理策略，为空时覆盖已有对象
+	// 自动重命名时返回的对象路径为实际上传的路径
+	Collision CollisionPolicy
+	// Progress 上传进度回调，total为上传内容的总字节数，未知时为-1
+	Progress ProgressFunc `json:"-"`
+}
+
+// Normalize 将字符集合并到内容类型中
+// 设置了 Charset 而未设置 ContentType 时，根据扩展名确定内容类型，无法确定时使用 text/plain
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - PutOptions: 合并字符集后的选项
+func (opts PutOptions) Normalize(path string) PutOptions {
+	if opts.Charset == "" {
+		return opts
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(pathpkg.Ext(path))
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	params["charset"] = strings.ToLower(opts.Charset)
+	opts.ContentType = mime.FormatMediaType(mediaType, params)
+	return opts
+}
+
+// DefaultSignedURLExpiry 签名URL的默认有效期
+const DefaultSignedURLExpiry = time.Hour
+
+// SignedURLOptions 生成签名URL的选项
+// 后端不支持的响应头覆盖会被忽略
+type SignedURLOptions struct {
+	// Expiry 有效期，小于等于0时使用 DefaultSignedURLExpiry
+	Expiry time.Duration
+	// Method HTTP方法，支持 http.MethodGet 和 http.MethodPut，为空时使用GET
+	Method string
+	// ResponseContentType 覆盖下载响应的Content-Type
+	ResponseContentType string
+	// ResponseContentDisposition 覆盖下载响应的Content-Disposition
+	ResponseContentDisposition string
+	// ResponseCacheControl 覆盖下载响应的Cache-Control
+	ResponseCacheControl string
+}
+
+// Normalize 填充默认值并校验HTTP方法
+// 返回:
+//   - SignedURLOptions: 填充默认值后的选项
+//   - error: 错误信息，HTTP方法不支持时返回 ErrNotSupported
+func (opts SignedURLOptions) Normalize() (SignedURLOptions, error) {
+	if opts.Expiry <= 0 {
+		opts.Expiry = DefaultSignedURLExpiry
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	if opts.Method != http.MethodGet && opts.Method != http.MethodPut {
+		return opts, fmt.Errorf("%w: signed URL method %s", ErrNotSupported, opts.Method)
+	}
+	return opts, nil
+}
+
+// UploadURLOptions 生成客户端直传地址的选项
+type UploadURLOptions struct {
+	// Expiry 有效期，小于等于0时使用 DefaultSignedURLExpiry
+	Expiry time.Duration
+	// ContentType 上传内容类型，设置后客户端必须使用相同的Content-Type上传
+	ContentType string
+}
+
+// Normalize 填充默认值
+// 返回:
+//   - UploadURLOptions: 填充默认值后的选项
+func (opts UploadURLOptions) Normalize() UploadURLOptions {
+	if opts.Expiry <= 0 {
+		opts.Expiry = DefaultSignedURLExpiry
+	}
+	return opts
+}
+
+// Headers 获取客户端上传时必须携带的请求头
+// 返回:
+//   - map[string]string: 请求头
+func (opts UploadURLOptions) Headers() map[string]string {
+	headers := map[string]string{}
+	if opts.ContentType != "" {
+		headers["Content-Type"] = opts.ContentType
+	}
+	return headers
+}
+
+// UploadURL 客户端直传地址
+// 客户端使用Method向URL发送文件内容，并携带Headers中的全部请求头；
+// Method为POST时使用multipart表单上传，FormFields中的字段需要与文件字段 file 一同提交
+type UploadURL struct {
+	// URL 上传地址
+	URL string
+	// Method HTTP方法，PUT 或 POST
+	Method string
+	// Headers 上传时必须携带的请求头
+	Headers map[string]string
+	// FormFields 表单上传时必须携带的字段
+	FormFields map[string]string
+	// ExpiresAt 过期时间
+	ExpiresAt time.Time
+}
@@ -0,0 +1,24 @@
+package oss_test
+
+import (
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+func TestPutOptionsCharset(t *testing.T) {
+	for _, test := range []struct {
+		path        string
+		opts        oss.PutOptions
+		contentType string
+	}{
+		{"/a.txt", oss.PutOptions{Charset: "GBK"}, "text/plain; charset=gbk"},
+		{"/a.csv", oss.PutOptions{ContentType: "text/csv; charset=utf-8", Charset: "gb18030"}, "text/csv; charset=gb18030"},
+		{"/a", oss.PutOptions{Charset: "utf-8"}, "text/plain; charset=utf-8"},
+		{"/a.txt", oss.PutOptions{ContentType: "text/plain"}, "text/plain"},
+	} {
+		if contentType := test.opts.Normalize(test.path).ContentType; contentType != test.contentType {
+			t.Errorf("Should normalize content type of %v to %q, but got %q", test.path, test.contentType, contentType)
+		}
+	}
+}
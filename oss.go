@@ -3,8 +3,11 @@
 package oss
 
 import (
+	"errors"
 	"io"
+	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -27,6 +30,32 @@ type StorageInterface interface {
 	//   - error: 错误信息
 	GetStream(path string) (io.ReadCloser, error)
 	
+	// GetStreamRange 获取指定路径文件的部分内容
+	// 参数:
+	//   - path: 文件路径
+	//   - offset: 起始偏移量
+	//   - length: 读取长度，小于等于0时读取到文件末尾
+	// 返回:
+	//   - io.ReadCloser: 范围内的文件流
+	//   - error: 错误信息，偏移量无效时返回 ErrInvalidRange
+	GetStreamRange(path string, offset, length int64) (io.ReadCloser, error)
+	
+	// Stat 获取指定路径文件的元数据，不下载文件内容
+	// 参数:
+	//   - path: 文件路径
+	// 返回:
+	//   - *Object: 对象信息（包含大小、内容类型、最后修改时间、ETag）
+	//   - error: 错误信息
+	Stat(path string) (*Object, error)
+	
+	// Exists 判断指定路径的文件是否存在
+	// 参数:
+	//   - path: 文件路径
+	// 返回:
+	//   - bool: 文件是否存在
+	//   - error: 错误信息，文件不存在时返回 false 和 nil
+	Exists(path string) (bool, error)
+	
 	// Put 上传文件到指定路径
 	// 参数:
 	//   - path: 目标路径
@@ -36,6 +65,25 @@ type StorageInterface interface {
 	//   - error: 错误信息
 	Put(path string, reader io.Reader) (*Object, error)
 	
+	// PutWithOptions 使用指定选项上传文件到指定路径
+	// 参数:
+	//   - path: 目标路径
+	//   - reader: 文件内容读取器
+	//   - opts: 上传选项，为nil时等同于Put
+	// 返回:
+	//   - *Object: 上传后的对象信息
+	//   - error: 错误信息
+	PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error)
+	
+	// NewWriter 创建流式写入指定路径的写入器，数据不会在内存中整体缓冲
+	// 写入的内容在 Close 成功返回后才可见
+	// 参数:
+	//   - path: 目标路径
+	// 返回:
+	//   - io.WriteCloser: 写入器，Close 返回上传结果
+	//   - error: 错误信息
+	NewWriter(path string) (io.WriteCloser, error)
+	
 	// Delete 删除指定路径的文件
 	// 参数:
 	//   - path: 文件路径
@@ -43,7 +91,38 @@ type StorageInterface interface {
 	//   - error: 错误信息
 	Delete(path string) error
 	
-	// List 列出指定路径下的所有对象
+	// DeleteObjects 批量删除多个文件，优先使用存储后端的批量删除接口
+	// 不存在的文件视为删除成功
+	// 参数:
+	//   - paths: 文件路径列表
+	// 返回:
+	//   - error: 错误信息，部分文件删除失败时返回 *DeleteObjectsError
+	DeleteObjects(paths []string) error
+	
+	// DeleteDir 删除目录下的全部对象，内部使用分页列举和批量删除
+	// 参数:
+	//   - dir: 目录路径
+	// 返回:
+	//   - error: 错误信息，根目录返回 ErrDeleteRoot
+	DeleteDir(dir string) error
+	
+	// Copy 复制文件到新路径，优先使用服务端复制
+	// 参数:
+	//   - srcPath: 源文件路径
+	//   - dstPath: 目标文件路径
+	// 返回:
+	//   - error: 错误信息
+	Copy(srcPath, dstPath string) error
+	
+	// Move 移动（重命名）文件到新路径，优先使用服务端重命名
+	// 参数:
+	//   - srcPath: 源文件路径
+	//   - dstPath: 目标文件路径
+	// 返回:
+	//   - error: 错误信息
+	Move(srcPath, dstPath string) error
+	
+	// List 列出指定路径下的所有对象，结果按路径字典序排列
 	// 参数:
 	//   - path: 目录路径
 	// 返回:
@@ -58,6 +137,24 @@ type StorageInterface interface {
 	//   - string: 访问URL
 	//   - error: 错误信息
 	GetURL(path string) (string, error)
+
+	// GetSignedURL 生成指定路径文件的预签名URL
+	// 参数:
+	//   - path: 文件路径
+	//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+	// 返回:
+	//   - string: 预签名URL
+	//   - error: 错误信息，后端无法签名时返回 ErrNotSupported
+	GetSignedURL(path string, opts SignedURLOptions) (string, error)
+
+	// GetUploadURL 生成客户端直传地址，使客户端无需经过服务端中转即可上传文件
+	// 参数:
+	//   - path: 文件路径
+	//   - opts: 直传选项
+	// 返回:
+	//   - *UploadURL: 直传地址及需要携带的请求头或表单字段
+	//   - error: 错误信息，后端不支持直传时返回 ErrNotSupported
+	GetUploadURL(path string, opts UploadURLOptions) (*UploadURL, error)
 	
 	// GetEndpoint 获取存储服务的端点地址
 	// 返回:
@@ -72,14 +169,67 @@ type Object struct {
 	Path string
 	// Name 对象名称
 	Name string
-	// LastModified 最后修改时间
+	// LastModified 最后修改时间，统一为毫秒精度的UTC时间
 	LastModified *time.Time
 	// Size 对象大小（字节）
 	Size int64
+	// ContentType 内容类型
+	ContentType string
+	// ETag 对象的实体标签
+	ETag string
+	// Metadata 用户自定义元数据，键统一为小写且不含厂商前缀
+	Metadata map[string]string
+	// LinkTarget 符号链接的目标路径，仅在后端支持并启用时返回
+	LinkTarget string
 	// StorageInterface 关联的存储接口
 	StorageInterface StorageInterface
 }
 
+// NormalizeTime 将服务端返回的时间统一为毫秒精度的UTC时间
+// 参数:
+//   - t: 原始时间
+// 返回:
+//   - *time.Time: 规范化后的时间，零值时返回nil
+func NormalizeTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC().Truncate(time.Millisecond)
+	return &t
+}
+
+// NormalizeMetadata 将服务端返回的用户元数据的键统一为小写
+// 参数:
+//   - metadata: 原始元数据
+// 返回:
+//   - map[string]string: 规范化后的元数据，为空时返回nil
+func NormalizeMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		normalized[strings.ToLower(key)] = value
+	}
+	return normalized
+}
+
+// MetadataFromHeader 从响应头中提取用户元数据
+// 参数:
+//   - header: 响应头
+//   - prefix: 元数据头的前缀，例如 x-oss-meta-
+// 返回:
+//   - map[string]string: 去掉前缀后的元数据，不存在时返回nil
+func MetadataFromHeader(header http.Header, prefix string) map[string]string {
+	metadata := map[string]string{}
+	for key, values := range header {
+		if len(key) > len(prefix) && strings.EqualFold(key[:len(prefix)], prefix) && len(values) > 0 {
+			metadata[key[len(prefix):]] = values[0]
+		}
+	}
+	return NormalizeMetadata(metadata)
+}
+
 // Get 获取对象的内容
 // 返回:
 //   - *os.File: 文件对象
@@ -87,3 +237,40 @@ type Object struct {
 func (object Object) Get() (*os.File, error) {
 	return object.StorageInterface.Get(object.Path)
 }
+
+// GetStream 获取对象内容的流
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息
+func (object Object) GetStream() (io.ReadCloser, error) {
+	return object.StorageInterface.GetStream(object.Path)
+}
+
+// Stat 重新获取对象的元数据
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (object Object) Stat() (*Object, error) {
+	return object.StorageInterface.Stat(object.Path)
+}
+
+// Delete 删除对象
+// 返回:
+//   - error: 错误信息
+func (object Object) Delete() error {
+	return object.StorageInterface.Delete(object.Path)
+}
+
+// URL 获取对象的访问URL
+// 参数:
+//   - expiry: 签名URL的有效期，小于等于0时使用默认有效期，后端不支持签名时返回GetURL的结果
+// 返回:
+//   - string: 访问URL
+//   - error: 错误信息
+func (object Object) URL(expiry time.Duration) (string, error) {
+	url, err := object.StorageInterface.GetSignedURL(object.Path, SignedURLOptions{Expiry: expiry})
+	if errors.Is(err, ErrNotSupported) {
+		return object.StorageInterface.GetURL(object.Path)
+	}
+	return url, err
+}
@@ -0,0 +1,23 @@
+package oss_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+func TestMetadataFromHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Oss-Meta-Owner", "tests")
+	header.Set("X-Oss-Meta-Project-Id", "42")
+	header.Set("Content-Type", "text/plain")
+
+	metadata := oss.MetadataFromHeader(header, "x-oss-meta-")
+	if len(metadata) != 2 || metadata["owner"] != "tests" || metadata["project-id"] != "42" {
+		t.Errorf("Should extract lower-cased metadata without prefix, but got %v", metadata)
+	}
+	if metadata := oss.MetadataFromHeader(http.Header{"Content-Type": {"text/plain"}}, "X-Oss-Meta-"); metadata != nil {
+		t.Errorf("Should return nil when no metadata header exists, but got %v", metadata)
+	}
+}
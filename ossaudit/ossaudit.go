@@ -0,0 +1,282 @@
+// Package ossaudit 记录存储写入操作审计记录的包装器
+// 每次上传、删除、复制和移动结束后生成一条JSON审计记录，包含操作者、操作、路径、字节数、时间和结果，
+// 写入文件、另一个存储或webhook等接收端，记录生成后不会被修改
+package ossaudit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+const (
+	// ResultSuccess 操作成功
+	ResultSuccess = "success"
+	// ResultFailure 操作失败
+	ResultFailure = "failure"
+)
+
+// Record 一条审计记录
+type Record struct {
+	// ID 记录的唯一标识
+	ID string `json:"id"`
+	// Time 操作开始的时间
+	Time time.Time `json:"time"`
+	// Actor 操作者，例如用户ID或服务名称
+	Actor string `json:"actor,omitempty"`
+	// Op 操作名称，例如 Put、Delete、Move
+	Op string `json:"op"`
+	// Path 对象路径，复制和移动时为目标路径，删除目录时为目录路径
+	Path string `json:"path,omitempty"`
+	// Source 复制和移动的源路径
+	Source string `json:"source,omitempty"`
+	// Paths 批量删除的对象路径
+	Paths []string `json:"paths,omitempty"`
+	// Bytes 上传的字节数，其他操作或无法确定时为-1
+	Bytes int64 `json:"bytes"`
+	// Result 操作结果，ResultSuccess 或 ResultFailure
+	Result string `json:"result"`
+	// Error 失败时的错误信息
+	Error string `json:"error,omitempty"`
+	// Duration 操作耗时
+	Duration time.Duration `json:"duration"`
+}
+
+// Sink 审计记录的接收端
+type Sink interface {
+	// Write 写入一条审计记录，可能被并发调用
+	// 参数:
+	//   - record: 审计记录
+	// 返回:
+	//   - error: 错误信息
+	Write(record Record) error
+}
+
+// SinkFunc 将函数适配为接收端
+type SinkFunc func(record Record) error
+
+// Write 调用函数写入审计记录
+func (fn SinkFunc) Write(record Record) error {
+	return fn(record)
+}
+
+// Options 审计选项
+type Options struct {
+	// Sink 审计记录的接收端
+	Sink Sink
+	// Actor 默认的操作者，可以通过 WithActor 为每个请求指定
+	Actor string
+	// OnError 接收端写入失败时的回调，为nil时通过 slog.Default() 输出错误日志
+	// 接收端的错误不会改变存储操作的返回值
+	OnError func(record Record, err error)
+}
+
+// Storage 为写入操作生成审计记录的存储包装器
+// 读取、列出和生成URL等不修改数据的操作不生成记录
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Sink 审计记录的接收端
+	Sink Sink
+	// Actor 操作者
+	Actor string
+	// OnError 接收端写入失败时的回调
+	OnError func(record Record, err error)
+}
+
+// Wrap 创建生成审计记录的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 审计选项
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, opts Options) *Storage {
+	return &Storage{StorageInterface: storage, Sink: opts.Sink, Actor: opts.Actor, OnError: opts.OnError}
+}
+
+// WithActor 返回以指定操作者生成记录的存储包装器，用于在处理请求时记录当前用户
+// 参数:
+//   - actor: 操作者
+// 返回:
+//   - *Storage: 共享被包装存储和接收端的新包装器
+func (storage *Storage) WithActor(actor string) *Storage {
+	audited := *storage
+	audited.Actor = actor
+	return &audited
+}
+
+// Put 上传文件并生成审计记录
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	start, size := oss.Now(), oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.Put(path, reader)
+	storage.record(Record{Op: "Put", Path: path, Bytes: putSize(object, size, err)}, start, err)
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件并生成审计记录
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	start, size := oss.Now(), oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	storage.record(Record{Op: "PutWithOptions", Path: path, Bytes: putSize(object, size, err)}, start, err)
+	return object, err
+}
+
+// NewWriter 创建流式写入器，关闭写入器时生成审计记录和写入的字节数
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	start := oss.Now()
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		storage.record(Record{Op: "NewWriter", Path: path, Bytes: -1}, start, err)
+		return nil, err
+	}
+	return &auditedWriter{WriteCloser: writer, storage: storage, path: path, start: start}, nil
+}
+
+// Delete 删除文件并生成审计记录
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Delete(path string) error {
+	start := oss.Now()
+	err := storage.StorageInterface.Delete(path)
+	storage.record(Record{Op: "Delete", Path: path, Bytes: -1}, start, err)
+	return err
+}
+
+// DeleteObjects 批量删除对象并生成一条包含全部路径的审计记录
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	start := oss.Now()
+	err := storage.StorageInterface.DeleteObjects(paths)
+	storage.record(Record{Op: "DeleteObjects", Paths: append([]string(nil), paths...), Bytes: -1}, start, err)
+	return err
+}
+
+// DeleteDir 删除目录并生成审计记录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteDir(dir string) error {
+	start := oss.Now()
+	err := storage.StorageInterface.DeleteDir(dir)
+	storage.record(Record{Op: "DeleteDir", Path: dir, Bytes: -1}, start, err)
+	return err
+}
+
+// Copy 复制文件并生成审计记录
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	start := oss.Now()
+	err := storage.StorageInterface.Copy(srcPath, dstPath)
+	storage.record(Record{Op: "Copy", Path: dstPath, Source: srcPath, Bytes: -1}, start, err)
+	return err
+}
+
+// Move 移动文件并生成审计记录
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	start := oss.Now()
+	err := storage.StorageInterface.Move(srcPath, dstPath)
+	storage.record(Record{Op: "Move", Path: dstPath, Source: srcPath, Bytes: -1}, start, err)
+	return err
+}
+
+// record 补全记录的公共字段并写入接收端
+func (storage *Storage) record(record Record, start time.Time, err error) {
+	record.ID, _ = oss.RandomHex(16)
+	record.Time = start.UTC()
+	record.Actor = storage.Actor
+	record.Duration = oss.Now().Sub(start)
+	record.Result = ResultSuccess
+	if err != nil {
+		record.Result = ResultFailure
+		record.Error = err.Error()
+	}
+	if storage.Sink == nil {
+		return
+	}
+	if sinkErr := storage.Sink.Write(record); sinkErr != nil {
+		if storage.OnError != nil {
+			storage.OnError(record, sinkErr)
+			return
+		}
+		slog.Default().LogAttrs(context.Background(), slog.LevelError, "oss: audit record dropped",
+			slog.String("id", record.ID), slog.String("op", record.Op), slog.String("path", record.Path), slog.String("error", sinkErr.Error()))
+	}
+}
+
+// putSize 返回上传的字节数，优先使用上传后的对象大小，失败时为-1
+func putSize(object *oss.Object, size int64, err error) int64 {
+	if err != nil {
+		return -1
+	}
+	if object != nil && object.Size > 0 {
+		return object.Size
+	}
+	return size
+}
+
+// auditedWriter 关闭时生成审计记录的写入器
+type auditedWriter struct {
+	io.WriteCloser
+	storage *Storage
+	path    string
+	start   time.Time
+	bytes   int64
+	closed  bool
+}
+
+// Write 写入内容并统计字节数
+func (writer *auditedWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.bytes += int64(n)
+	return n, err
+}
+
+// Close 关闭写入器并生成审计记录
+func (writer *auditedWriter) Close() error {
+	err := writer.WriteCloser.Close()
+	if !writer.closed {
+		writer.closed = true
+		bytes := writer.bytes
+		if err != nil {
+			bytes = -1
+		}
+		writer.storage.record(Record{Op: "NewWriter", Path: writer.path, Bytes: bytes}, writer.start, err)
+	}
+	return err
+}
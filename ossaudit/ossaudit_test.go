@@ -0,0 +1,152 @@
+package ossaudit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestWrap(t *testing.T) {
+	var mutex sync.Mutex
+	var records []Record
+	sink := SinkFunc(func(record Record) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		records = append(records, record)
+		return nil
+	})
+	storage := Wrap(filesystem.New(t.TempDir()), Options{Sink: sink, Actor: "system"})
+
+	storage.Put("/a.txt", strings.NewReader("sample"))
+	storage.Get("/a.txt")
+	storage.WithActor("alice").Move("/a.txt", "/b.txt")
+	writer, _ := storage.NewWriter("/c.txt")
+	io.WriteString(writer, "streamed")
+	writer.Close()
+	if err := storage.Delete("/missing.txt"); err == nil {
+		t.Fatalf("Deleting a missing file should fail")
+	}
+	storage.DeleteObjects([]string{"/b.txt", "/c.txt"})
+
+	if len(records) != 5 {
+		t.Fatalf("Should record 5 mutating operations, but got %+v", records)
+	}
+	if put := records[0]; put.Op != "Put" || put.Path != "/a.txt" || put.Bytes != 6 || put.Actor != "system" || put.Result != ResultSuccess || put.ID == "" || put.Time.IsZero() {
+		t.Errorf("Put should be recorded with path, bytes and actor, but got %+v", put)
+	}
+	if move := records[1]; move.Op != "Move" || move.Path != "/b.txt" || move.Source != "/a.txt" || move.Actor != "alice" || move.Bytes != -1 {
+		t.Errorf("Move should be recorded with source and the request actor, but got %+v", move)
+	}
+	if write := records[2]; write.Op != "NewWriter" || write.Bytes != 8 {
+		t.Errorf("NewWriter should be recorded on close with bytes written, but got %+v", write)
+	}
+	if failed := records[3]; failed.Result != ResultFailure || failed.Error == "" {
+		t.Errorf("Failed Delete should be recorded as failure, but got %+v", failed)
+	}
+	if batch := records[4]; batch.Op != "DeleteObjects" || len(batch.Paths) != 2 {
+		t.Errorf("DeleteObjects should record all paths, but got %+v", batch)
+	}
+	if storage.Actor != "system" {
+		t.Errorf("WithActor should not change the original wrapper, but got %v", storage.Actor)
+	}
+}
+
+func TestSinkError(t *testing.T) {
+	var dropped []Record
+	storage := Wrap(ossmock.New(), Options{
+		Sink:    SinkFunc(func(record Record) error { return errors.New("sink down") }),
+		OnError: func(record Record, err error) { dropped = append(dropped, record) },
+	})
+	if _, err := storage.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("Sink errors should not fail the operation, but got %v", err)
+	}
+	if len(dropped) != 1 || dropped[0].Op != "Put" {
+		t.Errorf("OnError should receive the dropped record, but got %+v", dropped)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		sink, err := NewFileSink(name)
+		if err != nil {
+			t.Fatalf("Failed to open file sink, got %v", err)
+		}
+		storage := Wrap(ossmock.New(), Options{Sink: sink})
+		storage.Put("/a.txt", strings.NewReader("sample"))
+		if err := sink.Close(); err != nil {
+			t.Fatalf("Failed to close file sink, got %v", err)
+		}
+	}
+
+	file, _ := os.Open(name)
+	defer file.Close()
+	var lines int
+	for scanner := bufio.NewScanner(file); scanner.Scan(); lines++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Op != "Put" {
+			t.Errorf("Each line should be a JSON record, but got %s", scanner.Text())
+		}
+	}
+	if lines != 2 {
+		t.Errorf("Reopening the sink should append, but got %d lines", lines)
+	}
+}
+
+func TestStorageSink(t *testing.T) {
+	audit := ossmock.New()
+	storage := Wrap(ossmock.New(), Options{Sink: NewStorageSink(audit, "/audit")})
+	storage.Put("/a.txt", strings.NewReader("sample"))
+	storage.Put("/a.txt", strings.NewReader("sample"))
+
+	objects, err := audit.List("/audit")
+	if err != nil || len(objects) != 2 {
+		t.Fatalf("Each record should be a separate object, but got %v, %v", objects, err)
+	}
+	for _, object := range objects {
+		if !strings.HasPrefix(object.Path, "/audit/") || !strings.HasSuffix(object.Path, ".json") {
+			t.Errorf("Record path should be under the prefix, but got %v", object.Path)
+		}
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var record Record
+		json.NewDecoder(r.Body).Decode(&record)
+		received = append(received, record)
+	}))
+	defer server.Close()
+
+	sink := &WebhookSink{URL: server.URL, Header: http.Header{"Authorization": {"Bearer token"}}}
+	if err := sink.Write(Record{ID: "1", Op: "Delete", Path: "/a.txt"}); err != nil {
+		t.Fatalf("Webhook should accept the record, got %v", err)
+	}
+	if len(received) != 1 || received[0].Path != "/a.txt" {
+		t.Errorf("Webhook should receive the record, but got %+v", received)
+	}
+
+	sink.Header = nil
+	if err := sink.Write(Record{ID: "2"}); err == nil {
+		t.Errorf("Non-2xx responses should be returned as errors")
+	}
+}
+
+var _ oss.StorageInterface = (*Storage)(nil)
@@ -0,0 +1,151 @@
+package ossaudit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/smart-unicom/oss"
+)
+
+// FileSink 以每行一条JSON的格式追加写入本地文件的接收端
+// 文件以追加模式打开，已写入的记录不会被覆盖
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink 打开或创建审计日志文件
+// 参数:
+//   - name: 文件路径
+// 返回:
+//   - *FileSink: 接收端实例
+//   - error: 错误信息
+func NewFileSink(name string) (*FileSink, error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write 追加一行审计记录
+// 参数:
+//   - record: 审计记录
+// 返回:
+//   - error: 错误信息
+func (sink *FileSink) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	_, err = sink.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close 将记录同步到磁盘并关闭文件
+// 返回:
+//   - error: 错误信息
+func (sink *FileSink) Close() error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if err := sink.file.Sync(); err != nil {
+		sink.file.Close()
+		return err
+	}
+	return sink.file.Close()
+}
+
+// StorageSink 将每条审计记录保存为另一个存储中单独对象的接收端
+// 对象路径为 <Prefix>/<年>/<月>/<日>/<时间>-<ID>.json，每条记录使用新的路径，不会覆盖已有记录；
+// 配合存储桶的对象锁定或只写权限可以防止记录被篡改
+type StorageSink struct {
+	// Storage 保存记录的存储接口，不应是被审计的存储本身
+	Storage oss.StorageInterface
+	// Prefix 记录的目录
+	Prefix string
+}
+
+// NewStorageSink 创建保存到存储的接收端
+// 参数:
+//   - storage: 保存记录的存储接口
+//   - prefix: 记录的目录，例如 /audit
+// 返回:
+//   - *StorageSink: 接收端实例
+func NewStorageSink(storage oss.StorageInterface, prefix string) *StorageSink {
+	return &StorageSink{Storage: storage, Prefix: prefix}
+}
+
+// RecordPath 返回审计记录保存的对象路径
+// 参数:
+//   - record: 审计记录
+// 返回:
+//   - string: 对象路径
+func (sink *StorageSink) RecordPath(record Record) string {
+	return "/" + oss.DirPrefix(sink.Prefix) + record.Time.UTC().Format("2006/01/02/150405.000000000") + "-" + record.ID + ".json"
+}
+
+// Write 将审计记录上传为新对象
+// 参数:
+//   - record: 审计记录
+// 返回:
+//   - error: 错误信息
+func (sink *StorageSink) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = sink.Storage.PutWithOptions(sink.RecordPath(record), bytes.NewReader(data), &oss.PutOptions{ContentType: "application/json"})
+	return err
+}
+
+// WebhookSink 以POST请求将审计记录发送到HTTP地址的接收端
+type WebhookSink struct {
+	// URL 接收记录的地址
+	URL string
+	// Header 附加的请求头，例如 Authorization
+	Header http.Header
+	// Client HTTP客户端，为nil时使用 oss.DefaultHTTPClient
+	Client *http.Client
+}
+
+// Write 发送审计记录，响应状态码不是2xx时返回错误
+// 参数:
+//   - record: 审计记录
+// 返回:
+//   - error: 错误信息
+func (sink *WebhookSink) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, sink.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range sink.Header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := sink.Client
+	if client == nil {
+		client = oss.DefaultHTTPClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("ossaudit: webhook returned %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package osscache
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// MemoryOptions 内存缓存的配置
+type MemoryOptions struct {
+	// MaxObjectSize 可以缓存的单个对象的最大字节数，大于该值的对象直接从源存储读取
+	MaxObjectSize int64
+	// MaxBytes 缓存的最大总字节数，超过时淘汰最久未使用的对象
+	MaxBytes int64
+	// TTL 缓存的有效期，过期后重新从源存储读取，小于等于0时不过期
+	TTL time.Duration
+}
+
+// MemoryStorage 缓存小对象的内存读缓存包装器
+// 用于头像、配置等体积小、读取频繁的对象，可以叠加在磁盘缓存 Storage 之上；
+// 与磁盘缓存一样，只有通过该包装器的写入和删除会使缓存失效
+type MemoryStorage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Options 缓存配置
+	Options MemoryOptions
+
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	lru         *list.List
+	size        int64
+	generations map[string]uint64
+	hits        int64
+	misses      int64
+}
+
+// memoryEntry 内存缓存中的一个对象
+type memoryEntry struct {
+	path    string
+	content []byte
+	expires time.Time
+}
+
+// NewMemory 创建缓存小对象的内存读缓存包装器
+// 参数:
+//   - origin: 源存储
+//   - opts: 缓存配置
+// 返回:
+//   - *MemoryStorage: 存储包装器实例
+//   - error: 配置无效时的错误信息
+func NewMemory(origin oss.StorageInterface, opts MemoryOptions) (*MemoryStorage, error) {
+	if opts.MaxObjectSize <= 0 || opts.MaxBytes <= 0 {
+		return nil, errors.New("osscache: MaxObjectSize and MaxBytes must be positive")
+	}
+	return &MemoryStorage{
+		StorageInterface: origin,
+		Options:          opts,
+		entries:          map[string]*list.Element{},
+		lru:              list.New(),
+		generations:      map[string]uint64{},
+	}, nil
+}
+
+// lookup 返回缓存的内容并标记为最近使用，未缓存或已过期时返回false
+func (storage *MemoryStorage) lookup(path string) ([]byte, bool) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	element, ok := storage.entries[path]
+	if ok && !element.Value.(*memoryEntry).expires.IsZero() && !oss.Now().Before(element.Value.(*memoryEntry).expires) {
+		storage.remove(element)
+		ok = false
+	}
+	if !ok {
+		storage.misses++
+		return nil, false
+	}
+	storage.lru.MoveToFront(element)
+	storage.hits++
+	return element.Value.(*memoryEntry).content, true
+}
+
+// GetStream 获取文件流，命中时返回内存中的内容，未命中时从源存储读取，不超过 MaxObjectSize 的对象写入缓存
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *MemoryStorage) GetStream(path string) (io.ReadCloser, error) {
+	key := normalize(path)
+	if content, ok := storage.lookup(key); ok {
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	storage.mu.Lock()
+	generation := storage.generations[key]
+	storage.mu.Unlock()
+
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	head, err := io.ReadAll(io.LimitReader(stream, storage.Options.MaxObjectSize+1))
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	if int64(len(head)) > storage.Options.MaxObjectSize {
+		// 对象太大，已读取的部分和剩余的流一起返回
+		return &prefixedReadCloser{Reader: io.MultiReader(bytes.NewReader(head), stream), Closer: stream}, nil
+	}
+	stream.Close()
+	storage.store(key, generation, head)
+	return io.NopCloser(bytes.NewReader(head)), nil
+}
+
+// GetStreamRange 范围读取文件流，命中时返回内存中的内容，未命中时直接从源存储读取，不写入缓存
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *MemoryStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	content, ok := storage.lookup(normalize(path))
+	if !ok {
+		return storage.StorageInterface.GetStreamRange(path, offset, length)
+	}
+	offset = min(max(offset, 0), int64(len(content)))
+	end := int64(len(content))
+	if length > 0 {
+		end = min(end, offset+length)
+	}
+	return io.NopCloser(bytes.NewReader(content[offset:end])), nil
+}
+
+// Put 上传文件并使缓存失效
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *MemoryStorage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.Put(path, reader)
+}
+
+// PutWithOptions 使用指定选项上传文件并使缓存失效
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *MemoryStorage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.PutWithOptions(path, reader, opts)
+}
+
+// NewWriter 创建流式写入器，创建时和关闭时使缓存失效
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *MemoryStorage) NewWriter(path string) (io.WriteCloser, error) {
+	storage.Invalidate(path)
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &invalidateWriter{WriteCloser: writer, invalidate: storage.Invalidate, path: path}, nil
+}
+
+// Delete 删除文件并使缓存失效
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *MemoryStorage) Delete(path string) error {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.Delete(path)
+}
+
+// DeleteObjects 批量删除对象并使缓存失效
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *MemoryStorage) DeleteObjects(paths []string) error {
+	defer func() {
+		for _, path := range paths {
+			storage.Invalidate(path)
+		}
+	}()
+	return storage.StorageInterface.DeleteObjects(paths)
+}
+
+// DeleteDir 删除目录并使目录下的缓存失效
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *MemoryStorage) DeleteDir(dir string) error {
+	defer storage.invalidatePrefix(strings.TrimSuffix(normalize(dir), "/") + "/")
+	return storage.StorageInterface.DeleteDir(dir)
+}
+
+// Copy 复制文件并使目标路径的缓存失效
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *MemoryStorage) Copy(srcPath, dstPath string) error {
+	defer storage.Invalidate(dstPath)
+	return storage.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 移动文件并使源路径和目标路径的缓存失效
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *MemoryStorage) Move(srcPath, dstPath string) error {
+	defer storage.Invalidate(srcPath)
+	defer storage.Invalidate(dstPath)
+	return storage.StorageInterface.Move(srcPath, dstPath)
+}
+
+// Invalidate 使指定路径的缓存失效，正在读取的未命中请求结束后也不会写入缓存
+// 参数:
+//   - path: 文件路径
+func (storage *MemoryStorage) Invalidate(path string) {
+	path = normalize(path)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.generations[path]++
+	if element, ok := storage.entries[path]; ok {
+		storage.remove(element)
+	}
+}
+
+// invalidatePrefix 使路径以prefix开头的缓存失效
+func (storage *MemoryStorage) invalidatePrefix(prefix string) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for path := range storage.generations {
+		if strings.HasPrefix(path, prefix) {
+			storage.generations[path]++
+		}
+	}
+	for path, element := range storage.entries {
+		if strings.HasPrefix(path, prefix) {
+			storage.generations[path]++
+			storage.remove(element)
+		}
+	}
+}
+
+// Purge 清空全部缓存
+func (storage *MemoryStorage) Purge() {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for path, element := range storage.entries {
+		storage.generations[path]++
+		storage.remove(element)
+	}
+}
+
+// Stats 返回缓存的统计信息
+// 返回:
+//   - Stats: 统计信息
+func (storage *MemoryStorage) Stats() Stats {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	return Stats{Objects: len(storage.entries), Bytes: storage.size, Hits: storage.hits, Misses: storage.misses}
+}
+
+// store 将读取的内容加入缓存，读取期间缓存被失效时丢弃
+func (storage *MemoryStorage) store(path string, generation uint64, content []byte) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.generations[path] != generation || int64(len(content)) > storage.Options.MaxBytes {
+		return
+	}
+	if element, ok := storage.entries[path]; ok {
+		storage.remove(element)
+	}
+	item := &memoryEntry{path: path, content: content}
+	if storage.Options.TTL > 0 {
+		item.expires = oss.Now().Add(storage.Options.TTL)
+	}
+	storage.entries[path] = storage.lru.PushFront(item)
+	storage.size += int64(len(content))
+	for storage.size > storage.Options.MaxBytes && storage.lru.Len() > 0 {
+		storage.remove(storage.lru.Back())
+	}
+}
+
+// remove 删除缓存中的对象，调用方需要持有锁
+func (storage *MemoryStorage) remove(element *list.Element) {
+	item := storage.lru.Remove(element).(*memoryEntry)
+	delete(storage.entries, item.path)
+	storage.size -= int64(len(item.content))
+}
+
+// prefixedReadCloser 先返回已读取的部分再返回剩余内容的文件流
+type prefixedReadCloser struct {
+	io.Reader
+	io.Closer
+}
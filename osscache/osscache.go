@@ -0,0 +1,490 @@
+// Package osscache 本地磁盘和内存读缓存的存储包装器
+// GetStream 和 GetStreamRange 优先读取本地缓存，未命中时从源存储读取并写入缓存，
+// 通过包装器的写入和删除会使缓存失效，缓存总大小超过上限时淘汰最久未使用的对象
+package osscache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/smart-unicom/oss"
+)
+
+// keySuffix 记录对象路径的文件后缀，缓存文件以路径的SHA-256命名
+const keySuffix = ".key"
+
+// Storage 本地磁盘读缓存的存储包装器
+// 只有通过该包装器的写入和删除会使缓存失效，其他程序直接修改源存储后需要调用 Invalidate 或 Purge
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Dir 缓存目录
+	Dir string
+	// MaxBytes 缓存的最大总字节数，超过时淘汰最久未使用的对象，大于该值的对象不会被缓存
+	MaxBytes int64
+
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	lru         *list.List
+	size        int64
+	generations map[string]uint64
+	hits        int64
+	misses      int64
+}
+
+// entry 缓存中的一个对象
+type entry struct {
+	path string
+	size int64
+}
+
+// Stats 缓存的统计信息
+type Stats struct {
+	// Objects 缓存的对象数
+	Objects int
+	// Bytes 缓存的总字节数
+	Bytes int64
+	// Hits 命中次数
+	Hits int64
+	// Misses 未命中次数
+	Misses int64
+}
+
+// New 创建本地磁盘读缓存的存储包装器
+// 缓存目录中已有的缓存文件会被重新加载，按修改时间确定使用顺序
+// 参数:
+//   - origin: 源存储
+//   - cacheDir: 缓存目录，不存在时创建
+//   - maxBytes: 缓存的最大总字节数
+// 返回:
+//   - *Storage: 存储包装器实例
+//   - error: 创建或读取缓存目录失败时的错误信息
+func New(origin oss.StorageInterface, cacheDir string, maxBytes int64) (*Storage, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("osscache: maxBytes must be positive")
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("osscache: %w", err)
+	}
+	storage := &Storage{
+		StorageInterface: origin,
+		Dir:              cacheDir,
+		MaxBytes:         maxBytes,
+		entries:          map[string]*list.Element{},
+		lru:              list.New(),
+		generations:      map[string]uint64{},
+	}
+	if err := storage.load(); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
+
+// load 加载缓存目录中已有的缓存文件，删除不完整的缓存文件和中断的写入留下的临时文件
+// 缓存目录可能与其他程序共用，只处理该包创建的64位十六进制文件名和 fill-*.tmp 临时文件，其他文件保持不变
+func (storage *Storage) load() error {
+	files, err := os.ReadDir(storage.Dir)
+	if err != nil {
+		return fmt.Errorf("osscache: %w", err)
+	}
+
+	type loaded struct {
+		entry
+		modTime int64
+	}
+	var items []loaded
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			continue
+		}
+		if matched, _ := filepath.Match("fill-*.tmp", name); matched {
+			os.Remove(filepath.Join(storage.Dir, name))
+			continue
+		}
+		if !isCacheFileName(name) {
+			continue
+		}
+		key, err := os.ReadFile(filepath.Join(storage.Dir, name+keySuffix))
+		info, statErr := file.Info()
+		if err != nil || statErr != nil || storage.fileName(string(key)) != name {
+			os.Remove(filepath.Join(storage.Dir, name))
+			os.Remove(filepath.Join(storage.Dir, name+keySuffix))
+			continue
+		}
+		items = append(items, loaded{entry{path: string(key), size: info.Size()}, info.ModTime().UnixNano()})
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].modTime > items[j].modTime })
+	for _, item := range items {
+		storage.entries[item.path] = storage.lru.PushBack(&entry{path: item.path, size: item.size})
+		storage.size += item.size
+	}
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.evict()
+	return nil
+}
+
+// fileName 返回对象路径对应的缓存文件名
+func (storage *Storage) fileName(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:])
+}
+
+// isCacheFileName 判断文件名是否为 fileName 生成的缓存文件名，不包括 .key 文件
+func isCacheFileName(name string) bool {
+	if len(name) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
+}
+
+// filePath 返回对象路径对应的缓存文件路径
+func (storage *Storage) filePath(path string) string {
+	return filepath.Join(storage.Dir, storage.fileName(path))
+}
+
+// normalize 统一对象路径的写法，/a.txt 和 a.txt 使用同一个缓存
+func normalize(path string) string {
+	return "/" + strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
+
+// open 打开缓存文件并标记为最近使用，未缓存时返回nil
+func (storage *Storage) open(path string) *os.File {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	element, ok := storage.entries[path]
+	if !ok {
+		storage.misses++
+		return nil
+	}
+	file, err := os.Open(storage.filePath(path))
+	if err != nil {
+		storage.remove(element)
+		storage.misses++
+		return nil
+	}
+	storage.lru.MoveToFront(element)
+	storage.hits++
+	return file
+}
+
+// GetStream 获取文件流，命中时读取本地缓存，未命中时从源存储读取，完整读取后写入缓存
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	key := normalize(path)
+	if file := storage.open(key); file != nil {
+		return file, nil
+	}
+
+	storage.mu.Lock()
+	generation := storage.generations[key]
+	storage.mu.Unlock()
+
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	temp, err := os.CreateTemp(storage.Dir, "fill-*.tmp")
+	if err != nil {
+		// 缓存目录不可写时仍然返回源存储的内容
+		return stream, nil
+	}
+	return &fillReader{ReadCloser: stream, storage: storage, path: key, generation: generation, temp: temp}, nil
+}
+
+// GetStreamRange 范围读取文件流，命中时读取本地缓存，未命中时直接从源存储读取，不写入缓存
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	file := storage.open(normalize(path))
+	if file == nil {
+		return storage.StorageInterface.GetStreamRange(path, offset, length)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if length <= 0 {
+		return file, nil
+	}
+	return &rangeReader{Reader: io.LimitReader(file, length), file: file}, nil
+}
+
+// Put 上传文件并使缓存失效
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.Put(path, reader)
+}
+
+// PutWithOptions 使用指定选项上传文件并使缓存失效
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.PutWithOptions(path, reader, opts)
+}
+
+// NewWriter 创建流式写入器，创建时和关闭时使缓存失效
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	storage.Invalidate(path)
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &invalidateWriter{WriteCloser: writer, invalidate: storage.Invalidate, path: path}, nil
+}
+
+// Delete 删除文件并使缓存失效
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Delete(path string) error {
+	defer storage.Invalidate(path)
+	return storage.StorageInterface.Delete(path)
+}
+
+// DeleteObjects 批量删除对象并使缓存失效
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	defer func() {
+		for _, path := range paths {
+			storage.Invalidate(path)
+		}
+	}()
+	return storage.StorageInterface.DeleteObjects(paths)
+}
+
+// DeleteDir 删除目录并使目录下的缓存失效
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteDir(dir string) error {
+	defer storage.invalidatePrefix(strings.TrimSuffix(normalize(dir), "/") + "/")
+	return storage.StorageInterface.DeleteDir(dir)
+}
+
+// Copy 复制文件并使目标路径的缓存失效
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	defer storage.Invalidate(dstPath)
+	return storage.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 移动文件并使源路径和目标路径的缓存失效
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	defer storage.Invalidate(srcPath)
+	defer storage.Invalidate(dstPath)
+	return storage.StorageInterface.Move(srcPath, dstPath)
+}
+
+// Invalidate 使指定路径的缓存失效，正在写入缓存的读取结束后也不会写入
+// 参数:
+//   - path: 文件路径
+func (storage *Storage) Invalidate(path string) {
+	path = normalize(path)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.generations[path]++
+	if element, ok := storage.entries[path]; ok {
+		storage.remove(element)
+	}
+}
+
+// invalidatePrefix 使路径以prefix开头的缓存失效
+func (storage *Storage) invalidatePrefix(prefix string) {
+	storage.mu.Lock()
+	var paths []string
+	for path := range storage.entries {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	for path := range storage.generations {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	storage.mu.Unlock()
+	for _, path := range paths {
+		storage.Invalidate(path)
+	}
+}
+
+// Purge 清空全部缓存
+func (storage *Storage) Purge() {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for path, element := range storage.entries {
+		storage.generations[path]++
+		storage.remove(element)
+	}
+}
+
+// Stats 返回缓存的统计信息
+// 返回:
+//   - Stats: 统计信息
+func (storage *Storage) Stats() Stats {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	return Stats{Objects: len(storage.entries), Bytes: storage.size, Hits: storage.hits, Misses: storage.misses}
+}
+
+// remove 删除缓存中的对象，调用方需要持有锁
+func (storage *Storage) remove(element *list.Element) {
+	item := storage.lru.Remove(element).(*entry)
+	delete(storage.entries, item.path)
+	storage.size -= item.size
+	os.Remove(storage.filePath(item.path))
+	os.Remove(storage.filePath(item.path) + keySuffix)
+}
+
+// evict 淘汰最久未使用的对象直到总大小不超过上限，调用方需要持有锁
+func (storage *Storage) evict() {
+	for storage.size > storage.MaxBytes && storage.lru.Len() > 0 {
+		storage.remove(storage.lru.Back())
+	}
+}
+
+// commit 将完整读取的临时文件加入缓存，读取期间缓存被失效时丢弃
+func (storage *Storage) commit(path string, generation uint64, temp string, size int64) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.generations[path] != generation || size > storage.MaxBytes {
+		return errors.New("osscache: stale or too large")
+	}
+	if element, ok := storage.entries[path]; ok {
+		storage.remove(element)
+	}
+	target := storage.filePath(path)
+	if err := os.WriteFile(target+keySuffix, []byte(path), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, target); err != nil {
+		os.Remove(target + keySuffix)
+		return err
+	}
+	storage.entries[path] = storage.lru.PushFront(&entry{path: path, size: size})
+	storage.size += size
+	storage.evict()
+	return nil
+}
+
+// fillReader 读取源存储的同时写入临时文件，完整读取到末尾并关闭后加入缓存
+type fillReader struct {
+	io.ReadCloser
+	storage    *Storage
+	path       string
+	generation uint64
+	temp       *os.File
+	size       int64
+	complete   bool
+	failed     bool
+}
+
+// Read 读取源存储的内容并写入临时文件，写入失败时停止缓存但不影响读取
+func (reader *fillReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	if n > 0 && !reader.failed {
+		if _, writeErr := reader.temp.Write(p[:n]); writeErr != nil {
+			reader.failed = true
+		}
+		reader.size += int64(n)
+		if reader.size > reader.storage.MaxBytes {
+			reader.failed = true
+		}
+	}
+	switch {
+	case err == io.EOF:
+		reader.complete = true
+	case err != nil:
+		reader.failed = true
+	}
+	return n, err
+}
+
+// Close 关闭源存储的流，完整读取时将临时文件加入缓存
+func (reader *fillReader) Close() error {
+	err := reader.ReadCloser.Close()
+	name := reader.temp.Name()
+	closeErr := reader.temp.Close()
+	if err != nil || closeErr != nil || !reader.complete || reader.failed ||
+		reader.storage.commit(reader.path, reader.generation, name, reader.size) != nil {
+		os.Remove(name)
+	}
+	return err
+}
+
+// rangeReader 读取缓存文件的一部分
+type rangeReader struct {
+	io.Reader
+	file *os.File
+}
+
+// Close 关闭缓存文件
+func (reader *rangeReader) Close() error {
+	return reader.file.Close()
+}
+
+// invalidateWriter 关闭时使缓存失效的写入器
+type invalidateWriter struct {
+	io.WriteCloser
+	invalidate func(path string)
+	path       string
+}
+
+// Close 关闭写入器并使缓存失效
+func (writer *invalidateWriter) Close() error {
+	defer writer.invalidate(writer.path)
+	return writer.WriteCloser.Close()
+}
@@ -0,0 +1,126 @@
+package osscache
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestCache(t *testing.T) {
+	origin := ossmock.New()
+	dir := t.TempDir()
+	storage, err := New(origin, dir, 10)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	origin.Put("/a.txt", strings.NewReader("aaaa"))
+	if content := string(tests.ReadObject(t, storage, "/a.txt")); content != "aaaa" {
+		t.Errorf("Cache miss should read origin, but got %v", content)
+	}
+	if content := string(tests.ReadObject(t, storage, "a.txt")); content != "aaaa" {
+		t.Errorf("Cache hit should return cached content, but got %v", content)
+	}
+	if calls := len(origin.CallsTo("GetStream")); calls != 1 {
+		t.Errorf("Cache hit should not read origin, but GetStream was called %v times", calls)
+	}
+	stream, _ := storage.GetStreamRange("/a.txt", 1, 2)
+	part, _ := io.ReadAll(stream)
+	stream.Close()
+	if string(part) != "aa" || len(origin.CallsTo("GetStreamRange")) != 0 {
+		t.Errorf("Range read should be served from cache, but got %v", string(part))
+	}
+
+	storage.Put("/a.txt", strings.NewReader("bbbb"))
+	if content := string(tests.ReadObject(t, storage, "/a.txt")); content != "bbbb" {
+		t.Errorf("Put should invalidate cache, but got %v", content)
+	}
+
+	origin.Put("/b.txt", strings.NewReader("12345"))
+	origin.Put("/c.txt", strings.NewReader("67890"))
+	tests.ReadObject(t, storage, "/b.txt")
+	tests.ReadObject(t, storage, "/c.txt")
+	if stats := storage.Stats(); stats.Bytes > 10 || stats.Objects != 2 {
+		t.Errorf("Least recently used object should be evicted, but got %+v", stats)
+	}
+	tests.ReadObject(t, storage, "/a.txt")
+	if calls := len(origin.CallsTo("GetStream")); calls != 5 {
+		t.Errorf("Evicted object should be read from origin again, but GetStream was called %v times", calls)
+	}
+
+	partial, _ := storage.GetStream("/b.txt")
+	partial.Read(make([]byte, 1))
+	partial.Close()
+	storage.Delete("/b.txt")
+	if _, err := storage.GetStream("/b.txt"); err == nil {
+		t.Errorf("Delete should invalidate cache")
+	}
+
+	unrelated := []string{"important.db", "important.db" + keySuffix, strings.Repeat("A", 64)}
+	for _, name := range append(unrelated, "fill-1.tmp") {
+		os.WriteFile(filepath.Join(dir, name), []byte("data"), 0o644)
+	}
+	reopened, err := New(origin, dir, 10)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if stats := reopened.Stats(); stats.Objects != storage.Stats().Objects || stats.Bytes != storage.Stats().Bytes {
+		t.Errorf("Reopened cache should load existing files, but got %+v", stats)
+	}
+	for _, name := range unrelated {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Files not created by the cache should be kept, but %v got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fill-1.tmp")); !os.IsNotExist(err) {
+		t.Errorf("Interrupted fill should be removed, but got %v", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	now := time.Now()
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+
+	origin := ossmock.New()
+	storage, err := NewMemory(origin, MemoryOptions{MaxObjectSize: 4, MaxBytes: 8, TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewMemory failed: %v", err)
+	}
+
+	origin.Put("/avatar.png", strings.NewReader("icon"))
+	origin.Put("/large.bin", strings.NewReader("0123456789"))
+	tests.ReadObject(t, storage, "/avatar.png")
+	if content := string(tests.ReadObject(t, storage, "/avatar.png")); content != "icon" || len(origin.CallsTo("GetStream")) != 1 {
+		t.Errorf("Small object should be served from memory, but got %v", content)
+	}
+	if content := string(tests.ReadObject(t, storage, "/large.bin")); content != "0123456789" {
+		t.Errorf("Large object should be read completely from origin, but got %v", content)
+	}
+	if stats := storage.Stats(); stats.Objects != 1 || stats.Bytes != 4 {
+		t.Errorf("Large object should not be cached, but got %+v", stats)
+	}
+
+	now = now.Add(2 * time.Minute)
+	tests.ReadObject(t, storage, "/avatar.png")
+	if calls := len(origin.CallsTo("GetStream")); calls != 3 {
+		t.Errorf("Expired object should be read from origin again, but GetStream was called %v times", calls)
+	}
+
+	storage.Put("/avatar.png", strings.NewReader("new!"))
+	if content := string(tests.ReadObject(t, storage, "/avatar.png")); content != "new!" {
+		t.Errorf("Put should invalidate memory cache, but got %v", content)
+	}
+	storage.Invalidate("/avatar.png")
+	if stats := storage.Stats(); stats.Objects != 0 {
+		t.Errorf("Invalidate should remove the object, but got %+v", stats)
+	}
+}
@@ -0,0 +1,388 @@
+// Package osscompress 透明压缩对象内容的存储包装器
+// 上传时按内容类型白名单压缩文本、JSON等对象，下载时根据对象开头的标记解压，
+// 减少文本类对象的存储空间和流出流量；没有标记的对象原样返回，可以直接包装已有的存储桶
+package osscompress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	pathpkg "path"
+	"strings"
+
+	"github.com/smart-unicom/oss"
+)
+
+const (
+	// DefaultMinSize 默认只压缩不小于该大小的对象，更小的对象压缩后通常不会变小
+	DefaultMinSize = 1024
+
+	magic = "OSZ\x01"
+	// maxHeaderSize 标记的最大长度：魔数、名称长度和名称
+	maxHeaderSize = len(magic) + 1 + 255
+)
+
+// DefaultContentTypes 默认压缩的内容类型，type/* 匹配该类型下的全部子类型
+var DefaultContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/x-ndjson",
+	"application/xml",
+	"application/javascript",
+	"application/x-yaml",
+	"image/svg+xml",
+}
+
+// ErrUnknownCodec 对象使用了未配置的压缩算法
+var ErrUnknownCodec = errors.New("osscompress: unknown codec")
+
+// Codec 压缩算法
+type Codec interface {
+	// Name 算法名称，保存在对象开头的标记中，不超过255字节
+	Name() string
+	// NewWriter 创建压缩写入器
+	// 参数:
+	//   - writer: 写入压缩后内容的写入器
+	// 返回:
+	//   - io.WriteCloser: 压缩写入器，关闭时写入剩余内容，不关闭writer
+	//   - error: 错误信息
+	NewWriter(writer io.Writer) (io.WriteCloser, error)
+	// NewReader 创建解压读取器
+	// 参数:
+	//   - reader: 压缩后的内容
+	// 返回:
+	//   - io.ReadCloser: 解压读取器，关闭时不关闭reader
+	//   - error: 错误信息
+	NewReader(reader io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCodec gzip压缩算法
+type GzipCodec struct {
+	// Level 压缩级别，与 compress/gzip 相同，为0时使用 gzip.DefaultCompression
+	Level int
+}
+
+// Gzip 默认压缩级别的gzip压缩算法
+var Gzip Codec = GzipCodec{}
+
+// Name 返回 gzip
+func (codec GzipCodec) Name() string {
+	return "gzip"
+}
+
+// NewWriter 创建gzip压缩写入器
+func (codec GzipCodec) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	level := codec.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(writer, level)
+}
+
+// NewReader 创建gzip解压读取器
+func (codec GzipCodec) NewReader(reader io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(reader)
+}
+
+// Options 压缩选项
+type Options struct {
+	// Codec 上传时使用的压缩算法，为nil时使用 Gzip
+	Codec Codec
+	// Codecs 下载时额外支持的压缩算法，更换 Codec 后用于读取之前上传的对象
+	Codecs []Codec
+	// ContentTypes 需要压缩的内容类型，为nil时使用 DefaultContentTypes
+	ContentTypes []string
+	// MinSize 只压缩不小于该大小的对象，大小未知时总是压缩，小于0时压缩所有大小的对象，为0时使用 DefaultMinSize
+	MinSize int64
+}
+
+// Storage 透明压缩对象内容的存储包装器
+// Put、PutWithOptions 和 NewWriter 压缩内容类型在白名单中的对象，Get、GetStream 和 GetStreamRange 返回解压后的内容；
+// Stat 和 List 返回的是存储中压缩后的大小。Copy 和 Move 直接复制压缩后的内容
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Options 压缩选项
+	Options Options
+}
+
+// Wrap 创建透明压缩对象内容的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 压缩选项
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, opts Options) *Storage {
+	if opts.Codec == nil {
+		opts.Codec = Gzip
+	}
+	if opts.ContentTypes == nil {
+		opts.ContentTypes = DefaultContentTypes
+	}
+	if opts.MinSize == 0 {
+		opts.MinSize = DefaultMinSize
+	}
+	return &Storage{StorageInterface: storage, Options: opts}
+}
+
+// contentType 返回上传的内容类型，未指定时根据扩展名判断
+func contentType(path, declared string) string {
+	if declared != "" {
+		return declared
+	}
+	if contentType := mime.TypeByExtension(pathpkg.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// Compressible 判断内容类型是否在白名单中
+// 参数:
+//   - contentType: 内容类型，可以带有 charset 等参数
+// 返回:
+//   - bool: 是否需要压缩
+func (storage *Storage) Compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range storage.Options.ContentTypes {
+		if pattern == mediaType || strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, pattern[:len(pattern)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// header 返回压缩算法的标记
+func header(codec Codec) []byte {
+	name := codec.Name()
+	return append(append([]byte(magic), byte(len(name))), name...)
+}
+
+// codec 按名称查找压缩算法
+func (storage *Storage) codec(name string) (Codec, error) {
+	if storage.Options.Codec.Name() == name {
+		return storage.Options.Codec, nil
+	}
+	for _, codec := range storage.Options.Codecs {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+}
+
+// compress 在后台压缩内容，返回压缩后内容的读取器
+func (storage *Storage) compress(reader io.Reader) io.ReadCloser {
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		_, err := pipeWriter.Write(header(storage.Options.Codec))
+		if err == nil {
+			var writer io.WriteCloser
+			if writer, err = storage.Options.Codec.NewWriter(pipeWriter); err == nil {
+				_, err = io.Copy(writer, reader)
+				if closeErr := writer.Close(); err == nil {
+					err = closeErr
+				}
+			}
+		}
+		pipeWriter.CloseWithError(err)
+	}()
+	return pipeReader
+}
+
+// Put 上传文件，内容类型在白名单中时压缩
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息，大小为压缩后的大小
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件，内容类型在白名单中时压缩
+// 内容类型按 opts.ContentType 或扩展名判断，压缩时不再根据内容检测；校验和按压缩后的内容计算，调用方预先计算的 ChecksumValue 会被忽略
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息，大小为压缩后的大小
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	var putOpts oss.PutOptions
+	if opts != nil {
+		putOpts = *opts
+	}
+	putOpts.ContentType = contentType(path, putOpts.ContentType)
+	size := oss.ReaderSize(reader)
+	if !storage.Compressible(putOpts.ContentType) || size >= 0 && size < storage.Options.MinSize {
+		return storage.StorageInterface.PutWithOptions(path, reader, opts)
+	}
+
+	putOpts.ChecksumValue = ""
+	compressed := storage.compress(reader)
+	defer compressed.Close()
+	return storage.StorageInterface.PutWithOptions(path, compressed, &putOpts)
+}
+
+// NewWriter 创建流式写入器，扩展名对应的内容类型在白名单中时压缩
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil || !storage.Compressible(contentType(path, "")) {
+		return writer, err
+	}
+	if _, err := writer.Write(header(storage.Options.Codec)); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	compressor, err := storage.Options.Codec.NewWriter(writer)
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return &compressWriter{WriteCloser: compressor, dst: writer}, nil
+}
+
+// compressWriter 关闭时先写入剩余的压缩内容再关闭被包装的写入器
+type compressWriter struct {
+	io.WriteCloser
+	dst io.WriteCloser
+}
+
+// Close 关闭压缩写入器和被包装的写入器
+func (writer *compressWriter) Close() error {
+	err := writer.WriteCloser.Close()
+	if closeErr := writer.dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// decompress 读取流开头的标记，压缩的对象返回解压读取器，没有标记时原样返回
+func (storage *Storage) decompress(stream io.ReadCloser) (io.ReadCloser, bool, error) {
+	buffered := bufio.NewReaderSize(stream, maxHeaderSize)
+	prefix, _ := buffered.Peek(len(magic) + 1)
+	plain := &readCloser{Reader: buffered, closers: []io.Closer{stream}}
+	if len(prefix) < len(magic)+1 || string(prefix[:len(magic)]) != magic {
+		return plain, false, nil
+	}
+
+	nameLength := int(prefix[len(magic)])
+	head, err := buffered.Peek(len(magic) + 1 + nameLength)
+	if err != nil {
+		return plain, false, nil
+	}
+	codec, err := storage.codec(string(head[len(magic)+1:]))
+	if err != nil {
+		return nil, true, err
+	}
+	buffered.Discard(len(head))
+	reader, err := codec.NewReader(buffered)
+	if err != nil {
+		return nil, true, err
+	}
+	return &readCloser{Reader: reader, closers: []io.Closer{reader, stream}}, true, nil
+}
+
+// readCloser 关闭时依次关闭解压读取器和原始的流
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close 依次关闭解压读取器和原始的流
+func (reader *readCloser) Close() error {
+	var errs []error
+	for _, closer := range reader.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// GetStream 获取文件流，压缩的对象返回解压后的内容
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息，对象使用了未配置的压缩算法时返回 ErrUnknownCodec
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, _, err := storage.decompress(stream)
+	if err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reader, nil
+}
+
+// GetStreamRange 范围读取文件流
+// 先读取对象开头判断是否压缩，未压缩的对象直接范围读取，压缩的对象需要从头解压并跳过offset之前的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 解压后内容中的起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	probe, err := storage.StorageInterface.GetStreamRange(path, 0, int64(len(magic)))
+	if err != nil {
+		return nil, err
+	}
+	prefix, _ := io.ReadAll(io.LimitReader(probe, int64(len(magic))))
+	probe.Close()
+	if !bytes.Equal(prefix, []byte(magic)) {
+		return storage.StorageInterface.GetStreamRange(path, offset, length)
+	}
+
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return oss.LimitStream(stream, offset, length)
+}
+
+// Get 获取文件，压缩的对象解压到临时文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (*os.File, error) {
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	file, err := oss.CreateTempFile("osscompress-*" + pathpkg.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, stream); err != nil {
+		oss.RemoveTempFile(file)
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		oss.RemoveTempFile(file)
+		return nil, err
+	}
+	return file, nil
+}
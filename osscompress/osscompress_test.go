@@ -0,0 +1,114 @@
+package osscompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+	"github.com/smart-unicom/oss/tests"
+)
+
+// flateCodec 测试用的第二种压缩算法
+type flateCodec struct{}
+
+func (flateCodec) Name() string { return "deflate" }
+
+func (flateCodec) NewWriter(writer io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(writer, flate.BestSpeed)
+}
+
+func (flateCodec) NewReader(reader io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(reader), nil
+}
+
+func TestCompression(t *testing.T) {
+	mock := ossmock.New()
+	storage := Wrap(mock, Options{})
+	content := strings.Repeat(`{"name":"value","count":12345}`+"\n", 500)
+
+	object, err := storage.Put("/data.json", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("No error should happen when put, but got %v", err)
+	}
+	stored := tests.ReadObject(t, mock, "/data.json")
+	if !bytes.HasPrefix(stored, []byte(magic+"\x04gzip")) || len(stored) >= len(content) || object.Size != int64(len(stored)) {
+		t.Errorf("JSON object should be stored compressed, but got %v of %v bytes", len(stored), len(content))
+	}
+	if data, err := tests.ReadStream(storage.GetStream("/data.json")); err != nil || string(data) != content {
+		t.Errorf("GetStream should decompress, but got %v bytes, %v", len(data), err)
+	}
+	if data, err := tests.ReadStream(storage.GetStreamRange("/data.json", 100, 50)); err != nil || string(data) != content[100:150] {
+		t.Errorf("GetStreamRange should read the decompressed range, but got %q, %v", data, err)
+	}
+	file, err := storage.Get("/data.json")
+	if err != nil {
+		t.Fatalf("No error should happen when get, but got %v", err)
+	}
+	data, _ := io.ReadAll(file)
+	oss.RemoveTempFile(file)
+	if string(data) != content {
+		t.Errorf("Get should decompress to a temp file, but got %v bytes", len(data))
+	}
+
+	binary := strings.Repeat("x", 2048)
+	storage.Put("/image.png", strings.NewReader(binary))
+	storage.PutWithOptions("/small.txt", strings.NewReader("small"), nil)
+	storage.PutWithOptions("/blob", strings.NewReader(binary), &oss.PutOptions{ContentType: "text/plain; charset=utf-8"})
+	if string(tests.ReadObject(t, mock, "/image.png")) != binary || string(tests.ReadObject(t, mock, "/small.txt")) != "small" {
+		t.Errorf("Objects outside the allowlist or below MinSize should be stored as is")
+	}
+	if !bytes.HasPrefix(tests.ReadObject(t, mock, "/blob"), []byte(magic)) {
+		t.Errorf("Declared content type should be used for the allowlist")
+	}
+	if data, err := tests.ReadStream(storage.GetStreamRange("/image.png", 10, 5)); err != nil || string(data) != "xxxxx" {
+		t.Errorf("Range of an uncompressed object should be read directly, but got %q, %v", data, err)
+	}
+
+	writer, _ := storage.NewWriter("/log.txt")
+	for i := 0; i < 100; i++ {
+		io.WriteString(writer, "line of text\n")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("No error should happen when close writer, but got %v", err)
+	}
+	if data, err := tests.ReadStream(storage.GetStream("/log.txt")); err != nil || string(data) != strings.Repeat("line of text\n", 100) {
+		t.Errorf("Writer content should be decompressed, but got %v bytes, %v", len(data), err)
+	}
+	if !bytes.HasPrefix(tests.ReadObject(t, mock, "/log.txt"), []byte(magic)) {
+		t.Errorf("Writer should compress text objects")
+	}
+
+	mock.Put("/legacy.txt", strings.NewReader("uploaded before compression"))
+	if data, err := tests.ReadStream(storage.GetStream("/legacy.txt")); err != nil || string(data) != "uploaded before compression" {
+		t.Errorf("Objects without the marker should be returned as is, but got %q, %v", data, err)
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	mock := ossmock.New()
+	content := strings.Repeat("compressible text ", 200)
+	Wrap(mock, Options{Codec: flateCodec{}}).Put("/a.txt", strings.NewReader(content))
+	Wrap(mock, Options{}).Put("/b.txt", strings.NewReader(content))
+
+	if _, err := Wrap(mock, Options{}).GetStream("/a.txt"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Object compressed with an unconfigured codec should be reported, but got %v", err)
+	}
+	storage := Wrap(mock, Options{Codecs: []Codec{flateCodec{}}})
+	for _, path := range []string{"/a.txt", "/b.txt"} {
+		if data, err := tests.ReadStream(storage.GetStream(path)); err != nil || string(data) != content {
+			t.Errorf("%v should be decompressed with the configured codecs, but got %v bytes, %v", path, len(data), err)
+		}
+	}
+
+	storage = Wrap(mock, Options{ContentTypes: []string{"application/octet-stream"}, MinSize: -1})
+	storage.Put("/c.bin", bytes.NewReader([]byte{1}))
+	storage.Put("/d.txt", strings.NewReader(content))
+	if !bytes.HasPrefix(tests.ReadObject(t, mock, "/c.bin"), []byte(magic)) || tests.ReadObject(t, mock, "/d.txt")[0] == magic[0] {
+		t.Errorf("Custom allowlist should replace the default content types")
+	}
+}
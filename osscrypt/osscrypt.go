@@ -0,0 +1,529 @@
+// Package osscrypt 在客户端加密对象内容的存储包装器
+// 上传前使用 AES-256-GCM 加密，下载后解密，存储服务和网络中只有密文，不依赖存储服务的服务端加密。
+// 每个对象使用随机生成的数据密钥加密，数据密钥由主密钥加密后与主密钥的标识一起保存在对象开头的头部中，
+// 轮换主密钥时只需重新加密头部
+package osscrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	pathpkg "path"
+
+	"github.com/smart-unicom/oss"
+)
+
+const (
+	// KeySize 主密钥和数据密钥的长度，使用 AES-256
+	KeySize = 32
+	// MaxKeyIDLength 主密钥标识的最大长度
+	MaxKeyIDLength = 64
+	// HeaderSize 对象开头的加密头部长度
+	HeaderSize = len(magic) + 1 + MaxKeyIDLength + nonceSize + KeySize + tagSize
+	// ChunkSize 内容按块加密的明文块大小，每块带有独立的认证标签，范围读取只需解密覆盖的块
+	ChunkSize = 64 << 10
+
+	magic     = "OSC1"
+	nonceSize = 12
+	tagSize   = 16
+)
+
+var (
+	// ErrNotEncrypted 对象没有加密头部，例如包装之前上传的对象
+	ErrNotEncrypted = errors.New("osscrypt: object is not encrypted")
+	// ErrDecrypt 密文认证失败，对象被篡改、损坏或使用了错误的密钥
+	ErrDecrypt = errors.New("osscrypt: message authentication failed")
+	// ErrInvalidKey 主密钥长度不是 KeySize 或标识超过 MaxKeyIDLength
+	ErrInvalidKey = errors.New("osscrypt: invalid key")
+	// ErrKeyNotFound 找不到对象头部中记录的主密钥
+	ErrKeyNotFound = errors.New("osscrypt: key not found")
+)
+
+// KeyProvider 提供主密钥，例如从密钥管理服务或配置中读取
+type KeyProvider interface {
+	// CurrentKey 返回加密新对象使用的主密钥
+	// 返回:
+	//   - string: 主密钥标识，保存在对象头部中
+	//   - []byte: KeySize 字节的主密钥
+	//   - error: 错误信息
+	CurrentKey() (string, []byte, error)
+	// Key 返回解密对象使用的主密钥
+	// 参数:
+	//   - id: 对象头部中记录的主密钥标识
+	// 返回:
+	//   - []byte: KeySize 字节的主密钥
+	//   - error: 错误信息，找不到时返回 ErrKeyNotFound
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeys 固定的主密钥集合，轮换时将新密钥加入 Keys 并修改 Current，旧密钥保留到所有对象完成轮换
+type StaticKeys struct {
+	// Current 加密新对象使用的主密钥标识
+	Current string
+	// Keys 主密钥标识到主密钥的映射
+	Keys map[string][]byte
+}
+
+// CurrentKey 返回 Current 对应的主密钥
+func (keys *StaticKeys) CurrentKey() (string, []byte, error) {
+	key, err := keys.Key(keys.Current)
+	return keys.Current, key, err
+}
+
+// Key 返回标识对应的主密钥
+func (keys *StaticKeys) Key(id string) ([]byte, error) {
+	key, ok := keys.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, id)
+	}
+	return key, nil
+}
+
+// Storage 在客户端加密对象内容的存储包装器
+// Put、PutWithOptions 和 NewWriter 上传密文，Get、GetStream 和 GetStreamRange 返回解密后的内容，
+// Stat 和 List 返回明文大小；Copy 和 Move 直接复制密文。GetURL 和 GetSignedURL 的链接下载的是密文，
+// GetUploadURL 会绕过加密，返回 oss.ErrNotSupported
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Keys 主密钥
+	Keys KeyProvider
+}
+
+// Wrap 创建在客户端加密对象内容的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - keys: 主密钥
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, keys KeyProvider) *Storage {
+	return &Storage{StorageInterface: storage, Keys: keys}
+}
+
+// newAEAD 创建 AES-256-GCM 加密器
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: key must be %d bytes, got %d", ErrInvalidKey, KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// newHeader 生成数据密钥，返回使用当前主密钥加密数据密钥后的头部和内容加密器
+// 数据密钥和头部的随机数总是取自 crypto/rand，不使用可以被替换的 oss.DefaultRandom，
+// 块的随机数由块序号生成，数据密钥重复会导致随机数重复
+func (storage *Storage) newHeader() ([]byte, cipher.AEAD, error) {
+	id, master, err := storage.Keys.CurrentKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, err
+	}
+	header, err := sealHeader(id, master, dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	return header, aead, err
+}
+
+// sealHeader 使用主密钥加密数据密钥，主密钥标识作为附加数据防止被替换
+func sealHeader(id string, master, dataKey []byte) ([]byte, error) {
+	if len(id) > MaxKeyIDLength {
+		return nil, fmt.Errorf("%w: key id longer than %d bytes", ErrInvalidKey, MaxKeyIDLength)
+	}
+	aead, err := newAEAD(master)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, HeaderSize)
+	prefix := len(magic) + 1 + MaxKeyIDLength
+	copy(header, magic)
+	header[len(magic)] = byte(len(id))
+	copy(header[len(magic)+1:], id)
+	nonce := header[prefix : prefix+nonceSize]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	aead.Seal(header[:prefix+nonceSize], nonce, dataKey, header[:prefix])
+	return header, nil
+}
+
+// openHeader 解析头部，返回主密钥标识和数据密钥
+func (storage *Storage) openHeader(header []byte) (string, []byte, error) {
+	id, err := headerKeyID(header)
+	if err != nil {
+		return "", nil, err
+	}
+	prefix := len(magic) + 1 + MaxKeyIDLength
+	master, err := storage.Keys.Key(id)
+	if err != nil {
+		return "", nil, err
+	}
+	aead, err := newAEAD(master)
+	if err != nil {
+		return "", nil, err
+	}
+	dataKey, err := aead.Open(nil, header[prefix:prefix+nonceSize], header[prefix+nonceSize:], header[:prefix])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: data key of key %s", ErrDecrypt, id)
+	}
+	return id, dataKey, nil
+}
+
+// headerKeyID 返回头部中的主密钥标识
+func headerKeyID(header []byte) (string, error) {
+	if len(header) != HeaderSize || string(header[:len(magic)]) != magic || int(header[len(magic)]) > MaxKeyIDLength {
+		return "", ErrNotEncrypted
+	}
+	return string(header[len(magic)+1 : len(magic)+1+int(header[len(magic)])]), nil
+}
+
+// readHeader 读取流开头的头部，返回内容解密器
+func (storage *Storage) readHeader(stream io.Reader) (cipher.AEAD, error) {
+	header := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(stream, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotEncrypted
+		}
+		return nil, err
+	}
+	_, dataKey, err := storage.openHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	return newAEAD(dataKey)
+}
+
+// CiphertextSize 返回明文加密后的对象大小
+// 参数:
+//   - size: 明文大小
+// 返回:
+//   - int64: 密文大小，包括头部和每块的认证标签
+func CiphertextSize(size int64) int64 {
+	return int64(HeaderSize) + size + chunkCount(size)*tagSize
+}
+
+// PlaintextSize 返回密文对应的明文大小
+// 参数:
+//   - size: 对象大小
+// 返回:
+//   - int64: 明文大小，对象小于最小的加密对象时返回-1
+func PlaintextSize(size int64) int64 {
+	body := size - int64(HeaderSize)
+	if body < tagSize {
+		return -1
+	}
+	chunks := (body + ChunkSize + tagSize - 1) / (ChunkSize + tagSize)
+	return body - chunks*tagSize
+}
+
+// chunkCount 返回明文分成的块数，空内容也有一个块
+func chunkCount(size int64) int64 {
+	if size <= 0 {
+		return 1
+	}
+	return (size + ChunkSize - 1) / ChunkSize
+}
+
+// plaintext 将对象信息中的大小改为明文大小
+func plaintext(object *oss.Object) *oss.Object {
+	if object == nil {
+		return nil
+	}
+	if size := PlaintextSize(object.Size); size >= 0 {
+		object.Size = size
+	}
+	return object
+}
+
+// Get 获取文件，解密到临时文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 解密后的临时文件
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (*os.File, error) {
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	file, err := oss.CreateTempFile("osscrypt-*" + pathpkg.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, stream); err != nil {
+		oss.RemoveTempFile(file)
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		oss.RemoveTempFile(file)
+		return nil, err
+	}
+	return file, nil
+}
+
+// GetStream 获取解密后的文件流
+// 读取到被篡改的块时返回 ErrDecrypt，密文被截断时返回 oss.ErrShortRead
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 解密后的文件流
+//   - error: 错误信息，对象没有加密头部时返回 ErrNotEncrypted
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := storage.readHeader(stream)
+	if err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return newDecryptReader(stream, aead, 0, 0, -1), nil
+}
+
+// GetStreamRange 范围读取解密后的文件流，只下载和解密范围覆盖的块
+// 参数:
+//   - path: 文件路径
+//   - offset: 明文中的起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内解密后的文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 {
+		return nil, oss.ErrInvalidRange
+	}
+	headerStream, err := storage.StorageInterface.GetStreamRange(path, 0, int64(HeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := storage.readHeader(headerStream)
+	headerStream.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	first := offset / ChunkSize
+	start := int64(HeaderSize) + first*(ChunkSize+tagSize)
+	limit := int64(-1)
+	rangeLength := int64(0)
+	if length > 0 {
+		limit = length
+		last := (offset + length - 1) / ChunkSize
+		rangeLength = (last - first + 1) * (ChunkSize + tagSize)
+	}
+	stream, err := storage.StorageInterface.GetStreamRange(path, start, rangeLength)
+	if err != nil {
+		return nil, err
+	}
+	return newDecryptReader(stream, aead, uint64(first), int(offset%ChunkSize), limit), nil
+}
+
+// Stat 获取对象信息，大小为明文大小
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Stat(path string) (*oss.Object, error) {
+	object, err := storage.StorageInterface.Stat(path)
+	return plaintext(object), err
+}
+
+// List 列出对象，大小为明文大小
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	objects, err := storage.StorageInterface.List(path)
+	for _, object := range objects {
+		plaintext(object)
+	}
+	return objects, err
+}
+
+// Put 加密后上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息，大小为明文大小
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项加密后上传文件
+// 未指定内容类型时根据扩展名设置，不再根据内容检测；校验和按密文计算，调用方预先计算的 ChecksumValue 会被忽略
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息，大小为明文大小
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	header, aead, err := storage.newHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	var putOpts oss.PutOptions
+	if opts != nil {
+		putOpts = *opts
+	}
+	putOpts.ChecksumValue = ""
+	if putOpts.ContentType == "" {
+		putOpts.ContentType = contentType(path)
+	}
+	object, err := storage.StorageInterface.PutWithOptions(path, newEncryptReader(header, reader, aead), &putOpts)
+	return plaintext(object), err
+}
+
+// NewWriter 创建加密后写入的流式写入器
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，关闭时写入最后一块
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	header, aead, err := storage.newHeader()
+	if err != nil {
+		return nil, err
+	}
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(header); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	return newEncryptWriter(writer, aead), nil
+}
+
+// GetUploadURL 客户端直传会绕过加密，不支持
+// 参数:
+//   - path: 目标路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 总是为nil
+//   - error: oss.ErrNotSupported
+func (storage *Storage) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	return nil, fmt.Errorf("%w: upload URL bypasses client-side encryption", oss.ErrNotSupported)
+}
+
+// KeyID 返回加密对象的主密钥标识
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 主密钥标识
+//   - error: 错误信息，对象没有加密头部时返回 ErrNotEncrypted
+func (storage *Storage) KeyID(path string) (string, error) {
+	header, err := storage.header(path)
+	if err != nil {
+		return "", err
+	}
+	id, err := headerKeyID(header)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return id, nil
+}
+
+// header 读取对象开头的头部
+func (storage *Storage) header(path string) ([]byte, error) {
+	stream, err := storage.StorageInterface.GetStreamRange(path, 0, int64(HeaderSize))
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(stream, header)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return header[:n], nil
+	}
+	return header, err
+}
+
+// Rotate 使用当前主密钥重新加密对象的数据密钥，内容的密文不变
+// 对象先下载到临时文件，再与新的头部一起上传到原路径
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否重新加密，对象已使用当前主密钥时返回false
+//   - error: 错误信息
+func (storage *Storage) Rotate(path string) (bool, error) {
+	currentID, master, err := storage.Keys.CurrentKey()
+	if err != nil {
+		return false, err
+	}
+	header, err := storage.header(path)
+	if err != nil {
+		return false, err
+	}
+	id, dataKey, err := storage.openHeader(header)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+	if id == currentID {
+		return false, nil
+	}
+	newHeader, err := sealHeader(currentID, master, dataKey)
+	if err != nil {
+		return false, err
+	}
+
+	stream, err := storage.StorageInterface.GetStreamRange(path, int64(HeaderSize), 0)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+	file, err := oss.CreateTempFile("osscrypt-rotate-*")
+	if err != nil {
+		return false, err
+	}
+	defer oss.RemoveTempFile(file)
+	if _, err := io.Copy(file, stream); err != nil {
+		return false, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	object, err := storage.StorageInterface.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	opts := &oss.PutOptions{ContentType: object.ContentType, Metadata: object.Metadata}
+	if _, err := storage.StorageInterface.PutWithOptions(path, io.MultiReader(bytes.NewReader(newHeader), file), opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// contentType 根据扩展名返回内容类型，密文无法根据内容检测
+func contentType(path string) string {
+	if contentType := mime.TypeByExtension(pathpkg.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
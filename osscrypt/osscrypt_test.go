@@ -0,0 +1,140 @@
+package osscrypt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestEncryption(t *testing.T) {
+	mock := ossmock.New()
+	keys := &StaticKeys{Current: "v1", Keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, KeySize)}}
+	storage := Wrap(mock, keys)
+
+	plain := make([]byte, 3*ChunkSize+5)
+	for i := range plain {
+		plain[i] = byte(i % 251)
+	}
+	for _, size := range []int{0, 1, ChunkSize, ChunkSize + 1, len(plain)} {
+		object, err := storage.Put("/a.bin", bytes.NewReader(plain[:size]))
+		if err != nil || object.Size != int64(size) {
+			t.Fatalf("Put should return the plaintext size %v, but got %v, %v", size, object, err)
+		}
+		stored := tests.ReadObject(t, mock, "/a.bin")
+		if int64(len(stored)) != CiphertextSize(int64(size)) || (size > 8 && bytes.Contains(stored, plain[:size])) {
+			t.Errorf("Stored object of %v bytes should be encrypted, but got %v bytes", size, len(stored))
+		}
+		if data, err := tests.ReadStream(storage.GetStream("/a.bin")); err != nil || !bytes.Equal(data, plain[:size]) {
+			t.Errorf("GetStream should decrypt %v bytes, but got %v bytes, %v", size, len(data), err)
+		}
+		if object, _ := storage.Stat("/a.bin"); object.Size != int64(size) {
+			t.Errorf("Stat should return the plaintext size %v, but got %v", size, object.Size)
+		}
+	}
+
+	for _, r := range [][2]int64{{0, 10}, {ChunkSize - 3, 10}, {ChunkSize, ChunkSize}, {2*ChunkSize + 7, 0}, {5, 0}} {
+		offset, length := r[0], r[1]
+		expected := plain[offset:]
+		if length > 0 {
+			expected = expected[:length]
+		}
+		if data, err := tests.ReadStream(storage.GetStreamRange("/a.bin", offset, length)); err != nil || !bytes.Equal(data, expected) {
+			t.Errorf("GetStreamRange(%v, %v) should decrypt the covered chunks, but got %v bytes, %v", offset, length, len(data), err)
+		}
+	}
+
+	writer, _ := storage.NewWriter("/b.bin")
+	for i := 0; i < len(plain); i += 1000 {
+		writer.Write(plain[i:min(i+1000, len(plain))])
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("No error should happen when close writer, but got %v", err)
+	}
+	if data, err := tests.ReadStream(storage.GetStream("/b.bin")); err != nil || !bytes.Equal(data, plain) {
+		t.Errorf("Writer content should be decrypted, but got %v bytes, %v", len(data), err)
+	}
+	if !bytes.Equal(tests.ReadObject(t, mock, "/b.bin")[:HeaderSize-KeySize-tagSize-nonceSize], tests.ReadObject(t, mock, "/a.bin")[:HeaderSize-KeySize-tagSize-nonceSize]) {
+		t.Errorf("Objects encrypted with the same key should record the same key id")
+	}
+
+	stored := tests.ReadObject(t, mock, "/a.bin")
+	tampered := append([]byte(nil), stored...)
+	tampered[HeaderSize+ChunkSize+100] ^= 1
+	mock.Put("/tampered.bin", bytes.NewReader(tampered))
+	if _, err := tests.ReadStream(storage.GetStream("/tampered.bin")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Tampered chunk should fail authentication, but got %v", err)
+	}
+	mock.Put("/truncated.bin", bytes.NewReader(stored[:HeaderSize+2*(ChunkSize+tagSize)]))
+	if _, err := tests.ReadStream(storage.GetStream("/truncated.bin")); !errors.Is(err, oss.ErrShortRead) {
+		t.Errorf("Stream truncated at a chunk boundary should be detected, but got %v", err)
+	}
+	mock.Put("/plain.txt", strings.NewReader("not encrypted"))
+	if _, err := storage.GetStream("/plain.txt"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("Unencrypted object should be reported, but got %v", err)
+	}
+	if _, err := storage.GetUploadURL("/c.bin", oss.UploadURLOptions{}); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Upload URL should not bypass encryption, but got %v", err)
+	}
+}
+
+func TestSeededRandom(t *testing.T) {
+	random := oss.DefaultRandom
+	defer func() { oss.DefaultRandom = random }()
+
+	mock := ossmock.New()
+	keys := &StaticKeys{Current: "v1", Keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, KeySize)}}
+	storage := Wrap(mock, keys)
+	var dataKeys [2][]byte
+	for i, path := range []string{"/a.txt", "/b.txt"} {
+		oss.DefaultRandom = oss.NewSeededRandom(1)
+		if _, err := storage.Put(path, strings.NewReader("secret")); err != nil {
+			t.Fatalf("No error should happen when put %v, but got %v", path, err)
+		}
+		_, dataKey, err := storage.openHeader(tests.ReadObject(t, mock, path)[:HeaderSize])
+		if err != nil {
+			t.Fatalf("Header of %v should be opened, but got %v", path, err)
+		}
+		dataKeys[i] = dataKey
+	}
+	if bytes.Equal(dataKeys[0], dataKeys[1]) {
+		t.Errorf("Data keys should not depend on the seeded oss.DefaultRandom")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	mock := ossmock.New()
+	keys := &StaticKeys{Current: "v1", Keys: map[string][]byte{"v1": bytes.Repeat([]byte{1}, KeySize)}}
+	storage := Wrap(mock, keys)
+	storage.Put("/a.txt", strings.NewReader("secret"))
+	body := tests.ReadObject(t, mock, "/a.txt")[HeaderSize:]
+
+	keys.Keys["v2"] = bytes.Repeat([]byte{2}, KeySize)
+	keys.Current = "v2"
+	if rotated, err := storage.Rotate("/a.txt"); !rotated || err != nil {
+		t.Fatalf("Object encrypted with the old key should be rotated, but got %v, %v", rotated, err)
+	}
+	if id, _ := storage.KeyID("/a.txt"); id != "v2" {
+		t.Errorf("Rotated object should record the current key, but got %v", id)
+	}
+	if !bytes.Equal(tests.ReadObject(t, mock, "/a.txt")[HeaderSize:], body) {
+		t.Errorf("Rotation should only re-encrypt the header")
+	}
+
+	delete(keys.Keys, "v1")
+	if data, err := tests.ReadStream(storage.GetStream("/a.txt")); err != nil || string(data) != "secret" {
+		t.Errorf("Rotated object should be readable without the old key, but got %q, %v", data, err)
+	}
+	if rotated, err := storage.Rotate("/a.txt"); rotated || err != nil {
+		t.Errorf("Object already using the current key should not be rotated, but got %v, %v", rotated, err)
+	}
+
+	keys.Current = "v3"
+	if _, err := storage.Put("/b.txt", strings.NewReader("b")); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Missing current key should be reported, but got %v", err)
+	}
+}
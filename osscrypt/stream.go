@@ -0,0 +1,256 @@
+package osscrypt
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/smart-unicom/oss"
+)
+
+// chunkNonce 返回块的随机数，由块序号和是否为最后一块组成
+// 每个对象的数据密钥都是随机生成的，同一密钥下块序号不会重复；最后一块的标记防止密文在块边界被截断
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce, index)
+	if final {
+		nonce[8] = 1
+	}
+	return nonce
+}
+
+// encryptReader 读取时按块加密内容的读取器，先输出头部
+type encryptReader struct {
+	src    io.Reader
+	aead   cipher.AEAD
+	index  uint64
+	buf    []byte
+	have   int
+	sealed []byte
+	out    []byte
+	done   bool
+	err    error
+}
+
+// newEncryptReader 创建按块加密内容的读取器
+func newEncryptReader(header []byte, src io.Reader, aead cipher.AEAD) *encryptReader {
+	return &encryptReader{
+		src:    src,
+		aead:   aead,
+		buf:    make([]byte, ChunkSize+1),
+		sealed: make([]byte, 0, ChunkSize+tagSize),
+		out:    header,
+	}
+}
+
+// Read 读取密文
+func (reader *encryptReader) Read(p []byte) (int, error) {
+	for len(reader.out) == 0 {
+		if reader.done {
+			return 0, io.EOF
+		}
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		reader.seal()
+	}
+	n := copy(p, reader.out)
+	reader.out = reader.out[n:]
+	return n, nil
+}
+
+// seal 读取并加密下一块，多读一个字节判断是否为最后一块
+func (reader *encryptReader) seal() {
+	n, err := io.ReadFull(reader.src, reader.buf[reader.have:])
+	reader.have += n
+	switch {
+	case err == nil:
+		reader.out = reader.aead.Seal(reader.sealed[:0], chunkNonce(reader.index, false), reader.buf[:ChunkSize], nil)
+		reader.buf[0] = reader.buf[ChunkSize]
+		reader.have = 1
+		reader.index++
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		reader.out = reader.aead.Seal(reader.sealed[:0], chunkNonce(reader.index, true), reader.buf[:reader.have], nil)
+		reader.done = true
+	default:
+		reader.err = err
+	}
+}
+
+// encryptWriter 写入时按块加密内容的写入器，关闭时写入最后一块
+type encryptWriter struct {
+	dst    io.WriteCloser
+	aead   cipher.AEAD
+	index  uint64
+	buf    []byte
+	sealed []byte
+	err    error
+	closed bool
+}
+
+// newEncryptWriter 创建按块加密内容的写入器
+func newEncryptWriter(dst io.WriteCloser, aead cipher.AEAD) *encryptWriter {
+	return &encryptWriter{
+		dst:    dst,
+		aead:   aead,
+		buf:    make([]byte, 0, ChunkSize),
+		sealed: make([]byte, 0, ChunkSize+tagSize),
+	}
+}
+
+// Write 写入明文，凑满一块后加密写入，最后一块留到关闭时写入
+func (writer *encryptWriter) Write(p []byte) (int, error) {
+	if writer.closed {
+		return 0, os.ErrClosed
+	}
+	written := 0
+	for len(p) > 0 {
+		if writer.err != nil {
+			return written, writer.err
+		}
+		if len(writer.buf) == ChunkSize {
+			writer.flush(false)
+			continue
+		}
+		n := copy(writer.buf[len(writer.buf):ChunkSize], p)
+		writer.buf = writer.buf[:len(writer.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// flush 加密并写入缓冲的块
+func (writer *encryptWriter) flush(final bool) {
+	writer.sealed = writer.aead.Seal(writer.sealed[:0], chunkNonce(writer.index, final), writer.buf, nil)
+	if _, err := writer.dst.Write(writer.sealed); err != nil {
+		writer.err = err
+	}
+	writer.buf = writer.buf[:0]
+	writer.index++
+}
+
+// Close 写入最后一块并关闭被包装的写入器
+func (writer *encryptWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	if writer.err == nil {
+		writer.flush(true)
+	}
+	closeErr := writer.dst.Close()
+	if writer.err != nil {
+		return writer.err
+	}
+	return closeErr
+}
+
+// decryptReader 按块解密的读取器
+type decryptReader struct {
+	src    io.ReadCloser
+	aead   cipher.AEAD
+	index  uint64
+	buf    []byte
+	opened []byte
+	plain  []byte
+	skip   int
+	limit  int64
+	final  bool
+	err    error
+}
+
+// newDecryptReader 创建按块解密的读取器
+// 参数:
+//   - src: 从第index块开始的密文
+//   - aead: 内容解密器
+//   - index: 第一块的序号
+//   - skip: 第一块中需要跳过的明文字节数
+//   - limit: 最多返回的明文字节数，小于0时读取到最后一块
+func newDecryptReader(src io.ReadCloser, aead cipher.AEAD, index uint64, skip int, limit int64) *decryptReader {
+	return &decryptReader{
+		src:    src,
+		aead:   aead,
+		index:  index,
+		buf:    make([]byte, ChunkSize+tagSize),
+		opened: make([]byte, 0, ChunkSize),
+		skip:   skip,
+		limit:  limit,
+	}
+}
+
+// Read 读取明文
+func (reader *decryptReader) Read(p []byte) (int, error) {
+	if reader.limit == 0 {
+		return 0, io.EOF
+	}
+	for len(reader.plain) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		reader.open()
+	}
+	if reader.limit > 0 && int64(len(p)) > reader.limit {
+		p = p[:reader.limit]
+	}
+	n := copy(p, reader.plain)
+	reader.plain = reader.plain[n:]
+	if reader.limit > 0 {
+		reader.limit -= int64(n)
+	}
+	return n, nil
+}
+
+// open 读取并解密下一块
+func (reader *decryptReader) open() {
+	if reader.final {
+		// 最后一块之后不应再有内容
+		if n, _ := io.ReadFull(reader.src, reader.buf[:1]); n > 0 {
+			reader.err = fmt.Errorf("%w: data after the last chunk", ErrDecrypt)
+		} else {
+			reader.err = io.EOF
+		}
+		return
+	}
+
+	n, err := io.ReadFull(reader.src, reader.buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		reader.err = err
+		return
+	}
+	if n == 0 {
+		reader.err = fmt.Errorf("%w: encrypted stream ended before the last chunk", oss.ErrShortRead)
+		return
+	}
+
+	plain, openErr := reader.aead.Open(reader.opened[:0], chunkNonce(reader.index, false), reader.buf[:n], nil)
+	if openErr != nil {
+		plain, openErr = reader.aead.Open(reader.opened[:0], chunkNonce(reader.index, true), reader.buf[:n], nil)
+		reader.final = true
+	}
+	switch {
+	case openErr == nil:
+	case n < len(reader.buf):
+		// 不完整的块可能是连接中断造成的
+		reader.err = fmt.Errorf("%w: encrypted chunk %d is incomplete", oss.ErrShortRead, reader.index)
+		return
+	default:
+		reader.err = fmt.Errorf("%w: chunk %d", ErrDecrypt, reader.index)
+		return
+	}
+	reader.index++
+
+	if reader.skip > len(plain) {
+		reader.skip = len(plain)
+	}
+	reader.plain = plain[reader.skip:]
+	reader.skip = 0
+}
+
+// Close 关闭密文流
+func (reader *decryptReader) Close() error {
+	return reader.src.Close()
+}
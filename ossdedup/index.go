@@ -0,0 +1,127 @@
+package ossdedup
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// Entry 索引中一个路径指向的内容
+type Entry struct {
+	// Path 对象路径，以斜杠开头
+	Path string `json:"path"`
+	// Hash 内容的SHA-256，十六进制小写
+	Hash string `json:"hash"`
+	// Size 内容大小
+	Size int64 `json:"size"`
+	// ContentType 内容类型
+	ContentType string `json:"content_type,omitempty"`
+	// LastModified 写入索引的时间
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Index 路径到内容哈希的索引和内容的引用计数
+// 多个进程共享同一个存储时应使用数据库等外部存储实现，Ref 需要是原子操作
+type Index interface {
+	// Lookup 返回路径指向的内容
+	// 返回:
+	//   - *Entry: 索引项
+	//   - error: 路径不存在时返回 oss.ErrObjectNotFound 或其他满足 errors.Is(err, oss.ErrNotFound) 的错误
+	Lookup(path string) (*Entry, error)
+	// Set 设置路径指向的内容
+	// 返回:
+	//   - *Entry: 被替换的索引项，路径原来不存在时为nil
+	//   - error: 错误信息
+	Set(entry Entry) (*Entry, error)
+	// Remove 删除路径
+	// 返回:
+	//   - *Entry: 被删除的索引项
+	//   - error: 路径不存在时返回 oss.ErrObjectNotFound 或其他满足 errors.Is(err, oss.ErrNotFound) 的错误
+	Remove(path string) (*Entry, error)
+	// List 返回以 prefix 开头的全部索引项，按路径排序
+	List(prefix string) ([]Entry, error)
+	// Ref 原子地修改内容的引用计数
+	// 返回:
+	//   - int64: 修改后的引用计数
+	//   - error: 错误信息
+	Ref(hash string, delta int64) (int64, error)
+}
+
+// MemoryIndex 保存在内存中的索引，用于测试和单进程的应用，进程退出后索引丢失
+type MemoryIndex struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	refs    map[string]int64
+}
+
+// NewMemoryIndex 创建内存索引
+// 返回:
+//   - *MemoryIndex: 内存索引实例
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{entries: map[string]Entry{}, refs: map[string]int64{}}
+}
+
+// Lookup 返回路径指向的内容
+func (index *MemoryIndex) Lookup(path string) (*Entry, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	entry, ok := index.entries[path]
+	if !ok {
+		return nil, oss.ErrObjectNotFound
+	}
+	return &entry, nil
+}
+
+// Set 设置路径指向的内容
+func (index *MemoryIndex) Set(entry Entry) (*Entry, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	previous, ok := index.entries[entry.Path]
+	index.entries[entry.Path] = entry
+	if !ok {
+		return nil, nil
+	}
+	return &previous, nil
+}
+
+// Remove 删除路径
+func (index *MemoryIndex) Remove(path string) (*Entry, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	entry, ok := index.entries[path]
+	if !ok {
+		return nil, oss.ErrObjectNotFound
+	}
+	delete(index.entries, path)
+	return &entry, nil
+}
+
+// List 返回以 prefix 开头的全部索引项
+func (index *MemoryIndex) List(prefix string) ([]Entry, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	var entries []Entry
+	for path, entry := range index.entries {
+		if strings.HasPrefix(path, prefix) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+// Ref 修改内容的引用计数
+func (index *MemoryIndex) Ref(hash string, delta int64) (int64, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	refs := index.refs[hash] + delta
+	if refs <= 0 {
+		delete(index.refs, hash)
+		return 0, nil
+	}
+	index.refs[hash] = refs
+	return refs, nil
+}
@@ -0,0 +1,520 @@
+// Package ossdedup 按内容寻址的去重存储包装器
+// 对象内容以SHA-256命名保存在 BlobPrefix 目录下，路径到哈希的对应关系保存在 Index 中，
+// 内容相同的对象只上传和保存一次，复制和移动只修改索引，引用计数归零时删除内容
+package ossdedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultBlobPrefix 默认的内容目录
+const DefaultBlobPrefix = "/.blobs"
+
+// maxKeyBytes 索引中路径的最大字节数，与大多数对象存储的键长度限制相同
+// 路径只保存在索引中，被包装的存储不会检查它的长度
+const maxKeyBytes = 1024
+
+// Options 去重存储的配置
+type Options struct {
+	// Index 路径索引和引用计数，为空时使用 MemoryIndex
+	Index Index
+	// BlobPrefix 保存内容的目录，为空时使用 DefaultBlobPrefix
+	BlobPrefix string
+}
+
+// Storage 按内容寻址的去重存储包装器
+// 上传的内容先写入临时文件计算哈希；PutWithOptions 的冲突策略按索引中的路径处理，其他选项只在内容第一次上传时生效，
+// 各路径只保留自己的 ContentType
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Index 路径索引和引用计数
+	Index Index
+	// BlobPrefix 保存内容的目录
+	BlobPrefix string
+
+	// locks 按哈希的前两个字符分段的锁，保证同一内容的引用计数和上传、删除不交错
+	locks [256]sync.Mutex
+}
+
+// Wrap 创建去重存储包装器
+// 参数:
+//   - storage: 保存内容的存储
+//   - opts: 配置
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, opts Options) *Storage {
+	if opts.Index == nil {
+		opts.Index = NewMemoryIndex()
+	}
+	if opts.BlobPrefix == "" {
+		opts.BlobPrefix = DefaultBlobPrefix
+	}
+	return &Storage{
+		StorageInterface: storage,
+		Index:            opts.Index,
+		BlobPrefix:       "/" + strings.Trim(opts.BlobPrefix, "/"),
+	}
+}
+
+// BlobPath 返回内容在被包装存储中的路径
+// 参数:
+//   - hash: 内容的SHA-256
+// 返回:
+//   - string: 内容路径
+func (storage *Storage) BlobPath(hash string) string {
+	return storage.BlobPrefix + "/" + hash[:2] + "/" + hash
+}
+
+// lock 返回内容哈希所在分段的锁
+func (storage *Storage) lock(hash string) *sync.Mutex {
+	stripe, _ := strconv.ParseUint(hash[:2], 16, 8)
+	return &storage.locks[stripe]
+}
+
+// normalize 将路径统一为以斜杠开头的索引键
+func normalize(path string) (string, error) {
+	path, err := oss.NormalizePath(path)
+	if err != nil {
+		return "", err
+	}
+	return pathpkg.Clean("/" + path), nil
+}
+
+// normalizeTarget 规范化写入的路径并校验长度
+func normalizeTarget(path string) (string, error) {
+	key, err := normalize(path)
+	if err != nil {
+		return "", err
+	}
+	if err := oss.ValidateKeyLength(strings.TrimPrefix(key, "/"), maxKeyBytes, 0); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// lookup 规范化路径并返回索引项
+func (storage *Storage) lookup(path string) (*Entry, error) {
+	key, err := normalize(path)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := storage.Index.Lookup(key)
+	if errors.Is(err, oss.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", oss.ErrObjectNotFound, key)
+	}
+	return entry, err
+}
+
+// object 将索引项转换为对象信息，ETag 为内容的哈希
+func (storage *Storage) object(entry *Entry) *oss.Object {
+	return &oss.Object{
+		Path:             entry.Path,
+		Name:             pathpkg.Base(entry.Path),
+		LastModified:     oss.NormalizeTime(entry.LastModified),
+		Size:             entry.Size,
+		ContentType:      entry.ContentType,
+		ETag:             entry.Hash,
+		StorageInterface: storage,
+	}
+}
+
+// Get 获取文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Get(path string) (*os.File, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.Get(storage.BlobPath(entry.Hash))
+}
+
+// GetStream 获取文件流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetStream(storage.BlobPath(entry.Hash))
+}
+
+// GetStreamRange 范围读取文件流
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetStreamRange(storage.BlobPath(entry.Hash), offset, length)
+}
+
+// Stat 从索引获取对象信息，不访问被包装的存储
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Stat(path string) (*oss.Object, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.object(entry), nil
+}
+
+// Exists 检查路径是否存在于索引中
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *Storage) Exists(path string) (bool, error) {
+	_, err := storage.lookup(path)
+	if errors.Is(err, oss.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Put 上传文件，内容已存在时只增加引用计数
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息，ETag 为内容的哈希
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件，内容已存在时只增加引用计数
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，冲突策略按索引中的路径处理，其他选项只在内容第一次上传时传给被包装的存储
+// 返回:
+//   - *oss.Object: 对象信息，ETag 为内容的哈希，重命名时 Path 为实际写入的路径
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	key, err := normalizeTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	// 按冲突策略确定索引中的路径，内容以哈希命名，上传内容时不使用冲突策略
+	if key, err = opts.ResolvePath(storage, key); err != nil {
+		return nil, err
+	}
+	blobOpts := *opts
+	blobOpts.Collision = ""
+	file, err := oss.CreateTempFile("ossdedup-*")
+	if err != nil {
+		return nil, err
+	}
+	defer oss.RemoveTempFile(file)
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hasher), reader)
+	if err != nil {
+		return nil, err
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	if err := storage.acquire(hash, file, &blobOpts); err != nil {
+		return nil, err
+	}
+
+	entry := Entry{Path: key, Hash: hash, Size: size, ContentType: opts.ContentType, LastModified: oss.Now()}
+	if err := storage.set(entry); err != nil {
+		storage.release(hash)
+		return nil, err
+	}
+	return storage.object(&entry), nil
+}
+
+// acquire 增加内容的引用计数，第一次引用且被包装的存储中没有该内容时上传
+func (storage *Storage) acquire(hash string, file *os.File, opts *oss.PutOptions) error {
+	lock := storage.lock(hash)
+	lock.Lock()
+	defer lock.Unlock()
+
+	refs, err := storage.Index.Ref(hash, 1)
+	if err != nil {
+		return err
+	}
+	if refs > 1 {
+		return nil
+	}
+	blob := storage.BlobPath(hash)
+	if exists, err := storage.StorageInterface.Exists(blob); err == nil && exists {
+		return nil
+	}
+	if _, err = file.Seek(0, io.SeekStart); err == nil {
+		_, err = storage.StorageInterface.PutWithOptions(blob, file, opts)
+	}
+	if err != nil {
+		storage.Index.Ref(hash, -1)
+		return err
+	}
+	return nil
+}
+
+// release 减少内容的引用计数，归零时删除内容
+func (storage *Storage) release(hash string) error {
+	lock := storage.lock(hash)
+	lock.Lock()
+	defer lock.Unlock()
+
+	refs, err := storage.Index.Ref(hash, -1)
+	if err != nil || refs > 0 {
+		return err
+	}
+	if err := storage.StorageInterface.Delete(storage.BlobPath(hash)); err != nil && !errors.Is(err, oss.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// set 写入索引项，并释放被替换的内容
+func (storage *Storage) set(entry Entry) error {
+	previous, err := storage.Index.Set(entry)
+	if err != nil || previous == nil {
+		return err
+	}
+	return storage.release(previous.Hash)
+}
+
+// NewWriter 创建流式写入器，内容写入临时文件，关闭时上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	if _, err := normalizeTarget(path); err != nil {
+		return nil, err
+	}
+	file, err := oss.CreateTempFile("ossdedup-*")
+	if err != nil {
+		return nil, err
+	}
+	return &writer{File: file, storage: storage, path: path}, nil
+}
+
+// writer 写入临时文件，关闭时通过 Put 上传
+type writer struct {
+	*os.File
+	storage *Storage
+	path    string
+	closed  bool
+}
+
+// Close 上传临时文件中的内容并删除临时文件
+func (writer *writer) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	defer oss.RemoveTempFile(writer.File)
+
+	if _, err := writer.File.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := writer.storage.Put(writer.path, writer.File)
+	return err
+}
+
+// Delete 从索引中删除路径，并减少内容的引用计数
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Delete(path string) error {
+	key, err := normalize(path)
+	if err != nil {
+		return err
+	}
+	entry, err := storage.Index.Remove(key)
+	if errors.Is(err, oss.ErrNotFound) {
+		return fmt.Errorf("%w: %s", oss.ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return err
+	}
+	return storage.release(entry.Hash)
+}
+
+// DeleteObjects 批量删除路径
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 部分路径删除失败时返回 *oss.DeleteObjectsError
+func (storage *Storage) DeleteObjects(paths []string) error {
+	failures := map[string]error{}
+	for _, path := range paths {
+		if err := storage.Delete(path); err != nil && !errors.Is(err, oss.ErrNotFound) {
+			failures[path] = err
+		}
+	}
+	return oss.NewDeleteObjectsError(failures)
+}
+
+// DeleteDir 删除目录下的全部路径
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，删除根目录时返回 oss.ErrDeleteRoot
+func (storage *Storage) DeleteDir(dir string) error {
+	// 防止误删整个索引
+	if oss.DirPrefix(dir) == "" {
+		return oss.ErrDeleteRoot
+	}
+	entries, err := storage.Index.List("/" + oss.DirPrefix(dir))
+	if err != nil {
+		return err
+	}
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		paths[i] = entry.Path
+	}
+	return storage.DeleteObjects(paths)
+}
+
+// Copy 复制文件，只增加内容的引用计数，不复制内容
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 源路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	entry, err := storage.lookup(srcPath)
+	if err != nil {
+		return err
+	}
+	if entry.Path, err = normalizeTarget(dstPath); err != nil {
+		return err
+	}
+	lock := storage.lock(entry.Hash)
+	lock.Lock()
+	_, err = storage.Index.Ref(entry.Hash, 1)
+	lock.Unlock()
+	if err != nil {
+		return err
+	}
+	entry.LastModified = oss.Now()
+	if err := storage.set(*entry); err != nil {
+		storage.release(entry.Hash)
+		return err
+	}
+	return nil
+}
+
+// Move 移动文件，只修改索引
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 源路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	src, err := normalize(srcPath)
+	if err != nil {
+		return err
+	}
+	dst, err := normalize(dstPath)
+	if err != nil {
+		return err
+	}
+	// 源和目标相同时无需移动，复制后删除会删除对象
+	if src == dst {
+		return nil
+	}
+	if err := storage.Copy(srcPath, dstPath); err != nil {
+		return err
+	}
+	return storage.Delete(srcPath)
+}
+
+// List 从索引列出目录下的全部对象，包括子目录中的对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	entries, err := storage.Index.List("/" + oss.DirPrefix(path))
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]*oss.Object, len(entries))
+	for i := range entries {
+		objects[i] = storage.object(&entries[i])
+	}
+	return objects, nil
+}
+
+// GetURL 获取内容的访问URL
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 访问URL
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) GetURL(path string) (string, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return "", err
+	}
+	return storage.StorageInterface.GetURL(storage.BlobPath(entry.Hash))
+}
+
+// GetSignedURL 获取内容的签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 签名URL
+//   - error: 路径不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	entry, err := storage.lookup(path)
+	if err != nil {
+		return "", err
+	}
+	return storage.StorageInterface.GetSignedURL(storage.BlobPath(entry.Hash), opts)
+}
+
+// GetUploadURL 直传的内容无法计算哈希和更新索引，不支持
+// 参数:
+//   - path: 目标路径
+//   - opts: 上传URL选项
+// 返回:
+//   - *oss.UploadURL: 总是为nil
+//   - error: 总是返回 oss.ErrNotSupported
+func (storage *Storage) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	return nil, fmt.Errorf("%w: ossdedup upload url", oss.ErrNotSupported)
+}
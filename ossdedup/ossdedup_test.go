@@ -0,0 +1,117 @@
+package ossdedup
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	tests.TestAll(Wrap(ossmock.New(), Options{}), t)
+}
+
+func TestDedup(t *testing.T) {
+	mock := ossmock.New()
+	storage := Wrap(mock, Options{})
+	blobs := func() int {
+		t.Helper()
+		objects, err := mock.List(DefaultBlobPrefix)
+		if err != nil {
+			t.Fatalf("List blobs failed: %v", err)
+		}
+		return len(objects)
+	}
+
+	first, err := storage.Put("/a.txt", strings.NewReader("same"))
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, err := storage.PutWithOptions(`b\b.txt`, strings.NewReader("same"), &oss.PutOptions{ContentType: "text/plain"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if blobs() != 1 || len(mock.CallsTo("PutWithOptions")) != 1 {
+		t.Errorf("Identical content should be uploaded once, but got %v blobs", blobs())
+	}
+	if content := string(tests.ReadObject(t, storage, "/b/b.txt")); content != "same" {
+		t.Errorf("Deduplicated path should read the shared content, but got %v", content)
+	}
+	if object, err := storage.Stat("/b/b.txt"); err != nil || object.ETag != first.ETag || object.ContentType != "text/plain" || object.Size != 4 {
+		t.Errorf("Stat should return the indexed entry, but got %+v, %v", object, err)
+	}
+
+	// 复制和移动只修改索引
+	if err := storage.Copy("/a.txt", "/c.txt"); err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if err := storage.Move("/c.txt", "/d/c.txt"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if len(mock.CallsTo("Copy")) != 0 || len(mock.CallsTo("Move")) != 0 || blobs() != 1 {
+		t.Errorf("Copy and Move should not touch the blobs")
+	}
+	if exists, _ := storage.Exists("/c.txt"); exists {
+		t.Errorf("Moved path should not exist")
+	}
+	if err := storage.Move("/d/c.txt", `d\c.txt`); err != nil {
+		t.Errorf("Moving a path onto itself should succeed, but got %v", err)
+	}
+	if exists, _ := storage.Exists("/d/c.txt"); !exists {
+		t.Errorf("Moving a path onto itself should keep it")
+	}
+	if objects, err := storage.List("/"); err != nil || len(objects) != 3 || objects[2].Path != "/d/c.txt" {
+		t.Errorf("List should return indexed paths, but got %v, %v", objects, err)
+	}
+
+	// 引用计数归零时删除内容
+	storage.Delete("/a.txt")
+	storage.DeleteObjects([]string{"/b/b.txt"})
+	if blobs() != 1 {
+		t.Errorf("Referenced blob should be kept")
+	}
+	if err := storage.DeleteDir("/d"); err != nil || blobs() != 0 {
+		t.Errorf("Unreferenced blob should be deleted, but got %v blobs, %v", blobs(), err)
+	}
+	if err := storage.Delete("/a.txt"); !errors.Is(err, oss.ErrObjectNotFound) {
+		t.Errorf("Deleting a missing path should return ErrObjectNotFound, but got %v", err)
+	}
+	if _, err := storage.Stat("/a.txt"); !errors.Is(err, oss.ErrObjectNotFound) {
+		t.Errorf("Stat on a missing path should return ErrObjectNotFound, but got %v", err)
+	}
+
+	// 覆盖时释放旧内容
+	storage.Put("/e.txt", strings.NewReader("old"))
+	writer, _ := storage.NewWriter("/e.txt")
+	io.WriteString(writer, "new")
+	if err := writer.Close(); err != nil || blobs() != 1 || string(tests.ReadObject(t, storage, "/e.txt")) != "new" {
+		t.Errorf("Overwrite should release the old blob, but got %v blobs, %v", blobs(), err)
+	}
+
+	// 冲突策略按索引中的路径处理
+	if _, err := storage.PutWithOptions("/e.txt", strings.NewReader("other"), &oss.PutOptions{Collision: oss.CollisionError}); !errors.Is(err, oss.ErrConflict) {
+		t.Errorf("Put on an indexed path with CollisionError should return ErrConflict, but got %v", err)
+	}
+	if object, err := storage.PutWithOptions("/e.txt", strings.NewReader("new"), &oss.PutOptions{Collision: oss.CollisionRename}); err != nil || object.Path != "/e-1.txt" {
+		t.Errorf("Put on an indexed path with CollisionRename should use a new path, but got %v, %v", object, err)
+	}
+	if content := string(tests.ReadObject(t, storage, "/e.txt")); content != "new" || blobs() != 1 {
+		t.Errorf("Renamed upload should keep the original path and share the blob, but got %v with %v blobs", content, blobs())
+	}
+	storage.Delete("/e-1.txt")
+
+	// 上传失败时不写入索引，也不保留引用
+	mock.FailWith("PutWithOptions", errors.New("network"))
+	if _, err := storage.Put("/f.txt", strings.NewReader("fail")); err == nil {
+		t.Errorf("Put should fail when the blob upload fails")
+	}
+	if refs := storage.Index.(*MemoryIndex).refs; len(refs) != 1 {
+		t.Errorf("Failed upload should not keep a reference, but got %v", refs)
+	}
+	if exists, _ := storage.Exists("/f.txt"); exists {
+		t.Errorf("Failed upload should not be indexed")
+	}
+}
@@ -0,0 +1,332 @@
+// Package osslog 记录存储操作日志的包装器
+// 每次调用结束后通过兼容 log/slog 的日志记录器输出操作、路径、字节数、耗时和错误
+package osslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// Message 日志消息
+const Message = "oss"
+
+// Logger 日志记录器，*slog.Logger 实现了该接口
+type Logger interface {
+	// LogAttrs 输出一条带属性的日志
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// Storage 记录存储操作日志的存储包装器
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Logger 日志记录器
+	Logger Logger
+	// Level 操作成功时的日志级别，默认为 slog.LevelInfo
+	Level slog.Level
+	// ErrorLevel 操作失败时的日志级别，默认为 slog.LevelError
+	ErrorLevel slog.Level
+}
+
+// Wrap 创建记录存储操作日志的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - logger: 日志记录器，为nil时使用 slog.Default()
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, logger Logger) *Storage {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Storage{StorageInterface: storage, Logger: logger, Level: slog.LevelInfo, ErrorLevel: slog.LevelError}
+}
+
+// Get 获取文件并记录日志
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (*os.File, error) {
+	start := time.Now()
+	file, err := storage.StorageInterface.Get(path)
+	bytes := int64(-1)
+	if err == nil {
+		if info, statErr := file.Stat(); statErr == nil {
+			bytes = info.Size()
+		}
+	}
+	storage.log("Get", path, bytes, start, err)
+	return file, err
+}
+
+// GetStream 获取文件流，关闭流时记录日志和实际读取的字节数
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	start := time.Now()
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		storage.log("GetStream", path, -1, start, err)
+		return nil, err
+	}
+	return &loggedReader{ReadCloser: stream, storage: storage, op: "GetStream", path: path, start: start}, nil
+}
+
+// GetStreamRange 范围读取文件流，关闭流时记录日志
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	start := time.Now()
+	stream, err := storage.StorageInterface.GetStreamRange(path, offset, length)
+	if err != nil {
+		storage.log("GetStreamRange", path, -1, start, err)
+		return nil, err
+	}
+	return &loggedReader{ReadCloser: stream, storage: storage, op: "GetStreamRange", path: path, start: start}, nil
+}
+
+// Stat 获取对象信息并记录日志
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Stat(path string) (*oss.Object, error) {
+	start := time.Now()
+	object, err := storage.StorageInterface.Stat(path)
+	storage.log("Stat", path, -1, start, err)
+	return object, err
+}
+
+// Exists 检查对象是否存在并记录日志
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *Storage) Exists(path string) (bool, error) {
+	start := time.Now()
+	exists, err := storage.StorageInterface.Exists(path)
+	storage.log("Exists", path, -1, start, err)
+	return exists, err
+}
+
+// Put 上传文件并记录日志
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	start, size := time.Now(), oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.Put(path, reader)
+	storage.log("Put", path, putSize(object, size), start, err)
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件并记录日志
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	start, size := time.Now(), oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	storage.log("PutWithOptions", path, putSize(object, size), start, err)
+	return object, err
+}
+
+// NewWriter 创建流式写入器，关闭写入器时记录日志和写入的字节数
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	start := time.Now()
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		storage.log("NewWriter", path, -1, start, err)
+		return nil, err
+	}
+	return &loggedWriter{WriteCloser: writer, storage: storage, path: path, start: start}, nil
+}
+
+// Delete 删除文件并记录日志
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Delete(path string) error {
+	start := time.Now()
+	err := storage.StorageInterface.Delete(path)
+	storage.log("Delete", path, -1, start, err)
+	return err
+}
+
+// DeleteObjects 批量删除对象并记录日志，日志中包含对象数量
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	start := time.Now()
+	err := storage.StorageInterface.DeleteObjects(paths)
+	storage.log("DeleteObjects", "", -1, start, err, slog.Int("count", len(paths)))
+	return err
+}
+
+// DeleteDir 删除目录并记录日志
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteDir(dir string) error {
+	start := time.Now()
+	err := storage.StorageInterface.DeleteDir(dir)
+	storage.log("DeleteDir", dir, -1, start, err)
+	return err
+}
+
+// Copy 复制文件并记录日志
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	start := time.Now()
+	err := storage.StorageInterface.Copy(srcPath, dstPath)
+	storage.log("Copy", dstPath, -1, start, err, slog.String("source", srcPath))
+	return err
+}
+
+// Move 移动文件并记录日志
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	start := time.Now()
+	err := storage.StorageInterface.Move(srcPath, dstPath)
+	storage.log("Move", dstPath, -1, start, err, slog.String("source", srcPath))
+	return err
+}
+
+// List 列出对象并记录日志，日志中包含返回的对象数量
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	start := time.Now()
+	objects, err := storage.StorageInterface.List(path)
+	storage.log("List", path, -1, start, err, slog.Int("count", len(objects)))
+	return objects, err
+}
+
+// log 输出一条操作日志，bytes小于0时不输出字节数
+func (storage *Storage) log(op, path string, bytes int64, start time.Time, err error, attrs ...slog.Attr) {
+	level := storage.Level
+	attrs = append([]slog.Attr{slog.String("op", op), slog.String("path", path)}, attrs...)
+	if bytes >= 0 {
+		attrs = append(attrs, slog.Int64("bytes", bytes))
+	}
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	if err != nil {
+		level = storage.ErrorLevel
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	storage.Logger.LogAttrs(context.Background(), level, Message, attrs...)
+}
+
+// putSize 返回上传的字节数，优先使用上传后的对象大小
+func putSize(object *oss.Object, size int64) int64 {
+	if object != nil && object.Size > 0 {
+		return object.Size
+	}
+	return size
+}
+
+// loggedReader 关闭时记录日志的文件流
+type loggedReader struct {
+	io.ReadCloser
+	storage *Storage
+	op      string
+	path    string
+	start   time.Time
+	bytes   int64
+	err     error
+	closed  bool
+}
+
+// Read 读取内容并统计字节数
+func (reader *loggedReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	reader.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		reader.err = err
+	}
+	return n, err
+}
+
+// Close 关闭文件流并记录日志，读取时的错误优先于关闭时的错误
+func (reader *loggedReader) Close() error {
+	err := reader.ReadCloser.Close()
+	if !reader.closed {
+		reader.closed = true
+		logErr := reader.err
+		if logErr == nil {
+			logErr = err
+		}
+		reader.storage.log(reader.op, reader.path, reader.bytes, reader.start, logErr)
+	}
+	return err
+}
+
+// loggedWriter 关闭时记录日志的写入器
+type loggedWriter struct {
+	io.WriteCloser
+	storage *Storage
+	path    string
+	start   time.Time
+	bytes   int64
+	closed  bool
+}
+
+// Write 写入内容并统计字节数
+func (writer *loggedWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.bytes += int64(n)
+	return n, err
+}
+
+// Close 关闭写入器并记录日志
+func (writer *loggedWriter) Close() error {
+	err := writer.WriteCloser.Close()
+	if !writer.closed {
+		writer.closed = true
+		writer.storage.log("NewWriter", writer.path, writer.bytes, writer.start, err)
+	}
+	return err
+}
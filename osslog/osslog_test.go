@@ -0,0 +1,48 @@
+package osslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestWrap(t *testing.T) {
+	var buffer bytes.Buffer
+	storage := Wrap(filesystem.New(t.TempDir()), slog.New(slog.NewJSONHandler(&buffer, nil)))
+
+	storage.Put("/a.txt", strings.NewReader("sample"))
+	stream, _ := storage.GetStream("/a.txt")
+	io.ReadAll(stream)
+	stream.Close()
+	if _, err := storage.Stat("/missing.txt"); !errors.Is(err, oss.ErrNotFound) {
+		t.Errorf("Stat should return the wrapped error, but got %v", err)
+	}
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Log line should be JSON, but got %v", line)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Should log 3 operations, but got %v", records)
+	}
+	if records[0]["op"] != "Put" || records[0]["path"] != "/a.txt" || records[0]["bytes"] != float64(6) || records[0]["level"] != "INFO" {
+		t.Errorf("Put should be logged with path and bytes, but got %v", records[0])
+	}
+	if records[1]["op"] != "GetStream" || records[1]["bytes"] != float64(6) || records[1]["duration"] == nil {
+		t.Errorf("GetStream should be logged on close with bytes read, but got %v", records[1])
+	}
+	if records[2]["level"] != "ERROR" || records[2]["error"] == nil {
+		t.Errorf("Failed Stat should be logged as error, but got %v", records[2])
+	}
+}
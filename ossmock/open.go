@@ -0,0 +1,37 @@
+package ossmock
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/smart-unicom/oss"
+)
+
+func init() {
+	oss.Register("mock", open)
+}
+
+// open 根据连接字符串创建内存模拟存储，每次调用返回新的空存储
+// 格式为 mock://?endpoint=https://cdn.example.com&max_key_bytes=1024
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - oss.StorageInterface: 模拟存储实例
+//   - error: 错误信息
+func open(dsn url.URL) (oss.StorageInterface, error) {
+	params := oss.NewDSNParams(dsn)
+	storage := New()
+	storage.Endpoint = params.Get("endpoint")
+	if maxKeyBytes := params.Get("max_key_bytes"); maxKeyBytes != "" {
+		n, err := strconv.Atoi(maxKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("ossmock: dsn parameter max_key_bytes: invalid number %q", maxKeyBytes)
+		}
+		storage.MaxKeyBytes = n
+	}
+	if err := params.Err(); err != nil {
+		return nil, err
+	}
+	return storage, nil
+}
@@ -0,0 +1,670 @@
+// Package ossmock 提供 oss.StorageInterface 的内存模拟实现
+// 默认行为与真实存储后端保持一致并通过公共测试用例校验，
+// 同时记录每次调用，可以为任意方法设置自定义返回值或错误，供下游单元测试使用
+package ossmock
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	pathpkg "path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+var _ oss.StorageInterface = (*Storage)(nil)
+
+// DefaultMaxKeyBytes 对象键的默认最大字节数
+const DefaultMaxKeyBytes = 1024
+
+// Call 一次方法调用的记录
+type Call struct {
+	// Method 方法名，例如 Put
+	Method string
+	// Args 调用参数，顺序与方法签名一致
+	Args []interface{}
+}
+
+// Storage 内存模拟存储
+// 每次调用都会被记录；FailWith 设置的错误优先返回，其次是方法对应的非nil Func 字段，最后使用内存存储的默认行为
+type Storage struct {
+	// Endpoint GetEndpoint 返回的服务端点，默认为 /
+	Endpoint string
+	// MaxKeyBytes 对象键的最大字节数，超过时返回 oss.ErrKeyTooLong，小于等于0时使用 DefaultMaxKeyBytes
+	MaxKeyBytes int
+
+	GetFunc            func(path string) (*os.File, error)
+	GetStreamFunc      func(path string) (io.ReadCloser, error)
+	GetStreamRangeFunc func(path string, offset, length int64) (io.ReadCloser, error)
+	StatFunc           func(path string) (*oss.Object, error)
+	ExistsFunc         func(path string) (bool, error)
+	PutFunc            func(path string, reader io.Reader) (*oss.Object, error)
+	PutWithOptionsFunc func(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error)
+	NewWriterFunc      func(path string) (io.WriteCloser, error)
+	DeleteFunc         func(path string) error
+	DeleteObjectsFunc  func(paths []string) error
+	DeleteDirFunc      func(dir string) error
+	CopyFunc           func(srcPath, dstPath string) error
+	MoveFunc           func(srcPath, dstPath string) error
+	ListFunc           func(path string) ([]*oss.Object, error)
+	GetURLFunc         func(path string) (string, error)
+	GetSignedURLFunc   func(path string, opts oss.SignedURLOptions) (string, error)
+	GetUploadURLFunc   func(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error)
+
+	mu       sync.Mutex
+	objects  map[string]*object
+	calls    []Call
+	failures map[string]error
+}
+
+// object 内存中保存的对象
+type object struct {
+	data         []byte
+	contentType  string
+	metadata     map[string]string
+	lastModified *time.Time
+}
+
+// New 创建空的内存模拟存储
+// 返回:
+//   - *Storage: 模拟存储实例
+func New() *Storage {
+	return &Storage{}
+}
+
+// Calls 返回按调用顺序记录的全部调用
+// 返回:
+//   - []Call: 调用记录的副本
+func (storage *Storage) Calls() []Call {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	return append([]Call(nil), storage.calls...)
+}
+
+// CallsTo 返回指定方法的调用记录
+// 参数:
+//   - method: 方法名，例如 Put
+// 返回:
+//   - []Call: 调用记录
+func (storage *Storage) CallsTo(method string) []Call {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	var calls []Call
+	for _, call := range storage.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// FailWith 让指定方法返回错误
+// 参数:
+//   - method: 方法名，例如 Put
+//   - err: 返回的错误，为nil时恢复默认行为
+func (storage *Storage) FailWith(method string, err error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	if storage.failures == nil {
+		storage.failures = map[string]error{}
+	}
+	if err == nil {
+		delete(storage.failures, method)
+	} else {
+		storage.failures[method] = err
+	}
+}
+
+// Reset 清空调用记录和 FailWith 设置的错误，保留已保存的对象
+func (storage *Storage) Reset() {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.calls = nil
+	storage.failures = nil
+}
+
+// record 记录调用并返回 FailWith 设置的错误
+func (storage *Storage) record(method string, args ...interface{}) error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	storage.calls = append(storage.calls, Call{Method: method, Args: args})
+	return storage.failures[method]
+}
+
+// Get 获取指定路径的文件，内容写入临时文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 临时文件，调用方负责删除
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (*os.File, error) {
+	if err := storage.record("Get", path); err != nil {
+		return nil, err
+	}
+	if storage.GetFunc != nil {
+		return storage.GetFunc(path)
+	}
+
+	data, err := storage.read(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := oss.CreateTempFile("ossmock-*" + pathpkg.Ext(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(data); err != nil {
+		oss.RemoveTempFile(file)
+		return nil, err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	return file, err
+}
+
+// GetStream 获取指定路径文件的流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	if err := storage.record("GetStream", path); err != nil {
+		return nil, err
+	}
+	if storage.GetStreamFunc != nil {
+		return storage.GetStreamFunc(path)
+	}
+
+	data, err := storage.read(path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := storage.record("GetStreamRange", path, offset, length); err != nil {
+		return nil, err
+	}
+	if storage.GetStreamRangeFunc != nil {
+		return storage.GetStreamRangeFunc(path, offset, length)
+	}
+
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+	data, err := storage.read(path)
+	if err != nil {
+		return nil, err
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length > 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Stat 获取指定路径文件的元信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象元信息
+//   - error: 错误信息，对象不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Stat(path string) (*oss.Object, error) {
+	if err := storage.record("Stat", path); err != nil {
+		return nil, err
+	}
+	if storage.StatFunc != nil {
+		return storage.StatFunc(path)
+	}
+	return storage.stat(path)
+}
+
+// Exists 检查指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *Storage) Exists(path string) (bool, error) {
+	if err := storage.record("Exists", path); err != nil {
+		return false, err
+	}
+	if storage.ExistsFunc != nil {
+		return storage.ExistsFunc(path)
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	_, ok := storage.objects[objectKey(path)]
+	return ok, nil
+}
+
+// Put 上传文件到指定路径
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	if err := storage.record("Put", path, reader); err != nil {
+		return nil, err
+	}
+	if storage.PutFunc != nil {
+		return storage.PutFunc(path, reader)
+	}
+	return storage.put(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时与Put相同
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	if err := storage.record("PutWithOptions", path, reader, opts); err != nil {
+		return nil, err
+	}
+	if storage.PutWithOptionsFunc != nil {
+		return storage.PutWithOptionsFunc(path, reader, opts)
+	}
+	return storage.put(path, reader, opts)
+}
+
+// NewWriter 创建流式写入器，Close 成功返回后对象才可见
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	if err := storage.record("NewWriter", path); err != nil {
+		return nil, err
+	}
+	if storage.NewWriterFunc != nil {
+		return storage.NewWriterFunc(path)
+	}
+
+	if err := storage.validateKey(path); err != nil {
+		return nil, err
+	}
+	return oss.NewPipeWriter(func(reader io.Reader) error {
+		_, err := storage.put(path, reader, nil)
+		return err
+	}), nil
+}
+
+// Delete 删除指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息，对象不存在时返回 oss.ErrObjectNotFound
+func (storage *Storage) Delete(path string) error {
+	if err := storage.record("Delete", path); err != nil {
+		return err
+	}
+	if storage.DeleteFunc != nil {
+		return storage.DeleteFunc(path)
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	key := objectKey(path)
+	if _, ok := storage.objects[key]; !ok {
+		return notFound(path)
+	}
+	delete(storage.objects, key)
+	return nil
+}
+
+// DeleteObjects 批量删除多个文件，不存在的文件视为删除成功
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	if err := storage.record("DeleteObjects", paths); err != nil {
+		return err
+	}
+	if storage.DeleteObjectsFunc != nil {
+		return storage.DeleteObjectsFunc(paths)
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for _, path := range paths {
+		delete(storage.objects, objectKey(path))
+	}
+	return nil
+}
+
+// DeleteDir 删除目录下的全部文件
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (storage *Storage) DeleteDir(dir string) error {
+	if err := storage.record("DeleteDir", dir); err != nil {
+		return err
+	}
+	if storage.DeleteDirFunc != nil {
+		return storage.DeleteDirFunc(dir)
+	}
+
+	prefix := oss.DirPrefix(dir)
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	for key := range storage.objects {
+		if strings.HasPrefix(key, "/"+prefix) {
+			delete(storage.objects, key)
+		}
+	}
+	return nil
+}
+
+// Copy 复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	if err := storage.record("Copy", srcPath, dstPath); err != nil {
+		return err
+	}
+	if storage.CopyFunc != nil {
+		return storage.CopyFunc(srcPath, dstPath)
+	}
+	return storage.copy(srcPath, dstPath, false)
+}
+
+// Move 移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	if err := storage.record("Move", srcPath, dstPath); err != nil {
+		return err
+	}
+	if storage.MoveFunc != nil {
+		return storage.MoveFunc(srcPath, dstPath)
+	}
+	return storage.copy(srcPath, dstPath, true)
+}
+
+// List 列出目录下的全部文件，按路径字典序排列
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	if err := storage.record("List", path); err != nil {
+		return nil, err
+	}
+	if storage.ListFunc != nil {
+		return storage.ListFunc(path)
+	}
+
+	prefix := "/" + oss.DirPrefix(path)
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	var objects []*oss.Object
+	for key, item := range storage.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, storage.toObject(key, item))
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path })
+	return objects, nil
+}
+
+// GetURL 获取指定路径文件的访问URL
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 对象路径
+//   - error: 错误信息
+func (storage *Storage) GetURL(path string) (string, error) {
+	if err := storage.record("GetURL", path); err != nil {
+		return "", err
+	}
+	if storage.GetURLFunc != nil {
+		return storage.GetURLFunc(path)
+	}
+	return objectKey(path), nil
+}
+
+// GetSignedURL 生成指定路径文件的预签名URL，默认不支持
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息，默认返回 oss.ErrNotSupported
+func (storage *Storage) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	if err := storage.record("GetSignedURL", path, opts); err != nil {
+		return "", err
+	}
+	if storage.GetSignedURLFunc != nil {
+		return storage.GetSignedURLFunc(path, opts)
+	}
+	return "", fmt.Errorf("%w: ossmock does not support signed URL", oss.ErrNotSupported)
+}
+
+// GetUploadURL 生成客户端直传地址，默认不支持
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址
+//   - error: 错误信息，默认返回 oss.ErrNotSupported
+func (storage *Storage) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	if err := storage.record("GetUploadURL", path, opts); err != nil {
+		return nil, err
+	}
+	if storage.GetUploadURLFunc != nil {
+		return storage.GetUploadURLFunc(path, opts)
+	}
+	return nil, fmt.Errorf("%w: ossmock does not support upload URL", oss.ErrNotSupported)
+}
+
+// GetEndpoint 获取服务端点
+// 返回:
+//   - string: Endpoint，未设置时返回 /
+func (storage *Storage) GetEndpoint() string {
+	if storage.Endpoint == "" {
+		return "/"
+	}
+	return storage.Endpoint
+}
+
+// read 读取对象内容的副本
+func (storage *Storage) read(path string) ([]byte, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	item, ok := storage.objects[objectKey(path)]
+	if !ok {
+		return nil, notFound(path)
+	}
+	return append([]byte(nil), item.data...), nil
+}
+
+// stat 获取对象元信息
+func (storage *Storage) stat(path string) (*oss.Object, error) {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+
+	key := objectKey(path)
+	item, ok := storage.objects[key]
+	if !ok {
+		return nil, notFound(path)
+	}
+	return storage.toObject(key, item), nil
+}
+
+// put 保存对象
+func (storage *Storage) put(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	path, err := oss.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	normalized := opts.Normalize(path)
+	opts = &normalized
+
+	if err := storage.validateKey(path); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(opts.WrapReader(reader, oss.ReaderSize(reader)))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := opts.ContentChecksum(data); err != nil {
+		return nil, err
+	}
+
+	item := &object{
+		data:         data,
+		contentType:  opts.ContentType,
+		metadata:     oss.NormalizeMetadata(opts.Metadata),
+		lastModified: oss.NormalizeTime(oss.Now()),
+	}
+	if item.contentType == "" {
+		item.contentType = mime.TypeByExtension(pathpkg.Ext(path))
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.objects == nil {
+		storage.objects = map[string]*object{}
+	}
+	key, err := storage.resolveKey(path, opts.Collision)
+	if err != nil {
+		return nil, err
+	}
+	storage.objects[key] = item
+	return storage.toObject(key, item), nil
+}
+
+// resolveKey 按冲突策略确定保存对象的键，调用方需要持有锁
+func (storage *Storage) resolveKey(path string, policy oss.CollisionPolicy) (string, error) {
+	switch policy {
+	case "", oss.CollisionOverwrite, oss.CollisionError, oss.CollisionRename:
+	default:
+		return "", fmt.Errorf("%w: collision policy %s", oss.ErrNotSupported, policy)
+	}
+
+	key := objectKey(path)
+	if _, exists := storage.objects[key]; !exists || policy == "" || policy == oss.CollisionOverwrite {
+		return key, nil
+	}
+	if policy == oss.CollisionError {
+		return "", fmt.Errorf("%w: %s already exists", oss.ErrConflict, path)
+	}
+	for n := 1; n <= oss.MaxRenameAttempts; n++ {
+		candidate := objectKey(oss.RenamedPath(path, n))
+		if _, exists := storage.objects[candidate]; !exists {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no free name for %s", oss.ErrConflict, path)
+}
+
+// copy 复制对象，remove为true时删除源对象
+func (storage *Storage) copy(srcPath, dstPath string, remove bool) error {
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	srcKey, dstKey := objectKey(srcPath), objectKey(dstPath)
+	if srcKey == dstKey {
+		return nil
+	}
+	if err := storage.validateKey(dstPath); err != nil {
+		return err
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	item, ok := storage.objects[srcKey]
+	if !ok {
+		return notFound(srcPath)
+	}
+	copied := *item
+	copied.lastModified = oss.NormalizeTime(oss.Now())
+	storage.objects[dstKey] = &copied
+	if remove {
+		delete(storage.objects, srcKey)
+	}
+	return nil
+}
+
+// validateKey 校验对象键长度
+func (storage *Storage) validateKey(path string) error {
+	maxKeyBytes := storage.MaxKeyBytes
+	if maxKeyBytes <= 0 {
+		maxKeyBytes = DefaultMaxKeyBytes
+	}
+	return oss.ValidateKeyLength(strings.TrimPrefix(objectKey(path), "/"), maxKeyBytes, 0)
+}
+
+// toObject 将内存对象转换为对象信息，调用方需持有锁
+func (storage *Storage) toObject(key string, item *object) *oss.Object {
+	sum := md5.Sum(item.data)
+	return &oss.Object{
+		Path:             key,
+		Name:             pathpkg.Base(key),
+		LastModified:     item.lastModified,
+		Size:             int64(len(item.data)),
+		ContentType:      item.contentType,
+		ETag:             `"` + hex.EncodeToString(sum[:]) + `"`,
+		Metadata:         item.metadata,
+		StorageInterface: storage,
+	}
+}
+
+// objectKey 将路径统一为以 / 开头的对象键
+func objectKey(p string) string {
+	return "/" + strings.TrimPrefix(pathpkg.Clean("/"+p), "/")
+}
+
+// notFound 返回对象不存在的错误
+func notFound(path string) error {
+	return oss.WrapError(oss.ErrObjectNotFound, fmt.Errorf("ossmock: %s not found", path))
+}
@@ -0,0 +1,88 @@
+package ossmock
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestAll(t *testing.T) {
+	tests.TestAll(New(), t)
+}
+
+func TestCalls(t *testing.T) {
+	storage := New()
+	storage.Put("/a.txt", strings.NewReader("a"))
+	storage.Stat("/a.txt")
+	storage.Put("/b.txt", strings.NewReader("b"))
+
+	if calls := storage.Calls(); len(calls) != 3 || calls[1].Method != "Stat" {
+		t.Errorf("Should record calls in order, but got %v", calls)
+	}
+	if calls := storage.CallsTo("Put"); len(calls) != 2 || calls[1].Args[0] != "/b.txt" {
+		t.Errorf("Should record Put calls with arguments, but got %v", calls)
+	}
+
+	storage.Reset()
+	if calls := storage.Calls(); len(calls) != 0 {
+		t.Errorf("Reset should clear calls, but got %v", calls)
+	}
+	if exists, _ := storage.Exists("/a.txt"); !exists {
+		t.Errorf("Reset should keep stored objects")
+	}
+}
+
+func TestProgrammedResponses(t *testing.T) {
+	storage := New()
+
+	storage.FailWith("Put", oss.ErrRateLimited)
+	if _, err := storage.Put("/a.txt", strings.NewReader("a")); !errors.Is(err, oss.ErrRateLimited) {
+		t.Errorf("Put should return programmed error, but got %v", err)
+	}
+	storage.FailWith("Put", nil)
+	if _, err := storage.Put("/a.txt", strings.NewReader("a")); err != nil {
+		t.Errorf("Put should succeed after clearing error, but got %v", err)
+	}
+
+	storage.GetStreamFunc = func(path string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("programmed")), nil
+	}
+	if stream, err := storage.GetStream("/missing.txt"); err != nil {
+		t.Errorf("GetStream should use programmed response, but got %v", err)
+	} else if buffer, _ := io.ReadAll(stream); string(buffer) != "programmed" {
+		t.Errorf("GetStream should return programmed content, but got %q", string(buffer))
+	}
+
+	if _, err := storage.Stat("/missing.txt"); !errors.Is(err, oss.ErrObjectNotFound) {
+		t.Errorf("Stat of missing object should return ErrObjectNotFound, but got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	storage, err := oss.Open("mock://?endpoint=https://cdn.example.com&prefix=/tenant-a")
+	if err != nil {
+		t.Fatalf("No error should happen when open mock dsn, but got %v", err)
+	}
+	prefixed, ok := storage.(*oss.PrefixedStorage)
+	if !ok {
+		t.Fatalf("Open with prefix should return *oss.PrefixedStorage, but got %T", storage)
+	}
+	if _, err := prefixed.Put("/a.txt", strings.NewReader("a")); err != nil {
+		t.Fatalf("No error should happen when put through opened storage, but got %v", err)
+	}
+	mock := prefixed.StorageInterface.(*Storage)
+	if mock.Endpoint != "https://cdn.example.com" {
+		t.Errorf("Endpoint should be read from dsn, but got %v", mock.Endpoint)
+	}
+	if exists, _ := mock.Exists("/tenant-a/a.txt"); !exists {
+		t.Errorf("Object should be saved under the dsn prefix")
+	}
+
+	if _, err := oss.Open("mock://?max_key_bytes=abc"); err == nil {
+		t.Errorf("Invalid max_key_bytes should be rejected")
+	}
+}
@@ -0,0 +1,268 @@
+// Package ossretry 重试临时错误的存储包装器
+// 在存储后端自身的重试之外，按存储接口的调用重试幂等操作，例如群晖和七牛手写的HTTP调用中连接中断后的重新登录和读取
+package ossretry
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// Config 重试配置，未设置的字段使用默认值
+type Config struct {
+	// MaxAttempts 最大尝试次数，包括第一次调用，为1时不重试，小于等于0时使用 oss.DefaultRetryMaxAttempts
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间，之后每次翻倍并带有随机抖动，小于等于0时使用 oss.DefaultRetryInitialBackoff
+	InitialBackoff time.Duration
+	// MaxBackoff 两次重试之间的最长等待时间，小于等于0时使用 oss.DefaultRetryMaxBackoff
+	MaxBackoff time.Duration
+	// Retryable 判断错误是否需要重试，为nil时使用 Retryable
+	Retryable func(err error) bool
+}
+
+// Storage 重试临时错误的存储包装器
+// 重试 Get、GetStream、GetStreamRange、Stat、Exists、List、Delete、DeleteObjects、DeleteDir 和 Copy；
+// Put 和 PutWithOptions 只在内容可以Seek时重试；Move 和 NewWriter 不是幂等操作，不会重试
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+	// Config 重试配置
+	Config Config
+}
+
+// Wrap 创建重试临时错误的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - config: 重试配置
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, config Config) *Storage {
+	return &Storage{StorageInterface: storage, Config: config}
+}
+
+// Retryable 默认的重试判断：限流、服务暂时不可用、超时、连接被重置和响应被截断
+// 参数:
+//   - err: 调用返回的错误
+// 返回:
+//   - bool: 是否需要重试
+func Retryable(err error) bool {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, oss.ErrRateLimited), errors.Is(err, oss.ErrUnavailable), errors.Is(err, oss.ErrTimeout):
+		return true
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return false
+}
+
+// do 调用函数，遇到可以重试的错误时等待后重试
+func (storage *Storage) do(fn func(attempt int) error) error {
+	retry := &oss.RetryConfig{MaxAttempts: storage.Config.MaxAttempts, InitialBackoff: storage.Config.InitialBackoff, MaxBackoff: storage.Config.MaxBackoff}
+	retryable := storage.Config.Retryable
+	if retryable == nil {
+		retryable = Retryable
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(attempt); err == nil || attempt >= retry.Attempts() || !retryable(err) {
+			return err
+		}
+		time.Sleep(retry.Backoff(attempt))
+	}
+}
+
+// Get 获取文件，遇到临时错误时重试
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (file *os.File, err error) {
+	err = storage.do(func(int) error {
+		file, err = storage.StorageInterface.Get(path)
+		return err
+	})
+	return file, err
+}
+
+// GetStream 获取文件流，打开流时遇到临时错误时重试，读取过程中的错误不会重试
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStream(path string) (stream io.ReadCloser, err error) {
+	err = storage.do(func(int) error {
+		stream, err = storage.StorageInterface.GetStream(path)
+		return err
+	})
+	return stream, err
+}
+
+// GetStreamRange 范围读取文件流，打开流时遇到临时错误时重试
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (stream io.ReadCloser, err error) {
+	err = storage.do(func(int) error {
+		stream, err = storage.StorageInterface.GetStreamRange(path, offset, length)
+		return err
+	})
+	return stream, err
+}
+
+// Stat 获取对象信息，遇到临时错误时重试
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Stat(path string) (object *oss.Object, err error) {
+	err = storage.do(func(int) error {
+		object, err = storage.StorageInterface.Stat(path)
+		return err
+	})
+	return object, err
+}
+
+// Exists 检查对象是否存在，遇到临时错误时重试
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *Storage) Exists(path string) (exists bool, err error) {
+	err = storage.do(func(int) error {
+		exists, err = storage.StorageInterface.Exists(path)
+		return err
+	})
+	return exists, err
+}
+
+// Put 上传文件，内容可以Seek时遇到临时错误回到原来的位置重试
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return storage.put(reader, func() (*oss.Object, error) {
+		return storage.StorageInterface.Put(path, reader)
+	})
+}
+
+// PutWithOptions 使用指定选项上传文件，内容可以Seek时遇到临时错误回到原来的位置重试
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	return storage.put(reader, func() (*oss.Object, error) {
+		return storage.StorageInterface.PutWithOptions(path, reader, opts)
+	})
+}
+
+// put 记录内容的起始位置，每次重试前回到该位置；内容不能Seek时只上传一次
+func (storage *Storage) put(reader io.Reader, upload func() (*oss.Object, error)) (object *oss.Object, err error) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return upload()
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return upload()
+	}
+	err = storage.do(func(attempt int) error {
+		if attempt > 1 {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+		object, err = upload()
+		return err
+	})
+	return object, err
+}
+
+// Delete 删除文件，遇到临时错误时重试
+// 重试时对象已不存在说明之前的尝试已经删除成功，不返回错误
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Delete(path string) error {
+	return storage.do(func(attempt int) error {
+		err := storage.StorageInterface.Delete(path)
+		if attempt > 1 && errors.Is(err, oss.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// DeleteObjects 批量删除对象，遇到临时错误时重试
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	return storage.do(func(int) error {
+		return storage.StorageInterface.DeleteObjects(paths)
+	})
+}
+
+// DeleteDir 删除目录，遇到临时错误时重试
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteDir(dir string) error {
+	return storage.do(func(int) error {
+		return storage.StorageInterface.DeleteDir(dir)
+	})
+}
+
+// Copy 复制文件，遇到临时错误时重试
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	return storage.do(func(int) error {
+		return storage.StorageInterface.Copy(srcPath, dstPath)
+	})
+}
+
+// List 列出对象，遇到临时错误时重试
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) (objects []*oss.Object, err error) {
+	err = storage.do(func(int) error {
+		objects, err = storage.StorageInterface.List(path)
+		return err
+	})
+	return objects, err
+}
@@ -0,0 +1,67 @@
+package ossretry
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestWrap(t *testing.T) {
+	mock := ossmock.New()
+	storage := Wrap(mock, Config{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+	var contents []string
+	mock.PutFunc = func(path string, reader io.Reader) (*oss.Object, error) {
+		data, _ := io.ReadAll(reader)
+		contents = append(contents, string(data))
+		if len(contents) == 1 {
+			return nil, oss.ErrUnavailable
+		}
+		return &oss.Object{Path: path, Size: int64(len(data))}, nil
+	}
+	if _, err := storage.Put("/a.txt", strings.NewReader("sample")); err != nil || len(contents) != 2 || contents[1] != "sample" {
+		t.Errorf("Put should be retried from the start of the content, but got %v %v", contents, err)
+	}
+
+	contents = nil
+	if _, err := storage.Put("/a.txt", io.MultiReader(strings.NewReader("sample"))); !errors.Is(err, oss.ErrUnavailable) || len(contents) != 1 {
+		t.Errorf("Put with unseekable content should not be retried, but got %v attempts and %v", len(contents), err)
+	}
+
+	mock.FailWith("Stat", oss.ErrRateLimited)
+	if _, err := storage.Stat("/a.txt"); !errors.Is(err, oss.ErrRateLimited) || len(mock.CallsTo("Stat")) != 3 {
+		t.Errorf("Stat should be attempted 3 times, but got %v calls and %v", len(mock.CallsTo("Stat")), err)
+	}
+	mock.FailWith("Exists", oss.ErrPermissionDenied)
+	if _, err := storage.Exists("/a.txt"); !errors.Is(err, oss.ErrPermissionDenied) || len(mock.CallsTo("Exists")) != 1 {
+		t.Errorf("Permanent error should not be retried, but got %v calls", len(mock.CallsTo("Exists")))
+	}
+
+	deletes := 0
+	mock.DeleteFunc = func(path string) error {
+		if deletes++; deletes == 1 {
+			return oss.ErrTimeout
+		}
+		return oss.ErrObjectNotFound
+	}
+	if err := storage.Delete("/a.txt"); err != nil {
+		t.Errorf("Not found after a failed attempt should be treated as deleted, but got %v", err)
+	}
+
+	mock.FailWith("Move", oss.ErrUnavailable)
+	storage.Move("/a.txt", "/b.txt")
+	if len(mock.CallsTo("Move")) != 1 {
+		t.Errorf("Move should not be retried, but got %v calls", len(mock.CallsTo("Move")))
+	}
+
+	custom := Wrap(mock, Config{MaxAttempts: 2, InitialBackoff: time.Millisecond, Retryable: func(err error) bool { return errors.Is(err, oss.ErrPermissionDenied) }})
+	custom.Exists("/a.txt")
+	if len(mock.CallsTo("Exists")) != 3 {
+		t.Errorf("Retryable predicate should decide which errors to retry, but got %v calls", len(mock.CallsTo("Exists")))
+	}
+}
@@ -0,0 +1,298 @@
+// Package osssync 在任意两个存储之间同步对象
+// 列出源存储目录下的全部对象，复制目标存储中缺失或不一致的对象，可选删除目标存储中多余的对象，
+// 用于从一个云厂商迁移到另一个云厂商或定期镜像目录
+package osssync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// Strategy 判断目标对象是否需要重新复制的比较策略
+type Strategy int
+
+const (
+	// CompareModTime 大小相同并且目标对象的修改时间不早于源对象时跳过，默认策略
+	CompareModTime Strategy = iota
+	// CompareSize 大小相同时跳过，用于修改时间不可靠的存储
+	CompareSize
+	// CompareNone 不比较，总是复制
+	CompareNone
+)
+
+// Action 对一个对象执行的操作
+type Action string
+
+const (
+	// ActionCopy 复制到目标存储
+	ActionCopy Action = "copy"
+	// ActionSkip 目标对象已经一致，跳过
+	ActionSkip Action = "skip"
+	// ActionDelete 从目标存储删除多余的对象
+	ActionDelete Action = "delete"
+)
+
+// Options 同步选项
+type Options struct {
+	// Prefix 需要同步的目录，为空时同步全部对象，两个存储中使用相同的路径
+	Prefix string
+	// Concurrency 复制的并发数，小于等于0时使用 oss.DefaultBatchConcurrency
+	Concurrency int
+	// Include 只同步匹配任一模式的对象，为空时同步全部对象
+	// 使用 path.Match 语法匹配相对于 Prefix 的路径，不含 / 的模式只匹配对象名称，例如 *.jpg
+	Include []string
+	// Exclude 跳过匹配任一模式的对象，优先于 Include，语法与 Include 相同
+	Exclude []string
+	// Strategy 比较策略
+	Strategy Strategy
+	// ModTimeTolerance CompareModTime 允许的修改时间误差，用于修改时间精度不同的存储
+	ModTimeTolerance time.Duration
+	// Delete 为true时删除目标存储中源存储不存在的对象，被过滤的对象不会被删除
+	Delete bool
+	// DryRun 为true时只计算需要执行的操作，不复制也不删除
+	DryRun bool
+	// OnResult 每个对象完成后的回调，多个对象的回调不会同时执行，可以为nil
+	OnResult func(result Result)
+}
+
+// Result 一个对象的同步结果
+type Result struct {
+	// Path 对象路径
+	Path string
+	// Action 执行的操作
+	Action Action
+	// Size 复制的字节数
+	Size int64
+	// Err 失败时的错误信息
+	Err error
+}
+
+// Report 同步结果汇总
+type Report struct {
+	// Copied 复制的对象数
+	Copied int
+	// Skipped 跳过的对象数
+	Skipped int
+	// Deleted 删除的对象数
+	Deleted int
+	// Bytes 复制的字节数
+	Bytes int64
+	// Errors 失败的对象及错误信息
+	Errors map[string]error
+}
+
+// Sync 将源存储中的对象同步到目标存储
+// 参数:
+//   - ctx: 上下文，取消后不再开始新的复制，已经开始的复制会完成
+//   - src: 源存储
+//   - dst: 目标存储
+//   - opts: 同步选项，可以为nil
+// 返回:
+//   - *Report: 同步结果汇总，出错时也返回已完成的部分
+//   - error: 列出失败或 ctx 被取消时的错误信息，部分对象失败时返回包含失败数量的错误，详情见 Report.Errors
+func Sync(ctx context.Context, src, dst oss.StorageInterface, opts *Options) (*Report, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = oss.DefaultBatchConcurrency
+	}
+	report := &Report{Errors: map[string]error{}}
+
+	sources, err := listAll(src, opts)
+	if err != nil {
+		return report, fmt.Errorf("osssync: list source: %w", err)
+	}
+	targets, err := listAll(dst, opts)
+	if err != nil {
+		return report, fmt.Errorf("osssync: list destination: %w", err)
+	}
+
+	var (
+		mutex     sync.Mutex
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, concurrency)
+	)
+	record := func(result Result) {
+		mutex.Lock()
+		switch {
+		case result.Err != nil:
+			report.Errors[result.Path] = result.Err
+		case result.Action == ActionCopy:
+			report.Copied++
+			report.Bytes += result.Size
+		case result.Action == ActionSkip:
+			report.Skipped++
+		case result.Action == ActionDelete:
+			report.Deleted++
+		}
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+		mutex.Unlock()
+	}
+
+	for _, source := range sortedObjects(sources) {
+		if !opts.needsCopy(source, targets[source.Path]) {
+			record(Result{Path: source.Path, Action: ActionSkip})
+			continue
+		}
+		if opts.DryRun {
+			record(Result{Path: source.Path, Action: ActionCopy, Size: source.Size})
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case semaphore <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(source *oss.Object) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			size, err := copyObject(src, dst, source)
+			record(Result{Path: source.Path, Action: ActionCopy, Size: size, Err: err})
+		}(source)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	if opts.Delete {
+		var extraneous []string
+		for _, target := range sortedObjects(targets) {
+			if sources[target.Path] == nil {
+				extraneous = append(extraneous, target.Path)
+			}
+		}
+		if len(extraneous) > 0 && !opts.DryRun {
+			err := dst.DeleteObjects(extraneous)
+			var deleteErr *oss.DeleteObjectsError
+			for _, path := range extraneous {
+				switch {
+				case errors.As(err, &deleteErr):
+					record(Result{Path: path, Action: ActionDelete, Err: deleteErr.Errors[path]})
+				default:
+					record(Result{Path: path, Action: ActionDelete, Err: err})
+				}
+			}
+		} else {
+			for _, path := range extraneous {
+				record(Result{Path: path, Action: ActionDelete})
+			}
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("osssync: %d objects failed", len(report.Errors))
+	}
+	return report, nil
+}
+
+// listAll 递归列出目录下通过过滤的对象，以规范化的路径为键
+func listAll(storage oss.StorageInterface, opts *Options) (map[string]*oss.Object, error) {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = "/"
+	}
+	result, err := oss.ListWithOptions(storage, prefix, oss.ListOptions{Recursive: true})
+	if err != nil {
+		if errors.Is(err, oss.ErrNotFound) {
+			return map[string]*oss.Object{}, nil
+		}
+		return nil, err
+	}
+
+	base := oss.DirPrefix(prefix)
+	objects := make(map[string]*oss.Object, len(result.Objects))
+	for _, object := range result.Objects {
+		key := strings.TrimPrefix(object.Path, "/")
+		if strings.HasSuffix(key, "/") || !opts.matches(key[len(base):]) {
+			continue
+		}
+		objects["/"+key] = object
+	}
+	return objects, nil
+}
+
+// matches 判断相对路径是否通过包含和排除模式
+func (opts *Options) matches(relative string) bool {
+	for _, pattern := range opts.Exclude {
+		if matchPattern(pattern, relative) {
+			return false
+		}
+	}
+	if len(opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range opts.Include {
+		if matchPattern(pattern, relative) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern 不含 / 的模式匹配对象名称，否则匹配相对路径
+func matchPattern(pattern, relative string) bool {
+	if !strings.Contains(pattern, "/") {
+		relative = path.Base(relative)
+	}
+	matched, err := path.Match(strings.TrimPrefix(pattern, "/"), relative)
+	return err == nil && matched
+}
+
+// needsCopy 按比较策略判断源对象是否需要复制
+func (opts *Options) needsCopy(source, target *oss.Object) bool {
+	if target == nil || opts.Strategy == CompareNone || target.Size != source.Size {
+		return true
+	}
+	if opts.Strategy == CompareSize {
+		return false
+	}
+	if source.LastModified == nil || target.LastModified == nil {
+		return true
+	}
+	return target.LastModified.Add(opts.ModTimeTolerance).Before(*source.LastModified)
+}
+
+// copyObject 流式复制一个对象，保留内容类型和元数据
+func copyObject(src, dst oss.StorageInterface, source *oss.Object) (int64, error) {
+	stream, err := src.GetStream(source.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+
+	object, err := dst.PutWithOptions(source.Path, stream, &oss.PutOptions{ContentType: source.ContentType, Metadata: source.Metadata})
+	if err != nil {
+		return 0, err
+	}
+	if object != nil && object.Size > 0 {
+		return object.Size, nil
+	}
+	return source.Size, nil
+}
+
+// sortedObjects 按路径排序，使复制和删除的顺序稳定
+func sortedObjects(objects map[string]*oss.Object) []*oss.Object {
+	sorted := make([]*oss.Object, 0, len(objects))
+	for path, object := range objects {
+		sorted = append(sorted, &oss.Object{Path: path, Name: object.Name, LastModified: object.LastModified, Size: object.Size,
+			ContentType: object.ContentType, ETag: object.ETag, Metadata: object.Metadata})
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	return sorted
+}
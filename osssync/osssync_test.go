@@ -0,0 +1,108 @@
+package osssync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func put(t *testing.T, storage oss.StorageInterface, path, content string) {
+	t.Helper()
+	if _, err := storage.Put(path, strings.NewReader(content)); err != nil {
+		t.Fatalf("Failed to put %v, got %v", path, err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	src, dst := ossmock.New(), ossmock.New()
+	put(t, src, "/data/a.jpg", "image")
+	put(t, src, "/data/b.txt", "text")
+	put(t, src, "/data/tmp/c.jpg", "temporary")
+	put(t, src, "/other/d.jpg", "outside")
+	put(t, dst, "/data/b.txt", "text")
+	put(t, dst, "/data/stale.jpg", "extraneous")
+	put(t, dst, "/data/keep.log", "filtered")
+
+	var results []Result
+	report, err := Sync(context.Background(), src, dst, &Options{
+		Prefix:   "/data",
+		Include:  []string{"*.jpg", "*.txt"},
+		Exclude:  []string{"tmp/*"},
+		Delete:   true,
+		OnResult: func(result Result) { results = append(results, result) },
+	})
+	if err != nil {
+		t.Fatalf("Sync should succeed, got %v", err)
+	}
+	if report.Copied != 1 || report.Skipped != 1 || report.Deleted != 1 || report.Bytes != 5 || len(results) != 3 {
+		t.Errorf("Should copy a.jpg, skip b.txt and delete stale.jpg, but got %+v", report)
+	}
+	for path, expected := range map[string]bool{"/data/a.jpg": true, "/data/tmp/c.jpg": false, "/other/d.jpg": false, "/data/stale.jpg": false, "/data/keep.log": true} {
+		if exists, _ := dst.Exists(path); exists != expected {
+			t.Errorf("%v should exist in destination: %v", path, expected)
+		}
+	}
+	if calls := len(dst.CallsTo("PutWithOptions")); calls != 1 {
+		t.Errorf("Unchanged objects should not be copied again, but got %d uploads", calls)
+	}
+
+	second, err := Sync(context.Background(), src, dst, &Options{Prefix: "/data", Include: []string{"*.jpg", "*.txt"}, Exclude: []string{"tmp/*"}})
+	if err != nil || second.Copied != 0 || second.Skipped != 2 {
+		t.Errorf("Second sync should skip everything, but got %+v, %v", second, err)
+	}
+}
+
+func TestSyncChangedSize(t *testing.T) {
+	src, dst := ossmock.New(), ossmock.New()
+	put(t, src, "/a.txt", "new content")
+	put(t, dst, "/a.txt", "old")
+
+	report, err := Sync(context.Background(), src, dst, &Options{Strategy: CompareSize})
+	if err != nil || report.Copied != 1 {
+		t.Fatalf("Objects with a different size should be copied, got %+v, %v", report, err)
+	}
+	stream, _ := dst.GetStream("/a.txt")
+	defer stream.Close()
+	if content, _ := io.ReadAll(stream); string(content) != "new content" {
+		t.Errorf("Destination should be overwritten, but got %q", content)
+	}
+}
+
+func TestSyncDryRun(t *testing.T) {
+	src, dst := ossmock.New(), ossmock.New()
+	put(t, src, "/a.txt", "content")
+	put(t, dst, "/b.txt", "extraneous")
+
+	report, err := Sync(context.Background(), src, dst, &Options{Delete: true, DryRun: true})
+	if err != nil || report.Copied != 1 || report.Deleted != 1 {
+		t.Fatalf("Dry run should report the planned actions, got %+v, %v", report, err)
+	}
+	if exists, _ := dst.Exists("/a.txt"); exists {
+		t.Errorf("Dry run should not copy")
+	}
+	if exists, _ := dst.Exists("/b.txt"); !exists {
+		t.Errorf("Dry run should not delete")
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	src, dst := ossmock.New(), ossmock.New()
+	put(t, src, "/a.txt", "content")
+	dst.FailWith("PutWithOptions", errors.New("quota exceeded"))
+
+	report, err := Sync(context.Background(), src, dst, nil)
+	if err == nil || report.Errors["/a.txt"] == nil {
+		t.Errorf("Failed copies should be reported, but got %+v, %v", report, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Sync(ctx, src, ossmock.New(), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled sync should return the context error, but got %v", err)
+	}
+}
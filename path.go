@@ -0,0 +1,49 @@
+package oss
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reservedNames Windows保留的设备名，不区分大小写，带扩展名时同样保留
+var reservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// NormalizePath 规范化来自Windows的路径
+// 反斜杠转换为斜杠，去掉开头的盘符，例如 C:\docs\a.txt 转换为 /docs/a.txt
+// 各存储后端在上传、复制和创建分片上传前调用，其它路径原样返回
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 规范化后的路径
+//   - error: 路径段为 CON、NUL 等Windows保留设备名时返回 ErrInvalidPath
+func NormalizePath(path string) (string, error) {
+	path = strings.ReplaceAll(path, `\`, "/")
+	if len(path) >= 2 && path[1] == ':' && isDriveLetter(path[0]) {
+		path = path[2:]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		name := segment
+		if index := strings.IndexByte(name, '.'); index >= 0 {
+			name = name[:index]
+		}
+		if reservedNames[strings.ToUpper(strings.TrimRight(name, " "))] {
+			return "", fmt.Errorf("%w: %q contains reserved device name %s", ErrInvalidPath, path, segment)
+		}
+	}
+	return path, nil
+}
+
+// isDriveLetter 判断字符是否为盘符字母
+func isDriveLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,32 @@
+package oss_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+func TestNormalizePath(t *testing.T) {
+	for path, expected := range map[string]string{
+		`C:\docs\a.txt`: "/docs/a.txt",
+		`d:docs\a.txt`:  "/docs/a.txt",
+		`\docs\a.txt`:   "/docs/a.txt",
+		"/docs/a.txt":   "/docs/a.txt",
+		"/docs/CONFIG":  "/docs/CONFIG",
+	} {
+		if normalized, err := oss.NormalizePath(path); err != nil || normalized != expected {
+			t.Errorf("%v should be normalized to %v, but got %v, %v", path, expected, normalized, err)
+		}
+	}
+	for _, path := range []string{"/docs/CON", `C:\docs\nul.txt`, "/com1/a.txt", "/docs/Aux "} {
+		if _, err := oss.NormalizePath(path); !errors.Is(err, oss.ErrInvalidPath) {
+			t.Errorf("%v should be rejected as reserved device name, but got %v", path, err)
+		}
+	}
+
+	if status := oss.HTTPStatus(oss.ErrInvalidPath); status != http.StatusBadRequest {
+		t.Errorf("ErrInvalidPath should map to 400, but got %v", status)
+	}
+}
@@ -0,0 +1,186 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPendingDir 待认领上传的默认目录
+	DefaultPendingDir = "/_pending"
+	// DefaultPendingTTL 待认领上传的默认保留时间
+	DefaultPendingTTL = 24 * time.Hour
+)
+
+// ErrPendingExpired 待认领的上传已超过保留时间，errors.Is(err, ErrNotFound) 同样成立
+var ErrPendingExpired error = &kindError{message: "oss: pending upload expired", parent: ErrNotFound}
+
+// PendingUploads 待认领上传的工作流
+// 客户端先把文件上传到 Dir 下（通常通过 UploadURL 直传），应用确认业务数据后调用 Claim 将其移动到正式路径，
+// 超过 TTL 仍未认领的上传由 Sweep 删除，避免放弃的表单和失败的流程留下无主对象
+//
+// 目录结构:
+//   - <Dir>/<path>: 等待认领的上传
+//   - <path>: 认领后的正式路径
+type PendingUploads struct {
+	// Storage 存储接口
+	Storage StorageInterface
+	// Dir 待认领上传的目录
+	Dir string
+	// TTL 待认领上传的保留时间
+	TTL time.Duration
+}
+
+// NewPendingUploads 创建待认领上传的工作流
+// 参数:
+//   - storage: 存储接口
+//   - ttl: 待认领上传的保留时间，小于等于0时使用 DefaultPendingTTL
+// 返回:
+//   - *PendingUploads: 工作流实例，目录为 DefaultPendingDir
+func NewPendingUploads(storage StorageInterface, ttl time.Duration) *PendingUploads {
+	if ttl <= 0 {
+		ttl = DefaultPendingTTL
+	}
+	return &PendingUploads{Storage: storage, Dir: DefaultPendingDir, TTL: ttl}
+}
+
+// PendingPath 返回正式路径对应的待认领路径
+// 参数:
+//   - path: 正式路径
+// 返回:
+//   - string: 待认领路径
+//   - error: 路径包含 .. 等无效片段时返回 ErrInvalidPath
+func (pending *PendingUploads) PendingPath(path string) (string, error) {
+	if strings.Trim(path, `/\`) == "" {
+		return "", fmt.Errorf("%w: empty pending path", ErrInvalidPath)
+	}
+	return NewPrefixedStorage(pending.Storage, pending.Dir).resolve(strings.ReplaceAll(path, `\`, "/"))
+}
+
+// Put 将文件上传到待认领路径
+// 参数:
+//   - path: 正式路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 待认领的对象信息
+//   - error: 错误信息
+func (pending *PendingUploads) Put(path string, reader io.Reader) (*Object, error) {
+	pendingPath, err := pending.PendingPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return pending.Storage.Put(pendingPath, reader)
+}
+
+// UploadURL 生成客户端直传到待认领路径的上传URL
+// 参数:
+//   - path: 正式路径
+//   - opts: 上传URL选项
+// 返回:
+//   - *UploadURL: 上传URL
+//   - error: 错误信息
+func (pending *PendingUploads) UploadURL(path string, opts UploadURLOptions) (*UploadURL, error) {
+	pendingPath, err := pending.PendingPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return pending.Storage.GetUploadURL(pendingPath, opts)
+}
+
+// Claim 认领上传，将待认领路径的对象移动到正式路径
+// 参数:
+//   - path: 正式路径，与上传时使用的路径相同
+// 返回:
+//   - *Object: 正式路径的对象信息
+//   - error: 没有对应的上传时返回 ErrNotFound，超过保留时间时返回 ErrPendingExpired
+func (pending *PendingUploads) Claim(path string) (*Object, error) {
+	return pending.ClaimAs(path, path)
+}
+
+// ClaimAs 认领上传并移动到另一个正式路径，例如客户端上传时还不知道最终的对象ID
+// 参数:
+//   - path: 上传时使用的路径
+//   - dstPath: 正式路径
+// 返回:
+//   - *Object: 正式路径的对象信息
+//   - error: 没有对应的上传时返回 ErrNotFound，超过保留时间时返回 ErrPendingExpired
+func (pending *PendingUploads) ClaimAs(path, dstPath string) (*Object, error) {
+	pendingPath, err := pending.PendingPath(path)
+	if err != nil {
+		return nil, err
+	}
+	object, err := pending.Storage.Stat(pendingPath)
+	if err != nil {
+		return nil, err
+	}
+	if pending.expired(object, Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrPendingExpired, path)
+	}
+	if err := pending.Storage.Move(pendingPath, dstPath); err != nil {
+		return nil, err
+	}
+	return pending.Storage.Stat(dstPath)
+}
+
+// expired 判断待认领的对象是否已超过保留时间，没有修改时间的对象不会过期
+func (pending *PendingUploads) expired(object *Object, now time.Time) bool {
+	return object.LastModified != nil && now.Sub(*object.LastModified) > pending.TTL
+}
+
+// Sweep 删除超过保留时间仍未认领的上传
+// 返回:
+//   - int: 删除的对象数
+//   - error: 列出或删除失败时的错误信息，部分删除失败时返回 *DeleteObjectsError
+func (pending *PendingUploads) Sweep() (int, error) {
+	objects, err := pending.Storage.List(pending.Dir)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+	now := Now()
+	var paths []string
+	for _, object := range objects {
+		if pending.expired(object, now) {
+			paths = append(paths, object.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return 0, nil
+	}
+	err = pending.Storage.DeleteObjects(paths)
+	var deleteErr *DeleteObjectsError
+	if errors.As(err, &deleteErr) {
+		return len(paths) - len(deleteErr.Errors), err
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(paths), nil
+}
+
+// RunSweeper 每隔 interval 调用一次 Sweep，直到 ctx 被取消
+// 参数:
+//   - ctx: 上下文，取消时返回
+//   - interval: 清理间隔，小于等于0时使用 TTL 的十分之一
+//   - onSweep: 每次清理后的回调，可以为nil
+func (pending *PendingUploads) RunSweeper(ctx context.Context, interval time.Duration, onSweep func(deleted int, err error)) {
+	if interval <= 0 {
+		interval = pending.TTL / 10
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := pending.Sweep()
+			if onSweep != nil {
+				onSweep(deleted, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package oss_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestPendingUploads(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	defaultClock := oss.DefaultClock
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+	defer func() { oss.DefaultClock = defaultClock }()
+
+	mock := ossmock.New()
+	pending := oss.NewPendingUploads(mock, time.Hour)
+	if path, err := pending.PendingPath("avatars/1.png"); err != nil || path != "/_pending/avatars/1.png" {
+		t.Errorf("Pending path should be under the pending dir, but got %v, %v", path, err)
+	}
+	if _, err := pending.PendingPath("../etc/passwd"); !errors.Is(err, oss.ErrInvalidPath) {
+		t.Errorf("Path escapes should be rejected, but got %v", err)
+	}
+
+	pending.Put("/avatars/1.png", strings.NewReader("png"))
+	pending.Put("/avatars/2.png", strings.NewReader("png"))
+	object, err := pending.Claim("/avatars/1.png")
+	if err != nil || object.Size != 3 {
+		t.Fatalf("Claim should move the upload, but got %+v, %v", object, err)
+	}
+	if exists, _ := mock.Exists("/_pending/avatars/1.png"); exists {
+		t.Errorf("Claimed upload should leave the pending dir")
+	}
+	if _, err := pending.Claim("/avatars/1.png"); !errors.Is(err, oss.ErrNotFound) {
+		t.Errorf("Claiming twice should return ErrNotFound, but got %v", err)
+	}
+
+	now = now.Add(30 * time.Minute)
+	pending.Put("/avatars/3.png", strings.NewReader("png"))
+	now = now.Add(45 * time.Minute)
+	if _, err := pending.ClaimAs("/avatars/2.png", "/users/2/avatar.png"); !errors.Is(err, oss.ErrPendingExpired) || !errors.Is(err, oss.ErrNotFound) {
+		t.Errorf("Expired upload should not be claimable, but got %v", err)
+	}
+	if deleted, err := pending.Sweep(); err != nil || deleted != 1 {
+		t.Errorf("Sweep should delete expired uploads, but got %v, %v", deleted, err)
+	}
+	if object, err := pending.ClaimAs("/avatars/3.png", "/users/3/avatar.png"); err != nil || object.Path != "/users/3/avatar.png" {
+		t.Errorf("Fresh upload should be claimable to another path, but got %+v, %v", object, err)
+	}
+	if exists, _ := mock.Exists("/avatars/1.png"); !exists {
+		t.Errorf("Sweep should not touch claimed objects")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	swept := make(chan int)
+	go pending.RunSweeper(ctx, time.Millisecond, func(deleted int, err error) {
+		select {
+		case swept <- deleted:
+		default:
+		}
+	})
+	<-swept
+	cancel()
+}
@@ -0,0 +1,159 @@
+package oss
+
+import (
+	"io"
+	"os"
+)
+
+// ProgressFunc 传输进度回调
+// 参数:
+//   - transferred: 已传输的字节数
+//   - total: 总字节数，未知时为-1
+type ProgressFunc func(transferred, total int64)
+
+// ReaderSize 获取读取器剩余内容的长度
+// 支持 bytes.Reader、strings.Reader、bytes.Buffer、os.File 和可寻址的读取器
+// 参数:
+//   - reader: 文件内容读取器
+// 返回:
+//   - int64: 剩余内容的字节数，无法确定时返回-1
+func ReaderSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	case io.Seeker:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - offset
+	}
+	return -1
+}
+
+// NewProgressReader 创建读取时回调传输进度的读取器
+// reader支持Seek时返回的读取器同样支持Seek，重新定位后进度随之调整，便于SDK重试
+// 参数:
+//   - reader: 文件内容读取器
+//   - total: 总字节数，未知时为-1
+//   - progress: 进度回调，为nil时直接返回reader
+// 返回:
+//   - io.Reader: 回调进度的读取器
+func NewProgressReader(reader io.Reader, total int64, progress ProgressFunc) io.Reader {
+	if progress == nil {
+		return reader
+	}
+	counter := &progressReader{Reader: reader, total: total, progress: progress}
+	if seeker, ok := reader.(io.Seeker); ok {
+		return &progressReadSeeker{progressReader: counter, seeker: seeker}
+	}
+	return counter
+}
+
+// NewProgressReadCloser 创建读取时回调传输进度的文件流
+// 参数:
+//   - stream: 文件流
+//   - total: 总字节数，未知时为-1
+//   - progress: 进度回调，为nil时直接返回stream
+// 返回:
+//   - io.ReadCloser: 回调进度的文件流，关闭时关闭stream
+func NewProgressReadCloser(stream io.ReadCloser, total int64, progress ProgressFunc) io.ReadCloser {
+	if progress == nil {
+		return stream
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{NewProgressReader(stream, total, progress), stream}
+}
+
+// WrapReader 在设置了进度回调时包装上传内容的读取器
+// 参数:
+//   - reader: 文件内容读取器
+//   - total: 总字节数，未知时为-1
+// 返回:
+//   - io.Reader: 回调进度的读取器，未设置 Progress 时返回reader
+func (opts PutOptions) WrapReader(reader io.Reader, total int64) io.Reader {
+	return NewProgressReader(reader, total, opts.Progress)
+}
+
+// GetStreamWithProgress 获取文件流并在读取时回调下载进度
+// 总字节数取自 Stat 返回的对象大小
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+//   - progress: 进度回调
+// 返回:
+//   - io.ReadCloser: 回调进度的文件流
+//   - error: 错误信息
+func GetStreamWithProgress(storage StorageInterface, path string, progress ProgressFunc) (io.ReadCloser, error) {
+	total := int64(-1)
+	if object, err := storage.Stat(path); err == nil && object.Size > 0 {
+		total = object.Size
+	}
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewProgressReadCloser(stream, total, progress), nil
+}
+
+// progressReader 读取时累计字节数并回调进度
+type progressReader struct {
+	io.Reader
+	total       int64
+	transferred int64
+	progress    ProgressFunc
+}
+
+// Read 读取内容并回调进度
+func (reader *progressReader) Read(p []byte) (int, error) {
+	n, err := reader.Reader.Read(p)
+	if n > 0 {
+		reader.transferred += int64(n)
+		reader.progress(reader.transferred, reader.total)
+	}
+	return n, err
+}
+
+// progressReadSeeker 支持Seek的进度读取器
+type progressReadSeeker struct {
+	*progressReader
+	seeker io.Seeker
+	start  int64
+	seeked bool
+}
+
+// Seek 重新定位读取位置，已传输字节数调整为相对起始位置的偏移
+func (reader *progressReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	if !reader.seeked {
+		start, err := reader.seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		reader.start, reader.seeked = start-reader.transferred, true
+	}
+	position, err := reader.seeker.Seek(offset, whence)
+	if err != nil {
+		return position, err
+	}
+	reader.transferred = position - reader.start
+	return position, nil
+}
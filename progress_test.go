@@ -0,0 +1,43 @@
+package oss_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestProgress(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+
+	var transferred, total int64
+	progress := func(n, size int64) { transferred, total = n, size }
+	if _, err := fileSystem.PutWithOptions("/a.txt", strings.NewReader("sample"), &oss.PutOptions{Progress: progress}); err != nil {
+		t.Fatalf("No error should happen when put with progress, but got %v", err)
+	}
+	if transferred != 6 || total != 6 {
+		t.Errorf("Upload progress should reach 6/6, but got %v/%v", transferred, total)
+	}
+
+	transferred, total = 0, 0
+	stream, err := oss.GetStreamWithProgress(fileSystem, "/a.txt", progress)
+	if err != nil {
+		t.Fatalf("No error should happen when get stream with progress, but got %v", err)
+	}
+	io.ReadAll(stream)
+	stream.Close()
+	if transferred != 6 || total != 6 {
+		t.Errorf("Download progress should reach 6/6, but got %v/%v", transferred, total)
+	}
+
+	// 重新定位后进度从新的位置开始计算
+	reader := oss.NewProgressReader(strings.NewReader("sample"), -1, progress).(io.ReadSeeker)
+	reader.Read(make([]byte, 4))
+	reader.Seek(1, io.SeekStart)
+	reader.Read(make([]byte, 2))
+	if transferred != 3 || total != -1 {
+		t.Errorf("Progress should follow seek, but got %v/%v", transferred, total)
+	}
+}
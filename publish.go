@@ -0,0 +1,220 @@
+package oss
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	pathpkg "path"
+	"strings"
+	"time"
+)
+
+// ErrNotPublished 数据集尚未发布任何版本
+var ErrNotPublished = errors.New("oss: dataset not published")
+
+const (
+	stagingDir   = ".staging"      // 暂存目录名
+	versionsDir  = "versions"      // 已发布版本目录名
+	manifestName = "MANIFEST.json" // 清单文件名
+)
+
+// Manifest 数据集清单，指向当前对外可见的版本
+type Manifest struct {
+	Version     string    `json:"version"`      // 当前版本
+	Objects     []string  `json:"objects"`      // 版本内的对象名称，按字典序排列
+	PublishedAt time.Time `json:"published_at"` // 发布时间
+}
+
+// Publisher 两阶段发布器
+// 先将新版本上传到暂存前缀，再通过服务端移动提升为正式版本并替换清单
+// 读取方始终通过清单定位版本，因此只会看到完整的旧版本或完整的新版本
+//
+// 目录结构:
+//   - <Root>/.staging/<version>/...: 暂存中的版本
+//   - <Root>/versions/<version>/...: 已提升的版本
+//   - <Root>/MANIFEST.json: 当前版本清单，替换清单即完成发布
+type Publisher struct {
+	Storage StorageInterface // 存储接口
+	Root    string           // 数据集根路径
+}
+
+// NewPublisher 创建两阶段发布器
+// 参数:
+//   - storage: 存储接口
+//   - root: 数据集根路径
+// 返回:
+//   - *Publisher: 发布器实例
+func NewPublisher(storage StorageInterface, root string) *Publisher {
+	return &Publisher{Storage: storage, Root: root}
+}
+
+// Stage 将一个版本的全部对象上传到暂存前缀
+// 任意对象上传失败时回滚已上传的暂存对象
+// 参数:
+//   - version: 版本名称
+//   - readers: 对象名称到文件内容读取器的映射
+// 返回:
+//   - []*Object: 上传后的暂存对象信息
+//   - error: 错误信息
+func (publisher Publisher) Stage(version string, readers map[string]io.Reader) ([]*Object, error) {
+	if err := validateVersion(version); err != nil {
+		return nil, err
+	}
+
+	staged := make(map[string]io.Reader, len(readers))
+	for name, reader := range readers {
+		staged[publisher.stagingPath(version, name)] = reader
+	}
+	return PutAllOrRollback(publisher.Storage, staged)
+}
+
+// Promote 将暂存的版本提升为正式版本并替换清单
+// 对象通过Move移动到版本目录，最后一次性写入清单，清单写入前读取方仍看到旧版本
+// 参数:
+//   - version: 版本名称
+// 返回:
+//   - *Manifest: 新的清单
+//   - error: 错误信息
+func (publisher Publisher) Promote(version string) (*Manifest, error) {
+	if err := validateVersion(version); err != nil {
+		return nil, err
+	}
+
+	prefix := publisher.stagingPath(version, "")
+	objects, err := publisher.Storage.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("oss: version %s has no staged objects", version)
+	}
+
+	manifest := &Manifest{Version: version}
+	for _, object := range objects {
+		name := relativeName(prefix, object.Path)
+		if err := publisher.Storage.Move(publisher.stagingPath(version, name), publisher.VersionPath(version, name)); err != nil {
+			return nil, fmt.Errorf("promote %s failed: %w", name, err)
+		}
+		manifest.Objects = append(manifest.Objects, name)
+	}
+	manifest.PublishedAt = Now().UTC()
+
+	// 写入清单，完成版本切换
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := publisher.Storage.PutWithOptions(publisher.manifestPath(), bytes.NewReader(data), &PutOptions{
+		ContentType:  "application/json",
+		CacheControl: "no-cache",
+	}); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Abort 删除暂存中的版本
+// 参数:
+//   - version: 版本名称
+// 返回:
+//   - error: 错误信息
+func (publisher Publisher) Abort(version string) error {
+	if err := validateVersion(version); err != nil {
+		return err
+	}
+
+	objects, err := publisher.Storage.List(publisher.stagingPath(version, ""))
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err := publisher.Storage.Delete(object.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Current 读取当前的清单
+// 返回:
+//   - *Manifest: 当前清单
+//   - error: 错误信息，未发布时返回ErrNotPublished
+func (publisher Publisher) Current() (*Manifest, error) {
+	exists, err := publisher.Storage.Exists(publisher.manifestPath())
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotPublished
+	}
+
+	readCloser, err := publisher.Storage.GetStream(publisher.manifestPath())
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	data, err := ioutil.ReadAll(readCloser)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Resolve 获取对象在当前版本中的路径
+// 参数:
+//   - name: 对象名称
+// 返回:
+//   - string: 对象路径
+//   - error: 错误信息
+func (publisher Publisher) Resolve(name string) (string, error) {
+	manifest, err := publisher.Current()
+	if err != nil {
+		return "", err
+	}
+	return publisher.VersionPath(manifest.Version, name), nil
+}
+
+// VersionPath 获取对象在指定版本中的路径
+// 参数:
+//   - version: 版本名称
+//   - name: 对象名称
+// 返回:
+//   - string: 对象路径
+func (publisher Publisher) VersionPath(version, name string) string {
+	return pathpkg.Join("/", publisher.Root, versionsDir, version, name)
+}
+
+// stagingPath 获取对象在暂存前缀中的路径
+func (publisher Publisher) stagingPath(version, name string) string {
+	return pathpkg.Join("/", publisher.Root, stagingDir, version, name)
+}
+
+// manifestPath 获取清单路径
+func (publisher Publisher) manifestPath() string {
+	return pathpkg.Join("/", publisher.Root, manifestName)
+}
+
+// validateVersion 校验版本名称，避免路径穿越到其他版本
+func validateVersion(version string) error {
+	if version == "" || version == "." || version == ".." || strings.Contains(version, "/") {
+		return fmt.Errorf("oss: invalid version %q", version)
+	}
+	return nil
+}
+
+// relativeName 获取对象相对于前缀的名称
+// 不同存储后端List返回的路径可能带或不带前导斜杠
+func relativeName(prefix, objectPath string) string {
+	prefix = strings.TrimPrefix(prefix, "/")
+	objectPath = strings.TrimPrefix(objectPath, "/")
+	return strings.TrimPrefix(strings.TrimPrefix(objectPath, prefix), "/")
+}
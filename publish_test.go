@@ -0,0 +1,69 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestPublisher(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	publisher := oss.NewPublisher(fileSystem, "/dataset")
+
+	if _, err := publisher.Current(); !errors.Is(err, oss.ErrNotPublished) {
+		t.Errorf("Should return ErrNotPublished before first publish, but got %v", err)
+	}
+
+	for _, version := range []string{"v1", "v2"} {
+		if _, err := publisher.Stage(version, map[string]io.Reader{
+			"a.txt":     strings.NewReader(version),
+			"dir/b.txt": strings.NewReader(version),
+		}); err != nil {
+			t.Fatalf("No error should happen when stage %v, but got %v", version, err)
+		}
+
+		// 提升前读取方仍然看到旧版本
+		if manifest, err := publisher.Current(); err == nil && manifest.Version == version {
+			t.Errorf("Staged version %v should not be visible before promote", version)
+		}
+
+		manifest, err := publisher.Promote(version)
+		if err != nil {
+			t.Fatalf("No error should happen when promote %v, but got %v", version, err)
+		}
+		if strings.Join(manifest.Objects, ",") != "a.txt,dir/b.txt" {
+			t.Errorf("Manifest should contain staged objects, but got %v", manifest.Objects)
+		}
+	}
+
+	path, err := publisher.Resolve("dir/b.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when resolve object, but got %v", err)
+	}
+	if file, err := fileSystem.Get(path); err != nil {
+		t.Errorf("No error should happen when get resolved object, but got %v", err)
+	} else {
+		defer file.Close()
+		if buffer, _ := io.ReadAll(file); string(buffer) != "v2" {
+			t.Errorf("Resolved object should come from latest version, but got %v", string(buffer))
+		}
+	}
+
+	if _, err := publisher.Stage("../v3", nil); err == nil {
+		t.Errorf("Should return error when stage invalid version")
+	}
+
+	if _, err := publisher.Stage("v3", map[string]io.Reader{"a.txt": strings.NewReader("v3")}); err != nil {
+		t.Fatalf("No error should happen when stage v3, but got %v", err)
+	}
+	if err := publisher.Abort("v3"); err != nil {
+		t.Errorf("No error should happen when abort v3, but got %v", err)
+	}
+	if _, err := publisher.Promote("v3"); err == nil {
+		t.Errorf("Should return error when promote aborted version")
+	}
+}
@@ -0,0 +1,155 @@
+package qiniu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"path/filepath"
+
+	"github.com/qiniu/go-sdk/v7/storage"
+	"github.com/smart-unicom/oss"
+)
+
+// InitiateMultipart 使用分片上传v2初始化断点续传
+// 参数:
+//   - urlPath: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值，内容类型和元数据在完成上传时应用
+//
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(urlPath string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// Qiniu不支持对象级ACL
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: qiniu does not support per-object ACL", oss.ErrNotSupported)
+	}
+
+	key := storageKey(urlPath)
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(key, maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	uploader, upHost, err := client.resumeUploader()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	ret := storage.InitPartsRet{}
+	if err := uploader.InitParts(context.Background(), client.uploadToken(key), upHost, client.Config.Bucket, key, true, &ret); err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.MultipartUpload{Path: "/" + key, UploadID: ret.UploadID, Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+//
+// 返回:
+//   - *oss.Part: 已上传的分片
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	uploader, upHost, err := client.resumeUploader()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	key := storageKey(upload.Path)
+	ret := storage.UploadPartsRet{}
+	err = uploader.UploadParts(context.Background(), client.uploadToken(key), upHost, client.Config.Bucket, key, true,
+		upload.UploadID, int64(number), "", &ret, reader, int(size))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: number, ETag: ret.Etag, Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号合并分片，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+//
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	uploader, upHost, err := client.resumeUploader()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	key := storageKey(upload.Path)
+	fileType := upload.Options.ContentType
+	if fileType == "" {
+		fileType = mime.TypeByExtension(path.Ext(key))
+	}
+	extra := &storage.RputV2Extra{
+		MimeType: fileType,
+		Metadata: map[string]string{},
+	}
+	for name, value := range upload.Options.Metadata {
+		extra.Metadata["x-qn-meta-"+name] = value
+	}
+	oss.SortParts(parts)
+	for _, part := range parts {
+		extra.Progresses = append(extra.Progresses, storage.UploadPartInfo{PartNumber: int64(part.Number), Etag: part.ETag})
+	}
+
+	ret := storage.PutRet{}
+	err = uploader.CompleteParts(context.Background(), client.uploadToken(key), upHost, &ret, client.Config.Bucket, key, true, upload.UploadID, extra)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(key); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: "/" + key, Name: filepath.Base(key), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传
+// 分片上传v2没有中止接口，未完成的分片由服务端在上传ID过期后自动清理，这里不发送请求
+// 参数:
+//   - upload: 分片上传会话
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	return nil
+}
+
+// resumeUploader 创建分片上传器并获取上传域名
+func (client Client) resumeUploader() (*storage.ResumeUploaderV2, string, error) {
+	uploader := storage.NewResumeUploaderV2Ex(&client.storageCfg, client.sdkClient())
+	upHost, err := uploader.UpHost(client.Config.AccessId, client.Config.Bucket)
+	return uploader, upHost, err
+}
+
+// uploadToken 生成指定对象的上传凭证，客户端设置了自定义上传策略时使用自定义策略
+func (client Client) uploadToken(key string) string {
+	putPolicy := storage.PutPolicy{
+		Scope: fmt.Sprintf("%s:%s", client.Config.Bucket, key),
+	}
+	if client.putPolicy != nil {
+		putPolicy = *client.putPolicy
+	}
+	return putPolicy.UploadToken(client.mac)
+}
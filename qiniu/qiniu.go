@@ -5,6 +5,7 @@ package qiniu
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	"time"
 
 	"github.com/qiniu/go-sdk/v7/auth/qbox"
+	qiniuclient "github.com/qiniu/go-sdk/v7/client"
 	"github.com/qiniu/go-sdk/v7/storage"
 	"github.com/smart-unicom/oss"
 )
@@ -57,6 +59,12 @@ type Config struct {
 	UseCdnDomains bool
 	// PrivateURL 是否为私有URL
 	PrivateURL bool
+	// Redirect 下载时跟随跳转的策略，例如源站跳转到CDN节点
+	Redirect oss.RedirectPolicy
+	// HTTPClient 上传、管理和下载使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用 oss.DefaultHTTPClient
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，为nil时使用默认配置，只重试幂等且内容可以重新读取的请求
+	Retry *oss.RetryConfig
 }
 
 // zonedata 七牛云存储区域映射表
@@ -104,11 +112,29 @@ func New(config *Config) (*Client, error) {
 	client.storageCfg.UseCdnDomains = config.UseCdnDomains
 
 	// 初始化存储桶管理器
-	client.bucketManager = storage.NewBucketManager(client.mac, &client.storageCfg)
+	client.bucketManager = storage.NewBucketManagerEx(client.mac, &client.storageCfg, client.sdkClient())
 
 	return client, nil
 }
 
+// httpClient 返回按重试配置重试的HTTP客户端，未配置时使用带超时的 oss.DefaultHTTPClient
+// 返回:
+//   - *http.Client: HTTP客户端
+func (client Client) httpClient() *http.Client {
+	httpClient := client.Config.HTTPClient
+	if httpClient == nil {
+		httpClient = oss.DefaultHTTPClient
+	}
+	return client.Config.Retry.Client(httpClient)
+}
+
+// sdkClient 返回七牛SDK使用的HTTP客户端
+// 返回:
+//   - *qiniuclient.Client: SDK客户端
+func (client Client) sdkClient() *qiniuclient.Client {
+	return &qiniuclient.Client{Client: client.httpClient()}
+}
+
 // SetPutPolicy 设置上传策略
 // 参数:
 //   - putPolicy: 七牛云上传策略
@@ -126,20 +152,15 @@ func (client Client) SetPutPolicy(putPolicy *storage.PutPolicy) {
 func (client Client) Get(path string) (file *os.File, err error) {
 	// 获取文件流
 	readCloser, err := client.GetStream(path)
-
-	// 创建临时文件并复制内容
-	if file, err = ioutil.TempFile(os.TempDir(), "qiniu"); err == nil {
-		defer readCloser.Close()
-		// 将流内容复制到临时文件
-		_, err = io.Copy(file, readCloser)
-		// 重置文件指针到开始位置
-		file.Seek(0, 0)
+	if err != nil {
+		return nil, err
 	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "qiniu")
 }
 
 // GetStream 获取指定路径文件的流
+// 按 Config.Redirect 跟随跳转，返回的 *oss.DownloadStream 记录最终的下载地址
 // 参数:
 //   - path: 文件路径
 //
@@ -153,14 +174,153 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	// 发送HTTP GET请求获取文件
-	var res *http.Response
-	res, err = http.Get(purl)
-	if err == nil && res.StatusCode != http.StatusOK {
-		err = fmt.Errorf("file %s not found", path)
+	// 发送HTTP GET请求获取文件，按 Config.Redirect 跟随跳转
+	res, err := client.Config.Redirect.Client(client.httpClient()).Get(purl)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		if location := res.Header.Get("Location"); location != "" {
+			return nil, fmt.Errorf("download %s redirected to %s with redirects disabled", path, location)
+		}
+		return nil, oss.WrapError(oss.ErrObjectNotFound, fmt.Errorf("file %s not found", path))
+	}
+
+	return oss.NewDownloadStream(res), nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用HTTP Range请求只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+//
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	purl, err := client.GetURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodGet, purl, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Range", oss.HTTPRange(offset, length))
+
+	res, err := client.Config.Redirect.Client(client.httpClient()).Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+		return oss.NewDownloadStream(res), nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: range %s of %s", oss.ErrInvalidRange, oss.HTTPRange(offset, length), path)
+	}
+	res.Body.Close()
+	return nil, oss.WrapError(oss.ErrObjectNotFound, fmt.Errorf("file %s not found", path))
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 获取文件信息
+	key := storageKey(path)
+	fileInfo, err := client.bucketManager.Stat(client.Config.Bucket, key)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	// PutTime 单位为100纳秒
+	return &oss.Object{
+		Path:             "/" + key,
+		Name:             filepath.Base(key),
+		LastModified:     oss.NormalizeTime(time.Unix(0, fileInfo.PutTime*100)),
+		Size:             fileInfo.Fsize,
+		ContentType:      fileInfo.MimeType,
+		ETag:             fileInfo.Hash,
+		Metadata:         oss.NormalizeMetadata(fileInfo.MetaData),
+		StorageInterface: client,
+	}, nil
+}
+
+// StatMany 使用批量操作接口获取多个对象的信息，每次请求最多 oss.MaxDeleteObjects 个对象
+// 批量接口不返回自定义元信息，需要元信息时使用 Stat
+// 参数:
+//   - paths: 文件路径列表
+//
+// 返回:
+//   - map[string]*oss.Object: 以请求的路径为键的对象信息，不存在的对象不包含在内
+//   - error: 错误信息，包含每个失败对象的原因
+func (client Client) StatMany(paths []string) (map[string]*oss.Object, error) {
+	objects := make(map[string]*oss.Object, len(paths))
+	var errs []error
+	for start := 0; start < len(paths); start += oss.MaxDeleteObjects {
+		end := min(start+oss.MaxDeleteObjects, len(paths))
+
+		var operations []string
+		for _, path := range paths[start:end] {
+			operations = append(operations, storage.URIStat(client.Config.Bucket, storageKey(path)))
+		}
+
+		rets, err := client.bucketManager.Batch(operations)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("stat %d objects: %w", end-start, wrapError(err)))
+			continue
+		}
+
+		// 批量操作结果与请求顺序一致，612表示文件不存在
+		for i, ret := range rets {
+			path, key := paths[start+i], storageKey(paths[start+i])
+			switch ret.Code {
+			case http.StatusOK:
+				objects[path] = &oss.Object{
+					Path:             "/" + key,
+					Name:             filepath.Base(key),
+					LastModified:     oss.NormalizeTime(time.Unix(0, ret.Data.PutTime*100)),
+					Size:             ret.Data.Fsize,
+					ContentType:      ret.Data.MimeType,
+					ETag:             ret.Data.Hash,
+					StorageInterface: client,
+				}
+			case 612:
+			default:
+				errs = append(errs, fmt.Errorf("%s: %w", path, wrapError(&storage.ErrorInfo{Err: ret.Data.Error, Key: key, Code: ret.Code})))
+			}
+		}
 	}
+	return objects, errors.Join(errs...)
+}
 
-	return res.Body, err
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	_, err := client.Stat(path)
+	if errors.Is(err, oss.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // Put 上传文件到指定路径
@@ -172,13 +332,61 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传成功后的对象信息
 //   - error: 错误信息
 func (client Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err error) {
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 文件路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+//
+// 返回:
+//   - *oss.Object: 上传成功后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (r *oss.Object, err error) {
+	// 规范化来自Windows的路径
+	if urlPath, err = oss.NormalizePath(urlPath); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	// Qiniu不支持对象级ACL
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: qiniu does not support per-object ACL", oss.ErrNotSupported)
+	}
+	// Qiniu不支持对象标签
+	if len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("%w: qiniu does not support object tags", oss.ErrNotSupported)
+	}
+	// 表单上传不支持发送校验和
+	if opts.Checksum != "" {
+		return nil, fmt.Errorf("%w: qiniu does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+	}
+
 	// 如果reader支持Seek，重置到开始位置
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
+	// 按冲突策略确定上传路径，CollisionError使用仅新增的上传策略
+	if opts.Collision != oss.CollisionError {
+		if urlPath, err = opts.ResolvePath(client, urlPath); err != nil {
+			return
+		}
+	}
+
 	// 处理存储键
 	urlPath = storageKey(urlPath)
+	// 在发送请求前校验对象键长度
+	if err = oss.ValidateKeyLength(urlPath, maxKeyBytes, 0); err != nil {
+		return
+	}
 	var buffer []byte
 	buffer, err = ioutil.ReadAll(reader)
 	if err != nil {
@@ -186,7 +394,10 @@ func (client Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err e
 	}
 
 	// 检测文件类型
-	fileType := mime.TypeByExtension(path.Ext(urlPath))
+	fileType := opts.ContentType
+	if fileType == "" {
+		fileType = mime.TypeByExtension(path.Ext(urlPath))
+	}
 	if fileType == "" {
 		fileType = http.DetectContentType(buffer)
 	}
@@ -200,33 +411,58 @@ func (client Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err e
 	if client.putPolicy != nil {
 		putPolicy = *client.putPolicy
 	}
+	if opts.Collision == oss.CollisionError {
+		putPolicy.InsertOnly = 1
+	}
 
 	// 生成上传凭证
 	upToken := putPolicy.UploadToken(client.mac)
 
 	// 创建表单上传器
-	formUploader := storage.NewFormUploader(&client.storageCfg)
+	formUploader := storage.NewFormUploaderEx(&client.storageCfg, client.sdkClient())
 	ret := storage.PutRet{}
 	dataLen := int64(len(buffer))
 
 	// 设置上传参数
 	putExtra := storage.PutExtra{
-		Params: map[string]string{},
+		Params:   map[string]string{},
+		MimeType: fileType,
+	}
+	for key, value := range opts.Metadata {
+		putExtra.Params["x-qn-meta-"+key] = value
 	}
 	// 执行文件上传
-	err = formUploader.Put(context.Background(), &ret, upToken, urlPath, bytes.NewReader(buffer), dataLen, &putExtra)
+	err = formUploader.Put(context.Background(), &ret, upToken, urlPath, opts.WrapReader(bytes.NewReader(buffer), dataLen), dataLen, &putExtra)
 	if err != nil {
+		err = wrapError(err)
 		return
 	}
 
 	// 创建返回对象
-	now := time.Now()
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             ret.Key,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, err
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if stat, err := client.Stat(urlPath); err == nil {
+		object.LastModified = stat.LastModified
+		object.Size = stat.Size
+	}
+
+	return object, err
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 数据通过管道交给 Put 上传
+// 参数:
+//   - path: 目标路径
+//
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
 }
 
 // Delete 删除指定路径的文件
@@ -236,7 +472,121 @@ func (client Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err e
 // 返回:
 //   - error: 错误信息
 func (client Client) Delete(path string) error {
-	return client.bucketManager.Delete(client.Config.Bucket, storageKey(path))
+	return wrapError(client.bucketManager.Delete(client.Config.Bucket, storageKey(path)))
+}
+
+// DeleteObjects 批量删除多个文件
+// 每次请求最多删除 oss.MaxDeleteObjects 个文件，多批请求并发执行
+// 参数:
+//   - paths: 文件路径列表
+//
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsInChunks(paths, oss.DefaultDeleteChunkConcurrency, func(chunk []string) map[string]error {
+		failures := map[string]error{}
+		var operations []string
+		for _, path := range chunk {
+			operations = append(operations, storage.URIDelete(client.Config.Bucket, storageKey(path)))
+		}
+
+		rets, err := client.bucketManager.Batch(operations)
+		if err != nil {
+			for _, path := range chunk {
+				failures[path] = wrapError(err)
+			}
+			return failures
+		}
+
+		// 批量操作结果与请求顺序一致，612表示文件不存在，视为删除成功
+		for i, ret := range rets {
+			if ret.Code == http.StatusOK || ret.Code == 612 {
+				continue
+			}
+			path := chunk[i]
+			failures[path] = wrapError(&storage.ErrorInfo{Err: ret.Data.Error, Key: storageKey(path), Code: ret.Code})
+		}
+		return failures
+	})
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分页列举目录下的对象，每页使用批量删除接口删除
+// 参数:
+//   - dir: 目录路径
+//
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(storageKey(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	marker := ""
+	for {
+		listItems, _, nextMarker, hasNext, err := client.bucketManager.ListFiles(client.Config.Bucket, prefix, "", marker, oss.MaxDeleteObjects)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		var paths []string
+		for _, item := range listItems {
+			paths = append(paths, item.Key)
+		}
+		if len(paths) > 0 {
+			if err := client.DeleteObjects(paths); err != nil {
+				return err
+			}
+		}
+
+		if !hasNext {
+			return nil
+		}
+		marker = nextMarker
+	}
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := oss.ValidateKeyLength(storageKey(dstPath), maxKeyBytes, 0); err != nil {
+		return err
+	}
+
+	// 使用服务端复制，目标已存在时覆盖
+	return wrapError(client.bucketManager.Copy(client.Config.Bucket, storageKey(srcPath), client.Config.Bucket, storageKey(dstPath), true))
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := oss.ValidateKeyLength(storageKey(dstPath), maxKeyBytes, 0); err != nil {
+		return err
+	}
+
+	// 使用服务端移动，目标已存在时覆盖
+	return wrapError(client.bucketManager.Move(client.Config.Bucket, storageKey(srcPath), client.Config.Bucket, storageKey(dstPath), true))
 }
 
 // List 列出指定路径下的所有对象
@@ -260,16 +610,20 @@ func (client Client) List(path string) (objects []*oss.Object, err error) {
 	)
 
 	if err != nil {
+		err = wrapError(err)
 		return
 	}
 
 	// 转换为oss.Object格式
 	for _, content := range listItems {
-		t := time.Unix(content.PutTime, 0)
+		// PutTime 单位为100纳秒
 		objects = append(objects, &oss.Object{
 			Path:             "/" + storageKey(content.Key),
 			Name:             filepath.Base(content.Key),
-			LastModified:     &t,
+			LastModified:     oss.NormalizeTime(time.Unix(0, content.PutTime*100)),
+			Size:             content.Fsize,
+			ContentType:      content.MimeType,
+			ETag:             content.Hash,
 			StorageInterface: client,
 		})
 	}
@@ -277,6 +631,21 @@ func (client Client) List(path string) (objects []*oss.Object, err error) {
 	return
 }
 
+// BucketManager 返回七牛云SDK的存储桶管理器，用于统一接口没有覆盖的功能，例如镜像回源和空间管理
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - *storage.BucketManager: 存储桶管理器
+func (client Client) BucketManager() *storage.BucketManager {
+	return client.bucketManager
+}
+
+// Underlying 返回七牛云SDK的存储桶管理器，与 BucketManager 相同
+// 返回:
+//   - interface{}: *storage.BucketManager
+func (client Client) Underlying() interface{} {
+	return client.bucketManager
+}
+
 // GetEndpoint 获取存储端点
 // 返回:
 //   - string: 存储端点URL
@@ -284,6 +653,9 @@ func (client Client) GetEndpoint() string {
 	return client.Config.Endpoint
 }
 
+// maxKeyBytes 七牛云对象键的最大字节数
+const maxKeyBytes = 750
+
 var urlRegexp = regexp.MustCompile(`(https?:)?//((\w+).)+(\w+)/`)
 
 // storageKey 处理存储键，去除URL前缀并标准化路径
@@ -316,9 +688,7 @@ func (client Client) GetURL(path string) (url string, err error) {
 
 	// 如果配置为私有URL，生成带签名的私有访问URL
 	if client.Config.PrivateURL {
-		deadline := time.Now().Add(time.Second * 3600).Unix()
-		url = storage.MakePrivateURL(client.mac, client.Config.Endpoint, key, deadline)
-		return
+		return client.GetSignedURL(path, oss.SignedURLOptions{})
 	}
 
 	// 生成公共访问URL
@@ -326,3 +696,124 @@ func (client Client) GetURL(path string) (url string, err error) {
 
 	return
 }
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+//
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// 七牛云通过上传凭证而不是预签名URL上传文件
+	if opts.Method == http.MethodPut {
+		return "", fmt.Errorf("%w: qiniu does not support presigned upload URL", oss.ErrNotSupported)
+	}
+
+	deadline := oss.Now().Add(opts.Expiry).Unix()
+	return storage.MakePrivateURL(client.mac, client.Config.Endpoint, storageKey(path), deadline), nil
+}
+
+// GetUploadURL 生成表单直传地址和上传凭证
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+//
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+	key := storageKey(path)
+	expiresAt := oss.Now().Add(opts.Expiry)
+
+	// 生成只允许上传指定键的上传凭证
+	putPolicy := storage.PutPolicy{
+		Scope:   fmt.Sprintf("%s:%s", client.Config.Bucket, key),
+		Expires: uint64(expiresAt.Unix()),
+	}
+	if opts.ContentType != "" {
+		putPolicy.MimeLimit = opts.ContentType
+	}
+
+	// 获取存储区域的上传域名
+	region := client.storageCfg.GetRegion()
+	if region == nil || len(region.SrcUpHosts) == 0 {
+		return nil, fmt.Errorf("upload host of region %s is not available", client.Config.Region)
+	}
+	scheme := "http://"
+	if client.storageCfg.UseHTTPS {
+		scheme = "https://"
+	}
+
+	return &oss.UploadURL{
+		URL:     scheme + region.SrcUpHosts[0],
+		Method:  http.MethodPost,
+		Headers: map[string]string{},
+		FormFields: map[string]string{
+			"token": putPolicy.UploadToken(client.mac),
+			"key":   key,
+		},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func init() {
+	oss.RegisterHTTPStatusMapper(httpStatus)
+}
+
+// httpStatus 将七牛云的错误转换为HTTP状态码
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码
+//   - bool: 是否识别该错误
+func httpStatus(err error) (int, bool) {
+	var errorInfo *storage.ErrorInfo
+	if !errors.As(err, &errorInfo) {
+		return 0, false
+	}
+
+	// 七牛云使用6xx状态码表示业务错误
+	switch errorInfo.HttpCode() {
+	case 612, 631:
+		return http.StatusNotFound, true
+	case 614:
+		return http.StatusConflict, true
+	case 573:
+		return http.StatusTooManyRequests, true
+	}
+	return errorInfo.HttpCode(), true
+}
+
+// wrapError 将七牛云的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+//
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var errorInfo *storage.ErrorInfo
+	if !errors.As(err, &errorInfo) {
+		return err
+	}
+
+	// 七牛云使用612表示文件不存在，614表示文件已存在，631表示空间不存在
+	switch errorInfo.HttpCode() {
+	case 612:
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case 614:
+		return oss.WrapError(oss.ErrConflict, err)
+	case 631:
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	}
+	return err
+}
@@ -0,0 +1,556 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ErrQuotaExceeded 上传会超出前缀的配额
+var ErrQuotaExceeded = errors.New("oss: quota exceeded")
+
+// Usage 前缀下对象占用的字节数和对象数
+type Usage struct {
+	// Bytes 对象的总字节数
+	Bytes int64 `json:"bytes"`
+	// Objects 对象数
+	Objects int64 `json:"objects"`
+}
+
+// Quota 前缀的配额
+type Quota struct {
+	// MaxBytes 最多占用的字节数，小于等于0时不限制
+	MaxBytes int64
+	// MaxObjects 最多的对象数，小于等于0时不限制
+	MaxObjects int64
+}
+
+// exceeded 判断用量是否超出配额
+func (quota Quota) exceeded(usage Usage) bool {
+	return quota.MaxBytes > 0 && usage.Bytes > quota.MaxBytes || quota.MaxObjects > 0 && usage.Objects > quota.MaxObjects
+}
+
+// QuotaExceededError 上传会超出前缀配额的错误
+// 可以用 errors.Is(err, ErrQuotaExceeded) 判断
+type QuotaExceededError struct {
+	// Prefix 超出配额的前缀
+	Prefix string
+	// Quota 前缀的配额
+	Quota Quota
+	// Usage 上传后的用量
+	Usage Usage
+}
+
+// Error 返回错误描述
+func (err *QuotaExceededError) Error() string {
+	return fmt.Sprintf("oss: quota of %s exceeded: %d/%d bytes, %d/%d objects", err.Prefix, err.Usage.Bytes, err.Quota.MaxBytes, err.Usage.Objects, err.Quota.MaxObjects)
+}
+
+// Is 使错误可以与 ErrQuotaExceeded 比较
+func (err *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// HTTPStatus 超出配额对应 507 Insufficient Storage
+func (err *QuotaExceededError) HTTPStatus() int {
+	return 507
+}
+
+// UsageStore 保存各前缀用量计数的存储
+// 多个进程共享配额时应使用Redis、数据库等外部存储实现，AddUsage 需要是原子操作
+type UsageStore interface {
+	// Usage 返回前缀的用量，没有记录时返回零值
+	Usage(prefix string) (Usage, error)
+	// AddUsage 原子地增加前缀的用量，delta 可以为负数
+	// 返回:
+	//   - Usage: 增加后的用量
+	//   - error: 错误信息
+	AddUsage(prefix string, delta Usage) (Usage, error)
+	// SetUsage 设置前缀的用量，用于重新统计
+	SetUsage(prefix string, usage Usage) error
+}
+
+// MemoryUsageStore 保存在内存中的用量计数，进程重启后需要用 QuotaStorage.Recalculate 重新统计
+type MemoryUsageStore struct {
+	mu     sync.Mutex
+	usages map[string]Usage
+}
+
+// Usage 返回前缀的用量
+func (store *MemoryUsageStore) Usage(prefix string) (Usage, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.usages[prefix], nil
+}
+
+// AddUsage 增加前缀的用量
+func (store *MemoryUsageStore) AddUsage(prefix string, delta Usage) (Usage, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.usages == nil {
+		store.usages = map[string]Usage{}
+	}
+	usage := store.usages[prefix]
+	usage.Bytes += delta.Bytes
+	usage.Objects += delta.Objects
+	store.usages[prefix] = usage
+	return usage, nil
+}
+
+// SetUsage 设置前缀的用量
+func (store *MemoryUsageStore) SetUsage(prefix string, usage Usage) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.usages == nil {
+		store.usages = map[string]Usage{}
+	}
+	store.usages[prefix] = usage
+	return nil
+}
+
+// QuotaOptions 配额配置
+type QuotaOptions struct {
+	// Depth 按路径的前几级目录统计用量，例如为2时 /tenants/a/x.txt 计入 /tenants/a/，小于等于0时为1
+	Depth int
+	// Quotas 各前缀的配额，前缀以斜杠开头和结尾，例如 /tenants/a/
+	Quotas map[string]Quota
+	// Default 没有在 Quotas 中配置的前缀的配额，零值表示不限制
+	Default Quota
+	// Store 用量计数的存储，为nil时使用 MemoryUsageStore
+	Store UsageStore
+}
+
+// QuotaStorage 按前缀统计用量并在超出配额时拒绝上传的存储包装器
+// 用量由包装器在上传、删除、复制和移动成功后增量维护，不需要定期列出全部对象；
+// 覆盖已有对象时先查询旧对象的大小。绕过包装器写入的对象需要用 Recalculate 重新统计。
+// 配额检查与上传不是一个原子操作，并发上传同一个前缀时可能短暂超出配额
+type QuotaStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Options 配额配置
+	Options QuotaOptions
+}
+
+// WithQuota 创建按前缀限制用量的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 配额配置
+// 返回:
+//   - *QuotaStorage: 存储包装器实例
+func WithQuota(storage StorageInterface, opts QuotaOptions) *QuotaStorage {
+	if opts.Depth <= 0 {
+		opts.Depth = 1
+	}
+	if opts.Store == nil {
+		opts.Store = &MemoryUsageStore{}
+	}
+	return &QuotaStorage{StorageInterface: storage, Options: opts}
+}
+
+// Prefix 返回路径计入的前缀
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - string: 以斜杠开头和结尾的前缀，对象直接位于根目录时为 /
+func (storage *QuotaStorage) Prefix(path string) string {
+	segments := strings.Split(strings.Trim(strings.ReplaceAll(path, `\`, "/"), "/"), "/")
+	segments = segments[:len(segments)-1]
+	if len(segments) > storage.Options.Depth {
+		segments = segments[:storage.Options.Depth]
+	}
+	if len(segments) == 0 {
+		return "/"
+	}
+	return "/" + strings.Join(segments, "/") + "/"
+}
+
+// Quota 返回前缀的配额
+// 参数:
+//   - prefix: 以斜杠开头和结尾的前缀
+// 返回:
+//   - Quota: 配置的配额，没有配置时为 Options.Default
+func (storage *QuotaStorage) Quota(prefix string) Quota {
+	if quota, ok := storage.Options.Quotas[prefix]; ok {
+		return quota
+	}
+	return storage.Options.Default
+}
+
+// Usage 返回前缀的用量
+// 参数:
+//   - prefix: 以斜杠开头和结尾的前缀，例如 Prefix 的返回值
+// 返回:
+//   - Usage: 用量
+//   - error: 错误信息
+func (storage *QuotaStorage) Usage(prefix string) (Usage, error) {
+	return storage.Options.Store.Usage(prefix)
+}
+
+// Recalculate 列出前缀下的全部对象重新统计用量
+// 用于首次启用配额或绕过包装器写入对象之后，只统计按 Depth 计入该前缀的对象
+// 参数:
+//   - prefix: 以斜杠开头和结尾的前缀
+// 返回:
+//   - Usage: 重新统计的用量
+//   - error: 错误信息
+func (storage *QuotaStorage) Recalculate(prefix string) (Usage, error) {
+	objects, err := storage.StorageInterface.List(prefix)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Usage{}, err
+	}
+	var usage Usage
+	for _, object := range objects {
+		if storage.Prefix(object.Path) == prefix {
+			usage.Bytes += object.Size
+			usage.Objects++
+		}
+	}
+	return usage, storage.Options.Store.SetUsage(prefix, usage)
+}
+
+// existing 返回已有对象的用量，对象不存在时为零值
+func (storage *QuotaStorage) existing(path string) (Usage, error) {
+	object, err := storage.StorageInterface.Stat(path)
+	if errors.Is(err, ErrNotFound) {
+		return Usage{}, nil
+	}
+	if err != nil {
+		return Usage{}, err
+	}
+	return Usage{Bytes: object.Size, Objects: 1}, nil
+}
+
+// reserve 预先计入写入后的用量变化，超出配额时撤销并返回 *QuotaExceededError
+// 返回:
+//   - func(): 写入失败时撤销预先计入的用量
+//   - error: 错误信息
+func (storage *QuotaStorage) reserve(prefix string, delta Usage) (func(), error) {
+	usage, err := storage.Options.Store.AddUsage(prefix, delta)
+	if err != nil {
+		return nil, err
+	}
+	undo := func() {
+		storage.Options.Store.AddUsage(prefix, Usage{Bytes: -delta.Bytes, Objects: -delta.Objects})
+	}
+	// 只拒绝增加用量的写入，已经超出配额时仍然允许缩小对象
+	if quota := storage.Quota(prefix); (delta.Bytes > 0 || delta.Objects > 0) && quota.exceeded(usage) {
+		undo()
+		return nil, &QuotaExceededError{Prefix: prefix, Quota: quota, Usage: usage}
+	}
+	return undo, nil
+}
+
+// Put 上传文件，超出配额时返回 *QuotaExceededError
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *QuotaStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return storage.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件，超出配额时返回 *QuotaExceededError
+// 内容大小可以确定时在上传前检查配额；大小未知时边上传边计数，超出剩余配额后中止读取，上传失败
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *QuotaStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	prefix := storage.Prefix(path)
+	// 不覆盖的冲突策略总是写入新对象：CollisionError 在对象已存在时失败，CollisionRename 写入同一目录下的新路径，
+	// 两者都按新对象预先计入用量，上传成功后按返回的实际路径计入
+	var old Usage
+	if opts == nil || opts.Collision == "" || opts.Collision == CollisionOverwrite {
+		var err error
+		if old, err = storage.existing(path); err != nil {
+			return nil, err
+		}
+	}
+
+	size := ReaderSize(reader)
+	delta := Usage{Bytes: size - old.Bytes, Objects: 1 - old.Objects}
+	if size < 0 {
+		delta.Bytes = 0
+	}
+	undo, err := storage.reserve(prefix, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	var limit *quotaLimit
+	if size < 0 {
+		limit = storage.limit(prefix, old.Bytes)
+		reader = &quotaReader{Reader: reader, quotaLimit: limit}
+	}
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	if err != nil {
+		undo()
+		if limit != nil && limit.err != nil {
+			return nil, limit.err
+		}
+		return nil, err
+	}
+
+	// 按实际上传的路径和大小修正预先计入的用量
+	if actual := storage.Prefix(objectPath(object, path)); actual != prefix {
+		undo()
+		storage.Options.Store.AddUsage(actual, Usage{Bytes: object.Size, Objects: 1})
+	} else if correction := object.Size - old.Bytes - delta.Bytes; correction != 0 {
+		storage.Options.Store.AddUsage(prefix, Usage{Bytes: correction})
+	}
+	return object, nil
+}
+
+// limit 返回按前缀剩余字节配额计数的限制
+// 参数:
+//   - prefix: 前缀
+//   - replaced: 被覆盖的旧对象大小，写入成功后释放
+// 返回:
+//   - *quotaLimit: 字节数限制
+func (storage *QuotaStorage) limit(prefix string, replaced int64) *quotaLimit {
+	limit := &quotaLimit{remaining: -1}
+	quota := storage.Quota(prefix)
+	if quota.MaxBytes > 0 {
+		usage, _ := storage.Options.Store.Usage(prefix)
+		limit.remaining = quota.MaxBytes - usage.Bytes + replaced
+		limit.exceeded = func(written int64) error {
+			return &QuotaExceededError{Prefix: prefix, Quota: quota, Usage: Usage{Bytes: usage.Bytes - replaced + written, Objects: usage.Objects}}
+		}
+	}
+	return limit
+}
+
+// quotaLimit 大小未知的写入的字节数限制
+type quotaLimit struct {
+	remaining int64
+	written   int64
+	exceeded  func(written int64) error
+	err       error
+}
+
+// add 计入n个字节，超出剩余配额时返回并记录 *QuotaExceededError
+func (limit *quotaLimit) add(n int64) error {
+	if limit.err == nil && limit.remaining >= 0 && limit.written+n > limit.remaining {
+		limit.err = limit.exceeded(limit.written + n)
+	}
+	if limit.err != nil {
+		return limit.err
+	}
+	limit.written += n
+	return nil
+}
+
+// quotaReader 读取超出剩余配额时返回 *QuotaExceededError 的读取器
+type quotaReader struct {
+	io.Reader
+	*quotaLimit
+}
+
+// Read 读取内容并计数
+func (reader *quotaReader) Read(p []byte) (int, error) {
+	if reader.err != nil {
+		return 0, reader.err
+	}
+	n, err := reader.Reader.Read(p)
+	if limitErr := reader.add(int64(n)); limitErr != nil {
+		return 0, limitErr
+	}
+	return n, err
+}
+
+// NewWriter 创建流式写入器，写入超出剩余配额时返回 *QuotaExceededError
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *QuotaStorage) NewWriter(path string) (io.WriteCloser, error) {
+	prefix := storage.Prefix(path)
+	old, err := storage.existing(path)
+	if err != nil {
+		return nil, err
+	}
+	undo, err := storage.reserve(prefix, Usage{Objects: 1 - old.Objects})
+	if err != nil {
+		return nil, err
+	}
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		undo()
+		return nil, err
+	}
+	return &quotaWriter{WriteCloser: writer, storage: storage, prefix: prefix, limit: storage.limit(prefix, old.Bytes), old: old.Bytes, undo: undo}, nil
+}
+
+// quotaWriter 关闭成功后计入写入字节数的写入器
+type quotaWriter struct {
+	io.WriteCloser
+	storage *QuotaStorage
+	prefix  string
+	limit   *quotaLimit
+	old     int64
+	undo    func()
+}
+
+// Write 写入内容，超出剩余配额时不再写入并返回错误
+func (writer *quotaWriter) Write(p []byte) (int, error) {
+	if err := writer.limit.add(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return writer.WriteCloser.Write(p)
+}
+
+// Close 关闭写入器，成功时计入写入的字节数，失败或超出配额时撤销预先计入的对象数
+func (writer *quotaWriter) Close() error {
+	err := writer.WriteCloser.Close()
+	if err == nil && writer.limit.err == nil {
+		writer.storage.Options.Store.AddUsage(writer.prefix, Usage{Bytes: writer.limit.written - writer.old})
+		return nil
+	}
+	writer.undo()
+	if writer.limit.err != nil {
+		return writer.limit.err
+	}
+	return err
+}
+
+// Delete 删除文件并释放用量
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *QuotaStorage) Delete(path string) error {
+	old, err := storage.existing(path)
+	if err != nil {
+		return err
+	}
+	if err := storage.StorageInterface.Delete(path); err != nil {
+		return err
+	}
+	storage.release(path, old)
+	return nil
+}
+
+// samePath 判断两个路径是否指向同一个对象
+func samePath(a, b string) bool {
+	return strings.TrimPrefix(strings.ReplaceAll(a, `\`, "/"), "/") == strings.TrimPrefix(strings.ReplaceAll(b, `\`, "/"), "/")
+}
+
+// release 释放对象占用的用量
+func (storage *QuotaStorage) release(path string, usage Usage) {
+	if usage.Objects > 0 {
+		storage.Options.Store.AddUsage(storage.Prefix(path), Usage{Bytes: -usage.Bytes, Objects: -usage.Objects})
+	}
+}
+
+// DeleteObjects 批量删除文件并释放删除成功的对象的用量
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *QuotaStorage) DeleteObjects(paths []string) error {
+	olds := make(map[string]Usage, len(paths))
+	for _, path := range paths {
+		old, err := storage.existing(path)
+		if err != nil {
+			return err
+		}
+		olds[path] = old
+	}
+
+	err := storage.StorageInterface.DeleteObjects(paths)
+	var deleteErr *DeleteObjectsError
+	if err != nil && !errors.As(err, &deleteErr) {
+		return err
+	}
+	for path, old := range olds {
+		if deleteErr != nil && deleteErr.Errors[path] != nil {
+			continue
+		}
+		storage.release(path, old)
+	}
+	return err
+}
+
+// DeleteDir 删除目录下的全部文件并释放用量
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *QuotaStorage) DeleteDir(dir string) error {
+	objects, err := storage.StorageInterface.List(dir)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if err := storage.StorageInterface.DeleteDir(dir); err != nil {
+		return err
+	}
+	for _, object := range objects {
+		storage.release(object.Path, Usage{Bytes: object.Size, Objects: 1})
+	}
+	return nil
+}
+
+// Copy 复制文件，目标前缀超出配额时返回 *QuotaExceededError
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *QuotaStorage) Copy(srcPath, dstPath string) error {
+	src, err := storage.existing(srcPath)
+	if err != nil {
+		return err
+	}
+	old, err := storage.existing(dstPath)
+	if err != nil {
+		return err
+	}
+	undo, err := storage.reserve(storage.Prefix(dstPath), Usage{Bytes: src.Bytes - old.Bytes, Objects: 1 - old.Objects})
+	if err != nil {
+		return err
+	}
+	if err := storage.StorageInterface.Copy(srcPath, dstPath); err != nil {
+		undo()
+		return err
+	}
+	return nil
+}
+
+// Move 移动文件，目标前缀超出配额时返回 *QuotaExceededError
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *QuotaStorage) Move(srcPath, dstPath string) error {
+	// 源和目标相同时用量不变
+	if samePath(srcPath, dstPath) {
+		return storage.StorageInterface.Move(srcPath, dstPath)
+	}
+	src, err := storage.existing(srcPath)
+	if err != nil {
+		return err
+	}
+	old, err := storage.existing(dstPath)
+	if err != nil {
+		return err
+	}
+	undo, err := storage.reserve(storage.Prefix(dstPath), Usage{Bytes: src.Bytes - old.Bytes, Objects: 1 - old.Objects})
+	if err != nil {
+		return err
+	}
+	if err := storage.StorageInterface.Move(srcPath, dstPath); err != nil {
+		undo()
+		return err
+	}
+	storage.release(srcPath, src)
+	return nil
+}
@@ -0,0 +1,118 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestQuota(t *testing.T) {
+	mock := ossmock.New()
+	mock.Put("/tenants/a/existing.txt", strings.NewReader("12345"))
+	storage := oss.WithQuota(mock, oss.QuotaOptions{
+		Depth:   2,
+		Quotas:  map[string]oss.Quota{"/tenants/a/": {MaxBytes: 20, MaxObjects: 3}},
+		Default: oss.Quota{MaxObjects: 1},
+	})
+	usage := func(prefix string) oss.Usage {
+		t.Helper()
+		usage, err := storage.Usage(prefix)
+		if err != nil {
+			t.Fatalf("No error should happen when get usage, but got %v", err)
+		}
+		return usage
+	}
+
+	if usage, err := storage.Recalculate("/tenants/a/"); err != nil || usage != (oss.Usage{Bytes: 5, Objects: 1}) {
+		t.Fatalf("Recalculate should count existing objects, but got %+v, %v", usage, err)
+	}
+	if storage.Prefix("/tenants/a/deep/x.txt") != "/tenants/a/" || storage.Prefix("/x.txt") != "/" {
+		t.Errorf("Prefix should use the first %v directories", storage.Options.Depth)
+	}
+
+	if _, err := storage.Put("/tenants/a/b.txt", strings.NewReader("0123456789")); err != nil {
+		t.Fatalf("Put within quota should succeed, but got %v", err)
+	}
+	_, err := storage.Put("/tenants/a/c.txt", strings.NewReader("0123456789"))
+	var quotaErr *oss.QuotaExceededError
+	if !errors.As(err, &quotaErr) || !errors.Is(err, oss.ErrQuotaExceeded) || quotaErr.Usage.Bytes != 25 || oss.HTTPStatus(err) != 507 {
+		t.Errorf("Put over the byte quota should be rejected, but got %v", err)
+	}
+	if exists, _ := mock.Exists("/tenants/a/c.txt"); exists {
+		t.Errorf("Rejected object should not be uploaded")
+	}
+	if usage("/tenants/a/") != (oss.Usage{Bytes: 15, Objects: 2}) {
+		t.Errorf("Rejected put should not change usage, but got %+v", usage("/tenants/a/"))
+	}
+
+	// 覆盖已有对象只计入大小的变化
+	if _, err := storage.Put("/tenants/a/b.txt", strings.NewReader("012345678901234")); err != nil || usage("/tenants/a/") != (oss.Usage{Bytes: 20, Objects: 2}) {
+		t.Errorf("Overwrite should count the size difference, but got %+v, %v", usage("/tenants/a/"), err)
+	}
+
+	// 大小未知的流超出剩余配额后中止
+	if _, err := storage.Put("/tenants/a/stream.txt", io.MultiReader(strings.NewReader("x"))); !errors.Is(err, oss.ErrQuotaExceeded) {
+		t.Errorf("Stream over the remaining quota should be rejected, but got %v", err)
+	}
+	storage.Delete("/tenants/a/b.txt")
+	writer, _ := storage.NewWriter("/tenants/a/stream.txt")
+	io.WriteString(writer, "0123456789")
+	if err := writer.Close(); err != nil || usage("/tenants/a/") != (oss.Usage{Bytes: 15, Objects: 2}) {
+		t.Errorf("Writer should count written bytes after delete released usage, but got %+v, %v", usage("/tenants/a/"), err)
+	}
+	writer, _ = storage.NewWriter("/tenants/a/big.txt")
+	if _, err := io.WriteString(writer, "0123456789"); !errors.Is(err, oss.ErrQuotaExceeded) || !errors.Is(writer.Close(), oss.ErrQuotaExceeded) {
+		t.Errorf("Writer over the remaining quota should fail, but got %v", err)
+	}
+	if usage("/tenants/a/") != (oss.Usage{Bytes: 15, Objects: 2}) {
+		t.Errorf("Failed writer should not change usage, but got %+v", usage("/tenants/a/"))
+	}
+
+	// 默认配额和跨前缀的复制、移动
+	if err := storage.Copy("/tenants/a/stream.txt", "/tenants/b/copy.txt"); err != nil {
+		t.Fatalf("Copy within the default quota should succeed, but got %v", err)
+	}
+	if err := storage.Move("/tenants/a/existing.txt", "/tenants/b/moved.txt"); !errors.Is(err, oss.ErrQuotaExceeded) {
+		t.Errorf("Move over the default object quota should be rejected, but got %v", err)
+	}
+	if err := storage.DeleteDir("/tenants/b"); err != nil || usage("/tenants/b/") != (oss.Usage{}) {
+		t.Errorf("DeleteDir should release usage, but got %+v, %v", usage("/tenants/b/"), err)
+	}
+	if err := storage.Move("/tenants/a/existing.txt", "/tenants/b/moved.txt"); err != nil || usage("/tenants/a/") != (oss.Usage{Bytes: 10, Objects: 1}) || usage("/tenants/b/") != (oss.Usage{Bytes: 5, Objects: 1}) {
+		t.Errorf("Move should transfer usage, but got %+v, %+v, %v", usage("/tenants/a/"), usage("/tenants/b/"), err)
+	}
+
+	if err := storage.DeleteObjects([]string{"/tenants/a/stream.txt", "/tenants/b/moved.txt"}); err != nil || usage("/tenants/a/") != (oss.Usage{}) || usage("/tenants/b/") != (oss.Usage{}) {
+		t.Errorf("DeleteObjects should release usage, but got %+v, %+v, %v", usage("/tenants/a/"), usage("/tenants/b/"), err)
+	}
+}
+
+func TestQuotaCollision(t *testing.T) {
+	mock := ossmock.New()
+	storage := oss.WithQuota(mock, oss.QuotaOptions{Depth: 1, Default: oss.Quota{MaxObjects: 2}})
+	rename := &oss.PutOptions{Collision: oss.CollisionRename}
+	for i := int64(0); i < 2; i++ {
+		if object, err := storage.PutWithOptions("/a/x.txt", strings.NewReader("data"), rename); err != nil {
+			t.Fatalf("Renamed upload within quota should succeed, but got %v", err)
+		} else if usage, _ := storage.Usage("/a/"); usage != (oss.Usage{Bytes: 4 * (i + 1), Objects: i + 1}) {
+			t.Errorf("Upload renamed to %v should be counted as a new object, but got %+v", object.Path, usage)
+		}
+	}
+	if _, err := storage.PutWithOptions("/a/x.txt", strings.NewReader("data"), rename); !errors.Is(err, oss.ErrQuotaExceeded) {
+		t.Errorf("Renamed upload over the object quota should be rejected, but got %v", err)
+	}
+	if _, err := storage.PutWithOptions("/a/x.txt", strings.NewReader("data"), &oss.PutOptions{Collision: oss.CollisionError}); !errors.Is(err, oss.ErrQuotaExceeded) {
+		t.Errorf("Upload with CollisionError should be counted as a new object, but got %v", err)
+	}
+
+	if err := storage.Move("/a/x.txt", `a\x.txt`); err != nil {
+		t.Fatalf("Moving an object onto itself should succeed, but got %v", err)
+	}
+	if usage, _ := storage.Usage("/a/"); usage != (oss.Usage{Bytes: 8, Objects: 2}) {
+		t.Errorf("Moving an object onto itself should not change usage, but got %+v", usage)
+	}
+}
@@ -0,0 +1,56 @@
+package oss
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ValidateRange 校验读取范围
+// 参数:
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - error: 偏移量为负数时返回 ErrInvalidRange
+func ValidateRange(offset, length int64) error {
+	if offset < 0 {
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidRange, offset)
+	}
+	return nil
+}
+
+// HTTPRange 将读取范围转换为HTTP Range头
+// 参数:
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - string: Range头的值，例如 bytes=100-199
+func HTTPRange(offset, length int64) string {
+	if length <= 0 {
+		return "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	}
+	return "bytes=" + strconv.FormatInt(offset, 10) + "-" + strconv.FormatInt(offset+length-1, 10)
+}
+
+// LimitStream 跳过偏移量之前的内容并限制读取长度
+// 用于不支持范围读取的存储后端，跳过的内容仍然需要下载
+// 参数:
+//   - stream: 完整的文件流
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func LimitStream(stream io.ReadCloser, offset, length int64) (io.ReadCloser, error) {
+	if _, err := io.CopyN(io.Discard, stream, offset); err != nil && err != io.EOF {
+		stream.Close()
+		return nil, err
+	}
+	if length <= 0 {
+		return stream, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(stream, length), stream}, nil
+}
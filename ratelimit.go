@@ -0,0 +1,319 @@
+package oss
+
+import (
+	"io"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// RateLimit 限制调用速率和并发数的配置
+type RateLimit struct {
+	// RequestsPerSecond 每秒最多开始的调用数，小于等于0时不限制速率
+	RequestsPerSecond float64
+	// Burst 令牌桶的容量，即空闲后可以立即开始的调用数，小于等于0时为 RequestsPerSecond 向上取整
+	Burst int
+	// MaxConcurrent 同时进行的调用数上限，小于等于0时不限制并发
+	MaxConcurrent int
+}
+
+// RateLimitStorage 限制调用速率和并发数的存储包装器
+// 用于保护群晖DSM、七牛等容易被批量任务的突发请求压垮的后端；同一个包装器的所有调用共享限制，
+// 多个应用组件需要共享限制时应使用同一个包装器实例。
+// GetStream、GetStreamRange 和 NewWriter 占用的并发数在关闭流时才释放，调用方必须关闭返回的流；
+// GetURL 等只在本地计算的方法不受限制
+type RateLimitStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Limit 限制配置
+	Limit RateLimit
+	// Sleep 令牌不足时等待的方法，为nil时使用 time.Sleep；令牌按 DefaultClock 的时间补充，测试中可以一起替换
+	Sleep func(d time.Duration)
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	slots  chan struct{}
+}
+
+// WithRateLimit 创建限制调用速率和并发数的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - limit: 限制配置
+// 返回:
+//   - *RateLimitStorage: 存储包装器实例
+func WithRateLimit(storage StorageInterface, limit RateLimit) *RateLimitStorage {
+	if limit.RequestsPerSecond > 0 && limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+	rateLimited := &RateLimitStorage{StorageInterface: storage, Limit: limit, tokens: float64(limit.Burst), last: Now()}
+	if limit.MaxConcurrent > 0 {
+		rateLimited.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return rateLimited
+}
+
+// acquire 等待并发数和令牌桶允许后开始一次调用
+// 返回:
+//   - func(): 调用结束时释放并发数，可以多次调用
+func (storage *RateLimitStorage) acquire() func() {
+	if storage.slots != nil {
+		storage.slots <- struct{}{}
+	}
+	if delay := storage.reserve(); delay > 0 {
+		if storage.Sleep != nil {
+			storage.Sleep(delay)
+		} else {
+			time.Sleep(delay)
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if storage.slots != nil {
+				<-storage.slots
+			}
+		})
+	}
+}
+
+// reserve 从令牌桶中预留一个令牌，令牌不足时返回需要等待的时间
+func (storage *RateLimitStorage) reserve() time.Duration {
+	rate := storage.Limit.RequestsPerSecond
+	if rate <= 0 {
+		return 0
+	}
+
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	now := Now()
+	storage.tokens = min(float64(storage.Limit.Burst), storage.tokens+now.Sub(storage.last).Seconds()*rate)
+	storage.last = now
+	storage.tokens--
+	if storage.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-storage.tokens / rate * float64(time.Second))
+}
+
+// Running 返回占用并发数的调用数，包括尚未关闭的流和写入器
+// 返回:
+//   - int: 正在进行的调用数，不限制并发时总是返回0
+func (storage *RateLimitStorage) Running() int {
+	return len(storage.slots)
+}
+
+// rateLimited 在限制内执行操作
+func rateLimited[T any](storage *RateLimitStorage, fn func() (T, error)) (T, error) {
+	release := storage.acquire()
+	defer release()
+	return fn()
+}
+
+// Get 在限制内获取指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *RateLimitStorage) Get(path string) (*os.File, error) {
+	return rateLimited(storage, func() (*os.File, error) {
+		return storage.StorageInterface.Get(path)
+	})
+}
+
+// GetStream 在限制内获取指定路径文件的流，关闭流时释放并发数
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息
+func (storage *RateLimitStorage) GetStream(path string) (io.ReadCloser, error) {
+	release := storage.acquire()
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseReadCloser{ReadCloser: stream, release: release}, nil
+}
+
+// GetStreamRange 在限制内获取指定路径文件的部分内容，关闭流时释放并发数
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *RateLimitStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	release := storage.acquire()
+	stream, err := storage.StorageInterface.GetStreamRange(path, offset, length)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseReadCloser{ReadCloser: stream, release: release}, nil
+}
+
+// Stat 在限制内获取对象信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *RateLimitStorage) Stat(path string) (*Object, error) {
+	return rateLimited(storage, func() (*Object, error) {
+		return storage.StorageInterface.Stat(path)
+	})
+}
+
+// Exists 在限制内检查对象是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *RateLimitStorage) Exists(path string) (bool, error) {
+	return rateLimited(storage, func() (bool, error) {
+		return storage.StorageInterface.Exists(path)
+	})
+}
+
+// Put 在限制内上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *RateLimitStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return rateLimited(storage, func() (*Object, error) {
+		return storage.StorageInterface.Put(path, reader)
+	})
+}
+
+// PutWithOptions 在限制内使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *RateLimitStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	return rateLimited(storage, func() (*Object, error) {
+		return storage.StorageInterface.PutWithOptions(path, reader, opts)
+	})
+}
+
+// NewWriter 在限制内创建流式写入器，关闭写入器时释放并发数
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *RateLimitStorage) NewWriter(path string) (io.WriteCloser, error) {
+	release := storage.acquire()
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseWriteCloser{WriteCloser: writer, release: release}, nil
+}
+
+// Delete 在限制内删除文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *RateLimitStorage) Delete(path string) error {
+	release := storage.acquire()
+	defer release()
+	return storage.StorageInterface.Delete(path)
+}
+
+// DeleteObjects 在限制内批量删除对象，一次批量删除计为一次调用
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *RateLimitStorage) DeleteObjects(paths []string) error {
+	release := storage.acquire()
+	defer release()
+	return storage.StorageInterface.DeleteObjects(paths)
+}
+
+// DeleteDir 在限制内删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *RateLimitStorage) DeleteDir(dir string) error {
+	release := storage.acquire()
+	defer release()
+	return storage.StorageInterface.DeleteDir(dir)
+}
+
+// Copy 在限制内复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *RateLimitStorage) Copy(srcPath, dstPath string) error {
+	release := storage.acquire()
+	defer release()
+	return storage.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 在限制内移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *RateLimitStorage) Move(srcPath, dstPath string) error {
+	release := storage.acquire()
+	defer release()
+	return storage.StorageInterface.Move(srcPath, dstPath)
+}
+
+// List 在限制内列出对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息
+func (storage *RateLimitStorage) List(path string) ([]*Object, error) {
+	return rateLimited(storage, func() ([]*Object, error) {
+		return storage.StorageInterface.List(path)
+	})
+}
+
+// releaseReadCloser 关闭时释放并发数的文件流
+type releaseReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close 关闭文件流并释放并发数
+func (stream *releaseReadCloser) Close() error {
+	defer stream.release()
+	return stream.ReadCloser.Close()
+}
+
+// releaseWriteCloser 关闭时释放并发数的写入器
+type releaseWriteCloser struct {
+	io.WriteCloser
+	release func()
+}
+
+// Close 关闭写入器并释放并发数
+func (writer *releaseWriteCloser) Close() error {
+	defer writer.release()
+	return writer.WriteCloser.Close()
+}
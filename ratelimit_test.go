@@ -0,0 +1,86 @@
+package oss_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	release := make(chan struct{})
+	mock := ossmock.New()
+	mock.StatFunc = func(path string) (*oss.Object, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &oss.Object{Path: path}, nil
+	}
+
+	storage := oss.WithRateLimit(mock, oss.RateLimit{MaxConcurrent: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			storage.Stat("/a.txt")
+		}()
+	}
+	for storage.Running() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if peak != 2 || len(mock.CallsTo("Stat")) != 8 {
+		t.Errorf("At most 2 of 8 calls should run concurrently, but got %v of %v", peak, len(mock.CallsTo("Stat")))
+	}
+
+	clock := oss.DefaultClock
+	defer func() { oss.DefaultClock = clock }()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	oss.DefaultClock = oss.ClockFunc(func() time.Time { return now })
+
+	var waits []time.Duration
+	storage = oss.WithRateLimit(mock, oss.RateLimit{RequestsPerSecond: 100, Burst: 2})
+	storage.Sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		now = now.Add(d)
+	}
+	for i := 0; i < 5; i++ {
+		storage.Exists("/a.txt")
+	}
+	if len(waits) != 3 || waits[0] != 10*time.Millisecond || waits[2] != 10*time.Millisecond {
+		t.Errorf("Calls after the burst of 2 should wait 10ms each at 100 per second, but got %v", waits)
+	}
+	now = now.Add(time.Second)
+	waits = nil
+	storage.Exists("/a.txt")
+	storage.Exists("/a.txt")
+	if len(waits) != 0 {
+		t.Errorf("Bucket should refill up to the burst while idle, but got %v", waits)
+	}
+
+	mock.Put("/b.txt", strings.NewReader("sample"))
+	storage = oss.WithRateLimit(mock, oss.RateLimit{MaxConcurrent: 1})
+	stream, _ := storage.GetStream("/b.txt")
+	if storage.Running() != 1 {
+		t.Errorf("Open stream should hold the concurrency slot until closed")
+	}
+	stream.Close()
+	if storage.Running() != 0 {
+		t.Errorf("Closing the stream should release its slot")
+	}
+	if _, err := storage.Exists("/b.txt"); err != nil || storage.Running() != 0 {
+		t.Errorf("Calls should release their slot on return, but got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package redirect 签名URL跳转处理器
+// 通过回调检查访问权限后以302跳转到新生成的预签名URL，服务不需要代理对象内容即可保护对象
+package redirect
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// DefaultExpiry 预签名URL的默认有效期
+const DefaultExpiry = 5 * time.Minute
+
+// 访问令牌的查询参数
+const (
+	// ExpiresParam 令牌过期时间的Unix时间戳，单位秒
+	ExpiresParam = "expires"
+	// TokenParam 令牌签名
+	TokenParam = "token"
+)
+
+// Authorizer 检查请求是否有权访问对象
+// 参数:
+//   - r: HTTP请求
+//   - path: 对象路径
+// 返回:
+//   - error: 无权访问时返回错误，响应状态码由 oss.HTTPStatus 决定，无法识别的错误返回403
+type Authorizer func(r *http.Request, path string) error
+
+// Handler 签名URL跳转处理器
+// 只处理GET和HEAD请求，对象路径为请求路径，挂载在子路径下时配合 http.StripPrefix 使用
+type Handler struct {
+	// Storage 存储接口
+	Storage oss.StorageInterface
+	// Authorize 权限检查回调，为nil时不检查，只设置 Secret 时仅依赖令牌
+	Authorize Authorizer
+	// Expiry 预签名URL的有效期，小于等于0时使用 DefaultExpiry
+	Expiry time.Duration
+	// Options 生成预签名URL的其它选项，例如 ResponseContentDisposition，Expiry 和 Method 会被覆盖
+	Options oss.SignedURLOptions
+	// Secret 访问令牌的密钥，设置后请求必须携带由 Token 生成的未过期令牌
+	Secret []byte
+	// Registry 一次性令牌登记表，设置后请求必须携带由 OnceURL 签发的一次性令牌，链接只能成功跳转一次
+	Registry oss.TokenRegistry
+	// Audit 每次请求处理完成后的回调，err为nil表示已跳转到预签名URL，可以为nil
+	Audit func(r *http.Request, path string, err error)
+}
+
+// New 创建签名URL跳转处理器
+// 参数:
+//   - storage: 存储接口
+//   - authorize: 权限检查回调
+// 返回:
+//   - *Handler: 跳转处理器
+func New(storage oss.StorageInterface, authorize Authorizer) *Handler {
+	return &Handler{Storage: storage, Authorize: authorize}
+}
+
+// ServeHTTP 检查权限后跳转到预签名URL
+// 跳转响应允许浏览器在预签名URL有效期的一半内复用，不允许共享缓存保存；使用一次性令牌时不允许缓存
+func (handler *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := r.URL.Path
+	signedURL, expiry, status, err := handler.signedURL(r, path)
+	if handler.Audit != nil {
+		handler.Audit(r, path, err)
+	}
+	if err != nil {
+		handler.error(w, status)
+		return
+	}
+
+	if handler.Registry != nil {
+		w.Header().Set("Cache-Control", "no-store")
+	} else {
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(expiry.Seconds()/2)))
+	}
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, signedURL, http.StatusFound)
+}
+
+// signedURL 依次校验访问令牌、权限和一次性令牌，通过后生成预签名URL
+// 一次性令牌在权限检查通过后才使用，无权访问的请求不会作废链接
+func (handler *Handler) signedURL(r *http.Request, path string) (string, time.Duration, int, error) {
+	if len(handler.Secret) > 0 {
+		if err := handler.verify(r.URL.Query(), path); err != nil {
+			return "", 0, oss.HTTPStatus(err), err
+		}
+	}
+	if handler.Authorize != nil {
+		if err := handler.Authorize(r, path); err != nil {
+			status := oss.HTTPStatus(err)
+			if status == http.StatusInternalServerError {
+				status = http.StatusForbidden
+			}
+			return "", 0, status, err
+		}
+	}
+	if handler.Registry != nil {
+		if err := handler.Registry.Redeem(r.URL.Query().Get(oss.OnceParam), path); err != nil {
+			return "", 0, oss.HTTPStatus(err), err
+		}
+	}
+
+	expiry := handler.Expiry
+	if expiry <= 0 {
+		expiry = DefaultExpiry
+	}
+	opts := handler.Options
+	opts.Expiry, opts.Method = expiry, http.MethodGet
+	signedURL, err := handler.Storage.GetSignedURL(path, opts)
+	if err != nil {
+		return "", 0, oss.HTTPStatus(err), err
+	}
+	return signedURL, expiry, http.StatusFound, nil
+}
+
+// Token 生成绑定对象路径和过期时间的访问令牌
+// 参数:
+//   - path: 对象路径
+//   - ttl: 令牌有效期
+// 返回:
+//   - url.Values: 需要附加到跳转地址的查询参数
+func (handler *Handler) Token(path string, ttl time.Duration) url.Values {
+	expires := strconv.FormatInt(oss.Now().Add(ttl).Unix(), 10)
+	return url.Values{ExpiresParam: {expires}, TokenParam: {handler.sign(path, expires)}}
+}
+
+// URL 生成带访问令牌的跳转地址
+// 参数:
+//   - base: 处理器挂载的地址，例如 https://example.com/files
+//   - path: 对象路径
+//   - ttl: 令牌有效期
+// 返回:
+//   - string: 跳转地址
+func (handler *Handler) URL(base, path string, ttl time.Duration) string {
+	return base + (&url.URL{Path: path}).EscapedPath() + "?" + handler.Token(path, ttl).Encode()
+}
+
+// OnceURL 生成只能成功跳转一次的地址，需要设置 Registry
+// 同时设置了 Secret 时地址还带有访问令牌，令牌有效期与一次性令牌相同
+// 参数:
+//   - base: 处理器挂载的地址，例如 https://example.com/files
+//   - path: 对象路径
+//   - ttl: 链接有效期
+// 返回:
+//   - string: 跳转地址
+//   - error: 签发一次性令牌失败时的错误信息
+func (handler *Handler) OnceURL(base, path string, ttl time.Duration) (string, error) {
+	if handler.Registry == nil {
+		return "", fmt.Errorf("%w: redirect handler has no token registry", oss.ErrNotSupported)
+	}
+	token, err := handler.Registry.Issue(path, ttl)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	if len(handler.Secret) > 0 {
+		query = handler.Token(path, ttl)
+	}
+	query.Set(oss.OnceParam, token)
+	return base + (&url.URL{Path: path}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+// verify 校验访问令牌
+func (handler *Handler) verify(query url.Values, path string) error {
+	expires := query.Get(ExpiresParam)
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid token", oss.ErrPermissionDenied)
+	}
+	if !hmac.Equal([]byte(query.Get(TokenParam)), []byte(handler.sign(path, expires))) {
+		return fmt.Errorf("%w: token does not match %s", oss.ErrPermissionDenied, path)
+	}
+	if oss.Now().Unix() > unix {
+		return fmt.Errorf("%w: token expired", oss.ErrPermissionDenied)
+	}
+	return nil
+}
+
+// sign 计算路径和过期时间的HMAC-SHA256签名
+func (handler *Handler) sign(path, expires string) string {
+	mac := hmac.New(sha256.New, handler.Secret)
+	mac.Write([]byte(path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// error 以状态码响应错误，错误响应不允许缓存
+func (handler *Handler) error(w http.ResponseWriter, status int) {
+	w.Header().Set("Cache-Control", "no-store")
+	http.Error(w, http.StatusText(status), status)
+}
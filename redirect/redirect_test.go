@@ -0,0 +1,121 @@
+package redirect
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func newStorage() *ossmock.Storage {
+	storage := ossmock.New()
+	storage.GetSignedURLFunc = func(path string, opts oss.SignedURLOptions) (string, error) {
+		return "https://bucket.example.com" + path + "?expires=" + opts.Expiry.String(), nil
+	}
+	return storage
+}
+
+func TestRedirect(t *testing.T) {
+	handler := New(newStorage(), func(r *http.Request, path string) error {
+		if r.Header.Get("Authorization") != "Bearer ok" {
+			return errors.New("no session")
+		}
+		if strings.HasPrefix(path, "/private/") {
+			return oss.ErrNotFound
+		}
+		return nil
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/docs/a.txt", nil)
+	request.Header.Set("Authorization", "Bearer ok")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusFound || recorder.Header().Get("Location") != "https://bucket.example.com/docs/a.txt?expires=5m0s" {
+		t.Errorf("Authorized request should redirect to signed URL, but got %v %v", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if cacheControl := recorder.Header().Get("Cache-Control"); cacheControl != "private, max-age=150" {
+		t.Errorf("Redirect should be cacheable for half of the expiry, but got %v", cacheControl)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs/a.txt", nil))
+	if recorder.Code != http.StatusForbidden || recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Unauthorized request should return uncached 403, but got %v %v", recorder.Code, recorder.Header().Get("Cache-Control"))
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/private/a.txt", nil)
+	request.Header.Set("Authorization", "Bearer ok")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Authorizer error should map to its HTTP status, but got %v", recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/docs/a.txt", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT should not be allowed, but got %v", recorder.Code)
+	}
+}
+
+func TestToken(t *testing.T) {
+	handler := &Handler{Storage: newStorage(), Secret: []byte("secret"), Expiry: time.Minute}
+	server := httptest.NewServer(http.StripPrefix("/files", handler))
+	defer server.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	for link, expected := range map[string]int{
+		handler.URL(server.URL+"/files", "/docs/a b.txt", time.Minute):  http.StatusFound,
+		handler.URL(server.URL+"/files", "/docs/a b.txt", -time.Minute): http.StatusForbidden,
+		server.URL + "/files/docs/other.txt?" + handler.Token("/docs/a b.txt", time.Minute).Encode(): http.StatusForbidden,
+		server.URL + "/files/docs/a.txt": http.StatusForbidden,
+	} {
+		response, err := client.Get(link)
+		if err != nil {
+			t.Fatalf("No error should happen when request %v, but got %v", link, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != expected {
+			t.Errorf("%v should return %v, but got %v", link, expected, response.StatusCode)
+		}
+	}
+}
+
+func TestOnceURL(t *testing.T) {
+	var audited []error
+	handler := &Handler{Storage: newStorage(), Secret: []byte("secret"), Registry: oss.NewMemoryTokenRegistry(),
+		Audit: func(r *http.Request, path string, err error) { audited = append(audited, err) }}
+	server := httptest.NewServer(http.StripPrefix("/files", handler))
+	defer server.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	link, err := handler.OnceURL(server.URL+"/files", "/docs/a.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("No error should happen when issue one-time link, but got %v", err)
+	}
+	for i, expected := range []int{http.StatusFound, http.StatusForbidden} {
+		response, err := client.Get(link)
+		if err != nil {
+			t.Fatalf("No error should happen when request %v, but got %v", link, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != expected {
+			t.Errorf("Request %d of one-time link should return %v, but got %v", i+1, expected, response.StatusCode)
+		}
+		if expected == http.StatusFound && response.Header.Get("Cache-Control") != "no-store" {
+			t.Errorf("One-time redirect should not be cached, but got %v", response.Header.Get("Cache-Control"))
+		}
+	}
+	if len(audited) != 2 || audited[0] != nil || !errors.Is(audited[1], oss.ErrTokenRedeemed) {
+		t.Errorf("Audit should record both requests, but got %v", audited)
+	}
+
+	if _, err := (&Handler{Storage: newStorage()}).OnceURL(server.URL, "/docs/a.txt", time.Minute); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("OnceURL without registry should not be supported, but got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package oss
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegionReporter 查询存储桶所在区域的接口
+// s3、aliyun、tencent、huawei和googlecloud实现了该接口，调用方通过类型断言判断是否支持
+type RegionReporter interface {
+	// BucketRegion 查询存储桶所在的区域
+	// 返回:
+	//   - string: 区域名，例如 eu-west-1、cn-hangzhou，统一为小写
+	//   - error: 错误信息
+	BucketRegion() (string, error)
+}
+
+// ResidencyStorage 限制数据存放区域的存储包装器
+// 创建时和每次写入前查询存储桶所在区域，不在允许的列表中时拒绝写入并返回 ErrRegionNotAllowed，读取和删除不受限制
+type ResidencyStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Allowed 允许的区域，不区分大小写，以*结尾时按前缀匹配，例如 eu-*
+	Allowed []string
+	// CacheFor 区域查询结果的缓存时间，为0时每次写入都重新查询
+	CacheFor time.Duration
+
+	reporter  RegionReporter
+	mu        sync.Mutex
+	checkedAt time.Time
+}
+
+// WithResidency 创建限制数据存放区域的存储包装器，并立即检查存储桶所在区域
+// 参数:
+//   - storage: 被包装的存储接口
+//   - allowed: 允许的区域
+// 返回:
+//   - *ResidencyStorage: 存储包装器实例
+//   - error: 存储不支持查询区域时返回 ErrNotSupported，区域不被允许时返回 ErrRegionNotAllowed
+func WithResidency(storage StorageInterface, allowed ...string) (*ResidencyStorage, error) {
+	reporter, ok := storage.(RegionReporter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T cannot report its bucket region", ErrNotSupported, storage)
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("oss: residency requires at least one allowed region")
+	}
+	residency := &ResidencyStorage{StorageInterface: storage, Allowed: allowed, reporter: reporter}
+	if err := residency.Check(); err != nil {
+		return nil, err
+	}
+	return residency, nil
+}
+
+// Check 查询存储桶所在区域并与允许的列表比较
+// 上次检查通过的时间在 CacheFor 之内时直接返回
+// 返回:
+//   - error: 区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) Check() error {
+	storage.mu.Lock()
+	defer storage.mu.Unlock()
+	if storage.CacheFor > 0 && !storage.checkedAt.IsZero() && time.Since(storage.checkedAt) < storage.CacheFor {
+		return nil
+	}
+
+	region, err := storage.reporter.BucketRegion()
+	if err != nil {
+		return err
+	}
+	if !RegionAllowed(region, storage.Allowed) {
+		storage.checkedAt = time.Time{}
+		return fmt.Errorf("%w: bucket region %s is not in %s", ErrRegionNotAllowed, region, strings.Join(storage.Allowed, ", "))
+	}
+	storage.checkedAt = time.Now()
+	return nil
+}
+
+// RegionAllowed 判断区域是否在允许的列表中
+// 参数:
+//   - region: 区域名
+//   - allowed: 允许的区域，不区分大小写，以*结尾时按前缀匹配
+// 返回:
+//   - bool: 是否允许
+func RegionAllowed(region string, allowed []string) bool {
+	region = strings.ToLower(region)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(region, prefix) || pattern == region {
+			return true
+		}
+	}
+	return false
+}
+
+// Put 检查区域后上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) Put(path string, reader io.Reader) (*Object, error) {
+	if err := storage.Check(); err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.Put(path, reader)
+}
+
+// PutWithOptions 检查区域后使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	if err := storage.Check(); err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.PutWithOptions(path, reader, opts)
+}
+
+// NewWriter 检查区域后创建流式写入器
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) NewWriter(path string) (io.WriteCloser, error) {
+	if err := storage.Check(); err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.NewWriter(path)
+}
+
+// Copy 检查区域后复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) Copy(srcPath, dstPath string) error {
+	if err := storage.Check(); err != nil {
+		return err
+	}
+	return storage.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 检查区域后移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) Move(srcPath, dstPath string) error {
+	if err := storage.Check(); err != nil {
+		return err
+	}
+	return storage.StorageInterface.Move(srcPath, dstPath)
+}
+
+// GetSignedURL 生成预签名URL，上传用的URL需要先检查区域
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) GetSignedURL(path string, opts SignedURLOptions) (string, error) {
+	if opts.Method == http.MethodPut {
+		if err := storage.Check(); err != nil {
+			return "", err
+		}
+	}
+	return storage.StorageInterface.GetSignedURL(path, opts)
+}
+
+// GetUploadURL 检查区域后生成客户端直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *UploadURL: 直传地址
+//   - error: 错误信息，区域不被允许时返回 ErrRegionNotAllowed
+func (storage *ResidencyStorage) GetUploadURL(path string, opts UploadURLOptions) (*UploadURL, error) {
+	if err := storage.Check(); err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetUploadURL(path, opts)
+}
@@ -0,0 +1,55 @@
+package oss_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+// regionStorage 报告固定区域的文件系统存储
+type regionStorage struct {
+	*filesystem.FileSystem
+	region string
+}
+
+func (storage *regionStorage) BucketRegion() (string, error) {
+	return storage.region, nil
+}
+
+func TestResidency(t *testing.T) {
+	if _, err := oss.WithResidency(filesystem.New(t.TempDir()), "eu-*"); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Storage without region should not support residency, but got %v", err)
+	}
+
+	storage := &regionStorage{FileSystem: filesystem.New(t.TempDir()), region: "eu-west-1"}
+	if _, err := oss.WithResidency(storage, "cn-*"); !errors.Is(err, oss.ErrRegionNotAllowed) || !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("Residency should refuse storage outside allowed regions, but got %v", err)
+	}
+	residency, err := oss.WithResidency(storage, "EU-*", "eu-central-1")
+	if err != nil {
+		t.Fatalf("No error should happen when bucket region is allowed, but got %v", err)
+	}
+	if _, err := residency.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Errorf("No error should happen when put in allowed region, but got %v", err)
+	}
+
+	storage.region = "us-east-1"
+	if _, err := residency.Put("/b.txt", strings.NewReader("sample")); !errors.Is(err, oss.ErrRegionNotAllowed) {
+		t.Errorf("Put should be refused after bucket moved outside allowed regions, but got %v", err)
+	}
+	if err := residency.Copy("/a.txt", "/c.txt"); !errors.Is(err, oss.ErrRegionNotAllowed) {
+		t.Errorf("Copy should be refused outside allowed regions, but got %v", err)
+	}
+	if stream, err := residency.GetStream("/a.txt"); err != nil {
+		t.Errorf("Reads should not be restricted, but got %v", err)
+	} else {
+		stream.Close()
+	}
+	if status := oss.HTTPStatus(oss.ErrRegionNotAllowed); status != http.StatusForbidden {
+		t.Errorf("ErrRegionNotAllowed should map to 403, but got %v", status)
+	}
+}
@@ -0,0 +1,165 @@
+package oss
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMaxResumes 读取中断后续传的默认最大次数
+const DefaultMaxResumes = 3
+
+var (
+	// ErrShortRead 读取到的内容比对象大小短且无法续传，errors.Is(err, ErrUnavailable) 同样成立
+	ErrShortRead error = &kindError{message: "oss: short read", parent: ErrUnavailable}
+	// ErrObjectChanged 续传时对象已被修改，继续读取会拼接不同版本的内容，errors.Is(err, ErrConflict) 同样成立
+	ErrObjectChanged error = &kindError{message: "oss: object changed during read", parent: ErrConflict}
+)
+
+// ResumeStorage 检测截断的下载并从中断位置续传的存储包装器
+// 连接不稳定时（例如访问群晖NAS）流可能在读完之前出错或提前结束，调用方只看到被截断的内容。
+// GetStream 和 GetStreamRange 打开流前先通过 Stat 获取对象大小，读取出错或提前结束时使用
+// GetStreamRange 从已读取的位置继续读取；续传前再次 Stat，对象大小、ETag 或修改时间变化时返回 ErrObjectChanged
+type ResumeStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// MaxResumes 每次读取最多续传的次数，为0时只检测截断不续传
+	MaxResumes int
+}
+
+// WithResume 创建检测截断的下载并从中断位置续传的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - maxResumes: 每次读取最多续传的次数，小于0时使用 DefaultMaxResumes
+// 返回:
+//   - *ResumeStorage: 存储包装器实例
+func WithResume(storage StorageInterface, maxResumes int) *ResumeStorage {
+	if maxResumes < 0 {
+		maxResumes = DefaultMaxResumes
+	}
+	return &ResumeStorage{StorageInterface: storage, MaxResumes: maxResumes}
+}
+
+// GetStream 获取文件流，读取内容短于对象大小时从中断位置续传
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流，无法续传时读取返回 ErrShortRead
+//   - error: 错误信息
+func (storage *ResumeStorage) GetStream(path string) (io.ReadCloser, error) {
+	object, err := storage.StorageInterface.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return &resumeReader{storage: storage, path: path, object: object, stream: stream, end: object.Size}, nil
+}
+
+// GetStreamRange 范围读取文件流，读取内容短于范围时从中断位置续传
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流，无法续传时读取返回 ErrShortRead
+//   - error: 错误信息
+func (storage *ResumeStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+	object, err := storage.StorageInterface.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	end := object.Size
+	if length > 0 {
+		end = min(end, offset+length)
+	}
+	stream, err := storage.StorageInterface.GetStreamRange(path, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	return &resumeReader{storage: storage, path: path, object: object, stream: stream, offset: offset, end: max(end, offset)}, nil
+}
+
+// resumeReader 记录已读取的位置，读取出错或提前结束时续传
+type resumeReader struct {
+	storage *ResumeStorage
+	path    string
+	object  *Object
+	stream  io.ReadCloser
+	offset  int64
+	end     int64
+	resumes int
+	err     error
+}
+
+// Read 读取内容，当前的流出错或提前结束时打开新的流继续读取
+func (reader *resumeReader) Read(p []byte) (int, error) {
+	for {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		if reader.offset >= reader.end {
+			return 0, io.EOF
+		}
+
+		if int64(len(p)) > reader.end-reader.offset {
+			p = p[:reader.end-reader.offset]
+		}
+		n, err := reader.stream.Read(p)
+		reader.offset += int64(n)
+		if err == nil || (err == io.EOF && reader.offset >= reader.end) {
+			return n, nil
+		}
+		if resumeErr := reader.resume(err); resumeErr != nil {
+			reader.err = resumeErr
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// resume 关闭中断的流并从已读取的位置打开新的流
+func (reader *resumeReader) resume(cause error) error {
+	if cause == io.EOF {
+		cause = io.ErrUnexpectedEOF
+	}
+	if reader.resumes >= reader.storage.MaxResumes {
+		return fmt.Errorf("%w: %s read %d of %d bytes: %w", ErrShortRead, reader.path, reader.offset, reader.end, cause)
+	}
+	reader.resumes++
+	reader.stream.Close()
+	reader.stream = io.NopCloser(strings.NewReader(""))
+
+	object, err := reader.storage.StorageInterface.Stat(reader.path)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrShortRead, reader.path, err)
+	}
+	if objectChanged(reader.object, object) {
+		return fmt.Errorf("%w: %s", ErrObjectChanged, reader.path)
+	}
+	stream, err := reader.storage.StorageInterface.GetStreamRange(reader.path, reader.offset, reader.end-reader.offset)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrShortRead, reader.path, err)
+	}
+	reader.stream = stream
+	return nil
+}
+
+// Close 关闭当前的流
+func (reader *resumeReader) Close() error {
+	return reader.stream.Close()
+}
+
+// objectChanged 判断两次获取的对象信息是否属于不同的版本
+func objectChanged(before, after *Object) bool {
+	if before.Size != after.Size || before.ETag != after.ETag {
+		return true
+	}
+	return before.LastModified != nil && after.LastModified != nil && !before.LastModified.Equal(*after.LastModified)
+}
@@ -0,0 +1,60 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestResume(t *testing.T) {
+	mock := ossmock.New()
+	mock.Put("/nas/video.bin", strings.NewReader("0123456789"))
+	truncated := func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("0123")), nil
+	}
+	mock.GetStreamFunc = truncated
+	storage := oss.WithResume(mock, 2)
+
+	stream, err := storage.GetStream("/nas/video.bin")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+	content, err := io.ReadAll(stream)
+	stream.Close()
+	if err != nil || string(content) != "0123456789" || len(mock.CallsTo("GetStreamRange")) != 1 {
+		t.Errorf("Truncated stream should be resumed from offset 4, but got %q %v", content, err)
+	}
+
+	stream, _ = storage.GetStreamRange("/nas/video.bin", 2, 5)
+	content, _ = io.ReadAll(stream)
+	stream.Close()
+	if string(content) != "23456" {
+		t.Errorf("Range read should return the requested range, but got %q", content)
+	}
+
+	mock.GetStreamRangeFunc = func(path string, offset, length int64) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("x")), nil
+	}
+	stream, _ = storage.GetStream("/nas/video.bin")
+	content, err = io.ReadAll(stream)
+	stream.Close()
+	if !errors.Is(err, oss.ErrShortRead) || !errors.Is(err, io.ErrUnexpectedEOF) || string(content) != "0123xx" {
+		t.Errorf("Should return ErrShortRead after the resumes are used up, but got %q %v", content, err)
+	}
+
+	mock.GetStreamRangeFunc = nil
+	mock.GetStreamFunc = func(path string) (io.ReadCloser, error) {
+		mock.Put(path, strings.NewReader("abcdefghij"))
+		return truncated(path)
+	}
+	stream, _ = storage.GetStream("/nas/video.bin")
+	_, err = io.ReadAll(stream)
+	stream.Close()
+	if !errors.Is(err, oss.ErrObjectChanged) || !errors.Is(err, oss.ErrConflict) {
+		t.Errorf("Should not splice different versions of the object, but got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package oss
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAttempts 默认的最大尝试次数，包括第一次请求
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryInitialBackoff 第一次重试前的默认等待时间
+	DefaultRetryInitialBackoff = 200 * time.Millisecond
+	// DefaultRetryMaxBackoff 两次重试之间的默认最长等待时间
+	DefaultRetryMaxBackoff = 5 * time.Second
+)
+
+// DefaultRetryableStatusCodes 默认重试的HTTP状态码：限流和临时的服务端错误
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryConfig 各存储后端通用的重试配置
+// 使用SDK的后端转换为SDK的重试设置，七牛和群晖等直接发送HTTP请求的后端通过 Transport 重试
+// 方法可以在nil上调用，nil和未设置的字段使用默认值
+type RetryConfig struct {
+	// MaxAttempts 最大尝试次数，包括第一次请求，为1时不重试，小于等于0时使用 DefaultRetryMaxAttempts
+	MaxAttempts int
+	// InitialBackoff 第一次重试前的等待时间，之后每次翻倍，小于等于0时使用 DefaultRetryInitialBackoff
+	InitialBackoff time.Duration
+	// MaxBackoff 两次重试之间的最长等待时间，小于等于0时使用 DefaultRetryMaxBackoff
+	MaxBackoff time.Duration
+	// RetryableStatusCodes 需要重试的HTTP状态码，为nil时使用 DefaultRetryableStatusCodes
+	RetryableStatusCodes []int
+}
+
+// Attempts 返回最大尝试次数
+// 返回:
+//   - int: 最大尝试次数，包括第一次请求
+func (config *RetryConfig) Attempts() int {
+	if config == nil || config.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return config.MaxAttempts
+}
+
+// InitialDelay 返回第一次重试前的等待时间
+// 返回:
+//   - time.Duration: 等待时间
+func (config *RetryConfig) InitialDelay() time.Duration {
+	if config == nil || config.InitialBackoff <= 0 {
+		return DefaultRetryInitialBackoff
+	}
+	return config.InitialBackoff
+}
+
+// MaxDelay 返回两次重试之间的最长等待时间
+// 返回:
+//   - time.Duration: 等待时间
+func (config *RetryConfig) MaxDelay() time.Duration {
+	if config == nil || config.MaxBackoff <= 0 {
+		return DefaultRetryMaxBackoff
+	}
+	return config.MaxBackoff
+}
+
+// Retryable 判断HTTP状态码是否需要重试
+// 参数:
+//   - statusCode: HTTP状态码
+// 返回:
+//   - bool: 是否需要重试
+func (config *RetryConfig) Retryable(statusCode int) bool {
+	if config == nil || config.RetryableStatusCodes == nil {
+		return slices.Contains(DefaultRetryableStatusCodes, statusCode)
+	}
+	return slices.Contains(config.RetryableStatusCodes, statusCode)
+}
+
+// Backoff 返回第attempt次请求失败后的等待时间
+// 按指数退避计算并加入随机抖动，避免大量客户端同时重试
+// 参数:
+//   - attempt: 已经失败的请求次数，从1开始
+// 返回:
+//   - time.Duration: 等待时间
+func (config *RetryConfig) Backoff(attempt int) time.Duration {
+	delay, maxDelay := config.InitialDelay(), config.MaxDelay()
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Transport 返回按重试配置重试的HTTP传输
+// 只重试幂等方法（GET、HEAD、OPTIONS、PUT、DELETE）且请求内容可以重新读取的请求，
+// 网络错误和 Retryable 的状态码会重试，响应带有 Retry-After 时按其等待，最长不超过 MaxDelay
+// 参数:
+//   - base: 实际发送请求的传输，为nil时使用 http.DefaultTransport
+// 返回:
+//   - http.RoundTripper: 重试传输
+func (config *RetryConfig) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{config: config, base: base}
+}
+
+// Client 返回使用重试传输的HTTP客户端
+// 参数:
+//   - base: 基础HTTP客户端，为nil时使用 http.DefaultClient，不会被修改
+// 返回:
+//   - *http.Client: 复制base并包装Transport后的客户端
+func (config *RetryConfig) Client(base *http.Client) *http.Client {
+	if base == nil {
+		base = http.DefaultClient
+	}
+	client := *base
+	client.Transport = config.Transport(base.Transport)
+	return &client
+}
+
+// retryTransport 按重试配置重试的HTTP传输
+type retryTransport struct {
+	config *RetryConfig
+	base   http.RoundTripper
+}
+
+// RoundTrip 发送请求，失败时按重试配置重试
+func (transport *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if !replayable(request) {
+		return transport.base.RoundTrip(request)
+	}
+
+	attempts := transport.config.Attempts()
+	for attempt := 1; ; attempt++ {
+		current := request
+		if attempt > 1 && request.Body != nil && request.Body != http.NoBody {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			current = request.Clone(request.Context())
+			current.Body = body
+		}
+
+		response, err := transport.base.RoundTrip(current)
+		if attempt >= attempts || request.Context().Err() != nil {
+			return response, err
+		}
+		if err == nil && !transport.config.Retryable(response.StatusCode) {
+			return response, nil
+		}
+
+		delay := transport.config.Backoff(attempt)
+		if response != nil {
+			delay = retryAfter(response, delay, transport.config.MaxDelay())
+			io.Copy(io.Discard, io.LimitReader(response.Body, 4<<10))
+			response.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// replayable 判断请求是否可以安全地重新发送
+func replayable(request *http.Request) bool {
+	switch request.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// retryAfter 读取响应的 Retry-After 秒数，没有时返回delay
+func retryAfter(response *http.Response, delay, maxDelay time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return delay
+	}
+	return min(time.Duration(seconds)*time.Second, maxDelay)
+}
@@ -0,0 +1,60 @@
+package s3
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/smart-unicom/oss"
+)
+
+// 预定义ACL授权的用户组
+const (
+	groupAllUsers           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	groupAuthenticatedUsers = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// SetACL 使用预定义ACL设置对象的访问控制
+// 参数:
+//   - path: 对象路径
+//   - acl: 访问控制类型
+// 返回:
+//   - error: 错误信息
+func (client Client) SetACL(path string, acl oss.ACL) error {
+	_, err := client.S3.PutObjectAcl(&s3.PutObjectAclInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToRelativePath(path)),
+		ACL:    aws.String(string(acl)),
+	})
+	return wrapError(err)
+}
+
+// GetACL 根据对象的授权列表获取访问控制
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - oss.ACL: 访问控制类型
+//   - error: 错误信息
+func (client Client) GetACL(path string) (oss.ACL, error) {
+	output, err := client.S3.GetObjectAcl(&s3.GetObjectAclInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToRelativePath(path)),
+	})
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	var publicRead, publicWrite, authenticatedRead bool
+	for _, grant := range output.Grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		permission := aws.StringValue(grant.Permission)
+		switch aws.StringValue(grant.Grantee.URI) {
+		case groupAllUsers:
+			publicRead = publicRead || permission == s3.PermissionRead || permission == s3.PermissionFullControl
+			publicWrite = publicWrite || permission == s3.PermissionWrite || permission == s3.PermissionFullControl
+		case groupAuthenticatedUsers:
+			authenticatedRead = authenticatedRead || permission == s3.PermissionRead || permission == s3.PermissionFullControl
+		}
+	}
+	return oss.ACLFromGrants(publicRead, publicWrite, authenticatedRead), nil
+}
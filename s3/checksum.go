@@ -0,0 +1,149 @@
+package s3
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/smart-unicom/oss"
+)
+
+// checksumHashes 支持的校验和算法
+var checksumHashes = map[string]func() hash.Hash{
+	s3.ChecksumAlgorithmCrc32:  func() hash.Hash { return crc32.NewIEEE() },
+	s3.ChecksumAlgorithmCrc32c: func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	s3.ChecksumAlgorithmSha1:   sha1.New,
+	s3.ChecksumAlgorithmSha256: sha256.New,
+}
+
+// computeChecksum 计算内容的校验和
+// 参数:
+//   - algorithm: 校验和算法，CRC32、CRC32C、SHA1或SHA256
+//   - data: 文件内容
+// 返回:
+//   - string: Base64编码的校验和
+//   - error: 算法不支持时返回错误
+func computeChecksum(algorithm string, data []byte) (string, error) {
+	newHash, ok := checksumHashes[strings.ToUpper(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("%w: checksum algorithm %s", oss.ErrNotSupported, algorithm)
+	}
+
+	h := newHash()
+	h.Write(data)
+	sum := h.Sum(nil)
+	// CRC32系列校验和按大端序编码
+	if h32, ok := h.(hash.Hash32); ok {
+		sum = binary.BigEndian.AppendUint32(nil, h32.Sum32())
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// applyPutIntegrity 为上传请求设置服务端加密和校验和参数
+// 参数:
+//   - params: 上传参数
+//   - data: 文件内容
+// 返回:
+//   - error: 错误信息
+func (client Client) applyPutIntegrity(params *s3.PutObjectInput, data []byte) error {
+	if client.Config.ServerSideEncryption != "" {
+		params.ServerSideEncryption = aws.String(client.Config.ServerSideEncryption)
+	}
+	if client.Config.SSEKMSKeyId != "" {
+		params.SSEKMSKeyId = aws.String(client.Config.SSEKMSKeyId)
+	}
+	if client.Config.BucketKeyEnabled {
+		params.BucketKeyEnabled = aws.Bool(true)
+	}
+
+	if client.Config.ChecksumAlgorithm == "" {
+		return nil
+	}
+	algorithm := strings.ToUpper(client.Config.ChecksumAlgorithm)
+	checksum, err := computeChecksum(algorithm, data)
+	if err != nil {
+		return err
+	}
+
+	params.ChecksumAlgorithm = aws.String(algorithm)
+	switch algorithm {
+	case s3.ChecksumAlgorithmCrc32:
+		params.ChecksumCRC32 = aws.String(checksum)
+	case s3.ChecksumAlgorithmCrc32c:
+		params.ChecksumCRC32C = aws.String(checksum)
+	case s3.ChecksumAlgorithmSha1:
+		params.ChecksumSHA1 = aws.String(checksum)
+	case s3.ChecksumAlgorithmSha256:
+		params.ChecksumSHA256 = aws.String(checksum)
+	}
+	return nil
+}
+
+// applyCopyIntegrity 为复制请求设置服务端加密和校验和参数
+// 复制时由服务端重新计算校验和
+// 参数:
+//   - params: 复制参数
+func (client Client) applyCopyIntegrity(params *s3.CopyObjectInput) {
+	if client.Config.ServerSideEncryption != "" {
+		params.ServerSideEncryption = aws.String(client.Config.ServerSideEncryption)
+	}
+	if client.Config.SSEKMSKeyId != "" {
+		params.SSEKMSKeyId = aws.String(client.Config.SSEKMSKeyId)
+	}
+	if client.Config.BucketKeyEnabled {
+		params.BucketKeyEnabled = aws.Bool(true)
+	}
+	if client.Config.ChecksumAlgorithm != "" {
+		params.ChecksumAlgorithm = aws.String(strings.ToUpper(client.Config.ChecksumAlgorithm))
+	}
+}
+
+// applyUploadIntegrity 为上传管理器的分片上传设置服务端加密和校验和算法
+// 分片的校验和由SDK在上传时计算
+func (client Client) applyUploadIntegrity(input *s3manager.UploadInput) {
+	if client.Config.ServerSideEncryption != "" {
+		input.ServerSideEncryption = aws.String(client.Config.ServerSideEncryption)
+	}
+	if client.Config.SSEKMSKeyId != "" {
+		input.SSEKMSKeyId = aws.String(client.Config.SSEKMSKeyId)
+	}
+	if client.Config.BucketKeyEnabled {
+		input.BucketKeyEnabled = aws.Bool(true)
+	}
+	if client.Config.ChecksumAlgorithm != "" {
+		input.ChecksumAlgorithm = aws.String(strings.ToUpper(client.Config.ChecksumAlgorithm))
+	}
+}
+
+// applyContentChecksum 为上传请求设置调用方要求的校验和
+// 调用方要求的校验和优先于客户端配置的校验和算法
+// 参数:
+//   - params: 上传参数
+//   - opts: 上传选项
+//   - data: 文件内容
+// 返回:
+//   - error: 错误信息
+func applyContentChecksum(params *s3.PutObjectInput, opts *oss.PutOptions, data []byte) error {
+	checksum, err := opts.ContentChecksum(data)
+	if err != nil || checksum == "" {
+		return err
+	}
+
+	switch opts.Checksum {
+	case oss.ChecksumMD5:
+		params.ContentMD5 = aws.String(checksum)
+	case oss.ChecksumSHA256:
+		params.ChecksumAlgorithm = aws.String(s3.ChecksumAlgorithmSha256)
+		params.ChecksumCRC32, params.ChecksumCRC32C, params.ChecksumSHA1 = nil, nil, nil
+		params.ChecksumSHA256 = aws.String(checksum)
+	}
+	return nil
+}
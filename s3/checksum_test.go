@@ -0,0 +1,57 @@
+package s3
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/smart-unicom/oss"
+)
+
+func TestComputeChecksum(t *testing.T) {
+	crc := func(value uint32) string {
+		return base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, value))
+	}
+
+	checksums := map[string]string{
+		s3.ChecksumAlgorithmCrc32:  crc(0xCBF43926),
+		s3.ChecksumAlgorithmCrc32c: crc(0xE3069283),
+		s3.ChecksumAlgorithmSha1:   "98O8HYCOBHMq32eZZczDTKeuNEE=",
+		s3.ChecksumAlgorithmSha256: "FeKw08M4keuw8e9gnsQZQgwg4yDOlMZfvIwzEkSOsiU=",
+	}
+
+	for algorithm, expected := range checksums {
+		if checksum, err := computeChecksum(algorithm, []byte("123456789")); err != nil || checksum != expected {
+			t.Errorf("%v checksum should be %v, but got %v, %v", algorithm, expected, checksum, err)
+		}
+	}
+
+	if _, err := computeChecksum("MD4", []byte("123456789")); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Should return ErrNotSupported for unknown algorithm, but got %v", err)
+	}
+}
+
+func TestApplyPutIntegrity(t *testing.T) {
+	client := Client{Config: &Config{
+		ServerSideEncryption: s3.ServerSideEncryptionAwsKms,
+		SSEKMSKeyId:          "key-id",
+		BucketKeyEnabled:     true,
+		ChecksumAlgorithm:    "crc32c",
+	}}
+
+	params := &s3.PutObjectInput{}
+	if err := client.applyPutIntegrity(params, []byte("123456789")); err != nil {
+		t.Fatalf("No error should happen when apply integrity options, but got %v", err)
+	}
+
+	if aws.StringValue(params.ServerSideEncryption) != s3.ServerSideEncryptionAwsKms || aws.StringValue(params.SSEKMSKeyId) != "key-id" || !aws.BoolValue(params.BucketKeyEnabled) {
+		t.Errorf("Should set SSE-KMS with bucket key, but got %v", params)
+	}
+
+	if aws.StringValue(params.ChecksumAlgorithm) != s3.ChecksumAlgorithmCrc32c || aws.StringValue(params.ChecksumCRC32C) != "4waSgw==" {
+		t.Errorf("Should set CRC32C checksum, but got %v", params)
+	}
+}
@@ -0,0 +1,63 @@
+package s3
+
+import (
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// usesRole 是否通过STS扮演角色获取凭据
+func usesRole(config *Config) bool {
+	return config.RoleARN != "" || config.WebIdentityTokenFile != ""
+}
+
+// roleCredentials 通过STS扮演角色获取凭据
+// 配置了 WebIdentityTokenFile 时使用 AssumeRoleWithWebIdentity 扮演 RoleARN（为空时使用 AWS_ROLE_ARN 环境变量），
+// 用于EKS的IRSA和其他OIDC联合身份；否则以会话的默认凭据使用 AssumeRole 扮演 RoleARN。
+// 之后按 RoleChain 的顺序，以上一个角色的凭据依次扮演下一个角色
+// 参数:
+//   - sess: 发送STS请求使用的会话
+//   - config: S3配置信息
+// 返回:
+//   - *credentials.Credentials: 最后一个角色的凭据，过期前由SDK自动刷新
+func roleCredentials(sess *session.Session, config *Config) *credentials.Credentials {
+	var creds *credentials.Credentials
+	if config.WebIdentityTokenFile != "" {
+		roleARN := config.RoleARN
+		if roleARN == "" {
+			roleARN = os.Getenv("AWS_ROLE_ARN")
+		}
+		provider := stscreds.NewWebIdentityRoleProviderWithOptions(sts.New(sess), roleARN, config.RoleSessionName, stscreds.FetchTokenPath(config.WebIdentityTokenFile), func(provider *stscreds.WebIdentityRoleProvider) {
+			provider.Duration = config.RoleDuration
+		})
+		creds = credentials.NewCredentials(provider)
+	} else {
+		creds = stscreds.NewCredentials(sess, config.RoleARN, assumeRoleOptions(config, config.RoleDuration))
+	}
+
+	for _, roleARN := range config.RoleChain {
+		// 角色链中的会话有效期最长为1小时
+		duration := config.RoleDuration
+		if duration > time.Hour {
+			duration = time.Hour
+		}
+		creds = stscreds.NewCredentials(sess.Copy(&aws.Config{Credentials: creds}), roleARN, assumeRoleOptions(config, duration))
+	}
+	return creds
+}
+
+// assumeRoleOptions 返回设置会话名称、有效期和外部ID的 AssumeRole 选项
+func assumeRoleOptions(config *Config, duration time.Duration) func(*stscreds.AssumeRoleProvider) {
+	return func(provider *stscreds.AssumeRoleProvider) {
+		provider.RoleSessionName = config.RoleSessionName
+		provider.Duration = duration
+		if config.RoleExternalID != "" {
+			provider.ExternalID = aws.String(config.RoleExternalID)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package s3
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+func TestRoleCredentials(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		action, accessKey := r.Form.Get("Action"), "anonymous"
+		if authorization := r.Header.Get("Authorization"); strings.Contains(authorization, "Credential=") {
+			accessKey = strings.SplitN(strings.SplitN(authorization, "Credential=", 2)[1], "/", 2)[0]
+		}
+		requests = append(requests, fmt.Sprintf("%v %v %v %v %v %v", action, r.Form.Get("RoleArn"), r.Form.Get("RoleSessionName"), r.Form.Get("DurationSeconds"), r.Form.Get("WebIdentityToken")+r.Form.Get("ExternalId"), accessKey))
+		fmt.Fprintf(w, `<%[1]vResponse><%[1]vResult><Credentials><AccessKeyId>AKID%[2]v</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken><Expiration>2030-01-01T00:00:00Z</Expiration></Credentials></%[1]vResult></%[1]vResponse>`, action, len(requests))
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(tokenFile, []byte("header.payload.signature"), 0600)
+	sess := session.Must(session.NewSession(&aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("BASE", "secret", ""),
+	}))
+
+	creds := roleCredentials(sess, &Config{
+		RoleARN:              "arn:aws:iam::1:role/pod",
+		WebIdentityTokenFile: tokenFile,
+		RoleSessionName:      "uploader",
+		RoleDuration:         2 * time.Hour,
+		RoleExternalID:       "ext",
+		RoleChain:            []string{"arn:aws:iam::2:role/bucket"},
+	})
+	value, err := creds.Get()
+	if err != nil || value.AccessKeyID != "AKID2" {
+		t.Fatalf("Chained role credentials should be returned, but got %v, %v", value.AccessKeyID, err)
+	}
+	expected := []string{
+		"AssumeRoleWithWebIdentity arn:aws:iam::1:role/pod uploader 7200 header.payload.signature anonymous",
+		"AssumeRole arn:aws:iam::2:role/bucket uploader 3600 ext AKID1",
+	}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Roles should be assumed in order with the previous credentials, but got\n%v", strings.Join(requests, "\n"))
+	}
+
+	requests = nil
+	if value, err := roleCredentials(sess, &Config{RoleARN: "arn:aws:iam::1:role/app"}).Get(); err != nil || value.AccessKeyID != "AKID1" {
+		t.Fatalf("Role should be assumed with the session credentials, but got %v, %v", value.AccessKeyID, err)
+	}
+	if len(requests) != 1 || !strings.HasPrefix(requests[0], "AssumeRole arn:aws:iam::1:role/app ") || !strings.HasSuffix(requests[0], " BASE") {
+		t.Errorf("AssumeRole should be signed with the session credentials, but got %v", requests)
+	}
+}
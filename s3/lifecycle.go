@@ -0,0 +1,114 @@
+package s3
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/smart-unicom/oss"
+)
+
+// PutLifecycleRule 添加或替换存储桶的生命周期规则
+// S3只能整体覆盖生命周期配置，先读取现有规则再写回
+// 参数:
+//   - rule: 生命周期规则
+// 返回:
+//   - error: 错误信息
+func (client Client) PutLifecycleRule(rule oss.LifecycleRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	rules, err := client.lifecycleRules()
+	if err != nil {
+		return err
+	}
+
+	filter := &s3.LifecycleRuleFilter{}
+	prefix := ""
+	if rule.Prefix != "" {
+		prefix = strings.TrimPrefix(client.ToRelativePath(rule.Prefix), "/")
+	}
+	switch {
+	case len(rule.Tags) == 0:
+		filter.Prefix = aws.String(prefix)
+	case len(rule.Tags) == 1 && prefix == "":
+		for key, value := range rule.Tags {
+			filter.Tag = &s3.Tag{Key: aws.String(key), Value: aws.String(value)}
+		}
+	default:
+		and := &s3.LifecycleRuleAndOperator{Prefix: aws.String(prefix)}
+		for _, key := range oss.SortedTagKeys(rule.Tags) {
+			and.Tags = append(and.Tags, &s3.Tag{Key: aws.String(key), Value: aws.String(rule.Tags[key])})
+		}
+		filter.And = and
+	}
+
+	// 替换相同ID的规则
+	newRules := []*s3.LifecycleRule{{
+		ID:         aws.String(rule.ID),
+		Status:     aws.String(s3.ExpirationStatusEnabled),
+		Filter:     filter,
+		Expiration: &s3.LifecycleExpiration{Days: aws.Int64(int64(rule.ExpirationDays))},
+	}}
+	for _, existing := range rules {
+		if aws.StringValue(existing.ID) != rule.ID {
+			newRules = append(newRules, existing)
+		}
+	}
+	return client.putLifecycleRules(newRules)
+}
+
+// DeleteLifecycleRule 删除存储桶中指定ID的生命周期规则
+// 参数:
+//   - id: 规则ID
+// 返回:
+//   - error: 错误信息
+func (client Client) DeleteLifecycleRule(id string) error {
+	rules, err := client.lifecycleRules()
+	if err != nil {
+		return err
+	}
+
+	var newRules []*s3.LifecycleRule
+	for _, existing := range rules {
+		if aws.StringValue(existing.ID) != id {
+			newRules = append(newRules, existing)
+		}
+	}
+	if len(newRules) == len(rules) {
+		return nil
+	}
+	return client.putLifecycleRules(newRules)
+}
+
+// lifecycleRules 读取存储桶现有的生命周期规则，未配置时返回空列表
+func (client Client) lifecycleRules() ([]*s3.LifecycleRule, error) {
+	output, err := client.S3.GetBucketLifecycleConfiguration(&s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(client.Config.Bucket),
+	})
+	var awsError awserr.Error
+	if errors.As(err, &awsError) && awsError.Code() == "NoSuchLifecycleConfiguration" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return output.Rules, nil
+}
+
+// putLifecycleRules 覆盖存储桶的生命周期规则，规则为空时删除生命周期配置
+func (client Client) putLifecycleRules(rules []*s3.LifecycleRule) error {
+	if len(rules) == 0 {
+		_, err := client.S3.DeleteBucketLifecycle(&s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(client.Config.Bucket),
+		})
+		return wrapError(err)
+	}
+	_, err := client.S3.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(client.Config.Bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	})
+	return wrapError(err)
+}
@@ -0,0 +1,163 @@
+package s3
+
+import (
+	"io"
+	"mime"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/smart-unicom/oss"
+)
+
+// InitiateMultipart 初始化分片上传
+// 参数:
+//   - urlPath: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(urlPath string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
+	key := client.ToRelativePath(urlPath)
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(strings.TrimPrefix(key, "/"), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	// 对象级ACL优先于客户端配置
+	acl := client.Config.ACL
+	if opts.ACL != "" {
+		acl = string(opts.ACL)
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(key))
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(key),
+		ACL:    aws.String(acl),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if opts.CacheControl != "" {
+		input.CacheControl = aws.String(opts.CacheControl)
+	} else if client.Config.CacheControl != "" {
+		input.CacheControl = aws.String(client.Config.CacheControl)
+	}
+	if opts.ContentDisposition != "" {
+		input.ContentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if len(opts.Metadata) > 0 {
+		input.Metadata = aws.StringMap(opts.Metadata)
+	}
+	// 分片上传只设置服务端加密，不设置对象级校验和
+	if client.Config.ServerSideEncryption != "" {
+		input.ServerSideEncryption = aws.String(client.Config.ServerSideEncryption)
+	}
+	if client.Config.SSEKMSKeyId != "" {
+		input.SSEKMSKeyId = aws.String(client.Config.SSEKMSKeyId)
+	}
+	if client.Config.BucketKeyEnabled {
+		input.BucketKeyEnabled = aws.Bool(true)
+	}
+
+	output, err := client.S3.CreateMultipartUpload(input)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.MultipartUpload{Path: key, UploadID: aws.StringValue(output.UploadId), Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+// 返回:
+//   - *oss.Part: 已上传的分片
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	// SDK签名时需要重读分片内容
+	body, err := oss.SeekablePart(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.S3.UploadPart(&s3.UploadPartInput{
+		Bucket:        aws.String(client.Config.Bucket),
+		Key:           aws.String(upload.Path),
+		UploadId:      aws.String(upload.UploadID),
+		PartNumber:    aws.Int64(int64(number)),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: number, ETag: aws.StringValue(output.ETag), Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号合并分片，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	oss.SortParts(parts)
+	completed := make([]*s3.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completed = append(completed, &s3.CompletedPart{
+			PartNumber: aws.Int64(int64(part.Number)),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err := client.S3.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(client.Config.Bucket),
+		Key:             aws.String(upload.Path),
+		UploadId:        aws.String(upload.UploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(upload.Path); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: upload.Path, Name: filepath.Base(upload.Path), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传并释放已上传的分片
+// 参数:
+//   - upload: 分片上传会话
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	_, err := client.S3.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(client.Config.Bucket),
+		Key:      aws.String(upload.Path),
+		UploadId: aws.String(upload.UploadID),
+	})
+	return wrapError(err)
+}
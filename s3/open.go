@@ -0,0 +1,67 @@
+package s3
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+func init() {
+	oss.Register("s3", open)
+}
+
+// open 根据连接字符串创建S3客户端
+// 格式为 s3://AccessId:AccessKey@bucket?region=us-east-1&acl=private，未提供访问密钥时使用AWS默认凭据
+// 参数:
+//   - dsn: 解析后的连接字符串
+// 返回:
+//   - oss.StorageInterface: S3存储客户端
+//   - error: 错误信息
+func open(dsn url.URL) (oss.StorageInterface, error) {
+	if dsn.Host == "" {
+		return nil, fmt.Errorf("s3: dsn %s has no bucket", dsn.Redacted())
+	}
+	accessKey, _ := dsn.User.Password()
+	params := oss.NewDSNParams(dsn)
+	config := &Config{
+		AccessId:             dsn.User.Username(),
+		AccessKey:            accessKey,
+		Bucket:               dsn.Host,
+		Region:               params.Get("region"),
+		ACL:                  params.Get("acl"),
+		Endpoint:             params.Get("endpoint"),
+		S3Endpoint:           params.Get("s3_endpoint"),
+		S3ForcePathStyle:     params.Bool("path_style"),
+		SessionToken:         params.Get("session_token"),
+		CacheControl:         params.Get("cache_control"),
+		SignatureVersion:     params.Get("signature_version"),
+		DisableSSL:           params.Bool("disable_ssl"),
+		Vendor:               params.Get("vendor"),
+		Namespace:            params.Get("namespace"),
+		ServerSideEncryption: params.Get("sse"),
+		SSEKMSKeyId:          params.Get("kms_key_id"),
+		BucketKeyEnabled:     params.Bool("bucket_key"),
+		ChecksumAlgorithm:    params.Get("checksum"),
+		RoleARN:              params.Get("role_arn"),
+		WebIdentityTokenFile: params.Get("web_identity_token_file"),
+		RoleSessionName:      params.Get("role_session_name"),
+		RoleExternalID:       params.Get("external_id"),
+	}
+	if duration := params.Get("role_duration"); duration != "" {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("s3: dsn parameter role_duration: invalid duration %q", duration)
+		}
+		config.RoleDuration = parsed
+	}
+	if chain := params.Get("role_chain"); chain != "" {
+		config.RoleChain = strings.Split(chain, ",")
+	}
+	if err := params.Err(); err != nil {
+		return nil, err
+	}
+	return New(config)
+}
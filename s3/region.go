@@ -0,0 +1,22 @@
+package s3
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// BucketRegion 查询存储桶所在的区域
+// 返回:
+//   - string: 区域名，例如 eu-west-1，未设置位置约束的存储桶为 us-east-1
+//   - error: 错误信息
+func (client Client) BucketRegion() (string, error) {
+	output, err := client.S3.GetBucketLocation(&s3.GetBucketLocationInput{
+		Bucket: aws.String(client.Config.Bucket),
+	})
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return strings.ToLower(s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint))), nil
+}
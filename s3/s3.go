@@ -4,6 +4,7 @@ package s3
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,12 +19,15 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/smart-unicom/oss"
 )
 
@@ -48,9 +52,28 @@ type Config struct {
 	S3ForcePathStyle bool              // 是否强制使用路径样式
 	CacheControl     string            // 缓存控制
 
+	SignatureVersion string // 签名版本：v4（默认）或 v2，v2只用于不支持v4的旧版兼容存储
+	DisableSSL       bool   // 是否使用HTTP访问默认端点和厂商预设的端点
+	Vendor           string // 兼容存储的厂商预设：wasabi、scaleway、oci，根据区域补全S3端点和路径样式
+	Namespace        string // Oracle云对象存储的命名空间，Vendor 为 oci 时必填
+
+	ServerSideEncryption string // 服务端加密方式，例如 AES256 或 aws:kms
+	SSEKMSKeyId          string // SSE-KMS使用的KMS密钥ID
+	BucketKeyEnabled     bool   // 是否为SSE-KMS启用S3 Bucket Key，以减少KMS请求费用
+	ChecksumAlgorithm    string // 上传时附带的校验和算法：CRC32、CRC32C、SHA1、SHA256
+
 	Session *session.Session          // AWS会话
 
 	RoleARN string                    // IAM角色ARN
+
+	WebIdentityTokenFile string        // Web身份令牌文件，设置后通过 AssumeRoleWithWebIdentity 扮演 RoleARN，EKS的IRSA中为 AWS_WEB_IDENTITY_TOKEN_FILE 环境变量的值
+	RoleSessionName      string        // 扮演角色的会话名称，会记录在CloudTrail中，为空时由SDK生成
+	RoleDuration         time.Duration // 扮演角色的会话有效期，为0时使用SDK默认值，角色链中最长1小时
+	RoleExternalID       string        // 扮演 RoleARN 和 RoleChain 中的角色时附带的外部ID
+	RoleChain            []string      // 扮演 RoleARN 之后依次扮演的角色ARN，用于跨账户访问
+
+	HTTPClient *http.Client     // 发送请求使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用SDK默认客户端
+	Retry      *oss.RetryConfig // 重试配置，转换为SDK重试器，为nil时使用SDK默认重试策略
 }
 
 // ec2RoleAwsCreds 获取EC2角色的AWS凭据
@@ -71,6 +94,42 @@ func ec2RoleAwsCreds(config *Config) *credentials.Credentials {
 	})
 }
 
+// retryer 按 oss.RetryConfig 重试的SDK重试器
+// 在SDK默认可重试的错误之外，额外重试配置中的HTTP状态码
+type retryer struct {
+	awsclient.DefaultRetryer
+	config *oss.RetryConfig
+}
+
+// newRetryer 根据重试配置创建SDK重试器
+// 参数:
+//   - config: 重试配置
+// 返回:
+//   - request.Retryer: SDK重试器，config为nil时返回nil，使用SDK默认重试策略
+func newRetryer(config *oss.RetryConfig) request.Retryer {
+	if config == nil {
+		return nil
+	}
+	return retryer{
+		DefaultRetryer: awsclient.DefaultRetryer{
+			NumMaxRetries:    config.Attempts() - 1,
+			MinRetryDelay:    config.InitialDelay(),
+			MaxRetryDelay:    config.MaxDelay(),
+			MinThrottleDelay: config.InitialDelay(),
+			MaxThrottleDelay: config.MaxDelay(),
+		},
+		config: config,
+	}
+}
+
+// ShouldRetry 判断请求是否需要重试
+func (r retryer) ShouldRetry(req *request.Request) bool {
+	if req.HTTPResponse != nil && r.config.Retryable(req.HTTPResponse.StatusCode) {
+		return true
+	}
+	return r.DefaultRetryer.ShouldRetry(req)
+}
+
 // EC2RoleAwsConfig 创建使用EC2角色的AWS配置
 // 参数:
 //   - config: S3配置信息
@@ -88,29 +147,47 @@ func EC2RoleAwsConfig(config *Config) *aws.Config {
 //   - config: S3配置信息
 // 返回:
 //   - *Client: S3存储客户端实例
-func New(config *Config) *Client {
+//   - error: 创建会话或获取凭据失败时返回错误
+func New(config *Config) (*Client, error) {
 	// 如果未设置ACL，使用默认的公共读取权限
 	if config.ACL == "" {
 		config.ACL = s3.BucketCannedACLPublicRead
 	}
 
+	if err := applyVendor(config); err != nil {
+		return nil, err
+	}
+
 	// 创建客户端实例
 	client := &Client{Config: config}
 
-	// 如果配置了IAM角色ARN，使用STS凭据
-	if config.RoleARN != "" {
-		sess := session.Must(session.NewSession())
-		creds := stscreds.NewCredentials(sess, config.RoleARN)
+	// 如果配置了IAM角色ARN或Web身份令牌，使用STS凭据
+	if usesRole(config) {
+		stsConfig := &aws.Config{HTTPClient: config.HTTPClient, Retryer: newRetryer(config.Retry)}
+		if config.Region != "" {
+			stsConfig.Region = aws.String(config.Region)
+		}
+		sess, err := session.NewSession(stsConfig)
+		if err != nil {
+			return nil, err
+		}
+		creds := roleCredentials(sess, config)
 
 		s3Config := &aws.Config{
 			Region:           &config.Region,
 			Endpoint:         &config.S3Endpoint,
 			S3ForcePathStyle: &config.S3ForcePathStyle,
+			DisableSSL:       aws.Bool(config.DisableSSL),
 			Credentials:      creds,
+			HTTPClient:       config.HTTPClient,
+			Retryer:          newRetryer(config.Retry),
 		}
 
 		client.S3 = s3.New(sess, s3Config)
-		return client
+		if err := useSignatureVersion(&client.S3.Handlers, config); err != nil {
+			return nil, err
+		}
+		return client, nil
 	}
 
 	// 创建基础S3配置
@@ -118,6 +195,9 @@ func New(config *Config) *Client {
 		Region:           &config.Region,
 		Endpoint:         &config.S3Endpoint,
 		S3ForcePathStyle: &config.S3ForcePathStyle,
+		DisableSSL:       aws.Bool(config.DisableSSL),
+		HTTPClient:       config.HTTPClient,
+		Retryer:          newRetryer(config.Retry),
 	}
 
 	// 根据不同的认证方式初始化S3客户端
@@ -126,17 +206,43 @@ func New(config *Config) *Client {
 		client.S3 = s3.New(config.Session, s3Config)
 	} else if config.AccessId == "" && config.AccessKey == "" {
 		// 使用AWS默认凭据
-		sess := session.Must(session.NewSession())
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, err
+		}
 		client.S3 = s3.New(sess, s3Config)
 	} else {
 		// 使用静态凭据
 		creds := credentials.NewStaticCredentials(config.AccessId, config.AccessKey, config.SessionToken)
-		if _, err := creds.Get(); err == nil {
-			s3Config.Credentials = creds
-			client.S3 = s3.New(session.New(), s3Config)
+		if _, err := creds.Get(); err != nil {
+			return nil, fmt.Errorf("s3: invalid static credentials: %w", err)
+		}
+		s3Config.Credentials = creds
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, err
 		}
+		client.S3 = s3.New(sess, s3Config)
 	}
 
+	if err := useSignatureVersion(&client.S3.Handlers, config); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// MustNew 初始化S3存储客户端，失败时panic
+// 参数:
+//   - config: S3配置信息
+// 返回:
+//   - *Client: S3存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		panic(err)
+	}
 	return client
 }
 
@@ -149,23 +255,11 @@ func New(config *Config) *Client {
 func (client Client) Get(path string) (file *os.File, err error) {
 	// 获取文件流
 	readCloser, err := client.GetStream(path)
-
-	// 根据文件扩展名生成临时文件模式
-	ext := filepath.Ext(path)
-	pattern := fmt.Sprintf("s3*%s", ext)
-
-	if err == nil {
-		// 创建临时文件并复制内容
-		if file, err = ioutil.TempFile("/tmp", pattern); err == nil {
-			defer readCloser.Close()
-			// 将流内容复制到临时文件
-			_, err = io.Copy(file, readCloser)
-			// 重置文件指针到开始位置
-			file.Seek(0, 0)
-		}
+	if err != nil {
+		return nil, err
 	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "s3*"+filepath.Ext(path))
 }
 
 // GetStream 获取指定路径文件的流
@@ -181,7 +275,75 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 		Key:    aws.String(client.ToRelativePath(path)),
 	})
 
-	return getResponse.Body, err
+	return getResponse.Body, wrapError(err)
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用HTTP Range请求只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	getResponse, err := client.S3.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToRelativePath(path)),
+		Range:  aws.String(oss.HTTPRange(offset, length)),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return getResponse.Body, nil
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 使用HEAD请求获取对象元数据
+	key := client.ToRelativePath(path)
+	headResponse, err := client.S3.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &oss.Object{
+		Path:             key,
+		Name:             filepath.Base(key),
+		LastModified:     oss.NormalizeTime(aws.TimeValue(headResponse.LastModified)),
+		Size:             aws.Int64Value(headResponse.ContentLength),
+		ContentType:      aws.StringValue(headResponse.ContentType),
+		ETag:             aws.StringValue(headResponse.ETag),
+		Metadata:         oss.NormalizeMetadata(aws.StringValueMap(headResponse.Metadata)),
+		StorageInterface: client,
+	}, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	_, err := client.Stat(path)
+	if errors.Is(err, oss.ErrObjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // Put 上传文件到指定路径
@@ -192,47 +354,163 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传成功后的对象信息
 //   - error: 错误信息
 func (client Client) Put(urlPath string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 文件路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传成功后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(urlPath)
+	opts = &normalized
+
 	// 如果reader支持Seek，重置到开始位置
 	if seeker, ok := reader.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
+	// 按冲突策略确定上传路径，CollisionError使用条件上传
+	if opts.Collision != oss.CollisionError {
+		resolved, err := opts.ResolvePath(client, urlPath)
+		if err != nil {
+			return nil, err
+		}
+		urlPath = resolved
+	}
+
 	// 转换为相对路径
 	urlPath = client.ToRelativePath(urlPath)
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(strings.TrimPrefix(urlPath, "/"), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
 	// 读取所有数据到缓冲区
 	buffer, err := ioutil.ReadAll(reader)
 
 	// 检测文件类型
-	fileType := mime.TypeByExtension(path.Ext(urlPath))
+	fileType := opts.ContentType
+	if fileType == "" {
+		fileType = mime.TypeByExtension(path.Ext(urlPath))
+	}
 	if fileType == "" {
 		fileType = http.DetectContentType(buffer)
 	}
 
+	// 对象级ACL优先于客户端配置
+	acl := client.Config.ACL
+	if opts.ACL != "" {
+		acl = string(opts.ACL)
+	}
+
 	// 构建上传参数
 	params := &s3.PutObjectInput{
 		Bucket:        aws.String(client.Config.Bucket), // 存储桶名称（必需）
 		Key:           aws.String(urlPath),              // 对象键（必需）
-		ACL:           aws.String(client.Config.ACL),    // 访问控制列表
+		ACL:           aws.String(acl),                  // 访问控制列表
 		Body:          bytes.NewReader(buffer),          // 文件内容
 		ContentLength: aws.Int64(int64(len(buffer))),    // 内容长度
 		ContentType:   aws.String(fileType),             // 内容类型
 	}
 	// 如果配置了缓存控制，添加到参数中
-	if client.Config.CacheControl != "" {
+	if opts.CacheControl != "" {
+		params.CacheControl = aws.String(opts.CacheControl)
+	} else if client.Config.CacheControl != "" {
 		params.CacheControl = aws.String(client.Config.CacheControl)
 	}
+	if opts.ContentDisposition != "" {
+		params.ContentDisposition = aws.String(opts.ContentDisposition)
+	}
+	if len(opts.Metadata) > 0 {
+		params.Metadata = aws.StringMap(opts.Metadata)
+	}
+	if len(opts.Tags) > 0 {
+		params.Tagging = aws.String(oss.EncodeTags(opts.Tags))
+	}
+	// 设置服务端加密和校验和
+	if err := client.applyPutIntegrity(params, buffer); err != nil {
+		return nil, err
+	}
+	if err := applyContentChecksum(params, opts, buffer); err != nil {
+		return nil, err
+	}
+
+	// 回调上传进度，bytes.Reader可寻址，包装后仍然满足 io.ReadSeeker
+	params.Body = opts.WrapReader(params.Body, int64(len(buffer))).(io.ReadSeeker)
 
-	// 执行上传操作
-	_, err = client.S3.PutObject(params)
+	// 执行上传操作，禁止覆盖时发送 If-None-Match: * 由服务端拒绝已存在的对象
+	request, _ := client.S3.PutObjectRequest(params)
+	if opts.Collision == oss.CollisionError {
+		request.HTTPRequest.Header.Set("If-None-Match", "*")
+	}
+	err = wrapError(request.Send())
 
 	// 创建返回对象
-	now := time.Now()
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, err
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if err == nil {
+		if stat, err := client.Stat(urlPath); err == nil {
+			object.LastModified = stat.LastModified
+			object.Size = stat.Size
+		}
+	}
+
+	return object, err
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 使用上传管理器分片上传，内存占用与分片大小相关而与对象大小无关
+// 参数:
+//   - urlPath: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(urlPath string) (io.WriteCloser, error) {
+	// 与 PutWithOptions 相同，规范化来自Windows的路径
+	urlPath, err := oss.NormalizePath(urlPath)
+	if err != nil {
+		return nil, err
+	}
+	urlPath = client.ToRelativePath(urlPath)
+	if err := oss.ValidateKeyLength(strings.TrimPrefix(urlPath, "/"), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	return oss.NewPipeWriter(func(reader io.Reader) error {
+		input := &s3manager.UploadInput{
+			Bucket: aws.String(client.Config.Bucket),
+			Key:    aws.String(urlPath),
+			ACL:    aws.String(client.Config.ACL),
+			Body:   reader,
+		}
+		if fileType := mime.TypeByExtension(path.Ext(urlPath)); fileType != "" {
+			input.ContentType = aws.String(fileType)
+		}
+		if client.Config.CacheControl != "" {
+			input.CacheControl = aws.String(client.Config.CacheControl)
+		}
+		client.applyUploadIntegrity(input)
+
+		_, err := s3manager.NewUploaderWithClient(client.S3).Upload(input)
+		return wrapError(err)
+	}), nil
 }
 
 // Delete 删除指定路径的文件
@@ -246,36 +524,120 @@ func (client Client) Delete(path string) error {
 		Bucket: aws.String(client.Config.Bucket),
 		Key:    aws.String(client.ToRelativePath(path)),
 	})
-	return err
+	return wrapError(err)
 }
 
 // DeleteObjects 批量删除多个文件
+// 每次请求最多删除 oss.MaxDeleteObjects 个文件，多批请求并发执行
 // 参数:
 //   - paths: 文件路径列表
 // 返回:
-//   - error: 错误信息
-func (client Client) DeleteObjects(paths []string) (err error) {
-	// 构建对象标识符列表
-	var objs []*s3.ObjectIdentifier
-	for _, v := range paths {
-		var obj s3.ObjectIdentifier
-		obj.Key = aws.String(strings.TrimPrefix(client.ToRelativePath(v), "/"))
-		objs = append(objs, &obj)
-	}
-	// 构建删除请求参数
-	input := &s3.DeleteObjectsInput{
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsInChunks(paths, oss.DefaultDeleteChunkConcurrency, func(chunk []string) map[string]error {
+		failures := map[string]error{}
+		// 构建对象标识符列表，记录对象键对应的原始路径
+		var objs []*s3.ObjectIdentifier
+		keys := map[string]string{}
+		for _, path := range chunk {
+			key := strings.TrimPrefix(client.ToRelativePath(path), "/")
+			keys[key] = path
+			objs = append(objs, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		// 执行批量删除操作，静默模式下只返回删除失败的对象
+		output, err := client.S3.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(client.Config.Bucket),
+			Delete: &s3.Delete{
+				Objects: objs,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			for _, path := range chunk {
+				failures[path] = wrapError(err)
+			}
+			return failures
+		}
+
+		for _, deleteError := range output.Errors {
+			failures[keys[aws.StringValue(deleteError.Key)]] = wrapError(awserr.New(aws.StringValue(deleteError.Code), aws.StringValue(deleteError.Message), nil))
+		}
+		return failures
+	})
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分页列举目录下的对象，每页使用批量删除接口删除
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(client.ToRelativePath(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	var deleteErr error
+	err := client.S3.ListObjectsV2Pages(&s3.ListObjectsV2Input{
 		Bucket: aws.String(client.Config.Bucket),
-		Delete: &s3.Delete{
-			Objects: objs,
-		},
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		var paths []string
+		for _, content := range page.Contents {
+			paths = append(paths, *content.Key)
+		}
+		deleteErr = client.DeleteObjects(paths)
+		return deleteErr == nil
+	})
+	if deleteErr != nil {
+		return deleteErr
 	}
+	return wrapError(err)
+}
 
-	// 执行批量删除操作
-	_, err = client.S3.DeleteObjects(input)
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
 	if err != nil {
-		return
+		return err
+	}
+	if err := oss.ValidateKeyLength(strings.TrimPrefix(client.ToRelativePath(dstPath), "/"), maxKeyBytes, 0); err != nil {
+		return err
 	}
-	return
+
+	// 复制源格式为 bucket/key，需要进行URL编码
+	copySource := (&url.URL{Path: client.Config.Bucket + "/" + strings.TrimPrefix(client.ToRelativePath(srcPath), "/")}).EscapedPath()
+
+	// 使用服务端复制
+	params := &s3.CopyObjectInput{
+		Bucket:     aws.String(client.Config.Bucket),
+		Key:        aws.String(client.ToRelativePath(dstPath)),
+		CopySource: aws.String(copySource),
+		ACL:        aws.String(client.Config.ACL),
+	}
+	client.applyCopyIntegrity(params)
+
+	_, err = client.S3.CopyObject(params)
+	return wrapError(err)
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 对象存储不支持重命名，通过服务端复制后删除源文件实现
+	return oss.MoveByCopy(client, srcPath, dstPath)
 }
 
 // List 列出指定路径下的所有对象
@@ -305,13 +667,68 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 			objects = append(objects, &oss.Object{
 				Path:             client.ToRelativePath(*content.Key),
 				Name:             filepath.Base(*content.Key),
-				LastModified:     content.LastModified,
+				LastModified:     oss.NormalizeTime(aws.TimeValue(content.LastModified)),
+				Size:             aws.Int64Value(content.Size),
+				ETag:             aws.StringValue(content.ETag),
+				StorageInterface: client,
+			})
+		}
+	}
+
+	return objects, wrapError(err)
+}
+
+// ListWithOptions 按选项列出指定路径下的对象
+// 非递归时使用分隔符列出，子目录作为公共前缀返回，分页直到取完或达到 MaxResults
+// 参数:
+//   - path: 路径前缀
+//   - opts: 列出选项
+// 返回:
+//   - *oss.ListResult: 列出结果
+//   - error: 错误信息
+func (client Client) ListWithOptions(path string, opts oss.ListOptions) (*oss.ListResult, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(client.Config.Bucket),
+		Prefix: aws.String(oss.DirPrefix(path)),
+	}
+	if !opts.Recursive {
+		input.Delimiter = aws.String("/")
+	}
+	// 没有过滤条件时返回的条目都会被保留，多取一个用于判断是否截断
+	filtered := opts.Glob != "" || opts.Pattern != nil
+
+	var (
+		objects  []*oss.Object
+		prefixes []string
+	)
+	err := client.S3.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, content := range page.Contents {
+			objects = append(objects, &oss.Object{
+				Path:             client.ToRelativePath(*content.Key),
+				Name:             filepath.Base(*content.Key),
+				LastModified:     oss.NormalizeTime(aws.TimeValue(content.LastModified)),
+				Size:             aws.Int64Value(content.Size),
+				ETag:             aws.StringValue(content.ETag),
 				StorageInterface: client,
 			})
 		}
+		for _, prefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.StringValue(prefix.Prefix))
+		}
+		return filtered || opts.MaxResults <= 0 || len(objects)+len(prefixes) <= opts.MaxResults
+	})
+	if err != nil {
+		return nil, wrapError(err)
 	}
+	return opts.Result(path, objects, prefixes), nil
+}
 
-	return objects, err
+// Underlying 返回AWS SDK的S3服务客户端，用于统一接口没有覆盖的功能
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - interface{}: *s3.S3
+func (client Client) Underlying() interface{} {
+	return client.S3
 }
 
 // GetEndpoint 获取存储服务的端点地址
@@ -330,6 +747,9 @@ func (client Client) GetEndpoint() string {
 	return client.Config.Bucket + "." + endpoint
 }
 
+// maxKeyBytes S3对象键的最大字节数
+const maxKeyBytes = 1024
+
 var urlRegexp = regexp.MustCompile(`(https?:)?//((\w+).)+(\w+)/`)
 
 // ToRelativePath 将路径转换为相对路径
@@ -362,14 +782,107 @@ func (client Client) ToRelativePath(urlPath string) string {
 func (client Client) GetURL(path string) (url string, err error) {
 	if client.Endpoint == "" {
 		if client.Config.ACL == s3.BucketCannedACLPrivate || client.Config.ACL == s3.BucketCannedACLAuthenticatedRead {
-			getResponse, _ := client.S3.GetObjectRequest(&s3.GetObjectInput{
-				Bucket: aws.String(client.Config.Bucket),
-				Key:    aws.String(client.ToRelativePath(path)),
-			})
-
-			return getResponse.Presign(1 * time.Hour)
+			return client.GetSignedURL(path, oss.SignedURLOptions{})
 		}
 	}
 
 	return path, nil
 }
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// 生成预签名上传URL
+	if opts.Method == http.MethodPut {
+		putRequest, _ := client.S3.PutObjectRequest(&s3.PutObjectInput{
+			Bucket: aws.String(client.Config.Bucket),
+			Key:    aws.String(client.ToRelativePath(path)),
+		})
+		return putRequest.Presign(opts.Expiry)
+	}
+
+	// 生成预签名下载URL
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToRelativePath(path)),
+	}
+	if opts.ResponseContentType != "" {
+		input.ResponseContentType = aws.String(opts.ResponseContentType)
+	}
+	if opts.ResponseContentDisposition != "" {
+		input.ResponseContentDisposition = aws.String(opts.ResponseContentDisposition)
+	}
+	if opts.ResponseCacheControl != "" {
+		input.ResponseCacheControl = aws.String(opts.ResponseCacheControl)
+	}
+	getRequest, _ := client.S3.GetObjectRequest(input)
+	return getRequest.Presign(opts.Expiry)
+}
+
+// GetUploadURL 生成预签名PUT直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(client.Config.Bucket),
+		Key:    aws.String(client.ToRelativePath(path)),
+	}
+	if opts.ContentType != "" {
+		input.ContentType = aws.String(opts.ContentType)
+	}
+
+	putRequest, _ := client.S3.PutObjectRequest(input)
+	signedURL, err := putRequest.Presign(opts.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oss.UploadURL{
+		URL:       signedURL,
+		Method:    http.MethodPut,
+		Headers:   opts.Headers(),
+		ExpiresAt: oss.Now().Add(opts.Expiry),
+	}, nil
+}
+
+// wrapError 将AWS S3的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var awsError awserr.Error
+	if !errors.As(err, &awsError) {
+		return err
+	}
+
+	switch awsError.Code() {
+	case s3.ErrCodeNoSuchKey, "NotFound":
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case s3.ErrCodeNoSuchBucket:
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case "AccessDenied", "Forbidden":
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return oss.WrapError(oss.ErrConflict, err)
+	case "BadDigest", "InvalidDigest", "XAmzContentSHA256Mismatch":
+		return oss.WrapError(oss.ErrChecksumMismatch, err)
+	}
+	return err
+}
@@ -26,7 +26,11 @@ var (
 func init() {
 	configor.Load(&config)
 
-	client = s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, Endpoint: config.Endpoint})
+	var err error
+	client, err = s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, Endpoint: config.Endpoint})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestAll(t *testing.T) {
@@ -34,11 +38,17 @@ func TestAll(t *testing.T) {
 	tests.TestAll(client, t)
 
 	fmt.Println("testing S3 with private ACL")
-	privateClient := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, ACL: awss3.BucketCannedACLPrivate, Endpoint: config.Endpoint})
+	privateClient, err := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, ACL: awss3.BucketCannedACLPrivate, Endpoint: config.Endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
 	tests.TestAll(privateClient, t)
 
 	fmt.Println("testing S3 with AuthenticatedRead ACL")
-	authenticatedReadClient := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, ACL: awss3.BucketCannedACLAuthenticatedRead, Endpoint: config.Endpoint})
+	authenticatedReadClient, err := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: config.Bucket, ACL: awss3.BucketCannedACLAuthenticatedRead, Endpoint: config.Endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
 	tests.TestAll(authenticatedReadClient, t)
 }
 
@@ -68,7 +78,10 @@ func TestToRelativePathWithS3ForcePathStyle(t *testing.T) {
 		"myobject.ext":                                   "/myobject.ext",
 	}
 
-	client := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: "mybucket", S3ForcePathStyle: true, Endpoint: config.Endpoint})
+	client, err := s3.New(&s3.Config{AccessId: config.AccessId, AccessKey: config.AccessKey, Region: config.Region, Bucket: "mybucket", S3ForcePathStyle: true, Endpoint: config.Endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	for url, path := range urlMap {
 		if client.ToRelativePath(url) != path {
@@ -0,0 +1,160 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/smart-unicom/oss"
+)
+
+// 签名版本
+const (
+	// SignatureV4 AWS签名版本4，默认值
+	SignatureV4 = "v4"
+	// SignatureV2 AWS签名版本2，只用于不支持版本4的旧版兼容存储
+	SignatureV2 = "v2"
+)
+
+// v2SubResources 签名版本2中需要加入规范化资源的查询参数
+var v2SubResources = map[string]bool{
+	"acl": true, "cors": true, "delete": true, "lifecycle": true, "location": true, "logging": true,
+	"notification": true, "partNumber": true, "policy": true, "requestPayment": true, "restore": true,
+	"tagging": true, "torrent": true, "uploadId": true, "uploads": true, "versionId": true,
+	"versioning": true, "versions": true, "website": true,
+	"response-cache-control": true, "response-content-disposition": true, "response-content-encoding": true,
+	"response-content-language": true, "response-content-type": true, "response-expires": true,
+}
+
+// useSignatureVersion 将S3客户端的签名处理器替换为配置的签名版本
+// 参数:
+//   - handlers: S3客户端的请求处理器
+//   - config: S3配置信息
+// 返回:
+//   - error: 签名版本不支持时的错误信息
+func useSignatureVersion(handlers *request.Handlers, config *Config) error {
+	switch strings.ToLower(config.SignatureVersion) {
+	case "", SignatureV4:
+		return nil
+	case SignatureV2:
+		handlers.Sign.Swap(v4.SignRequestHandler.Name, request.NamedHandler{
+			Name: "s3.SignV2RequestHandler",
+			Fn:   func(r *request.Request) { signV2Request(r, config.Bucket) },
+		})
+		return nil
+	default:
+		return fmt.Errorf("s3: unsupported signature version %q", config.SignatureVersion)
+	}
+}
+
+// signV2Request 使用签名版本2签名请求，预签名时将签名写入查询参数
+func signV2Request(r *request.Request, bucket string) {
+	creds, err := r.Config.Credentials.GetWithContext(r.Context())
+	if err != nil {
+		r.Error = err
+		return
+	}
+	signTime := r.Time
+	if signTime.IsZero() {
+		signTime = oss.Now()
+	}
+	req := r.HTTPRequest
+	if creds.SessionToken != "" {
+		if r.ExpireTime > 0 {
+			query := req.URL.Query()
+			query.Set("x-amz-security-token", creds.SessionToken)
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+		}
+	}
+
+	resource := v2Resource(req, bucket)
+	if r.ExpireTime > 0 {
+		expires := strconv.FormatInt(signTime.Add(r.ExpireTime).Unix(), 10)
+		query := req.URL.Query()
+		query.Set("AWSAccessKeyId", creds.AccessKeyID)
+		query.Set("Expires", expires)
+		query.Set("Signature", signV2(creds.SecretAccessKey, v2StringToSign(req, expires, resource)))
+		req.URL.RawQuery = query.Encode()
+		return
+	}
+
+	req.Header.Set("Date", signTime.UTC().Format(http.TimeFormat))
+	signature := signV2(creds.SecretAccessKey, v2StringToSign(req, req.Header.Get("Date"), resource))
+	req.Header.Set("Authorization", "AWS "+creds.AccessKeyID+":"+signature)
+}
+
+// v2Resource 返回签名版本2的规范化资源，虚拟主机样式的请求需要在路径前加上存储桶
+func v2Resource(req *http.Request, bucket string) string {
+	resource := req.URL.EscapedPath()
+	if resource == "" {
+		resource = "/"
+	}
+	if bucket != "" && strings.HasPrefix(req.URL.Host, bucket+".") {
+		resource = "/" + bucket + resource
+	}
+
+	query := req.URL.Query()
+	var keys []string
+	for key := range query {
+		if v2SubResources[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		separator := "&"
+		if i == 0 {
+			separator = "?"
+		}
+		resource += separator + key
+		if value := query.Get(key); value != "" {
+			resource += "=" + value
+		}
+	}
+	return resource
+}
+
+// v2StringToSign 返回签名版本2的待签名字符串
+// 参数:
+//   - req: HTTP请求
+//   - date: Date请求头，预签名时为过期时间的Unix时间戳
+//   - resource: 规范化资源
+// 返回:
+//   - string: 待签名字符串
+func v2StringToSign(req *http.Request, date, resource string) string {
+	var amzHeaders []string
+	for key, values := range req.Header {
+		key = strings.ToLower(key)
+		if strings.HasPrefix(key, "x-amz-") {
+			amzHeaders = append(amzHeaders, key+":"+strings.Join(values, ","))
+		}
+	}
+	sort.Strings(amzHeaders)
+
+	var builder strings.Builder
+	builder.WriteString(req.Method + "\n")
+	builder.WriteString(req.Header.Get("Content-MD5") + "\n")
+	builder.WriteString(req.Header.Get("Content-Type") + "\n")
+	builder.WriteString(date + "\n")
+	for _, header := range amzHeaders {
+		builder.WriteString(header + "\n")
+	}
+	builder.WriteString(resource)
+	return builder.String()
+}
+
+// signV2 使用HMAC-SHA1计算签名版本2的签名
+func signV2(secret, stringToSign string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
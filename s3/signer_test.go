@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/smart-unicom/oss"
+)
+
+func TestSignV2(t *testing.T) {
+	// AWS文档中签名版本2的示例
+	req, _ := http.NewRequest("GET", "https://johnsmith.s3.amazonaws.com/photos/puppy.jpg", nil)
+	date := "Tue, 27 Mar 2007 19:36:42 +0000"
+	stringToSign := v2StringToSign(req, date, v2Resource(req, "johnsmith"))
+	if stringToSign != "GET\n\n\n"+date+"\n/johnsmith/photos/puppy.jpg" {
+		t.Errorf("Virtual hosted request should sign the bucket in resource, but got %q", stringToSign)
+	}
+	if signature := signV2("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", stringToSign); signature != "bWq2s1WEIj+Ydj0vQ697zp+IXMU=" {
+		t.Errorf("Signature should match the documented example, but got %v", signature)
+	}
+
+	req, _ = http.NewRequest("PUT", "https://s3.amazonaws.com/bucket/a.txt?partNumber=2&uploadId=abc&x-id=UploadPart", nil)
+	req.Header.Set("X-Amz-Meta-B", "2")
+	req.Header.Set("x-amz-acl", "private")
+	if resource := v2Resource(req, "bucket"); resource != "/bucket/a.txt?partNumber=2&uploadId=abc" {
+		t.Errorf("Only sub-resources should be signed, but got %v", resource)
+	}
+	if stringToSign := v2StringToSign(req, "date", "/r"); stringToSign != "PUT\n\n\ndate\nx-amz-acl:private\nx-amz-meta-b:2\n/r" {
+		t.Errorf("Amz headers should be sorted and lower-cased, but got %q", stringToSign)
+	}
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	client, err := New(&Config{AccessId: "AKID", AccessKey: "secret", Region: "us-east-1", Bucket: "bucket", S3Endpoint: server.URL, S3ForcePathStyle: true, SignatureVersion: "v2"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := client.S3.PutObject(&s3.PutObjectInput{Bucket: aws.String("bucket"), Key: aws.String("a.txt"), Body: strings.NewReader("a")}); err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if !strings.HasPrefix(authorization, "AWS AKID:") {
+		t.Errorf("Request should be signed with v2, but got %v", authorization)
+	}
+	signedURL, err := client.GetSignedURL("/a.txt", oss.SignedURLOptions{})
+	if err != nil || !strings.Contains(signedURL, "AWSAccessKeyId=AKID") || !strings.Contains(signedURL, "Signature=") || strings.Contains(signedURL, "X-Amz-Signature") {
+		t.Errorf("Signed URL should use v2 query parameters, but got %v, %v", signedURL, err)
+	}
+
+	if _, err := New(&Config{AccessId: "AKID", AccessKey: "secret", SignatureVersion: "v3"}); err == nil {
+		t.Errorf("Unknown signature version should be rejected")
+	}
+}
+
+func TestVendor(t *testing.T) {
+	config := &Config{Vendor: VendorOCI, Region: "ap-tokyo-1", Namespace: "ns"}
+	if err := applyVendor(config); err != nil || config.S3Endpoint != "https://ns.compat.objectstorage.ap-tokyo-1.oraclecloud.com" || !config.S3ForcePathStyle {
+		t.Errorf("OCI preset should set endpoint and path style, but got %+v, %v", config, err)
+	}
+	config = &Config{Vendor: "Wasabi", DisableSSL: true}
+	if err := applyVendor(config); err != nil || config.Region != "us-east-1" || config.S3Endpoint != "http://s3.us-east-1.wasabisys.com" || config.S3ForcePathStyle {
+		t.Errorf("Wasabi preset should use the default region, but got %+v, %v", config, err)
+	}
+	config = &Config{Vendor: VendorScaleway, S3Endpoint: "https://custom"}
+	if err := applyVendor(config); err != nil || config.S3Endpoint != "https://custom" || config.Region != "fr-par" {
+		t.Errorf("Explicit endpoint should be kept, but got %+v, %v", config, err)
+	}
+	if err := applyVendor(&Config{Vendor: VendorOCI, Region: "ap-tokyo-1"}); err == nil {
+		t.Errorf("OCI preset without namespace should fail")
+	}
+	if err := applyVendor(&Config{Vendor: "unknown"}); err == nil {
+		t.Errorf("Unknown vendor should fail")
+	}
+}
@@ -0,0 +1,85 @@
+package s3
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 兼容S3协议的存储厂商
+const (
+	// VendorWasabi Wasabi热存储
+	VendorWasabi = "wasabi"
+	// VendorScaleway Scaleway对象存储
+	VendorScaleway = "scaleway"
+	// VendorOCI Oracle云对象存储的S3兼容接口
+	VendorOCI = "oci"
+)
+
+// vendorPreset 厂商预设的端点和兼容性设置
+type vendorPreset struct {
+	// region 未设置 Region 时使用的区域
+	region string
+	// endpoint 根据区域和命名空间生成S3端点的主机名
+	endpoint func(region, namespace string) (string, error)
+	// pathStyle 是否必须使用路径样式
+	pathStyle bool
+}
+
+// vendorPresets 已知厂商的预设
+var vendorPresets = map[string]vendorPreset{
+	VendorWasabi: {
+		region: "us-east-1",
+		endpoint: func(region, _ string) (string, error) {
+			return "s3." + region + ".wasabisys.com", nil
+		},
+	},
+	VendorScaleway: {
+		region: "fr-par",
+		endpoint: func(region, _ string) (string, error) {
+			return "s3." + region + ".scw.cloud", nil
+		},
+	},
+	VendorOCI: {
+		endpoint: func(region, namespace string) (string, error) {
+			if region == "" || namespace == "" {
+				return "", fmt.Errorf("s3: vendor %s requires region and namespace", VendorOCI)
+			}
+			return namespace + ".compat.objectstorage." + region + ".oraclecloud.com", nil
+		},
+		// OCI的S3兼容接口不支持虚拟主机样式
+		pathStyle: true,
+	},
+}
+
+// applyVendor 按 Vendor 补全区域、S3端点和路径样式，已经设置的字段保持不变
+// 参数:
+//   - config: S3配置信息
+// 返回:
+//   - error: 厂商未知或缺少必需的配置时的错误信息
+func applyVendor(config *Config) error {
+	if config.Vendor == "" {
+		return nil
+	}
+	preset, ok := vendorPresets[strings.ToLower(config.Vendor)]
+	if !ok {
+		return fmt.Errorf("s3: unknown vendor %q", config.Vendor)
+	}
+	if config.Region == "" {
+		config.Region = preset.region
+	}
+	if config.S3Endpoint == "" {
+		endpoint, err := preset.endpoint(config.Region, config.Namespace)
+		if err != nil {
+			return err
+		}
+		scheme := "https://"
+		if config.DisableSSL {
+			scheme = "http://"
+		}
+		config.S3Endpoint = scheme + endpoint
+	}
+	if preset.pathStyle {
+		config.S3ForcePathStyle = true
+	}
+	return nil
+}
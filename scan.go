@@ -0,0 +1,339 @@
+package oss
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	pathpkg "path"
+	"strings"
+	"time"
+)
+
+// ErrScanRejected 上传的内容被扫描器拒绝
+var ErrScanRejected = errors.New("oss: rejected by scanner")
+
+// ScanObject 交给扫描器检查的上传内容
+type ScanObject struct {
+	// Path 目标路径
+	Path string
+	// Size 内容大小
+	Size int64
+	// ContentType 上传选项中的内容类型，未指定时为空
+	ContentType string
+	// Reader 从头读取内容，每个扫描器得到一个新的读取器
+	Reader io.Reader
+}
+
+// Scanner 检查上传内容的扫描器
+// 返回错误时拒绝上传，扫描器本身不可用（例如连接ClamAV失败）时同样应返回错误
+type Scanner interface {
+	// Name 扫描器名称，记录在 ScanRejectedError 中
+	Name() string
+	// Scan 检查内容
+	// 参数:
+	//   - object: 上传内容
+	// 返回:
+	//   - error: 拒绝上传的原因
+	Scan(object *ScanObject) error
+}
+
+// ScannerFunc 将函数适配为扫描器
+// 参数:
+//   - name: 扫描器名称
+//   - scan: 检查内容的函数
+// 返回:
+//   - Scanner: 扫描器
+func ScannerFunc(name string, scan func(object *ScanObject) error) Scanner {
+	return scannerFunc{name: name, scan: scan}
+}
+
+// scannerFunc 函数扫描器
+type scannerFunc struct {
+	name string
+	scan func(object *ScanObject) error
+}
+
+// Name 返回扫描器名称
+func (scanner scannerFunc) Name() string { return scanner.name }
+
+// Scan 调用函数检查内容
+func (scanner scannerFunc) Scan(object *ScanObject) error { return scanner.scan(object) }
+
+// ScanRejectedError 上传内容被扫描器拒绝的错误
+// 可以用 errors.Is(err, ErrScanRejected) 判断，errors.Is 和 errors.As 也可以匹配扫描器返回的原因
+type ScanRejectedError struct {
+	// Path 目标路径
+	Path string
+	// Scanner 拒绝上传的扫描器名称
+	Scanner string
+	// Err 扫描器返回的原因
+	Err error
+}
+
+// Error 返回错误描述
+func (err *ScanRejectedError) Error() string {
+	return fmt.Sprintf("oss: %s rejected by %s: %v", err.Path, err.Scanner, err.Err)
+}
+
+// Is 使错误可以与 ErrScanRejected 比较
+func (err *ScanRejectedError) Is(target error) bool {
+	return target == ErrScanRejected
+}
+
+// Unwrap 返回扫描器返回的原因
+func (err *ScanRejectedError) Unwrap() error {
+	return err.Err
+}
+
+// HTTPStatus 被拒绝的上传对应 422 Unprocessable Entity
+func (err *ScanRejectedError) HTTPStatus() int {
+	return http.StatusUnprocessableEntity
+}
+
+// ScanStorage 上传前依次使用扫描器检查内容的存储包装器
+// 内容先写入临时文件，全部扫描器通过后才上传，任意扫描器拒绝时返回 *ScanRejectedError，内容不会写入存储；
+// Copy 和 Move 的内容已经在存储中，不会再次扫描
+type ScanStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Scanners 按顺序执行的扫描器
+	Scanners []Scanner
+}
+
+// WithScanners 创建上传前扫描内容的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - scanners: 按顺序执行的扫描器
+// 返回:
+//   - *ScanStorage: 存储包装器实例
+func WithScanners(storage StorageInterface, scanners ...Scanner) *ScanStorage {
+	return &ScanStorage{StorageInterface: storage, Scanners: scanners}
+}
+
+// Put 扫描通过后上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 被拒绝时返回 *ScanRejectedError
+func (storage *ScanStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return storage.upload(path, reader, nil)
+}
+
+// PutWithOptions 扫描通过后使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 被拒绝时返回 *ScanRejectedError
+func (storage *ScanStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	return storage.upload(path, reader, opts)
+}
+
+// upload 将内容写入临时文件，扫描通过后上传
+func (storage *ScanStorage) upload(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	file, err := CreateTempFile("oss-scan-*")
+	if err != nil {
+		return nil, err
+	}
+	defer RemoveTempFile(file)
+	if _, err := io.Copy(file, reader); err != nil {
+		return nil, err
+	}
+	return storage.scanAndPut(path, file, opts)
+}
+
+// scanAndPut 依次执行扫描器，全部通过后上传临时文件
+func (storage *ScanStorage) scanAndPut(path string, file *os.File, opts *PutOptions) (*Object, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	object := &ScanObject{Path: path, Size: info.Size()}
+	if opts != nil {
+		object.ContentType = opts.ContentType
+	}
+	for _, scanner := range storage.Scanners {
+		object.Reader = io.NewSectionReader(file, 0, info.Size())
+		if err := scanner.Scan(object); err != nil {
+			return nil, &ScanRejectedError{Path: path, Scanner: scanner.Name(), Err: err}
+		}
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		return storage.StorageInterface.Put(path, file)
+	}
+	return storage.StorageInterface.PutWithOptions(path, file, opts)
+}
+
+// NewWriter 创建流式写入器，内容写入临时文件，关闭时扫描通过后上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 在被拒绝时返回 *ScanRejectedError
+//   - error: 错误信息
+func (storage *ScanStorage) NewWriter(path string) (io.WriteCloser, error) {
+	file, err := CreateTempFile("oss-scan-*")
+	if err != nil {
+		return nil, err
+	}
+	return &scanWriter{File: file, storage: storage, path: path}, nil
+}
+
+// scanWriter 写入临时文件，关闭时扫描并上传
+type scanWriter struct {
+	*os.File
+	storage *ScanStorage
+	path    string
+	closed  bool
+}
+
+// Close 扫描通过后上传临时文件中的内容并删除临时文件
+func (writer *scanWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	defer RemoveTempFile(writer.File)
+	_, err := writer.storage.scanAndPut(writer.path, writer.File, nil)
+	return err
+}
+
+// MaxSizeScanner 拒绝超过指定大小的内容
+// 参数:
+//   - maxBytes: 允许的最大字节数
+// 返回:
+//   - Scanner: 扫描器，超过时返回的原因满足 errors.Is(err, ErrTooLarge)
+func MaxSizeScanner(maxBytes int64) Scanner {
+	return ScannerFunc("size", func(object *ScanObject) error {
+		if object.Size > maxBytes {
+			return fmt.Errorf("%w: %d bytes exceeds %d", ErrTooLarge, object.Size, maxBytes)
+		}
+		return nil
+	})
+}
+
+// ExtensionScanner 只允许指定扩展名的路径
+// 参数:
+//   - extensions: 允许的扩展名，例如 .jpg、.pdf，不区分大小写
+// 返回:
+//   - Scanner: 扫描器
+func ExtensionScanner(extensions ...string) Scanner {
+	allowed := map[string]bool{}
+	for _, extension := range extensions {
+		allowed[strings.ToLower(extension)] = true
+	}
+	return ScannerFunc("extension", func(object *ScanObject) error {
+		if extension := strings.ToLower(pathpkg.Ext(object.Path)); !allowed[extension] {
+			return fmt.Errorf("extension %q is not allowed", extension)
+		}
+		return nil
+	})
+}
+
+// MIMEScanner 根据内容开头的512字节嗅探内容类型，只允许指定的类型
+// 用于发现扩展名伪装成图片的可执行文件等内容
+// 参数:
+//   - types: 允许的内容类型，例如 image/png，以 / 结尾时匹配该大类，例如 image/
+// 返回:
+//   - Scanner: 扫描器
+func MIMEScanner(types ...string) Scanner {
+	return ScannerFunc("mime", func(object *ScanObject) error {
+		head := make([]byte, 512)
+		n, err := io.ReadFull(object.Reader, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		detected, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+		for _, allowed := range types {
+			if detected == allowed || strings.HasSuffix(allowed, "/") && strings.HasPrefix(detected, allowed) {
+				return nil
+			}
+		}
+		return fmt.Errorf("content type %s is not allowed", detected)
+	})
+}
+
+// ClamAVScanner 通过clamd的INSTREAM命令扫描内容
+type ClamAVScanner struct {
+	// Network 连接clamd的网络类型，为空时使用 tcp，本机的Unix套接字使用 unix
+	Network string
+	// Address clamd的地址，例如 127.0.0.1:3310 或 /run/clamav/clamd.ctl
+	Address string
+	// Timeout 一次扫描的超时时间，为0时不限制
+	Timeout time.Duration
+}
+
+// clamAVChunkSize 发送给clamd的数据块大小
+const clamAVChunkSize = 64 << 10
+
+// Name 返回扫描器名称
+func (scanner *ClamAVScanner) Name() string { return "clamav" }
+
+// Scan 将内容发送给clamd扫描
+// 参数:
+//   - object: 上传内容
+// 返回:
+//   - error: 发现病毒时返回包含病毒名称的错误，连接或协议出错时返回对应的错误
+func (scanner *ClamAVScanner) Scan(object *ScanObject) error {
+	network := scanner.Network
+	if network == "" {
+		network = "tcp"
+	}
+	conn, err := net.DialTimeout(network, scanner.Address, scanner.Timeout)
+	if err != nil {
+		return fmt.Errorf("clamav: %w", err)
+	}
+	defer conn.Close()
+	if scanner.Timeout > 0 {
+		conn.SetDeadline(Now().Add(scanner.Timeout))
+	}
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return fmt.Errorf("clamav: %w", err)
+	}
+	chunk := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, readErr := io.ReadFull(object.Reader, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk, uint32(n))
+			if _, err := conn.Write(chunk[:4+n]); err != nil {
+				return fmt.Errorf("clamav: %w", err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return fmt.Errorf("clamav: %w", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return fmt.Errorf("clamav: %w", err)
+	}
+	result := strings.TrimSpace(strings.TrimRight(string(reply), "\x00"))
+	result = strings.TrimPrefix(result, "stream: ")
+	switch {
+	case result == "OK":
+		return nil
+	case strings.HasSuffix(result, " FOUND"):
+		return fmt.Errorf("malware found: %s", strings.TrimSuffix(result, " FOUND"))
+	default:
+		return fmt.Errorf("clamav: %s", result)
+	}
+}
@@ -0,0 +1,99 @@
+package oss_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+// fakeClamd 按clamd的INSTREAM协议接收内容，内容包含 EICAR 时报告发现病毒
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			command := make([]byte, len("zINSTREAM\x00"))
+			io.ReadFull(conn, command)
+			var content bytes.Buffer
+			for {
+				var size uint32
+				if binary.Read(conn, binary.BigEndian, &size) != nil || size == 0 {
+					break
+				}
+				io.CopyN(&content, conn, int64(size))
+			}
+			if strings.Contains(content.String(), "EICAR") {
+				io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+			} else {
+				io.WriteString(conn, "stream: OK\x00")
+			}
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestScan(t *testing.T) {
+	mock := ossmock.New()
+	storage := oss.WithScanners(mock,
+		oss.MaxSizeScanner(16),
+		oss.ExtensionScanner(".txt", ".PNG"),
+		oss.MIMEScanner("text/", "image/png"),
+		&oss.ClamAVScanner{Address: fakeClamd(t)},
+	)
+
+	if _, err := storage.Put("/ok.txt", strings.NewReader("hello")); err != nil {
+		t.Fatalf("Clean content should be uploaded, but got %v", err)
+	}
+	if _, err := storage.PutWithOptions("/a.png", strings.NewReader("\x89PNG\r\n\x1a\n"), &oss.PutOptions{ContentType: "image/png"}); err != nil {
+		t.Errorf("Allowed content type should be uploaded, but got %v", err)
+	}
+
+	reject := func(path, content, scanner string) {
+		t.Helper()
+		_, err := storage.Put(path, strings.NewReader(content))
+		var rejected *oss.ScanRejectedError
+		if !errors.As(err, &rejected) || !errors.Is(err, oss.ErrScanRejected) || rejected.Scanner != scanner {
+			t.Errorf("%v should be rejected by %v, but got %v", path, scanner, err)
+		}
+		if exists, _ := mock.Exists(path); exists {
+			t.Errorf("Rejected %v should not be uploaded", path)
+		}
+	}
+	reject("/big.txt", strings.Repeat("x", 17), "size")
+	reject("/a.exe", "hello", "extension")
+	reject("/fake.png", "MZ\x90\x00 not png", "mime")
+	reject("/virus.txt", "EICAR test", "clamav")
+
+	writer, _ := storage.NewWriter("/writer.txt")
+	io.WriteString(writer, "EICAR")
+	if err := writer.Close(); !errors.Is(err, oss.ErrScanRejected) {
+		t.Errorf("Writer should be scanned on close, but got %v", err)
+	}
+	if _, err := storage.Put("/size.txt", strings.NewReader(strings.Repeat("x", 17))); !errors.Is(err, oss.ErrTooLarge) || oss.HTTPStatus(err) != 413 {
+		t.Errorf("Scanner reason should be matchable, but got %v", err)
+	}
+	if _, err := storage.Put("/a.exe", strings.NewReader("hello")); oss.HTTPStatus(err) != 422 {
+		t.Errorf("Rejected upload should map to 422, but got %v", oss.HTTPStatus(err))
+	}
+
+	unavailable := oss.WithScanners(mock, &oss.ClamAVScanner{Address: "127.0.0.1:1"})
+	if _, err := unavailable.Put("/down.txt", strings.NewReader("hello")); !errors.Is(err, oss.ErrScanRejected) {
+		t.Errorf("Unavailable scanner should reject the upload, but got %v", err)
+	}
+}
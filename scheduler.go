@@ -0,0 +1,389 @@
+package oss
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// DefaultSchedulerConcurrency 调度器默认的总并发数
+const DefaultSchedulerConcurrency = 16
+
+// Priority 传输任务的优先级，数值越大越优先
+type Priority int
+
+const (
+	// PriorityBackfill 回填、迁移等可以延后的后台任务
+	PriorityBackfill Priority = iota
+	// PriorityNormal 普通的后台任务，例如镜像复制
+	PriorityNormal
+	// PriorityInteractive 用户正在等待的上传和下载
+	PriorityInteractive
+)
+
+// String 返回优先级名称
+func (priority Priority) String() string {
+	switch priority {
+	case PriorityBackfill:
+		return "backfill"
+	case PriorityNormal:
+		return "normal"
+	case PriorityInteractive:
+		return "interactive"
+	}
+	return "priority(" + strconv.Itoa(int(priority)) + ")"
+}
+
+// SchedulerOptions 调度器配置
+type SchedulerOptions struct {
+	// MaxConcurrent 所有优先级同时进行的调用数上限，小于等于0时使用 DefaultSchedulerConcurrency
+	MaxConcurrent int
+	// ClassLimits 每个优先级同时进行的调用数上限，未设置或小于等于0时只受 MaxConcurrent 限制
+	// 例如限制回填任务最多占用2个并发，其余并发始终留给用户的上传
+	ClassLimits map[Priority]int
+}
+
+// Scheduler 在多个传输任务之间按优先级分配并发数的调度器
+// 有空闲并发数时总是先分配给等待中的最高优先级调用，同一优先级按等待顺序分配；
+// 正在进行的低优先级调用不会被中断，但在高优先级调用因总并发数已满而等待时不会开始新的低优先级调用。
+// 迁移、镜像复制和用户上传使用同一个调度器时，回填任务不会延迟用户的上传
+type Scheduler struct {
+	// Options 调度器配置
+	Options SchedulerOptions
+
+	mu      sync.Mutex
+	running map[Priority]int
+	total   int
+	waiting map[Priority][]chan struct{}
+}
+
+// NewScheduler 创建按优先级分配并发数的调度器
+// 参数:
+//   - opts: 调度器配置
+// 返回:
+//   - *Scheduler: 调度器实例
+func NewScheduler(opts SchedulerOptions) *Scheduler {
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = DefaultSchedulerConcurrency
+	}
+	return &Scheduler{Options: opts, running: map[Priority]int{}, waiting: map[Priority][]chan struct{}{}}
+}
+
+// Acquire 等待调度器分配一个并发数
+// 参数:
+//   - priority: 调用的优先级
+// 返回:
+//   - func(): 调用结束时释放并发数，可以多次调用
+func (scheduler *Scheduler) Acquire(priority Priority) func() {
+	scheduler.mu.Lock()
+	// 释放并发数时总是先唤醒等待中的调用，有空闲时不会有同一优先级的调用在等待
+	if scheduler.available(priority) {
+		scheduler.start(priority)
+		scheduler.mu.Unlock()
+	} else {
+		ready := make(chan struct{})
+		scheduler.waiting[priority] = append(scheduler.waiting[priority], ready)
+		scheduler.mu.Unlock()
+		<-ready
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { scheduler.release(priority) })
+	}
+}
+
+// Do 在调度器分配的并发数内执行函数
+// 参数:
+//   - priority: 调用的优先级
+//   - fn: 需要执行的函数
+func (scheduler *Scheduler) Do(priority Priority, fn func()) {
+	release := scheduler.Acquire(priority)
+	defer release()
+	fn()
+}
+
+// Running 返回指定优先级正在进行的调用数
+// 参数:
+//   - priority: 优先级
+// 返回:
+//   - int: 正在进行的调用数
+func (scheduler *Scheduler) Running(priority Priority) int {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	return scheduler.running[priority]
+}
+
+// Waiting 返回指定优先级等待中的调用数
+// 参数:
+//   - priority: 优先级
+// 返回:
+//   - int: 等待中的调用数
+func (scheduler *Scheduler) Waiting(priority Priority) int {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	return len(scheduler.waiting[priority])
+}
+
+// available 判断总并发数和优先级的并发数是否还有空闲，调用方需要持有锁
+func (scheduler *Scheduler) available(priority Priority) bool {
+	if scheduler.total >= scheduler.Options.MaxConcurrent {
+		return false
+	}
+	limit := scheduler.Options.ClassLimits[priority]
+	return limit <= 0 || scheduler.running[priority] < limit
+}
+
+// start 记录开始的调用，调用方需要持有锁
+func (scheduler *Scheduler) start(priority Priority) {
+	scheduler.running[priority]++
+	scheduler.total++
+}
+
+// release 释放并发数并按优先级唤醒等待中的调用
+func (scheduler *Scheduler) release(priority Priority) {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	scheduler.running[priority]--
+	scheduler.total--
+
+	for {
+		next, ok := scheduler.next()
+		if !ok {
+			return
+		}
+		ready := scheduler.waiting[next][0]
+		scheduler.waiting[next] = scheduler.waiting[next][1:]
+		scheduler.start(next)
+		close(ready)
+	}
+}
+
+// next 返回可以开始的最高优先级，调用方需要持有锁
+func (scheduler *Scheduler) next() (Priority, bool) {
+	var (
+		best  Priority
+		found bool
+	)
+	for priority, queue := range scheduler.waiting {
+		if len(queue) > 0 && scheduler.available(priority) && (!found || priority > best) {
+			best, found = priority, true
+		}
+	}
+	return best, found
+}
+
+// ScheduledStorage 通过调度器分配并发数的存储包装器
+// 同一个调度器可以被多个包装器共享，例如迁移任务使用 PriorityBackfill，用户上传使用 PriorityInteractive。
+// GetStream、GetStreamRange 和 NewWriter 占用的并发数在关闭流时才释放，调用方必须关闭返回的流；
+// GetURL 等只在本地计算的方法不经过调度器。
+// 迁移时只应包装源存储和目标存储中的一个，两者都包装时每个对象同时占用两个并发数，并发数用完后会互相等待
+type ScheduledStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Scheduler 调度器
+	Scheduler *Scheduler
+	// Priority 通过该包装器的调用的优先级
+	Priority Priority
+}
+
+// WithPriority 创建通过调度器分配并发数的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - scheduler: 调度器
+//   - priority: 通过该包装器的调用的优先级
+// 返回:
+//   - *ScheduledStorage: 存储包装器实例
+func WithPriority(storage StorageInterface, scheduler *Scheduler, priority Priority) *ScheduledStorage {
+	return &ScheduledStorage{StorageInterface: storage, Scheduler: scheduler, Priority: priority}
+}
+
+// scheduled 在调度器分配的并发数内执行操作
+func scheduled[T any](storage *ScheduledStorage, fn func() (T, error)) (T, error) {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return fn()
+}
+
+// Get 在调度器分配的并发数内获取指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *ScheduledStorage) Get(path string) (*os.File, error) {
+	return scheduled(storage, func() (*os.File, error) {
+		return storage.StorageInterface.Get(path)
+	})
+}
+
+// GetStream 在调度器分配的并发数内获取文件流，关闭流时释放并发数
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息
+func (storage *ScheduledStorage) GetStream(path string) (io.ReadCloser, error) {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseReadCloser{ReadCloser: stream, release: release}, nil
+}
+
+// GetStreamRange 在调度器分配的并发数内范围读取文件流，关闭流时释放并发数
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *ScheduledStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	stream, err := storage.StorageInterface.GetStreamRange(path, offset, length)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseReadCloser{ReadCloser: stream, release: release}, nil
+}
+
+// Stat 在调度器分配的并发数内获取对象信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ScheduledStorage) Stat(path string) (*Object, error) {
+	return scheduled(storage, func() (*Object, error) {
+		return storage.StorageInterface.Stat(path)
+	})
+}
+
+// Exists 在调度器分配的并发数内检查对象是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *ScheduledStorage) Exists(path string) (bool, error) {
+	return scheduled(storage, func() (bool, error) {
+		return storage.StorageInterface.Exists(path)
+	})
+}
+
+// Put 在调度器分配的并发数内上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ScheduledStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return scheduled(storage, func() (*Object, error) {
+		return storage.StorageInterface.Put(path, reader)
+	})
+}
+
+// PutWithOptions 在调度器分配的并发数内使用指定选项上传文件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (storage *ScheduledStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	return scheduled(storage, func() (*Object, error) {
+		return storage.StorageInterface.PutWithOptions(path, reader, opts)
+	})
+}
+
+// NewWriter 在调度器分配的并发数内创建流式写入器，关闭写入器时释放并发数
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *ScheduledStorage) NewWriter(path string) (io.WriteCloser, error) {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &releaseWriteCloser{WriteCloser: writer, release: release}, nil
+}
+
+// Delete 在调度器分配的并发数内删除文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ScheduledStorage) Delete(path string) error {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return storage.StorageInterface.Delete(path)
+}
+
+// DeleteObjects 在调度器分配的并发数内批量删除对象
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *ScheduledStorage) DeleteObjects(paths []string) error {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return storage.StorageInterface.DeleteObjects(paths)
+}
+
+// DeleteDir 在调度器分配的并发数内删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *ScheduledStorage) DeleteDir(dir string) error {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return storage.StorageInterface.DeleteDir(dir)
+}
+
+// Copy 在调度器分配的并发数内复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ScheduledStorage) Copy(srcPath, dstPath string) error {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return storage.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 在调度器分配的并发数内移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *ScheduledStorage) Move(srcPath, dstPath string) error {
+	release := storage.Scheduler.Acquire(storage.Priority)
+	defer release()
+	return storage.StorageInterface.Move(srcPath, dstPath)
+}
+
+// List 在调度器分配的并发数内列出对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息
+func (storage *ScheduledStorage) List(path string) ([]*Object, error) {
+	return scheduled(storage, func() ([]*Object, error) {
+		return storage.StorageInterface.List(path)
+	})
+}
@@ -0,0 +1,71 @@
+package oss_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestScheduler(t *testing.T) {
+	scheduler := oss.NewScheduler(oss.SchedulerOptions{MaxConcurrent: 2, ClassLimits: map[oss.Priority]int{oss.PriorityBackfill: 1}})
+
+	release := scheduler.Acquire(oss.PriorityInteractive)
+	backfill := scheduler.Acquire(oss.PriorityBackfill)
+
+	var (
+		mutex sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	start := func(priority oss.Priority) {
+		wg.Add(1)
+		go scheduler.Do(priority, func() {
+			defer wg.Done()
+			mutex.Lock()
+			order = append(order, priority.String())
+			mutex.Unlock()
+		})
+	}
+	start(oss.PriorityBackfill)
+	for scheduler.Waiting(oss.PriorityBackfill) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	start(oss.PriorityInteractive)
+	for scheduler.Waiting(oss.PriorityInteractive) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 释放用户上传的并发数后，回填任务已达到上限，等待中的用户上传先开始
+	release()
+	for scheduler.Running(oss.PriorityInteractive) != 0 || scheduler.Waiting(oss.PriorityInteractive) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if scheduler.Waiting(oss.PriorityBackfill) != 1 {
+		t.Errorf("Backfill should wait for its class limit")
+	}
+	backfill()
+	wg.Wait()
+	if strings.Join(order, ",") != "interactive,backfill" {
+		t.Errorf("Interactive calls should start before backfill, but got %v", order)
+	}
+
+	mock := ossmock.New()
+	storage := oss.WithPriority(mock, scheduler, oss.PriorityBackfill)
+	stream, _ := storage.GetStream("/missing.txt")
+	if stream != nil || scheduler.Running(oss.PriorityBackfill) != 0 {
+		t.Errorf("Failed call should release its slot")
+	}
+	storage.Put("/a.txt", strings.NewReader("sample"))
+	stream, _ = storage.GetStream("/a.txt")
+	if scheduler.Running(oss.PriorityBackfill) != 1 {
+		t.Errorf("Open stream should hold its slot")
+	}
+	stream.Close()
+	if scheduler.Running(oss.PriorityBackfill) != 0 {
+		t.Errorf("Closing the stream should release its slot")
+	}
+}
@@ -0,0 +1,220 @@
+package oss
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultSelfTestDir 自检对象的默认目录
+const DefaultSelfTestDir = "/.oss-selftest"
+
+// DefaultSelfTestSize 自检对象的默认大小
+const DefaultSelfTestSize = 64 << 10
+
+// SelfTestStatus 自检步骤的结果
+type SelfTestStatus string
+
+const (
+	// SelfTestOK 步骤成功
+	SelfTestOK SelfTestStatus = "ok"
+	// SelfTestFailed 步骤失败
+	SelfTestFailed SelfTestStatus = "failed"
+	// SelfTestUnsupported 后端不支持该功能，返回了 ErrNotSupported
+	SelfTestUnsupported SelfTestStatus = "unsupported"
+	// SelfTestSkipped 依赖的步骤失败，没有执行
+	SelfTestSkipped SelfTestStatus = "skipped"
+)
+
+// SelfTestOptions 自检选项
+type SelfTestOptions struct {
+	// Dir 自检对象的目录，为空时使用 DefaultSelfTestDir
+	Dir string
+	// Size 自检对象的大小，小于等于0时使用 DefaultSelfTestSize
+	Size int
+	// FetchSignedURL 为true时通过HTTP下载签名URL并校验内容，用于确认签名URL在部署环境中可以访问
+	FetchSignedURL bool
+	// Client 下载签名URL的HTTP客户端，为nil时使用 DefaultHTTPClient
+	Client *http.Client
+}
+
+// SelfTestStep 一个自检步骤的结果
+type SelfTestStep struct {
+	// Name 步骤名称，例如 put、get、range
+	Name string
+	// Status 步骤的结果
+	Status SelfTestStatus
+	// Duration 步骤耗时
+	Duration time.Duration
+	// Err 失败或不支持时的错误信息
+	Err error
+}
+
+// SelfTestReport 自检报告
+type SelfTestReport struct {
+	// Path 自检对象的路径
+	Path string
+	// Steps 按执行顺序排列的步骤结果
+	Steps []SelfTestStep
+}
+
+// OK 判断全部步骤是否成功，不支持的功能不算作失败
+// 返回:
+//   - bool: 没有失败或跳过的步骤时返回true
+func (report *SelfTestReport) OK() bool {
+	for _, step := range report.Steps {
+		if step.Status == SelfTestFailed || step.Status == SelfTestSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// String 返回每个步骤一行的文本报告
+// 返回:
+//   - string: 文本报告
+func (report *SelfTestReport) String() string {
+	var builder strings.Builder
+	for _, step := range report.Steps {
+		fmt.Fprintf(&builder, "%-8s %-11s %10s", step.Name, step.Status, step.Duration.Round(time.Microsecond))
+		if step.Err != nil {
+			fmt.Fprintf(&builder, "  %v", step.Err)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// SelfTest 使用一个临时的自检对象检查存储的读写功能和延迟
+// 依次执行 put、stat、get、range、list、presign 和 delete，读取的内容与写入的随机内容逐字节比较，
+// 用于部署时确认新的访问密钥、权限和网络配置；上传成功后总会尝试删除自检对象
+// 参数:
+//   - storage: 存储接口
+//   - opts: 自检选项
+// 返回:
+//   - *SelfTestReport: 自检报告
+func SelfTest(storage StorageInterface, opts SelfTestOptions) *SelfTestReport {
+	if opts.Dir == "" {
+		opts.Dir = DefaultSelfTestDir
+	}
+	if opts.Size <= 0 {
+		opts.Size = DefaultSelfTestSize
+	}
+	if opts.Client == nil {
+		opts.Client = DefaultHTTPClient
+	}
+	report := &SelfTestReport{}
+	step := func(name string, fn func() error) bool {
+		start := Now()
+		err := fn()
+		result := SelfTestStep{Name: name, Status: SelfTestOK, Duration: Now().Sub(start), Err: err}
+		switch {
+		case errors.Is(err, ErrNotSupported):
+			result.Status = SelfTestUnsupported
+		case err != nil:
+			result.Status = SelfTestFailed
+		}
+		report.Steps = append(report.Steps, result)
+		return err == nil
+	}
+
+	suffix, err := RandomHex(8)
+	content := make([]byte, opts.Size)
+	if err == nil {
+		_, err = io.ReadFull(DefaultRandom, content)
+	}
+	if err != nil {
+		report.Steps = append(report.Steps, SelfTestStep{Name: "put", Status: SelfTestFailed, Err: err})
+		return report
+	}
+	report.Path = "/" + DirPrefix(opts.Dir) + "canary-" + suffix + ".bin"
+
+	if !step("put", func() error {
+		_, err := storage.Put(report.Path, bytes.NewReader(content))
+		return err
+	}) {
+		for _, name := range []string{"stat", "get", "range", "list", "presign", "delete"} {
+			report.Steps = append(report.Steps, SelfTestStep{Name: name, Status: SelfTestSkipped})
+		}
+		return report
+	}
+
+	step("stat", func() error {
+		object, err := storage.Stat(report.Path)
+		if err == nil && object.Size != int64(len(content)) {
+			err = fmt.Errorf("size %d, expected %d", object.Size, len(content))
+		}
+		return err
+	})
+	step("get", func() error {
+		stream, err := storage.GetStream(report.Path)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return compareSelfTest(stream, content)
+	})
+	step("range", func() error {
+		offset, length := int64(len(content)/4), int64(len(content)/2)
+		stream, err := storage.GetStreamRange(report.Path, offset, length)
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return compareSelfTest(stream, content[offset:offset+length])
+	})
+	step("list", func() error {
+		objects, err := storage.List(opts.Dir)
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			if "/"+strings.TrimPrefix(object.Path, "/") == report.Path {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s not listed in %s", report.Path, opts.Dir)
+	})
+	step("presign", func() error {
+		signed, err := storage.GetSignedURL(report.Path, SignedURLOptions{Expiry: time.Minute})
+		if err != nil || !opts.FetchSignedURL || !strings.HasPrefix(signed, "http") {
+			return err
+		}
+		response, err := opts.Client.Get(signed)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("signed url returned %s", response.Status)
+		}
+		return compareSelfTest(response.Body, content)
+	})
+	step("delete", func() error {
+		if err := storage.Delete(report.Path); err != nil {
+			return err
+		}
+		exists, err := storage.Exists(report.Path)
+		if err == nil && exists {
+			err = errors.New("object still exists after delete")
+		}
+		return err
+	})
+	return report
+}
+
+// compareSelfTest 读取全部内容并与期望的内容比较
+func compareSelfTest(reader io.Reader, expected []byte) error {
+	actual, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(actual, expected) {
+		return fmt.Errorf("%w: read %d bytes that differ from the %d bytes written", ErrChecksumMismatch, len(actual), len(expected))
+	}
+	return nil
+}
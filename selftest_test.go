@@ -0,0 +1,41 @@
+package oss_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+func TestSelfTest(t *testing.T) {
+	mock := ossmock.New()
+	report := oss.SelfTest(mock, oss.SelfTestOptions{Size: 1024})
+	if !report.OK() || len(report.Steps) != 7 {
+		t.Fatalf("Self test should pass against the mock, but got\n%v", report)
+	}
+	if !strings.HasPrefix(report.Path, oss.DefaultSelfTestDir+"/canary-") {
+		t.Errorf("Canary should be written under the default dir, but got %v", report.Path)
+	}
+	if exists, _ := mock.Exists(report.Path); exists {
+		t.Errorf("Canary should be deleted after the self test")
+	}
+
+	mock.FailWith("GetSignedURL", oss.ErrNotSupported)
+	mock.FailWith("GetStreamRange", errors.New("range failed"))
+	report = oss.SelfTest(mock, oss.SelfTestOptions{})
+	statuses := map[string]oss.SelfTestStatus{}
+	for _, step := range report.Steps {
+		statuses[step.Name] = step.Status
+	}
+	if statuses["presign"] != oss.SelfTestUnsupported || statuses["range"] != oss.SelfTestFailed || statuses["delete"] != oss.SelfTestOK || report.OK() {
+		t.Errorf("Unsupported and failed steps should be reported, but got\n%v", report)
+	}
+
+	mock.FailWith("Put", errors.New("access denied"))
+	report = oss.SelfTest(mock, oss.SelfTestOptions{})
+	if report.OK() || report.Steps[0].Status != oss.SelfTestFailed || report.Steps[len(report.Steps)-1].Status != oss.SelfTestSkipped {
+		t.Errorf("Steps after a failed put should be skipped, but got\n%v", report)
+	}
+}
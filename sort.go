@@ -0,0 +1,36 @@
+package oss
+
+import "sort"
+
+// SortObjects 将对象列表按路径字典序（字节序）排序
+// 用于服务端不保证返回顺序的存储后端
+// 参数:
+//   - objects: 对象列表
+func SortObjects(objects []*Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].Path < objects[j].Path
+	})
+}
+
+// SortParts 将分片按编号升序排序
+// 完成分片上传时服务端要求分片按编号升序排列
+// 参数:
+//   - parts: 已上传的分片
+func SortParts(parts []*Part) {
+	sort.SliceStable(parts, func(i, j int) bool {
+		return parts[i].Number < parts[j].Number
+	})
+}
+
+// SortVersions 将版本列表按修改时间从新到旧排序
+// 用于服务端不保证返回顺序的存储后端
+// 参数:
+//   - versions: 版本列表
+func SortVersions(versions []*ObjectVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].LastModified == nil || versions[j].LastModified == nil {
+			return versions[j].LastModified == nil && versions[i].LastModified != nil
+		}
+		return versions[i].LastModified.After(*versions[j].LastModified)
+	})
+}
@@ -0,0 +1,41 @@
+package oss
+
+import "time"
+
+// PrefixStats 前缀下对象的用量统计
+type PrefixStats struct {
+	// Prefix 统计的路径前缀
+	Prefix string
+	// Objects 对象数量
+	Objects int64
+	// Bytes 对象总大小，单位字节
+	Bytes int64
+	// LastModified 最近一次修改时间，没有对象时为nil
+	LastModified *time.Time
+}
+
+// Stats 统计前缀下全部对象的数量、总大小和最近修改时间
+// 通过 List 遍历前缀，对象数量很多时耗时较长
+// 参数:
+//   - storage: 存储接口
+//   - prefix: 路径前缀
+// 返回:
+//   - *PrefixStats: 用量统计
+//   - error: 错误信息
+func Stats(storage StorageInterface, prefix string) (*PrefixStats, error) {
+	objects, err := storage.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PrefixStats{Prefix: prefix}
+	for _, object := range objects {
+		stats.Objects++
+		stats.Bytes += object.Size
+		if object.LastModified != nil && (stats.LastModified == nil || object.LastModified.After(*stats.LastModified)) {
+			lastModified := *object.LastModified
+			stats.LastModified = &lastModified
+		}
+	}
+	return stats, nil
+}
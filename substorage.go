@@ -0,0 +1,387 @@
+package oss
+
+import (
+	"fmt"
+	"io"
+	"os"
+	pathpkg "path"
+	"strings"
+)
+
+// PrefixedStorage 以某个目录为根的存储视图
+// 所有路径都相对于 Prefix，返回的对象路径同样相对于 Prefix，对象的 StorageInterface 指向视图本身
+// 含有 .. 路径段的路径返回 ErrInvalidPath，视图内的调用方无法访问 Prefix 以外的对象，可以交给不受信任的租户使用
+type PrefixedStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Prefix 视图根目录，不以 / 开头，以 / 结尾
+	Prefix string
+}
+
+// SubStorage 创建以目录对象为根的存储视图
+// 用于对单个目录执行同步、授权等递归操作，调用方不需要自行拼接路径
+// 参数:
+//   - storage: 存储接口
+//   - dir: 目录对象，例如 List 返回的对象或只设置了 Path 的对象
+// 返回:
+//   - *PrefixedStorage: 存储视图
+func SubStorage(storage StorageInterface, dir *Object) *PrefixedStorage {
+	return NewPrefixedStorage(storage, dir.Path)
+}
+
+// NewPrefixedStorage 创建以指定目录为根的存储视图
+// 参数:
+//   - storage: 存储接口
+//   - dir: 视图根目录
+// 返回:
+//   - *PrefixedStorage: 存储视图
+func NewPrefixedStorage(storage StorageInterface, dir string) *PrefixedStorage {
+	// 根目录本身中的 .. 在创建时清理掉，嵌套视图不能跳出上层视图
+	prefix := DirPrefix(pathpkg.Clean("/" + strings.ReplaceAll(dir, `\`, "/")))
+	// 嵌套视图直接叠加前缀
+	if parent, ok := storage.(*PrefixedStorage); ok {
+		return &PrefixedStorage{StorageInterface: parent.StorageInterface, Prefix: parent.Prefix + prefix}
+	}
+	return &PrefixedStorage{StorageInterface: storage, Prefix: prefix}
+}
+
+// FullPath 将视图内的路径转换为被包装存储中的路径
+// 路径中的 . 和 .. 路径段会被清理，.. 不会超出视图根目录，结尾的斜杠保留
+// 参数:
+//   - path: 视图内的路径
+// 返回:
+//   - string: 被包装存储中的路径
+func (storage *PrefixedStorage) FullPath(path string) string {
+	cleaned := strings.TrimPrefix(pathpkg.Clean("/"+path), "/")
+	if cleaned != "" && strings.HasSuffix(path, "/") {
+		cleaned += "/"
+	}
+	return "/" + storage.Prefix + cleaned
+}
+
+// ValidPath 检查视图内的路径是否安全
+// 与 fs.ValidPath 类似，拒绝含有 .. 路径段和空字符的路径，反斜杠同样视为分隔符，避免在 Windows 文件系统上跳出视图
+// 参数:
+//   - path: 视图内的路径
+// 返回:
+//   - error: 路径不安全时返回 ErrInvalidPath
+func (storage *PrefixedStorage) ValidPath(path string) error {
+	if strings.IndexByte(path, 0) >= 0 {
+		return fmt.Errorf("%w: %q contains NUL", ErrInvalidPath, path)
+	}
+	for _, segment := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return fmt.Errorf("%w: %q escapes the sub storage", ErrInvalidPath, path)
+		}
+	}
+	return nil
+}
+
+// resolve 检查视图内的路径并转换为被包装存储中的路径
+func (storage *PrefixedStorage) resolve(path string) (string, error) {
+	if err := storage.ValidPath(path); err != nil {
+		return "", err
+	}
+	return storage.FullPath(path), nil
+}
+
+// resolvePair 检查并转换复制和移动的源路径和目标路径
+func (storage *PrefixedStorage) resolvePair(srcPath, dstPath string) (string, string, error) {
+	src, err := storage.resolve(srcPath)
+	if err != nil {
+		return "", "", err
+	}
+	dst, err := storage.resolve(dstPath)
+	return src, dst, err
+}
+
+// RelativePath 将被包装存储中的路径转换为视图内的路径
+// 参数:
+//   - path: 被包装存储中的路径
+// 返回:
+//   - string: 视图内的路径
+func (storage *PrefixedStorage) RelativePath(path string) string {
+	return "/" + strings.TrimPrefix(strings.TrimPrefix(path, "/"), storage.Prefix)
+}
+
+// relative 将被包装存储返回的对象转换为视图内的对象
+func (storage *PrefixedStorage) relative(object *Object) *Object {
+	if object == nil {
+		return nil
+	}
+	relative := *object
+	relative.Path = storage.RelativePath(object.Path)
+	relative.StorageInterface = storage
+	return &relative
+}
+
+// Get 获取指定路径的文件
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *PrefixedStorage) Get(path string) (*os.File, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.Get(fullPath)
+}
+
+// GetStream 获取指定路径文件的流
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息
+func (storage *PrefixedStorage) GetStream(path string) (io.ReadCloser, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetStream(fullPath)
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 参数:
+//   - path: 视图内的文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (storage *PrefixedStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetStreamRange(fullPath, offset, length)
+}
+
+// Stat 获取指定路径文件的元信息
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - *Object: 视图内的对象信息
+//   - error: 错误信息
+func (storage *PrefixedStorage) Stat(path string) (*Object, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	object, err := storage.StorageInterface.Stat(fullPath)
+	return storage.relative(object), err
+}
+
+// Exists 检查指定路径的文件是否存在
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *PrefixedStorage) Exists(path string) (bool, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return false, err
+	}
+	return storage.StorageInterface.Exists(fullPath)
+}
+
+// Put 上传文件到指定路径
+// 参数:
+//   - path: 视图内的目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 视图内的对象信息
+//   - error: 错误信息
+func (storage *PrefixedStorage) Put(path string, reader io.Reader) (*Object, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	object, err := storage.StorageInterface.Put(fullPath, reader)
+	return storage.relative(object), err
+}
+
+// PutWithOptions 使用指定选项上传文件
+// 参数:
+//   - path: 视图内的目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 视图内的对象信息
+//   - error: 错误信息
+func (storage *PrefixedStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	object, err := storage.StorageInterface.PutWithOptions(fullPath, reader, opts)
+	return storage.relative(object), err
+}
+
+// NewWriter 创建流式写入器
+// 参数:
+//   - path: 视图内的目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *PrefixedStorage) NewWriter(path string) (io.WriteCloser, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.NewWriter(fullPath)
+}
+
+// Delete 删除指定路径的文件
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *PrefixedStorage) Delete(path string) error {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return err
+	}
+	return storage.StorageInterface.Delete(fullPath)
+}
+
+// DeleteObjects 批量删除多个文件
+// 参数:
+//   - paths: 视图内的文件路径列表
+// 返回:
+//   - error: 错误信息，部分失败时 *DeleteObjectsError 中的路径为视图内的路径
+func (storage *PrefixedStorage) DeleteObjects(paths []string) error {
+	fullPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		fullPath, err := storage.resolve(path)
+		if err != nil {
+			return err
+		}
+		fullPaths = append(fullPaths, fullPath)
+	}
+
+	err := storage.StorageInterface.DeleteObjects(fullPaths)
+	if deleteErr, ok := err.(*DeleteObjectsError); ok {
+		failures := make(map[string]error, len(deleteErr.Errors))
+		for path, failure := range deleteErr.Errors {
+			failures[storage.RelativePath(path)] = failure
+		}
+		return NewDeleteObjectsError(failures)
+	}
+	return err
+}
+
+// DeleteDir 删除目录下的全部文件
+// 与其他存储一致，拒绝删除视图的根目录
+// 参数:
+//   - dir: 视图内的目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 ErrDeleteRoot，路径不安全时返回 ErrInvalidPath
+func (storage *PrefixedStorage) DeleteDir(dir string) error {
+	fullPath, err := storage.resolve(dir)
+	if err != nil {
+		return err
+	}
+	if fullPath == "/"+storage.Prefix {
+		return ErrDeleteRoot
+	}
+	return storage.StorageInterface.DeleteDir(fullPath)
+}
+
+// Copy 复制文件
+// 参数:
+//   - srcPath: 视图内的源文件路径
+//   - dstPath: 视图内的目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *PrefixedStorage) Copy(srcPath, dstPath string) error {
+	src, dst, err := storage.resolvePair(srcPath, dstPath)
+	if err != nil {
+		return err
+	}
+	return storage.StorageInterface.Copy(src, dst)
+}
+
+// Move 移动文件
+// 参数:
+//   - srcPath: 视图内的源文件路径
+//   - dstPath: 视图内的目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *PrefixedStorage) Move(srcPath, dstPath string) error {
+	src, dst, err := storage.resolvePair(srcPath, dstPath)
+	if err != nil {
+		return err
+	}
+	return storage.StorageInterface.Move(src, dst)
+}
+
+// List 列出目录下的文件
+// 参数:
+//   - path: 视图内的目录路径
+// 返回:
+//   - []*Object: 视图内的对象列表
+//   - error: 错误信息
+func (storage *PrefixedStorage) List(path string) ([]*Object, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := storage.StorageInterface.List(fullPath)
+	relatives := make([]*Object, 0, len(objects))
+	for _, object := range objects {
+		// 被包装存储返回根目录以外的对象时不交给调用方
+		if !strings.HasPrefix(strings.TrimPrefix(object.Path, "/"), storage.Prefix) {
+			continue
+		}
+		relatives = append(relatives, storage.relative(object))
+	}
+	return relatives, err
+}
+
+// GetURL 获取指定路径文件的访问URL
+// 参数:
+//   - path: 视图内的文件路径
+// 返回:
+//   - string: 访问URL
+//   - error: 错误信息
+func (storage *PrefixedStorage) GetURL(path string) (string, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	return storage.StorageInterface.GetURL(fullPath)
+}
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 视图内的文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (storage *PrefixedStorage) GetSignedURL(path string, opts SignedURLOptions) (string, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	return storage.StorageInterface.GetSignedURL(fullPath, opts)
+}
+
+// GetUploadURL 生成客户端直传地址
+// 参数:
+//   - path: 视图内的文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *UploadURL: 直传地址
+//   - error: 错误信息
+func (storage *PrefixedStorage) GetUploadURL(path string, opts UploadURLOptions) (*UploadURL, error) {
+	fullPath, err := storage.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	return storage.StorageInterface.GetUploadURL(fullPath, opts)
+}
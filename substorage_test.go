@@ -0,0 +1,53 @@
+package oss_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestSubStorage(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	if _, err := fileSystem.Put("/projects/a/readme.txt", strings.NewReader("readme")); err != nil {
+		t.Fatalf("No error should happen when put file, but got %v", err)
+	}
+
+	dirs, _ := fileSystem.List("/projects")
+	sub := oss.SubStorage(fileSystem, &oss.Object{Path: filepath.ToSlash(filepath.Dir(dirs[0].Path))})
+
+	if objects, err := sub.List("/"); err != nil || len(objects) != 1 || objects[0].Path != "/readme.txt" {
+		t.Errorf("Sub storage should list objects relative to its root, but got %v, %v", objects, err)
+	} else if objects[0].StorageInterface != sub {
+		t.Errorf("Listed objects should belong to the sub storage")
+	}
+
+	if _, err := sub.Put("/b.txt", strings.NewReader("b")); err != nil {
+		t.Errorf("No error should happen when put file in sub storage, but got %v", err)
+	} else if exists, _ := fileSystem.Exists("/projects/a/b.txt"); !exists {
+		t.Errorf("File put in sub storage should be saved under its root")
+	}
+
+	if nested := oss.NewPrefixedStorage(oss.NewPrefixedStorage(fileSystem, "/projects"), "a"); nested.Prefix != "projects/a/" {
+		t.Errorf("Nested sub storage should combine prefixes, but got %v", nested.Prefix)
+	}
+
+	fileSystem.Put("/projects/b/secret.txt", strings.NewReader("secret"))
+	for _, path := range []string{"../b/secret.txt", "/x/../../b/secret.txt", `..\b\secret.txt`, "/secret\x00.txt"} {
+		if _, err := sub.GetStream(path); !errors.Is(err, oss.ErrInvalidPath) {
+			t.Errorf("Path %q should be rejected, but got %v", path, err)
+		}
+	}
+	if err := sub.Copy("/readme.txt", "../b/copied.txt"); !errors.Is(err, oss.ErrInvalidPath) {
+		t.Errorf("Copy out of the sub storage should be rejected, but got %v", err)
+	}
+	if err := sub.DeleteDir("/./"); err != oss.ErrDeleteRoot {
+		t.Errorf("Deleting the root of the sub storage should be rejected, but got %v", err)
+	}
+	if escaped := oss.NewPrefixedStorage(fileSystem, "/projects/../../etc"); escaped.Prefix != "etc/" || sub.FullPath("/./c/../d/") != "/projects/a/d/" {
+		t.Errorf("Prefix and paths should be cleaned, but got %v, %v", escaped.Prefix, sub.FullPath("/./c/../d/"))
+	}
+}
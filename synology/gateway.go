@@ -0,0 +1,972 @@
+package synology
+
+import (
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smart-unicom/oss"
+)
+
+// 网关请求头
+const (
+	// gatewayTimestampHeader 请求时间戳（Unix秒）
+	gatewayTimestampHeader = "X-Gateway-Timestamp"
+	// gatewayNonceHeader 每个请求唯一的随机数，用于拒绝重放的请求
+	gatewayNonceHeader = "X-Gateway-Nonce"
+	// gatewayContentHashHeader 请求体的SHA256摘要
+	gatewayContentHashHeader = "X-Gateway-Content-Sha256"
+	// gatewaySignatureHeader 请求签名
+	gatewaySignatureHeader = "X-Gateway-Signature"
+	// gatewayPutOptionsHeader JSON编码的上传选项
+	gatewayPutOptionsHeader = "X-Gateway-Put-Options"
+)
+
+// DefaultGatewayMaxClockSkew 网关允许的默认时钟偏差
+const DefaultGatewayMaxClockSkew = 5 * time.Minute
+
+// DefaultGatewayMaxBodySize 网关默认允许的最大请求体字节数
+const DefaultGatewayMaxBodySize = 5 << 30
+
+// maxGatewayNonceLength 随机数的最大长度
+const maxGatewayNonceLength = 128
+
+// errGatewayUnauthorized 网关请求签名校验失败
+var errGatewayUnauthorized = fmt.Errorf("gateway signature mismatch: %w", oss.ErrPermissionDenied)
+
+// gatewayErrors 网关错误码与统一错误的映射，用于在客户端还原错误类型
+var gatewayErrors = []struct {
+	code string
+	err  error
+}{
+	{"object_not_found", oss.ErrObjectNotFound},
+	{"bucket_not_found", oss.ErrBucketNotFound},
+	{"not_found", oss.ErrNotFound},
+	{"region_not_allowed", oss.ErrRegionNotAllowed},
+	{"permission_denied", oss.ErrPermissionDenied},
+	{"conflict", oss.ErrConflict},
+	{"too_large", oss.ErrTooLarge},
+	{"rate_limited", oss.ErrRateLimited},
+	{"unavailable", oss.ErrUnavailable},
+	{"timeout", oss.ErrTimeout},
+	{"key_too_long", oss.ErrKeyTooLong},
+	{"invalid_path", oss.ErrInvalidPath},
+	{"invalid_range", oss.ErrInvalidRange},
+	{"checksum_mismatch", oss.ErrChecksumMismatch},
+	{"not_supported", oss.ErrNotSupported},
+}
+
+// gatewayError 网关返回的错误信息
+type gatewayError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// gatewayObject 网关传输的对象信息，不包含存储接口
+type gatewayObject struct {
+	Path         string            `json:"path"`
+	Name         string            `json:"name"`
+	LastModified *time.Time        `json:"last_modified,omitempty"`
+	Size         int64             `json:"size"`
+	ContentType  string            `json:"content_type,omitempty"`
+	ETag         string            `json:"etag,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	LinkTarget   string            `json:"link_target,omitempty"`
+}
+
+// signGatewayRequest 计算网关请求签名
+// 签名覆盖HTTP方法、路径、排序后的查询参数、时间戳、随机数、请求体摘要、内容编码和上传选项
+// 参数:
+//   - secret: 共享密钥
+//   - method: HTTP方法
+//   - path: 请求路径
+//   - query: 查询参数
+//   - timestamp: 请求时间戳
+//   - nonce: 请求随机数
+//   - contentHash: 请求体的SHA256摘要
+//   - contentEncoding: 请求体的内容编码
+//   - putOptions: JSON编码的上传选项
+// 返回:
+//   - string: 十六进制编码的签名
+func signGatewayRequest(secret []byte, method, path string, query url.Values, timestamp, nonce, contentHash, contentEncoding, putOptions string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{method, path, query.Encode(), timestamp, nonce, contentHash, contentEncoding, putOptions}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// contentSHA256 计算内容的SHA256摘要
+func contentSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GatewayServer 群晖网关服务端
+// 只有网关持有DSM凭据，其他服务通过HMAC签名的内部请求访问群晖，
+// 避免将NAS管理员凭据分发到每个微服务
+type GatewayServer struct {
+	// Storage 实际访问群晖的存储客户端
+	Storage oss.StorageInterface
+	// Secret 与网关客户端共享的签名密钥
+	Secret []byte
+	// MaxClockSkew 允许的最大时钟偏差，为0时使用 DefaultGatewayMaxClockSkew
+	// 时间戳在偏差范围内的请求的随机数会被记住，同一个随机数的请求只接受一次
+	MaxClockSkew time.Duration
+	// MaxBodySize 允许的最大请求体字节数，为0时使用 DefaultGatewayMaxBodySize，超过时返回413
+	MaxBodySize int64
+
+	nonces gatewayNonces
+}
+
+// NewGatewayServer 创建群晖网关服务端
+// 参数:
+//   - storage: 实际访问群晖的存储客户端
+//   - secret: 共享签名密钥
+// 返回:
+//   - *GatewayServer: 网关服务端实例
+func NewGatewayServer(storage oss.StorageInterface, secret []byte) *GatewayServer {
+	return &GatewayServer{Storage: storage, Secret: secret}
+}
+
+// ServeHTTP 校验请求签名并转发到存储客户端
+// 参数:
+//   - w: HTTP响应写入器
+//   - r: HTTP请求
+func (server *GatewayServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, cleanup, err := server.verify(w, r)
+	if err != nil {
+		writeGatewayError(w, err)
+		return
+	}
+	defer cleanup()
+
+	// 客户端接受gzip时压缩JSON响应，文件内容按原样传输
+	if (r.Method != http.MethodGet || r.URL.Path != "/object") && acceptsGzip(r) {
+		compressed := &gzipResponseWriter{ResponseWriter: w, writer: gzip.NewWriter(w)}
+		defer compressed.writer.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Add("Vary", "Accept-Encoding")
+		w = compressed
+	}
+
+	query := r.URL.Query()
+	path := query.Get("path")
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /object":
+		var readCloser io.ReadCloser
+		if query.Has("offset") {
+			offset, offsetErr := strconv.ParseInt(query.Get("offset"), 10, 64)
+			length, lengthErr := strconv.ParseInt(query.Get("length"), 10, 64)
+			if offsetErr != nil || lengthErr != nil {
+				writeGatewayError(w, gatewayBadRequest(fmt.Sprintf("invalid range offset=%q length=%q", query.Get("offset"), query.Get("length"))))
+				return
+			}
+			readCloser, err = server.Storage.GetStreamRange(path, offset, length)
+		} else {
+			readCloser, err = server.Storage.GetStream(path)
+		}
+		if err != nil {
+			writeGatewayError(w, err)
+			return
+		}
+		defer readCloser.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.Copy(w, readCloser)
+	case "PUT /object":
+		opts := &oss.PutOptions{}
+		if header := r.Header.Get(gatewayPutOptionsHeader); header != "" {
+			if err := json.Unmarshal([]byte(header), opts); err != nil {
+				writeGatewayError(w, err)
+				return
+			}
+		}
+		object, err := server.Storage.PutWithOptions(path, body, opts)
+		writeGatewayJSON(w, toGatewayObject(object), err)
+	case "DELETE /object":
+		writeGatewayJSON(w, nil, server.Storage.Delete(path))
+	case "GET /stat":
+		object, err := server.Storage.Stat(path)
+		writeGatewayJSON(w, toGatewayObject(object), err)
+	case "GET /exists":
+		exists, err := server.Storage.Exists(path)
+		writeGatewayJSON(w, exists, err)
+	case "GET /list":
+		objects, err := server.Storage.List(path)
+		results := make([]*gatewayObject, 0, len(objects))
+		for _, object := range objects {
+			results = append(results, toGatewayObject(object))
+		}
+		writeGatewayJSON(w, results, err)
+	case "GET /url":
+		rawURL, err := server.Storage.GetURL(path)
+		writeGatewayJSON(w, rawURL, err)
+	case "POST /copy":
+		writeGatewayJSON(w, nil, server.Storage.Copy(query.Get("src"), query.Get("dst")))
+	case "POST /move":
+		writeGatewayJSON(w, nil, server.Storage.Move(query.Get("src"), query.Get("dst")))
+	default:
+		writeGatewayError(w, fmt.Errorf("%w: %s %s", oss.ErrNotSupported, r.Method, r.URL.Path))
+	}
+}
+
+// verify 校验请求签名、时间戳、随机数和请求体摘要
+// 先按请求头中的摘要校验签名，签名无效的请求不会读取请求体；
+// 请求体边读取边计算摘要并写入临时文件，摘要一致后才交给存储，内容不会整体读入内存
+// 参数:
+//   - w: HTTP响应写入器，用于限制请求体大小
+//   - r: HTTP请求
+// 返回:
+//   - io.Reader: 解压后的请求体
+//   - func(): 删除临时文件的清理函数
+//   - error: 校验失败时返回错误信息
+func (server *GatewayServer) verify(w http.ResponseWriter, r *http.Request) (io.Reader, func(), error) {
+	maxClockSkew := server.MaxClockSkew
+	if maxClockSkew == 0 {
+		maxClockSkew = DefaultGatewayMaxClockSkew
+	}
+	maxBodySize := server.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultGatewayMaxBodySize
+	}
+
+	timestamp := r.Header.Get(gatewayTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, nil, errGatewayUnauthorized
+	}
+	now := oss.Now()
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return nil, nil, errGatewayUnauthorized
+	}
+	nonce := r.Header.Get(gatewayNonceHeader)
+	if nonce == "" || len(nonce) > maxGatewayNonceLength {
+		return nil, nil, errGatewayUnauthorized
+	}
+
+	contentHash, contentEncoding := r.Header.Get(gatewayContentHashHeader), r.Header.Get("Content-Encoding")
+	expected := signGatewayRequest(server.Secret, r.Method, r.URL.Path, r.URL.Query(), timestamp, nonce, contentHash, contentEncoding, r.Header.Get(gatewayPutOptionsHeader))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(gatewaySignatureHeader))) {
+		return nil, nil, errGatewayUnauthorized
+	}
+	// 签名有效后才记录随机数，伪造的请求不会占用缓存
+	if !server.nonces.add(nonce, time.Unix(seconds, 0).Add(maxClockSkew), now) {
+		return nil, nil, errGatewayUnauthorized
+	}
+
+	// 没有请求体的请求（例如 GET 和 DELETE）不需要临时文件
+	if r.ContentLength == 0 {
+		if contentHash != contentSHA256(nil) {
+			return nil, nil, errGatewayUnauthorized
+		}
+		return http.NoBody, func() {}, nil
+	}
+
+	file, err := oss.CreateTempFile("synology-gateway-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { oss.RemoveTempFile(file) }
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), http.MaxBytesReader(w, r.Body, maxBodySize)); err != nil {
+		cleanup()
+		return nil, nil, gatewayBodyError(err, maxBodySize)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != contentHash {
+		cleanup()
+		return nil, nil, errGatewayUnauthorized
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	// 签名覆盖压缩后的请求体，校验通过后再解压，解压后的内容同样受大小限制
+	if contentEncoding == "gzip" {
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			cleanup()
+			return nil, nil, gatewayBadRequest(err.Error())
+		}
+		return &gatewayLimitedReader{reader: http.MaxBytesReader(w, reader, maxBodySize), limit: maxBodySize}, cleanup, nil
+	}
+	return file, cleanup, nil
+}
+
+// gatewayBodyError 将请求体超过大小限制的错误转换为 oss.ErrTooLarge
+func gatewayBodyError(err error, limit int64) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: request body exceeds %d bytes", oss.ErrTooLarge, limit)
+	}
+	return err
+}
+
+// gatewayLimitedReader 解压后的请求体，超过大小限制时返回 oss.ErrTooLarge
+type gatewayLimitedReader struct {
+	reader io.Reader
+	limit  int64
+}
+
+// Read 读取解压后的内容
+func (reader *gatewayLimitedReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = gatewayBodyError(err, reader.limit)
+	}
+	return n, err
+}
+
+// gatewayBadRequest 网关请求参数错误，对应400状态码
+type gatewayBadRequest string
+
+// Error 返回错误描述
+func (err gatewayBadRequest) Error() string {
+	return string(err)
+}
+
+// HTTPStatus 请求参数错误对应 400 Bad Request
+func (err gatewayBadRequest) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+// gatewayNonces 已接受请求的随机数，在请求的时间戳超出允许的时钟偏差前拒绝相同随机数的请求
+type gatewayNonces struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+	prune   int
+}
+
+// add 记录随机数
+// 参数:
+//   - nonce: 请求随机数
+//   - expires: 请求的时间戳超出允许偏差的时间
+//   - now: 当前时间
+// 返回:
+//   - bool: 随机数已被使用时返回false
+func (nonces *gatewayNonces) add(nonce string, expires, now time.Time) bool {
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+	if nonces.expires == nil {
+		nonces.expires = map[string]time.Time{}
+	}
+	if expiry, ok := nonces.expires[nonce]; ok && now.Before(expiry) {
+		return false
+	}
+	// 缓存增长到上次清理后的两倍时删除过期的随机数
+	if len(nonces.expires) >= nonces.prune {
+		for key, expiry := range nonces.expires {
+			if !now.Before(expiry) {
+				delete(nonces.expires, key)
+			}
+		}
+		nonces.prune = 2*len(nonces.expires) + 1024
+	}
+	nonces.expires[nonce] = expires
+	return true
+}
+
+// acceptsGzip 判断请求是否接受gzip编码的响应
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(strings.SplitN(encoding, ";", 2)[0]) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter 将响应体写入gzip压缩器
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+}
+
+// Write 压缩并写入响应体
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	return w.writer.Write(data)
+}
+
+// toGatewayObject 将对象转换为网关传输格式
+func toGatewayObject(object *oss.Object) *gatewayObject {
+	if object == nil {
+		return nil
+	}
+	return &gatewayObject{
+		Path:         object.Path,
+		Name:         object.Name,
+		LastModified: object.LastModified,
+		Size:         object.Size,
+		ContentType:  object.ContentType,
+		ETag:         object.ETag,
+		Metadata:     object.Metadata,
+		LinkTarget:   object.LinkTarget,
+	}
+}
+
+// writeGatewayJSON 写入JSON响应，err不为nil时写入错误响应
+func writeGatewayJSON(w http.ResponseWriter, value interface{}, err error) {
+	if err != nil {
+		writeGatewayError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeGatewayError 写入错误响应，状态码由 oss.HTTPStatus 决定
+// 未包装统一错误的后端错误（例如os.ErrNotExist）按状态码确定错误码
+func writeGatewayError(w http.ResponseWriter, err error) {
+	response := gatewayError{Message: err.Error()}
+	status := oss.HTTPStatus(err)
+	for _, known := range gatewayErrors {
+		if errors.Is(err, known.err) {
+			response.Code = known.code
+			break
+		}
+	}
+	if response.Code == "" && status != http.StatusBadRequest {
+		for _, known := range gatewayErrors {
+			if oss.HTTPStatus(known.err) == status {
+				response.Code = known.code
+				break
+			}
+		}
+	}
+
+	if err == errGatewayUnauthorized {
+		status = http.StatusUnauthorized
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GatewayClient 群晖网关客户端
+// 通过HMAC签名的请求访问网关服务端，本身不持有DSM凭据
+type GatewayClient struct {
+	// Endpoint 网关服务端地址
+	Endpoint string
+	// Secret 与网关服务端共享的签名密钥
+	Secret []byte
+	// HTTPClient 发送请求使用的HTTP客户端，为nil时使用带超时的 oss.DefaultHTTPClient
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，为nil时使用默认配置，只重试幂等且内容可以重新读取的请求
+	Retry *oss.RetryConfig
+	// Compression 是否启用gzip传输压缩，启用后上传内容压缩发送，并请求和解压gzip编码的响应，
+	// 适合通过广域网访问网关时列出大目录
+	Compression bool
+}
+
+// NewGatewayClient 创建群晖网关客户端
+// 参数:
+//   - endpoint: 网关服务端地址
+//   - secret: 共享签名密钥
+// 返回:
+//   - *GatewayClient: 网关客户端实例
+func NewGatewayClient(endpoint string, secret []byte) *GatewayClient {
+	return &GatewayClient{Endpoint: strings.TrimSuffix(endpoint, "/"), Secret: secret}
+}
+
+// do 发送签名请求
+// 参数:
+//   - method: HTTP方法
+//   - path: 网关接口路径
+//   - query: 查询参数
+//   - body: 请求体，为nil时不发送请求体
+//   - header: 附加请求头
+// 返回:
+//   - *http.Response: 成功时的HTTP响应
+//   - error: 错误信息，网关返回错误时还原为统一错误
+func (client GatewayClient) do(method, path string, query url.Values, body *gatewayBody, header http.Header) (*http.Response, error) {
+	request, err := http.NewRequest(method, client.Endpoint+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	if client.Compression {
+		// 显式设置后Transport不再自动解压，由 decodeGatewayResponse 处理
+		request.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	contentHash := contentSHA256(nil)
+	if body != nil && body.size > 0 {
+		request.ContentLength = body.size
+		request.Body, _ = body.open()
+		request.GetBody = body.open
+		contentHash = body.hash
+		if body.encoding != "" {
+			request.Header.Set("Content-Encoding", body.encoding)
+		}
+	}
+	request.Header.Set(gatewayContentHashHeader, contentHash)
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = oss.DefaultHTTPClient
+	}
+	// 每次重试都重新签名，使用新的时间戳和随机数，避免被服务端当作重放的请求拒绝
+	signing := *httpClient
+	signing.Transport = &gatewaySigner{secret: client.Secret, prefix: client.endpointPath(), base: httpClient.Transport}
+	response, err := client.Retry.Client(&signing).Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeGatewayResponse(response); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	if response.StatusCode >= 300 {
+		defer response.Body.Close()
+		return nil, decodeGatewayError(response)
+	}
+	return response, nil
+}
+
+// endpointPath 返回网关服务端地址中的路径前缀，签名覆盖去掉前缀后的接口路径
+func (client GatewayClient) endpointPath() string {
+	parsed, err := url.Parse(client.Endpoint)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(parsed.Path, "/")
+}
+
+// gatewaySigner 在发送每个请求前设置时间戳、随机数和签名的HTTP传输
+type gatewaySigner struct {
+	secret []byte
+	prefix string
+	base   http.RoundTripper
+}
+
+// RoundTrip 签名并发送请求
+// 随机数取自 crypto/rand，替换 oss.DefaultRandom 的多个进程不会发送相同的随机数而被网关当作重放拒绝
+func (signer *gatewaySigner) RoundTrip(request *http.Request) (*http.Response, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	nonce := hex.EncodeToString(random)
+	signed := request.Clone(request.Context())
+	timestamp := strconv.FormatInt(oss.Now().Unix(), 10)
+	signed.Header.Set(gatewayTimestampHeader, timestamp)
+	signed.Header.Set(gatewayNonceHeader, nonce)
+	signed.Header.Set(gatewaySignatureHeader, signGatewayRequest(signer.secret, signed.Method, strings.TrimPrefix(signed.URL.Path, signer.prefix),
+		signed.URL.Query(), timestamp, nonce, signed.Header.Get(gatewayContentHashHeader), signed.Header.Get("Content-Encoding"), signed.Header.Get(gatewayPutOptionsHeader)))
+
+	base := signer.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(signed)
+}
+
+// gatewayBody 已计算摘要的请求体，可以重复读取以便重试
+type gatewayBody struct {
+	reader   io.ReaderAt
+	size     int64
+	hash     string
+	encoding string
+}
+
+// open 从头读取请求体
+func (body *gatewayBody) open() (io.ReadCloser, error) {
+	return io.NopCloser(io.NewSectionReader(body.reader, 0, body.size)), nil
+}
+
+// newBody 准备上传的请求体
+// 签名需要先知道内容摘要，可以随机读取的内容（例如本地文件）先读一遍计算摘要再从头发送，
+// 其他内容和需要压缩的内容边计算摘要边写入临时文件，不会整体读入内存
+// 参数:
+//   - reader: 文件内容读取器，可寻址时已经重置到开始位置
+// 返回:
+//   - *gatewayBody: 请求体
+//   - func(): 删除临时文件的清理函数
+//   - error: 错误信息
+func (client GatewayClient) newBody(reader io.Reader) (*gatewayBody, func(), error) {
+	if at, ok := reader.(io.ReaderAt); ok && !client.Compression {
+		if _, seekable := reader.(io.Seeker); seekable {
+			if size := oss.ReaderSize(reader); size >= 0 {
+				hash := sha256.New()
+				if _, err := io.Copy(hash, io.NewSectionReader(at, 0, size)); err != nil {
+					return nil, nil, err
+				}
+				return &gatewayBody{reader: at, size: size, hash: hex.EncodeToString(hash.Sum(nil))}, func() {}, nil
+			}
+		}
+	}
+
+	file, err := oss.CreateTempFile("synology-gateway-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { oss.RemoveTempFile(file) }
+	hash := sha256.New()
+	body := &gatewayBody{reader: file}
+	if client.Compression {
+		body.encoding = "gzip"
+		writer := gzip.NewWriter(io.MultiWriter(file, hash))
+		if _, err = io.Copy(writer, reader); err == nil {
+			err = writer.Close()
+		}
+	} else {
+		_, err = io.Copy(io.MultiWriter(file, hash), reader)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	body.size, body.hash = info.Size(), hex.EncodeToString(hash.Sum(nil))
+	return body, cleanup, nil
+}
+
+// decodeGatewayResponse 解压gzip编码的响应体
+func decodeGatewayResponse(response *http.Response) error {
+	if response.Header.Get("Content-Encoding") != "gzip" {
+		return nil
+	}
+	reader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		return err
+	}
+	response.Body = gzipReadCloser{Reader: reader, body: response.Body}
+	response.Header.Del("Content-Encoding")
+	response.ContentLength = -1
+	return nil
+}
+
+// gzipReadCloser 关闭时同时关闭解压器和原始响应体
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close 关闭解压器和原始响应体
+func (reader gzipReadCloser) Close() error {
+	reader.Reader.Close()
+	return reader.body.Close()
+}
+
+// call 发送签名请求并解析JSON响应
+func (client GatewayClient) call(method, path string, query url.Values, body *gatewayBody, header http.Header, result interface{}) error {
+	response, err := client.do(method, path, query, body, header)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// decodeGatewayError 将网关错误响应还原为统一错误
+func decodeGatewayError(response *http.Response) error {
+	gatewayErr := gatewayError{}
+	if err := json.NewDecoder(response.Body).Decode(&gatewayErr); err != nil {
+		return fmt.Errorf("gateway returned status %d", response.StatusCode)
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: %s", oss.ErrPermissionDenied, gatewayErr.Message)
+	}
+	for _, known := range gatewayErrors {
+		if known.code == gatewayErr.Code {
+			return fmt.Errorf("%w: %s", known.err, gatewayErr.Message)
+		}
+	}
+	return errors.New(gatewayErr.Message)
+}
+
+// toObject 将网关传输格式转换为对象
+func (client GatewayClient) toObject(object *gatewayObject) *oss.Object {
+	if object == nil {
+		return nil
+	}
+	return &oss.Object{
+		Path:             object.Path,
+		Name:             object.Name,
+		LastModified:     object.LastModified,
+		Size:             object.Size,
+		ContentType:      object.ContentType,
+		ETag:             object.ETag,
+		Metadata:         object.Metadata,
+		LinkTarget:       object.LinkTarget,
+		StorageInterface: client,
+	}
+}
+
+// Get 获取指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (client GatewayClient) Get(path string) (file *os.File, err error) {
+	// 获取文件流
+	readCloser, err := client.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "synology")
+}
+
+// GetStream 获取指定路径文件的流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (client GatewayClient) GetStream(path string) (io.ReadCloser, error) {
+	response, err := client.do(http.MethodGet, "/object", url.Values{"path": {path}}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 由网关服务端按范围读取
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client GatewayClient) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"path":   {path},
+		"offset": {strconv.FormatInt(offset, 10)},
+		"length": {strconv.FormatInt(length, 10)},
+	}
+	response, err := client.do(http.MethodGet, "/object", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+// Stat 获取指定路径文件的元信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象元信息
+//   - error: 错误信息
+func (client GatewayClient) Stat(path string) (*oss.Object, error) {
+	object := &gatewayObject{}
+	if err := client.call(http.MethodGet, "/stat", url.Values{"path": {path}}, nil, nil, object); err != nil {
+		return nil, err
+	}
+	return client.toObject(object), nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息
+func (client GatewayClient) Exists(path string) (bool, error) {
+	var exists bool
+	err := client.call(http.MethodGet, "/exists", url.Values{"path": {path}}, nil, nil, &exists)
+	return exists, err
+}
+
+// Put 上传文件到指定路径
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client GatewayClient) Put(path string, reader io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(path, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client GatewayClient) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 如果是可寻址的读取器，重置到开始位置
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		seeker.Seek(0, 0)
+	}
+
+	// 网关请求需要对内容签名，进度回调统计读取源内容的字节数
+	if opts != nil {
+		reader = opts.WrapReader(reader, oss.ReaderSize(reader))
+	}
+	body, cleanup, err := client.newBody(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	header := http.Header{}
+	if opts != nil {
+		data, err := json.Marshal(opts)
+		if err != nil {
+			return nil, err
+		}
+		header.Set(gatewayPutOptionsHeader, string(data))
+	}
+
+	object := &gatewayObject{}
+	if err := client.call(http.MethodPut, "/object", url.Values{"path": {path}}, body, header, object); err != nil {
+		return nil, err
+	}
+	return client.toObject(object), nil
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 数据通过管道交给 Put 上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client GatewayClient) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
+}
+
+// Delete 删除指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (client GatewayClient) Delete(path string) error {
+	return client.call(http.MethodDelete, "/object", url.Values{"path": {path}}, nil, nil, nil)
+}
+
+// DeleteObjects 批量删除多个文件
+// 并发逐个调用网关删除接口
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client GatewayClient) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsConcurrently(client, paths, oss.DefaultDeleteConcurrency)
+}
+
+// DeleteDir 删除目录下的全部对象
+// 通过网关列举目录后批量删除
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client GatewayClient) DeleteDir(dir string) error {
+	return oss.DeleteDirByList(client, dir)
+}
+
+// Copy 复制文件到目标路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client GatewayClient) Copy(srcPath, dstPath string) error {
+	return client.call(http.MethodPost, "/copy", url.Values{"src": {srcPath}, "dst": {dstPath}}, nil, nil, nil)
+}
+
+// Move 移动文件到目标路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client GatewayClient) Move(srcPath, dstPath string) error {
+	return client.call(http.MethodPost, "/move", url.Values{"src": {srcPath}, "dst": {dstPath}}, nil, nil, nil)
+}
+
+// List 列出指定路径下的所有对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (client GatewayClient) List(path string) ([]*oss.Object, error) {
+	var results []*gatewayObject
+	if err := client.call(http.MethodGet, "/list", url.Values{"path": {path}}, nil, nil, &results); err != nil {
+		return nil, err
+	}
+
+	objects := make([]*oss.Object, 0, len(results))
+	for _, result := range results {
+		objects = append(objects, client.toObject(result))
+	}
+	return objects, nil
+}
+
+// GetURL 获取文件的访问URL
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 访问URL
+//   - error: 错误信息
+func (client GatewayClient) GetURL(path string) (string, error) {
+	var rawURL string
+	err := client.call(http.MethodGet, "/url", url.Values{"path": {path}}, nil, nil, &rawURL)
+	return rawURL, err
+}
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client GatewayClient) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	return "", fmt.Errorf("%w: synology gateway does not support signed URL", oss.ErrNotSupported)
+}
+
+// GetUploadURL 生成客户端直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址
+//   - error: 错误信息
+func (client GatewayClient) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	return nil, fmt.Errorf("%w: synology gateway does not support upload URL", oss.ErrNotSupported)
+}
+
+// GetEndpoint 获取网关服务端地址
+// 返回:
+//   - string: 网关服务端地址
+func (client GatewayClient) GetEndpoint() string {
+	return client.Endpoint
+}
@@ -0,0 +1,152 @@
+package synology_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/synology"
+	"github.com/smart-unicom/oss/tests"
+)
+
+func TestGateway(t *testing.T) {
+	server := httptest.NewServer(synology.NewGatewayServer(filesystem.New(t.TempDir()), []byte("secret")))
+	defer server.Close()
+
+	tests.TestAll(synology.NewGatewayClient(server.URL, []byte("secret")), t)
+}
+
+func TestGatewaySignature(t *testing.T) {
+	server := httptest.NewServer(synology.NewGatewayServer(filesystem.New(t.TempDir()), []byte("secret")))
+	defer server.Close()
+
+	client := synology.NewGatewayClient(server.URL, []byte("wrong"))
+	if _, err := client.Put("/a.txt", strings.NewReader("sample")); !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("Should return ErrPermissionDenied when signed with wrong secret, but got %v", err)
+	}
+
+	// 未签名的请求
+	if resp, err := http.Get(server.URL + "/object?path=/a.txt"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Unsigned request should be rejected, but got %v, %v", resp, err)
+	}
+
+	// 篡改路径的请求
+	tampered := synology.NewGatewayClient(server.URL, []byte("secret"))
+	tampered.HTTPClient = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		request.URL.RawQuery = "path=/b.txt"
+		return http.DefaultTransport.RoundTrip(request)
+	})}
+	if _, err := tampered.Put("/a.txt", strings.NewReader("sample")); !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("Should return ErrPermissionDenied when request tampered, but got %v", err)
+	}
+}
+
+func TestGatewayReplay(t *testing.T) {
+	gateway := synology.NewGatewayServer(filesystem.New(t.TempDir()), []byte("secret"))
+	gateway.MaxBodySize = 16
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	var captured *http.Request
+	var body []byte
+	client := synology.NewGatewayClient(server.URL, []byte("secret"))
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		if request.Method == http.MethodPut {
+			captured = request.Clone(request.Context())
+			body, _ = io.ReadAll(request.Body)
+			request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		return http.DefaultTransport.RoundTrip(request)
+	})}
+	if _, err := client.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("No error should happen when put, but got %v", err)
+	}
+	if captured.Header.Get("X-Gateway-Nonce") == "" {
+		t.Fatalf("Signed requests should carry a nonce")
+	}
+
+	// 重放捕获的请求
+	replay, _ := http.NewRequest(captured.Method, captured.URL.String(), bytes.NewReader(body))
+	replay.Header = captured.Header.Clone()
+	if resp, err := http.DefaultClient.Do(replay); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Replayed request should be rejected, but got %v, %v", resp, err)
+	}
+
+	// 篡改内容编码的请求
+	tampered := synology.NewGatewayClient(server.URL, []byte("secret"))
+	tampered.HTTPClient = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		request.Header.Set("Content-Encoding", "gzip")
+		return http.DefaultTransport.RoundTrip(request)
+	})}
+	if _, err := tampered.Put("/b.txt", strings.NewReader("sample")); !errors.Is(err, oss.ErrPermissionDenied) {
+		t.Errorf("Should return ErrPermissionDenied when content encoding tampered, but got %v", err)
+	}
+
+	if _, err := client.Put("/large.txt", strings.NewReader(strings.Repeat("x", 17))); !errors.Is(err, oss.ErrTooLarge) {
+		t.Errorf("Should return ErrTooLarge when body exceeds MaxBodySize, but got %v", err)
+	}
+	if exists, _ := client.Exists("/large.txt"); exists {
+		t.Errorf("Rejected body should not be stored")
+	}
+
+	// 签名有效但无法解析的范围参数
+	query := url.Values{"path": {"/a.txt"}, "offset": {"x"}, "length": {"2"}}
+	timestamp, emptyHash := strconv.FormatInt(time.Now().Unix(), 10), sha256.Sum256(nil)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(strings.Join([]string{http.MethodGet, "/object", query.Encode(), timestamp, "range-nonce", hex.EncodeToString(emptyHash[:]), "", ""}, "\n")))
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/object?"+query.Encode(), nil)
+	request.Header.Set("X-Gateway-Timestamp", timestamp)
+	request.Header.Set("X-Gateway-Nonce", "range-nonce")
+	request.Header.Set("X-Gateway-Content-Sha256", hex.EncodeToString(emptyHash[:]))
+	request.Header.Set("X-Gateway-Signature", hex.EncodeToString(mac.Sum(nil)))
+	if resp, err := http.DefaultClient.Do(request); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Invalid range parameters should return 400, but got %v, %v", resp, err)
+	}
+}
+
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return fn(request)
+}
+
+func TestGatewayCompression(t *testing.T) {
+	var encodings []string
+	server := httptest.NewServer(synology.NewGatewayServer(filesystem.New(t.TempDir()), []byte("secret")))
+	defer server.Close()
+
+	client := synology.NewGatewayClient(server.URL, []byte("secret"))
+	client.Compression = true
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := http.DefaultTransport.RoundTrip(request)
+		if err == nil {
+			encodings = append(encodings, request.Header.Get("Content-Encoding")+"/"+response.Header.Get("Content-Encoding"))
+		}
+		return response, err
+	})}
+
+	if _, err := client.Put("/docs/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("No error should happen when put with compression, but got %v", err)
+	}
+	objects, err := client.List("/docs")
+	if err != nil || len(objects) != 1 || objects[0].Size != 6 {
+		t.Errorf("Compressed list should be decoded, but got %v, %v", objects, err)
+	}
+	if len(encodings) < 2 || encodings[0] != "gzip/gzip" || encodings[len(encodings)-1] != "/gzip" {
+		t.Errorf("Request body and JSON responses should be gzip encoded, but got %v", encodings)
+	}
+
+	tests.TestAll(client, t)
+}
@@ -5,13 +5,17 @@ package synology
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	pathpkg "path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -54,23 +58,75 @@ type Config struct {
 	OtpCode string
 	// SharedFolder 共享文件夹名称
 	SharedFolder string
+	// UnsortedList 是否关闭List结果的排序，关闭后按FileStation返回顺序返回
+	UnsortedList bool
+	// Redirect 下载时跟随跳转的策略，跳转到其他主机时不发送会话Cookie和SynoToken
+	Redirect oss.RedirectPolicy
+	// HTTPClient 访问FileStation使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用 oss.DefaultHTTPClient
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，为nil时使用默认配置，只重试幂等且内容可以重新读取的请求
+	Retry *oss.RetryConfig
 }
 
+const (
+	// maxPathBytes FileStation完整路径的最大字节数
+	maxPathBytes = 4096
+	// maxNameBytes FileStation文件名的最大字节数
+	maxNameBytes = 255
+)
+
+// fileNotFoundCode FileStation文件不存在的错误码
+const fileNotFoundCode = 408
+
+// errFileNotFound 文件不存在
+var errFileNotFound = fmt.Errorf("file not found: %w", oss.ErrObjectNotFound)
+
 // New 初始化Synology NAS存储客户端
 // 参数:
 //   - config: Synology NAS配置信息
 // 返回:
 //   - *Client: Synology NAS存储客户端实例
-func New(config *Config) *Client {
+//   - error: 登录或获取API列表失败时返回错误
+func New(config *Config) (*Client, error) {
 	// 创建客户端实例
 	client := &Client{Config: config}
 	// 登录FileStation应用
-	client.Login("FileStation")
+	if err := client.Login("FileStation"); err != nil {
+		return nil, err
+	}
 	// 获取FileStation API列表
-	client.GetAPIList("FileStation")
+	if err := client.GetAPIList("FileStation"); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// MustNew 初始化Synology NAS存储客户端，失败时panic
+// 参数:
+//   - config: Synology NAS配置信息
+// 返回:
+//   - *Client: Synology NAS存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		panic(err)
+	}
 	return client
 }
 
+// httpClient 返回按重试配置重试的HTTP客户端，未配置时使用带超时的 oss.DefaultHTTPClient
+// 返回:
+//   - *http.Client: HTTP客户端
+func (client Client) httpClient() *http.Client {
+	httpClient := client.Config.HTTPClient
+	if httpClient == nil {
+		httpClient = oss.DefaultHTTPClient
+	}
+	return client.Config.Retry.Client(httpClient)
+}
+
 // Get 获取指定路径的文件
 // 参数:
 //   - path: 文件路径
@@ -83,26 +139,18 @@ func (client Client) Get(path string) (file *os.File, err error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建临时文件并复制内容
-	if file, err = ioutil.TempFile("/tmp", "synology"); err == nil {
-		defer readCloser.Close()
-		_, err = io.Copy(file, readCloser)
-		// 重置文件指针到开始位置
-		file.Seek(0, 0)
-	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "synology")
 }
 
 // GetStream 获取指定路径文件的流
+// 按 Config.Redirect 跟随跳转，返回的 *oss.DownloadStream 记录最终的下载地址
 // 参数:
 //   - path: 文件路径
 // 返回:
 //   - io.ReadCloser: 可读流
 //   - error: 错误信息
 func (client Client) GetStream(path string) (io.ReadCloser, error) {
-	sharedFolder := client.Config.SharedFolder
 	baseURL := client.Config.Endpoint + "/webapi/entry.cgi"
 	path = filepath.ToSlash(path)
 
@@ -115,7 +163,7 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	params.Set("api", apiName)
 	params.Set("version", "2")
 	params.Set("method", "download")
-	params.Set("path", sharedFolder+path)
+	params.Set("path", client.fullPath(path))
 	params.Set("mode", "download")
 	params.Set("SynoToken", client.SynoToken)
 	params.Set("_sid", client.SId)
@@ -128,23 +176,143 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	}
 
 	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	// 不手动设置Accept-Encoding，由Transport协商gzip并自动解压响应
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Cookie", "stay_login=1; id="+client.SId)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("X-SYNO-TOKEN", client.SynoToken) // not necessary
 
-	resp, err := http.Get(url)
+	resp, err := client.Config.Redirect.Client(client.httpClient()).Do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if location := resp.Header.Get("Location"); location != "" {
+			return nil, fmt.Errorf("download failed, redirected to %s with redirects disabled", location)
+		}
 		return nil, fmt.Errorf("download failed, status code: %d", resp.StatusCode)
 	}
 
-	return resp.Body, err
+	return oss.NewDownloadStream(resp), nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// FileStation下载接口不支持Range，跳过偏移量之前的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+
+	stream, err := client.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return oss.LimitStream(stream, offset, length)
+}
+
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	baseURL := client.Config.Endpoint + "/webapi/entry.cgi"
+	path = filepath.ToSlash(path)
+
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	apiName := "SYNO.FileStation.List"
+
+	params := url.Values{}
+	params.Set("api", apiName)
+	params.Set("version", "2")
+	params.Set("method", "getinfo")
+	params.Set("path", client.fullPath(path))
+	params.Set("additional", `["size","time","type"]`)
+	params.Set("SynoToken", client.SynoToken)
+	params.Set("_sid", client.SId)
+
+	resp, err := client.httpClient().Get(baseURL + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stat failed, status code: %d", resp.StatusCode)
+	}
+
+	var responseJSON struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Files []struct {
+				Code       int    `json:"code"`
+				IsDir      bool   `json:"isdir"`
+				Name       string `json:"name"`
+				Additional struct {
+					Size int64  `json:"size"`
+					Type string `json:"type"`
+					Time struct {
+						Mtime int64 `json:"mtime"`
+					} `json:"time"`
+				} `json:"additional"`
+			} `json:"files"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&responseJSON); err != nil {
+		return nil, err
+	}
+
+	if !responseJSON.Success || len(responseJSON.Data.Files) == 0 {
+		return nil, fmt.Errorf("stat failed for %s", path)
+	}
+
+	file := responseJSON.Data.Files[0]
+	// 文件不存在时，FileStation会在文件条目中返回错误码
+	if file.Code == fileNotFoundCode {
+		return nil, fmt.Errorf("stat failed for %s: %w", path, errFileNotFound)
+	}
+	if file.Code != 0 {
+		return nil, fmt.Errorf("stat failed for %s, error code: %d", path, file.Code)
+	}
+	if file.IsDir {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	return &oss.Object{
+		Path:             path,
+		Name:             file.Name,
+		LastModified:     oss.NormalizeTime(time.Unix(file.Additional.Time.Mtime, 0)),
+		Size:             file.Additional.Size,
+		ContentType:      mime.TypeByExtension(filepath.Ext(path)),
+		StorageInterface: &client,
+	}, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	_, err := client.Stat(path)
+	if errors.Is(err, errFileNotFound) {
+		return false, nil
+	}
+	return err == nil, err
 }
 
 // GetAPIList 获取API列表
@@ -160,7 +328,7 @@ func (client *Client) GetAPIList(app string) error {
 	params.Set("method", "query")
 	params.Set("query", "all")
 
-	response, err := http.Get(baseURL + queryPath + "&" + params.Encode())
+	response, err := client.httpClient().Get(baseURL + queryPath + "&" + params.Encode())
 
 	if err != nil {
 		return err
@@ -228,7 +396,7 @@ func (client *Client) Login(application string) error {
 		}
 	} else {
 		// Check request for error:
-		response, err := http.Get(baseURL + loginAPI)
+		response, err := client.httpClient().Get(baseURL + loginAPI)
 		if err != nil {
 			return err
 		}
@@ -293,7 +461,44 @@ func (client Client) getErrorCode(response map[string]interface{}) int {
 //   - *oss.Object: 上传成功后的对象信息
 //   - error: 错误信息
 func (client *Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err error) {
-	sharedFolder := client.Config.SharedFolder
+	return client.PutWithOptions(urlPath, reader, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - urlPath: 文件上传路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+// 返回:
+//   - *oss.Object: 上传成功后的对象信息
+//   - error: 错误信息
+func (client *Client) PutWithOptions(urlPath string, reader io.Reader, opts *oss.PutOptions) (r *oss.Object, err error) {
+	// 规范化来自Windows的路径
+	if urlPath, err = oss.NormalizePath(urlPath); err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+
+	// Synology不支持对象级ACL
+	if opts.ACL != "" {
+		return nil, fmt.Errorf("%w: synology does not support per-object ACL", oss.ErrNotSupported)
+	}
+	// Synology不支持对象标签
+	if len(opts.Tags) > 0 {
+		return nil, fmt.Errorf("%w: synology does not support object tags", oss.ErrNotSupported)
+	}
+	// FileStation上传不支持校验和
+	if opts.Checksum != "" {
+		return nil, fmt.Errorf("%w: synology does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+	}
+
+	// 按冲突策略确定上传路径
+	if urlPath, err = opts.ResolvePath(client, urlPath); err != nil {
+		return nil, err
+	}
+
 
 	apiName := "SYNO.FileStation.Upload"
 	baseURL := client.Config.Endpoint + "/webapi/"
@@ -313,11 +518,16 @@ func (client *Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err
 		fmt.Println("Error parsing URL:", err)
 	}
 	path := parserURL.Path
+
+	// 在发送请求前校验路径长度
+	if err = oss.ValidateKeyLength(client.fullPath(path), maxPathBytes, maxNameBytes); err != nil {
+		return nil, err
+	}
 	dir := filepath.Dir(path)
 	// change windows path to linux path
 	dir = filepath.ToSlash(dir)
 
-	err = writer.WriteField("path", sharedFolder+dir)
+	err = writer.WriteField("path", client.fullPath(dir))
 	if err != nil {
 		return nil, err
 	}
@@ -349,21 +559,24 @@ func (client *Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err
 
 	url := baseURL + loginAPI + "?" + params.Encode()
 
-	req, err := http.NewRequest("POST", url, body)
+	// 回调上传进度，总字节数包含表单字段，包装后需要显式设置内容长度
+	size := int64(body.Len())
+	req, err := http.NewRequest("POST", url, opts.WrapReader(body, size))
 	if err != nil {
 		return nil, err
 	}
+	req.ContentLength = size
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	// 不手动设置Accept-Encoding，由Transport协商gzip并自动解压响应
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Cookie", "stay_login=1; id="+client.SId)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("X-SYNO-TOKEN", client.SynoToken) // not necessary
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.httpClient().Do(req)
 
 	if err != nil {
 		return nil, err
@@ -374,23 +587,38 @@ func (client *Client) Put(urlPath string, reader io.Reader) (r *oss.Object, err
 		return nil, fmt.Errorf("upload failed, status code: %d", resp.StatusCode)
 	}
 
-	now := time.Now()
-	return &oss.Object{
+	object := &oss.Object{
 		Path:             urlPath,
 		Name:             filepath.Base(urlPath),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, nil
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if stat, err := client.Stat(urlPath); err == nil {
+		object.LastModified = stat.LastModified
+		object.Size = stat.Size
+	}
+
+	return object, nil
 
 }
 
+// NewWriter 创建流式写入指定路径的写入器
+// 数据通过管道交给 Put 上传
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client *Client) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
+}
+
 // Delete 删除指定路径的文件
 // 参数:
 //   - path: 要删除的文件路径
 // 返回:
 //   - error: 错误信息
 func (client Client) Delete(path string) error {
-	sharedFolder := client.Config.SharedFolder
 
 	apiName := "SYNO.FileStation.Delete"
 
@@ -401,7 +629,7 @@ func (client Client) Delete(path string) error {
 	params.Set("api", apiName)
 	params.Set("version", "2")
 	params.Set("method", "start")
-	params.Set("path", sharedFolder+path)
+	params.Set("path", client.fullPath(path))
 	params.Set("SynoToken", client.SynoToken)
 	params.Set("_sid", client.SId)
 
@@ -413,14 +641,14 @@ func (client Client) Delete(path string) error {
 	}
 
 	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	// 不手动设置Accept-Encoding，由Transport协商gzip并自动解压响应
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Cookie", "stay_login=1; id="+client.SId)
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("X-SYNO-TOKEN", client.SynoToken) // not necessary
 
-	resp, err := http.Get(req_url)
+	resp, err := client.httpClient().Get(req_url)
 	if err != nil {
 		return err
 	}
@@ -438,82 +666,361 @@ func (client Client) Delete(path string) error {
 	return nil
 }
 
-// List 列出指定路径下的所有文件对象
+// DeleteObjects 批量删除多个文件
+// 并发逐个调用FileStation删除接口
 // 参数:
-//   - path: 目录路径
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsConcurrently(&client, paths, oss.DefaultDeleteConcurrency)
+}
+
+// DeleteDir 删除目录及其下的全部文件
+// FileStation删除接口会递归删除目录，只需要一次请求
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(filepath.ToSlash(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+	return client.Delete("/" + strings.TrimSuffix(prefix, "/"))
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
 // 返回:
-//   - []*oss.Object: 文件对象列表
 //   - error: 错误信息
-func (client Client) List(path string) (objects []*oss.Object, err error) {
-	sharedFolder := client.Config.SharedFolder
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	// FileStation的复制无法指定目标文件名，通过流复制
+	return oss.CopyByStream(&client, srcPath, dstPath)
+}
 
-	apiName := "SYNO.FileStation.List"
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	srcPath = filepath.ToSlash(srcPath)
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+
+	// 在发送请求前校验路径长度
+	if err := oss.ValidateKeyLength(client.fullPath(dstPath), maxPathBytes, maxNameBytes); err != nil {
+		return err
+	}
+
+	// FileStation只支持同一目录下的重命名，其他情况通过复制后删除实现
+	if pathpkg.Dir(srcPath) != pathpkg.Dir(dstPath) {
+		return oss.MoveByCopy(&client, srcPath, dstPath)
+	}
 
 	baseURL := client.Config.Endpoint + "/webapi/entry.cgi"
-	path = filepath.ToSlash(path)
 
 	params := url.Values{}
-	params.Set("api", apiName)
+	params.Set("api", "SYNO.FileStation.Rename")
 	params.Set("version", "2")
-	params.Set("method", "list")
-	params.Set("folder_path", sharedFolder+"/"+path)
+	params.Set("method", "rename")
+	params.Set("path", client.fullPath(srcPath))
+	params.Set("name", pathpkg.Base(dstPath))
 	params.Set("SynoToken", client.SynoToken)
 	params.Set("_sid", client.SId)
 
-	req_url := baseURL + "?" + params.Encode()
+	resp, err := client.httpClient().Get(baseURL + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-	req, err := http.NewRequest("GET", req_url, nil)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rename failed, status code: %d", resp.StatusCode)
+	}
+
+	var responseJSON map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&responseJSON); err != nil {
+		return err
+	}
+
+	if code := client.getErrorCode(responseJSON); code != 0 {
+		return fmt.Errorf("rename %s to %s failed, error code: %d", srcPath, dstPath, code)
+	}
+	return nil
+}
+
+// listPageSize FileStation每次列出的最大条目数
+const listPageSize = 1000
+
+// listFile FileStation列表中的文件条目
+type listFile struct {
+	Path       string `json:"path"`
+	Name       string `json:"name"`
+	IsDir      bool   `json:"isdir"`
+	Additional struct {
+		Size int64 `json:"size"`
+		Time struct {
+			Mtime int64 `json:"mtime"`
+		} `json:"time"`
+	} `json:"additional"`
+}
+
+// List 列出指定路径下的所有文件对象
+// 按FileStation的offset/limit分页请求，直到取完全部条目
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 文件对象列表
+//   - error: 错误信息
+func (client Client) List(path string) (objects []*oss.Object, err error) {
+	files, err := client.listDir(path)
 	if err != nil {
 		return nil, err
 	}
+	for _, file := range files {
+		object, err := client.listObject(file)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
 
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-CN;q=0.8,zh;q=0.7")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Cookie", "stay_login=1; id="+client.SId)
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("X-SYNO-TOKEN", client.SynoToken) // not necessary
+	// FileStation不保证按路径字典序返回
+	if !client.Config.UnsortedList {
+		oss.SortObjects(objects)
+	}
+
+	return objects, nil
+}
+
+// ListWithOptions 按选项列出指定路径下的文件对象
+// FileStation只能逐级列出目录，递归时依次列出每个子目录，非递归时子目录作为 Prefixes 返回
+// 参数:
+//   - path: 目录路径
+//   - opts: 列出选项
+// 返回:
+//   - *oss.ListResult: 列出结果
+//   - error: 错误信息
+func (client Client) ListWithOptions(path string, opts oss.ListOptions) (*oss.ListResult, error) {
+	var (
+		objects  []*oss.Object
+		prefixes []string
+		dirs     = []string{filepath.ToSlash(path)}
+	)
+	for len(dirs) > 0 {
+		files, err := client.listDir(dirs[0])
+		if err != nil {
+			return nil, err
+		}
+		dirs = dirs[1:]
+
+		for _, file := range files {
+			object, err := client.listObject(file)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case !file.IsDir:
+				objects = append(objects, object)
+			case opts.Recursive:
+				dirs = append(dirs, strings.TrimPrefix(object.Path, "/"))
+			default:
+				prefixes = append(prefixes, object.Path)
+			}
+		}
+	}
+	return opts.Result(path, objects, prefixes), nil
+}
+
+// listDir 分页列出一个目录中的全部条目，包括子目录
+// 参数:
+//   - path: 不含共享文件夹的目录路径
+// 返回:
+//   - []listFile: 目录中的条目
+//   - error: 错误信息
+func (client Client) listDir(path string) ([]listFile, error) {
+	folder := client.fullPath(path)
+	method := "list"
+	if folder == "/" {
+		// 未设置共享文件夹时根目录下是各个共享文件夹
+		method = "list_share"
+	}
+
+	var entries []listFile
+	for offset := 0; ; {
+		files, total, err := client.listPage(method, folder, offset)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, files...)
+
+		offset += len(files)
+		if len(files) == 0 || offset >= total {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// listObject 将列表条目转换为对象，路径中去掉共享文件夹
+// 参数:
+//   - file: 列表条目
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) listObject(file listFile) (*oss.Object, error) {
+	// 同一次请求返回大小和修改时间（Unix秒），无需逐个 Stat
+	return &oss.Object{
+		Path:             client.relativePath(file.Path),
+		Name:             pathpkg.Base(file.Path),
+		Size:             file.Additional.Size,
+		LastModified:     oss.NormalizeTime(time.Unix(file.Additional.Time.Mtime, 0)),
+		StorageInterface: &client,
+	}, nil
+}
+
+// listPage 列出目录中从offset开始的一页条目
+// 参数:
+//   - method: list 列出目录，list_share 列出共享文件夹
+//   - folder: 包含共享文件夹的目录路径，列出共享文件夹时忽略
+//   - offset: 起始条目序号
+// 返回:
+//   - []listFile: 本页条目
+//   - int: 目录中的条目总数
+//   - error: 错误信息
+func (client Client) listPage(method, folder string, offset int) ([]listFile, int, error) {
+	baseURL := client.Config.Endpoint + "/webapi/entry.cgi"
 
-	resp, err := http.Get(req_url)
+	params := url.Values{}
+	params.Set("api", "SYNO.FileStation.List")
+	params.Set("version", "2")
+	params.Set("method", method)
+	if method == "list" {
+		params.Set("folder_path", folder)
+	}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(listPageSize))
+	params.Set("additional", `["size","time"]`)
+	params.Set("SynoToken", client.SynoToken)
+	params.Set("_sid", client.SId)
+
+	resp, err := client.httpClient().Get(baseURL + "?" + params.Encode())
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, err
+		return nil, 0, fmt.Errorf("list failed, status code: %d", resp.StatusCode)
 	}
 
-	var responseJSON map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&responseJSON)
-	if err != nil {
-		return nil, err
+	var responseJSON struct {
+		Success bool `json:"success"`
+		Data    struct {
+			Total  int        `json:"total"`
+			Files  []listFile `json:"files"`
+			Shares []listFile `json:"shares"`
+		} `json:"data"`
+		Error struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&responseJSON); err != nil {
+		return nil, 0, err
+	}
+	if !responseJSON.Success {
+		return nil, 0, fmt.Errorf("list %s failed, error code: %d", folder, responseJSON.Error.Code)
 	}
+	if method == "list_share" {
+		return responseJSON.Data.Shares, responseJSON.Data.Total, nil
+	}
+	return responseJSON.Data.Files, responseJSON.Data.Total, nil
+}
 
-	for _, content := range responseJSON["data"].(map[string]interface{})["files"].([]interface{}) {
-		now := time.Now()
-		path := content.(map[string]interface{})["path"].(string)
-		// remove top shared path
-		parsedUrl, err := url.Parse(path)
+// ListShares 列出当前账号可以访问的共享文件夹
+// 返回:
+//   - []Share: 共享文件夹列表，按名称字典序排列
+//   - error: 错误信息
+func (client Client) ListShares() ([]Share, error) {
+	var shares []Share
+	for offset := 0; ; {
+		files, total, err := client.listPage("list_share", "", offset)
 		if err != nil {
 			return nil, err
 		}
-		pathParts := strings.Split(parsedUrl.Path, "/")
-		if len(pathParts) > 1 {
-			pathParts = append(pathParts[:1], pathParts[2:]...)
+		for _, file := range files {
+			shares = append(shares, Share{Name: file.Name, Path: file.Path})
 		}
-		parsedUrl.Path = strings.Join(pathParts, "/")
-		path = parsedUrl.String()
 
-		objects = append(objects, &oss.Object{
-			Path:             path,
-			Name:             filepath.Base(content.(map[string]interface{})["path"].(string)),
-			LastModified:     &now,
-			StorageInterface: &client,
-		})
+		offset += len(files)
+		if len(files) == 0 || offset >= total {
+			break
+		}
 	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].Name < shares[j].Name })
+	return shares, nil
+}
+
+// Share 共享文件夹
+type Share struct {
+	// Name 共享文件夹名称
+	Name string
+	// Path 共享文件夹路径，可以作为 Config.SharedFolder 使用，例如 /docs
+	Path string
+}
 
-	return objects, err
+// sharedFolder 返回以斜杠开头、不以斜杠结尾的共享文件夹路径，未设置时返回空字符串
+// 共享文件夹可以是多级目录，例如 /docs/team
+func (client Client) sharedFolder() string {
+	folder := strings.Trim(filepath.ToSlash(client.Config.SharedFolder), "/")
+	if folder == "" {
+		return ""
+	}
+	return "/" + folder
+}
+
+// fullPath 返回FileStation使用的完整路径，由共享文件夹和对象路径组成
+// 对象路径可以带或不带开头的斜杠，根目录为共享文件夹本身，未设置共享文件夹时为 /
+// 参数:
+//   - path: 对象路径
+// 返回:
+//   - string: 完整路径
+func (client Client) fullPath(path string) string {
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	folder := client.sharedFolder()
+	switch {
+	case path == "" && folder == "":
+		return "/"
+	case path == "":
+		return folder
+	}
+	return folder + "/" + path
+}
+
+// relativePath 返回FileStation完整路径对应的对象路径，去掉开头的共享文件夹
+// 参数:
+//   - full: FileStation返回的完整路径
+// 返回:
+//   - string: 以斜杠开头的对象路径
+func (client Client) relativePath(full string) string {
+	folder := client.sharedFolder()
+	if folder != "" && (full == folder || strings.HasPrefix(full, folder+"/")) {
+		full = full[len(folder):]
+	}
+	return "/" + strings.TrimPrefix(full, "/")
 }
 
 // GetEndpoint 获取服务端点
@@ -530,7 +1037,6 @@ func (client Client) GetEndpoint() string {
 //   - string: 公共访问URL
 //   - error: 错误信息
 func (client Client) GetURL(path string) (get_url string, err error) {
-	sharedFolder := client.Config.SharedFolder
 	baseURL := client.Config.Endpoint + "/webapi/entry.cgi"
 	path = filepath.ToSlash(path)
 
@@ -545,7 +1051,7 @@ func (client Client) GetURL(path string) (get_url string, err error) {
 	params.Set("api", apiName)
 	params.Set("version", "2")
 	params.Set("method", "download")
-	params.Set("path", sharedFolder+path)
+	params.Set("path", client.fullPath(path))
 	params.Set("mode", "download")
 	params.Set("SynoToken", client.SynoToken)
 	params.Set("_sid", client.SId)
@@ -554,3 +1060,35 @@ func (client Client) GetURL(path string) (get_url string, err error) {
 
 	return get_url, nil
 }
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	// 群晖的下载地址依赖登录会话，无法生成独立有效期的签名URL
+	return "", fmt.Errorf("%w: synology does not support signed URL", oss.ErrNotSupported)
+}
+
+// GetUploadURL 生成客户端直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	// 群晖的上传接口依赖登录会话，无法向客户端下发独立的直传凭证
+	return nil, fmt.Errorf("%w: synology does not support upload URL", oss.ErrNotSupported)
+}
+
+// URLStrategies 返回生成访问URL时依次尝试的方式
+// GetURL 返回的下载地址带有登录会话，不能交给其他用户，只能通过代理访问
+// 返回:
+//   - []oss.URLStrategy: 生成访问URL的方式
+func (client Client) URLStrategies() []oss.URLStrategy {
+	return []oss.URLStrategy{oss.URLProxied}
+}
@@ -1,57 +1,295 @@
-package synology_test
-
-import (
-	"testing"
-
-	"github.com/jinzhu/configor"
-	"github.com/smart-unicom/oss/synology"
-	"github.com/smart-unicom/oss/tests"
-)
-
-type Config struct {
-	AccessId  string
-	AccessKey string
-	Region    string
-	Bucket    string
-	Endpoint  string
-}
-
-type AppConfig struct {
-	Private Config
-	Public  Config
-}
-
-var client *synology.Client
-var privateClient *synology.Client
-
-func init() {
-	config := AppConfig{}
-	configor.New(&configor.Config{ENVPrefix: "SYNOLOGY"}).Load(&config)
-	if len(config.Private.AccessId) == 0 {
-		return
-	}
-
-	client = synology.New(&synology.Config{
-		AccessId:  config.Public.AccessId,
-		AccessKey: config.Public.AccessKey,
-		Endpoint:  config.Public.Endpoint,
-	})
-	privateClient = synology.New(&synology.Config{
-		AccessId:  config.Private.AccessId,
-		AccessKey: config.Private.AccessKey,
-		Endpoint:  config.Private.Endpoint,
-	})
-}
-
-func TestAll(t *testing.T) {
-	if client == nil {
-		t.Skip(`skip because of no config:
-
-
-			`)
-	}
-	clis := []*synology.Client{client, privateClient}
-	for _, cli := range clis {
-		tests.TestAll(cli, t)
-	}
-}
+package synology_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/configor"
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/synology"
+	"github.com/smart-unicom/oss/tests"
+)
+
+type Config struct {
+	AccessId  string
+	AccessKey string
+	Region    string
+	Bucket    string
+	Endpoint  string
+}
+
+type AppConfig struct {
+	Private Config
+	Public  Config
+}
+
+var client *synology.Client
+var privateClient *synology.Client
+
+func init() {
+	config := AppConfig{}
+	configor.New(&configor.Config{ENVPrefix: "SYNOLOGY"}).Load(&config)
+	if len(config.Private.AccessId) == 0 {
+		return
+	}
+
+	var err error
+	client, err = synology.New(&synology.Config{
+		AccessId:  config.Public.AccessId,
+		AccessKey: config.Public.AccessKey,
+		Endpoint:  config.Public.Endpoint,
+	})
+	if err != nil {
+		panic(err)
+	}
+	privateClient, err = synology.New(&synology.Config{
+		AccessId:  config.Private.AccessId,
+		AccessKey: config.Private.AccessKey,
+		Endpoint:  config.Private.Endpoint,
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	if client == nil {
+		t.Skip(`skip because of no config:
+
+
+			`)
+	}
+	clis := []*synology.Client{client, privateClient}
+	for _, cli := range clis {
+		tests.TestAll(cli, t)
+	}
+}
+
+func TestListPaging(t *testing.T) {
+	files := []string{"/share/docs/c.txt", "/share/docs/a.txt", "/share/docs/b.txt"}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("additional") != `["size","time"]` {
+			t.Errorf("List should request size and time in one call, but got %v", r.URL.Query().Get("additional"))
+		}
+		// 每页最多返回两个条目
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		end := min(offset+2, len(files))
+		var page []map[string]interface{}
+		for i, path := range files[offset:end] {
+			page = append(page, map[string]interface{}{
+				"path":       path,
+				"name":       path[len("/share/docs/"):],
+				"additional": map[string]interface{}{"size": offset + i + 1, "time": map[string]interface{}{"mtime": 1700000000}},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"total": len(files), "offset": offset, "files": page},
+		})
+	}))
+	defer server.Close()
+
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share"}}
+	objects, err := client.List("docs")
+	if err != nil {
+		t.Fatalf("No error should happen when list, but got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("List should fetch 2 pages, but got %v requests", requests)
+	}
+	if len(objects) != 3 || objects[0].Path != "/docs/a.txt" || objects[0].Size != 2 || objects[0].LastModified == nil {
+		t.Errorf("List should return all pages sorted with size and time, but got %+v", objects)
+	}
+}
+
+func TestGetStreamRedirect(t *testing.T) {
+	var cookies []string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		w.Write([]byte("sample"))
+	}))
+	defer node.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		if r.URL.Path == "/webapi/entry.cgi" {
+			// 先在同一主机内跳转，再跳转到其他节点
+			http.Redirect(w, r, "/local", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, node.URL+"/file", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share"}, SId: "sid"}
+	stream, err := client.GetStream("/a.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when follow redirects, but got %v", err)
+	}
+	data, _ := io.ReadAll(stream)
+	stream.Close()
+	if string(data) != "sample" || oss.StreamURL(stream) != node.URL+"/file" {
+		t.Errorf("GetStream should surface the final URL, but got %q from %q", data, oss.StreamURL(stream))
+	}
+	if len(cookies) != 3 || cookies[1] == "" || cookies[2] != "" {
+		t.Errorf("Session cookie should be sent on the same host only, but got %q", cookies)
+	}
+
+	client.Config.Redirect = oss.RedirectPolicy{MaxRedirects: 1}
+	if _, err := client.GetStream("/a.txt"); !errors.Is(err, oss.ErrTooManyRedirects) {
+		t.Errorf("GetStream should stop after MaxRedirects, but got %v", err)
+	}
+	client.Config.Redirect = oss.RedirectPolicy{MaxRedirects: -1}
+	if _, err := client.GetStream("/a.txt"); err == nil || !strings.Contains(err.Error(), "/local") {
+		t.Errorf("GetStream should report the redirect location when redirects are disabled, but got %v", err)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (transport *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	transport.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sample"))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share", HTTPClient: &http.Client{Transport: transport}}}
+	stream, err := client.GetStream("/a.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+	stream.Close()
+	if transport.requests != 1 {
+		t.Errorf("GetStream should use Config.HTTPClient, but got %v requests", transport.requests)
+	}
+
+	if oss.DefaultHTTPClient.Transport.(*http.Transport).ResponseHeaderTimeout != oss.DefaultResponseHeaderTimeout {
+		t.Errorf("DefaultHTTPClient should wait for response headers with a timeout")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("sample"))
+	}))
+	defer server.Close()
+
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share", Retry: &oss.RetryConfig{InitialBackoff: time.Millisecond}}}
+	stream, err := client.GetStream("/a.txt")
+	if err != nil {
+		t.Fatalf("GetStream should succeed after transient errors, but got %v", err)
+	}
+	data, _ := io.ReadAll(stream)
+	stream.Close()
+	if string(data) != "sample" || requests != 3 {
+		t.Errorf("GetStream should retry 503 twice, but got %q after %v requests", data, requests)
+	}
+
+	requests = 0
+	client.Config.Retry = &oss.RetryConfig{MaxAttempts: 1}
+	if _, err := client.GetStream("/a.txt"); err == nil || requests != 1 {
+		t.Errorf("GetStream should not retry when MaxAttempts is 1, but got %v after %v requests", err, requests)
+	}
+}
+
+func TestListWithOptions(t *testing.T) {
+	dirs := map[string][]map[string]interface{}{
+		"/share/docs":     {{"path": "/share/docs/a.txt", "name": "a.txt"}, {"path": "/share/docs/sub", "name": "sub", "isdir": true}},
+		"/share/docs/sub": {{"path": "/share/docs/sub/b.txt", "name": "b.txt"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		files := dirs[r.URL.Query().Get("folder_path")]
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"total": len(files), "offset": 0, "files": files},
+		})
+	}))
+	defer server.Close()
+
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share"}}
+	result, err := oss.ListWithOptions(client, "docs", oss.ListOptions{})
+	if err != nil {
+		t.Fatalf("No error should happen when list, but got %v", err)
+	}
+	if len(result.Objects) != 1 || result.Objects[0].Path != "/docs/a.txt" || len(result.Prefixes) != 1 || result.Prefixes[0] != "/docs/sub/" {
+		t.Errorf("Sub directories should be returned as prefixes, but got %+v", result)
+	}
+
+	result, _ = oss.ListWithOptions(client, "docs", oss.ListOptions{Recursive: true})
+	if len(result.Objects) != 2 || result.Objects[1].Path != "/docs/sub/b.txt" || len(result.Prefixes) != 0 {
+		t.Errorf("Recursive list should walk sub directories, but got %+v", result)
+	}
+}
+
+func TestListSharedFolders(t *testing.T) {
+	dirs := map[string][]map[string]interface{}{
+		"/share/年度 报告": {
+			{"path": "/share/年度 报告/第一 季度.pdf", "name": "第一 季度.pdf"},
+			{"path": "/share/年度 报告/sub dir", "name": "sub dir", "isdir": true},
+		},
+		"/share/年度 报告/sub dir": {{"path": "/share/年度 报告/sub dir/a#1.txt", "name": "a#1.txt"}},
+		"/share/team/docs":     {{"path": "/share/team/docs/b.txt", "name": "b.txt"}},
+	}
+	var folders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("method") == "list_share" {
+			shares := []map[string]interface{}{{"path": "/video", "name": "video", "isdir": true}, {"path": "/share", "name": "share", "isdir": true}}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"total": len(shares), "shares": shares}})
+			return
+		}
+		folders = append(folders, r.URL.Query().Get("folder_path"))
+		files := dirs[r.URL.Query().Get("folder_path")]
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": map[string]interface{}{"total": len(files), "files": files}})
+	}))
+	defer server.Close()
+
+	client := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share/"}}
+	result, err := oss.ListWithOptions(client, "/年度 报告/", oss.ListOptions{Recursive: true})
+	if err != nil {
+		t.Fatalf("No error should happen when list, but got %v", err)
+	}
+	if len(result.Objects) != 2 || result.Objects[0].Path != "/年度 报告/sub dir/a#1.txt" || result.Objects[1].Path != "/年度 报告/第一 季度.pdf" {
+		t.Errorf("Paths with spaces and CJK names should be kept as is, but got %+v", result.Objects)
+	}
+	if folders[0] != "/share/年度 报告" {
+		t.Errorf("Folder path should join shared folder without double slash, but got %v", folders)
+	}
+
+	nested := &synology.Client{Config: &synology.Config{Endpoint: server.URL, SharedFolder: "/share/team"}}
+	objects, _ := nested.List("/docs")
+	if len(objects) != 1 || objects[0].Path != "/docs/b.txt" {
+		t.Errorf("Nested shared folder should be stripped, but got %+v", objects)
+	}
+
+	root := &synology.Client{Config: &synology.Config{Endpoint: server.URL}}
+	objects, err = root.List("/")
+	if err != nil || len(objects) != 2 || objects[0].Path != "/share" || objects[1].Path != "/video" {
+		t.Errorf("List of / without shared folder should return shared folders, but got %+v %v", objects, err)
+	}
+	shares, err := root.ListShares()
+	if err != nil || len(shares) != 2 || shares[0] != (synology.Share{Name: "share", Path: "/share"}) {
+		t.Errorf("ListShares should return shared folders sorted by name, but got %+v %v", shares, err)
+	}
+}
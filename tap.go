@@ -0,0 +1,332 @@
+package oss
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultTapBuffer 上传事件队列的默认长度
+	DefaultTapBuffer = 256
+	// DefaultTapMaxContentBytes 事件中携带上传内容的默认大小上限
+	DefaultTapMaxContentBytes = 1 << 20
+)
+
+// TapDropPolicy 上传事件队列已满时的处理策略
+type TapDropPolicy string
+
+const (
+	// TapDropNewest 丢弃新的事件，为默认策略
+	TapDropNewest TapDropPolicy = "drop-newest"
+	// TapDropOldest 丢弃队列中最早的事件
+	TapDropOldest TapDropPolicy = "drop-oldest"
+	// TapBlock 等待队列有空位，写入会被下游阻塞
+	TapBlock TapDropPolicy = "block"
+)
+
+// TapEvent 写入成功后发送给下游的事件
+type TapEvent struct {
+	// Op 操作名：put、copy、move
+	Op string
+	// Path 写入的对象路径
+	Path string
+	// Source 复制和移动的源路径
+	Source string
+	// Object 写入后的对象信息，可能为nil
+	Object *Object
+	// Data 上传内容的副本，只在 TapOptions.IncludeContent 为true且内容大小已知并不超过上限时设置
+	Data []byte
+}
+
+// TapSink 接收上传事件的下游，例如写入消息队列或搜索索引
+type TapSink interface {
+	// Receive 处理一个上传事件，在后台goroutine中按写入顺序调用
+	// 参数:
+	//   - event: 上传事件
+	// 返回:
+	//   - error: 错误信息，交给 TapOptions.OnError 处理
+	Receive(event TapEvent) error
+}
+
+// TapSinkFunc 函数形式的上传事件下游
+type TapSinkFunc func(event TapEvent) error
+
+// Receive 调用函数处理上传事件
+func (fn TapSinkFunc) Receive(event TapEvent) error {
+	return fn(event)
+}
+
+// TapOptions 上传事件的发送选项
+type TapOptions struct {
+	// Buffer 事件队列长度，小于等于0时使用 DefaultTapBuffer
+	Buffer int
+	// Policy 队列已满时的处理策略，为空时使用 TapDropNewest
+	Policy TapDropPolicy
+	// IncludeContent 是否在事件中携带上传内容的副本
+	IncludeContent bool
+	// MaxContentBytes 携带内容的大小上限，超过或大小未知时只发送元信息，小于等于0时使用 DefaultTapMaxContentBytes
+	MaxContentBytes int64
+	// OnError 下游处理失败时的回调，为nil时忽略错误
+	OnError func(event TapEvent, err error)
+}
+
+// TapStorage 将写入异步发送给下游的存储包装器
+// 写入成功后把事件放入有界队列，由后台goroutine发送给下游，下游的失败和延迟不影响写入
+type TapStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+
+	sink    TapSink
+	opts    TapOptions
+	events  chan TapEvent
+	mu      sync.RWMutex
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+// WithTap 创建将写入异步发送给下游的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - sink: 上传事件的下游
+//   - opts: 发送选项
+// 返回:
+//   - *TapStorage: 存储包装器实例，不再使用时调用 Close 发送剩余事件
+func WithTap(storage StorageInterface, sink TapSink, opts TapOptions) *TapStorage {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultTapBuffer
+	}
+	if opts.Policy == "" {
+		opts.Policy = TapDropNewest
+	}
+	if opts.MaxContentBytes <= 0 {
+		opts.MaxContentBytes = DefaultTapMaxContentBytes
+	}
+
+	tap := &TapStorage{
+		StorageInterface: storage,
+		sink:             sink,
+		opts:             opts,
+		events:           make(chan TapEvent, opts.Buffer),
+		done:             make(chan struct{}),
+	}
+	go tap.run()
+	return tap
+}
+
+// Dropped 返回因队列已满或已关闭而丢弃的事件数量
+// 返回:
+//   - int64: 丢弃的事件数量
+func (tap *TapStorage) Dropped() int64 {
+	return tap.dropped.Load()
+}
+
+// Close 停止接收新事件，等待队列中的事件发送完毕
+// 返回:
+//   - error: 错误信息
+func (tap *TapStorage) Close() error {
+	tap.mu.Lock()
+	if !tap.closed {
+		tap.closed = true
+		close(tap.events)
+	}
+	tap.mu.Unlock()
+	<-tap.done
+	return nil
+}
+
+// Put 上传文件并发送上传事件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (tap *TapStorage) Put(path string, reader io.Reader) (*Object, error) {
+	reader, data, err := tap.capture(reader)
+	if err != nil {
+		return nil, err
+	}
+	object, err := tap.StorageInterface.Put(path, reader)
+	if err == nil {
+		tap.emit(TapEvent{Op: "put", Path: objectPath(object, path), Object: object, Data: data})
+	}
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件并发送上传事件
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (tap *TapStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	reader, data, err := tap.capture(reader)
+	if err != nil {
+		return nil, err
+	}
+	object, err := tap.StorageInterface.PutWithOptions(path, reader, opts)
+	if err == nil {
+		tap.emit(TapEvent{Op: "put", Path: objectPath(object, path), Object: object, Data: data})
+	}
+	return object, err
+}
+
+// NewWriter 创建流式写入器，关闭成功后发送只包含元信息的上传事件
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (tap *TapStorage) NewWriter(path string) (io.WriteCloser, error) {
+	writer, err := tap.StorageInterface.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	return &tapWriter{WriteCloser: writer, tap: tap, path: path}, nil
+}
+
+// Copy 复制文件并发送复制事件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (tap *TapStorage) Copy(srcPath, dstPath string) error {
+	err := tap.StorageInterface.Copy(srcPath, dstPath)
+	if err == nil {
+		tap.emit(TapEvent{Op: "copy", Path: dstPath, Source: srcPath})
+	}
+	return err
+}
+
+// Move 移动文件并发送移动事件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (tap *TapStorage) Move(srcPath, dstPath string) error {
+	err := tap.StorageInterface.Move(srcPath, dstPath)
+	if err == nil {
+		tap.emit(TapEvent{Op: "move", Path: dstPath, Source: srcPath})
+	}
+	return err
+}
+
+// capture 在需要携带内容且大小不超过上限时读入内容副本
+func (tap *TapStorage) capture(reader io.Reader) (io.Reader, []byte, error) {
+	if !tap.opts.IncludeContent {
+		return reader, nil, nil
+	}
+	size := ReaderSize(reader)
+	if size < 0 || size > tap.opts.MaxContentBytes {
+		return reader, nil, nil
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewReader(data), data, nil
+}
+
+// emit 按丢弃策略将事件放入队列
+func (tap *TapStorage) emit(event TapEvent) {
+	tap.mu.RLock()
+	defer tap.mu.RUnlock()
+	if tap.closed {
+		tap.dropped.Add(1)
+		return
+	}
+
+	switch tap.opts.Policy {
+	case TapBlock:
+		tap.events <- event
+		return
+	case TapDropOldest:
+		for {
+			select {
+			case tap.events <- event:
+				return
+			default:
+			}
+			select {
+			case <-tap.events:
+				tap.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case tap.events <- event:
+		default:
+			tap.dropped.Add(1)
+		}
+	}
+}
+
+// run 在后台按顺序将事件发送给下游
+func (tap *TapStorage) run() {
+	defer close(tap.done)
+	for event := range tap.events {
+		if err := tap.sink.Receive(event); err != nil && tap.opts.OnError != nil {
+			tap.opts.OnError(event, err)
+		}
+	}
+}
+
+// objectPath 返回写入后对象的实际路径，例如自动重命名后的路径
+func objectPath(object *Object, path string) string {
+	if object != nil && object.Path != "" {
+		return object.Path
+	}
+	return path
+}
+
+// tapWriter 关闭成功后发送上传事件的写入器
+type tapWriter struct {
+	io.WriteCloser
+	tap  *TapStorage
+	path string
+}
+
+// Close 关闭写入器，成功时发送上传事件
+func (writer *tapWriter) Close() error {
+	if err := writer.WriteCloser.Close(); err != nil {
+		return err
+	}
+	writer.tap.emit(TapEvent{Op: "put", Path: writer.path})
+	return nil
+}
+
+// StorageSink 将上传事件镜像到另一个存储的下游
+// 事件携带内容时直接上传副本，否则从 Source 读取对象后上传；移动事件同样只复制，不删除目标存储中的源路径
+type StorageSink struct {
+	// Target 镜像目标存储
+	Target StorageInterface
+	// Source 被镜像的存储，用于读取没有携带内容的对象，为nil时这类事件返回 ErrNotSupported
+	Source StorageInterface
+}
+
+// Receive 将事件对应的对象写入目标存储
+func (sink *StorageSink) Receive(event TapEvent) error {
+	if event.Data != nil {
+		_, err := sink.Target.Put(event.Path, bytes.NewReader(event.Data))
+		return err
+	}
+	if sink.Source == nil {
+		return fmt.Errorf("%w: %s event for %s carries no content and StorageSink has no Source", ErrNotSupported, event.Op, event.Path)
+	}
+	stream, err := sink.Source.GetStream(event.Path)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	_, err = sink.Target.Put(event.Path, stream)
+	return err
+}
@@ -0,0 +1,61 @@
+package oss_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestTap(t *testing.T) {
+	source, mirror := filesystem.New(t.TempDir()), filesystem.New(t.TempDir())
+	var (
+		mutex  sync.Mutex
+		events []oss.TapEvent
+	)
+	sinks := oss.TapSinkFunc(func(event oss.TapEvent) error {
+		mutex.Lock()
+		events = append(events, event)
+		mutex.Unlock()
+		return (&oss.StorageSink{Target: mirror, Source: source}).Receive(event)
+	})
+	tap := oss.WithTap(source, sinks, oss.TapOptions{IncludeContent: true, MaxContentBytes: 10})
+
+	tap.Put("/small.txt", strings.NewReader("sample"))
+	tap.Put("/large.txt", strings.NewReader(strings.Repeat("a", 100)))
+	writer, _ := tap.NewWriter("/stream.txt")
+	writer.Write([]byte("stream"))
+	writer.Close()
+	tap.Copy("/small.txt", "/copy.txt")
+	tap.Close()
+
+	if len(events) != 4 {
+		t.Fatalf("Every successful write should produce an event, but got %v", events)
+	}
+	if string(events[0].Data) != "sample" || events[1].Data != nil {
+		t.Errorf("Only content within MaxContentBytes should be attached, but got %q and %d bytes", events[0].Data, len(events[1].Data))
+	}
+	for _, path := range []string{"/small.txt", "/large.txt", "/stream.txt", "/copy.txt"} {
+		if exists, _ := mirror.Exists(path); !exists {
+			t.Errorf("%v should be mirrored to the sink storage", path)
+		}
+	}
+
+	if _, err := tap.Put("/after-close.txt", strings.NewReader("sample")); err != nil || tap.Dropped() != 1 {
+		t.Errorf("Writes after Close should succeed and drop the event, but got %v, dropped %v", err, tap.Dropped())
+	}
+
+	block := make(chan struct{})
+	slow := oss.WithTap(source, oss.TapSinkFunc(func(oss.TapEvent) error { <-block; return nil }), oss.TapOptions{Buffer: 1})
+	for i := 0; i < 5; i++ {
+		slow.Put(fmt.Sprintf("/slow-%d.txt", i), strings.NewReader("sample"))
+	}
+	if slow.Dropped() < 3 {
+		t.Errorf("Events should be dropped when the queue is full, but dropped %v", slow.Dropped())
+	}
+	close(block)
+	slow.Close()
+}
@@ -0,0 +1,98 @@
+package oss
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TempDir Get 创建临时文件的目录，为空时使用 os.TempDir()
+// 需要在程序启动时设置，例如在只读根文件系统的容器中指向可写的卷
+var TempDir string
+
+// CreateTempFile 在 TempDir 下创建临时文件
+// 返回的 *os.File 不再被引用时由垃圾回收关闭并删除文件，也可以调用 RemoveTempFile 立即删除；
+// 需要在关闭后继续使用文件路径的调用方应自行复制或移动文件
+// 参数:
+//   - pattern: 文件名模式，与 os.CreateTemp 相同
+// 返回:
+//   - *os.File: 临时文件
+//   - error: 错误信息
+func CreateTempFile(pattern string) (*os.File, error) {
+	file, err := createTemp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	runtime.SetFinalizer(file, func(file *os.File) {
+		file.Close()
+		os.Remove(file.Name())
+	})
+	return file, nil
+}
+
+// createTemp 与 os.CreateTemp 相同，文件名中的随机部分取自 DefaultRandom
+func createTemp(pattern string) (*os.File, error) {
+	dir := TempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+	if strings.ContainsRune(prefix+suffix, os.PathSeparator) {
+		return nil, &os.PathError{Op: "createtemp", Path: pattern, Err: errors.New("pattern contains path separator")}
+	}
+
+	for try := 0; ; try++ {
+		random, err := RandomHex(5)
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.OpenFile(filepath.Join(dir, prefix+random+suffix), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// RemoveTempFile 关闭并删除 CreateTempFile 创建的临时文件
+// 参数:
+//   - file: 临时文件
+// 返回:
+//   - error: 删除文件时的错误信息
+func RemoveTempFile(file *os.File) error {
+	runtime.SetFinalizer(file, nil)
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// StreamToTempFile 将文件流复制到临时文件，并将读取位置重置到开头
+// 复制失败时删除临时文件，stream总是会被关闭
+// 参数:
+//   - stream: 文件流
+//   - pattern: 文件名模式，与 os.CreateTemp 相同
+// 返回:
+//   - *os.File: 临时文件
+//   - error: 错误信息
+func StreamToTempFile(stream io.ReadCloser, pattern string) (*os.File, error) {
+	defer stream.Close()
+
+	file, err := CreateTempFile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, stream); err != nil {
+		RemoveTempFile(file)
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		RemoveTempFile(file)
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,50 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+// failingReader 读取到一半时失败的读取器
+type failingReader struct{ read bool }
+
+func (reader *failingReader) Read(p []byte) (int, error) {
+	if reader.read {
+		return 0, errors.New("connection reset")
+	}
+	reader.read = true
+	return copy(p, "partial"), nil
+}
+
+func TestTempDir(t *testing.T) {
+	dir := t.TempDir()
+	oss.TempDir = dir
+	defer func() { oss.TempDir = "" }()
+
+	file, err := oss.StreamToTempFile(io.NopCloser(strings.NewReader("sample")), "test-*.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when copy stream to temp file, but got %v", err)
+	}
+	if filepath.Dir(file.Name()) != dir {
+		t.Errorf("Temp file should be created in oss.TempDir, but got %v", file.Name())
+	}
+	if data, _ := io.ReadAll(file); string(data) != "sample" {
+		t.Errorf("Temp file should be readable from the start, but got %v", string(data))
+	}
+	if err := oss.RemoveTempFile(file); err != nil {
+		t.Errorf("No error should happen when remove temp file, but got %v", err)
+	}
+
+	if _, err := oss.StreamToTempFile(io.NopCloser(&failingReader{}), "test-*.txt"); err == nil {
+		t.Errorf("Failed download should return error")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Temp files should be removed after failed download or RemoveTempFile, but got %v", entries)
+	}
+}
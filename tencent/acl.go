@@ -0,0 +1,54 @@
+package tencent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/smart-unicom/oss"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// groupAllUsers 公共读授权的匿名用户组
+const groupAllUsers = "http://cam.qcloud.com/groups/global/AllUsers"
+
+// SetACL 设置对象的访问控制
+// 参数:
+//   - path: 对象路径
+//   - acl: 访问控制类型，腾讯云COS的对象只支持 oss.ACLPrivate 和 oss.ACLPublicRead
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) SetACL(path string, acl oss.ACL) error {
+	if acl != oss.ACLPrivate && acl != oss.ACLPublicRead {
+		return fmt.Errorf("%w: tencent does not support %s object ACL", oss.ErrNotSupported, acl)
+	}
+
+	_, err := client.COS.Object.PutACL(context.Background(), client.ToRelativePath(path), &cos.ObjectPutACLOptions{
+		Header: &cos.ACLHeaderOptions{XCosACL: string(acl)},
+	})
+	return wrapError(err)
+}
+
+// GetACL 根据对象的授权列表获取访问控制
+// 参数:
+//   - path: 对象路径
+//
+// 返回:
+//   - oss.ACL: 访问控制类型
+//   - error: 错误信息
+func (client Client) GetACL(path string) (oss.ACL, error) {
+	result, _, err := client.COS.Object.GetACL(context.Background(), client.ToRelativePath(path))
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	var publicRead, publicWrite bool
+	for _, grant := range result.AccessControlList {
+		if grant.Grantee == nil || (grant.Grantee.URI != groupAllUsers && grant.Grantee.ID != "qcs::cam::anyone:anyone") {
+			continue
+		}
+		publicRead = publicRead || grant.Permission == "READ" || grant.Permission == "FULL_CONTROL"
+		publicWrite = publicWrite || grant.Permission == "WRITE" || grant.Permission == "FULL_CONTROL"
+	}
+	return oss.ACLFromGrants(publicRead, publicWrite, false), nil
+}
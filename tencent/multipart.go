@@ -0,0 +1,117 @@
+package tencent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/smart-unicom/oss"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// InitiateMultipart 初始化分片上传
+// 参数:
+//   - path: 目标对象路径
+//   - opts: 上传选项，为nil时使用默认值
+//
+// 返回:
+//   - *oss.MultipartUpload: 分片上传会话
+//   - error: 错误信息
+func (client Client) InitiateMultipart(path string, opts *oss.PutOptions) (*oss.MultipartUpload, error) {
+	// 规范化来自Windows的路径
+	path, err := oss.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(path)
+	opts = &normalized
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(path), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
+
+	// 构建初始化选项
+	initOptions := &cos.InitiateMultipartUploadOptions{
+		ACLHeaderOptions: &cos.ACLHeaderOptions{XCosACL: string(opts.ACL)},
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType:        opts.ContentType,
+			ContentDisposition: opts.ContentDisposition,
+			CacheControl:       opts.CacheControl,
+		},
+	}
+	if len(opts.Metadata) > 0 {
+		meta := http.Header{}
+		for key, value := range opts.Metadata {
+			meta.Set("x-cos-meta-"+key, value)
+		}
+		initOptions.ObjectPutHeaderOptions.XCosMetaXXX = &meta
+	}
+
+	result, _, err := client.COS.Object.InitiateMultipartUpload(context.Background(), client.ToRelativePath(path), initOptions)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.MultipartUpload{Path: path, UploadID: result.UploadID, Options: *opts}, nil
+}
+
+// UploadPart 上传一个分片
+// 参数:
+//   - upload: 分片上传会话
+//   - number: 分片编号，从1开始
+//   - reader: 分片内容
+//   - size: 分片大小（字节）
+//
+// 返回:
+//   - *oss.Part: 已上传的分片
+//   - error: 错误信息
+func (client Client) UploadPart(upload *oss.MultipartUpload, number int, reader io.Reader, size int64) (*oss.Part, error) {
+	resp, err := client.COS.Object.UploadPart(context.Background(), client.ToRelativePath(upload.Path), upload.UploadID, number, reader, &cos.ObjectUploadPartOptions{
+		ContentLength: size,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &oss.Part{Number: number, ETag: resp.Header.Get("ETag"), Size: size}, nil
+}
+
+// CompleteMultipart 按分片编号合并分片，完成上传
+// 参数:
+//   - upload: 分片上传会话
+//   - parts: 已上传的分片
+//
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) CompleteMultipart(upload *oss.MultipartUpload, parts []*oss.Part) (*oss.Object, error) {
+	oss.SortParts(parts)
+	completeOptions := &cos.CompleteMultipartUploadOptions{}
+	for _, part := range parts {
+		completeOptions.Parts = append(completeOptions.Parts, cos.Object{PartNumber: part.Number, ETag: part.ETag})
+	}
+
+	if _, _, err := client.COS.Object.CompleteMultipartUpload(context.Background(), client.ToRelativePath(upload.Path), upload.UploadID, completeOptions); err != nil {
+		return nil, wrapError(err)
+	}
+
+	if object, err := client.Stat(upload.Path); err == nil {
+		return object, nil
+	}
+	return &oss.Object{Path: upload.Path, Name: filepath.Base(upload.Path), StorageInterface: client}, nil
+}
+
+// AbortMultipart 中止分片上传并释放已上传的分片
+// 参数:
+//   - upload: 分片上传会话
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) AbortMultipart(upload *oss.MultipartUpload) error {
+	_, err := client.COS.Object.AbortMultipartUpload(context.Background(), client.ToRelativePath(upload.Path), upload.UploadID)
+	return wrapError(err)
+}
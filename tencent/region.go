@@ -0,0 +1,18 @@
+package tencent
+
+import (
+	"context"
+	"strings"
+)
+
+// BucketRegion 查询存储桶所在的区域
+// 返回:
+//   - string: 区域名，例如 ap-guangzhou、eu-frankfurt
+//   - error: 错误信息
+func (client Client) BucketRegion() (string, error) {
+	result, _, err := client.COS.Bucket.GetLocation(context.Background())
+	if err != nil {
+		return "", wrapError(err)
+	}
+	return strings.ToLower(result.Location), nil
+}
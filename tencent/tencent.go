@@ -4,9 +4,9 @@ package tencent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -41,6 +41,12 @@ type Config struct {
 	CORS string
 	// Endpoint 服务端点
 	Endpoint string
+	// HTTPClient 发送请求使用的HTTP客户端，可以设置代理、TLS和连接池，为nil时使用 http.DefaultTransport
+	// 客户端的Transport会被包装为签名传输，不会修改传入的客户端
+	HTTPClient *http.Client
+	// Retry 请求失败时的重试配置，为nil时使用SDK默认重试策略
+	// 设置后在签名传输内层按配置重试，只重试幂等且内容可以重新读取的请求，并关闭SDK自带的重试
+	Retry *oss.RetryConfig
 }
 
 // Client 腾讯云COS存储客户端
@@ -58,23 +64,54 @@ type Client struct {
 //
 // 返回:
 //   - *Client: 腾讯云COS存储客户端实例
-func New(config *Config) *Client {
+//   - error: 存储桶地址无效时返回错误
+func New(config *Config) (*Client, error) {
 	// 构建存储桶URL
 	bucketURL := fmt.Sprintf("https://%s-%s.cos.%s.myqcloud.com", config.Bucket, config.AppID, config.Region)
-	u, _ := url.Parse(bucketURL)
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, err
+	}
 
-	// 创建COS客户端
-	cosClient := cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{
-		Transport: &cos.AuthorizationTransport{
-			SecretID:  config.SecretID,
-			SecretKey: config.SecretKey,
-		},
-	})
+	// 创建COS客户端，在自定义HTTP客户端的Transport外层依次包装重试和签名
+	httpClient := &http.Client{}
+	if config.HTTPClient != nil {
+		*httpClient = *config.HTTPClient
+	}
+	transport := httpClient.Transport
+	if config.Retry != nil {
+		transport = config.Retry.Transport(transport)
+	}
+	httpClient.Transport = &cos.AuthorizationTransport{
+		SecretID:  config.SecretID,
+		SecretKey: config.SecretKey,
+		Transport: transport,
+	}
+	cosClient := cos.NewClient(&cos.BaseURL{BucketURL: u}, httpClient)
+	if config.Retry != nil {
+		cosClient.Conf.RetryOpt.Count = 1
+	}
 
 	return &Client{
 		Config: config,
 		COS:    cosClient,
+	}, nil
+}
+
+// MustNew 初始化腾讯云COS存储客户端，失败时panic
+// 参数:
+//   - config: 腾讯云COS配置信息
+//
+// 返回:
+//   - *Client: 腾讯云COS存储客户端实例
+//
+// Deprecated: 使用返回错误的 New，由调用方处理配置错误
+func MustNew(config *Config) *Client {
+	client, err := New(config)
+	if err != nil {
+		panic(err)
 	}
+	return client
 }
 
 // getUrl 获取腾讯云COS的访问URL
@@ -101,19 +138,13 @@ func (client Client) Get(path string) (file *os.File, err error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// 创建临时文件并复制内容
-	if file, err = ioutil.TempFile("/tmp", "tencent"); err == nil {
-		defer readCloser.Close()
-		// 将流内容复制到临时文件
-		_, err = io.Copy(file, readCloser)
-		// 重置文件指针到开始位置
-		file.Seek(0, 0)
-	}
-
-	return file, err
+	// 复制到临时文件，下载失败时删除临时文件
+	return oss.StreamToTempFile(readCloser, "tencent")
 }
 
+// maxKeyBytes 腾讯云COS对象键的最大字节数
+const maxKeyBytes = 850
+
 // urlRegexp URL正则表达式，用于匹配HTTP/HTTPS URL
 var urlRegexp = regexp.MustCompile(`(https?:)?//((\\w+).)+(\w+)/`)
 
@@ -146,12 +177,78 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 	// 使用COS客户端获取对象
 	resp, err := client.COS.Object.Get(context.Background(), client.ToRelativePath(path), nil)
 	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return resp.Body, nil
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 使用HTTP Range请求只下载范围内的内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+//
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (client Client) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := oss.ValidateRange(offset, length); err != nil {
 		return nil, err
 	}
 
+	resp, err := client.COS.Object.Get(context.Background(), client.ToRelativePath(path), &cos.ObjectGetOptions{
+		Range: oss.HTTPRange(offset, length),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
 	return resp.Body, nil
 }
 
+// Stat 获取指定路径文件的元数据
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (client Client) Stat(path string) (*oss.Object, error) {
+	// 使用HEAD请求获取对象元数据
+	resp, err := client.COS.Object.Head(context.Background(), client.ToRelativePath(path), nil)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	object := &oss.Object{
+		Path:             path,
+		Name:             filepath.Base(path),
+		Size:             resp.ContentLength,
+		ContentType:      resp.Header.Get("Content-Type"),
+		ETag:             resp.Header.Get("ETag"),
+		Metadata:         oss.MetadataFromHeader(resp.Header, "X-Cos-Meta-"),
+		StorageInterface: client,
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = oss.NormalizeTime(lastModified)
+	}
+
+	return object, nil
+}
+
+// Exists 判断指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+//
+// 返回:
+//   - bool: 文件是否存在
+//   - error: 错误信息，文件不存在时不返回错误
+func (client Client) Exists(path string) (bool, error) {
+	exists, err := client.COS.Object.IsExist(context.Background(), client.ToRelativePath(path))
+	return exists, wrapError(err)
+}
+
 // Put 上传文件到指定路径
 // 参数:
 //   - path: 目标路径
@@ -161,24 +258,119 @@ func (client Client) GetStream(path string) (io.ReadCloser, error) {
 //   - *oss.Object: 上传后的对象信息
 //   - error: 错误信息
 func (client Client) Put(path string, body io.Reader) (*oss.Object, error) {
+	return client.PutWithOptions(path, body, nil)
+}
+
+// PutWithOptions 使用指定选项上传文件到指定路径
+// 参数:
+//   - path: 目标路径
+//   - body: 文件内容读取器
+//   - opts: 上传选项，为nil时使用默认选项
+//
+// 返回:
+//   - *oss.Object: 上传后的对象信息
+//   - error: 错误信息
+func (client Client) PutWithOptions(path string, body io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	// 规范化来自Windows的路径
+	path, err := oss.NormalizePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &oss.PutOptions{}
+	}
+	// 将字符集合并到内容类型中
+	normalized := opts.Normalize(path)
+	opts = &normalized
+
 	// 如果是可寻址的读取器，重置到开始位置
 	if seeker, ok := body.(io.ReadSeeker); ok {
 		seeker.Seek(0, 0)
 	}
 
-	// 使用COS客户端上传对象
-	_, err := client.COS.Object.Put(context.Background(), client.ToRelativePath(path), body, nil)
+	// 按冲突策略确定上传路径
+	resolved, err := opts.ResolvePath(client, path)
 	if err != nil {
 		return nil, err
 	}
+	path = resolved
+
+	// 在发送请求前校验对象键长度
+	if err := oss.ValidateKeyLength(client.ToRelativePath(path), maxKeyBytes, 0); err != nil {
+		return nil, err
+	}
 
-	now := time.Now()
-	return &oss.Object{
+	// 构建上传选项
+	putOptions := &cos.ObjectPutOptions{
+		ACLHeaderOptions: &cos.ACLHeaderOptions{XCosACL: string(opts.ACL)},
+		ObjectPutHeaderOptions: &cos.ObjectPutHeaderOptions{
+			ContentType:        opts.ContentType,
+			ContentDisposition: opts.ContentDisposition,
+			CacheControl:       opts.CacheControl,
+		},
+	}
+	if len(opts.Metadata) > 0 {
+		meta := http.Header{}
+		for key, value := range opts.Metadata {
+			meta.Set("x-cos-meta-"+key, value)
+		}
+		putOptions.ObjectPutHeaderOptions.XCosMetaXXX = &meta
+	}
+	if len(opts.Tags) > 0 {
+		putOptions.ObjectPutHeaderOptions.XOptionHeader = &http.Header{"X-Cos-Tagging": {oss.EncodeTags(opts.Tags)}}
+	}
+	// 发送Content-MD5由服务端校验内容
+	if opts.Checksum != "" {
+		if opts.Checksum != oss.ChecksumMD5 {
+			return nil, fmt.Errorf("%w: tencent does not support %s checksum", oss.ErrNotSupported, opts.Checksum)
+		}
+		buffered, checksum, err := opts.ReadChecksum(body)
+		if err != nil {
+			return nil, err
+		}
+		body = buffered
+		putOptions.ObjectPutHeaderOptions.ContentMD5 = checksum
+	}
+
+	// 回调上传进度，包装后SDK无法识别内容长度，需要显式设置
+	if opts.Progress != nil {
+		size := oss.ReaderSize(body)
+		if size >= 0 {
+			putOptions.ObjectPutHeaderOptions.ContentLength = size
+		}
+		body = opts.WrapReader(body, size)
+	}
+
+	// 使用COS客户端上传对象
+	_, err = client.COS.Object.Put(context.Background(), client.ToRelativePath(path), body, putOptions)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	object := &oss.Object{
 		Path:             path,
 		Name:             filepath.Base(path),
-		LastModified:     &now,
 		StorageInterface: client,
-	}, nil
+	}
+	// 获取服务端记录的大小和最后修改时间
+	if stat, err := client.Stat(path); err == nil {
+		object.LastModified = stat.LastModified
+		object.Size = stat.Size
+	}
+
+	return object, nil
+}
+
+// NewWriter 创建流式写入指定路径的写入器
+// 数据通过管道交给 Put 上传
+// 参数:
+//   - path: 目标路径
+//
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+//   - error: 错误信息
+func (client Client) NewWriter(path string) (io.WriteCloser, error) {
+	return oss.NewWriterByPut(client, path), nil
 }
 
 // Delete 删除指定路径的文件
@@ -190,7 +382,119 @@ func (client Client) Put(path string, body io.Reader) (*oss.Object, error) {
 func (client Client) Delete(path string) error {
 	// 使用COS客户端删除对象
 	_, err := client.COS.Object.Delete(context.Background(), client.ToRelativePath(path))
-	return err
+	return wrapError(err)
+}
+
+// DeleteObjects 批量删除多个文件
+// 每次请求最多删除 oss.MaxDeleteObjects 个文件，多批请求并发执行
+// 参数:
+//   - paths: 文件路径列表
+//
+// 返回:
+//   - error: 错误信息，部分文件删除失败时返回 *oss.DeleteObjectsError
+func (client Client) DeleteObjects(paths []string) error {
+	return oss.DeleteObjectsInChunks(paths, oss.DefaultDeleteChunkConcurrency, func(chunk []string) map[string]error {
+		failures := map[string]error{}
+		// 构建批量删除请求，记录对象键对应的原始路径
+		opt := &cos.ObjectDeleteMultiOptions{Quiet: true}
+		keys := map[string]string{}
+		for _, path := range chunk {
+			key := client.ToRelativePath(path)
+			keys[key] = path
+			opt.Objects = append(opt.Objects, cos.Object{Key: key})
+		}
+
+		// 静默模式下只返回删除失败的对象
+		result, resp, err := client.COS.Object.DeleteMulti(context.Background(), opt)
+		if err != nil {
+			for _, path := range chunk {
+				failures[path] = wrapError(err)
+			}
+			return failures
+		}
+
+		for _, deleteError := range result.Errors {
+			failures[keys[deleteError.Key]] = wrapError(&cos.ErrorResponse{Response: resp.Response, Code: deleteError.Code, Message: deleteError.Message})
+		}
+		return failures
+	})
+}
+
+// DeleteDir 删除目录下的全部对象
+// 分页列举目录下的对象，每页使用批量删除接口删除
+// 参数:
+//   - dir: 目录路径
+//
+// 返回:
+//   - error: 错误信息，根目录返回 oss.ErrDeleteRoot
+func (client Client) DeleteDir(dir string) error {
+	prefix := oss.DirPrefix(client.ToRelativePath(dir))
+	if prefix == "" {
+		return oss.ErrDeleteRoot
+	}
+
+	opt := &cos.BucketGetOptions{
+		Prefix:  prefix,
+		MaxKeys: oss.MaxDeleteObjects,
+	}
+	for {
+		resp, _, err := client.COS.Bucket.Get(context.Background(), opt)
+		if err != nil {
+			return wrapError(err)
+		}
+
+		var paths []string
+		for _, obj := range resp.Contents {
+			paths = append(paths, obj.Key)
+		}
+		if len(paths) > 0 {
+			if err := client.DeleteObjects(paths); err != nil {
+				return err
+			}
+		}
+
+		if !resp.IsTruncated {
+			return nil
+		}
+		opt.Marker = resp.NextMarker
+	}
+}
+
+// Copy 复制文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Copy(srcPath, dstPath string) error {
+	// 规范化来自Windows的路径
+	dstPath, err := oss.NormalizePath(dstPath)
+	if err != nil {
+		return err
+	}
+	if err := oss.ValidateKeyLength(client.ToRelativePath(dstPath), maxKeyBytes, 0); err != nil {
+		return err
+	}
+
+	// 复制源格式为 <BucketName-APPID>.cos.<Region>.myqcloud.com/<ObjectKey>
+	sourceURL := client.COS.BaseURL.BucketURL.Host + "/" + client.ToRelativePath(srcPath)
+
+	// 使用服务端复制
+	_, _, err = client.COS.Object.Copy(context.Background(), client.ToRelativePath(dstPath), sourceURL, nil)
+	return wrapError(err)
+}
+
+// Move 移动文件到新路径
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+//
+// 返回:
+//   - error: 错误信息
+func (client Client) Move(srcPath, dstPath string) error {
+	// 对象存储不支持重命名，通过服务端复制后删除源文件实现
+	return oss.MoveByCopy(client, srcPath, dstPath)
 }
 
 // List 列出指定路径下的所有对象
@@ -210,23 +514,36 @@ func (client Client) List(path string) ([]*oss.Object, error) {
 
 	resp, _, err := client.COS.Bucket.Get(context.Background(), opt)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	// 遍历对象列表并转换为统一格式
 	for _, obj := range resp.Contents {
-		objects = append(objects, &oss.Object{
-			Path: "/" + obj.Key,
-			Name: filepath.Base(obj.Key),
-			//LastModified:     &obj.LastModified,
+		object := &oss.Object{
+			Path:             "/" + obj.Key,
+			Name:             filepath.Base(obj.Key),
 			Size:             obj.Size,
+			ETag:             obj.ETag,
 			StorageInterface: client,
-		})
+		}
+		// COS返回ISO8601格式的时间
+		if lastModified, err := time.Parse(time.RFC3339, obj.LastModified); err == nil {
+			object.LastModified = oss.NormalizeTime(lastModified)
+		}
+		objects = append(objects, object)
 	}
 
 	return objects, nil
 }
 
+// Underlying 返回腾讯云COS SDK的客户端实例，用于统一接口没有覆盖的功能
+// 返回值的类型随SDK版本变化，不在兼容性承诺之内
+// 返回:
+//   - interface{}: *cos.Client
+func (client Client) Underlying() interface{} {
+	return client.COS
+}
+
 // GetEndpoint 获取存储服务的端点地址
 // 返回:
 //   - string: 端点地址
@@ -250,6 +567,74 @@ func (client Client) GetURL(path string) (string, error) {
 	return client.getUrl(path), nil
 }
 
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项，包括有效期、HTTP方法和响应头覆盖
+//
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息
+func (client Client) GetSignedURL(path string, opts oss.SignedURLOptions) (string, error) {
+	opts, err := opts.Normalize()
+	if err != nil {
+		return "", err
+	}
+
+	// 设置响应头覆盖
+	query := url.Values{}
+	if opts.ResponseContentType != "" {
+		query.Set("response-content-type", opts.ResponseContentType)
+	}
+	if opts.ResponseContentDisposition != "" {
+		query.Set("response-content-disposition", opts.ResponseContentDisposition)
+	}
+	if opts.ResponseCacheControl != "" {
+		query.Set("response-cache-control", opts.ResponseCacheControl)
+	}
+
+	// 生成预签名URL
+	signedURL, err := client.COS.Object.GetPresignedURL(context.Background(), opts.Method, client.ToRelativePath(path),
+		client.Config.SecretID, client.Config.SecretKey, opts.Expiry, &cos.PresignedURLOptions{Query: &query})
+	if err != nil {
+		return "", err
+	}
+
+	return signedURL.String(), nil
+}
+
+// GetUploadURL 生成预签名PUT直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+//
+// 返回:
+//   - *oss.UploadURL: 直传地址及需要携带的请求头或表单字段
+//   - error: 错误信息
+func (client Client) GetUploadURL(path string, opts oss.UploadURLOptions) (*oss.UploadURL, error) {
+	opts = opts.Normalize()
+
+	// 签名中包含Content-Type时客户端必须携带相同的请求头
+	header := http.Header{}
+	for key, value := range opts.Headers() {
+		header.Set(key, value)
+	}
+
+	// 生成预签名URL
+	signedURL, err := client.COS.Object.GetPresignedURL(context.Background(), http.MethodPut, client.ToRelativePath(path),
+		client.Config.SecretID, client.Config.SecretKey, opts.Expiry, &cos.PresignedURLOptions{Header: &header})
+	if err != nil {
+		return nil, err
+	}
+
+	return &oss.UploadURL{
+		URL:       signedURL.String(),
+		Method:    http.MethodPut,
+		Headers:   opts.Headers(),
+		ExpiresAt: oss.Now().Add(opts.Expiry),
+	}, nil
+}
+
 // authorization 生成腾讯云COS的授权签名
 // 参数:
 //   - req: HTTP请求对象
@@ -267,3 +652,53 @@ func (client Client) authorization(req *http.Request) string {
 
 	return authStr
 }
+
+func init() {
+	oss.RegisterHTTPStatusMapper(httpStatus)
+}
+
+// httpStatus 将腾讯云COS的错误转换为HTTP状态码
+// 参数:
+//   - err: 错误信息
+// 返回:
+//   - int: HTTP状态码
+//   - bool: 是否识别该错误
+func httpStatus(err error) (int, bool) {
+	var errorResponse *cos.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		return errorResponse.Response.StatusCode, true
+	}
+	return 0, false
+}
+
+// wrapError 将腾讯云COS的错误包装为统一错误
+// 参数:
+//   - err: 原始错误
+//
+// 返回:
+//   - error: 包装后的错误
+func wrapError(err error) error {
+	var errorResponse *cos.ErrorResponse
+	if !errors.As(err, &errorResponse) {
+		return err
+	}
+
+	statusCode := 0
+	if errorResponse.Response != nil {
+		statusCode = errorResponse.Response.StatusCode
+	}
+
+	switch {
+	case errorResponse.Code == "NoSuchKey":
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	case errorResponse.Code == "NoSuchBucket":
+		return oss.WrapError(oss.ErrBucketNotFound, err)
+	case errorResponse.Code == "AccessDenied" || statusCode == http.StatusForbidden:
+		return oss.WrapError(oss.ErrAccessDenied, err)
+	case errorResponse.Code == "InvalidDigest" || errorResponse.Code == "BadDigest":
+		return oss.WrapError(oss.ErrChecksumMismatch, err)
+	case statusCode == http.StatusNotFound:
+		return oss.WrapError(oss.ErrObjectNotFound, err)
+	}
+	return err
+}
@@ -16,7 +16,8 @@ func TestClient_Get(t *testing.T) {
 var client *Client
 
 func init() {
-	client = New(&Config{
+	var err error
+	client, err = New(&Config{
 		AppID:     "1252882253",
 		SecretID:  "AKIdToxukQWBG8nGXcBN8i662nOo12sc5Wjl",
 		SecretKey: "40jNrBf5mLiuuiU8HH7lDTXP5at00sbA",
@@ -25,6 +26,9 @@ func init() {
 		ACL:       "public-read", // private，public-read-write，public-read；默认值：private
 		//Endpoint:  config.Public.Endpoint,
 	})
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestClient_Put(t *testing.T) {
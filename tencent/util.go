@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/smart-unicom/oss"
 )
 
 func sha(s string) string {
@@ -29,7 +31,7 @@ func hmacSha(k, s string) string {
 }
 
 func getSignTime() string {
-	now := time.Now()
+	now := oss.Now()
 	expired := now.Add(time.Second * 1800)
 	return fmt.Sprintf("%d;%d", now.Unix(), expired.Unix())
 }
@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"io"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+// ReadStream 读取并关闭流，可以直接传入 GetStream 和 GetStreamRange 的返回值
+// 参数:
+//   - stream: 文件流
+//   - err: 获取流时的错误信息
+// 返回:
+//   - []byte: 流的全部内容
+//   - error: 获取或读取失败时的错误信息
+func ReadStream(stream io.ReadCloser, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// ReadObject 读取对象的全部内容，失败时结束测试
+// 参数:
+//   - t: 测试对象
+//   - storage: 存储接口
+//   - path: 对象路径
+// 返回:
+//   - []byte: 对象内容
+func ReadObject(t *testing.T, storage oss.StorageInterface, path string) []byte {
+	t.Helper()
+	data, err := ReadStream(storage.GetStream(path))
+	if err != nil {
+		t.Fatalf("No error should happen when read %v, but got %v", path, err)
+	}
+	return data
+}
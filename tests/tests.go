@@ -1,11 +1,14 @@
 package tests
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -13,8 +16,37 @@ import (
 	"github.com/smart-unicom/oss"
 )
 
+// checkSize 检查对象的大小是否由存储后端填充
+func checkSize(t *testing.T, action string, object *oss.Object, size int64) {
+	if object.Size != size {
+		t.Errorf("%v should return size %v for %v, but got %v", action, size, object.Path, object.Size)
+	}
+}
+
+// checkLastModified 检查对象的最后修改时间是否符合约定
+// LastModified 必须由服务端返回、使用UTC时区并且为毫秒精度
+func checkLastModified(t *testing.T, action string, object *oss.Object) {
+	if object.LastModified == nil {
+		t.Errorf("%v should return last modified time for %v", action, object.Path)
+		return
+	}
+
+	lastModified := *object.LastModified
+	if lastModified.Location() != time.UTC {
+		t.Errorf("%v should return last modified time in UTC, but got %v", action, lastModified.Location())
+	}
+
+	if !lastModified.Equal(lastModified.Truncate(time.Millisecond)) {
+		t.Errorf("%v should return last modified time in millisecond precision, but got %v", action, lastModified)
+	}
+
+	if diff := time.Since(lastModified); diff > time.Hour || diff < -time.Hour {
+		t.Errorf("%v should return last modified time close to now, but got %v", action, lastModified)
+	}
+}
+
 func TestAll(storage oss.StorageInterface, t *testing.T) {
-	randomPath := strings.Replace(time.Now().Format("20060102150506.000"), ".", "", -1)
+	randomPath := strings.Replace(oss.Now().Format("20060102150506.000"), ".", "", -1)
 	fmt.Printf("testing file in %v\n", filepath.Join(storage.GetEndpoint(), randomPath))
 
 	fileName := "/" + filepath.Join(randomPath, "sample.txt")
@@ -28,6 +60,9 @@ func TestAll(storage oss.StorageInterface, t *testing.T) {
 			t.Errorf("No error should happen when save sample file, but got %v", err)
 		} else if object.Path == "" || object.StorageInterface == nil {
 			t.Errorf("returned object should necessary information")
+		} else {
+			checkLastModified(t, "Put", object)
+			checkSize(t, "Put", object, int64(len("sample")))
 		}
 	} else {
 		t.Errorf("No error should happen when opem sample file, but got %v", err)
@@ -43,17 +78,53 @@ func TestAll(storage oss.StorageInterface, t *testing.T) {
 		t.Errorf("No error should happen when opem sample file, but got %v", err)
 	}
 
+	// Put file with too long key
+	if _, err := storage.Put("/"+filepath.Join(randomPath, strings.Repeat("a", 5000)), strings.NewReader("sample")); !errors.Is(err, oss.ErrKeyTooLong) {
+		t.Errorf("Should return ErrKeyTooLong when save file with too long key, but got %v", err)
+	}
+
+	// Put all or rollback
+	bundleFile := "/" + filepath.Join(randomPath, "bundle", "a.txt")
+	if objects, err := oss.PutAllOrRollback(storage, map[string]io.Reader{
+		bundleFile: strings.NewReader("sample"),
+		"/" + filepath.Join(randomPath, "bundle", "b"+strings.Repeat("a", 5000)): strings.NewReader("sample"),
+	}); err == nil || objects != nil {
+		t.Errorf("Should return error when put bundle with invalid object")
+	} else if exists, err := storage.Exists(bundleFile); err != nil || exists {
+		t.Errorf("Uploaded bundle file should be rolled back, but got %v, %v", exists, err)
+	}
+
 	// Get file
 	if file, err := storage.Get(fileName); err != nil {
 		t.Errorf("No error should happen when get sample file, but got %v", err)
 	} else {
 		if buffer, err := ioutil.ReadAll(file); err != nil {
 			t.Errorf("No error should happen when read downloaded file, but got %v", err)
-		} else if string(buffer) == "sample" {
+		} else if string(buffer) != "sample" {
 			t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
 		}
 	}
 
+	// Stat
+	if object, err := storage.Stat(fileName); err != nil {
+		t.Errorf("No error should happen when stat sample file, but got %v", err)
+	} else {
+		if info, err := os.Stat(sampleFile); err == nil && object.Size != info.Size() {
+			t.Errorf("Stat should return correct size, expect %v, but got %v", info.Size(), object.Size)
+		}
+
+		checkLastModified(t, "Stat", object)
+	}
+
+	// Exists
+	if exists, err := storage.Exists(fileName); err != nil || !exists {
+		t.Errorf("Sample file should exist, but got %v, %v", exists, err)
+	}
+
+	if exists, err := storage.Exists(fileName + ".missing"); err != nil || exists {
+		t.Errorf("Missing file should not exist, but got %v, %v", exists, err)
+	}
+
 	// GetURL
 	if url, err := storage.GetURL(fileName); err != nil {
 		t.Errorf("No error should happen when GetURL for sample file, but got %v", err)
@@ -65,33 +136,120 @@ func TestAll(storage oss.StorageInterface, t *testing.T) {
 		} else {
 			if buffer, err := ioutil.ReadAll(resp.Body); err != nil {
 				t.Errorf("No error should happen when read downloaded file, but got %v", err)
-			} else if string(buffer) == "sample" {
+			} else if string(buffer) != "sample" {
 				t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
 			}
 		}
 	}
 
+	// GetSignedURL
+	if url, err := storage.GetSignedURL(fileName, oss.SignedURLOptions{Expiry: 10 * time.Minute, ResponseContentType: "text/plain"}); err != nil {
+		if !errors.Is(err, oss.ErrNotSupported) {
+			t.Errorf("No error should happen when GetSignedURL for sample file, but got %v", err)
+		}
+	} else if resp, err := http.Get(url); err != nil {
+		t.Errorf("No error should happen when get file with signed URL, but got %v", err)
+	} else {
+		if buffer, err := ioutil.ReadAll(resp.Body); err != nil || string(buffer) != "sample" {
+			t.Errorf("Downloaded file with signed URL should contain correct content, but got %v, %v", string(buffer), err)
+		}
+		resp.Body.Close()
+	}
+
+	if _, err := storage.GetSignedURL(fileName, oss.SignedURLOptions{Method: http.MethodDelete}); !errors.Is(err, oss.ErrNotSupported) {
+		t.Errorf("Should return ErrNotSupported when GetSignedURL with unsupported method, but got %v", err)
+	}
+
+	// GetUploadURL
+	uploadFile := "/" + filepath.Join(randomPath, "upload", "sample.txt")
+	if upload, err := storage.GetUploadURL(uploadFile, oss.UploadURLOptions{Expiry: 10 * time.Minute, ContentType: "text/plain"}); err != nil {
+		if !errors.Is(err, oss.ErrNotSupported) {
+			t.Errorf("No error should happen when GetUploadURL for sample file, but got %v", err)
+		}
+	} else if upload.Method == http.MethodPut {
+		request, _ := http.NewRequest(http.MethodPut, upload.URL, strings.NewReader("sample"))
+		for key, value := range upload.Headers {
+			request.Header.Set(key, value)
+		}
+
+		if resp, err := http.DefaultClient.Do(request); err != nil || resp.StatusCode >= 300 {
+			t.Errorf("No error should happen when upload file with upload URL, but got %v, %v", resp, err)
+		} else {
+			resp.Body.Close()
+			if exists, err := storage.Exists(uploadFile); err != nil || !exists {
+				t.Errorf("Uploaded file with upload URL should exist, but got %v, %v", exists, err)
+			}
+			storage.Delete(uploadFile)
+		}
+	}
+
 	// Get stream
 	if stream, err := storage.GetStream(fileName); err != nil {
 		t.Errorf("No error should happen when get sample file, but got %v", err)
 	} else {
 		if buffer, err := ioutil.ReadAll(stream); err != nil {
 			t.Errorf("No error should happen when read downloaded file, but got %v", err)
-		} else if string(buffer) == "sample" {
+		} else if string(buffer) != "sample" {
 			t.Errorf("Downloaded file should contain correct content, but got %v", string(buffer))
 		}
 	}
 
+	// Get stream range
+	for _, c := range []struct {
+		offset, length int64
+		content        string
+	}{{1, 3, "amp"}, {2, 0, "mple"}, {0, 1, "s"}} {
+		if stream, err := storage.GetStreamRange(fileName, c.offset, c.length); err != nil {
+			t.Errorf("No error should happen when get range %v+%v of sample file, but got %v", c.offset, c.length, err)
+		} else {
+			buffer, err := ioutil.ReadAll(stream)
+			stream.Close()
+			if err != nil {
+				t.Errorf("No error should happen when read range of downloaded file, but got %v", err)
+			} else if string(buffer) != c.content {
+				t.Errorf("Range %v+%v should contain %q, but got %q", c.offset, c.length, c.content, string(buffer))
+			}
+		}
+	}
+	if _, err := storage.GetStreamRange(fileName, -1, 3); !errors.Is(err, oss.ErrInvalidRange) {
+		t.Errorf("Negative offset should return ErrInvalidRange, but got %v", err)
+	}
+
 	// List
 	if objects, err := storage.List(randomPath); err != nil {
 		t.Errorf("No error should happen when list objects, but got %v", err)
 	} else if len(objects) != exceptObjects {
 		t.Errorf("Should found %v objects, but got %v", exceptObjects, len(objects))
 	} else {
+		if !sort.SliceIsSorted(objects, func(i, j int) bool { return objects[i].Path < objects[j].Path }) {
+			t.Errorf("List should return objects in lexicographic order")
+		}
+
 		var found1, found2 bool
 		for _, object := range objects {
+			checkLastModified(t, "List", object)
+
 			if object.Path == fileName {
 				found1 = true
+				checkSize(t, "List", object, int64(len("sample")))
+
+				// Object convenience methods
+				if stream, err := object.GetStream(); err != nil {
+					t.Errorf("No error should happen when get stream from listed object, but got %v", err)
+				} else {
+					if buffer, err := ioutil.ReadAll(stream); err != nil || string(buffer) != "sample" {
+						t.Errorf("Listed object should return correct content, but got %v, %v", string(buffer), err)
+					}
+					stream.Close()
+				}
+
+				if _, err := object.Stat(); err != nil {
+					t.Errorf("No error should happen when stat listed object, but got %v", err)
+				}
+
+				if _, err := object.URL(time.Minute); err != nil {
+					t.Errorf("No error should happen when get URL of listed object, but got %v", err)
+				}
 			}
 
 			if object.Path == fileName2 {
@@ -108,6 +266,228 @@ func TestAll(storage oss.StorageInterface, t *testing.T) {
 		}
 	}
 
+	// Copy
+	fileName3 := "/" + filepath.Join(randomPath, "sample3", "sample.txt")
+	if err := storage.Copy(fileName, fileName3); err != nil {
+		t.Errorf("No error should happen when copy sample file, but got %v", err)
+	} else {
+		if stream, err := storage.GetStream(fileName3); err != nil {
+			t.Errorf("No error should happen when get copied file, but got %v", err)
+		} else {
+			if buffer, err := ioutil.ReadAll(stream); err != nil || string(buffer) != "sample" {
+				t.Errorf("Copied file should contain correct content, but got %v, %v", string(buffer), err)
+			}
+			stream.Close()
+		}
+
+		// Move
+		fileName4 := "/" + filepath.Join(randomPath, "sample4", "sample.txt")
+		if err := storage.Move(fileName3, fileName4); err != nil {
+			t.Errorf("No error should happen when move copied file, but got %v", err)
+		} else {
+			if exists, err := storage.Exists(fileName3); err != nil || exists {
+				t.Errorf("Moved file should not exist in source path, but got %v, %v", exists, err)
+			}
+
+			if exists, err := storage.Exists(fileName4); err != nil || !exists {
+				t.Errorf("Moved file should exist in destination path, but got %v, %v", exists, err)
+			}
+		}
+
+		if err := storage.Delete(fileName4); err != nil {
+			t.Errorf("No error should happen when delete moved file, but got %v", err)
+		}
+	}
+
+	// Put file with options
+	fileName5 := "/" + filepath.Join(randomPath, "sample5", "sample.txt")
+	if object, err := storage.PutWithOptions(fileName5, strings.NewReader("sample"), &oss.PutOptions{
+		ContentType:  "text/plain",
+		CacheControl: "no-cache",
+		Metadata:     map[string]string{"owner": "tests"},
+	}); err != nil {
+		t.Errorf("No error should happen when save file with options, but got %v", err)
+	} else {
+		checkLastModified(t, "PutWithOptions", object)
+
+		// 不支持用户元数据的后端返回nil
+		if stat, err := storage.Stat(fileName5); err != nil {
+			t.Errorf("No error should happen when stat file saved with options, but got %v", err)
+		} else {
+			if !strings.HasPrefix(stat.ContentType, "text/plain") {
+				t.Errorf("Stat should return content type text/plain, but got %v", stat.ContentType)
+			}
+			if stat.Metadata != nil && stat.Metadata["owner"] != "tests" {
+				t.Errorf("Stat should return metadata owner=tests, but got %v", stat.Metadata)
+			}
+		}
+
+		if err := storage.Delete(fileName5); err != nil {
+			t.Errorf("No error should happen when delete file saved with options, but got %v", err)
+		}
+	}
+
+	// Put file with collision policy
+	fileName6 := "/" + filepath.Join(randomPath, "sample6", "sample.txt")
+	if _, err := storage.Put(fileName6, strings.NewReader("sample")); err != nil {
+		t.Errorf("No error should happen when save file for collision, but got %v", err)
+	} else {
+		if _, err := storage.PutWithOptions(fileName6, strings.NewReader("sample2"), &oss.PutOptions{Collision: oss.CollisionError}); !errors.Is(err, oss.ErrConflict) {
+			t.Errorf("Put on existing path with CollisionError should return ErrConflict, but got %v", err)
+		}
+
+		renamed := oss.RenamedPath(fileName6, 1)
+		if object, err := storage.PutWithOptions(fileName6, strings.NewReader("sample2"), &oss.PutOptions{Collision: oss.CollisionRename}); err != nil {
+			t.Errorf("No error should happen when save file with CollisionRename, but got %v", err)
+		} else if !strings.HasSuffix(object.Path, "sample-1.txt") {
+			t.Errorf("Renamed object should have path %v, but got %v", renamed, object.Path)
+		}
+		if exists, err := storage.Exists(renamed); err != nil || !exists {
+			t.Errorf("Renamed file should exist, but got %v, %v", exists, err)
+		}
+
+		storage.Delete(renamed)
+		storage.Delete(fileName6)
+	}
+
+	// Multipart upload
+	if uploader, ok := storage.(oss.MultipartUploader); ok {
+		multipartFile := "/" + filepath.Join(randomPath, "multipart", "sample.txt")
+		if object, err := oss.UploadMultipart(uploader, multipartFile, strings.NewReader("sample"), 0, nil); err != nil {
+			t.Errorf("No error should happen when upload file in parts, but got %v", err)
+		} else {
+			checkLastModified(t, "UploadMultipart", object)
+			if stream, err := storage.GetStream(multipartFile); err != nil {
+				t.Errorf("No error should happen when get file uploaded in parts, but got %v", err)
+			} else {
+				if buffer, err := ioutil.ReadAll(stream); err != nil || string(buffer) != "sample" {
+					t.Errorf("File uploaded in parts should contain correct content, but got %v, %v", string(buffer), err)
+				}
+				stream.Close()
+			}
+			storage.Delete(multipartFile)
+		}
+
+		if upload, err := uploader.InitiateMultipart(multipartFile, nil); err != nil {
+			t.Errorf("No error should happen when initiate multipart upload, but got %v", err)
+		} else if err := uploader.AbortMultipart(upload); err != nil {
+			t.Errorf("No error should happen when abort multipart upload, but got %v", err)
+		}
+	}
+
+	// Object ACL
+	if manager, ok := storage.(oss.ACLManager); ok {
+		aclFile := "/" + filepath.Join(randomPath, "acl", "sample.txt")
+		if _, err := storage.Put(aclFile, strings.NewReader("sample")); err != nil {
+			t.Errorf("No error should happen when upload file, but got %v", err)
+		} else {
+			if err := manager.SetACL(aclFile, oss.ACLPrivate); err != nil {
+				t.Errorf("No error should happen when set object ACL, but got %v", err)
+			} else if acl, err := manager.GetACL(aclFile); err != nil || acl != oss.ACLPrivate {
+				t.Errorf("Object ACL should be private, but got %v, %v", acl, err)
+			}
+			storage.Delete(aclFile)
+		}
+	}
+
+	// New writer
+	writerFile := "/" + filepath.Join(randomPath, "writer", "sample.txt")
+	if writer, err := storage.NewWriter(writerFile); err != nil {
+		t.Errorf("No error should happen when create writer, but got %v", err)
+	} else {
+		io.WriteString(writer, "sam")
+		io.WriteString(writer, "ple")
+		if err := writer.Close(); err != nil {
+			t.Errorf("No error should happen when close writer, but got %v", err)
+		} else if stream, err := storage.GetStream(writerFile); err != nil {
+			t.Errorf("No error should happen when get written file, but got %v", err)
+		} else {
+			if buffer, err := ioutil.ReadAll(stream); err != nil || string(buffer) != "sample" {
+				t.Errorf("Written file should contain correct content, but got %v, %v", string(buffer), err)
+			}
+			stream.Close()
+			storage.Delete(writerFile)
+		}
+	}
+
+	// New writer with windows path
+	windowsFile := "/" + filepath.Join(randomPath, "windows") + `\sample.txt`
+	normalizedFile := "/" + filepath.Join(randomPath, "windows", "sample.txt")
+	if writer, err := storage.NewWriter(windowsFile); err != nil {
+		t.Errorf("No error should happen when create writer for windows path, but got %v", err)
+	} else {
+		io.WriteString(writer, "sample")
+		if err := writer.Close(); err != nil {
+			t.Errorf("No error should happen when close writer for windows path, but got %v", err)
+		} else if stream, err := storage.GetStream(normalizedFile); err != nil {
+			t.Errorf("Windows path should be written as %v, but got %v", normalizedFile, err)
+		} else {
+			stream.Close()
+			storage.Delete(normalizedFile)
+		}
+	}
+	reservedFile := "/" + filepath.Join(randomPath, "windows", "NUL")
+	if writer, err := storage.NewWriter(reservedFile); err == nil {
+		io.WriteString(writer, "sample")
+		if err := writer.Close(); !errors.Is(err, oss.ErrInvalidPath) {
+			t.Errorf("Reserved device name should be rejected by writer, but got %v", err)
+		}
+	} else if !errors.Is(err, oss.ErrInvalidPath) {
+		t.Errorf("Reserved device name should be rejected by writer, but got %v", err)
+	}
+
+	// Delete objects
+	batchFiles := []string{
+		"/" + filepath.Join(randomPath, "batch", "a.txt"),
+		"/" + filepath.Join(randomPath, "batch", "b.txt"),
+	}
+	for _, batchFile := range batchFiles {
+		if _, err := storage.Put(batchFile, strings.NewReader("sample")); err != nil {
+			t.Errorf("No error should happen when save batch file, but got %v", err)
+		}
+	}
+
+	if err := storage.DeleteObjects(append(batchFiles, "/"+filepath.Join(randomPath, "batch", "missing.txt"))); err != nil {
+		t.Errorf("No error should happen when delete objects, but got %v", err)
+	}
+
+	for _, batchFile := range batchFiles {
+		if exists, err := storage.Exists(batchFile); err != nil || exists {
+			t.Errorf("Deleted batch file should not exist, but got %v, %v", exists, err)
+		}
+	}
+
+	// Delete dir
+	dirFiles := []string{
+		"/" + filepath.Join(randomPath, "dir", "a.txt"),
+		"/" + filepath.Join(randomPath, "dir", "sub", "b.txt"),
+	}
+	siblingFile := "/" + filepath.Join(randomPath, "dirkeep.txt")
+	for _, dirFile := range append(dirFiles, siblingFile) {
+		if _, err := storage.Put(dirFile, strings.NewReader("sample")); err != nil {
+			t.Errorf("No error should happen when save dir file, but got %v", err)
+		}
+	}
+
+	if err := storage.DeleteDir("/" + filepath.Join(randomPath, "dir")); err != nil {
+		t.Errorf("No error should happen when delete dir, but got %v", err)
+	}
+
+	for _, dirFile := range dirFiles {
+		if exists, err := storage.Exists(dirFile); err != nil || exists {
+			t.Errorf("File in deleted dir should not exist, but got %v, %v", exists, err)
+		}
+	}
+
+	if exists, err := storage.Exists(siblingFile); err != nil || !exists {
+		t.Errorf("File with same prefix outside deleted dir should exist, but got %v, %v", exists, err)
+	}
+	storage.Delete(siblingFile)
+
+	if err := storage.DeleteDir("/"); !errors.Is(err, oss.ErrDeleteRoot) {
+		t.Errorf("Should return ErrDeleteRoot when delete root dir, but got %v", err)
+	}
+
 	// Delete
 	if err := storage.Delete(fileName); err != nil {
 		t.Errorf("No error should happen when delete sample file, but got %v", err)
@@ -118,6 +498,10 @@ func TestAll(storage oss.StorageInterface, t *testing.T) {
 		t.Errorf("There should be an error when get deleted sample file")
 	}
 
+	if exists, err := storage.Exists(fileName); err != nil || exists {
+		t.Errorf("Deleted sample file should not exist, but got %v, %v", exists, err)
+	}
+
 	// Get file after delete
 	if _, err := storage.Get(fileName2); err != nil {
 		t.Errorf("Sample file 2 should no been deleted")
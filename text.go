@@ -0,0 +1,161 @@
+package oss
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	pathpkg "path"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// textSniffSize 判断内容是否为UTF-8时读取的字节数
+const textSniffSize = 4096
+
+// TextOptions 文本对象的转码选项
+type TextOptions struct {
+	// SourceCharset 未声明字符集或声明与内容不符时假定的源字符集，例如 gbk、gb18030
+	SourceCharset string
+}
+
+// TextStorage 读取时将文本对象转码为UTF-8的存储包装器
+// 只处理内容类型为文本的对象，已经是UTF-8的内容原样返回，其他方法直接调用被包装的存储
+type TextStorage struct {
+	StorageInterface
+	// Options 转码选项
+	Options TextOptions
+}
+
+// NewTextStorage 创建读取时转码文本对象的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 转码选项
+// 返回:
+//   - *TextStorage: 存储包装器实例
+func NewTextStorage(storage StorageInterface, opts TextOptions) *TextStorage {
+	return &TextStorage{StorageInterface: storage, Options: opts}
+}
+
+// GetStream 获取文件流，文本对象转码为UTF-8
+// 需要额外调用一次 Stat 获取内容类型
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: UTF-8编码的文件流
+//   - error: 错误信息
+func (storage TextStorage) GetStream(path string) (io.ReadCloser, error) {
+	contentType := mime.TypeByExtension(pathpkg.Ext(path))
+	if object, err := storage.StorageInterface.Stat(path); err == nil && object.ContentType != "" {
+		contentType = object.ContentType
+	}
+
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil || !IsTextContentType(contentType) {
+		return stream, err
+	}
+
+	// 声明的字符集优先，UTF-8声明不可靠时使用配置的源字符集
+	charset := storage.Options.SourceCharset
+	if _, params, err := mime.ParseMediaType(contentType); err == nil && params["charset"] != "" && !isUTF8Charset(params["charset"]) {
+		charset = params["charset"]
+	}
+
+	reader, err := DecodeText(stream, charset)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, stream}, nil
+}
+
+// Get 获取文件，文本对象转码为UTF-8后写入临时文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: UTF-8编码的临时文件
+//   - error: 错误信息
+func (storage TextStorage) Get(path string) (*os.File, error) {
+	stream, err := storage.GetStream(path)
+	if err != nil {
+		return nil, err
+	}
+	return StreamToTempFile(stream, "oss-text-*"+pathpkg.Ext(path))
+}
+
+// DecodeText 将文本内容转码为UTF-8
+// 内容开头已经是合法的UTF-8时原样返回，否则按charset解码
+// 参数:
+//   - reader: 文本内容读取器
+//   - charset: 源字符集，为空或为UTF-8时原样返回
+// 返回:
+//   - io.Reader: UTF-8编码的内容读取器
+//   - error: 错误信息，字符集不支持时返回 ErrNotSupported
+func DecodeText(reader io.Reader, charset string) (io.Reader, error) {
+	if charset == "" || isUTF8Charset(charset) {
+		return reader, nil
+	}
+
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown charset %s", ErrNotSupported, charset)
+	}
+
+	buffered := bufio.NewReaderSize(reader, textSniffSize)
+	if head, err := buffered.Peek(textSniffSize); validUTF8Prefix(head, err != nil) {
+		return buffered, nil
+	}
+	return transform.NewReader(buffered, encoding.NewDecoder()), nil
+}
+
+// IsTextContentType 判断内容类型是否为文本
+// 参数:
+//   - contentType: 内容类型
+// 返回:
+//   - bool: text/*、JSON、XML、JavaScript和CSV返回true
+func IsTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/xml", mediaType == "application/javascript", mediaType == "application/csv":
+		return true
+	case strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+// isUTF8Charset 判断字符集是否为UTF-8或其子集
+func isUTF8Charset(charset string) bool {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return true
+	}
+	return false
+}
+
+// validUTF8Prefix 判断内容开头是否为合法的UTF-8
+// 内容未读完时忽略末尾可能被截断的字符
+func validUTF8Prefix(data []byte, complete bool) bool {
+	if utf8.Valid(data) {
+		return true
+	}
+	for i := 1; !complete && i < utf8.UTFMax && i < len(data); i++ {
+		if utf8.Valid(data[:len(data)-i]) {
+			return true
+		}
+	}
+	return false
+}
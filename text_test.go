@@ -0,0 +1,42 @@
+package oss_test
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+)
+
+func TestTextStorage(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	storage := oss.NewTextStorage(fileSystem, oss.TextOptions{SourceCharset: "gbk"})
+
+	// "中文" 的GBK编码
+	fileSystem.Put("/gbk.txt", strings.NewReader("\xd6\xd0\xce\xc4"))
+	fileSystem.Put("/utf8.txt", strings.NewReader("中文"))
+	fileSystem.Put("/binary.png", strings.NewReader("\xd6\xd0\xce\xc4"))
+
+	for path, expected := range map[string]string{"/gbk.txt": "中文", "/utf8.txt": "中文", "/binary.png": "\xd6\xd0\xce\xc4"} {
+		if stream, err := storage.GetStream(path); err != nil {
+			t.Errorf("No error should happen when get %v, but got %v", path, err)
+		} else {
+			if buffer, err := io.ReadAll(stream); err != nil || string(buffer) != expected {
+				t.Errorf("Should get %q for %v, but got %q, %v", expected, path, string(buffer), err)
+			}
+			stream.Close()
+		}
+	}
+
+	if file, err := storage.Get("/gbk.txt"); err != nil {
+		t.Errorf("No error should happen when get file, but got %v", err)
+	} else {
+		if buffer, _ := io.ReadAll(file); string(buffer) != "中文" {
+			t.Errorf("Downloaded file should be transcoded, but got %q", string(buffer))
+		}
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
@@ -0,0 +1,377 @@
+package oss
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShortTimeout 元数据类操作的默认超时时间，例如 Stat、Exists、Delete
+	DefaultShortTimeout = 30 * time.Second
+	// DefaultLongTimeout 传输类操作的默认超时时间，例如 Get、Put、List
+	DefaultLongTimeout = 10 * time.Minute
+)
+
+// TimeoutStorage 为每次调用设置超时时间的存储包装器
+// 存储接口还不支持context，超时后立即返回 ErrTimeout，被包装的调用在后台继续执行直到结束，
+// 结束时返回的文件或流会被自动关闭
+type TimeoutStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+	// Short 元数据类操作的超时时间：Stat、Exists、Delete、GetURL、GetSignedURL、GetUploadURL
+	Short time.Duration
+	// Long 传输类操作的超时时间：Get、GetStream、GetStreamRange、Put、PutWithOptions、NewWriter、List、DeleteObjects、DeleteDir、Copy、Move
+	// 不支持服务端复制的后端通过 CopyByStream 和 MoveByCopy 传输完整的对象，因此 Copy 和 Move 也使用该超时时间
+	Long time.Duration
+}
+
+// WithTimeout 创建为每次调用设置超时时间的存储包装器
+// 传输类操作使用d作为超时时间，元数据类操作使用 DefaultShortTimeout 和d中较小的一个
+// GetStream 和 NewWriter 的超时只限制打开流的时间，不限制之后的读写
+// 参数:
+//   - storage: 被包装的存储接口
+//   - d: 传输类操作的超时时间，小于等于0时使用 DefaultLongTimeout
+// 返回:
+//   - *TimeoutStorage: 存储包装器实例
+func WithTimeout(storage StorageInterface, d time.Duration) *TimeoutStorage {
+	if d <= 0 {
+		d = DefaultLongTimeout
+	}
+	return &TimeoutStorage{StorageInterface: storage, Short: min(DefaultShortTimeout, d), Long: d}
+}
+
+// withDeadline 在超时时间内执行操作
+// 超时后返回 ErrTimeout，操作结束后实现了 io.Closer 的结果会被关闭
+func withDeadline[T any](timeout time.Duration, op, path string, fn func() (T, error)) (T, error) {
+	if timeout <= 0 {
+		return fn()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-timer.C:
+		// 调用方已经放弃结果，关闭之后返回的文件或流
+		go func() {
+			if result := <-done; result.err == nil {
+				if closer, ok := any(result.value).(io.Closer); ok && closer != nil {
+					closer.Close()
+				}
+			}
+		}()
+		var zero T
+		return zero, fmt.Errorf("%w: %s %s after %v", ErrTimeout, op, path, timeout)
+	}
+}
+
+// withDeadlineErr 在超时时间内执行只返回错误的操作
+func withDeadlineErr(timeout time.Duration, op, path string, fn func() error) error {
+	_, err := withDeadline(timeout, op, path, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+// Get 获取指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Get(path string) (*os.File, error) {
+	return withDeadline(storage.Long, "get", path, func() (*os.File, error) {
+		return storage.StorageInterface.Get(path)
+	})
+}
+
+// GetStream 获取指定路径文件的流
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 可读流
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) GetStream(path string) (io.ReadCloser, error) {
+	return withDeadline(storage.Long, "get stream", path, func() (io.ReadCloser, error) {
+		return storage.StorageInterface.GetStream(path)
+	})
+}
+
+// GetStreamRange 获取指定路径文件的部分内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	return withDeadline(storage.Long, "get stream range", path, func() (io.ReadCloser, error) {
+		return storage.StorageInterface.GetStreamRange(path, offset, length)
+	})
+}
+
+// Stat 获取指定路径文件的元信息
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Stat(path string) (*Object, error) {
+	return withDeadline(storage.Short, "stat", path, func() (*Object, error) {
+		return storage.StorageInterface.Stat(path)
+	})
+}
+
+// Exists 检查指定路径的文件是否存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Exists(path string) (bool, error) {
+	return withDeadline(storage.Short, "exists", path, func() (bool, error) {
+		return storage.StorageInterface.Exists(path)
+	})
+}
+
+// Put 上传文件到指定路径
+// 超时后被包装的上传不能再读取可以Seek的reader，返回时不会有正在进行的读取，调用方可以回到原来的位置重试；
+// 不能Seek的reader无法重新读取，超时后被包装的上传可能仍在读取
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Put(path string, reader io.Reader) (*Object, error) {
+	body, cancel := cancelableReader(storage.Long, reader)
+	object, err := withDeadline(storage.Long, "put", path, func() (*Object, error) {
+		return storage.StorageInterface.Put(path, body)
+	})
+	if errors.Is(err, ErrTimeout) {
+		cancel()
+	}
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件，超时后对reader的处理与 Put 相同
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	body, cancel := cancelableReader(storage.Long, reader)
+	object, err := withDeadline(storage.Long, "put", path, func() (*Object, error) {
+		return storage.StorageInterface.PutWithOptions(path, body, opts)
+	})
+	if errors.Is(err, ErrTimeout) {
+		cancel()
+	}
+	return object, err
+}
+
+// cancelableReader 包装可以Seek的上传内容，取消后读取和Seek都返回 ErrTimeout
+// 取消时等待正在进行的读取结束，之后被放弃的上传不会再访问原来的reader；
+// 同时实现 io.ReaderAt 的内容（例如本地文件）保留 ReadAt，后端仍可并发读取分片
+func cancelableReader(timeout time.Duration, reader io.Reader) (io.Reader, func()) {
+	seeker, ok := reader.(io.ReadSeeker)
+	if timeout <= 0 || !ok {
+		return reader, func() {}
+	}
+	guarded := &cancelableSeeker{ReadSeeker: seeker}
+	if at, ok := reader.(io.ReaderAt); ok {
+		return &cancelableReaderAt{cancelableSeeker: guarded, at: at}, guarded.cancel
+	}
+	return guarded, guarded.cancel
+}
+
+// cancelableSeeker 可以取消的上传内容
+type cancelableSeeker struct {
+	io.ReadSeeker
+	mu       sync.RWMutex
+	canceled bool
+}
+
+// cancel 等待正在进行的读取结束后禁止继续访问
+func (reader *cancelableSeeker) cancel() {
+	reader.mu.Lock()
+	reader.canceled = true
+	reader.mu.Unlock()
+}
+
+// Read 读取内容，取消后返回 ErrTimeout
+func (reader *cancelableSeeker) Read(p []byte) (int, error) {
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	if reader.canceled {
+		return 0, ErrTimeout
+	}
+	return reader.ReadSeeker.Read(p)
+}
+
+// Seek 移动读取位置，取消后返回 ErrTimeout
+func (reader *cancelableSeeker) Seek(offset int64, whence int) (int64, error) {
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	if reader.canceled {
+		return 0, ErrTimeout
+	}
+	return reader.ReadSeeker.Seek(offset, whence)
+}
+
+// cancelableReaderAt 同时支持随机读取的可以取消的上传内容
+type cancelableReaderAt struct {
+	*cancelableSeeker
+	at io.ReaderAt
+}
+
+// ReadAt 读取指定位置的内容，取消后返回 ErrTimeout
+func (reader *cancelableReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	reader.mu.RLock()
+	defer reader.mu.RUnlock()
+	if reader.canceled {
+		return 0, ErrTimeout
+	}
+	return reader.at.ReadAt(p, offset)
+}
+
+// NewWriter 创建流式写入器
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) NewWriter(path string) (io.WriteCloser, error) {
+	return withDeadline(storage.Long, "new writer", path, func() (io.WriteCloser, error) {
+		return storage.StorageInterface.NewWriter(path)
+	})
+}
+
+// Delete 删除指定路径的文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Delete(path string) error {
+	return withDeadlineErr(storage.Short, "delete", path, func() error {
+		return storage.StorageInterface.Delete(path)
+	})
+}
+
+// DeleteObjects 批量删除多个文件
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) DeleteObjects(paths []string) error {
+	return withDeadlineErr(storage.Long, "delete objects", fmt.Sprintf("(%d paths)", len(paths)), func() error {
+		return storage.StorageInterface.DeleteObjects(paths)
+	})
+}
+
+// DeleteDir 删除目录下的全部文件
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) DeleteDir(dir string) error {
+	return withDeadlineErr(storage.Long, "delete dir", dir, func() error {
+		return storage.StorageInterface.DeleteDir(dir)
+	})
+}
+
+// Copy 复制文件
+// 超时后复制在后台继续执行，目标对象仍可能被写入
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Copy(srcPath, dstPath string) error {
+	return withDeadlineErr(storage.Long, "copy", srcPath, func() error {
+		return storage.StorageInterface.Copy(srcPath, dstPath)
+	})
+}
+
+// Move 移动文件
+// 超时后移动在后台继续执行，源对象仍可能在之后被删除
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) Move(srcPath, dstPath string) error {
+	return withDeadlineErr(storage.Long, "move", srcPath, func() error {
+		return storage.StorageInterface.Move(srcPath, dstPath)
+	})
+}
+
+// List 列出目录下的文件
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) List(path string) ([]*Object, error) {
+	return withDeadline(storage.Long, "list", path, func() ([]*Object, error) {
+		return storage.StorageInterface.List(path)
+	})
+}
+
+// GetURL 获取指定路径文件的访问URL
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - string: 访问URL
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) GetURL(path string) (string, error) {
+	return withDeadline(storage.Short, "get url", path, func() (string, error) {
+		return storage.StorageInterface.GetURL(path)
+	})
+}
+
+// GetSignedURL 生成指定路径文件的预签名URL
+// 参数:
+//   - path: 文件路径
+//   - opts: 签名选项
+// 返回:
+//   - string: 预签名URL
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) GetSignedURL(path string, opts SignedURLOptions) (string, error) {
+	return withDeadline(storage.Short, "get signed url", path, func() (string, error) {
+		return storage.StorageInterface.GetSignedURL(path, opts)
+	})
+}
+
+// GetUploadURL 生成客户端直传地址
+// 参数:
+//   - path: 文件路径
+//   - opts: 直传选项
+// 返回:
+//   - *UploadURL: 直传地址
+//   - error: 错误信息，超时返回 ErrTimeout
+func (storage *TimeoutStorage) GetUploadURL(path string, opts UploadURLOptions) (*UploadURL, error) {
+	return withDeadline(storage.Short, "get upload url", path, func() (*UploadURL, error) {
+		return storage.StorageInterface.GetUploadURL(path, opts)
+	})
+}
@@ -0,0 +1,86 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+	"github.com/smart-unicom/oss/ossretry"
+)
+
+// slowStorage 元数据操作阻塞直到通道关闭的文件系统
+type slowStorage struct {
+	*filesystem.FileSystem
+	release chan struct{}
+}
+
+func (storage slowStorage) Stat(path string) (*oss.Object, error) {
+	<-storage.release
+	return storage.FileSystem.Stat(path)
+}
+
+func TestWithTimeout(t *testing.T) {
+	fileSystem := filesystem.New(t.TempDir())
+	slow := slowStorage{FileSystem: fileSystem, release: make(chan struct{})}
+	defer close(slow.release)
+
+	storage := oss.WithTimeout(slow, time.Minute)
+	if storage.Short != oss.DefaultShortTimeout || storage.Long != time.Minute {
+		t.Errorf("Should use default short timeout and given long timeout, but got %v, %v", storage.Short, storage.Long)
+	}
+
+	storage.Short = 10 * time.Millisecond
+	if _, err := storage.Stat("/a.txt"); !errors.Is(err, oss.ErrTimeout) || oss.HTTPStatus(err) != http.StatusGatewayTimeout {
+		t.Errorf("Slow stat should time out with 504, but got %v", err)
+	}
+
+	if _, err := storage.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Errorf("Put within the long timeout should succeed, but got %v", err)
+	}
+}
+
+func TestTimeoutRetry(t *testing.T) {
+	mock := ossmock.New()
+	abandoned := make(chan error, 1)
+	var attempts int32
+	mock.PutFunc = func(path string, reader io.Reader) (*oss.Object, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// 第一次上传读取一部分后卡住，超时返回后继续读取
+			head := make([]byte, 3)
+			if _, err := io.ReadFull(reader, head); err != nil {
+				abandoned <- err
+				return nil, err
+			}
+			time.Sleep(50 * time.Millisecond)
+			_, err := io.ReadAll(reader)
+			abandoned <- err
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &oss.Object{Path: path, Size: int64(len(content)), Metadata: map[string]string{"content": string(content)}}, nil
+	}
+
+	timeout := oss.WithTimeout(mock, time.Minute)
+	timeout.Long = 10 * time.Millisecond
+	storage := ossretry.Wrap(timeout, ossretry.Config{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	object, err := storage.Put("/a.txt", strings.NewReader("sample content"))
+	if err != nil {
+		t.Fatalf("Put should succeed after retrying the timed out attempt, but got %v", err)
+	}
+	if object.Metadata["content"] != "sample content" {
+		t.Errorf("Retried upload should read the content from the start, but got %q", object.Metadata["content"])
+	}
+	if err := <-abandoned; !errors.Is(err, oss.ErrTimeout) {
+		t.Errorf("Abandoned upload should not read after the timeout, but got %v", err)
+	}
+}
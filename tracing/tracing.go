@@ -0,0 +1,408 @@
+// Package tracing 存储操作的OpenTelemetry链路追踪
+// 为每次存储调用创建span，记录存储后端、存储桶、对象路径、字节数、耗时和错误
+package tracing
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName 创建Tracer使用的instrumentation scope名称
+const ScopeName = "github.com/smart-unicom/oss/tracing"
+
+// span属性
+const (
+	// AttrProvider 存储后端名称，例如 s3、aliyun、filesystem
+	AttrProvider = attribute.Key("oss.provider")
+	// AttrBucket 存储桶名称
+	AttrBucket = attribute.Key("oss.bucket")
+	// AttrKey 对象路径，复制和移动时为目标路径
+	AttrKey = attribute.Key("oss.key")
+	// AttrSourceKey 复制和移动的源路径
+	AttrSourceKey = attribute.Key("oss.source_key")
+	// AttrBytes 上传或下载的字节数
+	AttrBytes = attribute.Key("oss.bytes")
+	// AttrCount 批量删除的对象数量或列举返回的对象数量
+	AttrCount = attribute.Key("oss.count")
+	// AttrDuration 调用耗时，单位毫秒
+	AttrDuration = attribute.Key("oss.duration_ms")
+)
+
+// Options 链路追踪选项
+type Options struct {
+	// TracerProvider 创建Tracer的提供者，为nil时使用 otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+	// Provider 存储后端名称，为空时使用被包装存储的包名，例如 s3
+	Provider string
+	// Bucket 存储桶名称，为空时不记录
+	Bucket string
+}
+
+// Storage 为存储调用创建span的存储包装器
+// 存储接口还不支持context，span默认没有父span，需要加入请求的链路时使用 WithContext
+type Storage struct {
+	// StorageInterface 被包装的存储接口
+	oss.StorageInterface
+
+	ctx    context.Context
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+// Wrap 创建为存储调用创建span的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 链路追踪选项
+// 返回:
+//   - *Storage: 存储包装器实例
+func Wrap(storage oss.StorageInterface, opts Options) *Storage {
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.Provider == "" {
+		opts.Provider = providerName(storage)
+	}
+
+	attrs := []attribute.KeyValue{AttrProvider.String(opts.Provider)}
+	if opts.Bucket != "" {
+		attrs = append(attrs, AttrBucket.String(opts.Bucket))
+	}
+	return &Storage{
+		StorageInterface: storage,
+		ctx:              context.Background(),
+		tracer:           opts.TracerProvider.Tracer(ScopeName),
+		attrs:            attrs,
+	}
+}
+
+// WithContext 返回以ctx中的span为父span的存储包装器，用于在处理请求时加入请求的链路
+// 参数:
+//   - ctx: 携带父span的上下文
+// 返回:
+//   - *Storage: 共享被包装存储的新包装器
+func (storage *Storage) WithContext(ctx context.Context) *Storage {
+	traced := *storage
+	traced.ctx = ctx
+	return &traced
+}
+
+// Get 获取文件并记录span
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件对象
+//   - error: 错误信息
+func (storage *Storage) Get(path string) (*os.File, error) {
+	span := storage.start("Get", AttrKey.String(path))
+	file, err := storage.StorageInterface.Get(path)
+	if err == nil {
+		if info, statErr := file.Stat(); statErr == nil {
+			span.SetAttributes(AttrBytes.Int64(info.Size()))
+		}
+	}
+	span.end(err)
+	return file, err
+}
+
+// GetStream 获取文件流并记录span，span在关闭流时结束，记录实际读取的字节数
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStream(path string) (io.ReadCloser, error) {
+	span := storage.start("GetStream", AttrKey.String(path))
+	stream, err := storage.StorageInterface.GetStream(path)
+	if err != nil {
+		span.end(err)
+		return nil, err
+	}
+	return &tracedReader{ReadCloser: stream, span: span}, nil
+}
+
+// GetStreamRange 范围读取文件流并记录span，span在关闭流时结束
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (storage *Storage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	span := storage.start("GetStreamRange", AttrKey.String(path))
+	stream, err := storage.StorageInterface.GetStreamRange(path, offset, length)
+	if err != nil {
+		span.end(err)
+		return nil, err
+	}
+	return &tracedReader{ReadCloser: stream, span: span}, nil
+}
+
+// Stat 获取对象信息并记录span
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Stat(path string) (*oss.Object, error) {
+	span := storage.start("Stat", AttrKey.String(path))
+	object, err := storage.StorageInterface.Stat(path)
+	span.end(err)
+	return object, err
+}
+
+// Exists 检查对象是否存在并记录span
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (storage *Storage) Exists(path string) (bool, error) {
+	span := storage.start("Exists", AttrKey.String(path))
+	exists, err := storage.StorageInterface.Exists(path)
+	span.end(err)
+	return exists, err
+}
+
+// Put 上传文件并记录span
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	span := storage.start("Put", AttrKey.String(path))
+	size := oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.Put(path, reader)
+	span.setBytes(object, size)
+	span.end(err)
+	return object, err
+}
+
+// PutWithOptions 使用指定选项上传文件并记录span
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *oss.Object: 对象信息
+//   - error: 错误信息
+func (storage *Storage) PutWithOptions(path string, reader io.Reader, opts *oss.PutOptions) (*oss.Object, error) {
+	span := storage.start("Put", AttrKey.String(path))
+	size := oss.ReaderSize(reader)
+	object, err := storage.StorageInterface.PutWithOptions(path, reader, opts)
+	span.setBytes(object, size)
+	span.end(err)
+	return object, err
+}
+
+// NewWriter 创建流式写入器并记录span，span在关闭写入器时结束，记录写入的字节数
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (storage *Storage) NewWriter(path string) (io.WriteCloser, error) {
+	span := storage.start("Put", AttrKey.String(path))
+	writer, err := storage.StorageInterface.NewWriter(path)
+	if err != nil {
+		span.end(err)
+		return nil, err
+	}
+	return &tracedWriter{WriteCloser: writer, span: span}, nil
+}
+
+// Delete 删除文件并记录span
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Delete(path string) error {
+	span := storage.start("Delete", AttrKey.String(path))
+	err := storage.StorageInterface.Delete(path)
+	span.end(err)
+	return err
+}
+
+// DeleteObjects 批量删除对象并记录span
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteObjects(paths []string) error {
+	span := storage.start("DeleteObjects", AttrCount.Int(len(paths)))
+	err := storage.StorageInterface.DeleteObjects(paths)
+	span.end(err)
+	return err
+}
+
+// DeleteDir 删除目录并记录span
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) DeleteDir(dir string) error {
+	span := storage.start("DeleteDir", AttrKey.String(dir))
+	err := storage.StorageInterface.DeleteDir(dir)
+	span.end(err)
+	return err
+}
+
+// Copy 复制文件并记录span
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Copy(srcPath, dstPath string) error {
+	span := storage.start("Copy", AttrKey.String(dstPath), AttrSourceKey.String(srcPath))
+	err := storage.StorageInterface.Copy(srcPath, dstPath)
+	span.end(err)
+	return err
+}
+
+// Move 移动文件并记录span
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (storage *Storage) Move(srcPath, dstPath string) error {
+	span := storage.start("Move", AttrKey.String(dstPath), AttrSourceKey.String(srcPath))
+	err := storage.StorageInterface.Move(srcPath, dstPath)
+	span.end(err)
+	return err
+}
+
+// List 列出对象并记录span，记录返回的对象数量
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*oss.Object: 对象列表
+//   - error: 错误信息
+func (storage *Storage) List(path string) ([]*oss.Object, error) {
+	span := storage.start("List", AttrKey.String(path))
+	objects, err := storage.StorageInterface.List(path)
+	if err == nil {
+		span.SetAttributes(AttrCount.Int(len(objects)))
+	}
+	span.end(err)
+	return objects, err
+}
+
+// start 创建名为 oss.<op> 的客户端span
+func (storage *Storage) start(op string, attrs ...attribute.KeyValue) *span {
+	_, s := storage.tracer.Start(storage.ctx, "oss."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(storage.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+	return &span{Span: s, start: time.Now()}
+}
+
+// span 记录开始时间的span
+type span struct {
+	trace.Span
+	start time.Time
+}
+
+// setBytes 记录上传的字节数，优先使用上传后的对象大小
+func (s *span) setBytes(object *oss.Object, size int64) {
+	if object != nil && object.Size > 0 {
+		size = object.Size
+	}
+	if size >= 0 {
+		s.SetAttributes(AttrBytes.Int64(size))
+	}
+}
+
+// end 记录耗时和错误并结束span
+func (s *span) end(err error) {
+	s.SetAttributes(AttrDuration.Float64(float64(time.Since(s.start).Microseconds()) / 1000))
+	if err != nil {
+		s.RecordError(err)
+		s.SetStatus(codes.Error, err.Error())
+	}
+	s.End()
+}
+
+// tracedReader 关闭时结束span的文件流
+type tracedReader struct {
+	io.ReadCloser
+	span  *span
+	bytes int64
+	err   error
+}
+
+// Read 读取内容并统计字节数
+func (reader *tracedReader) Read(p []byte) (int, error) {
+	n, err := reader.ReadCloser.Read(p)
+	reader.bytes += int64(n)
+	if err != nil && err != io.EOF {
+		reader.err = err
+	}
+	return n, err
+}
+
+// Close 关闭文件流并结束span，读取时的错误也记录在span中
+func (reader *tracedReader) Close() error {
+	err := reader.ReadCloser.Close()
+	if reader.span != nil {
+		reader.span.SetAttributes(AttrBytes.Int64(reader.bytes))
+		if reader.err != nil {
+			reader.span.end(reader.err)
+		} else {
+			reader.span.end(err)
+		}
+		reader.span = nil
+	}
+	return err
+}
+
+// tracedWriter 关闭时结束span的写入器
+type tracedWriter struct {
+	io.WriteCloser
+	span  *span
+	bytes int64
+}
+
+// Write 写入内容并统计字节数
+func (writer *tracedWriter) Write(p []byte) (int, error) {
+	n, err := writer.WriteCloser.Write(p)
+	writer.bytes += int64(n)
+	return n, err
+}
+
+// Close 关闭写入器并结束span
+func (writer *tracedWriter) Close() error {
+	err := writer.WriteCloser.Close()
+	if writer.span != nil {
+		writer.span.SetAttributes(AttrBytes.Int64(writer.bytes))
+		writer.span.end(err)
+		writer.span = nil
+	}
+	return err
+}
+
+// providerName 返回存储实现所在的包名，例如 *s3.Client 返回 s3
+func providerName(storage oss.StorageInterface) string {
+	t := reflect.TypeOf(storage)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.PkgPath() == "" {
+		return "unknown"
+	}
+	return path.Base(t.PkgPath())
+}
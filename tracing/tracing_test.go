@@ -0,0 +1,71 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// attr 返回span中指定属性的值
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	storage := Wrap(filesystem.New(t.TempDir()), Options{TracerProvider: provider, Bucket: "assets"})
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	traced := storage.WithContext(ctx)
+	if _, err := traced.Put("/a.txt", strings.NewReader("sample")); err != nil {
+		t.Fatalf("No error should happen when put, but got %v", err)
+	}
+	stream, err := traced.GetStream("/a.txt")
+	if err != nil {
+		t.Fatalf("No error should happen when get stream, but got %v", err)
+	}
+	io.ReadAll(stream)
+	stream.Close()
+	traced.List("/")
+	parent.End()
+	if _, err := storage.Stat("/missing.txt"); !errors.Is(err, oss.ErrNotFound) {
+		t.Errorf("Stat should return the wrapped error, but got %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("Should record 5 spans, but got %v", len(spans))
+	}
+	put, get, list, stat := spans[0], spans[1], spans[2], spans[4]
+	if put.Name() != "oss.Put" || put.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Put span should be a child of the request span, but got %v", put.Name())
+	}
+	if attr(put, AttrProvider).AsString() != "filesystem" || attr(put, AttrBucket).AsString() != "assets" ||
+		attr(put, AttrKey).AsString() != "/a.txt" || attr(put, AttrBytes).AsInt64() != 6 {
+		t.Errorf("Put span should carry provider, bucket, key and bytes, but got %v", put.Attributes())
+	}
+	if get.Name() != "oss.GetStream" || attr(get, AttrBytes).AsInt64() != 6 {
+		t.Errorf("GetStream span should end on close with bytes read, but got %v %v", get.Name(), get.Attributes())
+	}
+	if list.Name() != "oss.List" || attr(list, AttrCount).AsInt64() != 1 {
+		t.Errorf("List span should record object count, but got %v", list.Attributes())
+	}
+	if stat.Status().Code != codes.Error || stat.Parent().IsValid() || len(stat.Events()) == 0 {
+		t.Errorf("Stat span should record the error without parent, but got %+v", stat.Status())
+	}
+}
@@ -0,0 +1,52 @@
+package oss
+
+import "reflect"
+
+// Underlier 可以返回原始SDK客户端的存储后端
+// s3、aliyun、azureblob、googlecloud、huawei、qiniu 和 tencent 实现了该接口，各包同时提供返回具体类型的方法或导出字段。
+// 原始客户端用于统一接口没有覆盖的少数功能，其类型与各后端使用的SDK版本绑定，SDK升级主版本时可能变化，不在兼容性承诺之内；
+// 通过原始客户端做出的修改（例如改变存储桶配置）不会同步到存储后端的 Config
+type Underlier interface {
+	// Underlying 返回原始SDK客户端
+	// 返回:
+	//   - interface{}: 原始SDK客户端，具体类型见各后端的文档
+	Underlying() interface{}
+}
+
+// Underlying 返回存储后端的原始SDK客户端
+// 依次解开嵌入了 StorageInterface 字段的包装器，例如 PrefixedStorage、TimeoutStorage 和各子包的包装器，
+// 直到找到实现了 Underlier 的存储后端
+// 参数:
+//   - storage: 存储接口，可以是包装器
+// 返回:
+//   - interface{}: 原始SDK客户端
+//   - bool: 是否找到
+func Underlying(storage StorageInterface) (interface{}, bool) {
+	for storage != nil {
+		if underlier, ok := storage.(Underlier); ok {
+			return underlier.Underlying(), true
+		}
+		storage = wrappedStorage(storage)
+	}
+	return nil, false
+}
+
+// wrappedStorage 返回包装器的 StorageInterface 字段，不是包装器时返回nil
+func wrappedStorage(storage StorageInterface) StorageInterface {
+	value := reflect.ValueOf(storage)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	field := value.FieldByName("StorageInterface")
+	if !field.IsValid() || field.Kind() != reflect.Interface || field.IsNil() {
+		return nil
+	}
+	wrapped, _ := field.Interface().(StorageInterface)
+	return wrapped
+}
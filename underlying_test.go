@@ -0,0 +1,28 @@
+package oss_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/filesystem"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+type underlyingStorage struct {
+	*ossmock.Storage
+}
+
+func (underlyingStorage) Underlying() interface{} {
+	return "raw client"
+}
+
+func TestUnderlying(t *testing.T) {
+	if _, ok := oss.Underlying(oss.NewPrefixedStorage(filesystem.New(t.TempDir()), "/a")); ok {
+		t.Errorf("File system should not have an underlying client")
+	}
+	storage := oss.WithTimeout(oss.NewPrefixedStorage(underlyingStorage{ossmock.New()}, "/a"), time.Minute)
+	if raw, ok := oss.Underlying(storage); !ok || raw != "raw client" {
+		t.Errorf("Underlying should unwrap wrappers, but got %v %v", raw, ok)
+	}
+}
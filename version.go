@@ -0,0 +1,52 @@
+package oss
+
+import "io"
+
+// ObjectVersion 对象的一个历史版本
+type ObjectVersion struct {
+	// Object 版本对应的对象信息，删除标记没有大小和内容类型
+	Object
+	// VersionID 服务端分配的版本ID
+	VersionID string
+	// IsLatest 是否为当前版本
+	IsLatest bool
+	// IsDeleteMarker 是否为删除标记，删除开启了版本控制的对象时产生
+	IsDeleteMarker bool
+}
+
+// Versioner 对象版本控制接口
+// 存储桶需要先在服务端开启版本控制，s3、googlecloud、azureblob和aliyun实现了该接口，调用方通过类型断言判断是否支持
+type Versioner interface {
+	// ListVersions 列出对象的全部版本，按修改时间从新到旧排列
+	// 参数:
+	//   - path: 对象路径
+	// 返回:
+	//   - []*ObjectVersion: 版本列表
+	//   - error: 错误信息
+	ListVersions(path string) ([]*ObjectVersion, error)
+
+	// GetVersion 获取对象指定版本的内容
+	// 参数:
+	//   - path: 对象路径
+	//   - versionID: 版本ID
+	// 返回:
+	//   - io.ReadCloser: 版本内容
+	//   - error: 错误信息
+	GetVersion(path string, versionID string) (io.ReadCloser, error)
+
+	// DeleteVersion 永久删除对象的指定版本
+	// 参数:
+	//   - path: 对象路径
+	//   - versionID: 版本ID
+	// 返回:
+	//   - error: 错误信息
+	DeleteVersion(path string, versionID string) error
+
+	// RestoreVersion 将指定版本复制为对象的当前版本，原有版本保持不变
+	// 参数:
+	//   - path: 对象路径
+	//   - versionID: 版本ID
+	// 返回:
+	//   - error: 错误信息
+	RestoreVersion(path string, versionID string) error
+}
@@ -0,0 +1,462 @@
+package oss
+
+import (
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+	pathpkg "path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWriteBehindQueue 异步写入队列的默认长度
+	DefaultWriteBehindQueue = 64
+	// DefaultWriteBehindWorkers 异步写入的默认并发数
+	DefaultWriteBehindWorkers = 4
+)
+
+// ErrWriteBehindClosed 异步写入包装器已经关闭
+var ErrWriteBehindClosed = errors.New("oss: write-behind storage closed")
+
+// WriteBehindResult 一次异步写入的结果
+type WriteBehindResult struct {
+	// Path 目标路径
+	Path string
+	// Object 写入后的对象信息，失败时为nil
+	Object *Object
+	// Err 写入被包装存储时的错误
+	Err error
+	// Latency 从调用方写入返回到写入被包装存储完成的时间
+	Latency time.Duration
+}
+
+// WriteBehindOptions 异步写入的选项
+type WriteBehindOptions struct {
+	// Queue 等待写入的最大对象数，队列已满时写入阻塞，小于等于0时使用 DefaultWriteBehindQueue
+	Queue int
+	// Workers 写入被包装存储的并发数，小于等于0时使用 DefaultWriteBehindWorkers
+	Workers int
+	// OnDurable 每个对象写入被包装存储成功或失败后的回调，在写入协程中调用
+	OnDurable func(result WriteBehindResult)
+}
+
+// WriteBehindStorage 异步写入的存储包装器
+// Put、PutWithOptions 和 NewWriter 的 Close 将内容写入本地临时文件并放入有界队列后立即返回，
+// 由后台协程写入被包装的存储，调用方的延迟不再取决于广域网上的群晖NAS等慢速后端。
+// 同一路径的写入由同一个协程按顺序执行；写入完成前 Get、GetStream、GetStreamRange、Stat 和 Exists 返回本地的内容，
+// Delete、Copy、Move、DeleteDir 和 List 先等待相关路径的写入完成。
+// 写入失败时只通过 OnDurable 报告，进程退出前需要调用 Close 等待队列中的写入完成
+type WriteBehindStorage struct {
+	// StorageInterface 被包装的存储接口
+	StorageInterface
+
+	opts    WriteBehindOptions
+	queues  []chan *writeBehindJob
+	workers sync.WaitGroup
+
+	// sendMu 保证关闭队列时没有正在放入队列的写入
+	sendMu sync.RWMutex
+	closed bool
+
+	mu      sync.Mutex
+	pending map[string]*writeBehindJob
+}
+
+// writeBehindJob 等待写入的对象
+type writeBehindJob struct {
+	path   string
+	file   *os.File
+	size   int64
+	opts   *PutOptions
+	queued time.Time
+	done   chan struct{}
+}
+
+// WithWriteBehind 创建异步写入的存储包装器
+// 参数:
+//   - storage: 被包装的存储接口
+//   - opts: 异步写入的选项
+// 返回:
+//   - *WriteBehindStorage: 存储包装器实例，不再使用时调用 Close
+func WithWriteBehind(storage StorageInterface, opts WriteBehindOptions) *WriteBehindStorage {
+	if opts.Queue <= 0 {
+		opts.Queue = DefaultWriteBehindQueue
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWriteBehindWorkers
+	}
+	wb := &WriteBehindStorage{
+		StorageInterface: storage,
+		opts:             opts,
+		queues:           make([]chan *writeBehindJob, opts.Workers),
+		pending:          map[string]*writeBehindJob{},
+	}
+	for i := range wb.queues {
+		wb.queues[i] = make(chan *writeBehindJob, max(opts.Queue/opts.Workers, 1))
+		wb.workers.Add(1)
+		go wb.run(wb.queues[i])
+	}
+	return wb
+}
+
+// run 按顺序将队列中的对象写入被包装的存储
+func (wb *WriteBehindStorage) run(queue chan *writeBehindJob) {
+	defer wb.workers.Done()
+	for job := range queue {
+		reader := io.NewSectionReader(job.file, 0, job.size)
+		var object *Object
+		var err error
+		if job.opts == nil {
+			object, err = wb.StorageInterface.Put(job.path, reader)
+		} else {
+			object, err = wb.StorageInterface.PutWithOptions(job.path, reader, job.opts)
+		}
+
+		wb.mu.Lock()
+		if wb.pending[job.path] == job {
+			delete(wb.pending, job.path)
+		}
+		wb.mu.Unlock()
+		close(job.done)
+		RemoveTempFile(job.file)
+
+		if wb.opts.OnDurable != nil {
+			wb.opts.OnDurable(WriteBehindResult{Path: job.path, Object: object, Err: err, Latency: Now().Sub(job.queued)})
+		}
+	}
+}
+
+// Put 将内容写入本地临时文件并放入写入队列
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+// 返回:
+//   - *Object: 根据本地内容生成的对象信息，没有ETag
+//   - error: 写入临时文件失败或包装器已关闭时的错误，写入被包装存储的结果通过 OnDurable 报告
+func (wb *WriteBehindStorage) Put(path string, reader io.Reader) (*Object, error) {
+	return wb.enqueue(path, reader, nil)
+}
+
+// PutWithOptions 将内容写入本地临时文件，使用指定选项放入写入队列
+// 参数:
+//   - path: 目标路径
+//   - reader: 文件内容读取器
+//   - opts: 上传选项
+// 返回:
+//   - *Object: 根据本地内容生成的对象信息，没有ETag
+//   - error: 写入临时文件失败或包装器已关闭时的错误，写入被包装存储的结果通过 OnDurable 报告
+func (wb *WriteBehindStorage) PutWithOptions(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+	return wb.enqueue(path, reader, opts)
+}
+
+// enqueue 将内容写入临时文件并放入路径对应的写入队列
+func (wb *WriteBehindStorage) enqueue(path string, reader io.Reader, opts *PutOptions) (*Object, error) {
+	file, err := CreateTempFile("oss-writebehind-*")
+	if err != nil {
+		return nil, err
+	}
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		RemoveTempFile(file)
+		return nil, err
+	}
+	return wb.submit(path, file, size, opts)
+}
+
+// submit 将本地临时文件放入路径对应的写入队列，队列已满时阻塞
+func (wb *WriteBehindStorage) submit(path string, file *os.File, size int64, opts *PutOptions) (*Object, error) {
+	job := &writeBehindJob{path: path, file: file, size: size, opts: opts, queued: Now(), done: make(chan struct{})}
+	wb.sendMu.RLock()
+	defer wb.sendMu.RUnlock()
+	if wb.closed {
+		RemoveTempFile(file)
+		return nil, ErrWriteBehindClosed
+	}
+	wb.mu.Lock()
+	wb.pending[path] = job
+	wb.mu.Unlock()
+
+	hash := fnv.New32a()
+	hash.Write([]byte(path))
+	wb.queues[hash.Sum32()%uint32(len(wb.queues))] <- job
+	return job.object(), nil
+}
+
+// object 根据本地内容生成对象信息
+func (job *writeBehindJob) object() *Object {
+	object := &Object{Path: job.path, Name: pathpkg.Base(job.path), Size: job.size, LastModified: NormalizeTime(job.queued)}
+	if job.opts != nil {
+		object.ContentType = job.opts.ContentType
+		object.Metadata = job.opts.Metadata
+	}
+	return object
+}
+
+// lookup 返回路径等待写入的对象
+func (wb *WriteBehindStorage) lookup(path string) *writeBehindJob {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return wb.pending[path]
+}
+
+// openLocal 打开路径等待写入的本地内容，写入完成后临时文件被删除时已打开的文件仍然可读
+// 没有等待写入的对象或刚好写入完成时返回nil，由调用方读取被包装的存储
+func (wb *WriteBehindStorage) openLocal(path string) (*writeBehindJob, *os.File) {
+	job := wb.lookup(path)
+	if job == nil {
+		return nil, nil
+	}
+	file, err := os.Open(job.file.Name())
+	if err != nil {
+		return nil, nil
+	}
+	return job, file
+}
+
+// wait 等待匹配的路径写入完成
+func (wb *WriteBehindStorage) wait(match func(path string) bool) {
+	wb.mu.Lock()
+	var jobs []*writeBehindJob
+	for path, job := range wb.pending {
+		if match(path) {
+			jobs = append(jobs, job)
+		}
+	}
+	wb.mu.Unlock()
+	for _, job := range jobs {
+		<-job.done
+	}
+}
+
+// waitPaths 等待指定路径的写入完成
+func (wb *WriteBehindStorage) waitPaths(paths ...string) {
+	wb.wait(func(path string) bool {
+		for _, candidate := range paths {
+			if path == candidate {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// waitDir 等待目录下全部路径的写入完成
+func (wb *WriteBehindStorage) waitDir(dir string) {
+	prefix := DirPrefix(dir)
+	wb.wait(func(path string) bool {
+		return strings.HasPrefix(strings.TrimPrefix(path, "/"), prefix)
+	})
+}
+
+// Flush 等待调用前放入队列的全部写入完成
+func (wb *WriteBehindStorage) Flush() {
+	wb.wait(func(string) bool { return true })
+}
+
+// Close 停止接收新的写入，等待队列中的写入完成
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Close() error {
+	wb.sendMu.Lock()
+	if !wb.closed {
+		wb.closed = true
+		for _, queue := range wb.queues {
+			close(queue)
+		}
+	}
+	wb.sendMu.Unlock()
+	wb.workers.Wait()
+	return nil
+}
+
+// Pending 返回等待写入的对象数
+// 返回:
+//   - int: 等待写入的对象数
+func (wb *WriteBehindStorage) Pending() int {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	return len(wb.pending)
+}
+
+// Get 获取文件，写入完成前复制本地内容
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *os.File: 文件
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Get(path string) (*os.File, error) {
+	if _, file := wb.openLocal(path); file != nil {
+		return StreamToTempFile(file, "oss-*"+pathpkg.Ext(path))
+	}
+	return wb.StorageInterface.Get(path)
+}
+
+// GetStream 获取文件流，写入完成前读取本地内容
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - io.ReadCloser: 文件流
+//   - error: 错误信息
+func (wb *WriteBehindStorage) GetStream(path string) (io.ReadCloser, error) {
+	if _, file := wb.openLocal(path); file != nil {
+		return file, nil
+	}
+	return wb.StorageInterface.GetStream(path)
+}
+
+// GetStreamRange 范围读取文件流，写入完成前读取本地内容
+// 参数:
+//   - path: 文件路径
+//   - offset: 起始偏移量
+//   - length: 读取长度，小于等于0时读取到文件末尾
+// 返回:
+//   - io.ReadCloser: 范围内的文件流
+//   - error: 错误信息
+func (wb *WriteBehindStorage) GetStreamRange(path string, offset, length int64) (io.ReadCloser, error) {
+	if err := ValidateRange(offset, length); err != nil {
+		return nil, err
+	}
+	job, file := wb.openLocal(path)
+	if file == nil {
+		return wb.StorageInterface.GetStreamRange(path, offset, length)
+	}
+	if length <= 0 {
+		length = max(job.size-offset, 0)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(file, offset, length), file}, nil
+}
+
+// Stat 获取对象信息，写入完成前根据本地内容生成
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - *Object: 对象信息
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Stat(path string) (*Object, error) {
+	if job := wb.lookup(path); job != nil {
+		return job.object(), nil
+	}
+	return wb.StorageInterface.Stat(path)
+}
+
+// Exists 检查文件是否存在，等待写入的路径视为存在
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - bool: 是否存在
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Exists(path string) (bool, error) {
+	if wb.lookup(path) != nil {
+		return true, nil
+	}
+	return wb.StorageInterface.Exists(path)
+}
+
+// NewWriter 创建流式写入器，内容写入本地临时文件，Close 时放入写入队列
+// 参数:
+//   - path: 目标路径
+// 返回:
+//   - io.WriteCloser: 写入器
+//   - error: 错误信息
+func (wb *WriteBehindStorage) NewWriter(path string) (io.WriteCloser, error) {
+	file, err := CreateTempFile("oss-writebehind-*")
+	if err != nil {
+		return nil, err
+	}
+	return &writeBehindWriter{File: file, wb: wb, path: path}, nil
+}
+
+// writeBehindWriter 写入本地临时文件，关闭时放入写入队列
+type writeBehindWriter struct {
+	*os.File
+	wb     *WriteBehindStorage
+	path   string
+	closed bool
+}
+
+// Close 将临时文件放入写入队列
+func (writer *writeBehindWriter) Close() error {
+	if writer.closed {
+		return nil
+	}
+	writer.closed = true
+	info, err := writer.File.Stat()
+	if err != nil {
+		RemoveTempFile(writer.File)
+		return err
+	}
+	_, err = writer.wb.submit(writer.path, writer.File, info.Size(), nil)
+	return err
+}
+
+// Delete 等待路径的写入完成后删除文件
+// 参数:
+//   - path: 文件路径
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Delete(path string) error {
+	wb.waitPaths(path)
+	return wb.StorageInterface.Delete(path)
+}
+
+// DeleteObjects 等待路径的写入完成后批量删除对象
+// 参数:
+//   - paths: 文件路径列表
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) DeleteObjects(paths []string) error {
+	wb.waitPaths(paths...)
+	return wb.StorageInterface.DeleteObjects(paths)
+}
+
+// DeleteDir 等待目录下的写入完成后删除目录
+// 参数:
+//   - dir: 目录路径
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) DeleteDir(dir string) error {
+	wb.waitDir(dir)
+	return wb.StorageInterface.DeleteDir(dir)
+}
+
+// Copy 等待源路径和目标路径的写入完成后复制文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Copy(srcPath, dstPath string) error {
+	wb.waitPaths(srcPath, dstPath)
+	return wb.StorageInterface.Copy(srcPath, dstPath)
+}
+
+// Move 等待源路径和目标路径的写入完成后移动文件
+// 参数:
+//   - srcPath: 源文件路径
+//   - dstPath: 目标文件路径
+// 返回:
+//   - error: 错误信息
+func (wb *WriteBehindStorage) Move(srcPath, dstPath string) error {
+	wb.waitPaths(srcPath, dstPath)
+	return wb.StorageInterface.Move(srcPath, dstPath)
+}
+
+// List 等待目录下的写入完成后列出对象
+// 参数:
+//   - path: 目录路径
+// 返回:
+//   - []*Object: 对象列表
+//   - error: 错误信息
+func (wb *WriteBehindStorage) List(path string) ([]*Object, error) {
+	wb.waitDir(path)
+	return wb.StorageInterface.List(path)
+}
@@ -0,0 +1,96 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+	"github.com/smart-unicom/oss/ossmock"
+)
+
+// gatedStorage 在 gate 关闭前阻塞上传的存储
+type gatedStorage struct {
+	oss.StorageInterface
+	gate chan struct{}
+}
+
+func (storage *gatedStorage) Put(path string, reader io.Reader) (*oss.Object, error) {
+	<-storage.gate
+	return storage.StorageInterface.Put(path, reader)
+}
+
+func TestWriteBehind(t *testing.T) {
+	mock := ossmock.New()
+	backend := &gatedStorage{StorageInterface: mock, gate: make(chan struct{})}
+	var mu sync.Mutex
+	var results []oss.WriteBehindResult
+	storage := oss.WithWriteBehind(backend, oss.WriteBehindOptions{Workers: 2, OnDurable: func(result oss.WriteBehindResult) {
+		mu.Lock()
+		results = append(results, result)
+		mu.Unlock()
+	}})
+
+	read := func(storage oss.StorageInterface, path string) string {
+		t.Helper()
+		var content strings.Builder
+		if err := oss.Download(storage, path, &content); err != nil {
+			t.Fatalf("Download %v failed: %v", path, err)
+		}
+		return content.String()
+	}
+
+	object, err := storage.Put("/dir/a.txt", strings.NewReader("hello"))
+	if err != nil || object.Size != 5 {
+		t.Fatalf("Put should return before the backend write, but got %+v, %v", object, err)
+	}
+	if exists, _ := mock.Exists("/dir/a.txt"); exists || storage.Pending() != 1 {
+		t.Errorf("Backend write should still be pending")
+	}
+	if content := read(storage, "/dir/a.txt"); content != "hello" {
+		t.Errorf("Pending write should be readable, but got %v", content)
+	}
+	if stream, err := storage.GetStreamRange("/dir/a.txt", 1, 3); err == nil {
+		content, _ := io.ReadAll(stream)
+		stream.Close()
+		if string(content) != "ell" {
+			t.Errorf("Pending range should be readable, but got %q", content)
+		}
+	}
+	if info, err := storage.Stat("/dir/a.txt"); err != nil || info.Size != 5 {
+		t.Errorf("Pending write should be visible to Stat, but got %+v, %v", info, err)
+	}
+
+	// 删除等待写入完成后执行
+	deleted := make(chan error)
+	go func() { deleted <- storage.Delete("/dir/a.txt") }()
+	close(backend.gate)
+	if err := <-deleted; err != nil {
+		t.Errorf("Delete after pending write should succeed, but got %v", err)
+	}
+	if exists, _ := mock.Exists("/dir/a.txt"); exists {
+		t.Errorf("Delete should run after the pending write")
+	}
+
+	writer, _ := storage.NewWriter("/dir/b.txt")
+	io.WriteString(writer, "world")
+	writer.Close()
+	storage.Flush()
+	if content := read(mock, "/dir/b.txt"); content != "world" {
+		t.Errorf("Flush should wait for pending writes, but got %v", content)
+	}
+
+	mock.FailWith("Put", errors.New("nas offline"))
+	storage.Put("/dir/c.txt", strings.NewReader("lost"))
+	storage.Close()
+	if _, err := storage.Put("/dir/d.txt", strings.NewReader("late")); !errors.Is(err, oss.ErrWriteBehindClosed) {
+		t.Errorf("Put after Close should fail, but got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(results) != 3 || results[0].Err != nil || results[2].Path != "/dir/c.txt" || results[2].Err == nil {
+		t.Errorf("OnDurable should report every write, but got %+v", results)
+	}
+}
@@ -0,0 +1,69 @@
+package oss
+
+import "io"
+
+// PipeWriter 通过管道将写入的数据流式交给上传函数的写入器
+// Close 等待上传完成并返回上传结果，CloseWithError 取消上传
+type PipeWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+// NewPipeWriter 创建管道写入器，并在后台启动上传
+// 用于只接受io.Reader的上传接口，调用方写入的数据不会在内存中整体缓冲
+// 参数:
+//   - upload: 上传函数，从reader读取数据直到EOF
+// 返回:
+//   - *PipeWriter: 管道写入器
+func NewPipeWriter(upload func(reader io.Reader) error) *PipeWriter {
+	reader, writer := io.Pipe()
+	pipeWriter := &PipeWriter{PipeWriter: writer, done: make(chan error, 1)}
+
+	go func() {
+		err := upload(reader)
+		// 上传提前结束时让后续写入立即失败，避免调用方阻塞
+		if err != nil {
+			reader.CloseWithError(err)
+		} else {
+			reader.Close()
+		}
+		pipeWriter.done <- err
+	}()
+
+	return pipeWriter
+}
+
+// Close 结束写入并等待上传完成
+// 返回:
+//   - error: 上传过程中的错误信息
+func (writer *PipeWriter) Close() error {
+	writer.PipeWriter.Close()
+	return <-writer.done
+}
+
+// CloseWithError 取消上传并等待上传函数退出
+// 参数:
+//   - err: 传递给上传函数的错误，为nil时使用 io.ErrClosedPipe
+// 返回:
+//   - error: 上传函数返回的错误信息
+func (writer *PipeWriter) CloseWithError(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	writer.PipeWriter.CloseWithError(err)
+	return <-writer.done
+}
+
+// NewWriterByPut 通过 Put 创建流式写入器
+// 用于没有原生流式上传接口的存储后端
+// 参数:
+//   - storage: 存储接口
+//   - path: 文件路径
+// 返回:
+//   - io.WriteCloser: 写入器，Close 返回上传结果
+func NewWriterByPut(storage StorageInterface, path string) io.WriteCloser {
+	return NewPipeWriter(func(reader io.Reader) error {
+		_, err := storage.Put(path, reader)
+		return err
+	})
+}
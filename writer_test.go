@@ -0,0 +1,34 @@
+package oss_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/smart-unicom/oss"
+)
+
+func TestPipeWriter(t *testing.T) {
+	uploadErr := errors.New("upload failed")
+	writer := oss.NewPipeWriter(func(reader io.Reader) error {
+		return uploadErr
+	})
+
+	if _, err := io.WriteString(writer, "sample"); err == nil {
+		t.Errorf("Write should fail after upload failed")
+	}
+
+	if err := writer.Close(); !errors.Is(err, uploadErr) {
+		t.Errorf("Close should return upload error, but got %v", err)
+	}
+
+	canceled := errors.New("canceled")
+	writer = oss.NewPipeWriter(func(reader io.Reader) error {
+		_, err := io.Copy(io.Discard, reader)
+		return err
+	})
+
+	if err := writer.CloseWithError(canceled); !errors.Is(err, canceled) {
+		t.Errorf("CloseWithError should cancel upload, but got %v", err)
+	}
+}